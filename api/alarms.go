@@ -0,0 +1,21 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// AlarmsAPI the API to manipulate alarms
+type AlarmsAPI interface {
+	GetAlarms(filter string, limit int, offset int) (*model.OnmsAlarmList, error)
+	GetAllAlarms(filter string) (*model.OnmsAlarmList, error)
+	GetAlarm(id int) (*model.OnmsAlarm, error)
+	AcknowledgeAlarm(id int, user string) error
+	UnacknowledgeAlarm(id int, user string) error
+	ClearAlarm(id int, user string) error
+	EscalateAlarm(id int, user string) error
+	SetJournalMemo(id int, user string, body string) error
+	DeleteJournalMemo(id int) error
+	SetStickyMemo(id int, user string, body string) error
+	DeleteStickyMemo(id int) error
+	CreateTicket(id int) error
+	UpdateTicket(id int) error
+	CloseTicket(id int) error
+}
@@ -0,0 +1,15 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// BusinessServicesAPI the API to manage business services (BSM)
+type BusinessServicesAPI interface {
+	GetBusinessServices() ([]model.BusinessService, error)
+	GetBusinessService(id int) (*model.BusinessService, error)
+	FindBusinessService(name string) (*model.BusinessService, error)
+	SetBusinessService(bs model.BusinessService) error
+	DeleteBusinessService(id int) error
+	AddIPServiceEdge(id int, edge model.IPServiceEdge) error
+	AddReductionKeyEdge(id int, edge model.ReductionKeyEdge) error
+	AddChildEdge(id int, edge model.ChildEdge) error
+}
@@ -0,0 +1,14 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// CategoriesAPI the API to manipulate the categories from the OpenNMS database and their node associations
+type CategoriesAPI interface {
+	GetCategories(filter string) (*model.OnmsCategoryList, error)
+	GetCategory(name string) (*model.OnmsCategory, error)
+	AddCategory(category *model.OnmsCategory) error
+	DeleteCategory(name string, force bool) (int, error)
+	GetNodes(category string) (*model.OnmsNodeList, error)
+	AssignCategory(category string, nodeCriteria string) error
+	UnassignCategory(category string, nodeCriteria string) error
+}
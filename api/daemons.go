@@ -0,0 +1,14 @@
+package api
+
+import (
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+// DaemonsAPI the API to manage OpenNMS daemons
+type DaemonsAPI interface {
+	GetDaemons() ([]model.Daemon, error)
+	GetReloadState(daemonName string) (*model.DaemonReloadState, error)
+	WaitForReload(daemonName string, lastRequest *model.Time, timeout time.Duration, pollInterval time.Duration) (*model.DaemonReloadState, error)
+}
@@ -0,0 +1,9 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// DiscoveryAPI the API to manipulate the Discovery Configuration
+type DiscoveryAPI interface {
+	GetConfig() (*model.DiscoveryConfiguration, error)
+	SetConfig(config model.DiscoveryConfiguration) error
+}
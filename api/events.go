@@ -5,4 +5,23 @@ import "github.com/OpenNMS/onmsctl/model"
 // EventsAPI the API to manipulate Events
 type EventsAPI interface {
 	SendEvent(event model.Event) error
+	SendEvents(events []model.Event, concurrency int) []error
+}
+
+// EventsQueryAPI the API to search the events stored on the OpenNMS database
+type EventsQueryAPI interface {
+	GetEvents(filter string, limit int, offset int) (*model.OnmsEventList, error)
+	GetAllEvents(filter string) (*model.OnmsEventList, error)
+	GetEventsAfter(id int, filter string, limit int) (*model.OnmsEventList, error)
+}
+
+// EventConfAPI the API to inspect the event definitions known to the server
+type EventConfAPI interface {
+	GetEventDefinitions() (*model.EventDefinitionList, error)
+}
+
+// EventSink a destination for events; the ReST API is the default, but events can also be sent to a message broker
+type EventSink interface {
+	EventsAPI
+	Close() error
 }
@@ -0,0 +1,9 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// GraphsAPI the API to obtain the prefab graph definitions
+type GraphsAPI interface {
+	GetGraphNames(resourceID string) (*model.GraphNameList, error)
+	GetGraph(name string) (*model.PrefabGraph, error)
+}
@@ -0,0 +1,13 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// GroupsAPI the API to manage groups of users
+type GroupsAPI interface {
+	GetGroups() (*model.OnmsGroupList, error)
+	GetGroup(name string) (*model.OnmsGroup, error)
+	AddGroup(group model.OnmsGroup) error
+	DeleteGroup(name string) error
+	AddUser(group string, user string) error
+	RemoveUser(group string, user string) error
+}
@@ -0,0 +1,8 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// MeasurementsAPI the API to query collected metrics
+type MeasurementsAPI interface {
+	Query(request model.QueryRequest) (*model.QueryResponse, error)
+}
@@ -0,0 +1,9 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// MinionsAPI the API to obtain the minions and the state of their heartbeat
+type MinionsAPI interface {
+	GetMinions(filter string, limit int, offset int) (*model.OnmsMinionList, error)
+	GetMinion(id string) (*model.OnmsMinion, error)
+}
@@ -8,4 +8,6 @@ type MonitoringLocationsAPI interface {
 	LocationExists(location string) (bool, error)
 	GetLocation(location string) (*model.MonitoringLocation, error)
 	SetLocation(location model.MonitoringLocation) error
+	UpdateLocation(location model.MonitoringLocation) error
+	DeleteLocation(location string) error
 }
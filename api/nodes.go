@@ -0,0 +1,14 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// NodesAPI the API to manipulate nodes from the OpenNMS database
+type NodesAPI interface {
+	GetNodes(filter string, limit int, offset int) (*model.OnmsNodeList, error)
+	GetAllNodes(filter string) (*model.OnmsNodeList, error)
+	GetNode(criteria string) (*model.OnmsNode, error)
+	DeleteNode(id string) error
+	GetIPInterfaces(id string) (*model.OnmsIPInterfaceList, error)
+	GetSnmpInterfaces(id string) (*model.OnmsSnmpInterfaceList, error)
+	GetHardwareInventory(id string) (*model.HwEntity, error)
+}
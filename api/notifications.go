@@ -0,0 +1,18 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// NotificationsAPI the API to manage the notification configuration
+type NotificationsAPI interface {
+	GetStatus() (string, error)
+	SetStatus(status string) error
+
+	GetEventNotifications() (*model.EventNotificationList, error)
+	AddEventNotification(notification model.EventNotification) error
+	DeleteEventNotification(name string) error
+
+	GetDestinationPaths() (*model.DestinationPathList, error)
+	GetDestinationPath(name string) (*model.DestinationPath, error)
+	SetDestinationPath(path model.DestinationPath) error
+	DeleteDestinationPath(name string) error
+}
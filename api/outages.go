@@ -0,0 +1,9 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// OutagesAPI the API to obtain the outages of the monitored services
+type OutagesAPI interface {
+	GetOutages(filter string, limit int, offset int) (*model.OnmsOutageList, error)
+	GetAllOutages(filter string) (*model.OnmsOutageList, error)
+}
@@ -0,0 +1,8 @@
+package api
+
+import "net/url"
+
+// PagerAPI the API to traverse the paginated lists of the ReST API v2
+type PagerAPI interface {
+	ForEachPage(path string, params url.Values, pageSize int, handle func(body []byte) (count int, err error)) error
+}
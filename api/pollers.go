@@ -0,0 +1,9 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// PollersAPI the API to inspect the poller configuration
+type PollersAPI interface {
+	GetConfiguration(location string) (*model.PollerConfiguration, error)
+	EvaluateNode(criteria string, clientSide bool) (*model.PollerEvaluation, error)
+}
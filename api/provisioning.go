@@ -10,4 +10,5 @@ type ProvisioningUtilsAPI interface {
 	GetAvailableAssets() (*model.ElementList, error)
 	GetAvailableDetectors() (*model.PluginList, error)
 	GetAvailablePolicies() (*model.PluginList, error)
+	GetAvailableServices(foreignSource string) (*model.ElementList, error)
 }
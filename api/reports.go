@@ -0,0 +1,17 @@
+package api
+
+import (
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+// ReportsAPI the API to run database reports
+type ReportsAPI interface {
+	GetReports() (*model.ReportList, error)
+	GetReport(reportID string) (*model.ReportDetails, error)
+	RunReport(request model.ReportRequest) error
+	GetPersistedReports() (*model.PersistedReportList, error)
+	WaitForReport(reportID string, previous model.PersistedReportList, timeout time.Duration, pollInterval time.Duration) (*model.PersistedReport, error)
+	DownloadReport(persistedID int, format string) ([]byte, error)
+}
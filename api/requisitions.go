@@ -1,6 +1,10 @@
 package api
 
-import "github.com/OpenNMS/onmsctl/model"
+import (
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+)
 
 // RequisitionsAPI the API to manipulate Requisitions
 type RequisitionsAPI interface {
@@ -8,9 +12,14 @@ type RequisitionsAPI interface {
 
 	CreateRequisition(foreignSource string) error
 	GetRequisition(foreignSource string) (*model.Requisition, error)
+	GetRequisitions(foreignSources []string, concurrency int) ([]model.Requisition, map[string]error)
+	GetDeployedRequisitions() ([]model.Requisition, error)
+	ForEachNode(foreignSource string, fn func(node model.RequisitionNode) error) (*model.Requisition, error)
 	SetRequisition(req model.Requisition) error
+	SetRequisitionChunked(req model.Requisition, concurrency int, progress func(done int, total int)) ([]model.NodeError, error)
 	DeleteRequisition(foreignSource string) error
-	ImportRequisition(foreignSource string, rescanExisting string) error
+	ImportRequisition(foreignSource string, rescan model.RescanMode) error
+	WaitForImport(foreignSource string, lastImport *model.Time, timeout time.Duration, pollInterval time.Duration) (*model.RequisitionStats, error)
 
 	GetNode(foreignSource string, foreignID string) (*model.RequisitionNode, error)
 	SetNode(foreignSource string, node model.RequisitionNode) error
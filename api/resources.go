@@ -4,8 +4,8 @@ import "github.com/OpenNMS/onmsctl/model"
 
 // ResourcesAPI the API to manipulate Resources
 type ResourcesAPI interface {
-	GetResourceForNode(nodeCriteria string) (*model.Resource, error)
-	GetResources() (*model.ResourceList, error)
+	GetResourceForNode(nodeCriteria string, depth int) (*model.Resource, error)
+	GetResources(depth int) (*model.ResourceList, error)
 	GetResource(resourceID string) (*model.Resource, error)
 	DeleteResource(resourceID string) error
 }
@@ -1,5 +1,10 @@
 package api
 
+import (
+	"context"
+	"io"
+)
+
 // RestAPI the API for ReST Operations
 type RestAPI interface {
 	Get(path string) ([]byte, error)
@@ -7,3 +12,36 @@ type RestAPI interface {
 	Delete(path string) error
 	Put(path string, dataBytes []byte, contentType string) error
 }
+
+// RestContextAPI the API for ReST Operations that can be cancelled through a context
+type RestContextAPI interface {
+	RestAPI
+	GetWithContext(ctx context.Context, path string) ([]byte, error)
+	PostWithContext(ctx context.Context, path string, jsonBytes []byte) error
+	DeleteWithContext(ctx context.Context, path string) error
+	PutWithContext(ctx context.Context, path string, dataBytes []byte, contentType string) error
+}
+
+// RestCancelAPI the API for ReST Operations bound to a context that interrupts them (e.x. cancelled on Ctrl-C)
+type RestCancelAPI interface {
+	RestAPI
+	Context() context.Context
+}
+
+// RestQueryAPI the API for ReST Operations that return content on POST requests (e.x. queries)
+type RestQueryAPI interface {
+	RestAPI
+	PostWithResponse(path string, jsonBytes []byte) ([]byte, error)
+}
+
+// RestStreamAPI the API for ReST Operations whose responses are processed while they are received (e.x. large requisitions)
+type RestStreamAPI interface {
+	RestAPI
+	GetStream(path string, consume func(body io.Reader) error) error
+}
+
+// RestDecodeAPI the API for ReST Operations whose responses might be decoded strictly (e.x. to detect API changes)
+type RestDecodeAPI interface {
+	RestAPI
+	IsStrictDecode() bool
+}
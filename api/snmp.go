@@ -6,4 +6,5 @@ import "github.com/OpenNMS/onmsctl/model"
 type SnmpAPI interface {
 	GetConfig(ipAddress string, location string) (*model.SnmpInfo, error)
 	SetConfig(ipAddress string, config model.SnmpInfo) error
+	SetRangeConfig(config model.SnmpRange) error
 }
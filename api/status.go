@@ -0,0 +1,12 @@
+package api
+
+import (
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+// StatusAPI the API to obtain a summary of the state of the server
+type StatusAPI interface {
+	GetStatus(timeout time.Duration, stale time.Duration) (*model.ServerStatus, error)
+}
@@ -0,0 +1,10 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// ThresholdsAPI the API to manage threshold groups
+type ThresholdsAPI interface {
+	GetGroups() (*model.ThresholdGroupList, error)
+	GetGroup(name string) (*model.ThresholdGroup, error)
+	SetGroup(group model.ThresholdGroup) error
+}
@@ -0,0 +1,14 @@
+package api
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// UsersAPI the API to manage users
+type UsersAPI interface {
+	GetUsers() (*model.OnmsUserList, error)
+	GetUser(id string) (*model.OnmsUser, error)
+	AddUser(user model.OnmsUser) error
+	DeleteUser(id string) error
+	SetPassword(id string, password string) error
+	AddRole(id string, role string) error
+	RemoveRole(id string, role string) error
+}
@@ -0,0 +1,194 @@
+package alarms
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+var severities = model.NewEnum(model.AlarmSeverities.Enum...)
+
+// The oldest version that provides the alarms through the ReST API v2
+const minAlarmsVersion = "22.0.0"
+
+// now the reference for the relative times of --since and --until (replaced on tests)
+var now = time.Now
+
+// CliCommand the CLI command to manage alarms
+var CliCommand = cli.Command{
+	Name:  "alarms",
+	Usage: "Manage alarms",
+	Before: func(c *cli.Context) error {
+		return common.GetClient(c).MinServerVersion(minAlarmsVersion, "Managing alarms through the ReST API v2")
+	},
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List alarms",
+			Action: listAlarms,
+			Flags: []cli.Flag{
+				cli.GenericFlag{
+					Name:  "severity, s",
+					Value: severities,
+					Usage: "The severity of the alarms: " + severities.EnumAsString(),
+				},
+				cli.StringFlag{
+					Name:  "node, n",
+					Usage: "The node ID or node label of the alarms",
+				},
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "A FIQL expression to filter alarms (e.x. 'alarm.uei==*nodeDown')",
+				},
+				cli.StringFlag{
+					Name:  "since",
+					Usage: "Only alarms whose last event happened after the given time (e.x. 2h, '2h ago', now-1d, '2024-01-02 15:04' in local time, or an RFC3339 timestamp)",
+				},
+				cli.StringFlag{
+					Name:  "until",
+					Usage: "Only alarms whose last event happened before the given time, with the same formats as --since",
+				},
+				cli.IntFlag{
+					Name:  "limit, l",
+					Usage: "The amount of alarms per query",
+					Value: 10,
+				},
+				cli.IntFlag{
+					Name:  "offset",
+					Usage: "The starting alarm index (for pagination)",
+					Value: 0,
+				},
+				common.AllPagesFlag,
+			},
+		},
+		{
+			Name:      "get",
+			Usage:     "Shows an alarm, including its sticky and journal memos",
+			ArgsUsage: "<id>",
+			Action:    showAlarm,
+		},
+		{
+			Name:      "ack",
+			Usage:     "Acknowledges an alarm, or all the alarms matching a filter",
+			ArgsUsage: "<id>",
+			Action:    alarmActionCommand(ackAction),
+			Flags:     actionFlags(),
+		},
+		{
+			Name:      "unack",
+			Usage:     "Unacknowledges an alarm, or all the alarms matching a filter",
+			ArgsUsage: "<id>",
+			Action:    alarmActionCommand(unackAction),
+			Flags:     actionFlags(),
+		},
+		{
+			Name:      "clear",
+			Usage:     "Clears an alarm, or all the alarms matching a filter",
+			ArgsUsage: "<id>",
+			Action:    alarmActionCommand(clearAction),
+			Flags:     actionFlags(),
+		},
+		{
+			Name:      "escalate",
+			Usage:     "Escalates an alarm, or all the alarms matching a filter",
+			ArgsUsage: "<id>",
+			Action:    alarmActionCommand(escalateAction),
+			Flags:     actionFlags(),
+		},
+		{
+			Name:        "memo",
+			Usage:       "Manage the sticky memo of an alarm, which is removed with the alarm",
+			Subcommands: memoSubcommands(stickyMemo),
+		},
+		{
+			Name:        "journal",
+			Usage:       "Manage the journal memo of an alarm, which is shared by all the alarms with the same reduction key",
+			Subcommands: memoSubcommands(journalMemo),
+		},
+	},
+}
+
+func listAlarms(c *cli.Context) error {
+	filter, err := buildFilter(c, now())
+	if err != nil {
+		return err
+	}
+	var list *model.OnmsAlarmList
+	if c.Bool("all") {
+		list, err = getAPI(c).GetAllAlarms(filter)
+	} else {
+		list, err = getAPI(c).GetAlarms(filter, c.Int("limit"), c.Int("offset"))
+	}
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no alarms", "ID", "Severity", "Count", "Last Event", "Log Message")
+	for _, a := range list.Alarms {
+		table.AddRow(a.ID, a.Severity, a.Count, common.DisplayTime(a.LastEventTime), a.LogMessage)
+	}
+	if err := common.Print(list.Alarms, table); err != nil {
+		return err
+	}
+	if list.TotalCount > list.Offset+len(list.Alarms) && common.OutputFormat == common.OutputTable {
+		common.Log.Infof("Showing %d of %d alarms; use --all, or --offset and --limit, to see more", len(list.Alarms), list.TotalCount)
+	}
+	return nil
+}
+
+func showAlarm(c *cli.Context) error {
+	id, err := getAlarmID(c)
+	if err != nil {
+		return err
+	}
+	alarm, err := getAPI(c).GetAlarm(id)
+	if err != nil {
+		return err
+	}
+	return common.Print(alarm, nil)
+}
+
+func buildFilter(c *cli.Context, now time.Time) (string, error) {
+	builder := fiql.NewBuilder()
+	if severity := c.String("severity"); severity != "" {
+		builder.Equals("alarm.severity", strings.ToUpper(severity))
+	}
+	if node := c.String("node"); node != "" {
+		if _, err := strconv.Atoi(node); err == nil {
+			builder.Equals("node.id", node)
+		} else {
+			builder.Equals("node.label", node)
+		}
+	}
+	timeRange, err := common.TimeRangeFilter("alarm.lastEventTime", c.String("since"), c.String("until"), now)
+	if err != nil {
+		return "", err
+	}
+	for _, expression := range timeRange {
+		builder.Filter(expression)
+	}
+	return builder.Filter(c.String("filter")).Build()
+}
+
+func getAlarmID(c *cli.Context) (int, error) {
+	arg := c.Args().First()
+	if arg == "" {
+		return 0, fmt.Errorf("Alarm ID required")
+	}
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid alarm ID %s", arg)
+	}
+	return id, nil
+}
+
+func getAPI(c *cli.Context) api.AlarmsAPI {
+	return services.GetAlarmsAPI(common.GetClient(c))
+}
@@ -0,0 +1,163 @@
+package alarms
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"github.com/urfave/cli"
+	"gotest.tools/assert"
+)
+
+// Creates the CLI with a client for a server that supports the ReST API v2, so its version is not requested
+func createCli(cmd cli.Command, opts ...rest.Option) *cli.App {
+	app := test.CreateCli(cmd, opts...)
+	test.Client(app).ServerVersion = &rest.Version{Major: 26}
+	return app
+}
+
+var mockData = &model.OnmsAlarmList{
+	Count:      1,
+	TotalCount: 1,
+	Alarms: []model.OnmsAlarm{
+		{
+			ID: 10, UEI: "uei.opennms.org/nodes/nodeDown", Severity: "MAJOR", Count: 2, LogMessage: "Node is down",
+			StickyMemo: &model.OnmsMemo{ID: 1, Body: "Waiting for the ISP", Author: "jdoe"},
+		},
+	},
+}
+
+// The form sent by the last memo update, or deleted when the memo was removed
+var lastMemo string
+
+func createMockServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v2/alarms":
+			assert.Equal(t, http.MethodGet, req.Method)
+			if req.URL.Query().Get("_s") == "alarm.severity==CRITICAL;node.label==srv01" {
+				res.WriteHeader(http.StatusNoContent)
+				return
+			}
+			bytes, _ := json.Marshal(mockData)
+			res.WriteHeader(http.StatusOK)
+			res.Write(bytes)
+		case "/api/v2/alarms/10":
+			assert.Equal(t, http.MethodPut, req.Method)
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.Equal(t, "ack=true&ackUser=admin", string(bytes))
+			res.WriteHeader(http.StatusNoContent)
+		case "/api/v2/alarms/10/memo", "/api/v2/alarms/10/journal":
+			if req.Method == http.MethodPut {
+				bytes, err := ioutil.ReadAll(req.Body)
+				assert.NilError(t, err)
+				lastMemo = string(bytes)
+			} else {
+				assert.Equal(t, http.MethodDelete, req.Method)
+				lastMemo = "deleted"
+			}
+			res.WriteHeader(http.StatusNoContent)
+		default:
+			res.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	return server
+}
+
+func TestListAlarms(t *testing.T) {
+	var err error
+	app := createCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "alarms", "list"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "alarms", "list", "-s", "Critical", "-n", "srv01"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "alarms", "list", "-s", "Bad"})
+	assert.ErrorContains(t, err, "valid options: ")
+
+	err = app.Run([]string{app.Name, "alarms", "list", "--since", "now", "--until", "1d ago"})
+	assert.ErrorContains(t, err, "must be before the --until time")
+}
+
+func TestListAlarmsOutput(t *testing.T) {
+	now = func() time.Time { return time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	var filter string
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		filter = req.URL.Query().Get("_s")
+		bytes, _ := json.Marshal(mockData)
+		res.Write(bytes)
+	}))
+	defer server.Close()
+	app := createCli(CliCommand, rest.WithURL(server.URL))
+
+	output, err := test.RunWithOutput(app, "table", "alarms", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `ID  Severity  Count  Last Event  Log Message
+10  MAJOR     2      Never       Node is down
+`, output)
+
+	output, err = test.RunWithOutput(app, "jsonpath=$[*].id", "alarms", "list", "--since", "1h ago", "--offset", "0")
+	assert.NilError(t, err)
+	assert.Equal(t, "10\n", output)
+	assert.Assert(t, strings.Contains(filter, "alarm.lastEventTime=gt=2020-01-01T"), filter)
+}
+
+func TestBuildFilter(t *testing.T) {
+	var filter string
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{cli.StringFlag{Name: "severity"}, cli.StringFlag{Name: "node"}, cli.StringFlag{Name: "filter"}, cli.StringFlag{Name: "since"}, cli.StringFlag{Name: "until"}}
+	app.Action = func(c *cli.Context) error {
+		var err error
+		filter, err = buildFilter(c, now)
+		return err
+	}
+	assert.NilError(t, app.Run([]string{app.Name, "--severity", "major", "--since", "2020-01-01 08:30", "--until", "30m ago"}))
+	assert.Equal(t, "alarm.severity==MAJOR;alarm.lastEventTime=gt=2020-01-01T08:30:00.000-0500;alarm.lastEventTime=lt=2020-01-01T11:30:00.000-0500", filter)
+
+	// Labels with special characters are quoted, and filters with alternatives are grouped
+	assert.NilError(t, app.Run([]string{app.Name, "--node", "rtr (core), ams", "--filter", "alarm.uei==*nodeDown,alarm.uei==*interfaceDown"}))
+	assert.Equal(t, `node.label=="rtr (core), ams";(alarm.uei==*nodeDown,alarm.uei==*interfaceDown)`, filter)
+
+	err := app.Run([]string{app.Name, "--severity", "major", "--filter", "alarm.severity=gte=MAJOR"})
+	assert.Error(t, err, "Invalid FIQL expression, unknown operator =gte=, expected one of ==, !=, =ge=, =le=, =gt=, =lt= at position 15\nalarm.severity=gte=MAJOR\n              ^")
+}
+
+func TestAckAlarm(t *testing.T) {
+	var err error
+	app := createCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "alarms", "ack"})
+	assert.Error(t, err, "Alarm ID required")
+
+	err = app.Run([]string{app.Name, "alarms", "ack", "abc"})
+	assert.Error(t, err, "Invalid alarm ID abc")
+
+	err = app.Run([]string{app.Name, "alarms", "ack", "10"})
+	assert.NilError(t, err)
+}
+
+func TestAlarmsRequireV2API(t *testing.T) {
+	app := createCli(CliCommand)
+	test.Client(app).ServerVersion = &rest.Version{Major: 2017, Minor: 1, Patch: 4}
+
+	_, err := test.RunWithOutput(app, "table", "alarms", "list")
+	assert.Error(t, err, "Managing alarms through the ReST API v2 requires OpenNMS >= 22.0.0, the server runs 2017.1.4 (Horizon 20)")
+}
@@ -0,0 +1,209 @@
+package alarms
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// The amount of alarms requested per query when listing the alarms that match a filter
+const bulkPageSize = 500
+
+// alarmAction an operation that can be applied to one alarm, or to all the alarms matching a filter
+type alarmAction struct {
+	Verb  string // The past participle used on the messages (e.x. acknowledged)
+	Apply func(api api.AlarmsAPI, id int, user string) error
+	Skip  func(alarm model.OnmsAlarm) bool // Optional, to ignore the matching alarms that don't need the action
+}
+
+var (
+	ackAction = alarmAction{Verb: "acknowledged", Apply: func(api api.AlarmsAPI, id int, user string) error {
+		return api.AcknowledgeAlarm(id, user)
+	}}
+	unackAction = alarmAction{Verb: "unacknowledged", Apply: func(api api.AlarmsAPI, id int, user string) error {
+		return api.UnacknowledgeAlarm(id, user)
+	}}
+	clearAction = alarmAction{Verb: "cleared", Apply: func(api api.AlarmsAPI, id int, user string) error {
+		return api.ClearAlarm(id, user)
+	}}
+	escalateAction = alarmAction{Verb: "escalated", Apply: func(api api.AlarmsAPI, id int, user string) error {
+		return api.EscalateAlarm(id, user)
+	}}
+)
+
+// alarmActionResult the outcome of applying an action to an alarm
+type alarmActionResult struct {
+	ID     int    `json:"id" yaml:"id"`
+	Result string `json:"result" yaml:"result"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// The flags shared by the commands that update alarms
+func actionFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "filter, f",
+			Usage: "A FIQL expression to update all the matching alarms instead of a single one (e.x. 'severity=ge=MAJOR;alarmAckUser==null')",
+		},
+		cli.StringFlag{
+			Name:  "comment, c",
+			Usage: "A comment added to the journal memo of each updated alarm",
+		},
+		cli.IntFlag{
+			Name:  "batch-size",
+			Usage: "The amount of alarms updated before pausing, when using a filter",
+			Value: 50,
+		},
+		cli.DurationFlag{
+			Name:  "batch-delay",
+			Usage: "The pause between batches, to avoid overwhelming the server",
+			Value: time.Second,
+		},
+		cli.IntFlag{
+			Name:  "workers, w",
+			Usage: "The amount of alarms updated in parallel within a batch",
+			Value: 4,
+		},
+		common.YesFlag,
+	}
+}
+
+// Returns the command action that applies the given action to one alarm, or to all the alarms matching --filter
+func alarmActionCommand(action alarmAction) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.String("filter") != "" {
+			return applyToMatchingAlarms(c, action)
+		}
+		id, err := getAlarmID(c)
+		if err != nil {
+			return err
+		}
+		return applyToAlarm(getAPI(c), action, id, common.GetClient(c).Username, c.String("comment"))
+	}
+}
+
+func applyToAlarm(api api.AlarmsAPI, action alarmAction, id int, user string, comment string) error {
+	if err := action.Apply(api, id, user); err != nil {
+		return err
+	}
+	if comment != "" {
+		if err := api.SetJournalMemo(id, user, comment); err != nil {
+			return fmt.Errorf("Alarm %d was %s, but the comment cannot be added: %w", id, action.Verb, err)
+		}
+	}
+	return nil
+}
+
+// Lists the alarms matching the filter and, after confirmation, applies the action to them in batches;
+// a failure doesn't stop the run, and all of them are reported at the end
+func applyToMatchingAlarms(c *cli.Context, action alarmAction) error {
+	batchSize := c.Int("batch-size")
+	if batchSize <= 0 {
+		return fmt.Errorf("Batch size must be greater than zero")
+	}
+	workers := c.Int("workers")
+	if workers <= 0 {
+		return fmt.Errorf("Workers must be greater than zero")
+	}
+	if c.Args().Present() {
+		return fmt.Errorf("Use either an alarm ID or --filter, not both")
+	}
+	filter := c.String("filter")
+	if err := fiql.Validate(filter); err != nil {
+		return err
+	}
+	api := getAPI(c)
+	ids, err := getMatchingAlarmIDs(api, filter, action.Skip)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		common.Log.Infof("There are no alarms matching %s", filter)
+		return nil
+	}
+	common.Log.Infof("%d alarms match %s", len(ids), filter)
+	if err := common.Confirm(c, fmt.Sprintf("%d alarms will be %s", len(ids), action.Verb)); err != nil {
+		return err
+	}
+	results := make([]alarmActionResult, len(ids))
+	for start := 0; start < len(ids); start += batchSize {
+		if start > 0 {
+			if err := common.GetClient(c).Sleep(c.Duration("batch-delay")); err != nil {
+				return err
+			}
+		}
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		runBatch(api, action, common.GetClient(c).Username, c.String("comment"), ids[start:end], results[start:end], workers)
+	}
+	failed := 0
+	table := common.NewTable("", "ID", "Result")
+	for _, r := range results {
+		status := r.Result
+		if r.Error != "" {
+			status += ": " + r.Error
+			failed++
+		}
+		table.AddRow(r.ID, status)
+	}
+	if err := common.Print(results, table); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d alarms failed", failed, len(ids))
+	}
+	return nil
+}
+
+// Applies the action to the alarms with a bounded amount of workers; results[i] holds the outcome of ids[i]
+func runBatch(api api.AlarmsAPI, action alarmAction, user string, comment string, ids []int, results []alarmActionResult, workers int) {
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = alarmActionResult{ID: ids[i], Result: action.Verb}
+				if err := applyToAlarm(api, action, ids[i], user, comment); err != nil {
+					results[i].Result = "failed"
+					results[i].Error = err.Error()
+				}
+			}
+		}()
+	}
+	for i := range ids {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// Obtains the IDs of all the alarms matching the filter, following the pagination; skip can be nil
+func getMatchingAlarmIDs(api api.AlarmsAPI, filter string, skip func(alarm model.OnmsAlarm) bool) ([]int, error) {
+	ids := make([]int, 0)
+	offset := 0
+	for {
+		list, err := api.GetAlarms(filter, bulkPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range list.Alarms {
+			if skip == nil || !skip(a) {
+				ids = append(ids, a.ID)
+			}
+		}
+		offset += len(list.Alarms)
+		if len(list.Alarms) == 0 || offset >= list.TotalCount {
+			return ids, nil
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package alarms
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func createBulkMockServer(t *testing.T, updates map[string]string, lock *sync.Mutex) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/api/v2/alarms" {
+			assert.Equal(t, "severity=ge=MAJOR;alarmAckUser==null", req.URL.Query().Get("_s"))
+			list := &model.OnmsAlarmList{TotalCount: 3}
+			if req.URL.Query().Get("offset") == "0" {
+				list.Alarms = []model.OnmsAlarm{{ID: 10}, {ID: 11}}
+			} else {
+				list.Alarms = []model.OnmsAlarm{{ID: 12}}
+			}
+			list.Count = len(list.Alarms)
+			bytes, _ := json.Marshal(list)
+			res.Write(bytes)
+			return
+		}
+		assert.Equal(t, http.MethodPut, req.Method)
+		if req.URL.Path == "/api/v2/alarms/11" {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		bytes, err := ioutil.ReadAll(req.Body)
+		assert.NilError(t, err)
+		lock.Lock()
+		updates[req.URL.Path] = string(bytes)
+		lock.Unlock()
+		res.WriteHeader(http.StatusNoContent)
+	}))
+	return server
+}
+
+func TestAckMatchingAlarms(t *testing.T) {
+	var err error
+	updates := make(map[string]string)
+	lock := &sync.Mutex{}
+	app := createCli(CliCommand)
+	server := createBulkMockServer(t, updates, lock)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	defer func() { common.ConfirmInput = nil }()
+	filter := "severity=ge=MAJOR;alarmAckUser==null"
+
+	common.ConfirmInput = strings.NewReader("n\n")
+	_, err = test.RunWithOutput(app, "table", "alarms", "ack", "--filter", filter)
+	assert.Error(t, err, "Operation cancelled")
+	assert.Equal(t, 0, len(updates))
+
+	err = app.Run([]string{app.Name, "alarms", "ack", "--filter", filter, "--batch-size", "0"})
+	assert.Error(t, err, "Batch size must be greater than zero")
+
+	err = app.Run([]string{app.Name, "alarms", "ack", "--filter", filter, "10"})
+	assert.Error(t, err, "Use either an alarm ID or --filter, not both")
+
+	err = app.Run([]string{app.Name, "alarms", "ack", "--filter", "(severity=ge=MAJOR;alarmAckUser==null"})
+	assert.ErrorContains(t, err, "Invalid FIQL expression, unbalanced parenthesis at position 1")
+
+	common.ConfirmInput = strings.NewReader("y\n")
+	logs, restore := test.CaptureLogs()
+	defer restore()
+	output, err := test.RunWithOutput(app, "table", "alarms", "ack", "--filter", filter, "--comment", "maintenance window", "--batch-size", "2", "--batch-delay", "1ms", "-w", "2")
+	assert.Error(t, err, "1 of 3 alarms failed")
+	assert.Assert(t, strings.Contains(logs.String(), "3 alarms match "+filter))
+	assert.Assert(t, strings.Contains(output, "10  acknowledged"))
+	assert.Assert(t, strings.Contains(output, "11  failed: "))
+	assert.Assert(t, strings.Contains(output, "12  acknowledged"))
+	assert.Equal(t, "ack=true&ackUser=admin", updates["/api/v2/alarms/10"])
+	assert.Equal(t, "ack=true&ackUser=admin", updates["/api/v2/alarms/12"])
+	assert.Equal(t, "body=maintenance+window&user=admin", updates["/api/v2/alarms/12/journal"])
+	_, ok := updates["/api/v2/alarms/11/journal"]
+	assert.Assert(t, !ok)
+}
+
+func TestClearMatchingAlarms(t *testing.T) {
+	updates := make(map[string]string)
+	lock := &sync.Mutex{}
+	app := createCli(CliCommand)
+	server := createBulkMockServer(t, updates, lock)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	_, err := test.RunWithOutput(app, "table", "alarms", "clear", "--yes", "--filter", "severity=ge=MAJOR;alarmAckUser==null", "--batch-delay", "1ms")
+	assert.Error(t, err, "1 of 3 alarms failed")
+	assert.Equal(t, "ackUser=admin&clear=true", updates["/api/v2/alarms/10"])
+	assert.Equal(t, 2, len(updates))
+}
@@ -0,0 +1,94 @@
+package alarms
+
+import (
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/urfave/cli"
+)
+
+// memoKind a type of memo that can be attached to an alarm
+type memoKind struct {
+	Name   string // The name used on the messages
+	Set    func(api api.AlarmsAPI, id int, user string, body string) error
+	Delete func(api api.AlarmsAPI, id int) error
+}
+
+var (
+	stickyMemo = memoKind{"Sticky memo", func(api api.AlarmsAPI, id int, user string, body string) error {
+		return api.SetStickyMemo(id, user, body)
+	}, func(api api.AlarmsAPI, id int) error {
+		return api.DeleteStickyMemo(id)
+	}}
+	journalMemo = memoKind{"Journal memo", func(api api.AlarmsAPI, id int, user string, body string) error {
+		return api.SetJournalMemo(id, user, body)
+	}, func(api api.AlarmsAPI, id int) error {
+		return api.DeleteJournalMemo(id)
+	}}
+)
+
+// Returns the subcommands to manage the given kind of memo
+func memoSubcommands(kind memoKind) []cli.Command {
+	return []cli.Command{
+		{
+			Name:      "set",
+			Usage:     "Adds or replaces the memo of an alarm",
+			ArgsUsage: "<id>",
+			Action:    setMemoCommand(kind),
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "body, b",
+					Usage: "The text of the memo",
+				},
+				cli.StringFlag{
+					Name:  "author, a",
+					Usage: "The author of the memo (defaults to the ReST user)",
+				},
+			},
+		},
+		{
+			Name:      "delete",
+			ShortName: "del",
+			Usage:     "Removes the memo of an alarm",
+			ArgsUsage: "<id>",
+			Action:    deleteMemoCommand(kind),
+		},
+	}
+}
+
+func setMemoCommand(kind memoKind) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		id, err := getAlarmID(c)
+		if err != nil {
+			return err
+		}
+		body := c.String("body")
+		if body == "" {
+			return fmt.Errorf("Memo body required")
+		}
+		author := c.String("author")
+		if author == "" {
+			author = common.GetClient(c).Username
+		}
+		if err := kind.Set(getAPI(c), id, author, body); err != nil {
+			return err
+		}
+		common.Log.Infof("%s of alarm %d set by %s", kind.Name, id, author)
+		return nil
+	}
+}
+
+func deleteMemoCommand(kind memoKind) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		id, err := getAlarmID(c)
+		if err != nil {
+			return err
+		}
+		if err := kind.Delete(getAPI(c), id); err != nil {
+			return err
+		}
+		common.Log.Infof("%s of alarm %d removed", kind.Name, id)
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+package alarms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestGetAlarm(t *testing.T) {
+	app := createCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "alarms", "get", "10")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, "logMessage: Node is down\n"))
+	assert.Assert(t, strings.Contains(output, "stickyMemo:\n  body: Waiting for the ISP\n  author: jdoe\n"))
+	assert.Assert(t, !strings.Contains(output, "journalMemo"))
+
+	_, err = test.RunWithOutput(app, "table", "alarms", "get")
+	assert.Error(t, err, "Alarm ID required")
+}
+
+func TestAlarmMemos(t *testing.T) {
+	app := createCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "alarms", "memo", "set", "10", "--body", "Waiting for the ISP")
+	assert.NilError(t, err)
+	assert.Equal(t, "Sticky memo of alarm 10 set by admin\n", logs.String())
+	logs.Reset()
+	assert.Equal(t, "body=Waiting+for+the+ISP&user=admin", lastMemo)
+
+	_, err = test.RunWithOutput(app, "table", "alarms", "journal", "set", "10", "-b", "Known issue", "--author", "jdoe")
+	assert.NilError(t, err)
+	assert.Equal(t, "Journal memo of alarm 10 set by jdoe\n", logs.String())
+	logs.Reset()
+	assert.Equal(t, "body=Known+issue&user=jdoe", lastMemo)
+
+	_, err = test.RunWithOutput(app, "table", "alarms", "memo", "set", "10")
+	assert.Error(t, err, "Memo body required")
+
+	_, err = test.RunWithOutput(app, "table", "alarms", "journal", "delete", "10")
+	assert.NilError(t, err)
+	assert.Equal(t, "Journal memo of alarm 10 removed\n", logs.String())
+	logs.Reset()
+	assert.Equal(t, "deleted", lastMemo)
+}
@@ -0,0 +1,120 @@
+package alarms
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+var (
+	createTicketAction = alarmAction{
+		Verb: "ticketed",
+		Apply: func(api api.AlarmsAPI, id int, user string) error {
+			return api.CreateTicket(id)
+		},
+		Skip: func(alarm model.OnmsAlarm) bool {
+			return alarm.TroubleTicketID != ""
+		},
+	}
+	updateTicketAction = alarmAction{Verb: "updated on the ticketer", Apply: func(api api.AlarmsAPI, id int, user string) error {
+		return api.UpdateTicket(id)
+	}}
+	closeTicketAction = alarmAction{Verb: "closed on the ticketer", Apply: func(api api.AlarmsAPI, id int, user string) error {
+		return api.CloseTicket(id)
+	}}
+)
+
+// ticketStatus the trouble ticket of an alarm
+type ticketStatus struct {
+	AlarmID  int    `json:"alarmId" yaml:"alarmId"`
+	TicketID string `json:"ticketId,omitempty" yaml:"ticketId,omitempty"`
+	State    string `json:"state,omitempty" yaml:"state,omitempty"`
+}
+
+// TicketsCliCommand the CLI command to manage the trouble tickets of alarms
+var TicketsCliCommand = cli.Command{
+	Name:  "tickets",
+	Usage: "Manage the trouble tickets of alarms, through the ticketer plugin configured on the server",
+	Before: func(c *cli.Context) error {
+		return common.GetClient(c).MinServerVersion(minAlarmsVersion, "Managing alarms through the ReST API v2")
+	},
+	Subcommands: []cli.Command{
+		{
+			Name:      "status",
+			Usage:     "Shows the trouble ticket ID and state of an alarm",
+			ArgsUsage: "<alarmId>",
+			Action:    showTicketStatus,
+		},
+		{
+			Name:      "create",
+			Usage:     "Creates the trouble ticket of an alarm, or of all the alarms matching a filter without a ticket",
+			ArgsUsage: "<alarmId>",
+			Action:    ticketActionCommand(createTicketAction),
+			Flags:     actionFlags(),
+		},
+		{
+			Name:      "update",
+			Usage:     "Updates the trouble ticket of an alarm, or of all the alarms matching a filter",
+			ArgsUsage: "<alarmId>",
+			Action:    ticketActionCommand(updateTicketAction),
+			Flags:     actionFlags(),
+		},
+		{
+			Name:      "close",
+			Usage:     "Closes the trouble ticket of an alarm, or of all the alarms matching a filter",
+			ArgsUsage: "<alarmId>",
+			Action:    ticketActionCommand(closeTicketAction),
+			Flags:     actionFlags(),
+		},
+	},
+}
+
+func showTicketStatus(c *cli.Context) error {
+	id, err := getAlarmID(c)
+	if err != nil {
+		return err
+	}
+	alarm, err := getAPI(c).GetAlarm(id)
+	if err != nil {
+		return err
+	}
+	status := ticketStatus{AlarmID: alarm.ID, TicketID: alarm.TroubleTicketID, State: alarm.TroubleTicketState}
+	table := common.NewTable("", "Alarm ID", "Ticket ID", "State")
+	if status.TicketID == "" {
+		table.AddRow(status.AlarmID, "None", "")
+	} else {
+		table.AddRow(status.AlarmID, status.TicketID, status.State)
+	}
+	return common.Print(status, table)
+}
+
+// Returns the command action that applies a ticket action, after verifying that the server has a ticketer
+func ticketActionCommand(action alarmAction) cli.ActionFunc {
+	run := alarmActionCommand(action)
+	return func(c *cli.Context) error {
+		if err := checkTicketerEnabled(c); err != nil {
+			return err
+		}
+		return run(c)
+	}
+}
+
+// Returns an error when the trouble ticketing integration is disabled on the server
+func checkTicketerEnabled(c *cli.Context) error {
+	data, err := common.GetClient(c).Get("/rest/info")
+	if err != nil {
+		return err
+	}
+	info := model.OnmsInfo{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+	if info.TicketerConfig == nil || !info.TicketerConfig.Enabled {
+		return fmt.Errorf("Trouble ticketing is disabled on the server; set opennms.alarmTroubleTicketEnabled=true and configure a ticketer plugin")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package alarms
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+// Starts a server with two alarms, where only the first one has a ticket; returns the requested ticket actions
+func createTicketsServer(t *testing.T, enabled bool) (*httptest.Server, func() []string) {
+	lock := sync.Mutex{}
+	actions := make([]string, 0)
+	alarms := &model.OnmsAlarmList{
+		Count:      2,
+		TotalCount: 2,
+		Alarms: []model.OnmsAlarm{
+			{ID: 10, Severity: "MAJOR", TroubleTicketID: "RT-100", TroubleTicketState: "OPEN"},
+			{ID: 11, Severity: "MAJOR"},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/rest/info":
+			info := model.OnmsInfo{Version: "26.0.0", TicketerConfig: &model.OnmsInfoTicketerConfig{Plugin: "RtTicketerPlugin", Enabled: enabled}}
+			bytes, _ := json.Marshal(info)
+			res.Write(bytes)
+		case req.URL.Path == "/api/v2/alarms":
+			list := *alarms
+			if req.URL.Query().Get("_s") == "alarm.id==11" {
+				list.Alarms = list.Alarms[1:]
+			}
+			bytes, _ := json.Marshal(list)
+			res.Write(bytes)
+		case strings.Contains(req.URL.Path, "/ticket/"):
+			assert.Equal(t, http.MethodPost, req.Method)
+			lock.Lock()
+			actions = append(actions, strings.TrimPrefix(req.URL.Path, "/api/v2/alarms/"))
+			lock.Unlock()
+			res.WriteHeader(http.StatusAccepted)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		sort.Strings(actions)
+		return actions
+	}
+}
+
+func TestTicketStatus(t *testing.T) {
+	app := createCli(TicketsCliCommand)
+	server, _ := createTicketsServer(t, false)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "tickets", "status", "10")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, "RT-100"))
+	assert.Assert(t, strings.Contains(output, "OPEN"))
+
+	output, err = test.RunWithOutput(app, "yaml", "tickets", "status", "11")
+	assert.NilError(t, err)
+	assert.Equal(t, "alarmId: 11\n", output)
+}
+
+func TestTicketActions(t *testing.T) {
+	app := createCli(TicketsCliCommand)
+	server, getActions := createTicketsServer(t, true)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	_, err := test.RunWithOutput(app, "table", "tickets", "create", "10")
+	assert.NilError(t, err)
+	_, err = test.RunWithOutput(app, "table", "tickets", "close", "10")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"10/ticket/close", "10/ticket/create"}, getActions())
+
+	// Only the alarms without a ticket are created, after confirmation
+	common.ConfirmInput = strings.NewReader("y\n")
+	defer func() { common.ConfirmInput = nil }()
+	logs, restore := test.CaptureLogs()
+	defer restore()
+	_, err = test.RunWithOutput(app, "table", "tickets", "create", "--filter", "alarm.severity==MAJOR", "--batch-delay", "0")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(logs.String(), "1 alarms match alarm.severity==MAJOR\n"))
+	assert.DeepEqual(t, []string{"10/ticket/close", "10/ticket/create", "11/ticket/create"}, getActions())
+}
+
+func TestTicketerDisabled(t *testing.T) {
+	app := createCli(TicketsCliCommand)
+	server, getActions := createTicketsServer(t, false)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	_, err := test.RunWithOutput(app, "table", "tickets", "update", "10")
+	assert.ErrorContains(t, err, "Trouble ticketing is disabled on the server")
+	assert.Equal(t, 0, len(getActions()))
+}
@@ -0,0 +1,257 @@
+package bsm
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/cli/daemon"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// reloadFlag the flag to reload Bsmd after a successful change
+var reloadFlag = cli.BoolFlag{
+	Name:  "reload",
+	Usage: "Request Bsmd to reload its configuration after the change",
+}
+
+// edgeFlags the flags for the map function and weight of an edge
+var edgeFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "map, m",
+		Value: model.BSMMapFunctions.Default,
+		Usage: "The map function: " + model.BSMMapFunctions.EnumAsString(),
+	},
+	cli.StringFlag{
+		Name:  "status, s",
+		Usage: "The status for the SetTo map function: " + model.BSMStatuses.EnumAsString(),
+	},
+	cli.IntFlag{
+		Name:  "weight, w",
+		Value: 1,
+		Usage: "The weight of the edge",
+	},
+	reloadFlag,
+}
+
+// friendlyNameFlag the flag for the name displayed for an edge
+var friendlyNameFlag = cli.StringFlag{
+	Name:  "friendly-name, n",
+	Usage: "The name displayed for the edge",
+}
+
+// CliCommand the CLI command to manage business services
+var CliCommand = cli.Command{
+	Name:  "bsm",
+	Usage: "Manage Business Services",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List all business services",
+			Action: listBusinessServices,
+		},
+		{
+			Name:      "get",
+			Usage:     "Gets a business service",
+			ArgsUsage: "<id>",
+			Action:    showBusinessService,
+		},
+		{
+			Name:      "apply",
+			Usage:     "Creates or updates a business service from a external YAML file; existing services are matched by ID or name",
+			ArgsUsage: "<yaml>",
+			Action:    applyBusinessService,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External YAML file (use '-' for STDIN Pipe)",
+				},
+				reloadFlag,
+			},
+		},
+		{
+			Name:      "delete",
+			ShortName: "del",
+			Usage:     "Deletes a business service",
+			ArgsUsage: "<id>",
+			Action:    deleteBusinessService,
+			Flags:     []cli.Flag{reloadFlag},
+		},
+		{
+			Name:  "edge",
+			Usage: "Manage the edges of a business service",
+			Subcommands: []cli.Command{
+				{
+					Name:  "add",
+					Usage: "Adds an edge to a business service",
+					Subcommands: []cli.Command{
+						{
+							Name:      "ip-service",
+							Usage:     "Adds an edge to a monitored service",
+							ArgsUsage: "<id> <ipServiceId>",
+							Action:    addIPServiceEdge,
+							Flags:     append(append([]cli.Flag{}, edgeFlags...), friendlyNameFlag),
+						},
+						{
+							Name:      "reduction-key",
+							Usage:     "Adds an edge to the alarms with a given reduction key",
+							ArgsUsage: "<id> <reductionKey>",
+							Action:    addReductionKeyEdge,
+							Flags:     append(append([]cli.Flag{}, edgeFlags...), friendlyNameFlag),
+						},
+						{
+							Name:      "child-service",
+							ShortName: "child",
+							Usage:     "Adds an edge to another business service",
+							ArgsUsage: "<id> <childId>",
+							Action:    addChildEdge,
+							Flags:     edgeFlags,
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func listBusinessServices(c *cli.Context) error {
+	list, err := getAPI(c).GetBusinessServices()
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no business services", "ID", "Name", "Status", "Reduce Function", "Edges")
+	for _, bs := range list {
+		table.AddRow(bs.ID, bs.Name, bs.OperationalStatus, bs.ReduceFunction.Type, bs.EdgeCount())
+	}
+	return common.Print(list, table)
+}
+
+func showBusinessService(c *cli.Context) error {
+	id, err := getID(c, 0, "Business service ID")
+	if err != nil {
+		return err
+	}
+	bs, err := getAPI(c).GetBusinessService(id)
+	if err != nil {
+		return err
+	}
+	return common.Print(bs, nil)
+}
+
+func applyBusinessService(c *cli.Context) error {
+	data, err := common.ReadInput(c, 0)
+	if err != nil {
+		return err
+	}
+	bs := &model.BusinessService{}
+	return common.ApplyYAML(data, bs, func() error {
+		if err := getAPI(c).SetBusinessService(*bs); err != nil {
+			return err
+		}
+		return reload(c)
+	})
+}
+
+func deleteBusinessService(c *cli.Context) error {
+	id, err := getID(c, 0, "Business service ID")
+	if err != nil {
+		return err
+	}
+	if err := getAPI(c).DeleteBusinessService(id); err != nil {
+		return err
+	}
+	return reload(c)
+}
+
+func addIPServiceEdge(c *cli.Context) error {
+	id, err := getID(c, 0, "Business service ID")
+	if err != nil {
+		return err
+	}
+	ipServiceID, err := getID(c, 1, "IP service ID")
+	if err != nil {
+		return err
+	}
+	edge := model.IPServiceEdge{
+		IPServiceID:  ipServiceID,
+		MapFunction:  getMapFunction(c),
+		Weight:       c.Int("weight"),
+		FriendlyName: c.String("friendly-name"),
+	}
+	if err := getAPI(c).AddIPServiceEdge(id, edge); err != nil {
+		return err
+	}
+	return reload(c)
+}
+
+func addReductionKeyEdge(c *cli.Context) error {
+	id, err := getID(c, 0, "Business service ID")
+	if err != nil {
+		return err
+	}
+	edge := model.ReductionKeyEdge{
+		ReductionKey: c.Args().Get(1),
+		MapFunction:  getMapFunction(c),
+		Weight:       c.Int("weight"),
+		FriendlyName: c.String("friendly-name"),
+	}
+	if err := getAPI(c).AddReductionKeyEdge(id, edge); err != nil {
+		return err
+	}
+	return reload(c)
+}
+
+func addChildEdge(c *cli.Context) error {
+	id, err := getID(c, 0, "Business service ID")
+	if err != nil {
+		return err
+	}
+	childID, err := getID(c, 1, "Child business service ID")
+	if err != nil {
+		return err
+	}
+	edge := model.ChildEdge{
+		ChildID:     childID,
+		MapFunction: getMapFunction(c),
+		Weight:      c.Int("weight"),
+	}
+	if err := getAPI(c).AddChildEdge(id, edge); err != nil {
+		return err
+	}
+	return reload(c)
+}
+
+func getMapFunction(c *cli.Context) model.BSMFunction {
+	function := model.BSMFunction{Type: c.String("map")}
+	if status := c.String("status"); status != "" {
+		function.Properties = map[string]string{"status": status}
+	}
+	return function
+}
+
+func getID(c *cli.Context, index int, name string) (int, error) {
+	value := c.Args().Get(index)
+	if value == "" {
+		return 0, fmt.Errorf("%s required", name)
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("Invalid %s %s", name, value)
+	}
+	return id, nil
+}
+
+// Requests Bsmd to reload its configuration when the reload flag is present
+func reload(c *cli.Context) error {
+	if c.Bool("reload") {
+		return services.GetEventsAPI(common.GetClient(c)).SendEvent(daemon.ReloadEvent("bsmd", ""))
+	}
+	return nil
+}
+
+func getAPI(c *cli.Context) api.BusinessServicesAPI {
+	return services.GetBusinessServicesAPI(common.GetClient(c))
+}
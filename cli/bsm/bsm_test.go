@@ -0,0 +1,133 @@
+package bsm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+
+	"gotest.tools/assert"
+)
+
+type mockServer struct {
+	*httptest.Server
+	requests []string
+	bodies   map[string]map[string]interface{}
+	events   []model.Event
+}
+
+func createMockServer(t *testing.T) *mockServer {
+	mock := &mockServer{bodies: make(map[string]map[string]interface{})}
+	mock.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		request := req.Method + " " + req.URL.Path
+		mock.requests = append(mock.requests, request)
+		switch request {
+		case "GET /api/v2/business-services":
+			bytes, _ := json.Marshal(model.BusinessServiceList{Services: []model.ResourceLocation{{Location: "/api/v2/business-services/1"}}})
+			res.Write(bytes)
+		case "GET /api/v2/business-services/1":
+			bytes, _ := json.Marshal(model.BusinessService{
+				ID:                1,
+				Name:              "Web Portal",
+				ReduceFunction:    model.BSMFunction{Type: "HighestSeverity"},
+				OperationalStatus: "Normal",
+				ReductionKeyEdges: []model.ReductionKeyEdge{{ID: 2, ReductionKey: "uei.opennms.org/nodes/nodeDown::1", Weight: 1}},
+			})
+			res.Write(bytes)
+		case "POST /rest/events":
+			event := model.Event{}
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			json.Unmarshal(bytes, &event)
+			mock.events = append(mock.events, event)
+		case "POST /api/v2/business-services", "PUT /api/v2/business-services/1", "POST /api/v2/business-services/1/ip-service-edge", "POST /api/v2/business-services/1/child-edge":
+			body := make(map[string]interface{})
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(bytes, &body))
+			mock.bodies[request] = body
+			res.WriteHeader(http.StatusNoContent)
+		case "DELETE /api/v2/business-services/1":
+			res.WriteHeader(http.StatusNoContent)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return mock
+}
+
+func TestListBusinessServices(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "bsm", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `ID  Name        Status  Reduce Function  Edges
+1   Web Portal  Normal  HighestSeverity  1
+`, output)
+
+	_, err = test.RunWithOutput(app, "yaml", "bsm", "get")
+	assert.Error(t, err, "Business service ID required")
+
+	_, err = test.RunWithOutput(app, "yaml", "bsm", "get", "web")
+	assert.Error(t, err, "Invalid Business service ID web")
+
+	output, err = test.RunWithOutput(app, "jsonpath=$.reduction-key-edges[0].reduction-key", "bsm", "get", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, "uei.opennms.org/nodes/nodeDown::1\n", output)
+}
+
+func TestApplyBusinessService(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err := app.Run([]string{app.Name, "bsm", "apply", "name: Database\nreduceFunction:\n  type: Threshold\n  properties:\n    threshold: '2'"})
+	assert.ErrorContains(t, err, "requires a threshold property greater than 0")
+
+	err = app.Run([]string{app.Name, "bsm", "apply", "--reload", "name: Database\nreduceFunction:\n  type: Threshold\n  properties:\n    threshold: '0.5'"})
+	assert.NilError(t, err)
+	body := server.bodies["POST /api/v2/business-services"]
+	assert.Equal(t, "Database", body["name"])
+	assert.Equal(t, 1, len(server.events))
+	assert.Equal(t, "Bsmd", server.events[0].Parameters[0].Value)
+
+	err = app.Run([]string{app.Name, "bsm", "apply", "name: Web Portal"})
+	assert.NilError(t, err)
+	assert.Assert(t, server.bodies["PUT /api/v2/business-services/1"] != nil)
+}
+
+func TestAddEdges(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err := app.Run([]string{app.Name, "bsm", "edge", "add", "ip-service", "1"})
+	assert.Error(t, err, "IP service ID required")
+
+	err = app.Run([]string{app.Name, "bsm", "edge", "add", "ip-service", "-m", "SetTo", "1", "10"})
+	assert.ErrorContains(t, err, "The SetTo map function requires a status property")
+
+	err = app.Run([]string{app.Name, "bsm", "edge", "add", "ip-service", "-m", "SetTo", "-s", "Major", "-w", "2", "1", "10"})
+	assert.NilError(t, err)
+	body := server.bodies["POST /api/v2/business-services/1/ip-service-edge"]
+	assert.Equal(t, float64(10), body["ip-service-id"])
+	assert.Equal(t, float64(2), body["weight"])
+	assert.DeepEqual(t, map[string]interface{}{"type": "SetTo", "properties": map[string]interface{}{"status": "Major"}}, body["map-function"])
+
+	err = app.Run([]string{app.Name, "bsm", "edge", "add", "child", "1", "3"})
+	assert.NilError(t, err)
+	assert.Equal(t, float64(3), server.bodies["POST /api/v2/business-services/1/child-edge"]["child-id"])
+
+	err = app.Run([]string{app.Name, "bsm", "delete", "--reload", "1"})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(server.events))
+}
@@ -0,0 +1,177 @@
+package categories
+
+import (
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// nodeFlag the flag to choose the node of an association
+var nodeFlag = cli.StringFlag{
+	Name:  "node, n",
+	Usage: "The node ID or foreignSource:foreignID (e.x. Servers:web01)",
+}
+
+// The note about the changes made to nodes that belong to a requisition
+const requisitionNote = "Nodes from requisitions get the categories of the requisition on the next import, so use 'inv category add' to keep the change."
+
+// CliCommand the CLI command to manage categories from the OpenNMS database
+var CliCommand = cli.Command{
+	Name:  "categories",
+	Usage: "Manage the categories from the OpenNMS database, used by surveillance views (not the categories of requisitions)",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List all categories",
+			Action: listCategories,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "A FIQL expression to filter categories (e.x. 'name==Prod*')",
+				},
+			},
+		},
+		{
+			Name:      "add",
+			Usage:     "Adds a category",
+			ArgsUsage: "<name>",
+			Action:    addCategory,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "description, d",
+					Usage: "Category Description",
+				},
+			},
+		},
+		{
+			Name:         "delete",
+			ShortName:    "del",
+			Usage:        "Deletes a category",
+			ArgsUsage:    "<name>",
+			Action:       deleteCategory,
+			BashComplete: categoryNameBashComplete,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Remove the category from its nodes when it is assigned to any of them",
+				},
+			},
+		},
+		{
+			Name:         "nodes",
+			Usage:        "List the nodes that belong to a category",
+			ArgsUsage:    "<category>",
+			Action:       listNodes,
+			BashComplete: categoryNameBashComplete,
+		},
+		{
+			Name:         "assign",
+			Usage:        "Adds a category to a node",
+			ArgsUsage:    "<category>",
+			Description:  requisitionNote,
+			Action:       assignCategory,
+			BashComplete: categoryNameBashComplete,
+			Flags:        []cli.Flag{nodeFlag},
+		},
+		{
+			Name:         "unassign",
+			Usage:        "Removes a category from a node",
+			ArgsUsage:    "<category>",
+			Description:  requisitionNote,
+			Action:       unassignCategory,
+			BashComplete: categoryNameBashComplete,
+			Flags:        []cli.Flag{nodeFlag},
+		},
+	},
+}
+
+func listCategories(c *cli.Context) error {
+	if err := fiql.Validate(c.String("filter")); err != nil {
+		return err
+	}
+	list, err := getAPI(c).GetCategories(c.String("filter"))
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no categories", "ID", "Name", "Description")
+	for _, cat := range list.Categories {
+		table.AddRow(cat.ID, cat.Name, cat.Description)
+	}
+	return common.Print(list.Categories, table)
+}
+
+func addCategory(c *cli.Context) error {
+	category := &model.OnmsCategory{
+		Name:        c.Args().First(),
+		Description: c.String("description"),
+	}
+	return common.Apply(category, func() error {
+		return getAPI(c).AddCategory(category)
+	})
+}
+
+func deleteCategory(c *cli.Context) error {
+	name := c.Args().First()
+	removed, err := getAPI(c).DeleteCategory(name, c.Bool("force"))
+	if removed > 0 {
+		common.Log.Infof("Category %s removed from %d nodes", name, removed)
+	}
+	if err != nil {
+		return err
+	}
+	common.Log.Infof("Category %s deleted", name)
+	return nil
+}
+
+func listNodes(c *cli.Context) error {
+	category := c.Args().First()
+	list, err := getAPI(c).GetNodes(category)
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no nodes on category "+category, "ID", "Label", "Foreign Source", "Foreign ID", "Location")
+	for _, n := range list.Nodes {
+		table.AddRow(n.ID, n.Label, n.ForeignSource, n.ForeignID, n.Location)
+	}
+	return common.Print(list.Nodes, table)
+}
+
+func assignCategory(c *cli.Context) error {
+	category := c.Args().First()
+	if err := getAPI(c).AssignCategory(category, c.String("node")); err != nil {
+		return err
+	}
+	common.Log.Infof("Category %s added to node %s", category, c.String("node"))
+	return nil
+}
+
+func unassignCategory(c *cli.Context) error {
+	category := c.Args().First()
+	if err := getAPI(c).UnassignCategory(category, c.String("node")); err != nil {
+		return err
+	}
+	common.Log.Infof("Category %s removed from node %s", category, c.String("node"))
+	return nil
+}
+
+func categoryNameBashComplete(c *cli.Context) {
+	if c.NArg() > 0 {
+		return
+	}
+	list, err := getAPI(c).GetCategories("")
+	if err != nil {
+		return
+	}
+	for _, cat := range list.Categories {
+		fmt.Println(cat.Name)
+	}
+}
+
+func getAPI(c *cli.Context) api.CategoriesAPI {
+	return services.GetCategoriesAPI(common.GetClient(c))
+}
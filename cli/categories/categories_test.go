@@ -0,0 +1,153 @@
+package categories
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+
+	"gotest.tools/assert"
+)
+
+var mockCategories = model.OnmsCategoryList{Count: 2, TotalCount: 2, Categories: []model.OnmsCategory{
+	{ID: 1, Name: "Routers", Description: "Core routers"},
+	{ID: 2, Name: "Servers"},
+}}
+
+var mockNodes = model.OnmsNodeList{Count: 2, TotalCount: 2, Nodes: []model.OnmsNode{
+	{ID: "1", Label: "rtr01", ForeignSource: "Network", ForeignID: "rtr01", Categories: []model.OnmsCategory{{ID: 1, Name: "Routers"}}},
+	{ID: "4", Label: "rtr02", Categories: []model.OnmsCategory{{ID: 1, Name: "Routers"}}},
+}}
+
+func sendJSON(res http.ResponseWriter, data interface{}) {
+	bytes, _ := json.Marshal(data)
+	res.Header().Set("Content-Type", "application/json")
+	res.Write(bytes)
+}
+
+// Creates a server with the mock categories and nodes, recording the requests that modify them
+func createTestServer(t *testing.T, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/v2/categories":
+			filter := req.URL.Query().Get("_s")
+			list := model.OnmsCategoryList{}
+			for _, cat := range mockCategories.Categories {
+				if filter == "" || filter == "name=="+cat.Name {
+					list.Categories = append(list.Categories, cat)
+				}
+			}
+			if len(list.Categories) == 0 {
+				res.WriteHeader(http.StatusNoContent)
+				return
+			}
+			sendJSON(res, list)
+		case req.Method == http.MethodGet && req.URL.Path == "/api/v2/nodes":
+			switch req.URL.Query().Get("_s") {
+			case "category.name==Routers":
+				sendJSON(res, mockNodes)
+			case "id==1":
+				sendJSON(res, model.OnmsNodeList{Count: 1, TotalCount: 1, Nodes: mockNodes.Nodes[:1]})
+			default:
+				res.WriteHeader(http.StatusNoContent)
+			}
+		case req.Method == http.MethodPost || req.Method == http.MethodDelete:
+			data, _ := ioutil.ReadAll(req.Body)
+			*requests = append(*requests, req.Method+" "+req.URL.Path+" "+string(data))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestListCategories(t *testing.T) {
+	requests := make([]string, 0)
+	server := createTestServer(t, &requests)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	output, err := test.RunWithOutput(app, "table", "categories", "list")
+	assert.NilError(t, err)
+	assert.Assert(t, bytes.Contains([]byte(output), []byte("Core routers")), output)
+
+	output, err = test.RunWithOutput(app, "json", "categories", "nodes", "Routers")
+	assert.NilError(t, err)
+	nodes := []model.OnmsNode{}
+	assert.NilError(t, json.Unmarshal([]byte(output), &nodes))
+	assert.Equal(t, 2, len(nodes))
+	assert.Equal(t, "rtr02", nodes[1].Label)
+
+	output, err = test.RunWithOutput(app, "table", "categories", "nodes", "Servers")
+	assert.NilError(t, err)
+	assert.Assert(t, bytes.Contains([]byte(output), []byte("There are no nodes on category Servers")), output)
+}
+
+func TestAddCategory(t *testing.T) {
+	requests := make([]string, 0)
+	server := createTestServer(t, &requests)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	_, err := test.RunWithOutput(app, "table", "categories", "add", "-d", "Access switches", "Switches")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{`POST /api/v2/categories {"name":"Switches","description":"Access switches"}`}, requests)
+
+	_, err = test.RunWithOutput(app, "table", "categories", "add", "Servers")
+	assert.Error(t, err, "Category Servers already exists")
+}
+
+func TestDeleteCategory(t *testing.T) {
+	requests := make([]string, 0)
+	server := createTestServer(t, &requests)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	_, err := test.RunWithOutput(app, "table", "categories", "delete", "Routers")
+	assert.Error(t, err, "Category Routers is assigned to 2 nodes; use --force to remove the associations and delete it")
+	assert.Equal(t, 0, len(requests))
+
+	_, err = test.RunWithOutput(app, "table", "categories", "delete", "--force", "Routers")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{
+		"DELETE /api/v2/nodes/1/categories/1 ",
+		"DELETE /api/v2/nodes/4/categories/1 ",
+		"DELETE /api/v2/categories/1 ",
+	}, requests)
+	assert.Equal(t, "Category Routers removed from 2 nodes\nCategory Routers deleted\n", logs.String())
+}
+
+func TestAssignCategory(t *testing.T) {
+	requests := make([]string, 0)
+	server := createTestServer(t, &requests)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	_, err := test.RunWithOutput(app, "table", "categories", "assign", "--node", "1", "Servers")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{`POST /api/v2/nodes/1/categories {"id":2,"name":"Servers"}`}, requests)
+
+	_, err = test.RunWithOutput(app, "table", "categories", "assign", "--node", "1", "Routers")
+	assert.Error(t, err, "Node 1 (rtr01) already belongs to category Routers")
+
+	_, err = test.RunWithOutput(app, "table", "categories", "unassign", "--node", "1", "Routers")
+	assert.NilError(t, err)
+	assert.Equal(t, "DELETE /api/v2/nodes/1/categories/1 ", requests[1])
+
+	_, err = test.RunWithOutput(app, "table", "categories", "unassign", "--node", "9", "Routers")
+	assert.Error(t, err, "Node 9 doesn't exist")
+
+	_, err = test.RunWithOutput(app, "table", "categories", "assign", "Routers")
+	assert.Error(t, err, "Node ID or foreignSource:foreignID required")
+}
@@ -0,0 +1,77 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/urfave/cli"
+)
+
+// The completion scripts; %[1]s is replaced with the name of the application
+var scripts = map[string]string{
+	"bash": `# bash completion for %[1]s
+# Load it with: source <(%[1]s completion bash)
+_%[1]s_complete() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    local opts
+    opts=$("${COMP_WORDS[0]}" "${COMP_WORDS[@]:1:$((COMP_CWORD-1))}" --generate-bash-completion 2>/dev/null)
+    COMPREPLY=($(compgen -W "${opts}" -- "${cur}"))
+    return 0
+}
+complete -o default -F _%[1]s_complete %[1]s
+`,
+	"zsh": `#compdef %[1]s
+# zsh completion for %[1]s
+# Load it with: source <(%[1]s completion zsh)
+_%[1]s() {
+    local -a opts
+    opts=("${(@f)$(${words[1]} ${words[2,CURRENT-1]} --generate-bash-completion 2>/dev/null)}")
+    opts=("${(@)opts//:/\\:}")
+    if [[ -n "${opts[*]}" ]]; then
+        _describe 'values' opts
+    else
+        _files
+    fi
+}
+compdef _%[1]s %[1]s
+`,
+	"fish": `# fish completion for %[1]s
+# Load it with: %[1]s completion fish | source
+function __%[1]s_complete
+    set -l args (commandline -opc)
+    $args --generate-bash-completion 2>/dev/null
+end
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`,
+}
+
+// Shells the shells with completion scripts
+var Shells = []string{"bash", "zsh", "fish"}
+
+// CliCommand the CLI command to generate shell completion scripts
+var CliCommand = cli.Command{
+	Name:      "completion",
+	Usage:     "Generates the shell completion script for bash, zsh or fish",
+	ArgsUsage: "<" + strings.Join(Shells, "|") + ">",
+	Action:    showCompletionScript,
+	BashComplete: func(c *cli.Context) {
+		if c.NArg() == 0 {
+			common.PrintCompletions(Shells...)
+		}
+	},
+}
+
+func showCompletionScript(c *cli.Context) error {
+	shell := c.Args().First()
+	if shell == "" {
+		return fmt.Errorf("Shell required, valid options: %s", strings.Join(Shells, ", "))
+	}
+	script, ok := scripts[shell]
+	if !ok {
+		return fmt.Errorf("Invalid shell %s, valid options: %s", shell, strings.Join(Shells, ", "))
+	}
+	_, err := fmt.Fprintf(common.Output, script, c.App.Name)
+	return err
+}
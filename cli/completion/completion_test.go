@@ -0,0 +1,36 @@
+package completion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+
+	output, err := test.RunWithOutput(app, "table", "completion", "bash")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, "complete -o default -F _onmsctl_complete onmsctl\n"))
+
+	output, err = test.RunWithOutput(app, "table", "completion", "zsh")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(output, "#compdef onmsctl\n"))
+	assert.Assert(t, strings.Contains(output, "compdef _onmsctl onmsctl\n"))
+
+	output, err = test.RunWithOutput(app, "table", "completion", "fish")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, "complete -c onmsctl -f -a '(__onmsctl_complete)'\n"))
+
+	_, err = test.RunWithOutput(app, "table", "completion", "powershell")
+	assert.Error(t, err, "Invalid shell powershell, valid options: bash, zsh, fish")
+	_, err = test.RunWithOutput(app, "table", "completion")
+	assert.Error(t, err, "Shell required, valid options: bash, zsh, fish")
+
+	app.EnableBashCompletion = true
+	output, err = test.RunWithOutput(app, "table", "completion", "--generate-bash-completion")
+	assert.NilError(t, err)
+	assert.Equal(t, "bash\nzsh\nfish\n", output)
+}
@@ -0,0 +1,462 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// CliCommand the CLI command to manage the onmsctl configuration
+var CliCommand = cli.Command{
+	Name:  "config",
+	Usage: "Manage the onmsctl configuration",
+	Subcommands: []cli.Command{
+		{
+			Name:   "view",
+			Usage:  "Shows the effective configuration, after applying the profile, the environment variables and the flags (passwords, including the ones of proxies, are masked)",
+			Action: viewConfig,
+		},
+		{
+			Name:   "validate",
+			Usage:  "Validates the configuration file; unknown keys, invalid URLs or unreadable certificates are errors",
+			Action: validateConfig,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "ping",
+					Usage: "Verify that the server of each profile is reachable, and show its version and the proxy used to reach it",
+				},
+			},
+		},
+		{
+			Name:  "profile",
+			Usage: "Manage server profiles",
+			Subcommands: []cli.Command{
+				{
+					Name:   "list",
+					Usage:  "List all the server profiles",
+					Action: listProfiles,
+				},
+				{
+					Name:      "set",
+					Usage:     "Adds or updates a server profile; unset fields are inherited from the default profile",
+					ArgsUsage: "<name>",
+					Action:    setProfile,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "url",
+							Usage: "OpenNMS Base URL",
+						},
+						cli.StringFlag{
+							Name:  "user, u",
+							Usage: "OpenNMS Username (with ROLE_REST or ROLE_ADMIN)",
+						},
+						cli.StringFlag{
+							Name:  "passwd, p",
+							Usage: "OpenNMS User's Password",
+						},
+						cli.StringFlag{
+							Name:  "password-source",
+							Usage: "Where the password is obtained from instead of storing it: keyring, env:VARNAME or cmd:command (empty to use the password)",
+						},
+						cli.IntFlag{
+							Name:  "timeout, t",
+							Usage: "Connection Timeout in Seconds",
+						},
+						cli.BoolFlag{
+							Name:  "insecure, k",
+							Usage: "Skips HTTPS certificate validation (e.x. self-signed certificates)",
+						},
+						cli.StringFlag{
+							Name:  "ca-cert",
+							Usage: "PEM file with the CA certificates trusted to sign the server certificate",
+						},
+						cli.StringFlag{
+							Name:  "client-cert",
+							Usage: "PEM file with the client certificate for mutual TLS",
+						},
+						cli.StringFlag{
+							Name:  "client-key",
+							Usage: "PEM file with the private key of the client certificate",
+						},
+						cli.BoolFlag{
+							Name:  "production",
+							Usage: "Mark the server as production, so disruptive operations (e.x. turning notifications off) ask for confirmation",
+						},
+						cli.BoolFlag{
+							Name:  "strict-decode",
+							Usage: "Fail when a response of the server has unknown fields or misses required ones (use --strict-decode=false to disable it)",
+						},
+						cli.StringFlag{
+							Name:  "event-sink",
+							Usage: "Where events are sent: " + strings.Join(services.EventSinkNames(), ", "),
+						},
+						cli.StringSliceFlag{
+							Name:  "event-brokers",
+							Usage: "A Kafka bootstrap server as host:port for the events",
+						},
+						cli.StringFlag{
+							Name:  "event-topic",
+							Usage: "The Kafka topic for the events",
+						},
+						cli.BoolFlag{
+							Name:  "event-tls",
+							Usage: "Connect to the Kafka brokers with TLS (use --event-tls=false to disable it)",
+						},
+						cli.StringFlag{
+							Name:  "event-ca-cert",
+							Usage: "Path to a PEM bundle with the CAs trusted to sign the certificates of the Kafka brokers",
+						},
+						cli.StringFlag{
+							Name:  "event-sasl",
+							Usage: "The SASL mechanism for the Kafka brokers: plain, scram-sha-256 or scram-sha-512 (empty to disable it)",
+						},
+						cli.StringFlag{
+							Name:  "event-username",
+							Usage: "The SASL username for the Kafka brokers",
+						},
+						cli.StringFlag{
+							Name:  "event-password",
+							Usage: "The SASL password for the Kafka brokers",
+						},
+						cli.StringFlag{
+							Name:  "proxy",
+							Usage: "Proxy for the server, as http(s)://[user:password@]host:port or socks5://[user:password@]host:port; empty to use the environment",
+						},
+						cli.BoolFlag{
+							Name:  "no-proxy",
+							Usage: "Connect directly to the server, ignoring the proxies from the environment",
+						},
+					},
+				},
+				{
+					Name:      "delete",
+					ShortName: "del",
+					Usage:     "Deletes a server profile",
+					ArgsUsage: "<name>",
+					Action:    deleteProfile,
+				},
+				{
+					Name:      "use",
+					Usage:     "Sets the server profile used by default",
+					ArgsUsage: "<name>",
+					Action:    useProfile,
+				},
+			},
+		},
+		{
+			Name:      "set-password",
+			Usage:     "Stores the password of a server profile on the OS keychain, and sets its password source to keyring",
+			ArgsUsage: "<name>",
+			Description: "The password is asked without echoing it, or read from STDIN when it is not a terminal;\n" +
+				"   the password stored on the configuration file for the profile is removed.",
+			Action: setPassword,
+		},
+	},
+}
+
+// The string displayed instead of passwords
+const maskedPassword = "********"
+
+// effectiveConfig the settings used by the commands, and where they come from
+type effectiveConfig struct {
+	File        string `json:"file" yaml:"file"`
+	Profile     string `json:"profile" yaml:"profile"`
+	rest.Client `yaml:",inline"`
+}
+
+func viewConfig(c *cli.Context) error {
+	client := common.GetClient(c)
+	profile := client.ProfileName
+	if profile == "" {
+		profile = rest.DefaultProfile
+	}
+	cfg := effectiveConfig{File: common.ConfigFile(), Profile: profile, Client: *client}
+	if cfg.Password != "" {
+		cfg.Password = maskedPassword
+	}
+	if cfg.EventSink.Password != "" {
+		cfg.EventSink.Password = maskedPassword
+	}
+	cfg.Proxy = cfg.Proxy.Mask(maskedPassword)
+	cfg.ServerVersion = nil
+	return common.Print(cfg, nil)
+}
+
+func validateConfig(c *cli.Context) error {
+	file := common.ConfigFile()
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		common.Log.Infof("Configuration file %s doesn't exist; the built-in settings are used", file)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cfg, err := rest.ParseConfig(data)
+	if err != nil {
+		return common.ValidationError(fmt.Errorf("Invalid configuration file %s: %w", file, err))
+	}
+	problems := cfg.Validate()
+	for _, p := range problems {
+		common.Log.Errorf("%s", p)
+	}
+	if len(problems) > 0 {
+		return common.ValidationError(fmt.Errorf("Configuration file %s has %d problems", file, len(problems)))
+	}
+	common.Log.Infof("Configuration file %s is valid", file)
+	if !c.Bool("ping") {
+		return nil
+	}
+	return pingProfiles(*cfg)
+}
+
+// pingResult the outcome of contacting the server of a profile
+type pingResult struct {
+	Profile string `json:"profile" yaml:"profile"`
+	URL     string `json:"url" yaml:"url"`
+	Proxy   string `json:"proxy" yaml:"proxy"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Obtains the version of the server of each profile, to verify the URL and the credentials
+func pingProfiles(cfg rest.Config) error {
+	results := make([]pingResult, 0)
+	table := common.NewTable("", "Profile", "URL", "Proxy", "Status")
+	failed := 0
+	for _, name := range cfg.ProfileNames() {
+		client, err := cfg.GetProfile(name)
+		if err != nil {
+			return err
+		}
+		client.Retries = 0
+		result := pingResult{Profile: name, URL: client.URL, Proxy: client.DescribeProxy()}
+		status := ""
+		if version, err := client.GetServerVersion(); err != nil {
+			result.Error = err.Error()
+			status = "ERROR: " + result.Error
+			failed++
+		} else {
+			result.Version = version.String()
+			status = "OK, OpenNMS " + result.Version
+		}
+		results = append(results, result)
+		table.AddRow(name, client.URL, result.Proxy, status)
+	}
+	if err := common.Print(results, table); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d profiles are unreachable", failed, len(results))
+	}
+	return nil
+}
+
+// profileSummary what the profile list shows of each profile, without the credentials
+type profileSummary struct {
+	Name     string `json:"name" yaml:"name"`
+	URL      string `json:"url" yaml:"url"`
+	Username string `json:"username" yaml:"username"`
+	Active   bool   `json:"active" yaml:"active"`
+}
+
+func listProfiles(c *cli.Context) error {
+	cfg, err := common.ReadConfig()
+	if err != nil {
+		return err
+	}
+	active := cfg.Profile
+	if active == "" {
+		active = rest.DefaultProfile
+	}
+	profiles := make([]profileSummary, 0)
+	table := common.NewTable("There are no profiles", "Profile", "URL", "Username", "Active")
+	for _, name := range cfg.ProfileNames() {
+		client, err := cfg.GetProfile(name)
+		if err != nil {
+			return err
+		}
+		profile := profileSummary{name, client.URL, client.Username, name == active}
+		profiles = append(profiles, profile)
+		flag := ""
+		if profile.Active {
+			flag = "*"
+		}
+		table.AddRow(name, client.URL, client.Username, flag)
+	}
+	return common.Print(profiles, table)
+}
+
+func setProfile(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("Profile name required")
+	}
+	cfg, err := common.ReadConfig()
+	if err != nil {
+		return err
+	}
+	client := cfg.Client
+	if name != rest.DefaultProfile {
+		client = cfg.Profiles[name]
+	}
+	if c.IsSet("url") {
+		client.URL = c.String("url")
+	}
+	if c.IsSet("user") {
+		client.Username = c.String("user")
+	}
+	if c.IsSet("passwd") {
+		client.Password = c.String("passwd")
+		client.PasswordSource = ""
+	}
+	if c.IsSet("password-source") {
+		source := c.String("password-source")
+		if err := rest.ValidatePasswordSource(source); err != nil {
+			return err
+		}
+		client.PasswordSource = source
+		if source != "" {
+			client.Password = "" // Replaced by the source
+		}
+	}
+	if c.IsSet("timeout") {
+		client.Timeout = c.Int("timeout")
+	}
+	if c.IsSet("insecure") {
+		client.Insecure = c.Bool("insecure")
+	}
+	if c.IsSet("ca-cert") {
+		client.CACert = c.String("ca-cert")
+	}
+	if c.IsSet("client-cert") {
+		client.ClientCert = c.String("client-cert")
+	}
+	if c.IsSet("client-key") {
+		client.ClientKey = c.String("client-key")
+	}
+	if c.IsSet("production") {
+		client.Production = c.Bool("production")
+	}
+	if c.IsSet("strict-decode") {
+		client.StrictDecode = c.Bool("strict-decode")
+	}
+	if c.IsSet("event-sink") {
+		via := c.String("event-sink")
+		if err := services.ValidateEventSink(via); err != nil {
+			return err
+		}
+		client.EventSink.Via = via
+	}
+	if c.IsSet("event-brokers") {
+		client.EventSink.Brokers = c.StringSlice("event-brokers")
+	}
+	if c.IsSet("event-topic") {
+		client.EventSink.Topic = c.String("event-topic")
+	}
+	if c.IsSet("event-tls") {
+		client.EventSink.TLS = c.Bool("event-tls")
+	}
+	if c.IsSet("event-ca-cert") {
+		client.EventSink.CACert = c.String("event-ca-cert")
+	}
+	if c.IsSet("event-sasl") {
+		client.EventSink.SASL = c.String("event-sasl")
+	}
+	if c.IsSet("event-username") {
+		client.EventSink.Username = c.String("event-username")
+	}
+	if c.IsSet("event-password") {
+		client.EventSink.Password = c.String("event-password")
+	}
+	if c.IsSet("proxy") {
+		client.Proxy = rest.ProxySettings{}
+		if value := c.String("proxy"); value != "" {
+			if client.Proxy, err = rest.ParseProxy(value); err != nil {
+				return err
+			}
+		}
+	}
+	if c.IsSet("no-proxy") {
+		client.NoProxy = c.Bool("no-proxy")
+	}
+	if err := client.ValidateTLS(); err != nil {
+		return err
+	}
+	if client.URL == "" && name != rest.DefaultProfile {
+		return fmt.Errorf("URL required for profile %s", name)
+	}
+	if err := cfg.SetProfile(name, client); err != nil {
+		return err
+	}
+	// Keeps the flags set to false or 0, so they override the settings of the default profile
+	for flag, key := range map[string]string{"timeout": "timeout", "insecure": "insecure", "production": "production", "strict-decode": "strictDecode", "no-proxy": "noProxy"} {
+		if c.IsSet(flag) {
+			cfg.MarkDefined(name, key)
+		}
+	}
+	return common.SaveConfig(*cfg)
+}
+
+func deleteProfile(c *cli.Context) error {
+	cfg, err := common.ReadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.DeleteProfile(c.Args().First()); err != nil {
+		return err
+	}
+	return common.SaveConfig(*cfg)
+}
+
+func useProfile(c *cli.Context) error {
+	cfg, err := common.ReadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.UseProfile(c.Args().First()); err != nil {
+		return err
+	}
+	return common.SaveConfig(*cfg)
+}
+
+func setPassword(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("Profile name required")
+	}
+	cfg, err := common.ReadConfig()
+	if err != nil {
+		return err
+	}
+	client := cfg.Client
+	if name != rest.DefaultProfile {
+		profile, ok := cfg.Profiles[name]
+		if !ok {
+			return fmt.Errorf("Profile %s doesn't exist", name)
+		}
+		client = profile
+	}
+	password, err := common.PromptSecret(fmt.Sprintf("Password of profile %s: ", name))
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return fmt.Errorf("Password cannot be empty")
+	}
+	if err := rest.DefaultKeyring.Set(rest.KeyringService, name, password); err != nil {
+		return fmt.Errorf("Cannot store the password of profile %s: %w", name, err)
+	}
+	client.Password = ""
+	client.PasswordSource = rest.PasswordSourceKeyring
+	if err := cfg.SetProfile(name, client); err != nil {
+		return err
+	}
+	return common.SaveConfig(*cfg)
+}
@@ -2,11 +2,12 @@ package daemon
 
 import (
 	"fmt"
-	"sort"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
-	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/OpenNMS/onmsctl/services"
 	"github.com/urfave/cli"
 )
@@ -14,37 +15,112 @@ import (
 // CorrelatorPrefix the prefix for correlation engines
 const CorrelatorPrefix = "correlation"
 
-// DaemonMap a map with reloadable daemons
-var DaemonMap = map[string]string{
-	"ackd":                               "Ackd",
-	"alarmd":                             "alarmd",
-	"bsmd":                               "Bsmd",
-	"collectd":                           "Collectd",
-	CorrelatorPrefix:                     "DroolsCorrelationEngine", // Append engine name
-	"discoverd":                          "Discovery",
-	"enlinkd":                            "Enlinkd",
-	"eventd":                             "Eventd",
-	"ticketd":                            "Ticketd",
-	"syslogd":                            "syslogd",
-	"trapd":                              "trapd",
-	"telemetryd":                         "telemetryd",
-	"nbi-email":                          "EmailNBI",
-	"nbi-snmptrap":                       "SnmpTrapNBI",
-	"nbi-syslog":                         "SyslogNBI",
-	"notifd":                             "Notifd",
-	"reportd":                            "Reportd",
-	"pollerd":                            "Pollerd",
-	"poller-backend":                     "PollerBackEnd",
-	"provisiond":                         "Provisiond",
-	"provisiond-snmp-asset":              "Provisiond.SnmpAssetProvisioningAdapter",
-	"provisiond-snmp-hardware-inventory": "Provisiond.SnmpHardwareInventoryProvisioningAdapter",
-	"provisiond-wsman":                   "WsManAssetProvisioningAdapter",
-	"scriptd":                            "Scriptd",
-	"statsd":                             "Statsd",
-	"tl1d":                               "Tl1d",
-	"threshd":                            "Threshd",
-	"translator":                         "Translator",
-	"vacuumd":                            "Vacuumd",
+// The oldest version that provides the status and the reload state of the daemons
+const minReloadStateVersion = "26.0.0"
+
+// DaemonInfo a daemon whose configuration can be reloaded
+type DaemonInfo struct {
+	Alias       string `json:"alias" yaml:"alias"`             // The name used on the CLI
+	Name        string `json:"name" yaml:"name"`               // The name used on the reload event
+	ConfigFile  bool   `json:"configFile" yaml:"configFile"`   // Whether the daemon honors the configFile parameter
+	Description string `json:"description" yaml:"description"` // A one-line description of the daemon
+}
+
+// Daemons the reloadable daemons, sorted by alias
+var Daemons = []DaemonInfo{
+	{"ackd", "Ackd", false, "Acknowledges alarms and notifications from replies (e.x. e-mail)"},
+	{"alarmd", "alarmd", false, "Creates and reduces alarms from events"},
+	{"bsmd", "Bsmd", false, "Computes the operational status of the Business Services"},
+	{"collectd", "Collectd", true, "Collects performance metrics"},
+	{CorrelatorPrefix, "DroolsCorrelationEngine", false, "Drools correlation engines; use correlation:<engine> for a specific engine"},
+	{"discoverd", "Discovery", false, "Discovers nodes from IP ranges, specifics and URLs"},
+	{"enlinkd", "Enlinkd", false, "Discovers the layer 2 and layer 3 topology"},
+	{"eventd", "Eventd", false, "Receives and persists events, using the event definitions"},
+	{"nbi-email", "EmailNBI", false, "Forwards alarms through e-mail"},
+	{"nbi-snmptrap", "SnmpTrapNBI", false, "Forwards alarms as SNMP traps"},
+	{"nbi-syslog", "SyslogNBI", false, "Forwards alarms as syslog messages"},
+	{"notifd", "Notifd", false, "Sends notifications"},
+	{"poller-backend", "PollerBackEnd", false, "Receives the results of the remote pollers"},
+	{"pollerd", "Pollerd", false, "Monitors the availability of services"},
+	{"provisiond", "Provisiond", false, "Imports requisitions and scans nodes"},
+	{"provisiond-snmp-asset", "Provisiond.SnmpAssetProvisioningAdapter", false, "Populates assets from SNMP during provisioning"},
+	{"provisiond-snmp-hardware-inventory", "Provisiond.SnmpHardwareInventoryProvisioningAdapter", false, "Populates the hardware inventory from SNMP during provisioning"},
+	{"provisiond-wsman", "WsManAssetProvisioningAdapter", false, "Populates assets from WS-Man during provisioning"},
+	{"reportd", "Reportd", false, "Runs scheduled reports"},
+	{"scriptd", "Scriptd", false, "Runs scripts triggered by events"},
+	{"statsd", "Statsd", false, "Computes statistics reports (e.x. top-N)"},
+	{"syslogd", "syslogd", false, "Receives syslog messages and converts them into events"},
+	{"telemetryd", "telemetryd", false, "Receives streaming telemetry and flows"},
+	{"threshd", "Threshd", true, "Evaluates thresholds against collected metrics"},
+	{"ticketd", "Ticketd", false, "Creates and updates trouble tickets from alarms"},
+	{"tl1d", "Tl1d", false, "Receives TL1 autonomous messages"},
+	{"translator", "Translator", false, "Translates events into other events"},
+	{"trapd", "trapd", false, "Receives SNMP traps and converts them into events"},
+	{"vacuumd", "Vacuumd", false, "Runs automations and maintenance tasks against the database"},
+}
+
+// DaemonGroup a set of related daemons that can be reloaded together
+type DaemonGroup struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Members     []string `json:"members" yaml:"members"` // Aliases of the daemons; a trailing '*' matches all the aliases with that prefix
+}
+
+// DaemonGroups the groups of daemons accepted by reload --group, sorted by name
+var DaemonGroups = []DaemonGroup{
+	{"northbound", "The northbound interfaces that forward alarms", []string{"nbi-*"}},
+	{"polling", "Service monitoring, data collection and thresholding", []string{"pollerd", "collectd", "threshd"}},
+	{"provisioning", "Provisiond and its provisioning adapters", []string{"provisiond*"}},
+}
+
+// FindDaemonGroup returns the group with the given name (case insensitive), or nil if it doesn't exist
+func FindDaemonGroup(name string) *DaemonGroup {
+	for i := range DaemonGroups {
+		if strings.EqualFold(DaemonGroups[i].Name, name) {
+			return &DaemonGroups[i]
+		}
+	}
+	return nil
+}
+
+// Aliases returns the aliases of the daemons of the group, in the order of the members
+func (g DaemonGroup) Aliases() []string {
+	aliases := []string{}
+	for _, member := range g.Members {
+		if !strings.HasSuffix(member, "*") {
+			aliases = append(aliases, member)
+			continue
+		}
+		for _, d := range Daemons {
+			if strings.HasPrefix(d.Alias, strings.TrimSuffix(member, "*")) {
+				aliases = append(aliases, d.Alias)
+			}
+		}
+	}
+	return aliases
+}
+
+func daemonGroupNames() []string {
+	names := make([]string, len(DaemonGroups))
+	for i, g := range DaemonGroups {
+		names[i] = g.Name
+	}
+	return names
+}
+
+// FindDaemon returns the reloadable daemon for an alias (case insensitive), or nil if it doesn't exist;
+// correlation engines (e.x. correlation:MyEngine) are accepted
+func FindDaemon(alias string) *DaemonInfo {
+	alias = strings.ToLower(alias)
+	if strings.HasPrefix(alias, CorrelatorPrefix) {
+		alias = CorrelatorPrefix
+	}
+	for i := range Daemons {
+		if Daemons[i].Alias == alias {
+			return &Daemons[i]
+		}
+	}
+	return nil
 }
 
 // CliCommand the CLI command to manage events
@@ -54,84 +130,281 @@ var CliCommand = cli.Command{
 	Subcommands: []cli.Command{
 		{
 			Name:         "reload",
-			Usage:        "Request reload the configuration of a given OpenNMS daemon",
-			ArgsUsage:    "<daemonName>",
-			Action:       reloadDaemon,
+			Usage:        "Request reload the configuration of one or more OpenNMS daemons",
+			Description:  "All the names are verified before sending any event, and the daemons are reloaded one at a time, reporting the result of each one.",
+			ArgsUsage:    "<daemonName> [<daemonName>...]",
+			Action:       reloadDaemons,
 			BashComplete: reloadBashComplete,
 			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "group, g",
+					Usage: "Reload a group of daemons: " + strings.Join(daemonGroupNames(), ", ") + " (see 'daemon groups'); can be repeated",
+				},
 				cli.StringFlag{
 					Name:  "configFile, f",
-					Usage: "Configuration File (used by a few daemons)",
+					Usage: "Configuration File (used by a few daemons; only when reloading a single daemon)",
+				},
+				cli.BoolFlag{
+					Name:  "wait, w",
+					Usage: "Wait until each daemon reports the result of the reload (requires Horizon 26 or newer)",
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Value: 2 * time.Minute,
+					Usage: "Maximum time to wait for the reload of each daemon to finish",
+				},
+				cli.DurationFlag{
+					Name:  "poll-interval",
+					Value: 2 * time.Second,
+					Usage: "Time between checks of the reload state",
 				},
 			},
 		},
+		{
+			Name:   "groups",
+			Usage:  "Show the groups of daemons accepted by reload --group",
+			Action: showDaemonGroups,
+		},
+		{
+			Name:         "status",
+			Usage:        "Show whether a given OpenNMS daemon is enabled, and the result of its last reload (requires OpenNMS 26 or newer)",
+			ArgsUsage:    "<daemonName>",
+			Action:       showDaemonStatus,
+			BashComplete: reloadBashComplete,
+		},
 		{
 			Name:   "list",
 			Usage:  "Show a list of reloadable daemons",
 			Action: showReloadableDaemons,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "Show only the daemons whose name or description contains the given text",
+				},
+			},
 		},
 	},
 }
 
-func reloadDaemon(c *cli.Context) error {
-	if !c.Args().Present() {
-		return fmt.Errorf("Daemon name required")
+func reloadDaemons(c *cli.Context) error {
+	aliases, err := getReloadAliases(c)
+	if err != nil {
+		return err
 	}
-	daemonName := c.Args().First()
-	if !isValidDaemon(daemonName) {
-		return fmt.Errorf("Invalid daemon name %s", daemonName)
+	if len(aliases) > 1 && c.String("configFile") != "" {
+		return fmt.Errorf("--configFile can only be used when reloading a single daemon")
+	}
+	if c.Bool("wait") {
+		if err := common.GetClient(c).MinServerVersion(minReloadStateVersion, "Waiting for the reload of a daemon"); err != nil {
+			return err
+		}
+	}
+	if len(aliases) == 1 {
+		return reloadDaemon(c, aliases[0])
+	}
+	failed := []string{}
+	for _, alias := range aliases {
+		if err := reloadDaemon(c, alias); err != nil {
+			common.Log.Errorf("%s", err)
+			failed = append(failed, alias)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Failed to reload %d of %d daemons: %s", len(failed), len(aliases), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Returns the daemons passed as arguments followed by the members of the groups, without duplicates;
+// all of them are verified, so nothing is reloaded when there is an invalid name
+func getReloadAliases(c *cli.Context) ([]string, error) {
+	names := append([]string{}, c.Args()...)
+	for _, group := range c.StringSlice("group") {
+		g := FindDaemonGroup(group)
+		if g == nil {
+			return nil, fmt.Errorf("Invalid daemon group %s, valid options: %s", group, strings.Join(daemonGroupNames(), ", "))
+		}
+		names = append(names, g.Aliases()...)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("Daemon name required")
+	}
+	aliases := []string{}
+	invalid := []string{}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !isValidDaemon(name) {
+			invalid = append(invalid, name)
+			continue
+		}
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			aliases = append(aliases, name)
+		}
+	}
+	switch len(invalid) {
+	case 0:
+		return aliases, nil
+	case 1:
+		return nil, fmt.Errorf("Invalid daemon name %s", invalid[0])
+	}
+	return nil, fmt.Errorf("Invalid daemon names %s", strings.Join(invalid, ", "))
+}
+
+func reloadDaemon(c *cli.Context, daemonName string) error {
+	if configFile := c.String("configFile"); configFile != "" && !FindDaemon(daemonName).ConfigFile {
+		common.Log.Warnf("%s ignores the configFile parameter, so %s is not going to be used", daemonName, configFile)
+	}
+	event := ReloadEvent(daemonName, c.String("configFile"))
+	eventsAPI := services.GetEventsAPI(common.GetClient(c))
+	if !c.Bool("wait") {
+		if err := eventsAPI.SendEvent(event); err != nil {
+			return err
+		}
+		common.Log.Infof("Reload of %s requested", daemonName)
+		return nil
+	}
+	api := services.GetDaemonsAPI(common.GetClient(c))
+	name := getDaemonName(daemonName)
+	last, err := api.GetReloadState(name)
+	if err != nil {
+		common.Log.Warnf("The reload of %s cannot be verified, as the server doesn't provide the reload state of the daemons: %s", daemonName, err)
+		return eventsAPI.SendEvent(event)
+	}
+	if err := eventsAPI.SendEvent(event); err != nil {
+		return err
+	}
+	state, err := api.WaitForReload(name, last.RequestTime, c.Duration("timeout"), c.Duration("poll-interval"))
+	if err != nil {
+		return err
 	}
+	if state.State == model.DaemonReloadFailed {
+		return fmt.Errorf("Daemon %s failed to reload its configuration", daemonName)
+	}
+	common.Log.Infof("Daemon %s reloaded its configuration successfully", daemonName)
+	return nil
+}
+
+// ReloadEvent builds the event to request the reload of the configuration of a given daemon;
+// the configuration file is optional
+func ReloadEvent(daemonName string, configFile string) model.Event {
 	event := model.Event{
 		UEI:    "uei.opennms.org/internal/reloadDaemonConfig",
 		Source: "onmsctl",
 	}
 	event.AddParameter("daemonName", getDaemonName(daemonName))
-	configFile := c.String("configFile")
 	if configFile != "" {
 		event.AddParameter("configFile", configFile)
 	}
-	return services.GetEventsAPI(rest.Instance).SendEvent(event)
+	return event
+}
+
+func showDaemonStatus(c *cli.Context) error {
+	if !c.Args().Present() {
+		return fmt.Errorf("Daemon name required")
+	}
+	if err := common.GetClient(c).MinServerVersion(minReloadStateVersion, "The status of the daemons"); err != nil {
+		return err
+	}
+	daemonName := c.Args().First()
+	api := services.GetDaemonsAPI(common.GetClient(c))
+	daemons, err := api.GetDaemons()
+	if err != nil {
+		return err
+	}
+	daemon := model.FindDaemon(daemons, daemonName)
+	if daemon == nil && isValidDaemon(daemonName) {
+		daemon = model.FindDaemon(daemons, getDaemonName(daemonName))
+	}
+	if daemon == nil {
+		return fmt.Errorf("Daemon %s doesn't exist on the server", daemonName)
+	}
+	status := daemonStatus{Daemon: *daemon}
+	table := common.NewTable("", "Name", "Enabled", "Reloadable", "Last Reload", "Requested", "Finished")
+	if daemon.Reloadable {
+		if status.ReloadState, err = api.GetReloadState(daemon.Name); err != nil {
+			return err
+		}
+		state := status.ReloadState
+		table.AddRow(daemon.Name, daemon.Enabled, daemon.Reloadable, state.State, formatTime(state.RequestTime), formatTime(state.ResultTime))
+	} else {
+		table.AddRow(daemon.Name, daemon.Enabled, daemon.Reloadable, "N/A", "N/A", "N/A")
+	}
+	return common.Print(status, table)
+}
+
+// daemonStatus the status of a daemon, including the state of its last reload
+type daemonStatus struct {
+	model.Daemon `yaml:",inline"`
+	ReloadState  *model.DaemonReloadState `json:"reloadState,omitempty" yaml:"reloadState,omitempty"`
+}
+
+func formatTime(t *model.Time) string {
+	if t == nil {
+		return "N/A"
+	}
+	return t.UTC().Format(time.RFC3339)
 }
 
 func reloadBashComplete(c *cli.Context) {
-	if c.NArg() > 0 {
+	if common.CompletingFlag(os.Args, "group", "g") {
+		common.PrintCompletions(daemonGroupNames()...)
 		return
 	}
-	for k := range DaemonMap {
-		fmt.Println(k)
+	if c.NArg() > 0 && c.Command.Name != "reload" { // Only reload accepts multiple daemons
+		return
+	}
+	used := make(map[string]bool)
+	for _, arg := range c.Args() {
+		used[strings.ToLower(arg)] = true
+	}
+	for _, d := range Daemons {
+		if !used[d.Alias] {
+			common.PrintCompletions(d.Alias)
+		}
 	}
 }
 
-func showReloadableDaemons(c *cli.Context) error {
-	keys := make([]string, 0, len(DaemonMap))
-	for k := range DaemonMap {
-		keys = append(keys, k)
+func showDaemonGroups(c *cli.Context) error {
+	table := common.NewTable("", "Group", "Daemons", "Description")
+	for _, g := range DaemonGroups {
+		table.AddRow(g.Name, strings.Join(g.Aliases(), ", "), g.Description)
 	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		fmt.Println(k)
+	return common.Print(DaemonGroups, table)
+}
+
+func showReloadableDaemons(c *cli.Context) error {
+	filter := strings.ToLower(c.String("filter"))
+	daemons := make([]DaemonInfo, 0)
+	table := common.NewTable("There are no daemons matching "+filter, "Daemon Name", "Internal Name", "Config File", "Description")
+	for _, d := range Daemons {
+		if filter != "" && !strings.Contains(strings.ToLower(d.Alias+" "+d.Name+" "+d.Description), filter) {
+			continue
+		}
+		daemons = append(daemons, d)
+		configFile := "-"
+		if d.ConfigFile {
+			configFile = "yes"
+		}
+		table.AddRow(d.Alias, d.Name, configFile, d.Description)
 	}
-	return nil
+	return common.Print(daemons, table)
 }
 
 func isValidDaemon(daemonName string) bool {
-	name := strings.ToLower(daemonName)
-	if _, ok := DaemonMap[name]; ok {
-		return true
-	} else if strings.HasPrefix(name, CorrelatorPrefix) {
-		return true
-	}
-	return false
+	return FindDaemon(daemonName) != nil
 }
 
 func getDaemonName(id string) string {
-	if strings.HasPrefix(id, CorrelatorPrefix) {
+	daemon := FindDaemon(id)
+	if daemon == nil {
+		return ""
+	}
+	if daemon.Alias == CorrelatorPrefix {
 		data := strings.Split(id, ":")
 		if len(data) == 2 {
-			return DaemonMap[CorrelatorPrefix] + ":" + data[1]
-		} else {
-			return DaemonMap[CorrelatorPrefix]
+			return daemon.Name + ":" + data[1]
 		}
 	}
-	return DaemonMap[id]
+	return daemon.Name
 }
@@ -7,15 +7,23 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/OpenNMS/onmsctl/test"
 	"github.com/google/go-cmp/cmp"
-
+	"github.com/urfave/cli"
 	"gotest.tools/assert"
 )
 
+// Creates the CLI with a client for a server that supports the reload state of the daemons, so its version is not requested
+func createCli(cmd cli.Command, opts ...rest.Option) *cli.App {
+	app := test.CreateCli(cmd, opts...)
+	test.Client(app).ServerVersion = &rest.Version{Major: 26}
+	return app
+}
+
 var mockData = &model.Event{
 	UEI:    "uei.opennms.org/internal/reloadDaemonConfig",
 	Source: "onmsctl",
@@ -24,6 +32,52 @@ var mockData = &model.Event{
 	},
 }
 
+// The internal names of all the daemons, to make sure a change on the list doesn't break reloads
+var expectedDaemonNames = map[string]string{
+	"ackd":                               "Ackd",
+	"alarmd":                             "alarmd",
+	"bsmd":                               "Bsmd",
+	"collectd":                           "Collectd",
+	"correlation":                        "DroolsCorrelationEngine",
+	"discoverd":                          "Discovery",
+	"enlinkd":                            "Enlinkd",
+	"eventd":                             "Eventd",
+	"ticketd":                            "Ticketd",
+	"syslogd":                            "syslogd",
+	"trapd":                              "trapd",
+	"telemetryd":                         "telemetryd",
+	"nbi-email":                          "EmailNBI",
+	"nbi-snmptrap":                       "SnmpTrapNBI",
+	"nbi-syslog":                         "SyslogNBI",
+	"notifd":                             "Notifd",
+	"reportd":                            "Reportd",
+	"pollerd":                            "Pollerd",
+	"poller-backend":                     "PollerBackEnd",
+	"provisiond":                         "Provisiond",
+	"provisiond-snmp-asset":              "Provisiond.SnmpAssetProvisioningAdapter",
+	"provisiond-snmp-hardware-inventory": "Provisiond.SnmpHardwareInventoryProvisioningAdapter",
+	"provisiond-wsman":                   "WsManAssetProvisioningAdapter",
+	"scriptd":                            "Scriptd",
+	"statsd":                             "Statsd",
+	"tl1d":                               "Tl1d",
+	"threshd":                            "Threshd",
+	"translator":                         "Translator",
+	"vacuumd":                            "Vacuumd",
+}
+
+func TestDaemons(t *testing.T) {
+	assert.Equal(t, len(expectedDaemonNames), len(Daemons))
+	for i, d := range Daemons {
+		assert.Equal(t, expectedDaemonNames[d.Alias], d.Name, "internal name of %s", d.Alias)
+		assert.Equal(t, d.Name, getDaemonName(d.Alias))
+		assert.Equal(t, d.Name, getDaemonName(strings.ToUpper(d.Alias)))
+		assert.Assert(t, d.Description != "", "description of %s", d.Alias)
+		if i > 0 {
+			assert.Assert(t, Daemons[i-1].Alias < d.Alias, "%s must be after %s", d.Alias, Daemons[i-1].Alias)
+		}
+	}
+}
+
 func TestDaemonMap(t *testing.T) {
 	assert.Equal(t, true, isValidDaemon("pollerd"))
 	assert.Equal(t, false, isValidDaemon("nonexistent"))
@@ -37,14 +91,14 @@ func TestDaemonMap(t *testing.T) {
 }
 
 func TestListDaemon(t *testing.T) {
-	app := test.CreateCli(CliCommand)
+	app := createCli(CliCommand)
 	err := app.Run([]string{app.Name, "daemon", "list"})
 	assert.NilError(t, err)
 }
 
 func TestReloadDaemon(t *testing.T) {
 	var err error
-	app := test.CreateCli(CliCommand)
+	app := createCli(CliCommand)
 	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		assert.Assert(t, strings.HasPrefix(req.URL.Path, "/rest/events"))
 		assert.Equal(t, http.MethodPost, req.Method)
@@ -55,7 +109,7 @@ func TestReloadDaemon(t *testing.T) {
 		assert.Assert(t, cmp.Equal(mockData, event))
 		res.WriteHeader(http.StatusOK)
 	}))
-	rest.Instance.URL = server.URL
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "daemon", "reload"})
@@ -67,3 +121,239 @@ func TestReloadDaemon(t *testing.T) {
 	err = app.Run([]string{app.Name, "daemon", "reload", "pollerd"})
 	assert.NilError(t, err)
 }
+
+func TestReloadDaemonWait(t *testing.T) {
+	var err error
+	var polls int
+	var events int
+	var supported = true
+	app := createCli(CliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/events":
+			assert.Equal(t, http.MethodPost, req.Method)
+			events++
+			res.WriteHeader(http.StatusOK)
+		case "/rest/daemons/reload/Pollerd":
+			if !supported {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			polls++
+			state := model.DaemonReloadState{State: model.DaemonReloadSuccess}
+			state.RequestTime = &model.Time{Time: time.Unix(1571000000, 0)}
+			if polls > 2 {
+				state.RequestTime = &model.Time{Time: time.Unix(1571000100, 0)}
+			}
+			bytes, _ := json.Marshal(state)
+			res.Write(bytes)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "daemon", "reload", "--wait", "--poll-interval", "1ms", "pollerd"})
+	assert.NilError(t, err)
+	assert.Equal(t, 3, polls)
+
+	assert.Equal(t, 1, events)
+
+	// When the reload state is unavailable, the event is sent anyway
+	supported = false
+	err = app.Run([]string{app.Name, "daemon", "reload", "--wait", "pollerd"})
+	assert.NilError(t, err)
+	assert.Equal(t, 2, events)
+
+	// Older servers fail fast, without sending the event
+	test.Client(app).ServerVersion = &rest.Version{Major: 24, Minor: 1, Patch: 2}
+	err = app.Run([]string{app.Name, "daemon", "reload", "--wait", "pollerd"})
+	assert.Error(t, err, "Waiting for the reload of a daemon requires OpenNMS >= 26.0.0, the server runs 24.1.2")
+	assert.Equal(t, 2, events)
+
+	err = app.Run([]string{app.Name, "daemon", "status", "pollerd"})
+	assert.Error(t, err, "The status of the daemons requires OpenNMS >= 26.0.0, the server runs 24.1.2")
+}
+
+func TestDaemonStatus(t *testing.T) {
+	var err error
+	app := createCli(CliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.Path {
+		case "/rest/daemons":
+			res.Write([]byte(`[{"name":"Pollerd","enabled":true,"reloadable":true},{"name":"Eventd","enabled":true,"reloadable":false}]`))
+		case "/rest/daemons/reload/Pollerd":
+			res.Write([]byte(`{"reloadRequestEventTime":1571000000000,"reloadResultEventTime":1571000001000,"reloadState":"Success"}`))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "daemon", "status"})
+	assert.Error(t, err, "Daemon name required")
+
+	err = app.Run([]string{app.Name, "daemon", "status", "collectd"})
+	assert.Error(t, err, "Daemon collectd doesn't exist on the server")
+
+	err = app.Run([]string{app.Name, "daemon", "status", "pollerd"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "daemon", "status", "eventd"})
+	assert.NilError(t, err)
+}
+
+func TestDaemonOutput(t *testing.T) {
+	time.Local = time.UTC // The YAML representation of the reload times uses the local time zone
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/daemons":
+			res.Write([]byte(`[{"name":"Pollerd","enabled":true,"reloadable":true},{"name":"Eventd","enabled":true,"reloadable":false}]`))
+		case "/rest/daemons/reload/Pollerd":
+			res.Write([]byte(`{"reloadRequestEventTime":1571000000000,"reloadResultEventTime":1571000001000,"reloadState":"Success"}`))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		args     []string
+		format   string
+		expected string
+	}{
+		{[]string{"list"}, "jsonpath=$[0].alias", "ackd\n"},
+		{[]string{"list"}, "jsonpath=$[-1].alias", "vacuumd\n"},
+		{[]string{"status", "pollerd"}, "table", `Name     Enabled  Reloadable  Last Reload  Requested             Finished
+Pollerd  true     true        Success      2019-10-13T20:53:20Z  2019-10-13T20:53:21Z
+`},
+		{[]string{"status", "eventd"}, "table", `Name    Enabled  Reloadable  Last Reload  Requested  Finished
+Eventd  true     false       N/A          N/A        N/A
+`},
+		{[]string{"status", "pollerd"}, "yaml", `name: Pollerd
+internal: false
+enabled: true
+reloadable: true
+reloadState:
+  requestTime: "2019-10-13T20:53:20Z"
+  resultTime: "2019-10-13T20:53:21Z"
+  state: Success
+`},
+		{[]string{"status", "eventd"}, "json", `{
+  "name": "Eventd",
+  "internal": false,
+  "enabled": true,
+  "reloadable": false
+}
+`},
+		{[]string{"status", "pollerd"}, "jsonpath=$.reloadState.reloadState", "Success\n"},
+	}
+	for _, tc := range testCases {
+		app := createCli(CliCommand, rest.WithURL(server.URL))
+		output, err := test.RunWithOutput(app, tc.format, append([]string{"daemon"}, tc.args...)...)
+		assert.NilError(t, err)
+		assert.Equal(t, tc.expected, output, "%v with %s", tc.args, tc.format)
+	}
+
+	app := createCli(CliCommand, rest.WithURL(server.URL))
+	output, err := test.RunWithOutput(app, "table", "daemon", "list")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, len(Daemons)+1, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[0], "Daemon Name"))
+	assert.Assert(t, strings.HasPrefix(lines[1], "ackd "))
+	output, err = test.RunWithOutput(app, "table", "daemon", "list", "--filter", "THRESH")
+	assert.NilError(t, err)
+	assert.Equal(t, `Daemon Name  Internal Name  Config File  Description
+threshd      Threshd        yes          Evaluates thresholds against collected metrics
+`, output)
+	output, err = test.RunWithOutput(app, "yaml", "daemon", "list", "-f", "nbi-e")
+	assert.NilError(t, err)
+	assert.Equal(t, `- alias: nbi-email
+  name: EmailNBI
+  configFile: false
+  description: Forwards alarms through e-mail
+`, output)
+	output, err = test.RunWithOutput(app, "table", "daemon", "list", "-f", "unknown")
+	assert.NilError(t, err)
+	assert.Equal(t, "There are no daemons matching unknown\n", output)
+}
+
+func TestDaemonGroups(t *testing.T) {
+	for i, g := range DaemonGroups {
+		assert.Assert(t, g.Description != "", "description of %s", g.Name)
+		assert.Assert(t, len(g.Aliases()) > 0, "daemons of %s", g.Name)
+		for _, alias := range g.Aliases() {
+			assert.Assert(t, isValidDaemon(alias), "%s of group %s", alias, g.Name)
+		}
+		if i > 0 {
+			assert.Assert(t, DaemonGroups[i-1].Name < g.Name, "%s must be after %s", g.Name, DaemonGroups[i-1].Name)
+		}
+	}
+	assert.DeepEqual(t, []string{"pollerd", "collectd", "threshd"}, FindDaemonGroup("Polling").Aliases())
+	assert.DeepEqual(t, []string{"nbi-email", "nbi-snmptrap", "nbi-syslog"}, FindDaemonGroup("northbound").Aliases())
+	assert.DeepEqual(t, []string{"provisiond", "provisiond-snmp-asset", "provisiond-snmp-hardware-inventory", "provisiond-wsman"}, FindDaemonGroup("provisioning").Aliases())
+	assert.Assert(t, FindDaemonGroup("unknown") == nil)
+}
+
+func TestReloadMultipleDaemons(t *testing.T) {
+	var err error
+	var reloaded []string
+	app := createCli(CliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/rest/events", req.URL.Path)
+		event := &model.Event{}
+		bytes, err := ioutil.ReadAll(req.Body)
+		assert.NilError(t, err)
+		json.Unmarshal(bytes, event)
+		reloaded = append(reloaded, event.Parameters[0].Value)
+		res.WriteHeader(http.StatusOK)
+	}))
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "daemon", "reload", "pollerd", "Weird", "trapd", "Odd"})
+	assert.Error(t, err, "Invalid daemon names Weird, Odd")
+	assert.Equal(t, 0, len(reloaded))
+
+	err = app.Run([]string{app.Name, "daemon", "reload", "-g", "unknown"})
+	assert.Error(t, err, "Invalid daemon group unknown, valid options: northbound, polling, provisioning")
+	assert.Equal(t, 0, len(reloaded))
+
+	err = app.Run([]string{app.Name, "daemon", "reload", "-f", "poller-configuration.xml", "pollerd", "collectd"})
+	assert.Error(t, err, "--configFile can only be used when reloading a single daemon")
+	assert.Equal(t, 0, len(reloaded))
+
+	err = app.Run([]string{app.Name, "daemon", "reload", "trapd", "pollerd", "--group", "polling"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"trapd", "Pollerd", "Collectd", "Threshd"}, reloaded)
+}
+
+func TestReloadMultipleDaemonsWithFailures(t *testing.T) {
+	var polls int64
+	app := createCli(CliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/rest/events" {
+			res.WriteHeader(http.StatusOK)
+			return
+		}
+		polls++
+		state := model.DaemonReloadState{State: model.DaemonReloadSuccess}
+		state.RequestTime = &model.Time{Time: time.Unix(1571000000+polls, 0)}
+		if req.URL.Path == "/rest/daemons/reload/Collectd" {
+			state.State = model.DaemonReloadFailed
+		}
+		bytes, _ := json.Marshal(state)
+		res.Write(bytes)
+	}))
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	// A failure doesn't stop the reload of the rest of the daemons
+	err := app.Run([]string{app.Name, "daemon", "reload", "-w", "--poll-interval", "1ms", "-g", "polling"})
+	assert.Error(t, err, "Failed to reload 1 of 3 daemons: collectd")
+	assert.Equal(t, int64(6), polls)
+}
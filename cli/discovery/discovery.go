@@ -0,0 +1,306 @@
+package discovery
+
+import (
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/cli/daemon"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// reloadFlag the flag to reload Discoverd after a successful change
+var reloadFlag = cli.BoolFlag{
+	Name:  "reload",
+	Usage: "Request Discoverd to reload its configuration after the change",
+}
+
+// rangeFlags the flags for the boundaries of a range
+var rangeFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "begin, b",
+		Usage: "The first IP address of the range",
+	},
+	cli.StringFlag{
+		Name:  "end, e",
+		Usage: "The last IP address of the range",
+	},
+}
+
+// targetFlags the flags for the optional settings of specifics, include ranges and URLs
+var targetFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "location, l",
+		Usage: "Minion Location",
+	},
+	cli.IntFlag{
+		Name:  "retries, r",
+		Usage: "The number of retries before giving up (defaults to the global setting)",
+	},
+	cli.IntFlag{
+		Name:  "timeout, t",
+		Usage: "Timeout in milliseconds (defaults to the global setting)",
+	},
+	cli.StringFlag{
+		Name:  "foreign-source, fs",
+		Usage: "The requisition for the discovered nodes",
+	},
+}
+
+// CliCommand the CLI command to manage the discovery configuration
+var CliCommand = cli.Command{
+	Name:  "discovery",
+	Usage: "Manage Discovery configuration",
+	Subcommands: []cli.Command{
+		{
+			Name:   "show",
+			Usage:  "Shows the current discovery configuration",
+			Action: showDiscoveryConfig,
+		},
+		{
+			Name:  "include-range",
+			Usage: "Manage the ranges of IP addresses to discover",
+			Subcommands: []cli.Command{
+				{
+					Name:   "add",
+					Usage:  "Adds or updates an include range",
+					Action: addIncludeRange,
+					Flags:  append(append(append([]cli.Flag{}, rangeFlags...), targetFlags...), reloadFlag),
+				},
+				{
+					Name:      "delete",
+					ShortName: "del",
+					Usage:     "Deletes an include range",
+					Action:    deleteIncludeRange,
+					Flags:     append(append([]cli.Flag{}, rangeFlags...), reloadFlag),
+				},
+			},
+		},
+		{
+			Name:  "exclude-range",
+			Usage: "Manage the ranges of IP addresses to skip",
+			Subcommands: []cli.Command{
+				{
+					Name:   "add",
+					Usage:  "Adds or updates an exclude range",
+					Action: addExcludeRange,
+					Flags: append(append([]cli.Flag{}, rangeFlags...), cli.StringFlag{
+						Name:  "location, l",
+						Usage: "Minion Location",
+					}, reloadFlag),
+				},
+				{
+					Name:      "delete",
+					ShortName: "del",
+					Usage:     "Deletes an exclude range",
+					Action:    deleteExcludeRange,
+					Flags:     append(append([]cli.Flag{}, rangeFlags...), reloadFlag),
+				},
+			},
+		},
+		{
+			Name:  "specific",
+			Usage: "Manage the individual IP addresses to discover",
+			Subcommands: []cli.Command{
+				{
+					Name:      "add",
+					Usage:     "Adds or updates a specific IP address",
+					ArgsUsage: "<ipAddress>",
+					Action:    addSpecific,
+					Flags:     append(append([]cli.Flag{}, targetFlags...), reloadFlag),
+				},
+				{
+					Name:      "delete",
+					ShortName: "del",
+					Usage:     "Deletes a specific IP address",
+					ArgsUsage: "<ipAddress>",
+					Action:    deleteSpecific,
+					Flags:     []cli.Flag{reloadFlag},
+				},
+			},
+		},
+		{
+			Name:  "url",
+			Usage: "Manage the URLs with lists of IP addresses to discover",
+			Subcommands: []cli.Command{
+				{
+					Name:      "add",
+					Usage:     "Adds or updates an include URL",
+					ArgsUsage: "<url>",
+					Action:    addIncludeURL,
+					Flags:     append(append([]cli.Flag{}, targetFlags...), reloadFlag),
+				},
+				{
+					Name:      "delete",
+					ShortName: "del",
+					Usage:     "Deletes an include URL",
+					ArgsUsage: "<url>",
+					Action:    deleteIncludeURL,
+					Flags:     []cli.Flag{reloadFlag},
+				},
+			},
+		},
+	},
+}
+
+func showDiscoveryConfig(c *cli.Context) error {
+	config, err := getAPI(c).GetConfig()
+	if err != nil {
+		return err
+	}
+	return common.Print(config, nil)
+}
+
+func addIncludeRange(c *cli.Context) error {
+	begin, end, err := getRange(c)
+	if err != nil {
+		return err
+	}
+	return updateConfig(c, func(config *model.DiscoveryConfiguration) error {
+		config.AddIncludeRange(model.IncludeRange{
+			Begin:         begin,
+			End:           end,
+			Location:      c.String("location"),
+			Retries:       c.Int("retries"),
+			Timeout:       int64(c.Int("timeout")),
+			ForeignSource: c.String("foreign-source"),
+		})
+		return nil
+	})
+}
+
+func deleteIncludeRange(c *cli.Context) error {
+	begin, end, err := getRange(c)
+	if err != nil {
+		return err
+	}
+	return updateConfig(c, func(config *model.DiscoveryConfiguration) error {
+		if !config.DeleteIncludeRange(begin, end) {
+			return fmt.Errorf("Include range %s-%s doesn't exist", begin, end)
+		}
+		return nil
+	})
+}
+
+func addExcludeRange(c *cli.Context) error {
+	begin, end, err := getRange(c)
+	if err != nil {
+		return err
+	}
+	return updateConfig(c, func(config *model.DiscoveryConfiguration) error {
+		config.AddExcludeRange(model.ExcludeRange{
+			Begin:    begin,
+			End:      end,
+			Location: c.String("location"),
+		})
+		return nil
+	})
+}
+
+func deleteExcludeRange(c *cli.Context) error {
+	begin, end, err := getRange(c)
+	if err != nil {
+		return err
+	}
+	return updateConfig(c, func(config *model.DiscoveryConfiguration) error {
+		if !config.DeleteExcludeRange(begin, end) {
+			return fmt.Errorf("Exclude range %s-%s doesn't exist", begin, end)
+		}
+		return nil
+	})
+}
+
+func addSpecific(c *cli.Context) error {
+	address := c.Args().First()
+	if address == "" {
+		return fmt.Errorf("IP address required")
+	}
+	return updateConfig(c, func(config *model.DiscoveryConfiguration) error {
+		config.AddSpecific(model.Specific{
+			Address:       address,
+			Location:      c.String("location"),
+			Retries:       c.Int("retries"),
+			Timeout:       int64(c.Int("timeout")),
+			ForeignSource: c.String("foreign-source"),
+		})
+		return nil
+	})
+}
+
+func deleteSpecific(c *cli.Context) error {
+	address := c.Args().First()
+	if address == "" {
+		return fmt.Errorf("IP address required")
+	}
+	return updateConfig(c, func(config *model.DiscoveryConfiguration) error {
+		if !config.DeleteSpecific(address) {
+			return fmt.Errorf("Specific %s doesn't exist", address)
+		}
+		return nil
+	})
+}
+
+func addIncludeURL(c *cli.Context) error {
+	url := c.Args().First()
+	if url == "" {
+		return fmt.Errorf("URL required")
+	}
+	return updateConfig(c, func(config *model.DiscoveryConfiguration) error {
+		config.AddIncludeURL(model.IncludeURL{
+			URL:           url,
+			Location:      c.String("location"),
+			Retries:       c.Int("retries"),
+			Timeout:       int64(c.Int("timeout")),
+			ForeignSource: c.String("foreign-source"),
+		})
+		return nil
+	})
+}
+
+func deleteIncludeURL(c *cli.Context) error {
+	url := c.Args().First()
+	if url == "" {
+		return fmt.Errorf("URL required")
+	}
+	return updateConfig(c, func(config *model.DiscoveryConfiguration) error {
+		if !config.DeleteIncludeURL(url) {
+			return fmt.Errorf("Include URL %s doesn't exist", url)
+		}
+		return nil
+	})
+}
+
+func getRange(c *cli.Context) (string, string, error) {
+	begin := c.String("begin")
+	end := c.String("end")
+	if begin == "" || end == "" {
+		return "", "", fmt.Errorf("Begin and end IP addresses required")
+	}
+	return begin, end, nil
+}
+
+// Obtains the current configuration, applies the change, and sends it back to the server;
+// when requested, Discoverd reloads its configuration afterwards
+func updateConfig(c *cli.Context, change func(config *model.DiscoveryConfiguration) error) error {
+	api := getAPI(c)
+	config, err := api.GetConfig()
+	if err != nil {
+		return err
+	}
+	if err := change(config); err != nil {
+		return err
+	}
+	if err := api.SetConfig(*config); err != nil {
+		return err
+	}
+	if c.Bool("reload") {
+		return services.GetEventsAPI(common.GetClient(c)).SendEvent(daemon.ReloadEvent("discoverd", ""))
+	}
+	return nil
+}
+
+func getAPI(c *cli.Context) api.DiscoveryAPI {
+	return services.GetDiscoveryAPI(common.GetClient(c))
+}
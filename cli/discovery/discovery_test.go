@@ -0,0 +1,166 @@
+package discovery
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+
+	"gotest.tools/assert"
+)
+
+type mockServer struct {
+	*httptest.Server
+	config *model.DiscoveryConfiguration
+	events []model.Event
+}
+
+func createMockServer(t *testing.T) *mockServer {
+	mock := &mockServer{
+		config: &model.DiscoveryConfiguration{
+			Retries:       1,
+			Timeout:       2000,
+			IncludeRanges: []model.IncludeRange{{Begin: "10.0.0.1", End: "10.0.0.254"}},
+		},
+	}
+	mock.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/config/discovery":
+			switch req.Method {
+			case http.MethodGet:
+				bytes, _ := json.Marshal(mock.config)
+				res.Write(bytes)
+			case http.MethodPut:
+				assert.Equal(t, "application/xml", req.Header.Get("Content-Type"))
+				bytes, err := ioutil.ReadAll(req.Body)
+				assert.NilError(t, err)
+				mock.config = &model.DiscoveryConfiguration{}
+				assert.NilError(t, xml.Unmarshal(bytes, mock.config))
+			default:
+				res.WriteHeader(http.StatusForbidden)
+			}
+		case "/rest/events":
+			assert.Equal(t, http.MethodPost, req.Method)
+			event := model.Event{}
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			json.Unmarshal(bytes, &event)
+			mock.events = append(mock.events, event)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return mock
+}
+
+func TestShowDiscoveryConfig(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "yaml", "discovery", "show")
+	assert.NilError(t, err)
+	assert.Equal(t, "retries: 1\ntimeout: 2000\nincludeRanges:\n- begin: 10.0.0.1\n  end: 10.0.0.254\n", output)
+}
+
+func TestIncludeRange(t *testing.T) {
+	var err error
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "discovery", "include-range", "add", "-b", "10.0.1.1"})
+	assert.Error(t, err, "Begin and end IP addresses required")
+
+	err = app.Run([]string{app.Name, "discovery", "include-range", "add", "-b", "10.0.1.254", "-e", "10.0.1.1"})
+	assert.Error(t, err, "Invalid include range: begin 10.0.1.254 must be lower or equal than end 10.0.1.1")
+
+	err = app.Run([]string{app.Name, "discovery", "include-range", "add", "-b", "10.0.1.1", "-e", "fe80::1"})
+	assert.Error(t, err, "Invalid include range: 10.0.1.1 and fe80::1 must be on the same address family")
+
+	err = app.Run([]string{app.Name, "discovery", "include-range", "add", "-b", "10.0.1.1", "-e", "10.0.1.254", "-l", "Durham", "-r", "3"})
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(server.config.IncludeRanges))
+	assert.Equal(t, "Durham", server.config.IncludeRanges[1].Location)
+	assert.Equal(t, 3, server.config.IncludeRanges[1].Retries)
+	assert.Equal(t, 0, len(server.events))
+
+	err = app.Run([]string{app.Name, "discovery", "include-range", "delete", "-b", "10.0.2.1", "-e", "10.0.2.254"})
+	assert.Error(t, err, "Include range 10.0.2.1-10.0.2.254 doesn't exist")
+
+	err = app.Run([]string{app.Name, "discovery", "include-range", "delete", "-b", "10.0.0.1", "-e", "10.0.0.254", "--reload"})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(server.config.IncludeRanges))
+	assert.Equal(t, "10.0.1.1", server.config.IncludeRanges[0].Begin)
+	assert.Equal(t, 1, len(server.events))
+	assert.Equal(t, "uei.opennms.org/internal/reloadDaemonConfig", server.events[0].UEI)
+	assert.Equal(t, "Discovery", server.events[0].Parameters[0].Value)
+}
+
+func TestExcludeRange(t *testing.T) {
+	var err error
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "discovery", "exclude-range", "add", "-b", "10.0.0.100", "-e", "10.0.0.110"})
+	assert.NilError(t, err)
+	assert.Equal(t, "10.0.0.110", server.config.ExcludeRanges[0].End)
+
+	err = app.Run([]string{app.Name, "discovery", "exclude-range", "del", "-b", "10.0.0.100", "-e", "10.0.0.110"})
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(server.config.ExcludeRanges))
+}
+
+func TestSpecific(t *testing.T) {
+	var err error
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "discovery", "specific", "add"})
+	assert.Error(t, err, "IP address required")
+
+	err = app.Run([]string{app.Name, "discovery", "specific", "add", "10.0.0.500"})
+	assert.Error(t, err, "Invalid specific IP address 10.0.0.500")
+
+	err = app.Run([]string{app.Name, "discovery", "specific", "add", "-fs", "Servers", "10.0.5.1"})
+	assert.NilError(t, err)
+	assert.Equal(t, "10.0.5.1", server.config.Specifics[0].Address)
+	assert.Equal(t, "Servers", server.config.Specifics[0].ForeignSource)
+
+	err = app.Run([]string{app.Name, "discovery", "specific", "delete", "10.0.5.2"})
+	assert.Error(t, err, "Specific 10.0.5.2 doesn't exist")
+
+	err = app.Run([]string{app.Name, "discovery", "specific", "delete", "10.0.5.1"})
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(server.config.Specifics))
+}
+
+func TestIncludeURL(t *testing.T) {
+	var err error
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "discovery", "url", "add", "include.txt"})
+	assert.Error(t, err, "Invalid include URL include.txt")
+
+	err = app.Run([]string{app.Name, "discovery", "url", "add", "file:/opt/opennms/etc/include.txt"})
+	assert.NilError(t, err)
+	assert.Equal(t, "file:/opt/opennms/etc/include.txt", server.config.IncludeURLs[0].URL)
+
+	err = app.Run([]string{app.Name, "discovery", "url", "delete", "file:/opt/opennms/etc/include.txt"})
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(server.config.IncludeURLs))
+}
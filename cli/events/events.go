@@ -1,34 +1,43 @@
 package events
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/OpenNMS/onmsctl/api"
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
-	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/OpenNMS/onmsctl/services"
 	"github.com/urfave/cli"
-
 	"gopkg.in/yaml.v2"
 )
 
-var severities = &model.EnumValue{
-	Enum: model.Severities.Enum,
-}
+// DefaultMaxParmSize the default maximum size in bytes of the files used as parameter values
+const DefaultMaxParmSize = 64 * 1024
+
+var severities = model.NewEnum(model.Severities.Enum...)
 
 // CliCommand the CLI command to manage events
 var CliCommand = cli.Command{
 	Name:  "events",
 	Usage: "Manage events",
 	Subcommands: []cli.Command{
+		listCommand,
+		ueisCommand,
 		{
-			Name:      "send",
-			Usage:     "Sends an event to OpenNMS",
-			ArgsUsage: "<uei>",
-			Action:    sendEvent,
-			Flags: []cli.Flag{
+			Name:         "send",
+			Usage:        "Sends an event to OpenNMS",
+			ArgsUsage:    "<uei>",
+			Action:       sendEvent,
+			BashComplete: sendBashComplete,
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "host",
 					Usage: "IP address or FQDN of the host that sends the event",
@@ -62,23 +71,81 @@ var CliCommand = cli.Command{
 					Name:  "parm, p",
 					Usage: "An event parameter (e.x. --parm 'url=http://www.google.com/')",
 				},
-			},
+				cli.StringSliceFlag{
+					Name:  "parm-file",
+					Usage: "An event parameter whose value is the content of a file (e.x. --parm-file 'payload=@/tmp/payload.json')",
+				},
+				cli.StringSliceFlag{
+					Name:  "parm-type",
+					Usage: "The type of an event parameter: " + model.EventParamTypes.EnumAsString() + " (e.x. --parm-type 'count=int')",
+				},
+				cli.GenericFlag{
+					Name:  "encode",
+					Value: model.NewEnum(model.EventParamEncodings.Enum...).WithDefault(model.EventParamEncodings.Default),
+					Usage: "The encoding of the values read with --parm-file: " + model.EventParamEncodings.EnumAsString() + " (use base64 for binary content)",
+				},
+				cli.IntFlag{
+					Name:  "max-parm-size",
+					Value: DefaultMaxParmSize,
+					Usage: "The maximum size in bytes of the files read with --parm-file",
+				},
+				cli.BoolFlag{
+					Name:  "strict-uei",
+					Usage: "Reject the UEIs not defined on the event configuration of the server, instead of warning about them",
+				},
+			}, sinkFlags...),
 		},
 		{
-			Name:      "apply",
-			Usage:     "Sends an event to OpenNMS in YAML format",
-			Action:    applyEvent,
-			ArgsUsage: "<yaml>",
-			Flags: []cli.Flag{
+			Name:         "apply",
+			Usage:        "Sends one or more events to OpenNMS in YAML format (a single event, a list, or multiple documents)",
+			Action:       applyEvents,
+			ArgsUsage:    "<yaml>",
+			BashComplete: eventsBashComplete,
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "file, f",
 					Usage: "External YAML file (use '-' for STDIN Pipe)",
 				},
-			},
+				cli.IntFlag{
+					Name:  "concurrency, n",
+					Value: 1,
+					Usage: "Number of events sent in parallel (with 1, events are sent in order)",
+				},
+				cli.BoolFlag{
+					Name:  "continue-on-error",
+					Usage: "Exit successfully even if some events were rejected",
+				},
+			}, sinkFlags...),
 		},
+		roundtripCommand,
 	},
 }
 
+// The flags to choose where the events are sent, overriding the eventSink settings of the profile
+var sinkFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "via",
+		Usage: "Where the events are sent: " + strings.Join(services.EventSinkNames(), ", ") + " (default: " + services.DefaultEventSink + ")",
+	},
+	cli.StringSliceFlag{
+		Name:  "brokers",
+		Usage: "A Kafka bootstrap server as host:port, when using --via kafka",
+	},
+	cli.StringFlag{
+		Name:  "topic",
+		Usage: "The Kafka topic for the events, when using --via kafka",
+	},
+}
+
+func eventsBashComplete(c *cli.Context) {
+	common.FlagValuesBashComplete(services.EventSinkNames(), "via")(c)
+}
+
+func sendBashComplete(c *cli.Context) {
+	eventsBashComplete(c)
+	ueiBashComplete(c)
+}
+
 func sendEvent(c *cli.Context) error {
 	if !c.Args().Present() {
 		return fmt.Errorf("UEI required")
@@ -95,29 +162,201 @@ func sendEvent(c *cli.Context) error {
 		Host:        c.String("host"),
 		Source:      "onmsctl",
 	}
-	params := c.StringSlice("parm")
-	for _, p := range params {
-		data := strings.Split(p, "=")
-		event.AddParameter(data[0], data[1])
+	types := make(map[string]string)
+	for _, t := range c.StringSlice("parm-type") {
+		data := strings.SplitN(t, "=", 2)
+		if len(data) != 2 {
+			return fmt.Errorf("Invalid parameter type %s, expected key=type", t)
+		}
+		types[data[0]] = data[1]
+	}
+	for _, p := range c.StringSlice("parm") {
+		data := strings.SplitN(p, "=", 2)
+		if len(data) != 2 {
+			return fmt.Errorf("Invalid parameter %s, expected key=value", p)
+		}
+		event.AddTypedParameter(data[0], data[1], types[data[0]])
+		delete(types, data[0])
+	}
+	for _, p := range c.StringSlice("parm-file") {
+		param, err := readParmFile(p, c.String("encode"), c.Int("max-parm-size"))
+		if err != nil {
+			return err
+		}
+		param.Type = types[param.Name]
+		event.Parameters = append(event.Parameters, *param)
+		delete(types, param.Name)
+	}
+	for key := range types {
+		return fmt.Errorf("Type set for unknown parameter %s", key)
+	}
+	if err := verifyUEI(c, uei, c.Bool("strict-uei")); err != nil {
+		return err
+	}
+	sink, err := getEventSink(c)
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+	return sink.SendEvent(event)
+}
+
+// Builds a parameter from a key=@file expression, with the content of the file as its value
+func readParmFile(expression string, encoding string, maxSize int) (*model.EventParam, error) {
+	data := strings.SplitN(expression, "=", 2)
+	if len(data) != 2 || data[0] == "" || !strings.HasPrefix(data[1], "@") || len(data[1]) == 1 {
+		return nil, fmt.Errorf("Invalid parameter file %s, expected key=@/path/to/file", expression)
+	}
+	key, file := data[0], data[1][1:]
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the value of parameter %s: %w", key, err)
+	}
+	if info.Size() > int64(maxSize) {
+		return nil, fmt.Errorf("The value of parameter %s has %d bytes, more than the limit of %d bytes; use --max-parm-size to change it", key, info.Size(), maxSize)
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the value of parameter %s: %w", key, err)
+	}
+	if encoding == "base64" {
+		return &model.EventParam{Name: key, Value: base64.StdEncoding.EncodeToString(content), Encoding: encoding}, nil
+	}
+	if !isText(content) {
+		return nil, fmt.Errorf("The content of %s for parameter %s is not text; use --encode base64 for binary content", file, key)
+	}
+	return &model.EventParam{Name: key, Value: string(content)}, nil
+}
+
+// Verifies that the content is valid UTF-8 without control characters other than tabs and line breaks, as they cannot be represented in XML
+func isText(content []byte) bool {
+	if !utf8.Valid(content) {
+		return false
 	}
-	return getAPI().SendEvent(event)
+	for _, r := range string(content) {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return false
+		}
+	}
+	return true
 }
 
-func applyEvent(c *cli.Context) error {
+func applyEvents(c *cli.Context) error {
 	data, err := common.ReadInput(c, 0)
 	if err != nil {
 		return err
 	}
-	event := model.Event{}
-	if err := yaml.Unmarshal(data, &event); err != nil {
+	events, err := parseEvents(data)
+	if err != nil {
 		return err
 	}
-	if err := event.Validate(); err != nil {
-		return err
+	if len(events) == 0 {
+		return fmt.Errorf("There are no events on the content")
+	}
+	errors := make([]error, len(events))
+	valid := make([]model.Event, 0, len(events))
+	indexes := make([]int, 0, len(events))
+	for i := range events {
+		if errors[i] = events[i].Validate(); errors[i] == nil {
+			valid = append(valid, events[i])
+			indexes = append(indexes, i)
+		}
 	}
-	return getAPI().SendEvent(event)
+	if common.DryRun {
+		var data []byte
+		if len(events) == 1 && len(valid) == 1 {
+			data, err = yaml.Marshal(valid[0])
+		} else {
+			data, err = yaml.Marshal(valid)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(common.Output, string(data))
+	} else {
+		sink, err := getEventSink(c)
+		if err != nil {
+			return err
+		}
+		defer sink.Close()
+		for i, err := range sink.SendEvents(valid, c.Int("concurrency")) {
+			errors[indexes[i]] = err
+		}
+	}
+	return reportEvents(errors, len(valid), c.Bool("continue-on-error"))
 }
 
-func getAPI() api.EventsAPI {
-	return services.GetEventsAPI(rest.Instance)
+// Prints how many events were accepted, and the index of the events that failed with the reason
+func reportEvents(errors []error, valid int, continueOnError bool) error {
+	failed := 0
+	invalid := len(errors) - valid
+	for i, err := range errors {
+		if err != nil {
+			common.Log.Errorf("Event %d failed: %s", i, err)
+			failed++
+		}
+	}
+	action := "accepted"
+	if common.DryRun {
+		action = "valid"
+	}
+	common.Log.Infof("%d of %d events %s", len(errors)-failed, len(errors), action)
+	if failed == 0 || continueOnError {
+		return nil
+	}
+	err := fmt.Errorf("%d of %d events failed", failed, len(errors))
+	if failed == invalid {
+		return common.ValidationError(err)
+	}
+	return err
+}
+
+// Parses a YAML content with a single event, a list of events, or multiple documents with either of them
+func parseEvents(data []byte) ([]model.Event, error) {
+	events := make([]model.Event, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document interface{}
+		if err := decoder.Decode(&document); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if document == nil {
+			continue
+		}
+		content, err := yaml.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		if _, isList := document.([]interface{}); isList {
+			list := make([]model.Event, 0)
+			if err := yaml.Unmarshal(content, &list); err != nil {
+				return nil, err
+			}
+			events = append(events, list...)
+		} else {
+			event := model.Event{}
+			if err := yaml.Unmarshal(content, &event); err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// Returns the event sink from the profile settings, overridden by the command flags
+func getEventSink(c *cli.Context) (api.EventSink, error) {
+	settings := common.GetClient(c).EventSink
+	if c.IsSet("via") {
+		settings.Via = c.String("via")
+	}
+	if c.IsSet("brokers") {
+		settings.Brokers = c.StringSlice("brokers")
+	}
+	if c.IsSet("topic") {
+		settings.Topic = c.String("topic")
+	}
+	return services.GetEventSink(common.GetClient(c), settings, time.Duration(common.GetClient(c).Timeout)*time.Second)
 }
@@ -2,14 +2,22 @@ package events
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/OpenNMS/onmsctl/api"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/services"
 	"github.com/OpenNMS/onmsctl/test"
 	"github.com/google/go-cmp/cmp"
 
@@ -25,11 +33,17 @@ var mockData = &model.Event{
 	Source:    "onmsctl",
 	Parameters: []model.EventParam{
 		{Name: "owner", Value: "agalue"},
+		{Name: "url", Value: "http://host/?a=b&c=d"},
+		{Name: "count", Value: "5", Type: "int"},
 	},
 }
 
 func createMockServer(t *testing.T) *httptest.Server {
 	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/rest/eventconf" { // Like the servers without the event configuration endpoint
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
 		assert.Assert(t, strings.HasPrefix(req.URL.Path, "/rest/events"))
 		assert.Equal(t, http.MethodPost, req.Method)
 		event := &model.Event{}
@@ -39,7 +53,6 @@ func createMockServer(t *testing.T) *httptest.Server {
 		assert.Assert(t, cmp.Equal(mockData, event))
 		res.WriteHeader(http.StatusOK)
 	}))
-	rest.Instance.URL = server.URL
 	return server
 }
 
@@ -47,12 +60,22 @@ func TestSendEvent(t *testing.T) {
 	var err error
 	app := test.CreateCli(CliCommand)
 	server := createMockServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "events", "send"})
 	assert.Error(t, err, "UEI required")
 
-	err = app.Run([]string{app.Name, "events", "send", "-n", "10", "-i", "10.0.0.1", "-s", "SNMP", "-p", "owner=agalue", "uei.opennms.org/test"})
+	err = app.Run([]string{app.Name, "events", "send", "-p", "owner", "uei.opennms.org/test"})
+	assert.Error(t, err, "Invalid parameter owner, expected key=value")
+
+	err = app.Run([]string{app.Name, "events", "send", "-p", "count=five", "--parm-type", "count=int", "uei.opennms.org/test"})
+	assert.Error(t, err, "Invalid integer value five for parameter count")
+
+	err = app.Run([]string{app.Name, "events", "send", "-p", "count=5", "--parm-type", "total=int", "uei.opennms.org/test"})
+	assert.Error(t, err, "Type set for unknown parameter total")
+
+	err = app.Run([]string{app.Name, "events", "send", "-n", "10", "-i", "10.0.0.1", "-s", "SNMP", "-p", "owner=agalue", "-p", "url=http://host/?a=b&c=d", "-p", "count=5", "--parm-type", "count=int", "uei.opennms.org/test"})
 	assert.NilError(t, err)
 }
 
@@ -60,9 +83,185 @@ func TestApplyEvent(t *testing.T) {
 	var err error
 	app := test.CreateCli(CliCommand)
 	server := createMockServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	yamlBytes, _ := yaml.Marshal(mockData)
 	err = app.Run([]string{app.Name, "events", "apply", string(yamlBytes)})
 	assert.NilError(t, err)
 }
+
+func TestApplyMultipleEvents(t *testing.T) {
+	var err error
+	var mutex sync.Mutex
+	received := make([]string, 0)
+	app := test.CreateCli(CliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		event := &model.Event{}
+		bytes, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(bytes, event)
+		if event.UEI == "uei.opennms.org/rejected" {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mutex.Lock()
+		received = append(received, event.UEI)
+		mutex.Unlock()
+	}))
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	events, err := parseEvents([]byte(`
+- uei: uei.opennms.org/test/1
+- uei: uei.opennms.org/test/2
+---
+uei: uei.opennms.org/test/3
+nodeID: 1
+---
+`))
+	assert.NilError(t, err)
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, int64(1), events[2].NodeID)
+
+	err = app.Run([]string{app.Name, "events", "apply", "--", "- uei: uei.opennms.org/test/1\n- uei: uei.opennms.org/test/2\n---\nuei: uei.opennms.org/test/3\n"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"uei.opennms.org/test/1", "uei.opennms.org/test/2", "uei.opennms.org/test/3"}, received)
+
+	received = received[:0]
+	content := "- uei: uei.opennms.org/test/1\n- uei: uei.opennms.org/rejected\n- interface: 10.0.0.1\n- uei: uei.opennms.org/test/4\n"
+	err = app.Run([]string{app.Name, "events", "apply", "--", content})
+	assert.Error(t, err, "2 of 4 events failed")
+	assert.Equal(t, 2, len(received))
+
+	err = app.Run([]string{app.Name, "events", "apply", "--", "- uei: uei.opennms.org/test/1\n- severity: Unknown\n"})
+	assert.Error(t, err, "1 of 2 events failed")
+	assert.Equal(t, 2, err.(interface{ ExitStatus() int }).ExitStatus())
+
+	received = received[:0]
+	err = app.Run([]string{app.Name, "events", "apply", "--continue-on-error", "-n", "4", "--", content})
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(received))
+
+	err = app.Run([]string{app.Name, "events", "apply", "--", "---\n"})
+	assert.Error(t, err, "There are no events on the content")
+}
+
+// memorySink an event sink that keeps the events, to verify that new sinks don't require changes on the commands
+type memorySink struct {
+	lock   *sync.Mutex
+	events *[]model.Event
+	closed *bool
+}
+
+func (sink memorySink) SendEvent(event model.Event) error {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+	*sink.events = append(*sink.events, event)
+	return nil
+}
+
+func (sink memorySink) SendEvents(events []model.Event, concurrency int) []error {
+	errors := make([]error, len(events))
+	for i, e := range events {
+		errors[i] = sink.SendEvent(e)
+	}
+	return errors
+}
+
+func (sink memorySink) Close() error {
+	*sink.closed = true
+	return nil
+}
+
+func TestSendEventViaSink(t *testing.T) {
+	var err error
+	events := make([]model.Event, 0)
+	closed := false
+	var topic string
+	services.RegisterEventSink("memory", func(client api.RestAPI, settings rest.EventSinkSettings, timeout time.Duration) (api.EventSink, error) {
+		topic = settings.Topic
+		return memorySink{&sync.Mutex{}, &events, &closed}, nil
+	})
+	app := test.CreateCli(CliCommand)
+
+	err = app.Run([]string{app.Name, "events", "send", "--via", "carrier-pigeon", "uei.opennms.org/test"})
+	assert.ErrorContains(t, err, "Invalid event sink carrier-pigeon, valid options: kafka, memory, rest")
+
+	err = app.Run([]string{app.Name, "events", "send", "--via", "kafka", "uei.opennms.org/test"})
+	assert.Error(t, err, "Kafka topic required")
+
+	err = app.Run([]string{app.Name, "events", "send", "--via", "memory", "--topic", "events", "uei.opennms.org/test"})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "events", topic)
+	assert.Assert(t, closed)
+
+	// The sink can be defined on the profile
+	test.Client(app).EventSink = rest.EventSinkSettings{Via: "memory", Topic: "profile"}
+	yamlBytes, _ := yaml.Marshal(mockData)
+	err = app.Run([]string{app.Name, "events", "apply", string(yamlBytes)})
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "profile", topic)
+	assert.DeepEqual(t, *mockData, events[1])
+}
+
+func TestSendEventWithParmFile(t *testing.T) {
+	var err error
+	var received model.Event
+	app := test.CreateCli(CliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/rest/eventconf" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		bytes, _ := ioutil.ReadAll(req.Body)
+		received = model.Event{}
+		assert.NilError(t, json.Unmarshal(bytes, &received))
+		res.Write(bytes) // Echoes the event, like a server that accepted it
+	}))
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	payload := "{\n  \"service\": \"HTTP\",\n  \"body\": \"<html>a & b</html>\"\n}\n"
+	jsonFile := filepath.Join(dir, "payload.json")
+	assert.NilError(t, ioutil.WriteFile(jsonFile, []byte(payload), 0644))
+	binaryFile := filepath.Join(dir, "payload.bin")
+	assert.NilError(t, ioutil.WriteFile(binaryFile, []byte{0, 1, 2, 255}, 0644))
+
+	err = app.Run([]string{app.Name, "events", "send", "--parm-file", "payload=@" + jsonFile, "-p", "owner=agalue", "uei.opennms.org/test"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []model.EventParam{{Name: "owner", Value: "agalue"}, {Name: "payload", Value: payload}}, received.Parameters)
+
+	// The value received by the server survives the XML representation used by the other event sinks
+	data, err := xml.Marshal(model.EventLog{Events: []model.Event{received}})
+	assert.NilError(t, err)
+	parsed := model.EventLog{}
+	assert.NilError(t, xml.Unmarshal(data, &parsed))
+	assert.Equal(t, payload, parsed.Events[0].Parameters[1].Value)
+
+	err = app.Run([]string{app.Name, "events", "send", "--parm-file", "payload=@" + binaryFile, "uei.opennms.org/test"})
+	assert.Error(t, err, "The content of "+binaryFile+" for parameter payload is not text; use --encode base64 for binary content")
+
+	err = app.Run([]string{app.Name, "events", "send", "--parm-file", "payload=@" + binaryFile, "--encode", "base64", "uei.opennms.org/test"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []model.EventParam{{Name: "payload", Value: "AAEC/w==", Encoding: "base64"}}, received.Parameters)
+
+	err = app.Run([]string{app.Name, "events", "send", "--parm-file", "payload=@" + jsonFile, "--max-parm-size", "10", "uei.opennms.org/test"})
+	assert.Error(t, err, fmt.Sprintf("The value of parameter payload has %d bytes, more than the limit of 10 bytes; use --max-parm-size to change it", len(payload)))
+
+	err = app.Run([]string{app.Name, "events", "send", "--parm-file", "payload=" + jsonFile, "uei.opennms.org/test"})
+	assert.Error(t, err, "Invalid parameter file payload="+jsonFile+", expected key=@/path/to/file")
+
+	err = app.Run([]string{app.Name, "events", "send", "--parm-file", "payload=@" + filepath.Join(dir, "missing.json"), "uei.opennms.org/test"})
+	assert.ErrorContains(t, err, "Cannot read the value of parameter payload")
+
+	// The same payload from YAML reaches the server intact
+	yamlBytes, _ := yaml.Marshal(model.Event{UEI: "uei.opennms.org/test", Parameters: []model.EventParam{{Name: "payload", Value: payload}}})
+	err = app.Run([]string{app.Name, "events", "apply", string(yamlBytes)})
+	assert.NilError(t, err)
+	assert.Equal(t, payload, received.Parameters[0].Value)
+}
@@ -0,0 +1,169 @@
+package events
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// The maximum number of new events fetched on each poll when following events
+const followBatchSize = 1000
+
+var listSeverities = model.NewEnum(model.AlarmSeverities.Enum...)
+
+// listCommand the CLI command to search the events from the OpenNMS database
+var listCommand = cli.Command{
+	Name:   "list",
+	Usage:  "List events from the database, newest first; use --follow to keep showing new events",
+	Action: listEvents,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "uei, u",
+			Usage: "The UEI of the events, accepts wildcards (e.x. 'uei.opennms.org/generic/traps/*')",
+		},
+		cli.StringFlag{
+			Name:  "node, n",
+			Usage: "The node ID or node label of the events",
+		},
+		cli.GenericFlag{
+			Name:  "severity, s",
+			Value: listSeverities,
+			Usage: "The severity of the events: " + listSeverities.EnumAsString(),
+		},
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "Only events created after the given time (e.x. 2h, '2h ago', now-1d, '2024-01-02 15:04' in local time, or an RFC3339 timestamp)",
+		},
+		cli.StringFlag{
+			Name:  "until",
+			Usage: "Only events created before the given time, with the same formats as --since",
+		},
+		cli.StringFlag{
+			Name:  "filter, f",
+			Usage: "A FIQL expression to filter events (e.x. 'event.source==syslogd')",
+		},
+		cli.IntFlag{
+			Name:  "limit, l",
+			Usage: "The amount of events per query",
+			Value: 10,
+		},
+		cli.IntFlag{
+			Name:  "offset",
+			Usage: "The starting event index (for pagination)",
+			Value: 0,
+		},
+		common.AllPagesFlag,
+		cli.BoolFlag{
+			Name:  "follow, F",
+			Usage: "Keep polling the server, showing only the new events, until Ctrl-C is pressed",
+		},
+		cli.DurationFlag{
+			Name:  "interval, i",
+			Usage: "Time between polls when following events",
+			Value: 5 * time.Second,
+		},
+	},
+}
+
+func listEvents(c *cli.Context) error {
+	filter, err := buildEventsFilter(c, time.Now())
+	if err != nil {
+		return err
+	}
+	var list *model.OnmsEventList
+	if c.Bool("all") {
+		list, err = getQueryAPI(c).GetAllEvents(filter)
+	} else {
+		list, err = getQueryAPI(c).GetEvents(filter, c.Int("limit"), c.Int("offset"))
+	}
+	if err != nil {
+		return err
+	}
+	// The events are fetched newest first, but displayed in chronological order
+	events := make([]model.OnmsEvent, len(list.Events))
+	for i, e := range list.Events {
+		events[len(events)-1-i] = e
+	}
+	if !c.Bool("follow") {
+		if err := printEvents(events, "There are no events"); err != nil {
+			return err
+		}
+		if list.TotalCount > list.Offset+len(list.Events) && common.OutputFormat == common.OutputTable {
+			common.Log.Infof("Showing %d of %d events; use --all, or --offset and --limit, to see more", len(list.Events), list.TotalCount)
+		}
+		return nil
+	}
+	return followEvents(c, filter, events)
+}
+
+// Polls for events newer than the last one displayed; it stops without errors when cancelled
+func followEvents(c *cli.Context, filter string, events []model.OnmsEvent) error {
+	lastID := 0
+	noHeaders := common.NoHeaders
+	defer func() { common.NoHeaders = noHeaders }()
+	for {
+		if len(events) > 0 {
+			if err := printEvents(events, ""); err != nil {
+				return err
+			}
+			lastID = events[len(events)-1].ID
+			common.NoHeaders = true // Show the headers only once
+		}
+		if err := common.GetClient(c).Sleep(c.Duration("interval")); err != nil {
+			return nil
+		}
+		list, err := getQueryAPI(c).GetEventsAfter(lastID, filter, followBatchSize)
+		if err == rest.ErrCancelled {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		events = list.Events
+	}
+}
+
+func printEvents(events []model.OnmsEvent, empty string) error {
+	table := common.NewTable(empty, "ID", "Time", "Severity", "UEI", "Log Message")
+	for _, e := range events {
+		table.AddRow(e.ID, common.DisplayTime(e.CreateTime), e.Severity, e.UEI, strings.TrimSpace(e.LogMessage))
+	}
+	return common.Print(events, table)
+}
+
+func buildEventsFilter(c *cli.Context, now time.Time) (string, error) {
+	builder := fiql.NewBuilder()
+	if uei := c.String("uei"); uei != "" {
+		builder.Equals("event.uei", uei)
+	}
+	if node := c.String("node"); node != "" {
+		if _, err := strconv.Atoi(node); err == nil {
+			builder.Equals("node.id", node)
+		} else {
+			builder.Equals("node.label", node)
+		}
+	}
+	if severity := c.String("severity"); severity != "" {
+		builder.Equals("event.severity", strings.ToUpper(severity))
+	}
+	timeRange, err := common.TimeRangeFilter("event.createTime", c.String("since"), c.String("until"), now)
+	if err != nil {
+		return "", err
+	}
+	for _, expression := range timeRange {
+		builder.Filter(expression)
+	}
+	return builder.Filter(c.String("filter")).Build()
+}
+
+func getQueryAPI(c *cli.Context) api.EventsQueryAPI {
+	return services.GetEventsQueryAPI(common.GetClient(c))
+}
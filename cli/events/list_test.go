@@ -0,0 +1,131 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"github.com/urfave/cli"
+	"gotest.tools/assert"
+)
+
+func createEvent(id int, uei string, severity string, message string) model.OnmsEvent {
+	created := &model.Time{Time: time.Date(2020, 1, 1, 10, 0, id, 0, time.UTC)}
+	return model.OnmsEvent{ID: id, UEI: uei, Severity: severity, CreateTime: created, LogMessage: message}
+}
+
+var mockEvents = []model.OnmsEvent{
+	createEvent(2, "uei.opennms.org/generic/traps/EnterpriseDefault", "WARNING", "Received unformatted enterprise event"),
+	createEvent(1, "uei.opennms.org/nodes/nodeDown", "MAJOR", "Node is down"),
+}
+
+func createQueryMockServer(t *testing.T, filters *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/v2/events", req.URL.Path)
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, "id", req.URL.Query().Get("orderBy"))
+		filter := req.URL.Query().Get("_s")
+		*filters = append(*filters, filter)
+		var events []model.OnmsEvent
+		switch {
+		case strings.HasPrefix(filter, "event.id=gt=2"):
+			assert.Equal(t, "asc", req.URL.Query().Get("order"))
+			events = []model.OnmsEvent{createEvent(3, "uei.opennms.org/syslogd/user/Error", "MINOR", "Syslog error")}
+		case strings.HasPrefix(filter, "event.id=gt="):
+			events = nil
+		default:
+			assert.Equal(t, "desc", req.URL.Query().Get("order"))
+			events = mockEvents
+		}
+		if len(events) == 0 {
+			res.WriteHeader(http.StatusNoContent)
+			return
+		}
+		bytes, _ := json.Marshal(model.OnmsEventList{Count: len(events), TotalCount: 5, Events: events})
+		res.Write(bytes)
+	}))
+	return server
+}
+
+func TestListEvents(t *testing.T) {
+	filters := []string{}
+	app := test.CreateCli(CliCommand)
+	server := createQueryMockServer(t, &filters)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	defer func() { *listSeverities = model.EnumValue{Enum: listSeverities.Enum} }()
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	output, err := test.RunWithOutput(app, "jsonpath=$[*].id", "events", "list", "--node", "10")
+	assert.NilError(t, err)
+	assert.Equal(t, "node.id==10", filters[0])
+	assert.Equal(t, "1\n2\n", output)
+
+	output, err = test.RunWithOutput(app, "table", "events", "list", "--uei", "uei.opennms.org/*", "--node", "web01", "--severity", "Major", "--filter", "event.source==syslogd")
+	assert.NilError(t, err)
+	assert.Equal(t, "event.uei==uei.opennms.org/*;node.label==web01;event.severity==MAJOR;event.source==syslogd", filters[1])
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[1], "1   "))
+	assert.Assert(t, strings.Contains(lines[1], "MAJOR     uei.opennms.org/nodes/nodeDown"))
+	assert.Assert(t, strings.HasPrefix(lines[2], "2   "))
+	assert.Equal(t, "Showing 2 of 5 events; use --all, or --offset and --limit, to see more\n", logs.String())
+
+	_, err = test.RunWithOutput(app, "table", "events", "list", "--since", "yesterday")
+	assert.ErrorContains(t, err, "Invalid time yesterday")
+}
+
+func TestEventsSince(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	var filter string
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{cli.StringFlag{Name: "since"}, cli.StringFlag{Name: "until"}}
+	app.Action = func(c *cli.Context) error {
+		var err error
+		filter, err = buildEventsFilter(c, now)
+		return err
+	}
+	for since, expected := range map[string]string{
+		"2h":            "event.createTime=gt=2020-01-01T10:00:00.000+0000",
+		"-30m":          "event.createTime=gt=2020-01-01T11:30:00.000+0000",
+		"now-1d":        "event.createTime=gt=2019-12-31T12:00:00.000+0000",
+		"1577869200000": "event.createTime=gt=" + time.Unix(1577869200, 0).Format(common.FIQLTimeFormat),
+		"3h ago":        "event.createTime=gt=2020-01-01T09:00:00.000+0000",
+	} {
+		assert.NilError(t, app.Run([]string{app.Name, "--since", since}))
+		assert.Equal(t, expected, filter)
+	}
+
+	assert.NilError(t, app.Run([]string{app.Name, "--since", "2020-01-01 08:00", "--until", "1h ago"}))
+	assert.Equal(t, "event.createTime=gt=2020-01-01T08:00:00.000+0000;event.createTime=lt=2020-01-01T11:00:00.000+0000", filter)
+	assert.ErrorContains(t, app.Run([]string{app.Name, "--since", "1h", "--until", "2h"}), "must be before the --until time")
+}
+
+func TestFollowEvents(t *testing.T) {
+	filters := []string{}
+	server := createQueryMockServer(t, &filters)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL), rest.WithContext(ctx))
+
+	output, err := test.RunWithOutput(app, "table", "events", "list", "--follow", "--interval", "20ms", "--uei", "uei.opennms.org/*")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[0], "ID"))
+	assert.Assert(t, strings.HasPrefix(lines[3], "3  "))
+	assert.Assert(t, len(filters) > 2)
+	assert.Equal(t, "event.id=gt=2;event.uei==uei.opennms.org/*", filters[1])
+	assert.Equal(t, "event.id=gt=3;event.uei==uei.opennms.org/*", filters[2])
+}
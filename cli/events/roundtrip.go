@@ -0,0 +1,143 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// The UEI sent by default by roundtrip, which is stored on the database without side effects
+const defaultRoundtripUEI = "uei.opennms.org/internal/authentication/successfulLogin"
+
+// The maximum number of new events fetched on each poll while waiting for the roundtrip event
+const roundtripBatchSize = 100
+
+// roundtripCommand the CLI command to verify that the events sent to OpenNMS are stored on its database
+var roundtripCommand = cli.Command{
+	Name:  "roundtrip",
+	Usage: "Sends an event with a unique parameter, and waits until it is stored on the database, reporting the end-to-end latency",
+	Description: "Verifies the whole event pipeline, for example to monitor OpenNMS itself. The ID of the stored event is printed for reference;\n" +
+		"   events are immutable, so nothing is cleaned up. It fails when the event doesn't appear within --timeout.",
+	Action:       eventRoundtrip,
+	BashComplete: eventsBashComplete,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "uei, u",
+			Value: defaultRoundtripUEI,
+			Usage: "The UEI of the event",
+		},
+		cli.StringFlag{
+			Name:  "parm-key, k",
+			Value: "onmsctlRoundtrip",
+			Usage: "The name of the parameter with the unique value used to find the event",
+		},
+		cli.DurationFlag{
+			Name:  "interval, i",
+			Value: time.Second,
+			Usage: "Time between polls of the events stored on the database",
+		},
+		cli.DurationFlag{
+			Name:  "timeout, t",
+			Value: 30 * time.Second,
+			Usage: "The maximum time to wait for the event to be stored",
+		},
+	}, sinkFlags...),
+}
+
+func eventRoundtrip(c *cli.Context) error {
+	uei := c.String("uei")
+	key := c.String("parm-key")
+	interval := c.Duration("interval")
+	timeout := c.Duration("timeout")
+	switch {
+	case uei == "":
+		return fmt.Errorf("UEI required")
+	case key == "":
+		return fmt.Errorf("Parameter key required")
+	case interval <= 0:
+		return fmt.Errorf("Interval must be greater than zero")
+	case timeout <= 0:
+		return fmt.Errorf("Timeout must be greater than zero")
+	}
+	filter, err := fiql.NewBuilder().Equals("event.uei", uei).Build()
+	if err != nil {
+		return err
+	}
+	common.GetClient(c).CacheTTL = -1 // Every poll must reach the server
+
+	// Only the events stored after sending ours are inspected
+	lastID := 0
+	list, err := getQueryAPI(c).GetEvents(filter, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(list.Events) > 0 {
+		lastID = list.Events[0].ID
+	}
+
+	value, err := newRoundtripID()
+	if err != nil {
+		return err
+	}
+	sink, err := getEventSink(c)
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+	event := model.Event{UEI: uei, Source: "onmsctl"}
+	event.AddParameter(key, value)
+	start := time.Now()
+	if err := sink.SendEvent(event); err != nil {
+		return err
+	}
+	common.Log.Infof("Sent %s with %s=%s, waiting up to %s", uei, key, value, timeout)
+
+	deadline := start.Add(timeout)
+	for {
+		list, err := getQueryAPI(c).GetEventsAfter(lastID, filter, roundtripBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, e := range list.Events {
+			if hasEventParameter(e, key, value) {
+				latency := time.Since(start)
+				fmt.Fprintf(common.Output, "Event %d stored after %s (%s=%s)\n", e.ID, latency.Round(time.Millisecond), key, value)
+				return nil
+			}
+			lastID = e.ID
+		}
+		if len(list.Events) == roundtripBatchSize && time.Now().Before(deadline) {
+			continue // There may be more new events before ours
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("Timed out after %s waiting for the event with %s=%s to be stored", timeout, key, value)
+		}
+		if err := common.GetClient(c).Sleep(interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Returns a value that identifies a single roundtrip
+func newRoundtripID() (string, error) {
+	data := make([]byte, 8)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return "onmsctl-" + hex.EncodeToString(data), nil
+}
+
+func hasEventParameter(e model.OnmsEvent, key string, value string) bool {
+	for _, p := range e.Parameters {
+		if p.Name == key && p.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+// Simulates a server that stores the events sent to it after a given amount of polls
+type roundtripServer struct {
+	mutex   sync.Mutex
+	delay   int // The polls that don't find the event after sending it
+	polls   int
+	filters []string
+	sent    *model.Event
+}
+
+func (s *roundtripServer) handle(t *testing.T) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		switch {
+		case req.URL.Path == "/rest/events" && req.Method == http.MethodPost:
+			assert.Assert(t, s.sent == nil, "only one event must be sent")
+			s.sent = &model.Event{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, s.sent))
+		case req.URL.Path == "/api/v2/events":
+			filter := req.URL.Query().Get("_s")
+			s.filters = append(s.filters, filter)
+			var events []model.OnmsEvent
+			switch filter {
+			case "event.uei==uei.opennms.org/test": // The newest event before sending ours
+				assert.Assert(t, s.sent == nil)
+				events = []model.OnmsEvent{{ID: 10, UEI: "uei.opennms.org/test"}}
+			case "event.id=gt=10;event.uei==uei.opennms.org/test":
+				assert.Assert(t, s.sent != nil)
+				s.polls++
+				// Another roundtrip, running at the same time, is seen first
+				events = []model.OnmsEvent{{ID: 11, UEI: "uei.opennms.org/test", Parameters: []model.OnmsEventParam{{Name: "check", Value: "other"}}}}
+			case "event.id=gt=11;event.uei==uei.opennms.org/test":
+				s.polls++
+				if s.polls > s.delay {
+					p := s.sent.Parameters[0]
+					events = []model.OnmsEvent{{ID: 12, UEI: "uei.opennms.org/test", Parameters: []model.OnmsEventParam{{Name: p.Name, Value: p.Value}}}}
+				}
+			default:
+				t.Errorf("unexpected filter %s", filter)
+			}
+			if len(events) == 0 {
+				res.WriteHeader(http.StatusNoContent)
+				return
+			}
+			bytes, _ := json.Marshal(model.OnmsEventList{Count: len(events), TotalCount: len(events), Events: events})
+			res.Write(bytes)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestEventRoundtrip(t *testing.T) {
+	mock := &roundtripServer{delay: 3}
+	server := httptest.NewServer(mock.handle(t))
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	output, err := test.RunWithOutput(app, "table", "events", "roundtrip", "--uei", "uei.opennms.org/test", "--parm-key", "check", "--interval", "10ms", "--timeout", "5s")
+	assert.NilError(t, err)
+	assert.Assert(t, regexp.MustCompile(`^Event 12 stored after [0-9.]+m?s \(check=onmsctl-[0-9a-f]{16}\)\n$`).MatchString(output), output)
+	assert.Equal(t, "uei.opennms.org/test", mock.sent.UEI)
+	assert.Equal(t, "check", mock.sent.Parameters[0].Name)
+	assert.Equal(t, 4, mock.polls)
+	assert.Equal(t, "event.uei==uei.opennms.org/test", mock.filters[0])
+
+	// Each roundtrip has its own value
+	first := mock.sent.Parameters[0].Value
+	mock.sent, mock.polls, mock.filters = nil, 0, nil
+	_, err = test.RunWithOutput(app, "table", "events", "roundtrip", "--uei", "uei.opennms.org/test", "--parm-key", "check", "--interval", "10ms", "--timeout", "5s")
+	assert.NilError(t, err)
+	assert.Assert(t, first != mock.sent.Parameters[0].Value)
+}
+
+func TestEventRoundtripTimeout(t *testing.T) {
+	mock := &roundtripServer{delay: 1000}
+	server := httptest.NewServer(mock.handle(t))
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	_, err := test.RunWithOutput(app, "table", "events", "roundtrip", "--uei", "uei.opennms.org/test", "--parm-key", "check", "--interval", "10ms", "--timeout", "100ms")
+	assert.ErrorContains(t, err, "Timed out after 100ms waiting for the event with check=onmsctl-")
+	assert.Assert(t, mock.polls > 1)
+
+	_, err = test.RunWithOutput(app, "table", "events", "roundtrip", "--interval", "0s")
+	assert.Error(t, err, "Interval must be greater than zero")
+}
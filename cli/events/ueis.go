@@ -0,0 +1,144 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// EventConfCacheTTL how long the event definitions obtained from a server are kept
+const EventConfCacheTTL = 24 * time.Hour
+
+// EventConfCacheDir where the event definitions are cached, one file per profile; empty disables the cache
+var EventConfCacheDir = ""
+
+// DefaultEventConfCacheDir returns the default location of the event definitions cache (e.x. ~/.cache/onmsctl/eventconf)
+func DefaultEventConfCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "onmsctl", "eventconf")
+}
+
+var ueisCommand = cli.Command{
+	Name:   "ueis",
+	Usage:  "List the event definitions known to the server (UEI, label and severity)",
+	Action: listUEIs,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "filter, f",
+			Usage: "Only the definitions whose UEI or label contain the given text (ignoring case)",
+		},
+		cli.BoolFlag{
+			Name:  "refresh-cache",
+			Usage: "Obtain the event definitions from the server, instead of the ones cached for the current profile",
+		},
+	},
+}
+
+func listUEIs(c *cli.Context) error {
+	list, err := loadEventDefinitions(c, c.Bool("refresh-cache"))
+	if err != nil {
+		return err
+	}
+	events := list.Events
+	if filter := c.String("filter"); filter != "" {
+		events = list.Filter(filter)
+	}
+	table := common.NewTable("There are no matching event definitions", "UEI", "Label", "Severity")
+	for _, e := range events {
+		table.AddRow(e.UEI, e.Label, e.Severity)
+	}
+	return common.Print(events, table)
+}
+
+// Returns the event definitions cached for the current profile, or the ones from the server when the cache is missing,
+// expired or a refresh is requested; when the server doesn't expose its event configuration, the bundled snapshot is used
+func loadEventDefinitions(c *cli.Context, refresh bool) (*model.EventDefinitionList, error) {
+	file := eventConfCacheFile(c)
+	if file != "" && !refresh {
+		if info, err := os.Stat(file); err == nil && time.Since(info.ModTime()) < EventConfCacheTTL {
+			if data, err := ioutil.ReadFile(file); err == nil {
+				list := &model.EventDefinitionList{}
+				if json.Unmarshal(data, list) == nil {
+					return list, nil
+				}
+			}
+		}
+	}
+	list, err := getEventConfAPI(c).GetEventDefinitions()
+	var e *rest.APIError
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		common.Log.Debugf("The server doesn't expose its event configuration, using the bundled event definitions")
+		list, err = services.BundledEventDefinitions(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if file != "" && os.MkdirAll(filepath.Dir(file), 0700) == nil {
+		if data, err := json.Marshal(list); err == nil {
+			ioutil.WriteFile(file, data, 0600)
+		}
+	}
+	return list, nil
+}
+
+func eventConfCacheFile(c *cli.Context) string {
+	if EventConfCacheDir == "" {
+		return ""
+	}
+	profile := common.GetClient(c).ProfileName
+	if profile == "" {
+		profile = rest.DefaultProfile
+	}
+	return filepath.Join(EventConfCacheDir, profile+".json")
+}
+
+// Verifies that the UEI is defined on the server, warning when it isn't, or failing when strict;
+// when the event definitions cannot be obtained, the UEI is only rejected when strict
+func verifyUEI(c *cli.Context, uei string, strict bool) error {
+	list, err := loadEventDefinitions(c, false)
+	if err != nil {
+		if strict {
+			return fmt.Errorf("Cannot verify UEI %s: %w", uei, err)
+		}
+		common.Log.Debugf("Cannot verify UEI %s: %s", uei, err)
+		return nil
+	}
+	if list.GetEvent(uei) != nil {
+		return nil
+	}
+	suggestion := model.DidYouMean(uei, list.UEIs())
+	if strict {
+		return common.ValidationError(fmt.Errorf("UEI %s is not defined on the event configuration%s", uei, suggestion))
+	}
+	common.Log.Warnf("UEI %s is not defined on the event configuration%s; use --strict-uei to reject unknown UEIs", uei, suggestion)
+	return nil
+}
+
+// Suggests the known UEIs for the argument of events send
+func ueiBashComplete(c *cli.Context) {
+	if c.NArg() > 0 || common.CompletingFlag(os.Args, "severity", "x", "via") {
+		return
+	}
+	if list, err := loadEventDefinitions(c, false); err == nil {
+		common.PrintCompletions(list.UEIs()...)
+	}
+}
+
+func getEventConfAPI(c *cli.Context) api.EventConfAPI {
+	return services.GetEventConfAPI(common.GetClient(c))
+}
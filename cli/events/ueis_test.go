@@ -0,0 +1,160 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+var mockEventConf = model.EventDefinitionList{
+	Count: 3,
+	Events: []model.EventDefinition{
+		{UEI: "uei.opennms.org/nodes/nodeDown", Label: "OpenNMS-defined node event: nodeDown", Severity: "Major"},
+		{UEI: "uei.opennms.org/nodes/nodeUp", Label: "OpenNMS-defined node event: nodeUp", Severity: "Normal"},
+		{UEI: "uei.opennms.org/custom/backupFailed", Label: "Custom: Backup Failed", Severity: "Minor"},
+	},
+}
+
+// Creates a server with the given event definitions (or without the eventconf endpoint when nil),
+// which counts the requests for them and accepts events
+func createEventConfServer(t *testing.T, eventConf *model.EventDefinitionList, requests *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/eventconf":
+			*requests++
+			if eventConf == nil {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			bytes, _ := json.Marshal(eventConf)
+			res.Write(bytes)
+		case "/rest/events":
+			assert.Equal(t, http.MethodPost, req.Method)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+// Uses an empty cache directory, returning a function to remove it
+func useTempEventConfCache(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "onmsctl-eventconf")
+	assert.NilError(t, err)
+	EventConfCacheDir = dir
+	return dir, func() {
+		EventConfCacheDir = ""
+		os.RemoveAll(dir)
+	}
+}
+
+func TestListUEIs(t *testing.T) {
+	dir, cleanup := useTempEventConfCache(t)
+	defer cleanup()
+	requests := 0
+	server := createEventConfServer(t, &mockEventConf, &requests)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	output, err := test.RunWithOutput(app, "table", "events", "ueis", "-f", "NODE")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 3, len(lines), output)
+	assert.Assert(t, strings.HasPrefix(lines[1], "uei.opennms.org/nodes/nodeDown"), lines[1])
+	assert.Assert(t, strings.HasSuffix(lines[1], "Major"), lines[1])
+	assert.Equal(t, 1, requests)
+
+	// The definitions are cached per profile
+	_, err = os.Stat(filepath.Join(dir, "default.json"))
+	assert.NilError(t, err)
+	output, err = test.RunWithOutput(app, "json", "events", "ueis", "--filter", "backup")
+	assert.NilError(t, err)
+	events := []model.EventDefinition{}
+	assert.NilError(t, json.Unmarshal([]byte(output), &events))
+	assert.DeepEqual(t, mockEventConf.Events[2:], events)
+	assert.Equal(t, 1, requests)
+
+	_, err = test.RunWithOutput(app, "table", "events", "ueis", "--refresh-cache")
+	assert.NilError(t, err)
+	assert.Equal(t, 2, requests)
+
+	test.Client(app).ProfileName = "lab"
+	_, err = test.RunWithOutput(app, "table", "events", "ueis")
+	assert.NilError(t, err)
+	assert.Equal(t, 3, requests)
+	_, err = os.Stat(filepath.Join(dir, "lab.json"))
+	assert.NilError(t, err)
+}
+
+func TestListBundledUEIs(t *testing.T) {
+	_, cleanup := useTempEventConfCache(t)
+	defer cleanup()
+	requests := 0
+	server := createEventConfServer(t, nil, &requests)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	output, err := test.RunWithOutput(app, "table", "events", "ueis", "-f", "nodeLostService")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 2, len(lines), output)
+	assert.Assert(t, strings.HasPrefix(lines[1], "uei.opennms.org/nodes/nodeLostService"), lines[1])
+}
+
+func TestSendEventUnknownUEI(t *testing.T) {
+	_, cleanup := useTempEventConfCache(t)
+	defer func() { cleanup() }()
+	requests := 0
+	server := createEventConfServer(t, &mockEventConf, &requests)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	err := app.Run([]string{app.Name, "events", "send", "uei.opennms.org/nodes/nodeDown"})
+	assert.NilError(t, err)
+	assert.Equal(t, "", logs.String())
+
+	err = app.Run([]string{app.Name, "events", "send", "uei.opennms.org/nodes/nodeDwon"})
+	assert.NilError(t, err)
+	assert.Equal(t, "WARNING: UEI uei.opennms.org/nodes/nodeDwon is not defined on the event configuration; did you mean 'uei.opennms.org/nodes/nodeDown'?; use --strict-uei to reject unknown UEIs\n", logs.String())
+
+	err = app.Run([]string{app.Name, "events", "send", "--strict-uei", "uei.opennms.org/custom/unknown"})
+	assert.Error(t, err, "UEI uei.opennms.org/custom/unknown is not defined on the event configuration")
+	assert.Equal(t, 1, requests)
+
+	// Without the definitions, the UEI can only be rejected when strict
+	server.Close()
+	cleanup()
+	_, cleanup = useTempEventConfCache(t)
+	logs.Reset()
+	err = app.Run([]string{app.Name, "events", "send", "--strict-uei", "--via", "memory", "--topic", "events", "uei.opennms.org/nodes/nodeDown"})
+	assert.ErrorContains(t, err, "Cannot verify UEI uei.opennms.org/nodes/nodeDown: ")
+}
+
+func TestUEIBashComplete(t *testing.T) {
+	_, cleanup := useTempEventConfCache(t)
+	defer cleanup()
+	requests := 0
+	server := createEventConfServer(t, &mockEventConf, &requests)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+	app.EnableBashCompletion = true
+
+	output, err := test.RunWithOutput(app, "table", "events", "send", "--generate-bash-completion")
+	assert.NilError(t, err)
+	assert.Equal(t, "uei.opennms.org/nodes/nodeDown\nuei.opennms.org/nodes/nodeUp\nuei.opennms.org/custom/backupFailed\n", output)
+}
@@ -0,0 +1,138 @@
+package groups
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// CliCommand the CLI command to manage groups
+var CliCommand = cli.Command{
+	Name:  "groups",
+	Usage: "Manage groups of users",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List all groups",
+			Action: listGroups,
+		},
+		{
+			Name:         "get",
+			Usage:        "Gets a group",
+			ArgsUsage:    "<name>",
+			Action:       showGroup,
+			BashComplete: groupNameBashComplete,
+		},
+		{
+			Name:      "add",
+			Usage:     "Adds or updates a group",
+			ArgsUsage: "<name>",
+			Action:    addGroup,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "comments, c",
+					Usage: "Comments about the group",
+				},
+				cli.StringFlag{
+					Name:  "default-map, m",
+					Usage: "The default map for the members of the group",
+				},
+				cli.StringSliceFlag{
+					Name:  "user, u",
+					Usage: "A member of the group; can be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "duty-schedule, s",
+					Usage: "A duty schedule for notifications (e.x. MoTuWeThFr800-1700); can be repeated",
+				},
+			},
+		},
+		{
+			Name:         "delete",
+			ShortName:    "del",
+			Usage:        "Deletes a group",
+			ArgsUsage:    "<name>",
+			Action:       deleteGroup,
+			BashComplete: groupNameBashComplete,
+		},
+		{
+			Name:         "add-user",
+			Usage:        "Adds a user to a group",
+			ArgsUsage:    "<name> <userId>",
+			Action:       addUser,
+			BashComplete: groupNameBashComplete,
+		},
+		{
+			Name:         "remove-user",
+			Usage:        "Removes a user from a group",
+			ArgsUsage:    "<name> <userId>",
+			Action:       removeUser,
+			BashComplete: groupNameBashComplete,
+		},
+	},
+}
+
+func listGroups(c *cli.Context) error {
+	list, err := getAPI(c).GetGroups()
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no groups", "Name", "Comments", "Users")
+	for _, group := range list.Groups {
+		table.AddRow(group.Name, group.Comments, strings.Join(group.Users, ","))
+	}
+	return common.Print(list.Groups, table)
+}
+
+func showGroup(c *cli.Context) error {
+	group, err := getAPI(c).GetGroup(c.Args().First())
+	if err != nil {
+		return err
+	}
+	return common.Print(group, nil)
+}
+
+func addGroup(c *cli.Context) error {
+	group := model.OnmsGroup{
+		Name:          c.Args().First(),
+		Comments:      c.String("comments"),
+		DefaultMap:    c.String("default-map"),
+		Users:         c.StringSlice("user"),
+		DutySchedules: c.StringSlice("duty-schedule"),
+	}
+	return getAPI(c).AddGroup(group)
+}
+
+func deleteGroup(c *cli.Context) error {
+	return getAPI(c).DeleteGroup(c.Args().First())
+}
+
+func addUser(c *cli.Context) error {
+	return getAPI(c).AddUser(c.Args().Get(0), c.Args().Get(1))
+}
+
+func removeUser(c *cli.Context) error {
+	return getAPI(c).RemoveUser(c.Args().Get(0), c.Args().Get(1))
+}
+
+func groupNameBashComplete(c *cli.Context) {
+	if c.NArg() > 0 {
+		return
+	}
+	list, err := getAPI(c).GetGroups()
+	if err != nil {
+		return
+	}
+	for _, group := range list.Groups {
+		fmt.Println(group.Name)
+	}
+}
+
+func getAPI(c *cli.Context) api.GroupsAPI {
+	return services.GetGroupsAPI(common.GetClient(c))
+}
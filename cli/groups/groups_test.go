@@ -0,0 +1,110 @@
+package groups
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+// A mock server that keeps the groups in memory, to verify the full CRUD cycle
+func createMockServer(t *testing.T, groups map[string]*model.OnmsGroup) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		path := strings.TrimPrefix(req.URL.Path, "/rest/groups")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		switch {
+		case req.Method == http.MethodGet && path == "":
+			list := model.OnmsGroupList{}
+			names := make([]string, 0, len(groups))
+			for name := range groups {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				list.Groups = append(list.Groups, *groups[name])
+			}
+			list.Count = len(list.Groups)
+			list.TotalCount = list.Count
+			bytes, _ := json.Marshal(list)
+			res.Write(bytes)
+		case req.Method == http.MethodPost && path == "":
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			group := &model.OnmsGroup{}
+			assert.NilError(t, json.Unmarshal(bytes, group))
+			groups[group.Name] = group
+			res.WriteHeader(http.StatusCreated)
+		case groups[parts[0]] == nil:
+			res.WriteHeader(http.StatusNotFound)
+		case req.Method == http.MethodGet && len(parts) == 1:
+			bytes, _ := json.Marshal(groups[parts[0]])
+			res.Write(bytes)
+		case req.Method == http.MethodDelete && len(parts) == 1:
+			delete(groups, parts[0])
+			res.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodPut && len(parts) == 3 && parts[1] == "users":
+			groups[parts[0]].Users = append(groups[parts[0]].Users, parts[2])
+			res.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodDelete && len(parts) == 3 && parts[1] == "users":
+			group := groups[parts[0]]
+			users := make([]string, 0)
+			for _, user := range group.Users {
+				if user != parts[2] {
+					users = append(users, user)
+				}
+			}
+			group.Users = users
+			res.WriteHeader(http.StatusNoContent)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestGroupsCrud(t *testing.T) {
+	groups := map[string]*model.OnmsGroup{}
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, groups)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "groups", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, "There are no groups\n", output)
+
+	assert.NilError(t, app.Run([]string{app.Name, "groups", "add", "--comments", "Network Operations", "--user", "admin", "--user", "jdoe", "NOC"}))
+	assert.DeepEqual(t, []string{"admin", "jdoe"}, groups["NOC"].Users)
+
+	err = app.Run([]string{app.Name, "groups", "add", "--user", "j doe", "Ops"})
+	assert.ErrorContains(t, err, "cannot contain spaces")
+
+	output, err = test.RunWithOutput(app, "table", "groups", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `Name  Comments            Users
+NOC   Network Operations  admin,jdoe
+`, output)
+
+	assert.NilError(t, app.Run([]string{app.Name, "groups", "remove-user", "NOC", "admin"}))
+	assert.NilError(t, app.Run([]string{app.Name, "groups", "add-user", "NOC", "operator"}))
+
+	output, err = test.RunWithOutput(app, "yaml", "groups", "get", "NOC")
+	assert.NilError(t, err)
+	assert.Equal(t, `name: NOC
+comments: Network Operations
+users:
+- jdoe
+- operator
+`, output)
+
+	assert.NilError(t, app.Run([]string{app.Name, "groups", "delete", "NOC"}))
+	assert.Equal(t, 0, len(groups))
+	assert.ErrorContains(t, app.Run([]string{app.Name, "groups", "get", "NOC"}), "Cannot retrieve group NOC")
+}
@@ -2,13 +2,11 @@ package info
 
 import (
 	"encoding/json"
-	"fmt"
 
+	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/urfave/cli"
-
-	"gopkg.in/yaml.v2"
 )
 
 // CliCommand the CLI command to provide server information
@@ -16,7 +14,7 @@ var CliCommand = cli.Command{
 	Name:  "info",
 	Usage: "Shows version information about the OpenNMS server",
 	Action: func(c *cli.Context) error {
-		jsonInfo, err := rest.Instance.Get("/rest/info")
+		jsonInfo, err := common.GetClient(c).Get("/rest/info")
 		if err != nil {
 			return err
 		}
@@ -25,11 +23,10 @@ var CliCommand = cli.Command{
 		if err != nil {
 			return err
 		}
-		data, err := yaml.Marshal(&info)
-		if err != nil {
-			return err
+		// Keep the version, so the commands that require a newer server don't have to ask for it again
+		if version, err := rest.ParseVersion(info.Version); err == nil {
+			common.GetClient(c).ServerVersion = version
 		}
-		fmt.Println(string(data))
-		return nil
+		return common.Print(info, nil)
 	},
 }
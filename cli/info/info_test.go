@@ -8,7 +8,6 @@ import (
 	"testing"
 
 	"github.com/OpenNMS/onmsctl/model"
-	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/OpenNMS/onmsctl/test"
 
 	"gotest.tools/assert"
@@ -19,6 +18,7 @@ var mockData = &model.OnmsInfo{
 	Version:            "24.1.2",
 	PackageName:        "opennms",
 	PackageDescription: "OpenNMS",
+	TicketerConfig:     &model.OnmsInfoTicketerConfig{Plugin: "org.opennms.netmgt.ticketd.NullTicketerPlugin"},
 }
 
 func TestSendEvent(t *testing.T) {
@@ -30,9 +30,16 @@ func TestSendEvent(t *testing.T) {
 		res.WriteHeader(http.StatusOK)
 		res.Write(bytes)
 	}))
-	rest.Instance.URL = server.URL
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
-	err := app.Run([]string{app.Name, "info"})
+	output, err := test.RunWithOutput(app, "table", "info")
 	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, "version: 24.1.2\n"))
+	assert.Assert(t, strings.Contains(output, "ticketer:\n  plugin: org.opennms.netmgt.ticketd.NullTicketerPlugin\n  enabled: false\n"))
+	assert.Equal(t, "24.1.2", test.Client(app).ServerVersion.String())
+
+	output, err = test.RunWithOutput(app, "jsonpath=$.packageName", "info")
+	assert.NilError(t, err)
+	assert.Equal(t, "opennms\n", output)
 }
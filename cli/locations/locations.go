@@ -0,0 +1,156 @@
+package locations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// CliCommand the CLI command to manage monitoring locations
+var CliCommand = cli.Command{
+	Name:  "locations",
+	Usage: "Manage Monitoring Locations (for Minion)",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List all monitoring locations",
+			Action: listLocations,
+		},
+		{
+			Name:         "get",
+			Usage:        "Gets a monitoring location",
+			ArgsUsage:    "<name>",
+			Action:       showLocation,
+			BashComplete: locationNameBashComplete,
+		},
+		{
+			Name:      "add",
+			ShortName: "set",
+			Usage:     "Adds or updates a monitoring location",
+			ArgsUsage: "<name>",
+			Action:    addLocation,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "area, a",
+					Usage: "Monitoring Area (defaults to the location name)",
+				},
+				cli.Float64Flag{
+					Name:  "lat",
+					Usage: "Latitude, between -90 and 90",
+				},
+				cli.Float64Flag{
+					Name:  "lon",
+					Usage: "Longitude, between -180 and 180",
+				},
+				cli.IntFlag{
+					Name:  "priority, p",
+					Value: 100,
+					Usage: "Priority",
+				},
+			},
+		},
+		{
+			Name:         "delete",
+			ShortName:    "del",
+			Usage:        "Deletes a monitoring location",
+			ArgsUsage:    "<name>",
+			Action:       deleteLocation,
+			BashComplete: locationNameBashComplete,
+		},
+		{
+			Name:      "apply",
+			Usage:     "Creates or updates a monitoring location from a external YAML file",
+			ArgsUsage: "<yaml>",
+			Action:    applyLocation,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External YAML file (use '-' for STDIN Pipe)",
+				},
+			},
+		},
+	},
+}
+
+func listLocations(c *cli.Context) error {
+	list, err := getAPI(c).GetLocations()
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no monitoring locations", "Name", "Monitoring Area", "Latitude", "Longitude", "Priority", "Tags")
+	for _, loc := range list.Locations {
+		table.AddRow(loc.LocationName, loc.MonitoringArea, loc.Latitude, loc.Longitude, loc.Priority, strings.Join(loc.Tags, ","))
+	}
+	return common.Print(list.Locations, table)
+}
+
+func showLocation(c *cli.Context) error {
+	loc, err := getAPI(c).GetLocation(c.Args().First())
+	if err != nil {
+		return err
+	}
+	return common.Print(loc, nil)
+}
+
+func addLocation(c *cli.Context) error {
+	loc := &model.MonitoringLocation{
+		LocationName:   c.Args().First(),
+		MonitoringArea: c.String("area"),
+		Latitude:       c.Float64("lat"),
+		Longitude:      c.Float64("lon"),
+		Priority:       c.Int("priority"),
+	}
+	return common.Apply(loc, func() error {
+		return setLocation(c, *loc)
+	})
+}
+
+func deleteLocation(c *cli.Context) error {
+	return getAPI(c).DeleteLocation(c.Args().First())
+}
+
+func applyLocation(c *cli.Context) error {
+	data, err := common.ReadInput(c, 0)
+	if err != nil {
+		return err
+	}
+	loc := &model.MonitoringLocation{}
+	return common.ApplyYAML(data, loc, func() error {
+		return setLocation(c, *loc)
+	})
+}
+
+// Creates the location, or updates it when it already exists
+func setLocation(c *cli.Context, loc model.MonitoringLocation) error {
+	api := getAPI(c)
+	exists, err := api.LocationExists(loc.LocationName)
+	if err != nil {
+		return fmt.Errorf("Cannot verify location %s: %w", loc.LocationName, err)
+	}
+	if exists {
+		return api.UpdateLocation(loc)
+	}
+	return api.SetLocation(loc)
+}
+
+func locationNameBashComplete(c *cli.Context) {
+	if c.NArg() > 0 {
+		return
+	}
+	list, err := getAPI(c).GetLocations()
+	if err != nil {
+		return
+	}
+	for _, loc := range list.Locations {
+		fmt.Println(loc.LocationName)
+	}
+}
+
+func getAPI(c *cli.Context) api.MonitoringLocationsAPI {
+	return services.GetMonitoringLocationsAPI(common.GetClient(c))
+}
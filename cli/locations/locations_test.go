@@ -0,0 +1,135 @@
+package locations
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+var mockLocation = model.MonitoringLocation{
+	LocationName:   "Durham",
+	MonitoringArea: "North Carolina",
+	Latitude:       35.99,
+	Longitude:      -78.9,
+	Priority:       100,
+	Tags:           []string{"east"},
+}
+
+func createMockServer(t *testing.T, created *model.MonitoringLocation, updated url.Values) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.Method + " " + req.URL.Path {
+		case "GET /api/v2/monitoringLocations":
+			bytes, _ := json.Marshal(model.MonitoringLocationList{Count: 1, Locations: []model.MonitoringLocation{mockLocation}})
+			res.Write(bytes)
+		case "GET /api/v2/monitoringLocations/Durham":
+			bytes, _ := json.Marshal(mockLocation)
+			res.Write(bytes)
+		case "POST /api/v2/monitoringLocations":
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(bytes, created))
+			res.WriteHeader(http.StatusCreated)
+		case "PUT /api/v2/monitoringLocations/Durham":
+			assert.NilError(t, req.ParseForm())
+			for k, v := range req.PostForm {
+				updated[k] = v
+			}
+			res.WriteHeader(http.StatusNoContent)
+		case "DELETE /api/v2/monitoringLocations/Durham":
+			res.WriteHeader(http.StatusNoContent)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestListLocations(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, nil, nil)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "locations", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `Name    Monitoring Area  Latitude  Longitude  Priority  Tags
+Durham  North Carolina   35.99     -78.9      100       east
+`, output)
+
+	output, err = test.RunWithOutput(app, "yaml", "locations", "get", "Durham")
+	assert.NilError(t, err)
+	assert.Equal(t, `tags:
+- east
+latitude: 35.99
+longitude: -78.9
+name: Durham
+priority: 100
+monitoringArea: North Carolina
+`, output)
+
+	_, err = test.RunWithOutput(app, "yaml", "locations", "get")
+	assert.Error(t, err, "Location name required")
+}
+
+func TestAddLocation(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	created := &model.MonitoringLocation{}
+	updated := url.Values{}
+	server := createMockServer(t, created, updated)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err := app.Run([]string{app.Name, "locations", "add"})
+	assert.Error(t, err, "Location name cannot be empty")
+
+	err = app.Run([]string{app.Name, "locations", "add", "--lat", "100", "Apex"})
+	assert.Error(t, err, "Invalid latitude 100, it must be between -90 and 90")
+
+	err = app.Run([]string{app.Name, "locations", "add", "--lat", "35.73", "--lon", "-78.85", "Apex"})
+	assert.NilError(t, err)
+	assert.Equal(t, "Apex", created.LocationName)
+	assert.Equal(t, "Apex", created.MonitoringArea)
+	assert.Equal(t, 100, created.Priority)
+
+	err = app.Run([]string{app.Name, "locations", "add", "--area", "NC", "--priority", "50", "Durham"})
+	assert.NilError(t, err)
+	assert.Equal(t, "NC", updated.Get("monitoringArea"))
+	assert.Equal(t, "50", updated.Get("priority"))
+}
+
+func TestApplyLocation(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	created := &model.MonitoringLocation{}
+	server := createMockServer(t, created, url.Values{})
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err := app.Run([]string{app.Name, "locations", "apply", "name: Cary\nlongitude: -200"})
+	assert.ErrorContains(t, err, "Invalid longitude -200")
+
+	err = app.Run([]string{app.Name, "locations", "apply", "name: Cary\nmonitoringArea: NC\ntags: [east]"})
+	assert.NilError(t, err)
+	assert.Equal(t, "Cary", created.LocationName)
+	assert.Equal(t, "NC", created.MonitoringArea)
+	assert.DeepEqual(t, []string{"east"}, created.Tags)
+}
+
+func TestDeleteLocation(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, nil, nil)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err := app.Run([]string{app.Name, "locations", "delete"})
+	assert.Error(t, err, "Location name required")
+
+	err = app.Run([]string{app.Name, "locations", "delete", "Durham"})
+	assert.NilError(t, err)
+}
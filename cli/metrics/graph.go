@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/plot"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+var graphCommand = cli.Command{
+	Name:   "graph",
+	Usage:  "Renders a prefab graph of a resource (e.x. mib2.HCbits) as a PNG or SVG image",
+	Action: renderGraph,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "resource, r",
+			Usage: "The resource ID (e.x. 'node[Servers:web01].interfaceSnmp[eth0]')",
+		},
+		cli.StringFlag{
+			Name:  "graph, g",
+			Usage: "The name of the graph; the graphs available for the resource are listed when it doesn't exist",
+		},
+		cli.StringFlag{
+			Name:  "start",
+			Value: "-1d",
+			Usage: "The start time (e.x. '-6h', 'now-1d', an RFC3339 timestamp or milliseconds since the epoch)",
+		},
+		cli.StringFlag{
+			Name:  "end",
+			Value: "now",
+			Usage: "The end time, with the same syntax as the start time",
+		},
+		cli.IntFlag{
+			Name:  "width",
+			Value: 800,
+			Usage: "The width of the image in pixels",
+		},
+		cli.IntFlag{
+			Name:  "height",
+			Value: 300,
+			Usage: "The height of the image in pixels",
+		},
+		cli.GenericFlag{
+			Name:  "format, x",
+			Value: model.NewEnum("png", "svg").WithDefault("png"),
+			Usage: "Image Format: png, svg (by default, svg when the output file ends with .svg)",
+		},
+		cli.StringFlag{
+			Name:  "out, O",
+			Usage: "The output file, or - for STDOUT (by default, the name of the graph with the extension of the format)",
+		},
+	},
+}
+
+func renderGraph(c *cli.Context) error {
+	resourceID := c.String("resource")
+	if resourceID == "" {
+		return fmt.Errorf("Resource ID required")
+	}
+	graph, err := getGraph(c, resourceID, c.String("graph"))
+	if err != nil {
+		return err
+	}
+	def, err := graph.Definition()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	start, err := common.ParseRelativeTime(c.String("start"), now)
+	if err != nil {
+		return err
+	}
+	end, err := common.ParseRelativeTime(c.String("end"), now)
+	if err != nil {
+		return err
+	}
+	width, height := c.Int("width"), c.Int("height")
+	if width < 200 || height < 100 {
+		return fmt.Errorf("The image must be at least 200x100 pixels")
+	}
+	// One value per pixel is enough, the server returns the stored values when they are coarser
+	step := end.Sub(start) / time.Duration(width)
+	if step < time.Second {
+		step = time.Second
+	}
+	request := def.QueryRequest(resourceID, toMillis(start), toMillis(end), int64(step/time.Millisecond))
+	request.MaxRows = width
+	response, err := getAPI(c).Query(request)
+	if err != nil {
+		return err
+	}
+	chart, err := buildChart(def, response, width, height)
+	if err != nil {
+		return err
+	}
+	format := common.EnumFlag(c, "format")
+	out := c.String("out")
+	if !c.IsSet("format") && strings.EqualFold(filepath.Ext(out), ".svg") {
+		format = "svg"
+	}
+	if out == "" {
+		out = graph.Name + "." + format
+	}
+	var image bytes.Buffer
+	if format == "svg" {
+		err = chart.RenderSVG(&image)
+	} else {
+		err = chart.RenderPNG(&image)
+	}
+	if err != nil {
+		return err
+	}
+	if out == "-" {
+		_, err = image.WriteTo(common.Output)
+		return err
+	}
+	if err := ioutil.WriteFile(out, image.Bytes(), 0644); err != nil {
+		return err
+	}
+	common.Log.Infof("Graph %s of %s saved to %s", graph.Name, resourceID, out)
+	return nil
+}
+
+// Returns the definition of a graph, verifying that it is available for the resource
+func getGraph(c *cli.Context, resourceID string, name string) (*model.PrefabGraph, error) {
+	api := services.GetGraphsAPI(common.GetClient(c))
+	names, err := api.GetGraphNames(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(names.Names) == 0 {
+		return nil, fmt.Errorf("There are no graphs for resource %s", resourceID)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("Graph name required; available graphs for %s: %s", resourceID, strings.Join(names.Names, ", "))
+	}
+	if !names.Contains(name) {
+		return nil, fmt.Errorf("Graph %s is not available for %s; available graphs: %s", name, resourceID, strings.Join(names.Names, ", "))
+	}
+	return api.GetGraph(name)
+}
+
+// Builds the chart with the series of the graph definition, using the values returned by the server
+func buildChart(def *model.GraphDefinition, response *model.QueryResponse, width int, height int) (*plot.Chart, error) {
+	if len(response.Timestamps) < 2 {
+		return nil, fmt.Errorf("There is no data for the requested period")
+	}
+	chart := &plot.Chart{Title: def.Title, VerticalLabel: def.VerticalLabel, Width: width, Height: height}
+	for _, ts := range response.Timestamps {
+		chart.Timestamps = append(chart.Timestamps, fromMillis(ts))
+	}
+	columns := make(map[string][]model.MetricValue)
+	for i, label := range response.Labels {
+		if i < len(response.Columns) {
+			columns[label] = response.Columns[i].Values
+		}
+	}
+	for _, s := range def.Series {
+		values, ok := columns[s.Label]
+		if !ok {
+			return nil, fmt.Errorf("The server didn't return the values of %s", s.Label)
+		}
+		color, err := plot.ParseColor(s.Color)
+		if err != nil {
+			return nil, err
+		}
+		series := plot.Series{Label: s.Legend, Color: color, Width: s.Width, Kind: plot.Line}
+		if s.Type != "LINE" {
+			series.Kind = plot.Area
+		}
+		series.Stack = s.Type == "STACK"
+		for i := range response.Timestamps {
+			v := math.NaN()
+			if i < len(values) {
+				v = float64(values[i])
+			}
+			series.Values = append(series.Values, v)
+		}
+		chart.Series = append(chart.Series, series)
+	}
+	return chart, nil
+}
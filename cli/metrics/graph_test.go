@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"encoding/json"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+const mockGraph = `{
+  "name": "mib2.HCbits", "title": "Bits In/Out", "columns": ["ifHCInOctets", "ifHCOutOctets"],
+  "command": "--title=\"Bits In/Out (High Speed)\" DEF:octIn={rrd1}:ifHCInOctets:AVERAGE DEF:octOut={rrd2}:ifHCOutOctets:AVERAGE CDEF:bitsIn=octIn,8,* CDEF:bitsOut=0,octOut,8,*,- AREA:bitsIn#73d216 LINE1:bitsIn#4e9a06:\"In\" LINE1:bitsOut#3465a4:\"Out\""
+}`
+
+const mockGraphData = `{
+  "step": 300000, "start": 1579089600000, "end": 1579090800000,
+  "timestamps": [1579089600000, 1579089900000, 1579090200000, 1579090500000],
+  "labels": ["octIn", "octOut", "bitsIn", "bitsOut"],
+  "columns": [{"values": [10, 20, 30, 20]}, {"values": [5, 5, 5, "NaN"]}, {"values": [80, 160, 240, 160]}, {"values": [-40, -40, -40, "NaN"]}]
+}`
+
+func TestRenderGraph(t *testing.T) {
+	request := &model.QueryRequest{}
+	app := test.CreateCli(CliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/graphs/for/node[Servers:web01].interfaceSnmp[eth0]":
+			res.Write([]byte(`{"name":["mib2.HCbits","mib2.errors"]}`))
+		case "/rest/graphs/mib2.HCbits":
+			res.Write([]byte(mockGraph))
+		case "/rest/measurements":
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(bytes, request))
+			res.Write([]byte(mockGraphData))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl-graph")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	resource := "node[Servers:web01].interfaceSnmp[eth0]"
+	_, err = test.RunWithOutput(app, "table", "metrics", "graph", "-r", resource, "-g", "mib2.bits")
+	assert.Error(t, err, "Graph mib2.bits is not available for "+resource+"; available graphs: mib2.HCbits, mib2.errors")
+
+	_, err = test.RunWithOutput(app, "table", "metrics", "graph", "-r", resource)
+	assert.Error(t, err, "Graph name required; available graphs for "+resource+": mib2.HCbits, mib2.errors")
+
+	out := filepath.Join(dir, "bits.png")
+	_, err = test.RunWithOutput(app, "table", "metrics", "graph", "-r", resource, "-g", "mib2.HCbits", "--start", "-6h", "--width", "600", "--height", "200", "-O", out)
+	assert.NilError(t, err)
+	assert.Equal(t, int64(6*time.Hour/time.Millisecond), request.End-request.Start)
+	assert.Equal(t, int64(36000), request.Step)
+	assert.Equal(t, 600, request.MaxRows)
+	assert.DeepEqual(t, []model.QueryExpression{{Label: "bitsIn", Value: "octIn * 8"}, {Label: "bitsOut", Value: "0 - (octOut * 8)"}}, request.Expressions)
+	file, err := os.Open(out)
+	assert.NilError(t, err)
+	defer file.Close()
+	config, err := png.DecodeConfig(file)
+	assert.NilError(t, err)
+	assert.Equal(t, 600, config.Width)
+	assert.Equal(t, 200, config.Height)
+
+	output, err := test.RunWithOutput(app, "table", "metrics", "graph", "-r", resource, "-g", "mib2.HCbits", "-x", "svg", "-O", "-")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(output, "<?xml ") && strings.Contains(output, "<svg "))
+	assert.Assert(t, strings.Contains(output, ">Bits In/Out (High Speed)</text>"))
+	assert.Equal(t, 1, len(regexp.MustCompile(`<polygon points="[^"]+" class="series"`).FindAllString(output, -1)))
+	assert.Equal(t, 2, len(regexp.MustCompile(`<polyline points="[^"]+" class="series"`).FindAllString(output, -1)))
+}
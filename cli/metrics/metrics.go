@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// The characters used to draw sparklines, from the lowest to the highest value
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// CliCommand the CLI command to query collected metrics
+var CliCommand = cli.Command{
+	Name:  "metrics",
+	Usage: "Query collected metrics through the Measurements API",
+	Subcommands: []cli.Command{
+		{
+			Name:   "get",
+			Usage:  "Fetches the values of one or more attributes of a resource",
+			Action: getMetrics,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "resource, r",
+					Usage: "The resource ID (e.x. 'node[Servers:web01].nodeSnmp[]')",
+				},
+				cli.StringSliceFlag{
+					Name:  "attribute, a",
+					Usage: "An attribute of the resource, optionally with a label (e.x. 'ifInOctets' or 'in=ifInOctets'); can be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "expression, e",
+					Usage: "A JEXL expression using the labels of the attributes, with a label (e.x. 'bits=in * 8'); can be repeated",
+				},
+				cli.StringFlag{
+					Name:  "aggregation",
+					Value: model.MeasurementAggregations.Default,
+					Usage: "The consolidation function: " + model.MeasurementAggregations.EnumAsString(),
+				},
+				cli.StringFlag{
+					Name:  "start",
+					Value: "-1h",
+					Usage: "The start time (e.x. '-2h', 'now-1d', an RFC3339 timestamp or milliseconds since the epoch)",
+				},
+				cli.StringFlag{
+					Name:  "end",
+					Value: "now",
+					Usage: "The end time, with the same syntax as the start time",
+				},
+				cli.IntFlag{
+					Name:  "step",
+					Value: 300,
+					Usage: "The requested interval between values in seconds",
+				},
+				cli.GenericFlag{
+					Name:  "format, x",
+					Value: model.NewEnum("csv", "json").WithDefault("csv"),
+					Usage: "Output Format: csv, json",
+				},
+				cli.BoolFlag{
+					Name:  "sparkline",
+					Usage: "Draw a sparkline per attribute and expression, with the minimum, maximum and last values",
+				},
+			},
+		},
+		graphCommand,
+	},
+}
+
+func getMetrics(c *cli.Context) error {
+	request, err := buildQueryRequest(c, time.Now())
+	if err != nil {
+		return err
+	}
+	response, err := getAPI(c).Query(*request)
+	if err != nil {
+		return err
+	}
+	if c.Bool("sparkline") {
+		return printSparklines(response)
+	}
+	if c.String("format") == "json" {
+		return printJSON(response)
+	}
+	return printCSV(response)
+}
+
+func buildQueryRequest(c *cli.Context, now time.Time) (*model.QueryRequest, error) {
+	resourceID := c.String("resource")
+	if resourceID == "" {
+		return nil, fmt.Errorf("Resource ID required")
+	}
+	if len(c.StringSlice("attribute")) == 0 {
+		return nil, fmt.Errorf("At least one attribute required")
+	}
+	start, err := common.ParseRelativeTime(c.String("start"), now)
+	if err != nil {
+		return nil, err
+	}
+	end, err := common.ParseRelativeTime(c.String("end"), now)
+	if err != nil {
+		return nil, err
+	}
+	request := &model.QueryRequest{
+		Start: toMillis(start),
+		End:   toMillis(end),
+		Step:  int64(c.Int("step")) * 1000,
+	}
+	for _, attribute := range c.StringSlice("attribute") {
+		label, name := splitLabel(attribute)
+		request.Sources = append(request.Sources, model.QuerySource{
+			Label:       label,
+			ResourceID:  resourceID,
+			Attribute:   name,
+			Aggregation: c.String("aggregation"),
+		})
+	}
+	for _, expression := range c.StringSlice("expression") {
+		label, value := splitLabel(expression)
+		if label == "" {
+			return nil, fmt.Errorf("Invalid expression %s, expected label=expression", expression)
+		}
+		request.Expressions = append(request.Expressions, model.QueryExpression{Label: label, Value: value})
+	}
+	return request, nil
+}
+
+// Splits an optional label from its value (e.x. 'in=ifInOctets'); the label is empty when not present
+func splitLabel(text string) (string, string) {
+	data := strings.SplitN(text, "=", 2)
+	if len(data) == 2 && !strings.ContainsAny(data[0], " ()+-*/<>!") {
+		return strings.TrimSpace(data[0]), strings.TrimSpace(data[1])
+	}
+	return "", strings.TrimSpace(text)
+}
+
+func printCSV(response *model.QueryResponse) error {
+	writer := csv.NewWriter(common.Output)
+	writer.Write(append([]string{"timestamp"}, response.Labels...))
+	for i, ts := range response.Timestamps {
+		row := []string{fromMillis(ts).Format(time.RFC3339)}
+		for _, column := range response.Columns {
+			row = append(row, formatValue(column.Values, i))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func printJSON(response *model.QueryResponse) error {
+	bytes, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(common.Output, string(bytes))
+	return err
+}
+
+func printSparklines(response *model.QueryResponse) error {
+	writer := tabwriter.NewWriter(common.Output, 0, 8, 2, ' ', 0)
+	for i, label := range response.Labels {
+		var values []model.MetricValue
+		if i < len(response.Columns) {
+			values = response.Columns[i].Values
+		}
+		fmt.Fprintf(writer, "%s\t%s\n", label, sparkline(values))
+	}
+	return writer.Flush()
+}
+
+// Returns the sparkline for the values, followed by the minimum, maximum and last values;
+// missing values are drawn as blank spaces
+func sparkline(values []model.MetricValue) string {
+	min, max := math.Inf(1), math.Inf(-1)
+	last := math.NaN()
+	for _, v := range values {
+		if v.IsNaN() {
+			continue
+		}
+		min = math.Min(min, float64(v))
+		max = math.Max(max, float64(v))
+		last = float64(v)
+	}
+	if math.IsNaN(last) {
+		return "no data"
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		switch {
+		case v.IsNaN():
+			line[i] = ' '
+		case max == min:
+			line[i] = sparks[0]
+		default:
+			line[i] = sparks[int((float64(v)-min)/(max-min)*float64(len(sparks)-1)+0.5)]
+		}
+	}
+	return fmt.Sprintf("%s\tmin=%s\tmax=%s\tlast=%s", string(line), formatFloat(min), formatFloat(max), formatFloat(last))
+}
+
+func formatValue(values []model.MetricValue, index int) string {
+	if index >= len(values) || values[index].IsNaN() {
+		return ""
+	}
+	return formatFloat(float64(values[index]))
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+func getAPI(c *cli.Context) api.MeasurementsAPI {
+	return services.GetMeasurementsAPI(common.GetClient(c))
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+const mockResponse = `{
+  "step": 300000, "start": 1579089600000, "end": 1579090800000,
+  "timestamps": [1579089600000, 1579089900000, 1579090200000, 1579090500000],
+  "labels": ["in", "bits"],
+  "columns": [{"values": [10, "NaN", 30, 20]}, {"values": [80, "NaN", 240, 160]}]
+}`
+
+func createMockServer(t *testing.T, request *model.QueryRequest) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/rest/measurements", req.URL.Path)
+		assert.Equal(t, http.MethodPost, req.Method)
+		bytes, err := ioutil.ReadAll(req.Body)
+		assert.NilError(t, err)
+		assert.NilError(t, json.Unmarshal(bytes, request))
+		res.Write([]byte(mockResponse))
+	}))
+	return server
+}
+
+func TestGetMetrics(t *testing.T) {
+	time.Local = time.UTC
+	app := test.CreateCli(CliCommand)
+	request := &model.QueryRequest{}
+	server := createMockServer(t, request)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	_, err := test.RunWithOutput(app, "table", "metrics", "get", "-a", "ifInOctets")
+	assert.Error(t, err, "Resource ID required")
+
+	_, err = test.RunWithOutput(app, "table", "metrics", "get", "-r", "node[1].nodeSnmp[]", "-a", "ifInOctets", "--start", "yesterday")
+	assert.ErrorContains(t, err, "Invalid time yesterday")
+
+	args := []string{"metrics", "get", "-r", "node[Servers:web01].interfaceSnmp[eth0]", "-a", "in=ifInOctets", "-e", "bits=in * 8", "--start", "-2h", "--step", "60"}
+	output, err := test.RunWithOutput(app, "table", args...)
+	assert.NilError(t, err)
+	assert.Equal(t, `timestamp,in,bits
+2020-01-15T12:00:00Z,10,80
+2020-01-15T12:05:00Z,,
+2020-01-15T12:10:00Z,30,240
+2020-01-15T12:15:00Z,20,160
+`, output)
+	assert.Equal(t, int64(2*time.Hour/time.Millisecond), request.End-request.Start)
+	assert.Equal(t, int64(60000), request.Step)
+	assert.DeepEqual(t, []model.QuerySource{{Label: "in", ResourceID: "node[Servers:web01].interfaceSnmp[eth0]", Attribute: "ifInOctets", Aggregation: "AVERAGE"}}, request.Sources)
+	assert.DeepEqual(t, []model.QueryExpression{{Label: "bits", Value: "in * 8"}}, request.Expressions)
+
+	output, err = test.RunWithOutput(app, "table", append(args, "-x", "json")...)
+	assert.NilError(t, err)
+	response := model.QueryResponse{}
+	assert.NilError(t, json.Unmarshal([]byte(output), &response))
+	assert.Assert(t, response.Columns[0].Values[1].IsNaN())
+
+	output, err = test.RunWithOutput(app, "table", append(args, "--sparkline")...)
+	assert.NilError(t, err)
+	assert.Equal(t, `in    ▁ █▅  min=10  max=30   last=20
+bits  ▁ █▅  min=80  max=240  last=160
+`, output)
+}
+
+func TestSparkline(t *testing.T) {
+	nan := model.MetricValue(0)
+	nan.UnmarshalJSON([]byte(`"NaN"`))
+	assert.Equal(t, "no data", sparkline([]model.MetricValue{nan, nan}))
+	assert.Equal(t, "▁▁\tmin=5\tmax=5\tlast=5", sparkline([]model.MetricValue{5, 5}))
+	assert.Equal(t, "▁▅█ \tmin=0\tmax=1\tlast=1", sparkline([]model.MetricValue{0, 0.5, 1, nan}))
+}
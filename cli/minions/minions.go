@@ -0,0 +1,221 @@
+package minions
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// The oldest version that provides the minions through the ReST API v2
+const minMinionsVersion = "22.0.0"
+
+// now the reference to calculate the age of the heartbeats (replaced on tests)
+var now = time.Now
+
+// staleFlag the flag to choose when a heartbeat is too old to consider the minion alive
+var staleFlag = cli.DurationFlag{
+	Name:  "stale",
+	Value: 5 * time.Minute,
+	Usage: "A minion whose last heartbeat is older than this is considered down",
+}
+
+// CliCommand the CLI command to inspect the minions and their heartbeat
+var CliCommand = cli.Command{
+	Name:  "minions",
+	Usage: "Inspect the minions and the state of their heartbeat",
+	Before: func(c *cli.Context) error {
+		return common.GetClient(c).MinServerVersion(minMinionsVersion, "Obtaining minions through the ReST API v2")
+	},
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List minions, with the age of their last heartbeat",
+			Action: listMinions,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "A FIQL expression to filter minions (e.x. 'location==Apex')",
+				},
+				cli.BoolFlag{
+					Name:  "only-down",
+					Usage: "Only the minions whose last heartbeat is older than --stale",
+				},
+				staleFlag,
+			},
+		},
+		{
+			Name:      "get",
+			Usage:     "Shows a minion, with the age of its last heartbeat",
+			ArgsUsage: "<id>",
+			Action:    showMinion,
+			Flags: []cli.Flag{
+				staleFlag,
+			},
+		},
+		{
+			Name:   "watch",
+			Usage:  "Polls the minions and prints their state transitions, one per line prefixed with the time they were detected, until Ctrl-C is pressed",
+			Action: watchMinions,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "A FIQL expression to filter minions (e.x. 'location==Apex')",
+				},
+				cli.DurationFlag{
+					Name:  "interval, i",
+					Value: 10 * time.Second,
+					Usage: "Time between polls",
+				},
+				staleFlag,
+			},
+		},
+	},
+}
+
+func listMinions(c *cli.Context) error {
+	if err := fiql.Validate(c.String("filter")); err != nil {
+		return err
+	}
+	list, err := getAPI(c).GetMinions(c.String("filter"), 0, 0)
+	if err != nil {
+		return err
+	}
+	current := now()
+	stale := c.Duration("stale")
+	minions := make([]model.OnmsMinion, 0, len(list.Minions))
+	for _, m := range list.Minions {
+		if !c.Bool("only-down") || m.IsStale(current, stale) {
+			minions = append(minions, m)
+		}
+	}
+	table := common.NewTable("There are no minions", "ID", "Label", "Location", "Status", "Last Heartbeat")
+	for _, m := range minions {
+		addMinionRow(table, m, current, stale)
+	}
+	return common.Print(minions, table)
+}
+
+func showMinion(c *cli.Context) error {
+	minion, err := getAPI(c).GetMinion(c.Args().First())
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("", "ID", "Label", "Location", "Status", "Last Heartbeat")
+	addMinionRow(table, *minion, now(), c.Duration("stale"))
+	return common.Print(minion, table)
+}
+
+func addMinionRow(table *common.Table, m model.OnmsMinion, current time.Time, stale time.Duration) {
+	table.AddRow(m.ID, m.Label, m.Location, m.GetState(current, stale), getHeartbeatAge(m, current))
+}
+
+func watchMinions(c *cli.Context) error {
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("Interval must be greater than zero")
+	}
+	if err := fiql.Validate(c.String("filter")); err != nil {
+		return err
+	}
+	common.GetClient(c).CacheTTL = -1 // Every poll must reach the server
+	stale := c.Duration("stale")
+	var previous map[string]minionState
+	for {
+		list, err := getAPI(c).GetMinions(c.String("filter"), 0, 0)
+		if err == rest.ErrCancelled {
+			return nil
+		}
+		current := now()
+		if err != nil {
+			common.Log.Errorf("%s %s", formatWatchTime(current), err)
+		} else {
+			states := getMinionStates(list.Minions, current, stale)
+			if previous == nil {
+				down := 0
+				for _, s := range states {
+					if s.State != model.MinionStatusUp {
+						down++
+					}
+				}
+				fmt.Fprintf(common.Output, "%s watching %d minions, %d down\n", formatWatchTime(current), len(states), down)
+			} else {
+				for _, line := range describeMinionTransitions(previous, states) {
+					fmt.Fprintf(common.Output, "%s %s\n", formatWatchTime(current), line)
+				}
+			}
+			previous = states
+		}
+		if err := common.GetClient(c).Sleep(interval); err != nil {
+			return nil
+		}
+	}
+}
+
+// minionState the state of a minion on a given poll
+type minionState struct {
+	Location  string
+	State     string
+	Heartbeat string
+}
+
+// Returns the state of each minion indexed by ID, considering the stale heartbeats as down
+func getMinionStates(minions []model.OnmsMinion, current time.Time, stale time.Duration) map[string]minionState {
+	states := make(map[string]minionState)
+	for _, m := range minions {
+		states[m.ID] = minionState{Location: m.Location, State: m.GetState(current, stale), Heartbeat: getHeartbeatAge(m, current)}
+	}
+	return states
+}
+
+// Returns a line per minion that appeared, disappeared or changed its state between two polls, sorted by ID
+func describeMinionTransitions(previous map[string]minionState, current map[string]minionState) []string {
+	ids := make([]string, 0, len(previous)+len(current))
+	for id := range previous {
+		ids = append(ids, id)
+	}
+	for id := range current {
+		if _, ok := previous[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	lines := make([]string, 0)
+	for _, id := range ids {
+		before, existed := previous[id]
+		after, exists := current[id]
+		switch {
+		case !exists:
+			lines = append(lines, fmt.Sprintf("- minion %s at %s", id, before.Location))
+		case !existed:
+			lines = append(lines, fmt.Sprintf("+ minion %s at %s is %s, last heartbeat %s", id, after.Location, after.State, after.Heartbeat))
+		case before.State != after.State:
+			lines = append(lines, fmt.Sprintf("minion %s at %s %s -> %s, last heartbeat %s", id, after.Location, before.State, after.State, after.Heartbeat))
+		}
+	}
+	return lines
+}
+
+// Returns how long ago the last heartbeat was received, like '3m10s ago', or Never
+func getHeartbeatAge(m model.OnmsMinion, current time.Time) string {
+	age := m.HeartbeatAge(current)
+	if age < 0 {
+		return "Never"
+	}
+	return common.HumanizeDuration(age) + " ago"
+}
+
+func formatWatchTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func getAPI(c *cli.Context) api.MinionsAPI {
+	return services.GetMinionsAPI(common.GetClient(c))
+}
@@ -0,0 +1,152 @@
+package minions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"github.com/urfave/cli"
+	"gotest.tools/assert"
+)
+
+// 2020-01-01T10:00:00Z
+var baseTime = time.Unix(1577872800, 0)
+
+func heartbeat(ago time.Duration) *model.Time {
+	return &model.Time{Time: baseTime.Add(-ago)}
+}
+
+var mockMinions = model.OnmsMinionList{Count: 3, TotalCount: 3, Minions: []model.OnmsMinion{
+	{ID: "minion-01", Label: "minion-01", Location: "Apex", Status: "UP", LastUpdated: heartbeat(30 * time.Second)},
+	{ID: "minion-02", Label: "minion-02", Location: "Durham", Status: "UP", LastUpdated: heartbeat(7*time.Minute + 5*time.Second)},
+	{ID: "minion-03", Label: "minion-03", Location: "Durham", Status: "DOWN", LastUpdated: heartbeat(2 * time.Hour)},
+}}
+
+func sendJSON(res http.ResponseWriter, data interface{}) {
+	bytes, _ := json.Marshal(data)
+	res.Header().Set("Content-Type", "application/json")
+	res.Write(bytes)
+}
+
+// Creates the CLI with a client for a server that supports the minions of the ReST API v2, so its version is not requested
+func createCli(cmd cli.Command, opts ...rest.Option) *cli.App {
+	app := test.CreateCli(cmd, opts...)
+	test.Client(app).ServerVersion = &rest.Version{Major: 26}
+	return app
+}
+
+func init() {
+	now = func() time.Time { return baseTime }
+}
+
+func TestListMinions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v2/minions":
+			assert.Equal(t, "location==Durham", req.URL.Query().Get("_s"))
+			sendJSON(res, mockMinions)
+		case "/api/v2/minions/minion-01":
+			sendJSON(res, mockMinions.Minions[0])
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	app := createCli(CliCommand, rest.WithURL(server.URL))
+
+	output, err := test.RunWithOutput(app, "table", "minions", "list", "-f", "location==Durham")
+	assert.NilError(t, err)
+	assert.Equal(t, `ID         Label      Location  Status  Last Heartbeat
+minion-01  minion-01  Apex      UP      30s ago
+minion-02  minion-02  Durham    DOWN    7m5s ago
+minion-03  minion-03  Durham    DOWN    2h ago
+`, output)
+
+	output, err = test.RunWithOutput(app, "table", "minions", "list", "-f", "location==Durham", "--only-down", "--stale", "1h")
+	assert.NilError(t, err)
+	assert.Equal(t, `ID         Label      Location  Status  Last Heartbeat
+minion-03  minion-03  Durham    DOWN    2h ago
+`, output)
+
+	output, err = test.RunWithOutput(app, "json", "minions", "list", "-f", "location==Durham", "--only-down")
+	assert.NilError(t, err)
+	minions := []model.OnmsMinion{}
+	assert.NilError(t, json.Unmarshal([]byte(output), &minions))
+	assert.Equal(t, 2, len(minions))
+
+	output, err = test.RunWithOutput(app, "table", "minions", "get", "minion-01")
+	assert.NilError(t, err)
+	assert.Equal(t, `ID         Label      Location  Status  Last Heartbeat
+minion-01  minion-01  Apex      UP      30s ago
+`, output)
+}
+
+func TestWatchMinions(t *testing.T) {
+	up := model.OnmsMinion{ID: "minion-01", Location: "Apex", Status: "UP", LastUpdated: heartbeat(10 * time.Second)}
+	stale := up
+	stale.LastUpdated = heartbeat(10 * time.Minute)
+	added := model.OnmsMinion{ID: "minion-02", Location: "Durham", Status: "UP", LastUpdated: heartbeat(time.Second)}
+	// The minions returned by each poll; nil means a failure, and the watch is interrupted on the last one
+	polls := [][]model.OnmsMinion{
+		{up},
+		{up},
+		nil,
+		{stale, added},
+		{up, added},
+		{up},
+		{up},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		minions := polls[calls]
+		calls++
+		if calls == len(polls) {
+			cancel()
+		}
+		if minions == nil {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		sendJSON(res, model.OnmsMinionList{Count: len(minions), Minions: minions})
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	app := createCli(CliCommand, rest.WithURL(server.URL), rest.WithContext(ctx))
+	output, err := test.RunWithOutput(app, "table", "minions", "watch", "-i", "1ms")
+	assert.NilError(t, err)
+	assert.Equal(t, len(polls), calls)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i, line := range lines {
+		// Remove the time stamps
+		parts := strings.SplitN(line, " ", 2)
+		_, err := time.Parse(time.RFC3339, parts[0])
+		assert.NilError(t, err)
+		lines[i] = parts[1]
+	}
+	assert.DeepEqual(t, []string{
+		"watching 1 minions, 0 down",
+		"minion minion-01 at Apex UP -> DOWN, last heartbeat 10m ago",
+		"+ minion minion-02 at Durham is UP, last heartbeat 1s ago",
+		"minion minion-01 at Apex DOWN -> UP, last heartbeat 10s ago",
+		"- minion minion-02 at Durham",
+	}, lines)
+	assert.Assert(t, strings.HasPrefix(logs.String(), "ERROR: "), logs.String())
+
+	_, err = test.RunWithOutput(app, "table", "minions", "watch", "-i", "0s")
+	assert.Error(t, err, "Interval must be greater than zero")
+}
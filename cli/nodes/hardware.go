@@ -0,0 +1,86 @@
+package nodes
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/urfave/cli"
+)
+
+// The columns of the flat listing of the hardware inventory
+var hardwareColumns = []string{"index", "parentIndex", "class", "name", "model", "serialNumber", "firmwareRev"}
+
+var hardwareCommand = cli.Command{
+	Name:      "hardware",
+	ShortName: "hw",
+	Usage:     "Show the hardware inventory of a deployed node (ENTITY-MIB), as collected by the SNMP hardware inventory provisioning adapter",
+	ArgsUsage: "<nodeId|foreignSource:foreignID>",
+	Action:    showHardware,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "flat",
+			Usage: "List the entities as CSV with the index of their parents, instead of a tree",
+		},
+		cli.BoolFlag{
+			Name:  "serials-only",
+			Usage: "Show only the serial numbers, one per line (e.x. for asset reconciliation)",
+		},
+	},
+}
+
+func showHardware(c *cli.Context) error {
+	if c.Bool("flat") && c.Bool("serials-only") {
+		return fmt.Errorf("--flat and --serials-only cannot be used together")
+	}
+	node, err := getAPI(c).GetNode(c.Args().First())
+	if err != nil {
+		return err
+	}
+	root, err := getAPI(c).GetHardwareInventory(node.ID)
+	var e *rest.APIError
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		root = nil
+	} else if err != nil {
+		return err
+	}
+	if root == nil {
+		return fmt.Errorf("Node %s (%s) has no hardware inventory; verify that the SNMP hardware inventory provisioning adapter is enabled (provisiond-snmp-hardware-inventory) and the node has been rescanned", node.ID, node.Label)
+	}
+	switch {
+	case c.Bool("serials-only"):
+		for _, serial := range root.SerialNumbers() {
+			fmt.Fprintln(common.Output, serial)
+		}
+		return nil
+	case c.Bool("flat"):
+		return printFlatHardware(root.Flatten())
+	}
+	table := common.NewTable("", "Entity", "Class", "Name", "Model", "Serial Number", "Firmware")
+	root.Walk(func(entity model.HwEntity, depth int) {
+		table.AddRow(strings.Repeat("  ", depth)+strconv.Itoa(entity.Index), valueOrDash(entity.Class), valueOrDash(entity.Name), valueOrDash(entity.ModelName), valueOrDash(entity.SerialNumber), valueOrDash(entity.FirmwareRev))
+	})
+	return common.Print(root, table)
+}
+
+// Writes the entities as CSV, unless another output format was chosen
+func printFlatHardware(entities []model.HwEntity) error {
+	if common.OutputFormat != common.OutputTable {
+		return common.Print(entities, nil)
+	}
+	writer := csv.NewWriter(common.Output)
+	if !common.NoHeaders {
+		writer.Write(hardwareColumns)
+	}
+	for _, e := range entities {
+		writer.Write([]string{strconv.Itoa(e.Index), strconv.Itoa(e.ParentIndex), e.Class, e.Name, e.ModelName, e.SerialNumber, e.FirmwareRev})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,94 @@
+package nodes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+const hardwareInventory = `{
+  "entPhysicalIndex": 1, "entPhysicalClass": "chassis", "entPhysicalName": "Chassis", "entPhysicalModelName": "ASR1001-X", "entPhysicalSerialNum": "FXS1234", "entPhysicalFirmwareRev": "16.9",
+  "hwEntity": [
+    {"entPhysicalIndex": 2, "entPhysicalClass": "powerSupply", "entPhysicalName": "PS 0", "entPhysicalSerialNum": "PSU01"},
+    {"entPhysicalIndex": 3, "entPhysicalClass": "module", "entPhysicalName": "SPA-1X10GE", "entPhysicalModelName": "SPA-1X10GE-L-V2", "entPhysicalSerialNum": "JAE5678",
+     "hwEntity": [{"entPhysicalIndex": 4, "entPhysicalClass": "port", "entPhysicalName": "Te0/1/0"}]}
+  ]
+}`
+
+func createHardwareMockServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.Path {
+		case "/api/v2/nodes":
+			switch req.URL.Query().Get("_s") {
+			case "id==1", "foreignSource==Routers;foreignId==asr01":
+				bytes, _ := json.Marshal(model.OnmsNodeList{Count: 1, TotalCount: 1, Nodes: []model.OnmsNode{{ID: "1", Label: "asr01"}}})
+				res.Write(bytes)
+			case "id==2":
+				bytes, _ := json.Marshal(model.OnmsNodeList{Count: 1, TotalCount: 1, Nodes: []model.OnmsNode{{ID: "2", Label: "srv01"}}})
+				res.Write(bytes)
+			default:
+				res.WriteHeader(http.StatusNoContent)
+			}
+		case "/rest/hardwareInventory/1":
+			res.Write([]byte(hardwareInventory))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestShowHardware(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createHardwareMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "nodes", "hardware", "Routers:asr01")
+	assert.NilError(t, err)
+	assert.Equal(t, `Entity  Class        Name        Model            Serial Number  Firmware
+1       chassis      Chassis     ASR1001-X        FXS1234        16.9
+  2     powerSupply  PS 0        -                PSU01          -
+  3     module       SPA-1X10GE  SPA-1X10GE-L-V2  JAE5678        -
+    4   port         Te0/1/0     -                -              -
+`, output)
+
+	output, err = test.RunWithOutput(app, "table", "nodes", "hw", "--flat", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, `index,parentIndex,class,name,model,serialNumber,firmwareRev
+1,0,chassis,Chassis,ASR1001-X,FXS1234,16.9
+2,1,powerSupply,PS 0,,PSU01,
+3,1,module,SPA-1X10GE,SPA-1X10GE-L-V2,JAE5678,
+4,3,port,Te0/1/0,,,
+`, output)
+
+	output, err = test.RunWithOutput(app, "table", "nodes", "hw", "--serials-only", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, "FXS1234\nPSU01\nJAE5678\n", output)
+
+	output, err = test.RunWithOutput(app, "json", "nodes", "hw", "1")
+	assert.NilError(t, err)
+	root := model.HwEntity{}
+	assert.NilError(t, json.Unmarshal([]byte(output), &root))
+	assert.Equal(t, "Te0/1/0", root.Children[1].Children[0].Name)
+	assert.Equal(t, 3, root.Children[1].Children[0].ParentIndex)
+}
+
+func TestShowHardwareWithoutInventory(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createHardwareMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	_, err := test.RunWithOutput(app, "table", "nodes", "hardware", "2")
+	assert.Error(t, err, "Node 2 (srv01) has no hardware inventory; verify that the SNMP hardware inventory provisioning adapter is enabled (provisiond-snmp-hardware-inventory) and the node has been rescanned")
+
+	_, err = test.RunWithOutput(app, "table", "nodes", "hardware", "--flat", "--serials-only", "1")
+	assert.Error(t, err, "--flat and --serials-only cannot be used together")
+}
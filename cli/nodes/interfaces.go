@@ -0,0 +1,162 @@
+package nodes
+
+import (
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// The maximum width of the descriptions of SNMP interfaces on tables, unless --wide is used
+const maxDescriptionWidth = 30
+
+// The value of ifOperStatus and ifAdminStatus when the interface is up (IF-MIB)
+const ifStatusUp = 1
+
+var ifStatusNames = map[int]string{
+	1: "up",
+	2: "down",
+	3: "testing",
+	4: "unknown",
+	5: "dormant",
+	6: "notPresent",
+	7: "lowerLayerDown",
+}
+
+var managedNames = map[string]string{
+	"M": "Managed",
+	"U": "Unmanaged",
+	"F": "Forced Unmanaged",
+	"N": "Not Polled",
+	"X": "Remotely Monitored",
+}
+
+var primaryNames = map[string]string{
+	"P": "Primary",
+	"S": "Secondary",
+	"N": "Not Eligible",
+}
+
+var ipInterfacesCommand = cli.Command{
+	Name:      "ipinterfaces",
+	ShortName: "ip",
+	Usage:     "List the IP interfaces of a deployed node",
+	ArgsUsage: "<nodeId|foreignSource:foreignID>",
+	Action:    listIPInterfaces,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "primary",
+			Usage: "Show only the primary SNMP interface",
+		},
+	},
+}
+
+var snmpInterfacesCommand = cli.Command{
+	Name:      "snmpinterfaces",
+	ShortName: "snmp",
+	Usage:     "List the SNMP interfaces of a deployed node",
+	ArgsUsage: "<nodeId|foreignSource:foreignID>",
+	Action:    listSnmpInterfaces,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "only-down",
+			Usage: "Show only the interfaces whose operational status is not up",
+		},
+		cli.BoolFlag{
+			Name:  "wide",
+			Usage: "Show the descriptions and aliases without truncating them",
+		},
+	},
+}
+
+func listIPInterfaces(c *cli.Context) error {
+	node, err := getAPI(c).GetNode(c.Args().First())
+	if err != nil {
+		return err
+	}
+	list, err := getAPI(c).GetIPInterfaces(node.ID)
+	if err != nil {
+		return err
+	}
+	interfaces := make([]model.OnmsIPInterface, 0, len(list.Interfaces))
+	for _, intf := range list.Interfaces {
+		if !c.Bool("primary") || intf.SnmpPrimary == "P" {
+			interfaces = append(interfaces, intf)
+		}
+	}
+	table := common.NewTable(fmt.Sprintf("There are no IP interfaces on node %s (%s)", node.ID, node.Label), "IP Address", "Hostname", "Managed", "SNMP Primary", "ifIndex", "Services", "Last Capsd Poll")
+	for _, intf := range interfaces {
+		table.AddRow(intf.IPAddress, valueOrDash(intf.HostName), describe(managedNames, intf.IsManaged), describe(primaryNames, intf.SnmpPrimary), ifIndexOrDash(intf.IfIndex), intf.MonitoredServiceCount, common.DisplayTime(intf.LastPoll))
+	}
+	return common.Print(interfaces, table)
+}
+
+func listSnmpInterfaces(c *cli.Context) error {
+	node, err := getAPI(c).GetNode(c.Args().First())
+	if err != nil {
+		return err
+	}
+	list, err := getAPI(c).GetSnmpInterfaces(node.ID)
+	if err != nil {
+		return err
+	}
+	interfaces := make([]model.OnmsSnmpInterface, 0, len(list.Interfaces))
+	for _, intf := range list.Interfaces {
+		if !c.Bool("only-down") || intf.IfOperStatus != ifStatusUp {
+			interfaces = append(interfaces, intf)
+		}
+	}
+	width := maxDescriptionWidth
+	if c.Bool("wide") {
+		width = 0
+	}
+	table := common.NewTable(fmt.Sprintf("There are no SNMP interfaces on node %s (%s)", node.ID, node.Label), "ifIndex", "ifName", "ifDescr", "ifAlias", "ifSpeed", "Admin", "Oper", "Collect", "Last Capsd Poll", "Last Collection")
+	for _, intf := range interfaces {
+		table.AddRow(intf.IfIndex, valueOrDash(intf.IfName), valueOrDash(common.Truncate(intf.IfDescr, width)), valueOrDash(common.Truncate(intf.IfAlias, width)), formatSpeed(intf.IfSpeed), ifStatus(intf.IfAdminStatus), ifStatus(intf.IfOperStatus), intf.Collect, common.DisplayTime(intf.LastPoll), common.DisplayTime(intf.LastSnmpPoll))
+	}
+	return common.Print(interfaces, table)
+}
+
+// Formats an interface speed in bits per second with the largest unit that keeps it above 1
+func formatSpeed(speed int) string {
+	if speed <= 0 {
+		return "-"
+	}
+	units := []string{"bps", "Kbps", "Mbps", "Gbps", "Tbps"}
+	value := float64(speed)
+	unit := 0
+	for value >= 1000 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+	return fmt.Sprintf("%.4g %s", value, units[unit])
+}
+
+func ifStatus(status int) string {
+	if name, ok := ifStatusNames[status]; ok {
+		return name
+	}
+	return "-"
+}
+
+func describe(names map[string]string, value string) string {
+	if name, ok := names[value]; ok {
+		return name
+	}
+	return valueOrDash(value)
+}
+
+func ifIndexOrDash(ifIndex int) string {
+	if ifIndex <= 0 {
+		return "-"
+	}
+	return fmt.Sprint(ifIndex)
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,110 @@
+package nodes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func createInterfacesMockServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.Path {
+		case "/api/v2/nodes":
+			switch req.URL.Query().Get("_s") {
+			case "id==1", "foreignSource==Servers;foreignId==web01":
+				bytes, _ := json.Marshal(model.OnmsNodeList{Count: 1, TotalCount: 1, Nodes: mockData.Nodes})
+				res.Write(bytes)
+			case "id==2":
+				bytes, _ := json.Marshal(model.OnmsNodeList{Count: 1, TotalCount: 1, Nodes: []model.OnmsNode{{ID: "2", Label: "empty"}}})
+				res.Write(bytes)
+			default:
+				res.WriteHeader(http.StatusNoContent)
+			}
+		case "/api/v2/nodes/1/ipinterfaces":
+			res.Write([]byte(`{"count":2,"totalCount":2,"offset":0,"ipInterface":[
+				{"id":10,"ipAddress":"10.0.0.1","hostName":"web01","isManaged":"M","snmpPrimary":"P","ifIndex":2,"monitoredServiceCount":3},
+				{"id":11,"ipAddress":"10.0.0.2","isManaged":"U","snmpPrimary":"N","monitoredServiceCount":0}
+			]}`))
+		case "/api/v2/nodes/1/snmpinterfaces":
+			res.Write([]byte(`{"count":2,"totalCount":2,"offset":0,"snmpInterface":[
+				{"id":20,"ifIndex":1,"ifName":"lo","ifDescr":"Loopback","ifSpeed":10000000,"ifAdminStatus":1,"ifOperStatus":1,"collect":false},
+				{"id":21,"ifIndex":2,"ifName":"Gi0/1","ifDescr":"GigabitEthernet0/1 - Uplink to the core switch","ifSpeed":1000000000,"ifAdminStatus":1,"ifOperStatus":2,"collect":true}
+			]}`))
+		default:
+			res.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	return server
+}
+
+func TestListIPInterfaces(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createInterfacesMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "nodes", "ipinterfaces", "Servers:web01")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[0], "IP Address  Hostname  Managed    SNMP Primary  ifIndex  Services  Last Capsd Poll"))
+	assert.Assert(t, strings.HasPrefix(lines[1], "10.0.0.1    web01     Managed    Primary       2        3         Never"))
+	assert.Assert(t, strings.HasPrefix(lines[2], "10.0.0.2    -         Unmanaged  Not Eligible  -        0         Never"))
+
+	output, err = test.RunWithOutput(app, "table", "nodes", "ipinterfaces", "--primary", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(strings.Split(strings.TrimSpace(output), "\n")))
+	assert.Assert(t, !strings.Contains(output, "10.0.0.2"))
+
+	output, err = test.RunWithOutput(app, "table", "nodes", "ipinterfaces", "2")
+	assert.NilError(t, err)
+	assert.Equal(t, "There are no IP interfaces on node 2 (empty)\n", output)
+
+	_, err = test.RunWithOutput(app, "table", "nodes", "ipinterfaces", "Servers:unknown")
+	assert.Error(t, err, "Node Servers:unknown doesn't exist")
+}
+
+func TestListSnmpInterfaces(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createInterfacesMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "nodes", "snmpinterfaces", "1")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, "GigabitEthernet0/1 - Uplink...  "))
+	assert.Assert(t, strings.Contains(output, "1 Gbps"))
+	assert.Assert(t, strings.Contains(output, "10 Mbps"))
+
+	output, err = test.RunWithOutput(app, "table", "nodes", "snmpinterfaces", "--wide", "1")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, "GigabitEthernet0/1 - Uplink to the core switch"))
+
+	output, err = test.RunWithOutput(app, "table", "nodes", "snmpinterfaces", "--only-down", "1")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[1], "2        Gi0/1"))
+	assert.Assert(t, strings.Contains(lines[1], "up     down"))
+
+	output, err = test.RunWithOutput(app, "json", "nodes", "snmpinterfaces", "--only-down", "1")
+	assert.NilError(t, err)
+	interfaces := []model.OnmsSnmpInterface{}
+	assert.NilError(t, json.Unmarshal([]byte(output), &interfaces))
+	assert.Equal(t, 1, len(interfaces))
+	assert.Equal(t, "Gi0/1", interfaces[0].IfName)
+}
+
+func TestFormatSpeed(t *testing.T) {
+	assert.Equal(t, "-", formatSpeed(0))
+	assert.Equal(t, "100 bps", formatSpeed(100))
+	assert.Equal(t, "1.544 Mbps", formatSpeed(1544000))
+	assert.Equal(t, "10 Gbps", formatSpeed(10000000000))
+}
@@ -0,0 +1,127 @@
+package nodes
+
+import (
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// CliCommand the CLI command to manage nodes from the OpenNMS database
+var CliCommand = cli.Command{
+	Name:  "nodes",
+	Usage: "Manage deployed nodes (from the OpenNMS database)",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List deployed nodes",
+			Action: listNodes,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "A FIQL expression to filter nodes (e.x. 'label==web*;location==MINION')",
+				},
+				cli.IntFlag{
+					Name:  "limit, l",
+					Usage: "The amount of nodes per query",
+					Value: 10,
+				},
+				cli.IntFlag{
+					Name:  "offset",
+					Usage: "The starting node index (for pagination)",
+					Value: 0,
+				},
+				common.AllPagesFlag,
+			},
+		},
+		{
+			Name:      "delete",
+			ShortName: "del",
+			Usage:     "Deletes a node from the database",
+			ArgsUsage: "<nodeId|foreignSource:foreignID>",
+			Description: `Removes the node and all its data (events, alarms, outages, etc.) from the database.
+	If the node is still on its requisition, it will be added again on the next import;
+	use --from-requisition to remove it from the requisition and import it right away
+	(with rescanExisting=dbonly, to avoid scanning the other nodes).`,
+			Action: deleteNode,
+			Flags: []cli.Flag{
+				common.YesFlag,
+				cli.BoolFlag{
+					Name:  "from-requisition",
+					Usage: "Delete the node from its requisition and import it, instead of deleting it directly from the database",
+				},
+			},
+		},
+		ipInterfacesCommand,
+		snmpInterfacesCommand,
+		hardwareCommand,
+	},
+}
+
+func listNodes(c *cli.Context) error {
+	if err := fiql.Validate(c.String("filter")); err != nil {
+		return err
+	}
+	var list *model.OnmsNodeList
+	var err error
+	if c.Bool("all") {
+		list, err = getAPI(c).GetAllNodes(c.String("filter"))
+	} else {
+		list, err = getAPI(c).GetNodes(c.String("filter"), c.Int("limit"), c.Int("offset"))
+	}
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no nodes", "ID", "Label", "Foreign Source", "Foreign ID", "Location")
+	for _, n := range list.Nodes {
+		table.AddRow(n.ID, n.Label, n.ForeignSource, n.ForeignID, n.Location)
+	}
+	if err := common.Print(list.Nodes, table); err != nil {
+		return err
+	}
+	if list.TotalCount > list.Offset+len(list.Nodes) && common.OutputFormat == common.OutputTable {
+		common.Log.Infof("Showing %d of %d nodes; use --all, or --offset and --limit, to see more", len(list.Nodes), list.TotalCount)
+	}
+	return nil
+}
+
+func deleteNode(c *cli.Context) error {
+	node, err := getAPI(c).GetNode(c.Args().First())
+	if err != nil {
+		return err
+	}
+	if c.Bool("from-requisition") && node.ForeignSource == "" {
+		return fmt.Errorf("Node %s (%s) doesn't belong to a requisition", node.ID, node.Label)
+	}
+	description := fmt.Sprintf("Node %s (%s) will be deleted from the database with all its data", node.ID, node.Label)
+	if c.Bool("from-requisition") {
+		description = fmt.Sprintf("Node %s (%s) will be deleted from requisition %s and from the database", node.ID, node.Label, node.ForeignSource)
+	}
+	if err := common.Confirm(c, description); err != nil {
+		return err
+	}
+	if c.Bool("from-requisition") {
+		reqAPI := services.GetRequisitionsAPI(common.GetClient(c))
+		if err := reqAPI.DeleteNode(node.ForeignSource, node.ForeignID); err != nil {
+			return err
+		}
+		if err := reqAPI.ImportRequisition(node.ForeignSource, model.RescanDBOnly); err != nil {
+			return err
+		}
+		common.Log.Infof("Node %s (%s) deleted from requisition %s, import requested", node.ID, node.Label, node.ForeignSource)
+		return nil
+	}
+	if err := getAPI(c).DeleteNode(node.ID); err != nil {
+		return err
+	}
+	common.Log.Infof("Node %s (%s) deleted", node.ID, node.Label)
+	return nil
+}
+
+func getAPI(c *cli.Context) api.NodesAPI {
+	return services.GetNodesAPI(common.GetClient(c))
+}
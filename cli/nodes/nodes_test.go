@@ -0,0 +1,158 @@
+package nodes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+var mockData = &model.OnmsNodeList{
+	Count:      1,
+	TotalCount: 3,
+	Nodes: []model.OnmsNode{
+		{ID: "1", Label: "web01", ForeignSource: "Servers", ForeignID: "web01"},
+	},
+}
+
+func createMockServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/v2/nodes", req.URL.Path)
+		assert.Equal(t, http.MethodGet, req.Method)
+		if req.URL.Query().Get("_s") == "label==unknown" {
+			res.WriteHeader(http.StatusNoContent)
+			return
+		}
+		assert.Equal(t, "10", req.URL.Query().Get("limit"))
+		bytes, _ := json.Marshal(mockData)
+		res.WriteHeader(http.StatusOK)
+		res.Write(bytes)
+	}))
+	return server
+}
+
+func TestListNodes(t *testing.T) {
+	var err error
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "nodes", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `ID  Label  Foreign Source  Foreign ID  Location
+1   web01  Servers         web01       
+`, output)
+
+	output, err = test.RunWithOutput(app, "jsonpath=$[*].label", "nodes", "list", "--offset", "0")
+	assert.NilError(t, err)
+	assert.Equal(t, "web01\n", output)
+
+	err = app.Run([]string{app.Name, "nodes", "list", "-f", "label==web*;location==MINION"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "nodes", "list", "-f", "label==unknown"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "nodes", "list", "-l", "-1"})
+	assert.ErrorContains(t, err, "Limit")
+}
+
+func TestListAllNodes(t *testing.T) {
+	pages := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/v2/nodes", req.URL.Path)
+		assert.Equal(t, "100", req.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(req.URL.Query().Get("offset"))
+		pages = append(pages, req.URL.Query().Get("offset"))
+		list := model.OnmsNodeList{TotalCount: 250, Offset: offset}
+		for i := offset; i < offset+100 && i < 250; i++ {
+			list.Nodes = append(list.Nodes, model.OnmsNode{ID: strconv.Itoa(i + 1), Label: fmt.Sprintf("srv%03d", i+1)})
+		}
+		list.Count = len(list.Nodes)
+		bytes, _ := json.Marshal(list)
+		res.Write(bytes)
+	}))
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	err := app.Run([]string{app.Name, "nodes", "list", "--all", "-l", "5"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"0", "100", "200"}, pages)
+}
+
+func createDeleteMockServer(t *testing.T, calls *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && req.URL.Path == "/api/v2/nodes" {
+			switch req.URL.Query().Get("_s") {
+			case "id==1", "foreignSource==Servers;foreignId==web01":
+				bytes, _ := json.Marshal(model.OnmsNodeList{Count: 1, TotalCount: 1, Nodes: mockData.Nodes})
+				res.Write(bytes)
+			case "id==2":
+				bytes, _ := json.Marshal(model.OnmsNodeList{Count: 1, TotalCount: 1, Nodes: []model.OnmsNode{{ID: "2", Label: "rogue"}}})
+				res.Write(bytes)
+			default:
+				res.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+		if req.URL.Path == "/rest/requisitionNames" {
+			res.Write([]byte(`{"count":1,"foreign-source":["Servers"]}`))
+			return
+		}
+		*calls = append(*calls, req.Method+" "+req.URL.RequestURI())
+		res.WriteHeader(http.StatusNoContent)
+	}))
+	return server
+}
+
+func TestDeleteNode(t *testing.T) {
+	calls := []string{}
+	app := test.CreateCli(CliCommand)
+	server := createDeleteMockServer(t, &calls)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	defer func() { common.ConfirmInput = nil }()
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "nodes", "delete", "Servers:unknown")
+	assert.Error(t, err, "Node Servers:unknown doesn't exist")
+
+	_, err = test.RunWithOutput(app, "table", "nodes", "delete", "web01")
+	assert.ErrorContains(t, err, "Invalid node web01")
+
+	common.ConfirmInput = strings.NewReader("n\n")
+	_, err = test.RunWithOutput(app, "table", "nodes", "delete", "1")
+	assert.Error(t, err, "Operation cancelled")
+	assert.Equal(t, 0, len(calls))
+
+	common.ConfirmInput = strings.NewReader("yes\n")
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "nodes", "delete", "Servers:web01")
+	assert.NilError(t, err)
+	assert.Equal(t, "Node 1 (web01) deleted\n", logs.String())
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "nodes", "delete", "--yes", "--from-requisition", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, "Node 1 (web01) deleted from requisition Servers, import requested\n", logs.String())
+
+	_, err = test.RunWithOutput(app, "table", "nodes", "delete", "--yes", "--from-requisition", "2")
+	assert.Error(t, err, "Node 2 (rogue) doesn't belong to a requisition")
+
+	assert.DeepEqual(t, []string{
+		"DELETE /api/v2/nodes/1",
+		"DELETE /rest/requisitions/Servers/nodes/web01",
+		"PUT /rest/requisitions/Servers/import?rescanExisting=dbonly",
+	}, calls)
+}
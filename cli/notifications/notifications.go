@@ -0,0 +1,307 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// CliCommand the CLI command to manage notifications
+var CliCommand = cli.Command{
+	Name:      "notifications",
+	ShortName: "notif",
+	Usage:     "Manage notifications, event notifications and destination paths",
+	Subcommands: []cli.Command{
+		{
+			Name:         "status",
+			Usage:        "Shows whether notifications are sent, or turns them on or off globally",
+			ArgsUsage:    "[on|off]",
+			Action:       notificationStatus,
+			BashComplete: statusBashComplete,
+			Description: `Without arguments, the current status is displayed.
+	Turning notifications off on a production profile (see 'config profile set --production')
+	requires confirmation.`,
+			Flags: []cli.Flag{
+				common.YesFlag,
+			},
+		},
+		{
+			Name:  "events",
+			Usage: "Manage the notifications sent when events are received",
+			Subcommands: []cli.Command{
+				{
+					Name:   "list",
+					Usage:  "List the event notifications",
+					Action: listEventNotifications,
+				},
+				{
+					Name:      "add",
+					Usage:     "Adds an event notification",
+					ArgsUsage: "<name>",
+					Action:    addEventNotification,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "uei, u",
+							Usage: "The UEI of the event that triggers the notification",
+						},
+						cli.StringFlag{
+							Name:  "path, p",
+							Usage: "The destination path of the notification",
+						},
+						cli.StringFlag{
+							Name:  "subject, s",
+							Usage: "The subject of the notification",
+						},
+						cli.StringFlag{
+							Name:  "text, t",
+							Usage: "The text of the notification (defaults to the log message of the event)",
+						},
+						cli.StringFlag{
+							Name:  "rule, r",
+							Usage: "The filter rule the interface of the event must match (defaults to all interfaces)",
+						},
+						cli.StringFlag{
+							Name:  "description, d",
+							Usage: "The description of the notification",
+						},
+						cli.BoolFlag{
+							Name:  "disabled",
+							Usage: "Add the notification turned off",
+						},
+					},
+				},
+				{
+					Name:         "remove",
+					ShortName:    "rm",
+					Usage:        "Removes an event notification",
+					ArgsUsage:    "<name>",
+					Action:       removeEventNotification,
+					BashComplete: eventNotificationBashComplete,
+					Flags: []cli.Flag{
+						common.YesFlag,
+					},
+				},
+			},
+		},
+		{
+			Name:      "destination-paths",
+			ShortName: "paths",
+			Usage:     "Manage the destination paths, who is notified and how",
+			Subcommands: []cli.Command{
+				{
+					Name:   "list",
+					Usage:  "List the destination paths",
+					Action: listDestinationPaths,
+				},
+				{
+					Name:         "get",
+					Usage:        "Gets a destination path",
+					ArgsUsage:    "<name>",
+					Action:       showDestinationPath,
+					BashComplete: destinationPathBashComplete,
+				},
+				{
+					Name:      "apply",
+					Usage:     "Creates or replaces a destination path from a external YAML file",
+					ArgsUsage: "<yaml>",
+					Action:    applyDestinationPath,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "file, f",
+							Usage: "External YAML file (use '-' for STDIN Pipe)",
+						},
+					},
+				},
+				{
+					Name:         "delete",
+					ShortName:    "del",
+					Usage:        "Deletes a destination path",
+					ArgsUsage:    "<name>",
+					Action:       deleteDestinationPath,
+					BashComplete: destinationPathBashComplete,
+					Flags: []cli.Flag{
+						common.YesFlag,
+					},
+				},
+			},
+		},
+	},
+}
+
+// notifdStatus the global status of notifications
+type notifdStatus struct {
+	Status string `json:"status" yaml:"status"`
+}
+
+func notificationStatus(c *cli.Context) error {
+	if !c.Args().Present() {
+		status, err := getAPI(c).GetStatus()
+		if err != nil {
+			return err
+		}
+		table := common.NewTable("", "Status")
+		table.AddRow(status)
+		return common.Print(notifdStatus{status}, table)
+	}
+	status := c.Args().First()
+	if !model.NotificationStatus.Contains(status) {
+		return fmt.Errorf("Invalid notification status %s, valid options: %s", status, model.NotificationStatus.EnumAsString())
+	}
+	if status == model.NotificationStatusOff && common.GetClient(c).Production {
+		description := fmt.Sprintf("Notifications will be turned off on the production server %s; no one will be notified until they are turned on again", common.GetClient(c).URL)
+		if err := common.Confirm(c, description); err != nil {
+			return err
+		}
+	}
+	if err := getAPI(c).SetStatus(status); err != nil {
+		return err
+	}
+	common.Log.Infof("Notifications turned %s", status)
+	return nil
+}
+
+func listEventNotifications(c *cli.Context) error {
+	list, err := getAPI(c).GetEventNotifications()
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no event notifications", "Name", "Status", "UEI", "Destination Path")
+	for _, n := range list.Notifications {
+		table.AddRow(n.Name, n.Status, n.UEI, n.DestinationPath)
+	}
+	return common.Print(list.Notifications, table)
+}
+
+func addEventNotification(c *cli.Context) error {
+	notification := model.EventNotification{
+		Name:            c.Args().First(),
+		UEI:             c.String("uei"),
+		DestinationPath: c.String("path"),
+		Subject:         c.String("subject"),
+		TextMessage:     c.String("text"),
+		Rule:            c.String("rule"),
+		Description:     c.String("description"),
+	}
+	if c.Bool("disabled") {
+		notification.Status = model.NotificationStatusOff
+	}
+	return getAPI(c).AddEventNotification(notification)
+}
+
+func removeEventNotification(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("Notification name required")
+	}
+	if err := common.Confirm(c, fmt.Sprintf("Event notification %s will be removed", name)); err != nil {
+		return err
+	}
+	return getAPI(c).DeleteEventNotification(name)
+}
+
+func listDestinationPaths(c *cli.Context) error {
+	list, err := getAPI(c).GetDestinationPaths()
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no destination paths", "Name", "Initial Delay", "Targets", "Escalations")
+	for _, p := range list.Paths {
+		targets := make([]string, len(p.Targets))
+		for i, t := range p.Targets {
+			targets[i] = t.Name
+		}
+		delay := p.InitialDelay
+		if delay == "" {
+			delay = "0s"
+		}
+		table.AddRow(p.Name, delay, strings.Join(targets, ","), len(p.Escalations))
+	}
+	return common.Print(list.Paths, table)
+}
+
+func showDestinationPath(c *cli.Context) error {
+	path, err := getAPI(c).GetDestinationPath(c.Args().First())
+	if err != nil {
+		return err
+	}
+	return common.Print(path, nil)
+}
+
+func applyDestinationPath(c *cli.Context) error {
+	data, err := common.ReadInput(c, 0)
+	if err != nil {
+		return err
+	}
+	path := &model.DestinationPath{}
+	return common.ApplyYAML(data, path, func() error {
+		return getAPI(c).SetDestinationPath(*path)
+	})
+}
+
+func deleteDestinationPath(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("Destination path name required")
+	}
+	if !common.SkipConfirmation(c) {
+		list, err := getAPI(c).GetEventNotifications()
+		if err != nil {
+			return err
+		}
+		users := make([]string, 0)
+		for _, n := range list.Notifications {
+			if n.DestinationPath == name {
+				users = append(users, n.Name)
+			}
+		}
+		description := fmt.Sprintf("Destination path %s will be deleted", name)
+		if len(users) > 0 {
+			description += fmt.Sprintf("; it is used by %d event notifications: %s", len(users), strings.Join(users, ", "))
+		}
+		if err := common.Confirm(c, description); err != nil {
+			return err
+		}
+	}
+	return getAPI(c).DeleteDestinationPath(name)
+}
+
+func statusBashComplete(c *cli.Context) {
+	if c.NArg() == 0 {
+		common.PrintCompletions(model.NotificationStatus.Enum...)
+	}
+}
+
+func eventNotificationBashComplete(c *cli.Context) {
+	if c.NArg() > 0 {
+		return
+	}
+	list, err := getAPI(c).GetEventNotifications()
+	if err != nil {
+		return
+	}
+	for _, n := range list.Notifications {
+		common.PrintCompletions(n.Name)
+	}
+}
+
+func destinationPathBashComplete(c *cli.Context) {
+	if c.NArg() > 0 {
+		return
+	}
+	list, err := getAPI(c).GetDestinationPaths()
+	if err != nil {
+		return
+	}
+	for _, p := range list.Paths {
+		common.PrintCompletions(p.Name)
+	}
+}
+
+func getAPI(c *cli.Context) api.NotificationsAPI {
+	return services.GetNotificationsAPI(common.GetClient(c))
+}
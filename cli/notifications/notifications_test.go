@@ -0,0 +1,154 @@
+package notifications
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/test"
+
+	"gotest.tools/assert"
+)
+
+func createMockServer(t *testing.T, calls *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			*calls = append(*calls, req.Method+" "+req.URL.EscapedPath()+" "+string(bytes))
+			res.WriteHeader(http.StatusOK)
+			return
+		}
+		switch req.URL.Path {
+		case "/rest/notifd/status":
+			res.Write([]byte(`{"status":"on"}`))
+		case "/rest/notifd/events":
+			res.Write([]byte(`{"count":2,"notification":[{"name":"Node Down","status":"on","uei":"uei.opennms.org/nodes/nodeDown","destinationPath":"Email-Admin"},{"name":"Node Up","status":"off","uei":"uei.opennms.org/nodes/nodeUp","destinationPath":"Email-Admin"}]}`))
+		case "/rest/notifd/destinationPaths":
+			res.Write([]byte(`{"count":2,"path":[{"name":"Email-Admin","initial-delay":"1m","target":[{"name":"Admin","command":["javaEmail"]}],"escalate":[{"delay":"15m","target":[{"name":"Ops","command":["javaEmail"]}]}]},{"name":"Page","target":[{"name":"Admin","command":["javaPagerEmail"]},{"name":"Ops","command":["javaPagerEmail"]}]}]}`))
+		case "/rest/notifd/destinationPaths/Page":
+			res.Write([]byte(`{"name":"Page","target":[{"name":"Admin","command":["javaPagerEmail"]}]}`))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestNotificationStatus(t *testing.T) {
+	calls := []string{}
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, &calls)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	defer func() { common.ConfirmInput = nil }()
+
+	output, err := test.RunWithOutput(app, "table", "notifications", "status")
+	assert.NilError(t, err)
+	assert.Equal(t, "Status\non\n", output)
+	output, err = test.RunWithOutput(app, "json", "notifications", "status")
+	assert.NilError(t, err)
+	assert.Equal(t, "{\n  \"status\": \"on\"\n}\n", output)
+
+	_, err = test.RunWithOutput(app, "table", "notifications", "status", "disabled")
+	assert.Error(t, err, "Invalid notification status disabled, valid options: on, off")
+
+	logs, restore := test.CaptureLogs()
+	defer restore()
+	_, err = test.RunWithOutput(app, "table", "notifications", "status", "off")
+	assert.NilError(t, err)
+	assert.Equal(t, "Notifications turned off\n", logs.String())
+
+	test.Client(app).Production = true
+	common.ConfirmInput = strings.NewReader("n\n")
+	_, err = test.RunWithOutput(app, "table", "notifications", "status", "off")
+	assert.Error(t, err, "Operation cancelled")
+	assert.Equal(t, 1, len(calls))
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "notifications", "status", "on")
+	assert.NilError(t, err)
+	assert.Equal(t, "Notifications turned on\n", logs.String())
+	_, err = test.RunWithOutput(app, "table", "notifications", "status", "--yes", "off")
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, []string{
+		"PUT /rest/notifd/status status=off",
+		"PUT /rest/notifd/status status=on",
+		"PUT /rest/notifd/status status=off",
+	}, calls)
+}
+
+func TestEventNotifications(t *testing.T) {
+	calls := []string{}
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, &calls)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	defer func() { common.ConfirmInput = nil }()
+
+	output, err := test.RunWithOutput(app, "table", "notif", "events", "list")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(output, "Name"))
+	assert.Assert(t, strings.Contains(output, "Node Up"))
+	assert.Assert(t, strings.Contains(output, "uei.opennms.org/nodes/nodeDown"))
+
+	_, err = test.RunWithOutput(app, "table", "notif", "events", "add", "--uei", "uei.opennms.org/test", "Test")
+	assert.Error(t, err, "Notification Test requires a destination path")
+	_, err = test.RunWithOutput(app, "table", "notif", "events", "add", "--uei", "uei.opennms.org/test", "--path", "Page", "--subject", "Test event", "--disabled", "Test")
+	assert.NilError(t, err)
+
+	_, err = test.RunWithOutput(app, "table", "notif", "events", "remove")
+	assert.Error(t, err, "Notification name required")
+	common.ConfirmInput = strings.NewReader("n\n")
+	_, err = test.RunWithOutput(app, "table", "notif", "events", "remove", "Node Up")
+	assert.Error(t, err, "Operation cancelled")
+	_, err = test.RunWithOutput(app, "table", "notif", "events", "rm", "-y", "Node Up")
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, []string{
+		`POST /rest/notifd/events {"name":"Test","status":"off","uei":"uei.opennms.org/test","rule":"IPADDR != '0.0.0.0'","destinationPath":"Page","text-message":"%logmsg%","subject":"Test event"}`,
+		"DELETE /rest/notifd/events/Node%20Up ",
+	}, calls)
+}
+
+func TestDestinationPaths(t *testing.T) {
+	calls := []string{}
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, &calls)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	defer func() { common.ConfirmInput = nil }()
+
+	output, err := test.RunWithOutput(app, "table", "notif", "paths", "list")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[1], "Email-Admin  1m"))
+	assert.Assert(t, strings.HasPrefix(lines[2], "Page         0s             Admin,Ops  0"))
+
+	output, err = test.RunWithOutput(app, "yaml", "notif", "paths", "get", "Page")
+	assert.NilError(t, err)
+	assert.Equal(t, "name: Page\ntargets:\n- name: Admin\n  commands:\n  - javaPagerEmail\n", output)
+	_, err = test.RunWithOutput(app, "yaml", "notif", "paths", "get", "Unknown")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = test.RunWithOutput(app, "table", "notif", "paths", "apply", "name: Empty")
+	assert.Error(t, err, "Destination path Empty requires at least one target")
+	_, err = test.RunWithOutput(app, "table", "notif", "paths", "apply", "name: Ops\ntargets: [{name: Ops, commands: [javaEmail]}]")
+	assert.NilError(t, err)
+
+	common.ConfirmInput = strings.NewReader("n\n")
+	_, err = test.RunWithOutput(app, "table", "notif", "paths", "delete", "Email-Admin")
+	assert.Error(t, err, "Operation cancelled")
+	_, err = test.RunWithOutput(app, "table", "notif", "paths", "delete", "--yes", "Email-Admin")
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, []string{
+		`POST /rest/notifd/destinationPaths {"name":"Ops","target":[{"name":"Ops","command":["javaEmail"]}]}`,
+		"DELETE /rest/notifd/destinationPaths/Email-Admin ",
+	}, calls)
+}
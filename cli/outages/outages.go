@@ -0,0 +1,248 @@
+package outages
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// The name of the groups for outages without a category or a foreign source
+const noGroup = "None"
+
+// now the reference to calculate the age of current outages (replaced on tests)
+var now = time.Now
+
+var groupByOptions = model.NewEnum("category", "location", "foreign-source").WithDefault("category")
+
+var sortOptions = model.NewEnum("count", "age").WithDefault("count")
+
+// CliCommand the CLI command to inspect outages
+var CliCommand = cli.Command{
+	Name:  "outages",
+	Usage: "Inspect the outages of the monitored services",
+	Subcommands: []cli.Command{
+		{
+			Name:   "summary",
+			Usage:  "Summarizes the current outages by node category, location or foreign source",
+			Action: showSummary,
+			Flags: []cli.Flag{
+				cli.GenericFlag{
+					Name:  "group-by, g",
+					Value: groupByOptions,
+					Usage: "How to group the outages: " + groupByOptions.EnumAsString(),
+				},
+				cli.GenericFlag{
+					Name:  "sort",
+					Value: sortOptions,
+					Usage: "The order of the groups, by amount of outages or by the oldest outage: " + sortOptions.EnumAsString(),
+				},
+			},
+		},
+		{
+			Name:   "list",
+			Usage:  "List outages",
+			Action: listOutages,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "node, n",
+					Usage: "The node ID of the outages",
+				},
+				cli.BoolFlag{
+					Name:  "current, c",
+					Usage: "Only the outages whose service was not regained",
+				},
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "A FIQL expression to filter outages (e.x. 'ipAddress==10.0.0.1')",
+				},
+				cli.StringFlag{
+					Name:  "since",
+					Usage: "Only outages that started after the given time (e.x. 2h, '2h ago', now-1d, '2024-01-02 15:04' in local time, or an RFC3339 timestamp)",
+				},
+				cli.StringFlag{
+					Name:  "until",
+					Usage: "Only outages that started before the given time, with the same formats as --since",
+				},
+				cli.IntFlag{
+					Name:  "limit, l",
+					Usage: "The amount of outages per query",
+					Value: 10,
+				},
+				cli.IntFlag{
+					Name:  "offset",
+					Usage: "The starting outage index (for pagination)",
+					Value: 0,
+				},
+				common.AllPagesFlag,
+			},
+		},
+	},
+}
+
+func showSummary(c *cli.Context) error {
+	list, err := getAPI(c).GetOutages(model.CurrentOutagesFilter, 0, 0)
+	if err != nil {
+		return err
+	}
+	outages := make([]model.OnmsOutage, 0, len(list.Outages))
+	for _, o := range list.Outages {
+		if o.IsCurrent() {
+			outages = append(outages, o)
+		}
+	}
+	var groupsOf func(model.OnmsOutage) []string
+	var title string
+	switch c.String("group-by") {
+	case "location":
+		title = "Location"
+		groupsOf = func(o model.OnmsOutage) []string {
+			if o.Location == "" {
+				return []string{"Default"}
+			}
+			return []string{o.Location}
+		}
+	case "foreign-source":
+		title = "Foreign Source"
+		groupsOf = func(o model.OnmsOutage) []string {
+			if o.ForeignSource == "" {
+				return []string{noGroup}
+			}
+			return []string{o.ForeignSource}
+		}
+	default:
+		title = "Category"
+		categories, err := getNodeCategories(c, outages)
+		if err != nil {
+			return err
+		}
+		groupsOf = func(o model.OnmsOutage) []string {
+			if names := categories[o.NodeID]; len(names) > 0 {
+				return names
+			}
+			return []string{noGroup}
+		}
+	}
+	groups := model.GroupOutages(outages, groupsOf)
+	sortGroups(groups, c.String("sort"))
+	table := common.NewTable("There are no current outages", title, "Outages", "Nodes", "Oldest")
+	current := now()
+	for _, g := range groups {
+		table.AddRow(g.Name, g.Outages, g.Nodes, getDisplayAge(g.Oldest, current))
+	}
+	return common.Print(groups, table)
+}
+
+func listOutages(c *cli.Context) error {
+	builder := fiql.NewBuilder()
+	if node := c.String("node"); node != "" {
+		if _, err := strconv.Atoi(node); err != nil {
+			return fmt.Errorf("Invalid node ID %s", node)
+		}
+		builder.Equals("node.id", node)
+	}
+	if c.Bool("current") {
+		builder.Filter(model.CurrentOutagesFilter)
+	}
+	timeRange, err := common.TimeRangeFilter("ifLostService", c.String("since"), c.String("until"), now())
+	if err != nil {
+		return err
+	}
+	for _, expression := range timeRange {
+		builder.Filter(expression)
+	}
+	filter, err := builder.Filter(c.String("filter")).Build()
+	if err != nil {
+		return err
+	}
+	var list *model.OnmsOutageList
+	if c.Bool("all") {
+		list, err = getAPI(c).GetAllOutages(filter)
+	} else {
+		list, err = getAPI(c).GetOutages(filter, c.Int("limit"), c.Int("offset"))
+	}
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no outages", "ID", "Node", "IP Address", "Service", "Lost", "Regained", "Duration")
+	current := now()
+	for _, o := range list.Outages {
+		regained := "-"
+		if !o.IsCurrent() {
+			regained = common.DisplayTime(o.ServiceRegainedTime)
+		}
+		table.AddRow(o.ID, o.NodeLabel, o.IPAddress, o.ServiceName(), common.DisplayTime(o.ServiceLostTime), regained, common.HumanizeDuration(o.Duration(current)))
+	}
+	if err := common.Print(list.Outages, table); err != nil {
+		return err
+	}
+	if list.TotalCount > list.Offset+len(list.Outages) && common.OutputFormat == common.OutputTable {
+		common.Log.Infof("Showing %d of %d outages; use --all, or --offset and --limit, to see more", len(list.Outages), list.TotalCount)
+	}
+	return nil
+}
+
+// Obtains the category names of the nodes with outages, indexed by node ID
+func getNodeCategories(c *cli.Context, outages []model.OnmsOutage) (map[int][]string, error) {
+	categories := make(map[int][]string)
+	expressions := make([]string, 0)
+	for _, o := range outages {
+		if _, ok := categories[o.NodeID]; !ok {
+			categories[o.NodeID] = nil
+			expressions = append(expressions, "id=="+strconv.Itoa(o.NodeID))
+		}
+	}
+	if len(expressions) == 0 {
+		return categories, nil
+	}
+	list, err := services.GetNodesAPI(common.GetClient(c)).GetNodes(strings.Join(expressions, ","), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range list.Nodes {
+		id, err := strconv.Atoi(n.ID)
+		if err != nil {
+			continue
+		}
+		for _, cat := range n.Categories {
+			categories[id] = append(categories[id], cat.Name)
+		}
+	}
+	return categories, nil
+}
+
+// Sorts the groups by the amount of outages or by age, the oldest first; ties are sorted by name
+func sortGroups(groups []model.OutageGroup, order string) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if order == "age" {
+			switch {
+			case a.Oldest == nil || b.Oldest == nil:
+				return a.Oldest != nil && b.Oldest == nil
+			case !a.Oldest.Equal(b.Oldest.Time):
+				return a.Oldest.Before(b.Oldest.Time)
+			}
+			return false
+		}
+		return a.Outages > b.Outages
+	})
+}
+
+func getDisplayAge(t *model.Time, current time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return common.HumanizeDuration(current.Sub(t.Time))
+}
+
+func getAPI(c *cli.Context) api.OutagesAPI {
+	return services.GetOutagesAPI(common.GetClient(c))
+}
@@ -0,0 +1,121 @@
+package outages
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+
+	"gotest.tools/assert"
+)
+
+// 2020-01-01T10:00:00Z
+const baseTime = 1577872800000
+
+var mockOutages = `{"count":4,"totalCount":4,"offset":0,"outage":[
+	{"id":1,"nodeId":1,"nodeLabel":"srv01","foreignSource":"Servers","ipAddress":"10.0.0.1","locationName":"Durham","ifLostService":1577872800000,"monitoredService":{"serviceType":{"name":"ICMP"}}},
+	{"id":2,"nodeId":1,"nodeLabel":"srv01","foreignSource":"Servers","ipAddress":"10.0.0.1","locationName":"Durham","ifLostService":1577876400000,"monitoredService":{"serviceType":{"name":"HTTP"}}},
+	{"id":3,"nodeId":2,"nodeLabel":"rtr01","foreignSource":"Routers","ipAddress":"10.0.0.254","locationName":"Apex","ifLostService":1577869200000,"monitoredService":{"serviceType":{"name":"SNMP"}}},
+	{"id":4,"nodeId":3,"nodeLabel":"rogue","ipAddress":"10.0.0.99","ifLostService":1577880000000,"monitoredService":{"serviceType":{"name":"ICMP"}}}
+]}`
+
+var mockNodes = `{"count":3,"totalCount":3,"offset":0,"node":[
+	{"id":"1","label":"srv01","categories":[{"id":1,"name":"Servers"},{"id":2,"name":"Production"}]},
+	{"id":"2","label":"rtr01","categories":[{"id":3,"name":"Routers"},{"id":2,"name":"Production"}]},
+	{"id":"3","label":"rogue"}
+]}`
+
+func createMockServer(t *testing.T, queries *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		*queries = append(*queries, req.URL.Path+" "+req.URL.Query().Get("_s"))
+		switch req.URL.Path {
+		case "/api/v2/outages":
+			if req.URL.Query().Get("_s") == "node.id==5" {
+				res.WriteHeader(http.StatusNoContent)
+				return
+			}
+			res.Write([]byte(mockOutages))
+		case "/api/v2/nodes":
+			res.Write([]byte(mockNodes))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestOutagesSummary(t *testing.T) {
+	queries := []string{}
+	server := createMockServer(t, &queries)
+	defer server.Close()
+	now = func() time.Time { return time.Unix(baseTime/1000, 0).Add(3*time.Hour + 12*time.Minute) }
+	defer func() { now = time.Now }()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	output, err := test.RunWithOutput(app, "table", "outages", "summary")
+	assert.NilError(t, err)
+	assert.Equal(t, `Category    Outages  Nodes  Oldest
+Production  3        2      4h12m
+Servers     2        1      3h12m
+None        1        1      1h12m
+Routers     1        1      4h12m
+`, output)
+	assert.DeepEqual(t, []string{
+		`/api/v2/outages ifRegainedService==\u0000`,
+		"/api/v2/nodes id==1,id==2,id==3",
+	}, queries)
+
+	output, err = test.RunWithOutput(app, "table", "outages", "summary", "--group-by", "location", "--sort", "age")
+	assert.NilError(t, err)
+	assert.Equal(t, `Location  Outages  Nodes  Oldest
+Apex      1        1      4h12m
+Durham    2        1      3h12m
+Default   1        1      1h12m
+`, output)
+
+	output, err = test.RunWithOutput(app, "table", "outages", "summary", "-g", "foreign-source")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, "None            1        1      1h12m"))
+	assert.Assert(t, strings.Contains(output, "Servers         2        1      3h12m"))
+
+	_, err = test.RunWithOutput(app, "table", "outages", "summary", "--sort", "severity")
+	assert.ErrorContains(t, err, "valid options: count, age")
+}
+
+func TestListOutages(t *testing.T) {
+	queries := []string{}
+	server := createMockServer(t, &queries)
+	defer server.Close()
+	now = func() time.Time { return time.Unix(baseTime/1000, 0).Add(3*time.Hour + 12*time.Minute) }
+	defer func() { now = time.Now }()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	_, err := test.RunWithOutput(app, "table", "outages", "list", "--node", "srv01")
+	assert.Error(t, err, "Invalid node ID srv01")
+
+	output, err := test.RunWithOutput(app, "table", "outages", "list", "--node", "5")
+	assert.NilError(t, err)
+	assert.Equal(t, "There are no outages\n", output)
+
+	output, err = test.RunWithOutput(app, "table", "outages", "list", "--node", "1", "--current")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 5, len(lines))
+	assert.Assert(t, strings.Contains(lines[1], "srv01  10.0.0.1    ICMP"))
+	assert.Assert(t, strings.HasSuffix(lines[1], "-         3h12m"))
+	assert.Assert(t, strings.HasSuffix(lines[4], "-         1h12m"), lines[4])
+	assert.Equal(t, `/api/v2/outages node.id==1;ifRegainedService==\u0000`, queries[len(queries)-1])
+
+	_, err = test.RunWithOutput(app, "table", "outages", "list", "--node", "1", "--since", "2h ago", "--until", "now")
+	assert.NilError(t, err)
+	since := now().Add(-2 * time.Hour).Format(common.FIQLTimeFormat)
+	until := now().Format(common.FIQLTimeFormat)
+	assert.Equal(t, "/api/v2/outages node.id==1;ifLostService=gt="+since+";ifLostService=lt="+until, queries[len(queries)-1])
+	_, err = test.RunWithOutput(app, "table", "outages", "list", "--since", "yesterday")
+	assert.ErrorContains(t, err, "Invalid time yesterday")
+}
@@ -0,0 +1,117 @@
+package pollers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// locationFlag the flag to select the monitoring location of the poller configuration
+var locationFlag = cli.StringFlag{
+	Name:  "location, l",
+	Value: "Default",
+	Usage: "The monitoring location whose poller configuration is displayed",
+}
+
+// CliCommand the CLI command to inspect the poller configuration
+var CliCommand = cli.Command{
+	Name:  "pollers",
+	Usage: "Inspect the poller packages and service monitors",
+	Subcommands: []cli.Command{
+		{
+			Name:   "packages",
+			Usage:  "List the poller packages with their filters and services",
+			Action: listPackages,
+			Flags: []cli.Flag{
+				locationFlag,
+			},
+		},
+		{
+			Name:   "monitors",
+			Usage:  "List the service monitors and their classes",
+			Action: listMonitors,
+			Flags: []cli.Flag{
+				locationFlag,
+			},
+		},
+		{
+			Name:  "evaluate",
+			Usage: "Shows the poller packages and services that apply to the IP interfaces of a node",
+			Description: "The server evaluates the packages when it provides the endpoint; otherwise, the filters are evaluated by onmsctl\n" +
+				"   against the categories and IP interfaces of the node, supporting catinc<Category> and IPADDR (IPLIKE, ==, !=).\n" +
+				"   Packages with other constructs are reported as not evaluated. The effective package of a service is the last one\n" +
+				"   that includes the interface, as Pollerd does.",
+			Action: evaluateNode,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "node, n",
+					Usage: "The node ID or foreignSource:foreignID",
+				},
+				cli.BoolFlag{
+					Name:  "client-side",
+					Usage: "Evaluate the filters on the client side even if the server can evaluate them",
+				},
+			},
+		},
+	},
+}
+
+func listPackages(c *cli.Context) error {
+	config, err := getAPI(c).GetConfiguration(c.String("location"))
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no poller packages", "PACKAGE", "FILTER", "SERVICES")
+	for _, pkg := range config.Packages {
+		services := make([]string, 0, len(pkg.Services))
+		for _, svc := range pkg.Services {
+			if svc.IsEnabled() {
+				services = append(services, svc.Name)
+			} else {
+				services = append(services, svc.Name+" (off)")
+			}
+		}
+		table.AddRow(pkg.Name, pkg.Filter, strings.Join(services, ", "))
+	}
+	return common.Print(config.Packages, table)
+}
+
+func listMonitors(c *cli.Context) error {
+	config, err := getAPI(c).GetConfiguration(c.String("location"))
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no service monitors", "SERVICE", "CLASS")
+	for _, m := range config.Monitors {
+		table.AddRow(m.Service, m.ClassName)
+	}
+	return common.Print(config.Monitors, table)
+}
+
+func evaluateNode(c *cli.Context) error {
+	node := c.String("node")
+	if node == "" {
+		return fmt.Errorf("Node ID or foreignSource:foreignID required")
+	}
+	evaluation, err := getAPI(c).EvaluateNode(node, c.Bool("client-side"))
+	if err != nil {
+		return err
+	}
+	for _, p := range evaluation.Unevaluated {
+		common.Log.Warnf("Package %s was not evaluated: %s", p.Package, p.Reason)
+	}
+	table := common.NewTable(fmt.Sprintf("No poller package applies to node %s", node), "IP ADDRESS", "PACKAGE", "SERVICE", "INTERVAL", "EFFECTIVE")
+	for _, s := range evaluation.Services {
+		table.AddRow(s.IPAddress, s.Package, s.Service, time.Duration(s.Interval)*time.Millisecond, s.Effective)
+	}
+	return common.Print(evaluation, table)
+}
+
+func getAPI(c *cli.Context) api.PollersAPI {
+	return services.GetPollersAPI(common.GetClient(c))
+}
@@ -0,0 +1,111 @@
+package pollers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+const pollerConfig = `<?xml version="1.0"?>
+<poller-configuration threads="30">
+  <package name="example1">
+    <filter>IPADDR != '0.0.0.0'</filter>
+    <include-range begin="1.1.1.1" end="254.254.254.254"/>
+    <service name="ICMP" interval="300000" status="on"/>
+    <service name="SNMP" interval="300000" status="off"/>
+  </package>
+  <package name="production">
+    <filter>catincProduction</filter>
+    <include-range begin="10.0.0.1" end="10.0.0.254"/>
+    <service name="ICMP" interval="60000" status="on"/>
+  </package>
+  <package name="cisco">
+    <filter>nodeSysOID LIKE '.1.3.6.1.4.1.9.%'</filter>
+    <include-range begin="1.1.1.1" end="254.254.254.254"/>
+    <service name="SNMP" interval="30000"/>
+  </package>
+  <monitor service="ICMP" class-name="org.opennms.netmgt.poller.monitors.IcmpMonitor"/>
+  <monitor service="SNMP" class-name="org.opennms.netmgt.poller.monitors.SnmpMonitor"/>
+</poller-configuration>`
+
+func createMockServer(t *testing.T, evaluation bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.Path {
+		case "/rest/config/Default/polling":
+			res.Write([]byte(pollerConfig))
+		case "/rest/config/polling/evaluate":
+			if !evaluation {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			assert.Equal(t, "Servers:web01", req.URL.Query().Get("node"))
+			res.Write([]byte(`{"node": "Servers:web01", "services": [{"ipAddress": "10.0.0.1", "package": "production", "service": "ICMP", "interval": 60000, "effective": true}]}`))
+		case "/api/v2/nodes":
+			assert.Equal(t, "foreignSource==Servers;foreignId==web01", req.URL.Query().Get("_s"))
+			res.Write([]byte(`{"count": 1, "totalCount": 1, "node": [{"id": "1", "label": "web01", "categories": [{"id": 1, "name": "Production"}]}]}`))
+		case "/api/v2/nodes/1/ipinterfaces":
+			res.Write([]byte(`{"count": 1, "totalCount": 1, "ipInterface": [{"id": 10, "ipAddress": "10.0.0.1"}]}`))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestListPackages(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, false)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "pollers", "packages")
+	assert.NilError(t, err)
+	assert.Equal(t, `PACKAGE     FILTER                              SERVICES
+example1    IPADDR != '0.0.0.0'                 ICMP, SNMP (off)
+production  catincProduction                    ICMP
+cisco       nodeSysOID LIKE '.1.3.6.1.4.1.9.%'  SNMP
+`, output)
+
+	output, err = test.RunWithOutput(app, "table", "pollers", "monitors")
+	assert.NilError(t, err)
+	assert.Equal(t, `SERVICE  CLASS
+ICMP     org.opennms.netmgt.poller.monitors.IcmpMonitor
+SNMP     org.opennms.netmgt.poller.monitors.SnmpMonitor
+`, output)
+
+	_, err = test.RunWithOutput(app, "table", "pollers", "packages", "--location", "Durham")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestEvaluateNode(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, true)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	output, err := test.RunWithOutput(app, "jsonpath=$.source", "pollers", "evaluate", "--node", "Servers:web01")
+	assert.NilError(t, err)
+	assert.Equal(t, "server\n", output)
+
+	output, err = test.RunWithOutput(app, "table", "pollers", "evaluate", "--client-side", "-n", "Servers:web01")
+	assert.NilError(t, err)
+	assert.Equal(t, `IP ADDRESS  PACKAGE     SERVICE  INTERVAL  EFFECTIVE
+10.0.0.1    example1    ICMP     5m0s      false
+10.0.0.1    example1    SNMP     5m0s      false
+10.0.0.1    production  ICMP     1m0s      true
+`, output)
+	assert.Equal(t, "WARNING: Package cisco was not evaluated: nodeSysOID cannot be evaluated on the client side\n", logs.String())
+
+	_, err = test.RunWithOutput(app, "table", "pollers", "evaluate")
+	assert.Error(t, err, "Node ID or foreignSource:foreignID required")
+}
@@ -2,6 +2,7 @@ package provisioning
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
@@ -28,11 +29,24 @@ var AssetsCliCommand = cli.Command{
 			Usage:     "Enumerate the list of available assets",
 			Action:    enumerateAssets,
 		},
+		{
+			Name:   "fields",
+			Usage:  "Shows the asset fields known by onmsctl with their categories, used to verify the assets of the nodes",
+			Action: listAssetFields,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "category, c",
+					Usage: "Only the fields of the given category (e.x. identification, location or hardware)",
+				},
+				extraAssetsFlag,
+			},
+		},
 		{
 			Name:      "set",
-			Usage:     "Adds or update an asset from a given requisition/node",
-			ArgsUsage: "<foreignSource> <foreignId> <assetKey> <assetValue>",
+			Usage:     "Adds or update an asset from a given requisition/node, or replaces all the assets when a list of key=value pairs is provided",
+			ArgsUsage: "<foreignSource> <foreignId> <assetKey> <assetValue> | <foreignSource> <foreignId> <assetKey=assetValue,...>",
 			Action:    setAsset,
+			Flags:     []cli.Flag{common.YesFlag, strictAssetsFlag, extraAssetsFlag},
 		},
 		{
 			Name:      "delete",
@@ -45,42 +59,124 @@ var AssetsCliCommand = cli.Command{
 }
 
 func listAssets(c *cli.Context) error {
-	node, err := getReqAPI().GetNode(c.Args().Get(0), c.Args().Get(1))
+	node, err := getReqAPI(c).GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
-	if len(node.Assets) == 0 {
-		fmt.Println("There are no assets on the chosen node")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Asset Name\tAsset Value")
+	table := common.NewTable("There are no assets on the chosen node", "Asset Name", "Asset Value")
 	for _, asset := range node.Assets {
-		fmt.Fprintf(writer, "%s\t%s\n", asset.Name, asset.Value)
+		table.AddRow(asset.Name, asset.Value)
 	}
-	writer.Flush()
-	return nil
+	return common.Print(node.Assets, table)
 }
 
 func enumerateAssets(c *cli.Context) error {
-	assets, err := getUtilsAPI().GetAvailableAssets()
+	assets, err := getUtilsAPI(c).GetAvailableAssets()
 	if err != nil {
 		return err
 	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Asset Name")
+	table := common.NewTable("", "Asset Name")
 	for _, asset := range assets.Element {
-		fmt.Fprintf(writer, "%s\n", asset)
+		table.AddRow(asset)
+	}
+	return common.Print(assets.Element, table)
+}
+
+// The category of the fields accepted through --extra-asset
+const extraAssetCategory = "extra"
+
+func listAssetFields(c *cli.Context) error {
+	fields := append([]model.AssetField{}, model.AssetFields...)
+	for _, name := range c.StringSlice("extra-asset") {
+		fields = append(fields, model.AssetField{Name: name, Category: extraAssetCategory})
+	}
+	if category := c.String("category"); category != "" {
+		selected := make([]model.AssetField, 0)
+		for _, f := range fields {
+			if strings.EqualFold(f.Category, category) {
+				selected = append(selected, f)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("There are no asset fields on category %s", category)
+		}
+		fields = selected
+	}
+	table := common.NewTable("", "Asset Name", "Category")
+	for _, f := range fields {
+		table.AddRow(f.Name, f.Category)
+	}
+	return common.Print(fields, table)
+}
+
+// Verifies the names of the assets against the known fields, warning about the unknown ones, or failing with --strict-assets;
+// the foreign ID is included on the messages when not empty
+func checkAssets(c *cli.Context, foreignID string, assets []model.RequisitionAsset) error {
+	for _, a := range assets {
+		err := a.CheckName(c.StringSlice("extra-asset")...)
+		if err == nil || a.Name == "" { // Empty names are rejected by the validation of the asset
+			continue
+		}
+		if foreignID != "" {
+			err = fmt.Errorf("Node %s: %w", foreignID, err)
+		}
+		if c.Bool("strict-assets") {
+			return common.ValidationError(err)
+		}
+		common.Log.Warnf("%s; use --strict-assets to reject unknown asset fields, or --extra-asset to accept it", err)
+	}
+	return nil
+}
+
+// Verifies the assets of all the nodes of the requisitions
+func checkRequisitionAssets(c *cli.Context, requisitions []model.Requisition) error {
+	for _, requisition := range requisitions {
+		for _, node := range requisition.Nodes {
+			if err := checkAssets(c, node.ForeignID, node.Assets); err != nil {
+				return err
+			}
+		}
 	}
-	writer.Flush()
 	return nil
 }
 
 func setAsset(c *cli.Context) error {
+	if c.NArg() == 3 && strings.Contains(c.Args().Get(2), "=") {
+		return replaceAssets(c)
+	}
+	// The server verifies the field against the ones it supports
 	asset := model.RequisitionAsset{Name: c.Args().Get(2), Value: c.Args().Get(3)}
-	return getReqAPI().SetAsset(c.Args().Get(0), c.Args().Get(1), asset)
+	return getReqAPI(c).SetAsset(c.Args().Get(0), c.Args().Get(1), asset)
 }
 
 func deleteAsset(c *cli.Context) error {
-	return getReqAPI().DeleteAsset(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	return getReqAPI(c).DeleteAsset(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+}
+
+func replaceAssets(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	assets := make([]model.RequisitionAsset, 0)
+	for _, pair := range splitList(c.Args().Get(2)) {
+		data := strings.SplitN(pair, "=", 2)
+		if len(data) != 2 || strings.TrimSpace(data[0]) == "" {
+			return fmt.Errorf("Invalid asset %s, expected key=value", pair)
+		}
+		assets = append(assets, model.RequisitionAsset{Name: strings.TrimSpace(data[0]), Value: data[1]})
+	}
+	if err := checkAssets(c, "", assets); err != nil {
+		return err
+	}
+	node, err := getReqAPI(c).GetNode(foreignSource, foreignID)
+	if err != nil {
+		return err
+	}
+	removed, added, changed := node.SetAssets(assets)
+	common.Log.Infof("Node %s: %s, changed: %s", foreignID, formatChanges(removed, added), formatNames(changed))
+	if len(removed) > 0 && !common.SkipConfirmation(c) && !common.DryRun {
+		return fmt.Errorf("Use --yes to confirm the removal of existing assets")
+	}
+	return common.Apply(node, func() error {
+		return getReqAPI(c).SetNode(foreignSource, *node)
+	})
 }
@@ -1,8 +1,13 @@
 package provisioning
 
 import (
+	"bytes"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/test"
 	"gotest.tools/assert"
 )
@@ -11,6 +16,7 @@ func TestListAssets(t *testing.T) {
 	var err error
 	app := test.CreateCli(AssetsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "asset", "list"})
@@ -27,6 +33,7 @@ func TestAddAsset(t *testing.T) {
 	var err error
 	app := test.CreateCli(AssetsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "asset", "set"})
@@ -49,6 +56,7 @@ func TestDeleteAsset(t *testing.T) {
 	var err error
 	app := test.CreateCli(AssetsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "asset", "delete"})
@@ -63,3 +71,87 @@ func TestDeleteAsset(t *testing.T) {
 	err = app.Run([]string{app.Name, "asset", "delete", "Test", "n1", "state"})
 	assert.NilError(t, err)
 }
+
+func TestReplaceAssets(t *testing.T) {
+	app := test.CreateCli(AssetsCliCommand)
+	updated := make(map[string]model.RequisitionNode)
+	server := createCategoriesTestServer(t, updated)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "asset", "set", "Test", "n1", "=NC")
+	assert.Error(t, err, "Invalid asset =NC, expected key=value")
+
+	_, err = test.RunWithOutput(app, "table", "asset", "set", "--yes", "Test", "n1", "state=NC,city=Raleigh")
+	assert.NilError(t, err)
+	assert.Equal(t, "Node n1: removed: none, added: state, changed: city\n", logs.String())
+	assert.DeepEqual(t, []model.RequisitionAsset{{Name: "state", Value: "NC"}, {Name: "city", Value: "Raleigh"}}, updated["n1"].Assets)
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "asset", "set", "Test", "n1", "state=NC")
+	assert.Error(t, err, "Use --yes to confirm the removal of existing assets")
+	assert.Equal(t, "Node n1: removed: city, added: state, changed: none\n", logs.String())
+
+	_, err = test.RunWithOutput(app, "table", "asset", "set", "-y", "Test", "n1", "state=NC")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []model.RequisitionAsset{{Name: "state", Value: "NC"}}, updated["n1"].Assets)
+}
+
+func TestUnknownAssets(t *testing.T) {
+	app := test.CreateCli(AssetsCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer func() { server.Close() }()
+	updated := make(map[string]model.RequisitionNode)
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	// A single field is verified by the server
+	err := app.Run([]string{app.Name, "asset", "set", "Test", "n1", "ctiy", "Durham"})
+	assert.Error(t, err, "Invalid Asset Field: ctiy; did you mean 'city'?")
+
+	server.Close()
+	server = createCategoriesTestServer(t, updated)
+	test.Client(app).URL = server.URL
+	_, err = test.RunWithOutput(app, "table", "asset", "set", "--yes", "Test", "n1", "state=NC,serialnumber=ABC123")
+	assert.NilError(t, err)
+	assert.Equal(t, "WARNING: Unknown asset field serialnumber; did you mean 'serialNumber'?; use --strict-assets to reject unknown asset fields, or --extra-asset to accept it\n"+
+		"Node n1: removed: city, added: state serialnumber, changed: none\n", logs.String())
+
+	_, err = test.RunWithOutput(app, "table", "asset", "set", "--yes", "--strict-assets", "Test", "n1", "state=NC,serialnumber=ABC123")
+	assert.Error(t, err, "Unknown asset field serialnumber; did you mean 'serialNumber'?")
+
+	_, err = test.RunWithOutput(app, "table", "asset", "set", "--yes", "--strict-assets", "Test", "n1", "state=NC,warrantyEnd=2030")
+	assert.Error(t, err, "Unknown asset field warrantyEnd")
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "asset", "set", "--yes", "--strict-assets", "--extra-asset", "warrantyEnd", "Test", "n1", "state=NC,warrantyEnd=2030")
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(logs.String(), "WARNING"), logs.String())
+
+	os.Setenv("ONMSCTL_EXTRA_ASSETS", "warrantyEnd,supportTier")
+	defer os.Unsetenv("ONMSCTL_EXTRA_ASSETS")
+	_, err = test.RunWithOutput(app, "table", "asset", "set", "--yes", "--strict-assets", "Test", "n1", "state=NC,supportTier=gold")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []model.RequisitionAsset{{Name: "state", Value: "NC"}, {Name: "supportTier", Value: "gold"}}, updated["n1"].Assets)
+}
+
+func TestListAssetFields(t *testing.T) {
+	app := test.CreateCli(AssetsCliCommand)
+
+	output, err := test.RunWithOutput(app, "table", "asset", "fields", "--category", "Hardware")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 13, len(lines)) // The header and the 12 fields
+	assert.Assert(t, strings.HasPrefix(lines[1], "cpu"), lines[1])
+
+	output, err = test.RunWithOutput(app, "yaml", "asset", "fields", "-c", "extra", "--extra-asset", "warrantyEnd")
+	assert.NilError(t, err)
+	assert.Equal(t, "- name: warrantyEnd\n  category: extra\n", output)
+
+	_, err = test.RunWithOutput(app, "table", "asset", "fields", "-c", "software")
+	assert.Error(t, err, "There are no asset fields on category software")
+}
@@ -0,0 +1,317 @@
+package provisioning
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The layout of a backup directory; the default foreign source definition has its own file,
+// as a requisition can be called default
+const (
+	backupManifestFile             = "manifest.yaml"
+	backupRequisitionsDir          = "requisitions"
+	backupForeignSourcesDir        = "foreign-sources"
+	backupDefaultForeignSourceFile = "default-foreign-source.yaml"
+	defaultForeignSource           = "default"
+)
+
+// BackupCliCommand the CLI command to backup the provisioning data of a server
+var BackupCliCommand = cli.Command{
+	Name:     "backup",
+	Usage:    "Saves all the requisitions and foreign source definitions as YAML files; requisitions that weren't imported since the last backup are skipped",
+	Category: "Backup",
+	Action:   backupInventory,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "dir",
+			Value: "backup",
+			Usage: "The directory for the backup",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "Save all the requisitions, even when they weren't imported since the last backup (e.x. to include pending changes), or the directory has a backup of another server",
+		},
+		cli.DurationFlag{
+			Name:  "delay",
+			Usage: "Time to wait between requisitions, to limit the load on the server (e.x. 500ms)",
+		},
+	},
+}
+
+// RestoreCliCommand the CLI command to restore the provisioning data saved by backup
+var RestoreCliCommand = cli.Command{
+	Name:     "restore",
+	Usage:    "Sends the foreign source definitions and then the requisitions saved by backup",
+	Category: "Backup",
+	Action:   restoreInventory,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "dir",
+			Value: "backup",
+			Usage: "The directory with the backup",
+		},
+		cli.StringFlag{
+			Name:  "only",
+			Usage: "Comma separated list of the requisitions to restore (e.x. req1,req2); the default foreign source definition is only restored when not used",
+		},
+		cli.DurationFlag{
+			Name:  "delay",
+			Usage: "Time to wait between requisitions, to limit the load on the server (e.x. 500ms)",
+		},
+	},
+}
+
+// backupManifest what was captured by a backup, from which server and when
+type backupManifest struct {
+	Server       string        `yaml:"server"`
+	Created      time.Time     `yaml:"created"`
+	Updated      time.Time     `yaml:"updated"`
+	Requisitions []backupEntry `yaml:"requisitions"`
+}
+
+// backupEntry a requisition saved with its foreign source definition
+type backupEntry struct {
+	Name       string      `yaml:"name"`
+	DateStamp  *model.Time `yaml:"dateStamp,omitempty"`
+	LastImport *model.Time `yaml:"lastImport,omitempty"`
+	Nodes      int         `yaml:"nodes"`
+}
+
+// Adds or replaces the entry of a requisition
+func (m *backupManifest) setEntry(entry backupEntry) {
+	for i := range m.Requisitions {
+		if m.Requisitions[i].Name == entry.Name {
+			m.Requisitions[i] = entry
+			return
+		}
+	}
+	m.Requisitions = append(m.Requisitions, entry)
+}
+
+func (m *backupManifest) getEntry(name string) *backupEntry {
+	for i := range m.Requisitions {
+		if m.Requisitions[i].Name == name {
+			return &m.Requisitions[i]
+		}
+	}
+	return nil
+}
+
+func backupInventory(c *cli.Context) error {
+	dir := c.String("dir")
+	for _, d := range []string{backupRequisitionsDir, backupForeignSourcesDir} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			return err
+		}
+	}
+	manifest, err := readBackupManifest(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if manifest != nil && manifest.Server != common.GetClient(c).URL && !c.Bool("force") {
+		return fmt.Errorf("Directory %s has a backup of %s; use another directory or --force", dir, manifest.Server)
+	}
+	if manifest == nil || c.Bool("force") {
+		manifest = &backupManifest{Server: common.GetClient(c).URL, Created: time.Now()}
+	}
+	names, err := getUtilsAPI(c).GetRequisitionNames()
+	if err != nil {
+		return err
+	}
+	// The deployed statistics tell when each requisition was imported, so the unchanged ones are skipped without fetching them
+	stats, err := getReqAPI(c).GetRequisitionsStats()
+	if err != nil {
+		return err
+	}
+	lastImports := make(map[string]*model.Time)
+	for _, s := range stats.ForeignSources {
+		lastImports[s.Name] = s.LastImport
+	}
+	fsDef, err := getFsAPI(c).GetForeignSourceDef(defaultForeignSource)
+	if err != nil {
+		return err
+	}
+	if err := writeRequisitionFile(filepath.Join(dir, backupDefaultForeignSourceFile), "yaml", fsDef); err != nil {
+		return err
+	}
+	skipped := 0
+	fetched := 0
+	for _, name := range names.ForeignSources {
+		if !c.Bool("force") && isBackupCurrent(dir, manifest.getEntry(name), lastImports[name]) {
+			common.Log.Infof("Requisition %s wasn't imported since the last backup, skipped", name)
+			skipped++
+			continue
+		}
+		if fetched > 0 && c.Duration("delay") > 0 {
+			if err := common.GetClient(c).Sleep(c.Duration("delay")); err != nil {
+				return err
+			}
+		}
+		fetched++
+		requisition, err := getReqAPI(c).GetRequisition(name)
+		if err != nil {
+			return err
+		}
+		fsDef, err := getFsAPI(c).GetForeignSourceDef(name)
+		if err != nil {
+			return err
+		}
+		if err := writeBackupFile(dir, backupForeignSourcesDir, name, fsDef); err != nil {
+			return err
+		}
+		if err := writeBackupFile(dir, backupRequisitionsDir, name, requisition); err != nil {
+			return err
+		}
+		// The manifest is saved after each requisition, so an interrupted backup can be resumed
+		manifest.setEntry(backupEntry{Name: name, DateStamp: requisition.DateStamp, LastImport: lastImports[name], Nodes: len(requisition.Nodes)})
+		manifest.Updated = time.Now()
+		if err := writeBackupManifest(dir, manifest); err != nil {
+			return err
+		}
+		common.Log.Infof("Requisition %s saved with %d nodes", name, len(requisition.Nodes))
+	}
+	manifest.Updated = time.Now()
+	if err := writeBackupManifest(dir, manifest); err != nil {
+		return err
+	}
+	common.Log.Infof("Backup of %d requisitions from %s saved to %s (%d skipped)", len(names.ForeignSources), manifest.Server, dir, skipped)
+	return nil
+}
+
+// Returns true when the backup of the requisition exists and was taken after its last import;
+// requisitions that were never imported are always saved
+func isBackupCurrent(dir string, entry *backupEntry, lastImport *model.Time) bool {
+	if entry == nil || entry.LastImport == nil || lastImport == nil || !entry.LastImport.Equal(lastImport.Time) {
+		return false
+	}
+	for _, d := range []string{backupRequisitionsDir, backupForeignSourcesDir} {
+		if !fileExists(getBackupFile(dir, d, entry.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func restoreInventory(c *cli.Context) error {
+	dir := c.String("dir")
+	manifest, err := readBackupManifest(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("Directory %s doesn't contain a backup (%s is missing)", dir, backupManifestFile)
+	}
+	if err != nil {
+		return err
+	}
+	entries := manifest.Requisitions
+	if only := c.String("only"); only != "" {
+		entries = make([]backupEntry, 0)
+		for _, name := range strings.Split(only, ",") {
+			name = strings.TrimSpace(name)
+			entry := manifest.getEntry(name)
+			if entry == nil {
+				return fmt.Errorf("Requisition %s is not part of the backup on %s", name, dir)
+			}
+			entries = append(entries, *entry)
+		}
+	}
+	// Everything is read and validated before sending anything
+	fsDefs := make([]model.ForeignSourceDef, 0)
+	if c.String("only") == "" {
+		fsDef := model.ForeignSourceDef{}
+		if err := readYAMLFile(filepath.Join(dir, backupDefaultForeignSourceFile), &fsDef); err != nil {
+			return err
+		}
+		fsDefs = append(fsDefs, fsDef)
+	}
+	requisitions := make([]model.Requisition, 0)
+	for _, entry := range entries {
+		fsDef := model.ForeignSourceDef{}
+		if err := readBackupFile(dir, backupForeignSourcesDir, entry.Name, &fsDef); err != nil {
+			return err
+		}
+		requisition := model.Requisition{}
+		if err := readBackupFile(dir, backupRequisitionsDir, entry.Name, &requisition); err != nil {
+			return err
+		}
+		if err := requisition.Validate(); err != nil {
+			return common.ValidationError(fmt.Errorf("Requisition %s: %w", entry.Name, err))
+		}
+		fsDefs = append(fsDefs, fsDef)
+		requisitions = append(requisitions, requisition)
+	}
+	if manifest.Server != common.GetClient(c).URL {
+		common.Log.Warnf("The backup was taken from %s, and it is restored to %s", manifest.Server, common.GetClient(c).URL)
+	}
+	// The foreign source definitions must exist before the requisitions are sent
+	for _, fsDef := range fsDefs {
+		if err := getFsAPI(c).SetForeignSourceDef(fsDef); err != nil {
+			return fmt.Errorf("Cannot restore foreign source definition %s: %w", fsDef.Name, err)
+		}
+	}
+	for i, requisition := range requisitions {
+		if i > 0 && c.Duration("delay") > 0 {
+			if err := common.GetClient(c).Sleep(c.Duration("delay")); err != nil {
+				return err
+			}
+		}
+		if err := getReqAPI(c).SetRequisition(requisition); err != nil {
+			return fmt.Errorf("Cannot restore requisition %s: %w", requisition.Name, err)
+		}
+		common.Log.Infof("Requisition %s restored with %d nodes", requisition.Name, len(requisition.Nodes))
+	}
+	common.Log.Infof("Restored %d foreign source definitions and %d requisitions from %s", len(fsDefs), len(requisitions), dir)
+	return nil
+}
+
+func getBackupFile(dir string, kind string, name string) string {
+	return filepath.Join(dir, kind, name+".yaml")
+}
+
+func writeBackupFile(dir string, kind string, name string, data interface{}) error {
+	return writeRequisitionFile(getBackupFile(dir, kind, name), "yaml", data)
+}
+
+func readBackupFile(dir string, kind string, name string, target interface{}) error {
+	return readYAMLFile(getBackupFile(dir, kind, name), target)
+}
+
+func readYAMLFile(file string, target interface{}) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("Cannot parse %s: %w", file, err)
+	}
+	return nil
+}
+
+func readBackupManifest(dir string) (*backupManifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, backupManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &backupManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("Cannot parse the manifest of %s: %w", dir, err)
+	}
+	return manifest, nil
+}
+
+func writeBackupManifest(dir string, manifest *backupManifest) error {
+	return writeRequisitionFile(filepath.Join(dir, backupManifestFile), "yaml", manifest)
+}
+
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
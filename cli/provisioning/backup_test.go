@@ -0,0 +1,112 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	stamp := &model.Time{Time: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)}
+	posted := []string{}
+	fetched := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			data := make(map[string]string)
+			bytes, _ := ioutil.ReadAll(req.Body)
+			json.Unmarshal(bytes, &data)
+			posted = append(posted, req.URL.Path+" "+data["name"]+data["foreign-source"])
+			return
+		}
+		if strings.HasPrefix(req.URL.Path, "/rest/requisitions/") {
+			fetched = append(fetched, req.URL.Path)
+		}
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 3, ForeignSources: []string{"Routers", "Servers", "default"}})
+		case "/rest/requisitions/deployed/stats":
+			sendData(res, model.RequisitionsStats{Count: 3, ForeignSources: []model.RequisitionStats{
+				{Name: "Routers", Count: 1, ForeignIDs: []string{"r1"}, LastImport: stamp},
+				{Name: "Servers", Count: 0, ForeignIDs: []string{}, LastImport: stamp},
+				{Name: "default", Count: 0, ForeignIDs: []string{}},
+			}})
+		case "/rest/requisitions/default":
+			sendData(res, model.Requisition{Name: "default"})
+		case "/rest/requisitions/Routers":
+			sendData(res, model.Requisition{Name: "Routers", DateStamp: stamp, Nodes: []model.RequisitionNode{{ForeignID: "r1", NodeLabel: "router01"}}})
+		case "/rest/requisitions/Servers":
+			sendData(res, model.Requisition{Name: "Servers", DateStamp: stamp})
+		case "/rest/foreignSources/default", "/rest/foreignSources/Routers", "/rest/foreignSources/Servers":
+			sendData(res, model.ForeignSourceDef{Name: strings.TrimPrefix(req.URL.Path, "/rest/foreignSources/"), ScanInterval: "1d"})
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err = test.RunWithOutput(app, "table", "inv", "backup", "--dir", dir)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(logs.String(), "Requisition Routers saved with 1 nodes\n"))
+	assert.Assert(t, strings.HasSuffix(logs.String(), "(0 skipped)\n"))
+	for _, file := range []string{"manifest.yaml", "requisitions/Routers.yaml", "default-foreign-source.yaml", "foreign-sources/Servers.yaml", "requisitions/default.yaml", "foreign-sources/default.yaml"} {
+		assert.Assert(t, fileExists(filepath.Join(dir, file)), file)
+	}
+
+	// Simulate an interrupted backup
+	assert.NilError(t, os.Remove(filepath.Join(dir, "requisitions", "Servers.yaml")))
+	logs.Reset()
+	fetched = []string{}
+	_, err = test.RunWithOutput(app, "table", "inv", "backup", "--dir", dir)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(logs.String(), "Requisition Routers wasn't imported since the last backup, skipped\nRequisition Servers saved with 0 nodes\n"))
+	assert.Assert(t, strings.HasSuffix(logs.String(), "(1 skipped)\n"))
+	assert.DeepEqual(t, []string{"/rest/requisitions/deployed/stats", "/rest/requisitions/Servers", "/rest/requisitions/default"}, fetched)
+
+	_, err = test.RunWithOutput(app, "table", "inv", "restore", "--dir", dir, "--only", "Routers, Switches")
+	assert.ErrorContains(t, err, "Requisition Switches is not part of the backup")
+	assert.Equal(t, 0, len(posted))
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "inv", "restore", "--dir", dir, "--only", "Servers,Routers")
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(logs.String(), "WARNING"))
+	assert.DeepEqual(t, []string{
+		"/rest/foreignSources Servers",
+		"/rest/foreignSources Routers",
+		"/rest/requisitions Servers",
+		"/rest/requisitions Routers",
+	}, posted)
+
+	target := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	test.Client(app).URL = target
+	_, err = test.RunWithOutput(app, "table", "inv", "backup", "--dir", dir)
+	assert.ErrorContains(t, err, "has a backup of "+server.URL)
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "inv", "restore", "--dir", dir)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(logs.String(), "WARNING: The backup was taken from "+server.URL+", and it is restored to "+target+"\n"))
+	assert.Equal(t, 11, len(posted))
+	assert.Equal(t, "/rest/foreignSources default", posted[4])
+
+	_, err = test.RunWithOutput(app, "table", "inv", "restore", "--dir", filepath.Join(dir, "requisitions"))
+	assert.ErrorContains(t, err, "doesn't contain a backup")
+}
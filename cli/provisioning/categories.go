@@ -2,10 +2,12 @@ package provisioning
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
 )
 
 // CategoriesCliCommand the CLI command configuration for managing categories for requisitioned nodes
@@ -35,32 +37,156 @@ var CategoriesCliCommand = cli.Command{
 			ArgsUsage: "<foreignSource> <foreignId> <categoryName>",
 			Action:    deleteCategory,
 		},
+		{
+			Name:      "set",
+			Usage:     "Replaces all the categories of a given node",
+			ArgsUsage: "<foreignSource> <foreignId> <categoryName,...>",
+			Action:    setCategories,
+			Flags:     []cli.Flag{common.YesFlag},
+		},
+		{
+			Name:         "sync",
+			Usage:        "Replaces the categories of the nodes from a requisition whose label matches the rules from a YAML file",
+			ArgsUsage:    "<foreignSource> <yaml>",
+			Action:       syncCategories,
+			BashComplete: requisitionNameBashComplete,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External YAML file with a list of rules, each with a label glob and the desired categories (use '-' for STDIN Pipe)",
+				},
+				common.YesFlag,
+			},
+		},
 	},
 }
 
 func listCategories(c *cli.Context) error {
-	node, err := getReqAPI().GetNode(c.Args().Get(0), c.Args().Get(1))
+	node, err := getReqAPI(c).GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
-	if len(node.Categories) == 0 {
-		fmt.Println("There are no categories on the chosen node")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Category Name")
+	table := common.NewTable("There are no categories on the chosen node", "Category Name")
 	for _, cat := range node.Categories {
-		fmt.Fprintf(writer, "%s\n", cat.Name)
+		table.AddRow(cat.Name)
 	}
-	writer.Flush()
-	return nil
+	return common.Print(node.Categories, table)
 }
 
 func addCategory(c *cli.Context) error {
 	cat := model.RequisitionCategory{Name: c.Args().Get(2)}
-	return getReqAPI().SetCategory(c.Args().Get(0), c.Args().Get(1), cat)
+	return getReqAPI(c).SetCategory(c.Args().Get(0), c.Args().Get(1), cat)
 }
 
 func deleteCategory(c *cli.Context) error {
-	return getReqAPI().DeleteCategory(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	return getReqAPI(c).DeleteCategory(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+}
+
+func setCategories(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	if c.Args().Get(2) == "" {
+		return fmt.Errorf("Category list required")
+	}
+	node, err := getReqAPI(c).GetNode(foreignSource, foreignID)
+	if err != nil {
+		return err
+	}
+	removed, added := node.SetCategories(splitList(c.Args().Get(2)))
+	common.Log.Infof("Node %s: %s", foreignID, formatChanges(removed, added))
+	if len(removed) > 0 && !common.SkipConfirmation(c) && !common.DryRun {
+		return fmt.Errorf("Use --yes to confirm the removal of existing categories")
+	}
+	return common.Apply(node, func() error {
+		return getReqAPI(c).SetNode(foreignSource, *node)
+	})
+}
+
+// categoryRule the desired categories for the nodes whose label matches a glob
+type categoryRule struct {
+	Label      string   `yaml:"label"`
+	Categories []string `yaml:"categories"`
+}
+
+func syncCategories(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	data, err := common.ReadInput(c, 1)
+	if err != nil {
+		return err
+	}
+	rules := make([]categoryRule, 0)
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	matchers := make([]func(string) bool, len(rules))
+	for i, rule := range rules {
+		if rule.Label == "" {
+			return fmt.Errorf("Label glob required for rule %d", i)
+		}
+		if matchers[i], err = getMatcher(rule.Label, false); err != nil {
+			return err
+		}
+	}
+	requisition, err := getReqAPI(c).GetRequisition(foreignSource)
+	if err != nil {
+		return err
+	}
+	changed := make([]model.RequisitionNode, 0)
+	removals := false
+	for _, node := range requisition.Nodes {
+		for i, rule := range rules {
+			if !matchers[i](node.NodeLabel) {
+				continue
+			}
+			removed, added := node.SetCategories(rule.Categories)
+			if len(removed) > 0 || len(added) > 0 {
+				common.Log.Infof("Node %s: %s", node.ForeignID, formatChanges(removed, added))
+				if err := node.Validate(); err != nil {
+					return common.ValidationError(err)
+				}
+				changed = append(changed, node)
+				removals = removals || len(removed) > 0
+			}
+			break // The first matching rule wins
+		}
+	}
+	if len(changed) == 0 {
+		common.Log.Infof("All nodes have the desired categories")
+		return nil
+	}
+	if common.DryRun {
+		return nil
+	}
+	if removals && !common.SkipConfirmation(c) {
+		return fmt.Errorf("Use --yes to confirm the removal of existing categories")
+	}
+	for _, node := range changed {
+		if err := getReqAPI(c).SetNode(foreignSource, node); err != nil {
+			return fmt.Errorf("Cannot update node %s: %w", node.ForeignID, err)
+		}
+	}
+	return nil
+}
+
+// Splits a comma separated list, ignoring empty elements
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Describes the elements removed and added by a set based change
+func formatChanges(removed []string, added []string) string {
+	return "removed: " + formatNames(removed) + ", added: " + formatNames(added)
+}
+
+func formatNames(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, " ")
 }
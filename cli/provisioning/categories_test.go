@@ -1,8 +1,13 @@
 package provisioning
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/test"
 	"gotest.tools/assert"
 )
@@ -11,6 +16,7 @@ func TestListCategories(t *testing.T) {
 	var err error
 	app := test.CreateCli(CategoriesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "cat", "list"})
@@ -27,6 +33,7 @@ func TestAddCategory(t *testing.T) {
 	var err error
 	app := test.CreateCli(CategoriesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "cat", "add"})
@@ -46,6 +53,7 @@ func TestDeleteCategory(t *testing.T) {
 	var err error
 	app := test.CreateCli(CategoriesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "cat", "delete"})
@@ -60,3 +68,92 @@ func TestDeleteCategory(t *testing.T) {
 	err = app.Run([]string{app.Name, "cat", "delete", "Test", "n1", "Production"})
 	assert.NilError(t, err)
 }
+
+func createCategoriesTestServer(t *testing.T, updated map[string]model.RequisitionNode) *httptest.Server {
+	requisition := model.Requisition{Name: "Test", Nodes: []model.RequisitionNode{
+		{ForeignID: "srv-01", NodeLabel: "srv-01.example.com", Categories: []model.RequisitionCategory{{Name: "Servers"}, {Name: "Dev"}}},
+		{ForeignID: "srv-02", NodeLabel: "srv-02.example.com", Categories: []model.RequisitionCategory{{Name: "Servers"}, {Name: "Production"}}},
+		{ForeignID: "rtr-01", NodeLabel: "rtr-01.example.com"},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Test"}})
+		case "/rest/requisitions/Test":
+			sendData(res, requisition)
+		case "/rest/requisitions/Test/nodes/n1":
+			sendData(res, testNode)
+		case "/rest/requisitions/Test/nodes":
+			assert.Equal(t, http.MethodPost, req.Method)
+			node := model.RequisitionNode{}
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(bytes, &node))
+			updated[node.ForeignID] = node
+			res.WriteHeader(http.StatusAccepted)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestSetCategories(t *testing.T) {
+	app := test.CreateCli(CategoriesCliCommand)
+	updated := make(map[string]model.RequisitionNode)
+	server := createCategoriesTestServer(t, updated)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "cat", "set", "Test", "n1")
+	assert.Error(t, err, "Category list required")
+
+	_, err = test.RunWithOutput(app, "table", "cat", "set", "Test", "n1", "Production,Web")
+	assert.Error(t, err, "Use --yes to confirm the removal of existing categories")
+	assert.Equal(t, "Node n1: removed: Server, added: Production Web\n", logs.String())
+	assert.Equal(t, 0, len(updated))
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "cat", "set", "Test", "n1", "Server,Web,Server")
+	assert.NilError(t, err)
+	assert.Equal(t, "Node n1: removed: none, added: Web\n", logs.String())
+	assert.Equal(t, 2, len(updated["n1"].Categories))
+
+	_, err = test.RunWithOutput(app, "table", "cat", "set", "--yes", "Test", "n1", "Production")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []model.RequisitionCategory{{Name: "Production"}}, updated["n1"].Categories)
+}
+
+func TestSyncCategories(t *testing.T) {
+	app := test.CreateCli(CategoriesCliCommand)
+	updated := make(map[string]model.RequisitionNode)
+	server := createCategoriesTestServer(t, updated)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "cat", "sync", "--", "Test", "- label: ''")
+	assert.ErrorContains(t, err, "Label glob required")
+
+	rules := `
+- label: SRV-*
+  categories: [Servers, Production]
+- label: '*'
+  categories: [Network]
+`
+	_, err = test.RunWithOutput(app, "table", "cat", "sync", "Test", rules)
+	assert.Error(t, err, "Use --yes to confirm the removal of existing categories")
+	assert.Equal(t, `Node srv-01: removed: Dev, added: Production
+Node rtr-01: removed: none, added: Network
+`, logs.String())
+	assert.Equal(t, 0, len(updated))
+
+	_, err = test.RunWithOutput(app, "table", "cat", "sync", "--yes", "Test", rules)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(updated))
+	assert.DeepEqual(t, []model.RequisitionCategory{{Name: "Servers"}, {Name: "Production"}}, updated["srv-01"].Categories)
+	assert.DeepEqual(t, []model.RequisitionCategory{{Name: "Network"}}, updated["rtr-01"].Categories)
+}
@@ -7,8 +7,6 @@ import (
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/urfave/cli"
-
-	"gopkg.in/yaml.v2"
 )
 
 // DetectorsCliCommand the CLI command configuration for managing foreign source detectors
@@ -47,15 +45,17 @@ var DetectorsCliCommand = cli.Command{
 		},
 		{
 			Name:         "set",
+			Aliases:      []string{"add"},
 			Usage:        "Adds or update a detector for a given foreign source definition, overriding any existing content",
-			ArgsUsage:    "<foreignSource> <className> <detectorName>",
+			ArgsUsage:    "<foreignSource> <detectorName> <className>",
 			Action:       setDetector,
 			BashComplete: detectorClassBashComplete,
 			Flags: []cli.Flag{
 				cli.StringSliceFlag{
-					Name:  "parameter, p",
-					Usage: "A detector parameter (e.x. -p 'retry=2')",
+					Name:  "parameter, param, p",
+					Usage: "A detector parameter (e.x. -p 'retries=2'), verified against the parameters supported by the detector class unless --skip-validation is used",
 				},
+				skipValidationFlag,
 			},
 		},
 		{
@@ -68,6 +68,7 @@ var DetectorsCliCommand = cli.Command{
 					Name:  "file, f",
 					Usage: "External YAML file (use '-' for STDIN Pipe)",
 				},
+				skipValidationFlag,
 			},
 			ArgsUsage: "<foreignSource> <yaml>",
 		},
@@ -83,66 +84,61 @@ var DetectorsCliCommand = cli.Command{
 }
 
 func listDetectors(c *cli.Context) error {
-	fsDef, err := getFsAPI().GetForeignSourceDef(c.Args().Get(0))
+	fsDef, err := getFsAPI(c).GetForeignSourceDef(c.Args().Get(0))
 	if err != nil {
 		return err
 	}
-	if len(fsDef.Detectors) == 0 {
-		fmt.Println("There are no detectors on the chosen foreign source definition")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Detector Name\tDetector Class")
+	table := common.NewTable("There are no detectors on the chosen foreign source definition", "Detector Name", "Detector Class")
 	for _, detector := range fsDef.Detectors {
-		fmt.Fprintf(writer, "%s\t%s\n", detector.Name, detector.Class)
+		table.AddRow(detector.Name, detector.Class)
 	}
-	writer.Flush()
-	return nil
+	return common.Print(fsDef.Detectors, table)
 }
 
 func enumerateDetectorClasses(c *cli.Context) error {
-	detectors, err := getUtilsAPI().GetAvailableDetectors()
+	detectors, err := getUtilsAPI(c).GetAvailableDetectors()
 	if err != nil {
 		return err
 	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Detector Name\tDetector Class")
+	table := common.NewTable("", "Detector Name", "Detector Class")
 	for _, plugin := range detectors.Plugins {
-		fmt.Fprintf(writer, "%s\t%s\n", plugin.Name, plugin.Class)
+		table.AddRow(plugin.Name, plugin.Class)
 	}
-	writer.Flush()
-	return nil
+	return common.Print(detectors.Plugins, table)
 }
 
 func describeDetectorClass(c *cli.Context) error {
-	plugin, err := getFsAPI().GetDetectorConfig(c.Args().Get(0))
+	plugin, err := getFsAPI(c).GetDetectorConfig(c.Args().Get(0))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(plugin)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(plugin, nil)
 }
 
 func getDetector(c *cli.Context) error {
-	detector, err := getFsAPI().GetDetector(c.Args().Get(0), c.Args().Get(1))
+	detector, err := getFsAPI(c).GetDetector(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(detector)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(detector, nil)
 }
 
 func setDetector(c *cli.Context) error {
 	detector := model.Detector{Name: c.Args().Get(1), Class: c.Args().Get(2)}
 	params := c.StringSlice("parameter")
 	for _, p := range params {
-		data := strings.Split(p, "=")
+		data := strings.SplitN(p, "=", 2)
+		if len(data) != 2 || data[0] == "" {
+			return fmt.Errorf("Invalid parameter %s, expected key=value", p)
+		}
 		param := model.Parameter{Key: data[0], Value: data[1]}
 		detector.Parameters = append(detector.Parameters, param)
 	}
-	return getFsAPI().SetDetector(c.Args().Get(0), detector)
+	if err := getValidatingFsAPI(c).SetDetector(c.Args().Get(0), detector); err != nil {
+		return err
+	}
+	warnUnknownService(c, c.Args().Get(0), detector.Name)
+	return nil
 }
 
 func applyDetector(c *cli.Context) error {
@@ -150,27 +146,47 @@ func applyDetector(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	detector := model.Detector{}
-	err = yaml.Unmarshal(data, &detector)
-	if err != nil {
-		return err
+	detector := &model.Detector{}
+	return common.ApplyYAML(data, detector, func() error {
+		if err := getValidatingFsAPI(c).SetDetector(c.Args().Get(0), *detector); err != nil {
+			return err
+		}
+		warnUnknownService(c, c.Args().Get(0), detector.Name)
+		return nil
+	})
+}
+
+// The detector name is the service added to the interfaces, which is only monitored when the pollers know about it;
+// unlike invalid parameters, this is just a warning, as the poller configuration might be updated later
+func warnUnknownService(c *cli.Context, foreignSource string, name string) {
+	if c.Bool("skip-validation") {
+		return
+	}
+	services, err := getUtilsAPI(c).GetAvailableServices(foreignSource)
+	if err != nil || len(services.Element) == 0 {
+		return
+	}
+	for _, svc := range services.Element {
+		if svc == name {
+			return
+		}
 	}
-	return getFsAPI().SetDetector(c.Args().Get(0), detector)
+	common.Log.Warnf("Service %s is not defined on the poller configuration, so it won't be monitored%s", name, model.DidYouMean(name, services.Element))
 }
 
 func deleteDetector(c *cli.Context) error {
-	return getFsAPI().DeleteDetector(c.Args().Get(0), c.Args().Get(1))
+	return getFsAPI(c).DeleteDetector(c.Args().Get(0), c.Args().Get(1))
 }
 
 func detectorBashComplete(c *cli.Context) {
 	requisitionNameBashComplete(c)
 	if c.NArg() == 1 {
-		fs, err := getFsAPI().GetForeignSourceDef(c.Args().Get(0))
+		fs, err := getFsAPI(c).GetForeignSourceDef(c.Args().Get(0))
 		if err != nil {
 			return
 		}
 		for _, d := range fs.Detectors {
-			fmt.Println(zshNormalize(d.Name))
+			common.PrintCompletions(d.Name)
 		}
 	}
 }
@@ -178,12 +194,12 @@ func detectorBashComplete(c *cli.Context) {
 func detectorClassBashComplete(c *cli.Context) {
 	requisitionNameBashComplete(c)
 	if c.NArg() == 1 {
-		cfg, err := getUtilsAPI().GetAvailableDetectors()
+		cfg, err := getUtilsAPI(c).GetAvailableDetectors()
 		if err != nil {
 			return
 		}
 		for _, p := range cfg.Plugins {
-			fmt.Println(zshNormalize(p.Class))
+			common.PrintCompletions(p.Class)
 		}
 	}
 }
@@ -1,8 +1,13 @@
 package provisioning
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 
+	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/test"
 	"gopkg.in/yaml.v2"
@@ -13,6 +18,7 @@ func TestEnumDetectors(t *testing.T) {
 	var err error
 	app := test.CreateCli(DetectorsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "detector", "enum"})
@@ -23,6 +29,7 @@ func TestDescribeDetector(t *testing.T) {
 	var err error
 	app := test.CreateCli(DetectorsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "detector", "desc"})
@@ -36,6 +43,7 @@ func TestListDetectors(t *testing.T) {
 	var err error
 	app := test.CreateCli(DetectorsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "detector", "list"})
@@ -49,6 +57,7 @@ func TestGetDetector(t *testing.T) {
 	var err error
 	app := test.CreateCli(DetectorsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "detector", "get"})
@@ -65,6 +74,7 @@ func TestDeleteDetector(t *testing.T) {
 	var err error
 	app := test.CreateCli(DetectorsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "detector", "del"})
@@ -81,6 +91,7 @@ func TestApplyDetector(t *testing.T) {
 	var err error
 	app := test.CreateCli(DetectorsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "detector", "apply"})
@@ -110,6 +121,7 @@ func TestSetDetector(t *testing.T) {
 	var err error
 	app := test.CreateCli(DetectorsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "detector", "set"})
@@ -123,4 +135,55 @@ func TestSetDetector(t *testing.T) {
 
 	err = app.Run([]string{app.Name, "detector", "set", "Test", "ICMP", "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"})
 	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "detector", "add", "Test", "ICMP", "org.opennms.example.CustomIcmpDetector"})
+	assert.Error(t, err, "Cannot find detector with class org.opennms.example.CustomIcmpDetector")
+
+	err = app.Run([]string{app.Name, "detector", "add", "--skip-validation", "Test", "ICMP", "org.opennms.example.CustomIcmpDetector"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "detector", "add", "--param", "retires=2", "Test", "ICMP", "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"})
+	assert.Error(t, err, "Invalid parameter retires for org.opennms.netmgt.provision.detector.icmp.IcmpDetector; did you mean 'retries'?")
+
+	err = app.Run([]string{app.Name, "detector", "add", "--param", "retires=2", "--skip-validation", "Test", "ICMP", "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "detector", "add", "-p", "retries", "Test", "ICMP", "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"})
+	assert.Error(t, err, "Invalid parameter retries, expected key=value")
+}
+
+func TestSetDetectorUnknownService(t *testing.T) {
+	app := test.CreateCli(DetectorsCliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Test"}})
+		case "/rest/foreignSourcesConfig/detectors":
+			res.Write([]byte(test.DetectorsJSON))
+		case "/rest/foreignSourcesConfig/services/Test":
+			sendData(res, model.ElementList{Count: 3, Element: []string{"ICMP", "SNMP", "HTTP"}})
+		case "/rest/foreignSources/Test/detectors":
+			assert.Equal(t, http.MethodPost, req.Method)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	test.Client(app).URL = server.URL
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	err := app.Run([]string{app.Name, "detector", "add", "-p", "retries=2", "Test", "ICMP", "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"})
+	assert.NilError(t, err)
+	assert.Equal(t, "", logs.String())
+
+	err = app.Run([]string{app.Name, "detector", "add", "Test", "ICNP", "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"})
+	assert.NilError(t, err)
+	assert.Equal(t, "WARNING: Service ICNP is not defined on the poller configuration, so it won't be monitored; did you mean 'ICMP'?\n", logs.String())
+
+	logs.Reset()
+	err = app.Run([]string{app.Name, "detector", "add", "--skip-validation", "Test", "ICNP", "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"})
+	assert.NilError(t, err)
+	assert.Equal(t, "", logs.String())
 }
@@ -0,0 +1,177 @@
+package provisioning
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// FindCliCommand the CLI command configuration for finding nodes across all requisitions
+var FindCliCommand = cli.Command{
+	Name:      "find",
+	Usage:     "Find nodes on all requisitions by foreign ID, label or IP address",
+	ArgsUsage: "<pattern>",
+	Category:  "Requisitions",
+	Action:    findNodes,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "regex, r",
+			Usage: "Treat the pattern as a regular expression instead of a case insensitive glob (e.x. 'srv-*')",
+		},
+		cli.BoolFlag{
+			Name:  "deployed",
+			Usage: "Search the deployed requisitions (the content of the last import) instead of the pending ones",
+		},
+		cli.IntFlag{
+			Name:  "concurrency, n",
+			Value: 4,
+			Usage: "Number of requisitions fetched in parallel",
+		},
+	},
+}
+
+// nodeMatch a node that matches the search pattern
+type nodeMatch struct {
+	Requisition string   `json:"requisition" yaml:"requisition"`
+	ForeignID   string   `json:"foreign-id" yaml:"foreignID"`
+	NodeLabel   string   `json:"node-label" yaml:"nodeLabel"`
+	Matches     []string `json:"matches" yaml:"matches"`
+}
+
+func findNodes(c *cli.Context) error {
+	pattern := c.Args().First()
+	if pattern == "" {
+		return fmt.Errorf("Search pattern required")
+	}
+	match, err := getMatcher(pattern, c.Bool("regex"))
+	if err != nil {
+		return err
+	}
+	results := make([]nodeMatch, 0)
+	var failures map[string]error
+	total := 0
+	if c.Bool("deployed") {
+		requisitions, err := getReqAPI(c).GetDeployedRequisitions()
+		if err != nil {
+			return err
+		}
+		for _, req := range requisitions {
+			for _, node := range req.Nodes {
+				if matches := matchNode(node, match); len(matches) > 0 {
+					results = append(results, nodeMatch{req.Name, node.ForeignID, node.NodeLabel, matches})
+				}
+			}
+		}
+		total = len(requisitions)
+	} else {
+		list, err := getUtilsAPI(c).GetRequisitionNames()
+		if err != nil {
+			return err
+		}
+		results, failures = searchRequisitions(c, list.ForeignSources, match, c.Int("concurrency"))
+		total = len(list.ForeignSources)
+	}
+	table := common.NewTable("There are no nodes matching "+pattern, "Requisition", "Foreign ID", "Label", "Matching Field")
+	for _, m := range results {
+		table.AddRow(m.Requisition, m.ForeignID, m.NodeLabel, strings.Join(m.Matches, ", "))
+	}
+	if err := common.Print(results, table); err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(failures))
+	for name := range failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		common.Log.Warnf("Cannot search requisition %s: %s", name, failures[name])
+	}
+	return fmt.Errorf("Cannot search %d of %d requisitions", len(failures), total)
+}
+
+// Searches the requisitions using a pool of workers, streaming their nodes so only the matches are kept in memory;
+// the matches are returned in the given order, and the errors are reported per requisition without aborting the rest
+func searchRequisitions(c *cli.Context, foreignSources []string, match func(string) bool, concurrency int) ([]nodeMatch, map[string]error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	jobs := make(chan int, len(foreignSources))
+	for i := range foreignSources {
+		jobs <- i
+	}
+	close(jobs)
+	found := make([][]nodeMatch, len(foreignSources))
+	errors := make([]error, len(foreignSources))
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				name := foreignSources[i]
+				_, errors[i] = getReqAPI(c).ForEachNode(name, func(node model.RequisitionNode) error {
+					if matches := matchNode(node, match); len(matches) > 0 {
+						found[i] = append(found[i], nodeMatch{name, node.ForeignID, node.NodeLabel, matches})
+					}
+					return nil
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	results := make([]nodeMatch, 0)
+	failures := make(map[string]error)
+	for i, fs := range foreignSources {
+		if errors[i] != nil {
+			failures[fs] = errors[i]
+		} else {
+			results = append(results, found[i]...)
+		}
+	}
+	return results, failures
+}
+
+// Returns the fields of the node that match the pattern
+func matchNode(node model.RequisitionNode, match func(string) bool) []string {
+	matches := make([]string, 0)
+	if match(node.ForeignID) {
+		matches = append(matches, "foreign ID")
+	}
+	if match(node.NodeLabel) {
+		matches = append(matches, "label")
+	}
+	for _, intf := range node.Interfaces {
+		if match(intf.IPAddress) {
+			matches = append(matches, "interface "+intf.IPAddress)
+		}
+	}
+	return matches
+}
+
+func getMatcher(pattern string, isRegex bool) (func(string) bool, error) {
+	if isRegex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid regular expression %s: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+	pattern = strings.ToLower(pattern)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("Invalid pattern %s: %w", pattern, err)
+	}
+	return func(value string) bool {
+		ok, _ := filepath.Match(pattern, strings.ToLower(value))
+		return ok
+	}, nil
+}
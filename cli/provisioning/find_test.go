@@ -0,0 +1,78 @@
+package provisioning
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func createFindTestServer(t *testing.T) *httptest.Server {
+	routers := model.Requisition{Name: "Routers", Nodes: []model.RequisitionNode{
+		{ForeignID: "rtr-01", NodeLabel: "Core Router", Interfaces: []model.RequisitionInterface{{IPAddress: "10.0.0.1"}}},
+		{ForeignID: "rtr-02", NodeLabel: "Edge Router", Interfaces: []model.RequisitionInterface{{IPAddress: "10.0.1.1"}, {IPAddress: "192.168.0.1"}}},
+	}}
+	servers := model.Requisition{Name: "Servers", Nodes: []model.RequisitionNode{
+		{ForeignID: "srv-01", NodeLabel: "srv-01", Interfaces: []model.RequisitionInterface{{IPAddress: "10.0.0.10"}}},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 3, ForeignSources: []string{"Routers", "Broken", "Servers"}})
+		case "/rest/requisitions/Routers":
+			sendData(res, routers)
+		case "/rest/requisitions/Servers":
+			sendData(res, servers)
+		case "/rest/requisitions/deployed":
+			sendData(res, model.RequisitionCollection{Count: 1, Requisitions: []model.Requisition{servers}})
+		default:
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	return server
+}
+
+func TestFindNodes(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createFindTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	_, err := test.RunWithOutput(app, "table", "inv", "find")
+	assert.Error(t, err, "Search pattern required")
+
+	_, err = test.RunWithOutput(app, "table", "inv", "find", "-r", "rtr-(")
+	assert.ErrorContains(t, err, "Invalid regular expression rtr-(")
+
+	logs, restore := test.CaptureLogs()
+	defer restore()
+	output, err := test.RunWithOutput(app, "table", "inv", "find", "10.0.0.*")
+	assert.Error(t, err, "Cannot search 1 of 3 requisitions")
+	assert.Assert(t, strings.HasPrefix(logs.String(), "WARNING: Cannot search requisition Broken: "), logs.String())
+	assert.Equal(t, `Requisition  Foreign ID  Label        Matching Field
+Routers      rtr-01      Core Router  interface 10.0.0.1
+Servers      srv-01      srv-01       interface 10.0.0.10
+`, output)
+
+	output, _ = test.RunWithOutput(app, "table", "inv", "find", "*ROUTER")
+	assert.Equal(t, `Requisition  Foreign ID  Label        Matching Field
+Routers      rtr-01      Core Router  label
+Routers      rtr-02      Edge Router  label
+`, output)
+
+	output, _ = test.RunWithOutput(app, "jsonpath=$[*].matches", "inv", "find", "--regex", "^(srv|192)")
+	assert.Equal(t, "[\"interface 192.168.0.1\"]\n[\"foreign ID\",\"label\"]\n", output)
+
+	output, err = test.RunWithOutput(app, "table", "inv", "find", "--deployed", "rtr-*")
+	assert.NilError(t, err)
+	assert.Equal(t, "There are no nodes matching rtr-*\n", output)
+
+	output, err = test.RunWithOutput(app, "jsonpath=$[*].requisition", "inv", "find", "--deployed", "srv-*")
+	assert.NilError(t, err)
+	assert.Equal(t, "Servers\n", output)
+}
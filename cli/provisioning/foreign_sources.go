@@ -3,11 +3,11 @@ package provisioning
 import (
 	"encoding/json"
 	"encoding/xml"
-	"fmt"
 	"strings"
 
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/policy"
 	"github.com/urfave/cli"
 
 	"gopkg.in/yaml.v2"
@@ -30,6 +30,7 @@ var ForeignSourcesCliCommand = cli.Command{
 		{
 			Name:         "interval",
 			ShortName:    "int",
+			Aliases:      []string{"set-scan-interval"},
 			Usage:        "Sets the scan interval for a given requisition",
 			Action:       setScanInterval,
 			BashComplete: requisitionNameBashComplete,
@@ -41,17 +42,15 @@ var ForeignSourcesCliCommand = cli.Command{
 			Action: applyForeignSource,
 			Flags: []cli.Flag{
 				cli.GenericFlag{
-					Name: "format, x",
-					Value: &model.EnumValue{
-						Enum:    Formats,
-						Default: "yaml",
-					},
+					Name:  "format, x",
+					Value: model.NewEnum(Formats...).WithDefault("yaml"),
 					Usage: "File Format: " + strings.Join(Formats, ", "),
 				},
 				cli.StringFlag{
 					Name:  "file, f",
 					Usage: "External file (use '-' for STDIN Pipe)",
 				},
+				skipValidationFlag,
 			},
 			ArgsUsage: "<content>",
 		},
@@ -62,20 +61,32 @@ var ForeignSourcesCliCommand = cli.Command{
 			Action:    validateForeignSource,
 			Flags: []cli.Flag{
 				cli.GenericFlag{
-					Name: "format, x",
-					Value: &model.EnumValue{
-						Enum:    Formats,
-						Default: "xml",
-					},
+					Name:  "format, x",
+					Value: model.NewEnum(Formats...).WithDefault("xml"),
 					Usage: "File Format: " + strings.Join(Formats, ", "),
 				},
 				cli.StringFlag{
 					Name:  "file, f",
 					Usage: "External YAML file (use '-' for STDIN Pipe)",
 				},
+				skipValidationFlag,
 			},
 			ArgsUsage: "<content>",
 		},
+		{
+			Name:         "simulate",
+			ShortName:    "sim",
+			Usage:        "Previews the categories and interface decisions of the policies of a foreign source definition on a set of nodes, without importing them",
+			Action:       simulatePolicies,
+			BashComplete: requisitionNameBashComplete,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External YAML file with the nodes and their discovered attributes (use '-' for STDIN Pipe)",
+				},
+			},
+			ArgsUsage: "<name> <yaml>",
+		},
 		{
 			Name:         "delete",
 			ShortName:    "del",
@@ -88,17 +99,15 @@ var ForeignSourcesCliCommand = cli.Command{
 }
 
 func showForeignSource(c *cli.Context) error {
-	fsDef, err := getFsAPI().GetForeignSourceDef(c.Args().Get(0))
+	fsDef, err := getFsAPI(c).GetForeignSourceDef(c.Args().Get(0))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(fsDef)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(fsDef, nil)
 }
 
 func setScanInterval(c *cli.Context) error {
-	return getFsAPI().SetScanInterval(c.Args().Get(0), c.Args().Get(1))
+	return getFsAPI(c).SetScanInterval(c.Args().Get(0), c.Args().Get(1))
 }
 
 func applyForeignSource(c *cli.Context) error {
@@ -106,7 +115,9 @@ func applyForeignSource(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	return getFsAPI().SetForeignSourceDef(*fsDef)
+	return common.Apply(fsDef, func() error {
+		return getFsAPI(c).SetForeignSourceDef(*fsDef)
+	})
 }
 
 func validateForeignSource(c *cli.Context) error {
@@ -114,12 +125,42 @@ func validateForeignSource(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Foreign Source %s is valid!\n", fsDef.Name)
+	common.Log.Infof("Foreign Source %s is valid!", fsDef.Name)
 	return nil
 }
 
+func simulatePolicies(c *cli.Context) error {
+	data, err := common.ReadInput(c, 1)
+	if err != nil {
+		return err
+	}
+	nodes := &policy.NodeList{}
+	if err := yaml.Unmarshal(data, nodes); err != nil {
+		return err
+	}
+	if err := nodes.Validate(); err != nil {
+		return common.ValidationError(err)
+	}
+	fsDef, err := getFsAPI(c).GetForeignSourceDef(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	simulation, err := policy.Simulate(*fsDef, nodes.Nodes)
+	if err != nil {
+		return err
+	}
+	for _, skipped := range simulation.NotSimulated {
+		common.Log.Warnf("Policy %s (%s) not simulated: %s", skipped.Policy, skipped.Class, skipped.Reason)
+	}
+	table := common.NewTable("", "Foreign ID", "Target", "Policy", "Decision")
+	for _, d := range simulation.Decisions {
+		table.AddRow(d.ForeignID, d.Target, d.Policy, d.Decision)
+	}
+	return common.Print(simulation, table)
+}
+
 func deleteForeignSource(c *cli.Context) error {
-	return getFsAPI().DeleteForeignSourceDef(c.Args().Get(0))
+	return getFsAPI(c).DeleteForeignSourceDef(c.Args().Get(0))
 }
 
 func parseForeignSourceDefinition(c *cli.Context) (*model.ForeignSourceDef, error) {
@@ -139,5 +180,8 @@ func parseForeignSourceDefinition(c *cli.Context) (*model.ForeignSourceDef, erro
 	if err != nil {
 		return fsDef, err
 	}
-	return fsDef, getFsAPI().IsForeignSourceValid(*fsDef)
+	if common.DryRun {
+		return fsDef, common.ValidationError(fsDef.Validate())
+	}
+	return fsDef, common.ValidationError(getValidatingFsAPI(c).IsForeignSourceValid(*fsDef))
 }
@@ -1,9 +1,12 @@
 package provisioning
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/policy"
 	"github.com/OpenNMS/onmsctl/test"
 	"gopkg.in/yaml.v2"
 	"gotest.tools/assert"
@@ -13,6 +16,7 @@ func TestGetForeignSource(t *testing.T) {
 	var err error
 	app := test.CreateCli(ForeignSourcesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "fs", "get"})
@@ -26,6 +30,7 @@ func TestSetScanInterval(t *testing.T) {
 	var err error
 	app := test.CreateCli(ForeignSourcesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "fs", "int"})
@@ -45,6 +50,7 @@ func TestDeleteForeignSource(t *testing.T) {
 	var err error
 	app := test.CreateCli(ForeignSourcesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "fs", "del"})
@@ -58,6 +64,7 @@ func TestApplyForeignSource(t *testing.T) {
 	var err error
 	app := test.CreateCli(ForeignSourcesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "fs", "apply"})
@@ -97,3 +104,36 @@ func TestApplyForeignSource(t *testing.T) {
 	err = app.Run([]string{app.Name, "fs", "apply", string(fsYaml)})
 	assert.NilError(t, err)
 }
+
+func TestSimulatePolicies(t *testing.T) {
+	app := test.CreateCli(ForeignSourcesCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	_, err := test.RunWithOutput(app, "table", "fs", "simulate", "Test")
+	assert.Error(t, err, "Content cannot be empty")
+
+	_, err = test.RunWithOutput(app, "table", "fs", "simulate", "Test", "nodes: []")
+	assert.Error(t, err, "There are no nodes to simulate")
+
+	nodes := `
+nodes:
+- foreignId: srv01
+  sysObjectId: .1.3.6.1.4.1.8072.3.2.10
+- foreignId: srv02
+`
+	output, err := test.RunWithOutput(app, "table", "fs", "simulate", "Test", nodes)
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 3, len(lines), output)
+	assert.Assert(t, strings.HasPrefix(lines[0], "Foreign ID"), output)
+	assert.Equal(t, "srv01       node    Production  category Production", lines[1])
+
+	output, err = test.RunWithOutput(app, "json", "fs", "simulate", "Test", nodes)
+	assert.NilError(t, err)
+	simulation := &policy.Simulation{}
+	assert.NilError(t, json.Unmarshal([]byte(output), simulation))
+	assert.Equal(t, 2, len(simulation.Decisions))
+	assert.Equal(t, "srv02", simulation.Decisions[1].ForeignID)
+}
@@ -2,13 +2,12 @@ package provisioning
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/urfave/cli"
-
-	"gopkg.in/yaml.v2"
 )
 
 // InterfacesCliCommand the CLI command configuration for managing IP interfaces on requisitioned nodes
@@ -38,31 +37,65 @@ var InterfacesCliCommand = cli.Command{
 			Usage:     "Adds or update an IP interface from a given node",
 			ArgsUsage: "<foreignSource> <foreignId> <ipAddress|fqdn>",
 			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "cidr, c",
+					Usage: "Adds an IP interface for each address of a CIDR block (e.x. 10.0.0.0/29), instead of a single IP address",
+				},
+				cli.IntFlag{
+					Name:  "max-interfaces",
+					Value: model.DefaultMaxExpandedInterfaces,
+					Usage: "Maximum number of IP interfaces that can be generated from a CIDR block",
+				},
 				cli.StringFlag{
 					Name:  "description, d",
 					Usage: "IP Interface Description",
 				},
 				cli.GenericFlag{
-					Name: "snmpPrimary, p",
-					Value: &model.EnumValue{
-						Enum:    []string{"P", "N", "S"},
-						Default: "N",
-					},
+					Name:  "snmp-primary, snmpPrimary, p",
+					Value: model.NewEnum("P", "S", "N").WithDefault("N"),
 					Usage: "Primary Interface Flag: P (primary), S (secondary), N (Not Elegible)",
 				},
-				cli.IntFlag{
+				cli.GenericFlag{
 					Name:  "status, s",
-					Value: 1,
+					Value: model.NewEnum("1", "3").WithDefault("1"),
 					Usage: "Interface Status: 1 for managed, 3 for unmanaged (yes, I know)",
 				},
 				cli.StringSliceFlag{
-					Name:  "metaData, m",
-					Usage: "A meta-data entry (e.x. --metaData 'foo=bar')",
+					Name:  "service",
+					Usage: "A monitored service to add to the interface (e.x. --service ICMP --service SNMP)",
+				},
+				cli.BoolFlag{
+					Name:  "replace-services",
+					Usage: "Replace the services of an existing interface with the ones passed through --service, instead of adding them",
+				},
+				cli.StringSliceFlag{
+					Name:  "meta, metaData, m",
+					Usage: "A meta-data entry (e.x. --meta 'foo=bar')",
 				},
 			},
 			Action:       setInterface,
 			BashComplete: foreignIDBashComplete,
 		},
+		{
+			Name:      "set-ip",
+			Usage:     "Changes the IP address of an interface, keeping its services, meta-data and flags",
+			ArgsUsage: "<foreignSource> <foreignId> <ipAddress> <newIpAddress|fqdn>",
+			Description: "Re-addresses an interface without deleting it. With --swap-with, the interface exchanges its IP address and primary flag\n" +
+				"   with another interface of the node instead, so the services and meta-data of both interfaces move at once.\n" +
+				"   It fails when an address is already used by another node of the requisition, unless --force is used.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "swap-with",
+					Usage: "The IP address of another interface of the node to exchange the address and primary flag with, instead of a new IP address",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Change the address even when another node of the requisition has an interface with it",
+				},
+			},
+			Action:       setInterfaceAddress,
+			BashComplete: ipAddressBashComplete,
+		},
 		{
 			Name:         "apply",
 			Usage:        "Creates or updates an IP interface on a given node from a external YAML file, overriding any existing content",
@@ -76,6 +109,7 @@ var InterfacesCliCommand = cli.Command{
 			Usage:        "Deletes an IP interface from a given node",
 			ArgsUsage:    "<foreignSource> <foreignId> <ipAddress>",
 			Action:       deleteInterface,
+			Flags:        []cli.Flag{common.YesFlag},
 			BashComplete: ipAddressBashComplete,
 		},
 		{
@@ -110,96 +144,243 @@ var InterfacesCliCommand = cli.Command{
 }
 
 func listInterfaces(c *cli.Context) error {
-	node, err := getReqAPI().GetNode(c.Args().Get(0), c.Args().Get(1))
+	node, err := getReqAPI(c).GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
-	if len(node.Interfaces) == 0 {
-		fmt.Println("There are no IP interfaces on the chosen node")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "IP Address\tDescription\tSNMP Primary\tServices")
+	table := common.NewTable("There are no IP interfaces on the chosen node", "IP Address", "Description", "SNMP Primary", "Services")
 	for _, intf := range node.Interfaces {
 		desc := intf.Description
 		if desc == "" {
 			desc = "N/A"
 		}
-		fmt.Fprintf(writer, "%s\t%s\t%s\t%d\n", intf.IPAddress, desc, intf.SnmpPrimary, len(intf.Services))
+		table.AddRow(intf.IPAddress, desc, intf.SnmpPrimary, len(intf.Services))
 	}
-	writer.Flush()
-	return nil
+	return common.Print(node.Interfaces, table)
 }
 
 func showInterface(c *cli.Context) error {
-	intf, err := getReqAPI().GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	intf, err := getReqAPI(c).GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(&intf)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(intf, nil)
 }
 
 func setInterface(c *cli.Context) error {
-	intf := model.RequisitionInterface{
-		IPAddress:   c.Args().Get(2),
-		Description: c.String("description"),
-		SnmpPrimary: c.String("snmpPrimary"),
-		Status:      c.Int("status"),
+	if c.String("cidr") != "" {
+		return setInterfacesFromCIDR(c)
 	}
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	api := getReqAPI(c)
+	node, err := api.GetNode(foreignSource, foreignID)
+	if err != nil {
+		return err
+	}
+	intf := model.RequisitionInterface{IPAddress: c.Args().Get(2)}
+	if err := intf.Validate(); err != nil { // Translates a FQDN before looking for the interface on the node
+		return err
+	}
+	current := node.GetInterface(intf.IPAddress)
+	if current != nil {
+		intf = *current
+	}
+	if err := applyInterfaceFlags(c, &intf, current != nil); err != nil {
+		return err
+	}
+	// Validating the node catches the errors that involve other interfaces (e.x. a second primary interface)
+	if err := mergeNodeInterface(node, intf); err != nil {
+		return err
+	}
+	if err := node.Validate(); err != nil {
+		return common.ValidationError(err)
+	}
+	return api.SetInterface(foreignSource, foreignID, *node.GetInterface(intf.IPAddress))
+}
+
+// Sets the content of the interface from the flags of intf set; on existing interfaces, only the flags used explicitly are applied,
+// and the services are added to the existing ones, unless --replace-services is used
+func applyInterfaceFlags(c *cli.Context, intf *model.RequisitionInterface, existing bool) error {
+	if !existing || c.IsSet("description") {
+		intf.Description = c.String("description")
+	}
+	if !existing || c.IsSet("snmp-primary") {
+		intf.SnmpPrimary = common.EnumFlag(c, "snmp-primary")
+	}
+	if !existing || c.IsSet("status") {
+		intf.Status, _ = strconv.Atoi(common.EnumFlag(c, "status")) // The enum only accepts numbers
+	}
+	if c.Bool("replace-services") {
+		intf.Services = nil
+	}
+	for _, name := range c.StringSlice("service") {
+		if intf.GetService(name) == nil {
+			intf.AddService(&model.RequisitionMonitoredService{Name: name})
+		}
+	}
+	return mergeInterfaceMetaData(c, intf)
+}
 
-	api := getReqAPI()
-	current, err := api.GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+func setInterfacesFromCIDR(c *cli.Context) error {
+	if c.Args().Get(2) != "" {
+		return fmt.Errorf("Cannot specify an IP address and a CIDR block at the same time")
+	}
+	addresses, err := model.ExpandCIDR(c.String("cidr"), c.Int("max-interfaces"))
 	if err != nil {
-		mergeInterfaceMetaData(c, &intf)
-		return api.SetInterface(c.Args().Get(0), c.Args().Get(1), intf)
+		return err
 	}
-	err = current.Merge(intf)
+	api := getReqAPI(c)
+	node, err := api.GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
-	mergeInterfaceMetaData(c, current)
-	return api.SetInterface(c.Args().Get(0), c.Args().Get(1), *current)
+	for _, address := range addresses {
+		intf := model.RequisitionInterface{IPAddress: address}
+		current := node.GetInterface(address)
+		if current != nil {
+			intf = *current
+		}
+		if err := applyInterfaceFlags(c, &intf, current != nil); err != nil {
+			return err
+		}
+		if err := mergeNodeInterface(node, intf); err != nil {
+			return err
+		}
+	}
+	if err := node.Validate(); err != nil {
+		return common.ValidationError(err)
+	}
+	common.Log.Infof("Adding %d IP interfaces to node %s", len(addresses), node.ForeignID)
+	return api.SetNode(c.Args().Get(0), *node)
 }
 
-func applyInterface(c *cli.Context) error {
-	data, err := common.ReadInput(c, 2)
+// Replaces the interface with the same IP address on the node, or adds it when it doesn't exist
+func mergeNodeInterface(node *model.RequisitionNode, intf model.RequisitionInterface) error {
+	for i := range node.Interfaces {
+		if node.Interfaces[i].IPAddress == intf.IPAddress {
+			node.Interfaces[i] = intf
+			return nil
+		}
+	}
+	node.AddInterface(&intf)
+	return nil
+}
+
+// Changes the address of an interface (or swaps the addresses of two interfaces) on the node, sending the whole node,
+// so the interface keeps its content
+func setInterfaceAddress(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	ipAddress := c.Args().Get(2)
+	newIPAddress := c.Args().Get(3)
+	swapWith := c.String("swap-with")
+	switch {
+	case foreignSource == "":
+		return fmt.Errorf("Requisition name required")
+	case foreignID == "":
+		return fmt.Errorf("Foreign ID required")
+	case ipAddress == "":
+		return fmt.Errorf("IP Address required")
+	case newIPAddress != "" && swapWith != "":
+		return fmt.Errorf("Cannot specify a new IP address and --swap-with at the same time")
+	case newIPAddress == "" && swapWith == "":
+		return fmt.Errorf("New IP Address or --swap-with required")
+	}
+	requisition, err := getReqAPI(c).GetRequisition(foreignSource)
 	if err != nil {
 		return err
 	}
-	intf := model.RequisitionInterface{}
-	err = yaml.Unmarshal(data, &intf)
+	node := requisition.GetNode(foreignID)
+	if node == nil {
+		return fmt.Errorf("Node %s doesn't exist on requisition %s", foreignID, foreignSource)
+	}
+	var addresses []string // The addresses that the interfaces of the node get
+	if swapWith != "" {
+		if err := node.SwapInterfaceAddresses(ipAddress, swapWith); err != nil {
+			return err
+		}
+		addresses = []string{swapWith, ipAddress}
+	} else {
+		intf := model.RequisitionInterface{IPAddress: newIPAddress}
+		if err := intf.Validate(); err != nil { // Translates a FQDN
+			return err
+		}
+		if err := node.SetInterfaceAddress(ipAddress, intf.IPAddress); err != nil {
+			return err
+		}
+		addresses = []string{intf.IPAddress}
+	}
+	if !c.Bool("force") {
+		for _, address := range addresses {
+			if others := otherNodesWithInterface(requisition, foreignID, address); len(others) > 0 {
+				return fmt.Errorf("IP Address %s is already used by node %s on requisition %s, use --force to set it anyway", address, strings.Join(others, ", "), foreignSource)
+			}
+		}
+	}
+	if err := node.Validate(); err != nil {
+		return common.ValidationError(err)
+	}
+	if err := getReqAPI(c).SetNode(foreignSource, *node); err != nil {
+		return err
+	}
+	if swapWith != "" {
+		common.Log.Infof("IP interfaces %s and %s of node %s swapped", ipAddress, swapWith, foreignID)
+	} else {
+		common.Log.Infof("IP interface %s of node %s changed to %s", ipAddress, foreignID, addresses[0])
+	}
+	return nil
+}
+
+// Returns the foreign IDs of the nodes of the requisition, other than the given one, with an interface for the IP address
+func otherNodesWithInterface(requisition *model.Requisition, foreignID string, ipAddress string) []string {
+	others := make([]string, 0)
+	for _, id := range requisition.NodesWithInterface(ipAddress) {
+		if id != foreignID {
+			others = append(others, id)
+		}
+	}
+	return others
+}
+
+func applyInterface(c *cli.Context) error {
+	data, err := common.ReadInput(c, 2)
 	if err != nil {
 		return err
 	}
-	return getReqAPI().SetInterface(c.Args().Get(0), c.Args().Get(1), intf)
+	intf := &model.RequisitionInterface{}
+	return common.ApplyYAML(data, intf, func() error {
+		return getReqAPI(c).SetInterface(c.Args().Get(0), c.Args().Get(1), *intf)
+	})
 }
 
 func deleteInterface(c *cli.Context) error {
-	return getReqAPI().DeleteInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	ipAddress := c.Args().Get(2)
+	if !common.SkipConfirmation(c) {
+		intf, err := getReqAPI(c).GetInterface(foreignSource, foreignID, ipAddress)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("IP interface %s will be deleted from node %s on requisition %s with its %d services", ipAddress, foreignID, foreignSource, len(intf.Services))
+		if err := common.Confirm(c, description); err != nil {
+			return err
+		}
+	}
+	return getReqAPI(c).DeleteInterface(foreignSource, foreignID, ipAddress)
 }
 
 func intfListMetaData(c *cli.Context) error {
-	intf, err := getReqAPI().GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	intf, err := getReqAPI(c).GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
 	if err != nil {
 		return err
 	}
-	if len(intf.MetaData) == 0 {
-		fmt.Println("There is no meta-data for the chosen IP interface")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Context\tKey\tValue")
-	for _, m := range intf.MetaData {
-		fmt.Fprintf(writer, "%s\t%s\t%s\n", m.Context, m.Key, m.Value)
-	}
-	writer.Flush()
-	return nil
+	return printMetaData(intf.MetaData, "There is no meta-data for the chosen IP interface")
 }
 
 func intfSetMetaData(c *cli.Context) error {
-	intf, err := getReqAPI().GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	intf, err := getReqAPI(c).GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
 	if err != nil {
 		return err
 	}
@@ -207,11 +388,11 @@ func intfSetMetaData(c *cli.Context) error {
 	if err := intf.Validate(); err != nil {
 		return err
 	}
-	return getReqAPI().SetInterface(c.Args().Get(0), c.Args().Get(1), *intf)
+	return getReqAPI(c).SetInterface(c.Args().Get(0), c.Args().Get(1), *intf)
 }
 
 func intfDeleteMetaData(c *cli.Context) error {
-	intf, err := getReqAPI().GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	intf, err := getReqAPI(c).GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
 	if err != nil {
 		return err
 	}
@@ -219,13 +400,16 @@ func intfDeleteMetaData(c *cli.Context) error {
 	if err := intf.Validate(); err != nil {
 		return err
 	}
-	return getReqAPI().SetInterface(c.Args().Get(0), c.Args().Get(1), *intf)
+	return getReqAPI(c).SetInterface(c.Args().Get(0), c.Args().Get(1), *intf)
 }
 
-func mergeInterfaceMetaData(c *cli.Context, target *model.RequisitionInterface) {
-	metaData := c.StringSlice("metaData")
-	for _, p := range metaData {
-		data := strings.Split(p, "=")
-		target.AddMetaData(data[0], data[1])
+func mergeInterfaceMetaData(c *cli.Context, target *model.RequisitionInterface) error {
+	for _, p := range c.StringSlice("meta") {
+		data := strings.SplitN(p, "=", 2)
+		if len(data) != 2 || data[0] == "" {
+			return fmt.Errorf("Invalid meta-data %s, expected key=value", p)
+		}
+		target.SetMetaData(data[0], data[1])
 	}
+	return nil
 }
@@ -1,8 +1,15 @@
 package provisioning
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/test"
 	"gotest.tools/assert"
 )
@@ -11,6 +18,7 @@ func TestListInterfaces(t *testing.T) {
 	var err error
 	app := test.CreateCli(InterfacesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "intf", "list"})
@@ -27,6 +35,7 @@ func TestGetInterface(t *testing.T) {
 	var err error
 	app := test.CreateCli(InterfacesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "intf", "get"})
@@ -46,6 +55,7 @@ func TestAddInterface(t *testing.T) {
 	var err error
 	app := test.CreateCli(InterfacesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "intf", "add"})
@@ -59,12 +69,84 @@ func TestAddInterface(t *testing.T) {
 
 	err = app.Run([]string{app.Name, "intf", "add", "Test", "n1", "10.0.0.10"})
 	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "intf", "add", "--cidr", "10.0.0.0/29", "Test", "n1", "10.0.0.10"})
+	assert.Error(t, err, "Cannot specify an IP address and a CIDR block at the same time")
+
+	err = app.Run([]string{app.Name, "intf", "add", "--cidr", "10.0.0.0/29", "--max-interfaces", "4", "Test", "n1"})
+	assert.Error(t, err, "CIDR block 10.0.0.0/29 generates more than 4 IP interfaces")
+
+	err = app.Run([]string{app.Name, "intf", "add", "--cidr", "10.0.0.0/29", "Test", "n1"})
+	assert.NilError(t, err)
+}
+
+func TestAddInterfaceWithFlags(t *testing.T) {
+	app := test.CreateCli(InterfacesCliCommand)
+	var received []model.RequisitionInterface
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Test"}})
+		case "/rest/requisitions/Test/nodes/n1":
+			sendData(res, testNode)
+		case "/rest/requisitions/Test/nodes/n1/interfaces":
+			assert.Equal(t, http.MethodPost, req.Method)
+			intf := model.RequisitionInterface{}
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(bytes, &intf))
+			received = append(received, intf)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	test.Client(app).URL = server.URL
+
+	// The flags not used keep the content of the existing interface, and the services are added
+	err := app.Run([]string{app.Name, "intf", "add", "--description", "Uplink", "--service", "ICMP", "--service", "HTTP", "--meta", "mpls=true", "--meta", "vrf=core", "Test", "n1", "10.0.0.1"})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(received))
+	intf := received[0]
+	assert.Equal(t, "Uplink", intf.Description)
+	assert.Equal(t, "P", intf.SnmpPrimary)
+	assert.Equal(t, 1, intf.Status)
+	assert.Equal(t, 2, len(intf.Services))
+	assert.Equal(t, "/index.html", intf.Services[0].MetaData[0].Value)
+	assert.Equal(t, "ICMP", intf.Services[1].Name)
+	assert.DeepEqual(t, []model.RequisitionMetaData{{Key: "mpls", Value: "true"}, {Context: "requisition", Key: "vrf", Value: "core"}}, intf.MetaData)
+
+	err = app.Run([]string{app.Name, "intf", "add", "--replace-services", "--service", "SNMP", "--status", "3", "Test", "n1", "10.0.0.1"})
+	assert.NilError(t, err)
+	intf = received[1]
+	assert.Equal(t, "P", intf.SnmpPrimary)
+	assert.Equal(t, 3, intf.Status)
+	assert.Equal(t, 1, len(intf.Services))
+	assert.Equal(t, "SNMP", intf.Services[0].Name)
+
+	err = app.Run([]string{app.Name, "intf", "add", "--snmp-primary", "S", "--service", "ICMP", "Test", "n1", "10.0.0.2"})
+	assert.NilError(t, err)
+	intf = received[2]
+	assert.Equal(t, "10.0.0.2", intf.IPAddress)
+	assert.Equal(t, "S", intf.SnmpPrimary)
+	assert.Equal(t, 1, intf.Status)
+	assert.Equal(t, "ICMP", intf.Services[0].Name)
+
+	// The node is validated before sending the interface
+	err = app.Run([]string{app.Name, "intf", "add", "-p", "P", "Test", "n1", "10.0.0.2"})
+	assert.Error(t, err, "Node n1 cannot have more than one primary interface")
+	err = app.Run([]string{app.Name, "intf", "add", "--status", "2", "Test", "n1", "10.0.0.2"})
+	assert.ErrorContains(t, err, "Invalid value 2, valid options: 1, 3")
+	err = app.Run([]string{app.Name, "intf", "add", "--meta", "vrf", "Test", "n1", "10.0.0.2"})
+	assert.Error(t, err, "Invalid meta-data vrf, expected key=value")
+	assert.Equal(t, 3, len(received))
 }
 
 func TestDeleteInterface(t *testing.T) {
 	var err error
 	app := test.CreateCli(InterfacesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "intf", "delete"})
@@ -76,6 +158,12 @@ func TestDeleteInterface(t *testing.T) {
 	err = app.Run([]string{app.Name, "intf", "delete", "Test", "n1"})
 	assert.Error(t, err, "IP Address required")
 
+	defer func() { common.ConfirmInput = nil }()
+	common.ConfirmInput = strings.NewReader("\n")
+	err = app.Run([]string{app.Name, "intf", "delete", "Test", "n1", "10.0.0.10"})
+	assert.Error(t, err, "Operation cancelled")
+
+	common.ConfirmInput = strings.NewReader("yes\n")
 	err = app.Run([]string{app.Name, "intf", "delete", "Test", "n1", "10.0.0.10"})
 	assert.NilError(t, err)
 }
@@ -84,6 +172,7 @@ func TestInterfaceMetaData(t *testing.T) {
 	var err error
 	app := test.CreateCli(InterfacesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "intf", "meta", "set", "Test", "n1", "10.0.0.1", "active", "true"})
@@ -92,3 +181,78 @@ func TestInterfaceMetaData(t *testing.T) {
 	err = app.Run([]string{app.Name, "intf", "meta", "set", "Test", "n1", "10.0.0.1", "mpls", "false"})
 	assert.NilError(t, err)
 }
+
+func TestSetInterfaceAddress(t *testing.T) {
+	app := test.CreateCli(InterfacesCliCommand)
+	node := testNode
+	node.Interfaces = append([]model.RequisitionInterface{}, testNode.Interfaces...)
+	node.Interfaces = append(node.Interfaces, model.RequisitionInterface{IPAddress: "10.0.0.2", SnmpPrimary: "S"})
+	requisition := model.Requisition{
+		Name: "Test",
+		Nodes: []model.RequisitionNode{
+			node,
+			{ForeignID: "n2", NodeLabel: "n2", Interfaces: []model.RequisitionInterface{{IPAddress: "10.0.0.5", SnmpPrimary: "P"}}},
+		},
+	}
+	received := make([]model.RequisitionNode, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Test"}})
+		case "/rest/requisitions/Test":
+			sendData(res, requisition)
+		case "/rest/requisitions/Test/nodes":
+			assert.Equal(t, http.MethodPost, req.Method)
+			node := model.RequisitionNode{}
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(bytes, &node))
+			received = append(received, node)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	test.Client(app).URL = server.URL
+
+	// The interface keeps its content
+	err := app.Run([]string{app.Name, "intf", "set-ip", "Test", "n1", "10.0.0.1", "10.0.0.10"})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(received))
+	assert.Equal(t, 2, len(received[0].Interfaces))
+	intf := received[0].Interfaces[0]
+	assert.Equal(t, "10.0.0.10", intf.IPAddress)
+	assert.Equal(t, "P", intf.SnmpPrimary)
+	assert.Equal(t, "HTTP", intf.Services[0].Name)
+	assert.Equal(t, "false", intf.MetaData[0].Value)
+
+	// The addresses and primary flags are exchanged
+	err = app.Run([]string{app.Name, "intf", "set-ip", "--swap-with", "10.0.0.2", "Test", "n1", "10.0.0.1"})
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(received))
+	swapped := intf
+	swapped.IPAddress = "10.0.0.2"
+	swapped.SnmpPrimary = "S"
+	assert.DeepEqual(t, swapped, received[1].Interfaces[0])
+	assert.DeepEqual(t, model.RequisitionInterface{IPAddress: "10.0.0.1", SnmpPrimary: "P", Status: 1}, received[1].Interfaces[1])
+
+	// The addresses used by other nodes require --force
+	err = app.Run([]string{app.Name, "intf", "set-ip", "Test", "n1", "10.0.0.1", "10.0.0.5"})
+	assert.Error(t, err, "IP Address 10.0.0.5 is already used by node n2 on requisition Test, use --force to set it anyway")
+	err = app.Run([]string{app.Name, "intf", "set-ip", "--force", "Test", "n1", "10.0.0.1", "10.0.0.5"})
+	assert.NilError(t, err)
+	assert.Equal(t, 3, len(received))
+	assert.Equal(t, "10.0.0.5", received[2].Interfaces[0].IPAddress)
+
+	err = app.Run([]string{app.Name, "intf", "set-ip", "Test", "n1", "10.0.0.1", "10.0.0.2"})
+	assert.Error(t, err, "IP Address 10.0.0.2 already exists on node n1")
+	err = app.Run([]string{app.Name, "intf", "set-ip", "Test", "n1", "10.0.0.3", "10.0.0.4"})
+	assert.Error(t, err, "IP Address 10.0.0.3 doesn't exist on node n1")
+	err = app.Run([]string{app.Name, "intf", "set-ip", "Test", "n3", "10.0.0.1", "10.0.0.4"})
+	assert.Error(t, err, "Node n3 doesn't exist on requisition Test")
+	err = app.Run([]string{app.Name, "intf", "set-ip", "Test", "n1", "10.0.0.1"})
+	assert.Error(t, err, "New IP Address or --swap-with required")
+	err = app.Run([]string{app.Name, "intf", "set-ip", "--swap-with", "10.0.0.2", "Test", "n1", "10.0.0.1", "10.0.0.4"})
+	assert.Error(t, err, "Cannot specify a new IP address and --swap-with at the same time")
+	assert.Equal(t, 3, len(received))
+}
@@ -2,10 +2,10 @@ package provisioning
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/OpenNMS/onmsctl/api"
-	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/services"
 	"github.com/urfave/cli"
 )
@@ -13,73 +13,180 @@ import (
 // Formats the available file formats for requisitions and foreign source definitions
 var Formats = []string{"xml", "json", "yaml"}
 
-func getReqAPI() api.RequisitionsAPI {
-	return services.GetRequisitionsAPI(rest.Instance)
+// skipValidationFlag the flag to skip verifying detector and policy classes against the server
+var skipValidationFlag = cli.BoolFlag{
+	Name:  "skip-validation",
+	Usage: "Skip verifying detector and policy classes and parameters against the server",
 }
 
-func getFsAPI() api.ForeignSourcesAPI {
-	return services.GetForeignSourcesAPI(rest.Instance)
+// validateLocationsFlag the flag to verify the location of the nodes against the server
+var validateLocationsFlag = cli.BoolFlag{
+	Name:  "validate-locations",
+	Usage: "Verify that the location of the node exists on the server",
 }
 
-func getUtilsAPI() api.ProvisioningUtilsAPI {
-	return services.GetProvisioningUtilsAPI(rest.Instance)
+// strictAssetsFlag the flag to reject the asset fields that OpenNMS doesn't know, instead of warning about them
+var strictAssetsFlag = cli.BoolFlag{
+	Name:  "strict-assets",
+	Usage: "Reject unknown asset fields instead of warning about them (the server ignores them)",
+}
+
+// extraAssetsFlag the asset fields accepted besides the known ones, for the fields added by newer versions of the server
+var extraAssetsFlag = cli.StringSliceFlag{
+	Name:   "extra-asset",
+	Usage:  "An asset field to accept besides the known ones (e.x. a field added by Meridian); can be used multiple times",
+	EnvVar: "ONMSCTL_EXTRA_ASSETS",
+}
+
+// importModeValue the rescan mode of the --import flag, which can be used without a value to request a dbonly import;
+// as the value of a bare flag is "true", the full rescan is requested with "rescan" instead of the value used by the server
+type importModeValue struct {
+	mode model.RescanMode
+}
+
+// The values of the --import flag, and the rescan mode of each one; false means no import
+var importModes = map[string]model.RescanMode{
+	"true":      model.RescanDBOnly,
+	"false":     "",
+	"dbonly":    model.RescanDBOnly,
+	"rescan":    model.RescanAll,
+	"no-rescan": model.RescanNone,
+}
+
+func (v *importModeValue) Set(value string) error {
+	mode, ok := importModes[value]
+	if !ok {
+		return fmt.Errorf("allowed values are dbonly, rescan, no-rescan")
+	}
+	v.mode = mode
+	return nil
+}
+
+func (v *importModeValue) String() string {
+	return string(v.mode)
+}
+
+// IsBoolFlag allows using the flag without a value
+func (v *importModeValue) IsBoolFlag() bool {
+	return true
+}
+
+// The flags to import the requisition after updating its nodes
+func importFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.GenericFlag{
+			Name:  "import",
+			Value: &importModeValue{},
+			Usage: "Import the requisition after updating the nodes; --import uses dbonly to skip the scan phase, while --import=rescan and --import=no-rescan match rescanExisting true and false",
+		},
+		cli.BoolFlag{
+			Name:  "no-import",
+			Usage: "Don't import the requisition after updating the nodes (the default)",
+		},
+	}
+}
+
+// Returns the rescan mode of the import requested through the flags, or an empty mode when no import was requested
+func getImportMode(c *cli.Context) (model.RescanMode, error) {
+	if !c.IsSet("import") {
+		return "", nil
+	}
+	if c.Bool("no-import") {
+		return "", fmt.Errorf("--import and --no-import cannot be used together")
+	}
+	return model.RescanMode(c.String("import")), nil
+}
+
+// Imports the requisitions with the given rescan mode, when not empty
+func importRequisitions(c *cli.Context, mode model.RescanMode, foreignSources ...string) error {
+	if mode == "" {
+		return nil
+	}
+	imported := make(map[string]bool)
+	for _, fs := range foreignSources {
+		if imported[fs] {
+			continue
+		}
+		if err := getReqAPI(c).ImportRequisition(fs, mode); err != nil {
+			return fmt.Errorf("Cannot import requisition %s: %w", fs, err)
+		}
+		imported[fs] = true
+		common.Log.Infof("Import of requisition %s requested (rescanExisting=%s)", fs, mode)
+	}
+	return nil
+}
+
+func getReqAPI(c *cli.Context) api.RequisitionsAPI {
+	return services.GetRequisitionsAPI(common.GetClient(c))
+}
+
+func getFsAPI(c *cli.Context) api.ForeignSourcesAPI {
+	return services.GetForeignSourcesAPI(common.GetClient(c))
+}
+
+// Obtains the foreign sources API honoring the --skip-validation flag of the command
+func getValidatingFsAPI(c *cli.Context) api.ForeignSourcesAPI {
+	return services.GetForeignSourcesAPIWithValidation(common.GetClient(c), c.Bool("skip-validation"))
+}
+
+func getLocationsAPI(c *cli.Context) api.MonitoringLocationsAPI {
+	return services.GetMonitoringLocationsAPI(common.GetClient(c))
+}
+
+func getUtilsAPI(c *cli.Context) api.ProvisioningUtilsAPI {
+	return services.GetProvisioningUtilsAPI(common.GetClient(c))
 }
 
 func requisitionNameBashComplete(c *cli.Context) {
 	if c.NArg() > 0 {
 		return
 	}
-	list, err := getUtilsAPI().GetRequisitionNames()
-	if err != nil {
-		return
-	}
-	for _, fs := range list.ForeignSources {
-		fmt.Println(fs)
-	}
+	common.PrintCompletions(common.CachedCompletions(c, "requisitions", func() ([]string, error) {
+		list, err := getUtilsAPI(c).GetRequisitionNames()
+		if err != nil {
+			return nil, err
+		}
+		return list.ForeignSources, nil
+	})...)
 }
 
+// Suggests the deployed foreign IDs, as the stats of all requisitions are obtained with a single request
 func foreignIDBashComplete(c *cli.Context) {
 	requisitionNameBashComplete(c)
 	if c.NArg() == 1 {
-		req, err := getReqAPI().GetRequisition(c.Args().First())
-		if err != nil {
-			return
-		}
-		for _, n := range req.Nodes {
-			fmt.Println(zshNormalize(n.ForeignID))
-		}
+		name := c.Args().First()
+		common.PrintCompletions(common.CachedCompletions(c, "foreign-ids/"+name, func() ([]string, error) {
+			stats, err := getReqAPI(c).GetRequisitionsStats()
+			if err != nil {
+				return nil, err
+			}
+			return stats.GetRequisitionStats(name).ForeignIDs, nil
+		})...)
 	}
 }
 
 func ipAddressBashComplete(c *cli.Context) {
 	foreignIDBashComplete(c)
 	if c.NArg() == 2 {
-		node, err := getReqAPI().GetNode(c.Args().Get(0), c.Args().Get(1))
+		node, err := getReqAPI(c).GetNode(c.Args().Get(0), c.Args().Get(1))
 		if err != nil {
 			return
 		}
 		for _, intf := range node.Interfaces {
-			fmt.Println(zshNormalize(intf.IPAddress))
+			common.PrintCompletions(intf.IPAddress)
 		}
 	}
 }
 
 func servicesBashComplete(c *cli.Context) {
 	ipAddressBashComplete(c)
-	if c.NArg() == 2 {
-		intf, err := getReqAPI().GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	if c.NArg() == 3 {
+		intf, err := getReqAPI(c).GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
 		if err != nil {
 			return
 		}
 		for _, svc := range intf.Services {
-			fmt.Println(zshNormalize(svc.Name))
+			common.PrintCompletions(svc.Name)
 		}
 	}
 }
-
-// TODO Bash or ZSH don't like spaces when doing auto-complete
-//      The characters '.' and ':' are escaped as they have a special meaning
-func zshNormalize(src string) string {
-	dst := strings.ReplaceAll(src, ":", "\\:")
-	return strings.ReplaceAll(dst, ".", "\\.")
-}
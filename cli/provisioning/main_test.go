@@ -0,0 +1,68 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestMain(m *testing.M) {
+	model.Resolver.LookupIP = test.LookupIP
+	os.Exit(m.Run())
+}
+
+func TestBashComplete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	common.CompletionCacheDir = dir
+	defer func() { common.CompletionCacheDir = "" }()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests++
+		var data interface{}
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			data = model.RequisitionsList{Count: 2, ForeignSources: []string{"Test", "Local"}}
+		case "/rest/requisitions/deployed/stats":
+			data = model.RequisitionsStats{Count: 1, ForeignSources: []model.RequisitionStats{{Name: "Test", Count: 2, ForeignIDs: []string{"n1", "n2"}}}}
+		default:
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		bytes, _ := json.Marshal(data)
+		res.Write(bytes)
+	}))
+	defer server.Close()
+
+	app := test.CreateCli(NodesCliCommand, rest.WithURL(server.URL))
+	app.EnableBashCompletion = true
+
+	output, err := test.RunWithOutput(app, "table", "node", "get", "--generate-bash-completion")
+	assert.NilError(t, err)
+	assert.Equal(t, "Test\nLocal\n", output)
+	output, err = test.RunWithOutput(app, "table", "node", "get", "--generate-bash-completion")
+	assert.NilError(t, err)
+	assert.Equal(t, "Test\nLocal\n", output)
+	assert.Equal(t, 1, requests) // The second time, the names are obtained from the cache
+
+	output, err = test.RunWithOutput(app, "table", "node", "get", "Test", "--generate-bash-completion")
+	assert.NilError(t, err)
+	assert.Equal(t, "n1\nn2\n", output)
+	assert.Equal(t, 2, requests)
+
+	server.Close() // Completion doesn't fail when the server is unreachable
+	output, err = test.RunWithOutput(app, "table", "node", "get", "Local", "--generate-bash-completion")
+	assert.NilError(t, err)
+	assert.Equal(t, "", output)
+}
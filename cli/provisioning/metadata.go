@@ -0,0 +1,168 @@
+package provisioning
+
+import (
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// metaDataScopeFlags the flags to choose the entity that owns the meta-data entries
+var metaDataScopeFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "interface, i",
+		Usage: "The IP address of the interface that owns the meta-data (defaults to the node)",
+	},
+	cli.StringFlag{
+		Name:  "service, s",
+		Usage: "The name of the monitored service that owns the meta-data (requires --interface)",
+	},
+}
+
+// metaDataContextFlag the flag to choose the context of a meta-data entry
+var metaDataContextFlag = cli.StringFlag{
+	Name:  "context, c",
+	Value: model.DefaultMetaDataContext,
+	Usage: "The context of the meta-data entry",
+}
+
+// MetaDataCliCommand the CLI command configuration for managing meta-data on requisitioned nodes, IP interfaces and services
+var MetaDataCliCommand = cli.Command{
+	Name:      "metadata",
+	ShortName: "meta",
+	Usage:     "Manage meta-data for nodes, IP interfaces and monitored services",
+	Category:  "Requisitions",
+	Subcommands: []cli.Command{
+		{
+			Name:         "list",
+			Usage:        "List all meta-data entries from a given node, IP interface or service",
+			ArgsUsage:    "<foreignSource> <foreignId>",
+			Flags:        metaDataScopeFlags,
+			Action:       listMetaData,
+			BashComplete: foreignIDBashComplete,
+		},
+		{
+			Name:         "set",
+			ShortName:    "add",
+			Usage:        "Adds or updates a meta-data entry on a given node, IP interface or service",
+			ArgsUsage:    "<foreignSource> <foreignId> <key> <value>",
+			Flags:        append(metaDataScopeFlags, metaDataContextFlag),
+			Action:       setMetaData,
+			BashComplete: foreignIDBashComplete,
+		},
+		{
+			Name:         "delete",
+			ShortName:    "del",
+			Usage:        "Deletes a meta-data entry from a given node, IP interface or service",
+			ArgsUsage:    "<foreignSource> <foreignId> <key>",
+			Flags:        append(metaDataScopeFlags, metaDataContextFlag),
+			Action:       deleteMetaData,
+			BashComplete: foreignIDBashComplete,
+		},
+	},
+}
+
+// metaDataTarget the meta-data entries of the chosen entity, and how to send them back to the server
+type metaDataTarget struct {
+	name     string
+	metaData *[]model.RequisitionMetaData
+	save     func() error
+}
+
+func listMetaData(c *cli.Context) error {
+	target, err := getMetaDataTarget(c)
+	if err != nil {
+		return err
+	}
+	return printMetaData(*target.metaData, "There is no meta-data for the chosen "+target.name)
+}
+
+func printMetaData(metaData []model.RequisitionMetaData, empty string) error {
+	table := common.NewTable(empty, "Context", "Key", "Value")
+	for _, m := range metaData {
+		table.AddRow(m.Context, m.Key, m.Value)
+	}
+	return common.Print(metaData, table)
+}
+
+func setMetaData(c *cli.Context) error {
+	key := c.Args().Get(2)
+	if key == "" {
+		return fmt.Errorf("Meta-data key required")
+	}
+	value := c.Args().Get(3)
+	if value == "" {
+		return fmt.Errorf("Meta-data value required")
+	}
+	target, err := getMetaDataTarget(c)
+	if err != nil {
+		return err
+	}
+	*target.metaData = model.SetMetaDataEntry(*target.metaData, c.String("context"), key, value)
+	return target.save()
+}
+
+func deleteMetaData(c *cli.Context) error {
+	key := c.Args().Get(2)
+	if key == "" {
+		return fmt.Errorf("Meta-data key required")
+	}
+	target, err := getMetaDataTarget(c)
+	if err != nil {
+		return err
+	}
+	var found bool
+	*target.metaData, found = model.DeleteMetaDataEntry(*target.metaData, c.String("context"), key)
+	if !found {
+		return fmt.Errorf("Meta-data entry %s doesn't exist on context %s for the chosen %s", key, c.String("context"), target.name)
+	}
+	return target.save()
+}
+
+func getMetaDataTarget(c *cli.Context) (*metaDataTarget, error) {
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	ipAddress := c.String("interface")
+	serviceName := c.String("service")
+	if serviceName != "" && ipAddress == "" {
+		return nil, fmt.Errorf("An IP interface is required to manage meta-data for a service")
+	}
+	api := getReqAPI(c)
+	node, err := api.GetNode(foreignSource, foreignID)
+	if err != nil {
+		return nil, err
+	}
+	if ipAddress == "" {
+		return &metaDataTarget{"node", &node.MetaData, func() error {
+			return api.SetNode(foreignSource, *node)
+		}}, nil
+	}
+	ipAddress, _ = model.SplitIPZone(ipAddress)
+	var intf *model.RequisitionInterface
+	for i := range node.Interfaces {
+		if node.Interfaces[i].IPAddress == ipAddress {
+			intf = &node.Interfaces[i]
+		}
+	}
+	if intf == nil {
+		return nil, fmt.Errorf("IP interface %s doesn't exist on node %s", ipAddress, foreignID)
+	}
+	if serviceName == "" {
+		return &metaDataTarget{"IP interface", &intf.MetaData, func() error {
+			return api.SetInterface(foreignSource, foreignID, *intf)
+		}}, nil
+	}
+	var svc *model.RequisitionMonitoredService
+	for i := range intf.Services {
+		if intf.Services[i].Name == serviceName {
+			svc = &intf.Services[i]
+		}
+	}
+	if svc == nil {
+		return nil, fmt.Errorf("Service %s doesn't exist on IP interface %s", serviceName, ipAddress)
+	}
+	return &metaDataTarget{"service", &svc.MetaData, func() error {
+		return api.SetService(foreignSource, foreignID, ipAddress, *svc)
+	}}, nil
+}
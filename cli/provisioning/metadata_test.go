@@ -0,0 +1,80 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestListMetaData(t *testing.T) {
+	var err error
+	app := test.CreateCli(MetaDataCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "meta", "list"})
+	assert.Error(t, err, "Requisition name required")
+
+	err = app.Run([]string{app.Name, "meta", "list", "Test"})
+	assert.Error(t, err, "Foreign ID required")
+
+	err = app.Run([]string{app.Name, "meta", "list", "Test", "n1"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "meta", "list", "--service", "HTTP", "Test", "n1"})
+	assert.Error(t, err, "An IP interface is required to manage meta-data for a service")
+
+	err = app.Run([]string{app.Name, "meta", "list", "--interface", "10.0.0.2", "Test", "n1"})
+	assert.Error(t, err, "IP interface 10.0.0.2 doesn't exist on node n1")
+
+	err = app.Run([]string{app.Name, "meta", "list", "--interface", "10.0.0.1", "--service", "ICMP", "Test", "n1"})
+	assert.Error(t, err, "Service ICMP doesn't exist on IP interface 10.0.0.1")
+
+	err = app.Run([]string{app.Name, "meta", "list", "--interface", "10.0.0.1", "--service", "HTTP", "Test", "n1"})
+	assert.NilError(t, err)
+}
+
+func TestSetMetaData(t *testing.T) {
+	var err error
+	app := test.CreateCli(MetaDataCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "meta", "set", "Test", "n1"})
+	assert.Error(t, err, "Meta-data key required")
+
+	err = app.Run([]string{app.Name, "meta", "set", "Test", "n1", "owner"})
+	assert.Error(t, err, "Meta-data value required")
+
+	// The existing entry is replaced; the same key on a different context is added
+	err = app.Run([]string{app.Name, "meta", "set", "Test", "n1", "owner", "team"})
+	assert.NilError(t, err)
+	err = app.Run([]string{app.Name, "meta", "set", "--context", "custom", "Test", "n1", "owner", "team"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "meta", "set", "-i", "10.0.0.1", "Test", "n1", "active", "true"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "meta", "set", "-i", "10.0.0.1", "-s", "HTTP", "Test", "n1", "url", "/status"})
+	assert.NilError(t, err)
+}
+
+func TestDeleteMetaData(t *testing.T) {
+	var err error
+	app := test.CreateCli(MetaDataCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "meta", "delete", "Test", "n1"})
+	assert.Error(t, err, "Meta-data key required")
+
+	err = app.Run([]string{app.Name, "meta", "delete", "--context", "custom", "Test", "n1", "owner"})
+	assert.Error(t, err, "Meta-data entry owner doesn't exist on context custom for the chosen node")
+
+	err = app.Run([]string{app.Name, "meta", "delete", "-i", "10.0.0.1", "Test", "n1", "mpls"})
+	assert.NilError(t, err)
+}
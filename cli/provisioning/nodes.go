@@ -7,8 +7,6 @@ import (
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/urfave/cli"
-
-	"gopkg.in/yaml.v2"
 )
 
 // NodesCliCommand the CLI command configuration for managing requisitioned nodes
@@ -39,7 +37,7 @@ var NodesCliCommand = cli.Command{
 			ArgsUsage:    "<foreignSource> <foreignId>",
 			BashComplete: foreignIDBashComplete,
 			Action:       setNode,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "label, l",
 					Usage: "Node Label",
@@ -72,27 +70,108 @@ var NodesCliCommand = cli.Command{
 					Name:  "metaData, m",
 					Usage: "A meta-data entry (e.x. --metaData 'foo=bar')",
 				},
-			},
+				validateLocationsFlag,
+			}, importFlags()...),
 		},
 		{
 			Name:  "apply",
-			Usage: "Creates or updates a node on a given requisition from a external YAML file, overriding any existing content",
-			Flags: []cli.Flag{
-				cli.StringFlag{
+			Usage: "Creates or updates nodes on a given requisition from external YAML files, overriding any existing content",
+			Description: "All the nodes are validated before sending any of them, and a summary is displayed at the end. " +
+				"The requisition can be specified per file through a 'requisition' field.",
+			Flags: append([]cli.Flag{
+				cli.StringSliceFlag{
 					Name:  "file, f",
-					Usage: "External YAML file (use '-' for STDIN Pipe)",
+					Usage: "External YAML file, directory (with *.yaml and *.yml files, recursively) or glob pattern; can be used multiple times (use '-' for STDIN Pipe)",
 				},
-			},
+				cli.StringFlag{
+					Name:  "requisition, r",
+					Usage: "The requisition for the nodes whose files don't have a requisition field (defaults to the <foreignSource> argument)",
+				},
+				cli.BoolFlag{
+					Name:  "fail-fast",
+					Usage: "Stop sending nodes after the first failure",
+				},
+				validateLocationsFlag,
+				strictAssetsFlag,
+				extraAssetsFlag,
+			}, importFlags()...),
 			ArgsUsage:    "<foreignSource> <yaml>",
 			Action:       applyNode,
 			BashComplete: requisitionNameBashComplete,
 		},
+		{
+			Name:         "import-csv",
+			Usage:        "Creates or updates nodes on a given requisition from a CSV file with a header row",
+			Description:  "Valid columns: " + strings.Join(CSVColumns, ", ") + "; categories are separated by ';' and assets are key=value pairs separated by ';'",
+			ArgsUsage:    "<foreignSource> <csv>",
+			Action:       importNodesFromCSV,
+			BashComplete: requisitionNameBashComplete,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External CSV file (use '-' for STDIN Pipe)",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print the resulting requisition in YAML without sending it",
+				},
+				cli.BoolFlag{
+					Name:  "merge, m",
+					Usage: "Update existing nodes (matched by foreign ID) instead of rejecting them",
+				},
+				strictAssetsFlag,
+				extraAssetsFlag,
+			},
+		},
+		{
+			Name:         "copy",
+			ShortName:    "cp",
+			Usage:        "Copies a node from a given requisition to another one",
+			ArgsUsage:    "<foreignSource> <foreignId> <destinationForeignSource>",
+			Action:       copyNode,
+			BashComplete: foreignIDBashComplete,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "new-foreign-id, n",
+					Usage: "The foreign ID for the node on the destination requisition (defaults to the current one)",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Overwrite the node if it already exists on the destination requisition",
+				},
+			},
+		},
+		{
+			Name:         "move",
+			ShortName:    "mv",
+			Usage:        "Moves a node from a given requisition to another one; the node is removed from the source only after it has been added to the destination",
+			ArgsUsage:    "<foreignSource> <foreignId> <destinationForeignSource>",
+			Action:       moveNode,
+			BashComplete: foreignIDBashComplete,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "new-foreign-id, n",
+					Usage: "The foreign ID for the node on the destination requisition (defaults to the current one)",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Overwrite the node if it already exists on the destination requisition",
+				},
+			},
+		},
+		editNodeCommand,
+		setLocationCommand,
+		setParentsCommand,
+		setLocationAssetsCommand,
+		normalizeLabelsCommand,
+		discoverCommand,
 		{
 			Name:         "delete",
 			ShortName:    "del",
 			Usage:        "Deletes a node from a given requisition",
 			ArgsUsage:    "<foreignSource> <foreignId>",
 			Action:       deleteNode,
+			Flags:        []cli.Flag{common.YesFlag},
 			BashComplete: foreignIDBashComplete,
 		},
 		{
@@ -127,35 +206,27 @@ var NodesCliCommand = cli.Command{
 }
 
 func listNodes(c *cli.Context) error {
-	requisition, err := getReqAPI().GetRequisition(c.Args().Get(0))
+	requisition, err := getReqAPI(c).GetRequisition(c.Args().Get(0))
 	if err != nil {
 		return err
 	}
-	if len(requisition.Nodes) == 0 {
-		fmt.Println("There are no nodes on the chosen requisition")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Foreign ID\tLabel\tLocation\tInterfaces\tAssets\tCategories")
+	table := common.NewTable("There are no nodes on the chosen requisition", "Foreign ID", "Label", "Location", "Interfaces", "Assets", "Categories")
 	for _, node := range requisition.Nodes {
 		location := node.Location
 		if location == "" {
 			location = "Default"
 		}
-		fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%d\t%d\n", node.ForeignID, node.NodeLabel, location, len(node.Interfaces), len(node.Assets), len(node.Categories))
+		table.AddRow(node.ForeignID, node.NodeLabel, location, len(node.Interfaces), len(node.Assets), len(node.Categories))
 	}
-	writer.Flush()
-	return nil
+	return common.Print(requisition.Nodes, table)
 }
 
 func showNode(c *cli.Context) error {
-	node, err := getReqAPI().GetNode(c.Args().Get(0), c.Args().Get(1))
+	node, err := getReqAPI(c).GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(&node)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(node, nil)
 }
 
 func setNode(c *cli.Context) error {
@@ -170,57 +241,149 @@ func setNode(c *cli.Context) error {
 		ParentNodeLabel:     c.String("parentNodeLabel"),
 	}
 
-	api := getReqAPI()
-	current, err := api.GetNode(c.Args().Get(0), c.Args().Get(1))
+	mode, err := getImportMode(c)
 	if err != nil {
-		mergeNodeMetaData(c, &node)
-		return api.SetNode(c.Args().Get(0), node)
+		return err
 	}
-	err = current.Merge(node)
+	api := getReqAPI(c)
+	current, err := api.GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
+		current = &node
+	} else if err := current.Merge(node); err != nil {
 		return err
 	}
 	mergeNodeMetaData(c, current)
-	return api.SetNode(c.Args().Get(0), *current)
+	if err := checkLocation(c, current.Location); err != nil {
+		return err
+	}
+	if err := api.SetNode(c.Args().Get(0), *current); err != nil {
+		return err
+	}
+	return importRequisitions(c, mode, c.Args().Get(0))
 }
 
-func applyNode(c *cli.Context) error {
-	data, err := common.ReadInput(c, 1)
+// Verifies that the location exists on the server when requested; empty means the default location
+func checkLocation(c *cli.Context, location string) error {
+	if !c.Bool("validate-locations") || location == "" || location == "Default" {
+		return nil
+	}
+	exists, err := getLocationsAPI(c).LocationExists(location)
+	if err != nil {
+		return fmt.Errorf("Cannot verify location %s: %w", location, err)
+	}
+	if !exists {
+		return common.ValidationError(fmt.Errorf("Location %s doesn't exist", location))
+	}
+	return nil
+}
+
+func copyNode(c *cli.Context) error {
+	return transferNode(c, false)
+}
+
+func moveNode(c *cli.Context) error {
+	return transferNode(c, true)
+}
+
+func transferNode(c *cli.Context, move bool) error {
+	source := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	target := c.Args().Get(2)
+	if target == "" {
+		return fmt.Errorf("Destination requisition name required")
+	}
+	api := getReqAPI(c)
+	node, err := api.GetNode(source, foreignID)
 	if err != nil {
 		return err
 	}
-	node := model.RequisitionNode{}
-	err = yaml.Unmarshal(data, &node)
+	if newID := c.String("new-foreign-id"); newID != "" {
+		node.ForeignID = newID
+	}
+	if source == target && node.ForeignID == foreignID {
+		return fmt.Errorf("The source and the destination of node %s are the same", foreignID)
+	}
+	requisition, err := api.GetRequisition(target)
 	if err != nil {
 		return err
 	}
-	return getReqAPI().SetNode(c.Args().Get(0), node)
+	if requisition.GetNode(node.ForeignID) != nil && !c.Bool("force") {
+		return fmt.Errorf("Node %s already exists on requisition %s, use --force to overwrite it", node.ForeignID, target)
+	}
+	if source != target {
+		warnOnDifferentDetectors(c, source, target)
+	}
+	if err := api.SetNode(target, *node); err != nil {
+		return err
+	}
+	if !move {
+		common.Log.Infof("Node %s copied from %s to %s as %s", foreignID, source, target, node.ForeignID)
+		return nil
+	}
+	if err := api.DeleteNode(source, foreignID); err != nil {
+		return fmt.Errorf("Node %s has been added to %s, but it cannot be removed from %s: %w", foreignID, target, source, err)
+	}
+	common.Log.Infof("Node %s moved from %s to %s as %s", foreignID, source, target, node.ForeignID)
+	return nil
+}
+
+// Warns when the detectors are different, as the services of the node might change after the next import
+func warnOnDifferentDetectors(c *cli.Context, source string, target string) {
+	sourceDef, err := getFsAPI(c).GetForeignSourceDef(source)
+	if err != nil {
+		return
+	}
+	targetDef, err := getFsAPI(c).GetForeignSourceDef(target)
+	if err != nil {
+		return
+	}
+	if !sameDetectors(sourceDef.Detectors, targetDef.Detectors) {
+		common.Log.Warnf("The detectors of %s are different from the ones of %s, so the services of the node might change after the next import", target, source)
+	}
+}
+
+func sameDetectors(a []model.Detector, b []model.Detector) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	detectors := make(map[string]string)
+	for _, d := range a {
+		detectors[d.Name] = d.Class
+	}
+	for _, d := range b {
+		if class, ok := detectors[d.Name]; !ok || class != d.Class {
+			return false
+		}
+	}
+	return true
 }
 
 func deleteNode(c *cli.Context) error {
-	return getReqAPI().DeleteNode(c.Args().Get(0), c.Args().Get(1))
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	if !common.SkipConfirmation(c) {
+		node, err := getReqAPI(c).GetNode(foreignSource, foreignID)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("Node %s (%s) will be deleted from requisition %s with its %d IP interfaces", foreignID, node.NodeLabel, foreignSource, len(node.Interfaces))
+		if err := common.Confirm(c, description); err != nil {
+			return err
+		}
+	}
+	return getReqAPI(c).DeleteNode(foreignSource, foreignID)
 }
 
 func nodeListMetaData(c *cli.Context) error {
-	node, err := getReqAPI().GetNode(c.Args().Get(0), c.Args().Get(1))
+	node, err := getReqAPI(c).GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
-	if len(node.MetaData) == 0 {
-		fmt.Println("There is no meta-data for the chosen node")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Context\tKey\tValue")
-	for _, m := range node.MetaData {
-		fmt.Fprintf(writer, "%s\t%s\t%s\n", m.Context, m.Key, m.Value)
-	}
-	writer.Flush()
-	return nil
+	return printMetaData(node.MetaData, "There is no meta-data for the chosen node")
 }
 
 func nodeSetMetaData(c *cli.Context) error {
-	node, err := getReqAPI().GetNode(c.Args().Get(0), c.Args().Get(1))
+	node, err := getReqAPI(c).GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
@@ -228,11 +391,11 @@ func nodeSetMetaData(c *cli.Context) error {
 	if err := node.Validate(); err != nil {
 		return err
 	}
-	return getReqAPI().SetNode(c.Args().Get(0), *node)
+	return getReqAPI(c).SetNode(c.Args().Get(0), *node)
 }
 
 func nodeDeleteMetaData(c *cli.Context) error {
-	node, err := getReqAPI().GetNode(c.Args().Get(0), c.Args().Get(1))
+	node, err := getReqAPI(c).GetNode(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
@@ -240,7 +403,7 @@ func nodeDeleteMetaData(c *cli.Context) error {
 	if err := node.Validate(); err != nil {
 		return err
 	}
-	return getReqAPI().SetNode(c.Args().Get(0), *node)
+	return getReqAPI(c).SetNode(c.Args().Get(0), *node)
 }
 
 func mergeNodeMetaData(c *cli.Context, target *model.RequisitionNode) {
@@ -0,0 +1,279 @@
+package provisioning
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The results of applying a node from a file
+const (
+	nodeCreated = "created"
+	nodeUpdated = "updated"
+	nodeFailed  = "failed"
+	nodeSkipped = "skipped"
+)
+
+// nodeFile a node loaded from a file, with the requisition it belongs to
+type nodeFile struct {
+	File        string
+	Requisition string
+	Node        model.RequisitionNode
+}
+
+// nodeFileHeader the fields of a node file that are not part of the node
+type nodeFileHeader struct {
+	Requisition string `yaml:"requisition"`
+}
+
+// nodeApplyResult the outcome of applying a node from a file
+type nodeApplyResult struct {
+	File        string `json:"file" yaml:"file"`
+	Requisition string `json:"requisition" yaml:"requisition"`
+	ForeignID   string `json:"foreign-id" yaml:"foreignID"`
+	Result      string `json:"result" yaml:"result"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func applyNode(c *cli.Context) error {
+	if files := c.StringSlice("file"); len(files) > 0 {
+		return applyNodeFiles(c, files)
+	}
+	content := c.Args().Get(1)
+	if content == "" {
+		return fmt.Errorf("Content cannot be empty")
+	}
+	mode, err := getImportMode(c)
+	if err != nil {
+		return err
+	}
+	node := &model.RequisitionNode{}
+	return common.ApplyYAML([]byte(content), node, func() error {
+		if err := checkLocation(c, node.Location); err != nil {
+			return err
+		}
+		if err := getReqAPI(c).SetNode(c.Args().Get(0), *node); err != nil {
+			return err
+		}
+		return importRequisitions(c, mode, c.Args().Get(0))
+	})
+}
+
+// Validates all the nodes from the files before sending any of them;
+// a failure doesn't stop sending the remaining nodes, unless --fail-fast is used
+func applyNodeFiles(c *cli.Context, patterns []string) error {
+	mode, err := getImportMode(c)
+	if err != nil {
+		return err
+	}
+	files, err := expandNodeFiles(patterns)
+	if err != nil {
+		return err
+	}
+	requisition := c.String("requisition")
+	if requisition == "" {
+		requisition = c.Args().First()
+	}
+	nodes, err := readNodeFiles(c, files, requisition)
+	if err != nil {
+		return err
+	}
+	if common.DryRun {
+		for _, n := range nodes {
+			data, err := yaml.Marshal(n.Node)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(common.Output, "# %s (requisition %s)\n%s---\n", n.File, n.Requisition, string(data))
+		}
+		return nil
+	}
+	api := getReqAPI(c)
+	existing := make(map[string]*model.Requisition)
+	failures := make(map[string]error)
+	results := make([]nodeApplyResult, 0, len(nodes))
+	failed := 0
+	for i, n := range nodes {
+		result := nodeApplyResult{File: n.File, Requisition: n.Requisition, ForeignID: n.Node.ForeignID}
+		if failed > 0 && c.Bool("fail-fast") {
+			result.Result = nodeSkipped
+			results = append(results, result)
+			continue
+		}
+		req, ok := existing[n.Requisition]
+		if !ok {
+			if req, err = api.GetRequisition(n.Requisition); err != nil {
+				failures[n.Requisition] = err
+			}
+			existing[n.Requisition] = req
+		}
+		if err, ok := failures[n.Requisition]; ok {
+			result.Result = nodeFailed
+			result.Error = fmt.Sprintf("Cannot get requisition %s: %s", n.Requisition, err)
+		} else if err := api.SetNode(n.Requisition, nodes[i].Node); err != nil {
+			result.Result = nodeFailed
+			result.Error = err.Error()
+		} else if req.GetNode(n.Node.ForeignID) != nil {
+			result.Result = nodeUpdated
+		} else {
+			result.Result = nodeCreated
+			req.AddNode(&nodes[i].Node) // So another file with the same node is reported as updated
+		}
+		if result.Result == nodeFailed {
+			failed++
+		}
+		results = append(results, result)
+	}
+	table := common.NewTable("", "File", "Requisition", "Foreign ID", "Result")
+	for _, r := range results {
+		status := r.Result
+		if r.Error != "" {
+			status += ": " + r.Error
+		}
+		table.AddRow(r.File, r.Requisition, r.ForeignID, status)
+	}
+	if err := common.Print(results, table); err != nil {
+		return err
+	}
+	// Only the requisitions with updated nodes are imported
+	updated := make([]string, 0)
+	for _, r := range results {
+		if r.Result == nodeCreated || r.Result == nodeUpdated {
+			updated = append(updated, r.Requisition)
+		}
+	}
+	if err := importRequisitions(c, mode, updated...); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d nodes failed", failed, len(nodes))
+	}
+	return nil
+}
+
+// Parses and validates the nodes from the files; all the problems are reported together, and nothing is returned when any node is invalid
+func readNodeFiles(c *cli.Context, files []string, requisition string) ([]nodeFile, error) {
+	nodes := make([]nodeFile, 0, len(files))
+	problems := make([]string, 0)
+	foreignIDs := make(map[string]string)
+	for _, file := range files {
+		n, err := readNodeFile(file, requisition)
+		if err == nil {
+			err = checkLocation(c, n.Node.Location)
+		}
+		if err == nil {
+			err = checkAssets(c, "", n.Node.Assets)
+		}
+		if err == nil {
+			key := n.Requisition + "/" + n.Node.ForeignID
+			if other, ok := foreignIDs[key]; ok {
+				err = fmt.Errorf("Foreign ID %s is also defined on %s", n.Node.ForeignID, other)
+			}
+			foreignIDs[key] = file
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", file, err))
+			continue
+		}
+		nodes = append(nodes, *n)
+	}
+	if len(problems) > 0 {
+		return nil, common.ValidationError(fmt.Errorf("Nothing was sent, as %d of %d files are invalid:\n  %s", len(problems), len(files), strings.Join(problems, "\n  ")))
+	}
+	return nodes, nil
+}
+
+func readNodeFile(file string, requisition string) (*nodeFile, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	n := &nodeFile{File: file}
+	if err := yaml.Unmarshal(data, &n.Node); err != nil {
+		return nil, err
+	}
+	header := nodeFileHeader{}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	switch { // The requisition from the file has precedence, so a directory can have nodes for multiple requisitions
+	case header.Requisition != "":
+		n.Requisition = header.Requisition
+	case requisition != "":
+		n.Requisition = requisition
+	default:
+		return nil, fmt.Errorf("Requisition required, use --requisition or add a requisition field to the file")
+	}
+	if err := n.Node.Validate(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Expands directories (recursively, with *.yaml and *.yml files) and glob patterns into a list of files
+func expandNodeFiles(patterns []string) ([]string, error) {
+	files := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(file string) {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	for _, pattern := range patterns {
+		if pattern == "-" {
+			add(pattern)
+			continue
+		}
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("Invalid pattern %s: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("There are no files matching %s", pattern)
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("YAML file %s doesn't exist", match)
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			err = filepath.Walk(match, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if ext := strings.ToLower(filepath.Ext(path)); !info.IsDir() && (ext == ".yaml" || ext == ".yml") {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("There are no YAML files on %s", strings.Join(patterns, ", "))
+	}
+	return files, nil
+}
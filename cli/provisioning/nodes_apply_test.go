@@ -0,0 +1,72 @@
+package provisioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func writeNodeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NilError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+}
+
+func TestApplyNodeFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	writeNodeFiles(t, dir, map[string]string{
+		"nodes/local.yml":    "requisition: Local\nforeignID: n3\nnodeLabel: n1\ninterfaces:\n- ipAddress: 10.0.0.3\n",
+		"nodes/test/n2.yaml": "foreignID: n2\nnodeLabel: n2\n",
+		"nodes/README.md":    "Not a node",
+		"missing.yaml":       "requisition: Missing\nforeignID: n4\n",
+		"invalid/other.yaml": "foreignID: [n5]\n",
+		"invalid/empty.yaml": "nodeLabel: n6\n",
+	})
+	app := test.CreateCli(NodesCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "node", "apply", "-r", "Test", "-f", filepath.Join(dir, "nodes"))
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Assert(t, strings.HasSuffix(lines[1], "Local        n3          updated"), lines[1])
+	assert.Assert(t, strings.HasSuffix(lines[2], "Test         n2          created"), lines[2])
+
+	output, err = test.RunWithOutput(app, "jsonpath=$[*].result", "node", "apply", "-f", filepath.Join(dir, "missing.yaml"), "-f", filepath.Join(dir, "nodes", "*", "n*.yaml"), "--fail-fast", "Test")
+	assert.Error(t, err, "1 of 2 nodes failed")
+	assert.Equal(t, "failed\nskipped\n", output)
+
+	output, err = test.RunWithOutput(app, "jsonpath=$[*].result", "node", "apply", "-f", filepath.Join(dir, "missing.yaml"), "-f", filepath.Join(dir, "nodes", "*", "n*.yaml"), "Test")
+	assert.Error(t, err, "1 of 2 nodes failed")
+	assert.Equal(t, "failed\ncreated\n", output)
+
+	_, err = test.RunWithOutput(app, "table", "node", "apply", "-r", "Test", "-f", filepath.Join(dir, "invalid"))
+	assert.Equal(t, common.ExitValidationError, err.(common.ExitError).ExitStatus())
+	assert.ErrorContains(t, err, "Nothing was sent, as 2 of 2 files are invalid")
+	assert.ErrorContains(t, err, "empty.yaml: Foreign ID cannot be empty")
+	assert.ErrorContains(t, err, "other.yaml: yaml: unmarshal errors")
+
+	common.DryRun = true
+	output, err = test.RunWithOutput(app, "table", "node", "apply", "-f", filepath.Join(dir, "nodes", "test"), "Test")
+	common.DryRun = false
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(output, "# "+filepath.Join(dir, "nodes", "test", "n2.yaml")+" (requisition Test)\nnodeLabel: n2\nforeignID: n2\n"), output)
+
+	_, err = test.RunWithOutput(app, "table", "node", "apply", "-f", filepath.Join(dir, "nodes", "test"))
+	assert.ErrorContains(t, err, "Requisition required")
+
+	_, err = test.RunWithOutput(app, "table", "node", "apply", "-f", filepath.Join(dir, "*.xml"))
+	assert.Error(t, err, "There are no files matching "+filepath.Join(dir, "*.xml"))
+}
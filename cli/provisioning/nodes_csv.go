@@ -0,0 +1,187 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CSVColumns the columns accepted when importing nodes from a CSV file
+var CSVColumns = []string{"foreignID", "label", "ipAddress", "location", "categories", "assets"}
+
+func importNodesFromCSV(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	if foreignSource == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	data, err := common.ReadInput(c, 1)
+	if err != nil {
+		return err
+	}
+	nodes, problems := parseNodesCSV(bytes.NewReader(data))
+	if len(problems) > 0 {
+		for _, p := range problems {
+			common.Log.Errorf("%s", p)
+		}
+		return common.ValidationError(fmt.Errorf("%d rows failed validation, nothing has been sent", len(problems)))
+	}
+	for _, n := range nodes {
+		if err := checkAssets(c, n.node.ForeignID, n.node.Assets); err != nil {
+			return err
+		}
+	}
+	dryRun := c.Bool("dry-run") || common.DryRun
+	requisition := &model.Requisition{Name: foreignSource}
+	if !dryRun && getUtilsAPI(c).RequisitionExists(foreignSource) {
+		if requisition, err = getReqAPI(c).GetRequisition(foreignSource); err != nil {
+			return err
+		}
+	}
+	if err := mergeCSVNodes(requisition, nodes, c.Bool("merge")); err != nil {
+		return err
+	}
+	if dryRun {
+		data, _ := yaml.Marshal(requisition)
+		fmt.Fprintln(common.Output, string(data))
+		return nil
+	}
+	if err := requisition.Validate(); err != nil {
+		return common.ValidationError(err)
+	}
+	common.Log.Infof("Sending %d nodes to requisition %s...", len(nodes), foreignSource)
+	return getReqAPI(c).SetRequisition(*requisition)
+}
+
+// Adds the nodes to the requisition; when merge is enabled, existing nodes are updated, otherwise duplicates are rejected
+func mergeCSVNodes(requisition *model.Requisition, nodes []csvNode, merge bool) error {
+	index := make(map[string]int)
+	for i, n := range requisition.Nodes {
+		index[n.ForeignID] = i
+	}
+	duplicates := []string{}
+	for _, entry := range nodes {
+		i, exists := index[entry.node.ForeignID]
+		if !exists {
+			index[entry.node.ForeignID] = len(requisition.Nodes)
+			requisition.AddNode(&entry.node)
+			continue
+		}
+		if !merge {
+			duplicates = append(duplicates, fmt.Sprintf("Line %d: node %s already exists on requisition %s", entry.line, entry.node.ForeignID, requisition.Name))
+			continue
+		}
+		if err := requisition.Nodes[i].Merge(entry.node); err != nil {
+			return fmt.Errorf("Line %d: cannot merge node %s: %w", entry.line, entry.node.ForeignID, err)
+		}
+	}
+	if len(duplicates) > 0 {
+		for _, d := range duplicates {
+			common.Log.Errorf("%s", d)
+		}
+		return fmt.Errorf("%d nodes already exist, use --merge to update them", len(duplicates))
+	}
+	return nil
+}
+
+// csvNode a requisition node parsed from a given line of a CSV file
+type csvNode struct {
+	line int
+	node model.RequisitionNode
+}
+
+// Parses and validates the nodes from a CSV with a header row; it returns all the problems found instead of stopping on the first one
+func parseNodesCSV(input io.Reader) ([]csvNode, []string) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, []string{fmt.Sprintf("Line 1: cannot read CSV header: %s", err)}
+	}
+	columns := make(map[string]int)
+	for i, h := range header {
+		for _, col := range CSVColumns {
+			if strings.EqualFold(strings.TrimSpace(h), col) {
+				columns[col] = i
+			}
+		}
+	}
+	if _, ok := columns["foreignID"]; !ok {
+		return nil, []string{"Line 1: the foreignID column is required; valid columns: " + strings.Join(CSVColumns, ", ")}
+	}
+	nodes := []csvNode{}
+	problems := []string{}
+	seen := make(map[string]int)
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Line %d: %s", line, err))
+			continue
+		}
+		node, err := buildNodeFromCSV(record, columns)
+		if err == nil {
+			err = node.Validate()
+		}
+		if err == nil && seen[node.ForeignID] > 0 {
+			err = fmt.Errorf("duplicate foreign ID %s (first seen on line %d)", node.ForeignID, seen[node.ForeignID])
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Line %d: %s", line, err))
+			continue
+		}
+		seen[node.ForeignID] = line
+		nodes = append(nodes, csvNode{line, *node})
+	}
+	return nodes, problems
+}
+
+func buildNodeFromCSV(record []string, columns map[string]int) (*model.RequisitionNode, error) {
+	get := func(col string) string {
+		if i, ok := columns[col]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	node := &model.RequisitionNode{
+		ForeignID: get("foreignID"),
+		NodeLabel: get("label"),
+		Location:  get("location"),
+	}
+	for _, ip := range splitCSVList(get("ipAddress")) {
+		node.AddInterface(&model.RequisitionInterface{IPAddress: ip})
+	}
+	for _, cat := range splitCSVList(get("categories")) {
+		node.Categories = append(node.Categories, model.RequisitionCategory{Name: cat})
+	}
+	for _, asset := range splitCSVList(get("assets")) {
+		data := strings.SplitN(asset, "=", 2)
+		if len(data) != 2 {
+			return nil, fmt.Errorf("invalid asset %s, expected key=value", asset)
+		}
+		node.Assets = append(node.Assets, model.RequisitionAsset{Name: strings.TrimSpace(data[0]), Value: strings.TrimSpace(data[1])})
+	}
+	return node, nil
+}
+
+func splitCSVList(value string) []string {
+	list := []string{}
+	for _, v := range strings.Split(value, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
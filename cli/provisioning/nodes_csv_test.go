@@ -0,0 +1,85 @@
+package provisioning
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+const testCSV = `foreignID,label,ipAddress,location,categories,assets
+n3,Node 3,10.0.0.3,,Server;Production,city=Durham;state=NC
+n4,,10.0.0.4,Apex,,
+`
+
+func TestParseNodesCSV(t *testing.T) {
+	nodes, problems := parseNodesCSV(strings.NewReader(testCSV))
+	assert.Equal(t, 0, len(problems))
+	assert.Equal(t, 2, len(nodes))
+	n3 := nodes[0].node
+	assert.Equal(t, 2, nodes[0].line)
+	assert.Equal(t, "Node 3", n3.NodeLabel)
+	assert.Equal(t, "10.0.0.3", n3.Interfaces[0].IPAddress)
+	assert.Equal(t, 2, len(n3.Categories))
+	assert.Equal(t, "Production", n3.Categories[1].Name)
+	assert.Equal(t, 2, len(n3.Assets))
+	assert.Equal(t, "NC", n3.Assets[1].Value)
+	n4 := nodes[1].node
+	assert.Equal(t, "n4", n4.NodeLabel)
+	assert.Equal(t, "Apex", n4.Location)
+
+	invalid := `foreignID,label,ipAddress,assets
+n5,,10.0.0.5,
+,no-id,10.0.0.6,
+n7,,10.0.0.7,city
+n5,,10.0.0.8,
+n9,,10.0.0.9,state=NC
+`
+	nodes, problems = parseNodesCSV(strings.NewReader(invalid))
+	assert.Equal(t, 2, len(nodes))
+	assert.Equal(t, 3, len(problems))
+	assert.Assert(t, strings.HasPrefix(problems[0], "Line 3: Foreign ID cannot be empty"))
+	assert.Assert(t, strings.HasPrefix(problems[1], "Line 4: invalid asset city"))
+	assert.Assert(t, strings.HasPrefix(problems[2], "Line 5: duplicate foreign ID n5"))
+
+	_, problems = parseNodesCSV(strings.NewReader("label,ipAddress\n"))
+	assert.Equal(t, 1, len(problems))
+	assert.Assert(t, strings.Contains(problems[0], "foreignID column is required"))
+}
+
+func TestMergeCSVNodes(t *testing.T) {
+	req := &model.Requisition{Name: "Test", Nodes: []model.RequisitionNode{{ForeignID: "n1", NodeLabel: "n1"}}}
+	nodes := []csvNode{{2, model.RequisitionNode{ForeignID: "n1", NodeLabel: "Node 1"}}}
+	assert.ErrorContains(t, mergeCSVNodes(req, nodes, false), "use --merge")
+	assert.NilError(t, mergeCSVNodes(req, nodes, true))
+	assert.Equal(t, 1, len(req.Nodes))
+	assert.Equal(t, "Node 1", req.Nodes[0].NodeLabel)
+}
+
+func TestImportNodesFromCSV(t *testing.T) {
+	var err error
+	app := test.CreateCli(NodesCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "node", "import-csv"})
+	assert.Error(t, err, "Requisition name required")
+
+	err = app.Run([]string{app.Name, "node", "import-csv", "Test"})
+	assert.Error(t, err, "Content cannot be empty")
+
+	err = app.Run([]string{app.Name, "node", "import-csv", "Test", "foreignID\n,\n"})
+	assert.ErrorContains(t, err, "1 rows failed validation")
+
+	err = app.Run([]string{app.Name, "node", "import-csv", "--dry-run", "Test", testCSV})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "node", "import-csv", "Test", "foreignID,label\nn1,Node 1\n"})
+	assert.ErrorContains(t, err, "use --merge")
+
+	err = app.Run([]string{app.Name, "node", "import-csv", "--merge", "Test", testCSV})
+	assert.NilError(t, err)
+}
@@ -0,0 +1,221 @@
+package provisioning
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/snmpclient"
+	"github.com/urfave/cli"
+)
+
+// The characters that are not allowed on foreign IDs, replaced when the foreign ID is obtained from sysName
+var invalidForeignIDChars = regexp.MustCompile(`[/\\?:&*'"]`)
+
+// snmpGet obtains the values of the OIDs from an SNMP agent (replaced on tests)
+var snmpGet = func(client snmpclient.Client, oids ...string) (map[string]string, error) {
+	return client.Get(oids...)
+}
+
+// discoverCommand the CLI command to build a node from the system group of its SNMP agent
+var discoverCommand = cli.Command{
+	Name:         "discover",
+	Usage:        "Builds a node from the sysName, sysDescr, sysLocation and sysContact of its SNMP agent, prints it, and optionally adds it to a requisition",
+	ArgsUsage:    "<foreignSource> <ipAddress>",
+	Action:       discoverNodes,
+	BashComplete: requisitionNameBashComplete,
+	Description: "The SNMP requests are sent from the machine running onmsctl. The node label and foreign ID are taken from sysName (or the IP address), " +
+		"the building from sysLocation, and the description and admin assets from sysDescr and sysContact; the IP address is added as the primary interface with the SNMP and ICMP services.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "community, c",
+			Value: "public",
+			Usage: "Community String",
+		},
+		cli.GenericFlag{
+			Name:  "version, v",
+			Value: model.NewEnum(snmpclient.Versions...).WithDefault("v2c"),
+			Usage: "SNMP Version: " + strings.Join(snmpclient.Versions, ", "),
+		},
+		cli.IntFlag{
+			Name:  "port, p",
+			Value: 161,
+			Usage: "The UDP Port of the SNMP agent",
+		},
+		cli.DurationFlag{
+			Name:  "timeout, t",
+			Value: 2 * time.Second,
+			Usage: "Time to wait for each response",
+		},
+		cli.IntFlag{
+			Name:  "retries, r",
+			Value: 1,
+			Usage: "The number of retries before giving up",
+		},
+		cli.StringFlag{
+			Name:  "location, L",
+			Usage: "Node Location (when using Minion)",
+		},
+		cli.StringFlag{
+			Name:  "from-file",
+			Usage: "A file with an IP address per line (empty lines and lines starting with # are ignored), instead of the <ipAddress> argument",
+		},
+		cli.IntFlag{
+			Name:  "concurrency",
+			Value: 5,
+			Usage: "How many agents are queried at the same time with --from-file",
+		},
+		cli.BoolFlag{
+			Name:  "apply",
+			Usage: "Add the discovered nodes to the requisition, replacing the existing nodes with the same foreign ID",
+		},
+	},
+}
+
+func discoverNodes(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	if foreignSource == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	targets, err := getDiscoverTargets(c)
+	if err != nil {
+		return err
+	}
+	concurrency := c.Int("concurrency")
+	if concurrency < 1 {
+		return fmt.Errorf("Concurrency must be greater than zero")
+	}
+	client := snmpclient.Client{
+		Port:      c.Int("port"),
+		Community: c.String("community"),
+		Version:   c.String("version"),
+		Timeout:   c.Duration("timeout"),
+		Retries:   c.Int("retries"),
+	}
+	results := make([]*model.RequisitionNode, len(targets))
+	failures := make([]error, len(targets))
+	var wg sync.WaitGroup
+	pending := make(chan int)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				results[i], failures[i] = discoverNode(client, targets[i], c.String("location"))
+			}
+		}()
+	}
+	for i := range targets {
+		pending <- i
+	}
+	close(pending)
+	wg.Wait()
+
+	nodes := make([]model.RequisitionNode, 0, len(targets))
+	owners := make(map[string]string)
+	failed := 0
+	for i, node := range results {
+		if failures[i] == nil {
+			failures[i] = common.ValidationError(node.Validate())
+		}
+		if failures[i] == nil && owners[node.ForeignID] != "" {
+			failures[i] = fmt.Errorf("foreign ID %s is already used by %s", node.ForeignID, owners[node.ForeignID])
+		}
+		if failures[i] != nil && len(targets) == 1 {
+			return failures[i]
+		}
+		if failures[i] != nil {
+			common.Log.Errorf("%s: %s", targets[i], failures[i])
+			failed++
+			continue
+		}
+		owners[node.ForeignID] = targets[i]
+		nodes = append(nodes, *node)
+	}
+	if len(targets) == 1 {
+		err = common.Print(nodes[0], nil)
+	} else {
+		err = common.Print(nodes, nil)
+	}
+	if err != nil {
+		return err
+	}
+	if c.Bool("apply") && !common.DryRun {
+		for _, node := range nodes {
+			if err := getReqAPI(c).SetNode(foreignSource, node); err != nil {
+				return err
+			}
+			common.Log.Infof("Node %s added to requisition %s", node.ForeignID, foreignSource)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Cannot discover %d of %d targets", failed, len(targets))
+	}
+	return nil
+}
+
+// Returns the IP addresses from the argument or from --from-file
+func getDiscoverTargets(c *cli.Context) ([]string, error) {
+	file := c.String("from-file")
+	if file == "" {
+		if c.Args().Get(1) == "" {
+			return nil, fmt.Errorf("IP address or --from-file required")
+		}
+		return []string{c.Args().Get(1)}, nil
+	}
+	if c.Args().Get(1) != "" {
+		return nil, fmt.Errorf("Use either an IP address or --from-file, not both")
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			targets = append(targets, line)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("There are no IP addresses on %s", file)
+	}
+	return targets, nil
+}
+
+// Builds a node from the system group of the SNMP agent of the given address
+func discoverNode(client snmpclient.Client, address string, location string) (*model.RequisitionNode, error) {
+	client.Address = address
+	values, err := snmpGet(client, snmpclient.SysName, snmpclient.SysDescr, snmpclient.SysLocation, snmpclient.SysContact)
+	if err != nil {
+		return nil, err
+	}
+	label := strings.TrimSpace(values[snmpclient.SysName])
+	if label == "" {
+		label = address
+	}
+	node := &model.RequisitionNode{
+		ForeignID: invalidForeignIDChars.ReplaceAllString(label, "_"),
+		NodeLabel: label,
+		Location:  location,
+		Building:  strings.TrimSpace(values[snmpclient.SysLocation]),
+		Interfaces: []model.RequisitionInterface{{
+			IPAddress:   address,
+			SnmpPrimary: "P",
+			Status:      1,
+			Services:    []model.RequisitionMonitoredService{{Name: "ICMP"}, {Name: "SNMP"}},
+		}},
+	}
+	if description := strings.Join(strings.Fields(values[snmpclient.SysDescr]), " "); description != "" {
+		node.SetAsset("description", description)
+	}
+	if contact := strings.TrimSpace(values[snmpclient.SysContact]); contact != "" {
+		node.SetAsset("admin", contact)
+	}
+	return node, nil
+}
@@ -0,0 +1,125 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/snmpclient"
+	"github.com/OpenNMS/onmsctl/test"
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+// The system group of the agents used by the tests, by IP address; the other addresses don't respond
+var testAgents = map[string]map[string]string{
+	"10.0.0.1": {
+		snmpclient.SysName:     "rtr01.example.com",
+		snmpclient.SysDescr:    "Cisco IOS Software,\r\n  Version 15.1",
+		snmpclient.SysLocation: "Building A",
+		snmpclient.SysContact:  "noc@example.com",
+	},
+	"10.0.0.2": {
+		snmpclient.SysName: "sw/01",
+	},
+	"10.0.0.3": {},
+}
+
+func useTestAgents() func() {
+	snmpGet = func(client snmpclient.Client, oids ...string) (map[string]string, error) {
+		values, ok := testAgents[client.Address]
+		if !ok || client.Community != "public" {
+			return nil, fmt.Errorf("No response from the SNMP agent at %s:161", client.Address)
+		}
+		return values, nil
+	}
+	return func() {
+		snmpGet = func(client snmpclient.Client, oids ...string) (map[string]string, error) {
+			return client.Get(oids...)
+		}
+	}
+}
+
+func TestDiscoverNode(t *testing.T) {
+	defer useTestAgents()()
+	app := test.CreateCli(NodesCliCommand)
+
+	output, err := test.RunWithOutput(app, "yaml", "node", "discover", "Test", "10.0.0.1")
+	assert.NilError(t, err)
+	node := model.RequisitionNode{}
+	assert.NilError(t, yaml.Unmarshal([]byte(output), &node))
+	assert.Equal(t, "rtr01.example.com", node.ForeignID)
+	assert.Equal(t, "rtr01.example.com", node.NodeLabel)
+	assert.Equal(t, "Building A", node.Building)
+	assert.DeepEqual(t, []model.RequisitionAsset{
+		{Name: "description", Value: "Cisco IOS Software, Version 15.1"},
+		{Name: "admin", Value: "noc@example.com"},
+	}, node.Assets)
+	assert.Equal(t, 1, len(node.Interfaces))
+	assert.Equal(t, "10.0.0.1", node.Interfaces[0].IPAddress)
+	assert.Equal(t, "P", node.Interfaces[0].SnmpPrimary)
+	assert.Equal(t, 2, len(node.Interfaces[0].Services))
+
+	output, err = test.RunWithOutput(app, "yaml", "node", "discover", "Test", "10.0.0.3")
+	assert.NilError(t, err)
+	assert.NilError(t, yaml.Unmarshal([]byte(output), &node))
+	assert.Equal(t, "10.0.0.3", node.ForeignID)
+
+	_, err = test.RunWithOutput(app, "yaml", "node", "discover", "-c", "private", "Test", "10.0.0.1")
+	assert.Error(t, err, "No response from the SNMP agent at 10.0.0.1:161")
+
+	_, err = test.RunWithOutput(app, "yaml", "node", "discover", "Test")
+	assert.Error(t, err, "IP address or --from-file required")
+}
+
+func TestDiscoverNodesFromFile(t *testing.T) {
+	defer useTestAgents()()
+	posted := make([]model.RequisitionNode, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/rest/requisitionNames" {
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Test"}})
+			return
+		}
+		assert.Equal(t, "/rest/requisitions/Test/nodes", req.URL.Path)
+		assert.Equal(t, http.MethodPost, req.Method)
+		data, _ := ioutil.ReadAll(req.Body)
+		node := model.RequisitionNode{}
+		assert.NilError(t, json.Unmarshal(data, &node))
+		posted = append(posted, node)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	targets := filepath.Join(dir, "targets.txt")
+	assert.NilError(t, ioutil.WriteFile(targets, []byte("# Core\n10.0.0.1\n\n10.0.0.2\n10.0.0.9\n"), 0644))
+
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	app := test.CreateCli(NodesCliCommand, rest.WithURL(server.URL))
+	output, err := test.RunWithOutput(app, "json", "node", "discover", "--from-file", targets, "--concurrency", "2", "--apply", "Test")
+	assert.Error(t, err, "Cannot discover 1 of 3 targets")
+	nodes := []model.RequisitionNode{}
+	assert.NilError(t, json.Unmarshal([]byte(output), &nodes))
+	assert.Equal(t, 2, len(nodes))
+	assert.Equal(t, "rtr01.example.com", nodes[0].ForeignID)
+	assert.Equal(t, "sw_01", nodes[1].ForeignID)
+	assert.Equal(t, "sw/01", nodes[1].NodeLabel)
+	assert.Equal(t, 2, len(posted))
+	assert.Assert(t, bytes.Contains(logs.Bytes(), []byte("ERROR: 10.0.0.9: No response from the SNMP agent")), logs.String())
+
+	_, err = test.RunWithOutput(app, "json", "node", "discover", "--from-file", targets, "Test", "10.0.0.1")
+	assert.Error(t, err, "Use either an IP address or --from-file, not both")
+}
@@ -0,0 +1,142 @@
+package provisioning
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+// The comments added at the top of the file opened on the editor
+const editNodeHeader = `# Please edit the node below. Lines starting with '#' at the top of the file are ignored.
+# Saving an empty file or leaving it unchanged cancels the edition.
+#
+`
+
+// editNodeCommand the CLI command to edit a requisitioned node with the user's editor
+var editNodeCommand = cli.Command{
+	Name:  "edit",
+	Usage: "Edits a node from a given requisition with the editor from the EDITOR environment variable",
+	Description: "The node is validated when the file is saved; on failure, the editor is opened again with the error at the top of the file. " +
+		"The changes are displayed and must be confirmed before sending the node.",
+	ArgsUsage:    "<foreignSource> <foreignId>",
+	Action:       editNode,
+	BashComplete: foreignIDBashComplete,
+	Flags:        append([]cli.Flag{common.YesFlag, validateLocationsFlag}, importFlags()...),
+}
+
+func editNode(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	if err := common.EditorAvailable(); err != nil {
+		return fmt.Errorf("%s; use 'inv node get %s %s > node.yaml', and 'inv node apply -f node.yaml %s' after editing it", err, foreignSource, foreignID, foreignSource)
+	}
+	mode, err := getImportMode(c)
+	if err != nil {
+		return err
+	}
+	api := getReqAPI(c)
+	current, err := api.GetNode(foreignSource, foreignID)
+	if err != nil {
+		return err
+	}
+	original, err := yaml.Marshal(current)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile("", "onmsctl-node-*.yaml")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	content := string(original)
+	var lastError error
+	for {
+		edited, err := editContent(file.Name(), content, lastError)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(edited) == "" {
+			common.Log.Infof("Edit cancelled, the saved file was empty")
+			return nil
+		}
+		if edited == string(original) {
+			common.Log.Infof("Edit cancelled, no changes made")
+			return nil
+		}
+		if lastError != nil && edited == content {
+			return common.ValidationError(lastError) // The file was saved without fixing the problem
+		}
+		content = edited
+		node, err := parseEditedNode(c, edited, foreignID)
+		if err != nil {
+			lastError = err
+			continue
+		}
+		changes := current.Diff(*node)
+		if len(changes) == 0 {
+			common.Log.Infof("Edit cancelled, no changes made")
+			return nil
+		}
+		description := fmt.Sprintf("Node %s on requisition %s will be updated:", foreignID, foreignSource)
+		for _, change := range changes {
+			description += "\n    " + change.String()
+		}
+		if err := common.Confirm(c, description); err != nil {
+			return err
+		}
+		if err := api.SetNode(foreignSource, *node); err != nil {
+			return err
+		}
+		common.Log.Infof("Node %s has been updated with %d changes", foreignID, len(changes))
+		return importRequisitions(c, mode, foreignSource)
+	}
+}
+
+// Writes the content with the header (including the last error when present), runs the editor, and returns the content without the header
+func editContent(file string, content string, lastError error) (string, error) {
+	header := editNodeHeader
+	if lastError != nil {
+		header = "# Error: " + strings.Replace(lastError.Error(), "\n", "\n# ", -1) + "\n#\n" + header
+	}
+	if err := ioutil.WriteFile(file, []byte(header+content), 0600); err != nil {
+		return "", err
+	}
+	if err := common.RunEditor(file); err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	for len(lines) > 0 && strings.HasPrefix(lines[0], "#") {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// Parses and validates the edited node, which must keep its foreign ID
+func parseEditedNode(c *cli.Context, content string, foreignID string) (*model.RequisitionNode, error) {
+	node := &model.RequisitionNode{}
+	if err := yaml.Unmarshal([]byte(content), node); err != nil {
+		return nil, fmt.Errorf("Invalid YAML: %w", err)
+	}
+	if node.ForeignID != foreignID {
+		return nil, fmt.Errorf("The foreign ID cannot be changed from %s to %s; use 'inv node move' with --new-foreign-id instead", foreignID, node.ForeignID)
+	}
+	if err := node.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkLocation(c, node.Location); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
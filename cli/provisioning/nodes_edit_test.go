@@ -0,0 +1,120 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+// Replaces the editor with functions that receive the content of the file and return the saved content
+func mockEditor(t *testing.T, edits ...func(content string) string) func() {
+	available, run := common.EditorAvailable, common.RunEditor
+	common.EditorAvailable = func() error { return nil }
+	common.RunEditor = func(file string) error {
+		assert.Assert(t, len(edits) > 0, "the editor was opened too many times")
+		data, err := ioutil.ReadFile(file)
+		assert.NilError(t, err)
+		content := edits[0](string(data))
+		edits = edits[1:]
+		return ioutil.WriteFile(file, []byte(content), 0600)
+	}
+	return func() {
+		common.EditorAvailable, common.RunEditor = available, run
+		assert.Equal(t, 0, len(edits), "the editor was not opened enough times")
+	}
+}
+
+func TestEditNode(t *testing.T) {
+	var received []model.RequisitionNode
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Test"}})
+		case "/rest/requisitions/Test/nodes/n1":
+			sendData(res, testNode)
+		case "/rest/requisitions/Test/nodes":
+			assert.Equal(t, http.MethodPost, req.Method)
+			node := model.RequisitionNode{}
+			data, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(data, &node))
+			received = append(received, node)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	app := test.CreateCli(NodesCliCommand, rest.WithURL(server.URL))
+	confirmation := &bytes.Buffer{}
+	common.ConfirmOutput = confirmation
+	defer func() { common.ConfirmInput, common.ConfirmOutput = nil, os.Stderr }()
+
+	// Invalid changes re-open the editor with the error, keeping the changes
+	restore := mockEditor(t,
+		func(content string) string {
+			assert.Assert(t, strings.HasPrefix(content, editNodeHeader))
+			content = strings.Replace(content, "nodeLabel: n1", "nodeLabel: web01", 1)
+			return strings.Replace(content, "snmpPrimary: P", "snmpPrimary: X", 1)
+		},
+		func(content string) string {
+			assert.Assert(t, strings.HasPrefix(content, "# Error: Invalid snmp-primary for interface 10.0.0.1: X\n"))
+			assert.Assert(t, strings.Contains(content, "nodeLabel: web01"))
+			return strings.Replace(content, "snmpPrimary: X", "snmpPrimary: S", 1)
+		},
+	)
+	common.ConfirmInput = strings.NewReader("y\n")
+	err := app.Run([]string{app.Name, "node", "edit", "Test", "n1"})
+	assert.NilError(t, err)
+	restore()
+	assert.Assert(t, strings.Contains(confirmation.String(), "~ node-label: n1 -> web01\n    ~ interface 10.0.0.1 snmp-primary: P -> S\n"))
+	assert.Equal(t, 1, len(received))
+	assert.Equal(t, "web01", received[0].NodeLabel)
+	assert.Equal(t, "S", received[0].Interfaces[0].SnmpPrimary)
+	assert.Equal(t, "Durham", received[0].Assets[0].Value)
+
+	// Declining the confirmation doesn't send the node
+	restore = mockEditor(t, func(content string) string {
+		return strings.Replace(content, "nodeLabel: n1", "nodeLabel: web01", 1)
+	})
+	common.ConfirmInput = strings.NewReader("n\n")
+	err = app.Run([]string{app.Name, "node", "edit", "Test", "n1"})
+	restore()
+	assert.Error(t, err, "Operation cancelled")
+
+	// Leaving the file unchanged, or saving an empty file, cancels the edition
+	restore = mockEditor(t, func(content string) string { return content })
+	assert.NilError(t, app.Run([]string{app.Name, "node", "edit", "Test", "n1"}))
+	restore()
+	restore = mockEditor(t, func(content string) string { return "" })
+	assert.NilError(t, app.Run([]string{app.Name, "node", "edit", "Test", "n1"}))
+	restore()
+
+	// Saving the file without fixing the error stops the edition
+	restore = mockEditor(t,
+		func(content string) string {
+			return strings.Replace(content, "foreignID: n1", "foreignID: n2", 1)
+		},
+		func(content string) string { return content },
+	)
+	err = app.Run([]string{app.Name, "node", "edit", "Test", "n1"})
+	restore()
+	assert.ErrorContains(t, err, "The foreign ID cannot be changed from n1 to n2")
+	assert.Equal(t, 1, len(received))
+}
+
+func TestEditNodeWithoutTerminal(t *testing.T) {
+	app := test.CreateCli(NodesCliCommand)
+	err := app.Run([]string{app.Name, "node", "edit", "Test", "n1"}) // The output of the tests is not a terminal
+	assert.Error(t, err, "Interactive editing requires a terminal; use 'inv node get Test n1 > node.yaml', and 'inv node apply -f node.yaml Test' after editing it")
+}
@@ -0,0 +1,182 @@
+package provisioning
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/geo"
+	"github.com/urfave/cli"
+)
+
+// The asset fields of a postal address, in the order they appear on the --address flag
+var addressAssets = []string{"address1", "city", "state", "zip", "country"}
+
+// setLocationAssetsCommand the CLI command to set the address and coordinates assets of a node
+var setLocationAssetsCommand = cli.Command{
+	Name:         "set-location-assets",
+	Usage:        "Sets the address assets of a node, and optionally its latitude and longitude, merged with the existing assets",
+	ArgsUsage:    "<foreignSource> <foreignId>",
+	Action:       setLocationAssets,
+	BashComplete: foreignIDBashComplete,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "address, a",
+			Usage: "The postal address as 'street, city, state zip, country' (e.x. '220 Chatham Business Dr, Pittsboro, NC 27312, US'); trailing parts can be omitted",
+		},
+		cli.StringFlag{
+			Name:  "address1",
+			Usage: "The street address, overriding the one from --address",
+		},
+		cli.StringFlag{
+			Name:  "city",
+			Usage: "The city, overriding the one from --address",
+		},
+		cli.StringFlag{
+			Name:  "state",
+			Usage: "The state, overriding the one from --address",
+		},
+		cli.StringFlag{
+			Name:  "zip",
+			Usage: "The zip code, overriding the one from --address",
+		},
+		cli.StringFlag{
+			Name:  "country",
+			Usage: "The country, overriding the one from --address",
+		},
+		cli.Float64Flag{
+			Name:  "lat",
+			Usage: "The latitude in decimal degrees, between -90 and 90",
+		},
+		cli.Float64Flag{
+			Name:  "lon",
+			Usage: "The longitude in decimal degrees, between -180 and 180",
+		},
+		cli.BoolFlag{
+			Name:  "geocode, g",
+			Usage: "Obtain the latitude and longitude from the address through the geocoder",
+		},
+		cli.StringFlag{
+			Name:   "geocoder-url",
+			Value:  geo.DefaultNominatimURL,
+			EnvVar: "ONMSCTL_GEOCODER_URL",
+			Usage:  "The search endpoint of the Nominatim server used by --geocode",
+		},
+	},
+}
+
+func setLocationAssets(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	foreignID := c.Args().Get(1)
+	address, err := parseAddress(c.String("address"))
+	if err != nil {
+		return err
+	}
+	for _, name := range addressAssets {
+		if value := strings.TrimSpace(c.String(name)); value != "" {
+			address[name] = value
+		}
+	}
+	coordinates, err := getCoordinates(c, address)
+	if err != nil {
+		return err
+	}
+	if len(address) == 0 && coordinates == nil {
+		return fmt.Errorf("Either an address, --lat and --lon, or both are required")
+	}
+	node, err := getReqAPI(c).GetNode(foreignSource, foreignID)
+	if err != nil {
+		return err
+	}
+	changed := make([]string, 0)
+	for _, name := range addressAssets {
+		if value, ok := address[name]; ok && node.SetAsset(name, value) {
+			changed = append(changed, name)
+		}
+	}
+	if coordinates != nil {
+		if node.SetAsset("latitude", coordinates.LatitudeString()) {
+			changed = append(changed, "latitude")
+		}
+		if node.SetAsset("longitude", coordinates.LongitudeString()) {
+			changed = append(changed, "longitude")
+		}
+	}
+	if len(changed) == 0 {
+		common.Log.Infof("Node %s already has the given assets", foreignID)
+		return nil
+	}
+	common.Log.Infof("Node %s, changed: %s", foreignID, formatNames(changed))
+	return common.Apply(node, func() error {
+		return getReqAPI(c).SetNode(foreignSource, *node)
+	})
+}
+
+// Returns the coordinates from --lat and --lon or from the geocoder, or nil when none of them were requested
+func getCoordinates(c *cli.Context, address map[string]string) (*geo.Coordinates, error) {
+	manual := c.IsSet("lat") || c.IsSet("lon")
+	if manual && c.Bool("geocode") {
+		return nil, fmt.Errorf("Use either --geocode or --lat and --lon, not both")
+	}
+	if manual {
+		if !c.IsSet("lat") || !c.IsSet("lon") {
+			return nil, fmt.Errorf("Both --lat and --lon are required")
+		}
+		coordinates := &geo.Coordinates{Latitude: c.Float64("lat"), Longitude: c.Float64("lon")}
+		return coordinates, coordinates.Validate()
+	}
+	if !c.Bool("geocode") {
+		return nil, nil
+	}
+	parts := make([]string, 0)
+	for _, name := range addressAssets {
+		if value, ok := address[name]; ok {
+			parts = append(parts, value)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("An address is required to use --geocode")
+	}
+	return getGeocoder(c).Geocode(strings.Join(parts, ", "))
+}
+
+func getGeocoder(c *cli.Context) geo.Geocoder {
+	return geo.NominatimGeocoder{
+		URL:       c.String("geocoder-url"),
+		UserAgent: "onmsctl/" + c.App.Version,
+		Timeout:   time.Duration(common.GetClient(c).Timeout) * time.Second,
+	}
+}
+
+// Parses an address like 'street, city, state zip, country' into its asset fields; a last word with digits on the third part is the zip code
+func parseAddress(text string) (map[string]string, error) {
+	address := make(map[string]string)
+	if strings.TrimSpace(text) == "" {
+		return address, nil
+	}
+	parts := strings.Split(text, ",")
+	if len(parts) > 4 {
+		return nil, fmt.Errorf("Invalid address %s, expected 'street, city, state zip, country'", text)
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	names := []string{"address1", "city", "state", "country"}
+	for i, value := range parts {
+		if value != "" {
+			address[names[i]] = value
+		}
+	}
+	if len(parts) > 2 {
+		fields := strings.Fields(parts[2])
+		if last := len(fields) - 1; last >= 0 && strings.ContainsAny(fields[last], "0123456789") {
+			address["zip"] = fields[last]
+			delete(address, "state")
+			if last > 0 {
+				address["state"] = strings.Join(fields[:last], " ")
+			}
+		}
+	}
+	return address, nil
+}
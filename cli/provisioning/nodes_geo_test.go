@@ -0,0 +1,87 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestParseAddress(t *testing.T) {
+	address, err := parseAddress("220 Chatham Business Dr, Pittsboro, North Carolina 27312, US")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]string{"address1": "220 Chatham Business Dr", "city": "Pittsboro", "state": "North Carolina", "zip": "27312", "country": "US"}, address)
+
+	address, err = parseAddress("1 Main St, Durham, NC")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]string{"address1": "1 Main St", "city": "Durham", "state": "NC"}, address)
+
+	_, err = parseAddress("a, b, c, d, e")
+	assert.ErrorContains(t, err, "expected 'street, city, state zip, country'")
+}
+
+func TestSetLocationAssets(t *testing.T) {
+	var posted *model.RequisitionNode
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Net"}})
+		case req.URL.Path == "/rest/requisitions/Net/nodes/n1":
+			sendData(res, model.RequisitionNode{
+				ForeignID: "n1",
+				NodeLabel: "n1",
+				Assets:    []model.RequisitionAsset{{Name: "city", Value: "Durham"}, {Name: "description", Value: "Core"}, {Name: "city", Value: "Apex"}},
+			})
+		case req.URL.Path == "/rest/requisitions/Net/nodes" && req.Method == http.MethodPost:
+			posted = &model.RequisitionNode{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, posted))
+		case req.URL.Path == "/search":
+			assert.Equal(t, "1 Main St, Pittsboro, NC, 27312", req.URL.Query().Get("q"))
+			res.Write([]byte(`[{"lat":"35.72","lon":"-79.17"}]`))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	app := test.CreateCli(NodesCliCommand, rest.WithURL(server.URL))
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "node", "set-location-assets", "Net", "n1", "--address", "1 Main St, Pittsboro, NC 27312", "--geocode", "--geocoder-url", server.URL+"/search")
+	assert.NilError(t, err)
+	assert.Equal(t, "Node n1, changed: address1 city state zip latitude longitude\n", logs.String())
+	assert.DeepEqual(t, []model.RequisitionAsset{
+		{Name: "city", Value: "Pittsboro"},
+		{Name: "description", Value: "Core"},
+		{Name: "address1", Value: "1 Main St"},
+		{Name: "state", Value: "NC"},
+		{Name: "zip", Value: "27312"},
+		{Name: "latitude", Value: "35.72"},
+		{Name: "longitude", Value: "-79.17"},
+	}, posted.Assets)
+
+	posted = nil
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "node", "set-location-assets", "Net", "n1", "--lat", "35.5", "--lon", "-78.25", "--city", "Raleigh")
+	assert.NilError(t, err)
+	assert.Equal(t, "Node n1, changed: city latitude longitude\n", logs.String())
+	assert.Equal(t, 4, len(posted.Assets))
+
+	_, err = test.RunWithOutput(app, "table", "node", "set-location-assets", "Net", "n1", "--lat", "95", "--lon", "0")
+	assert.Error(t, err, "Invalid latitude 95, it must be between -90 and 90")
+	_, err = test.RunWithOutput(app, "table", "node", "set-location-assets", "Net", "n1", "--lat", "35")
+	assert.Error(t, err, "Both --lat and --lon are required")
+	_, err = test.RunWithOutput(app, "table", "node", "set-location-assets", "Net", "n1", "--lat", "35", "--lon", "0", "--geocode")
+	assert.Error(t, err, "Use either --geocode or --lat and --lon, not both")
+	_, err = test.RunWithOutput(app, "table", "node", "set-location-assets", "Net", "n1", "--geocode")
+	assert.Error(t, err, "An address is required to use --geocode")
+	_, err = test.RunWithOutput(app, "table", "node", "set-location-assets", "Net", "n1")
+	assert.Error(t, err, "Either an address, --lat and --lon, or both are required")
+}
@@ -0,0 +1,120 @@
+package provisioning
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// normalizeLabelsCommand the CLI command to normalize the labels of the nodes of a requisition
+var normalizeLabelsCommand = cli.Command{
+	Name:  "normalize-labels",
+	Usage: "Rewrites the labels of the nodes of a given requisition with a consistent format, after confirmation",
+	Description: "The strategies are lower (lowercase), short (without the domain) and fqdn (appending --domain to short names);\n" +
+		"   labels that are IP addresses are not changed by short and fqdn. The changes are previewed, and a warning is displayed\n" +
+		"   when nodes would end with the same label. Only the modified nodes are sent.",
+	ArgsUsage:    "<foreignSource>",
+	Action:       normalizeNodeLabels,
+	BashComplete: requisitionNameBashComplete,
+	Flags: append([]cli.Flag{
+		cli.GenericFlag{
+			Name:  "strategy, s",
+			Value: model.NewEnum(model.LabelStrategies...),
+			Usage: "How to rewrite the labels: " + strings.Join(model.LabelStrategies, ", "),
+		},
+		cli.StringFlag{
+			Name:  "domain, d",
+			Usage: "The domain appended by fqdn; with short, only this domain is removed (e.x. example.com)",
+		},
+		cli.StringFlag{
+			Name:  "match, m",
+			Usage: "Only nodes whose current label matches the pattern, case insensitive (e.x. 'sw-*')",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Show the changes without sending them to the server",
+		},
+		common.YesFlag,
+	}, importFlags()...),
+}
+
+func normalizeNodeLabels(c *cli.Context) error {
+	foreignSource := c.Args().First()
+	if foreignSource == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	opts := model.LabelNormalization{Strategy: common.EnumFlag(c, "strategy"), Domain: c.String("domain")}
+	if opts.Strategy == "" {
+		return fmt.Errorf("Strategy required, valid options: %s", strings.Join(model.LabelStrategies, ", "))
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	matches := func(label string) bool { return true }
+	if pattern := c.String("match"); pattern != "" {
+		matcher, err := getMatcher(pattern, false)
+		if err != nil {
+			return err
+		}
+		matches = matcher
+	}
+	mode, err := getImportMode(c)
+	if err != nil {
+		return err
+	}
+	requisition, err := getReqAPI(c).GetRequisition(foreignSource)
+	if err != nil {
+		return err
+	}
+	updated := *requisition
+	updated.Nodes = make([]model.RequisitionNode, len(requisition.Nodes))
+	nodes := make([]model.RequisitionNode, 0)
+	table := common.NewTable("", "Foreign ID", "Current Label", "New Label")
+	for i, node := range requisition.Nodes {
+		current := node.NodeLabel
+		if matches(current) && node.NormalizeLabel(opts) {
+			table.AddRow(node.ForeignID, current, node.NodeLabel)
+			nodes = append(nodes, node)
+		}
+		updated.Nodes[i] = node
+	}
+	if len(nodes) == 0 {
+		common.Log.Infof("The labels of the nodes on requisition %s are already normalized", foreignSource)
+		return nil
+	}
+	if err := common.Print(nodes, table); err != nil {
+		return err
+	}
+	warnLabelCollisions(requisition.LabelCollisions(), updated.LabelCollisions())
+	if c.Bool("dry-run") || common.DryRun {
+		return nil
+	}
+	if err := common.Confirm(c, fmt.Sprintf("The labels of %d of %d nodes of requisition %s will be changed", len(nodes), len(requisition.Nodes), foreignSource)); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if err := getReqAPI(c).SetNode(foreignSource, node); err != nil {
+			return fmt.Errorf("Cannot update node %s: %w", node.ForeignID, err)
+		}
+	}
+	common.Log.Infof("Labels of %d nodes normalized on requisition %s", len(nodes), foreignSource)
+	return importRequisitions(c, mode, foreignSource)
+}
+
+// Warns about the labels that more nodes would share after the normalization than before
+func warnLabelCollisions(before map[string][]string, after map[string][]string) {
+	labels := make([]string, 0)
+	for label, foreignIDs := range after {
+		if len(foreignIDs) > len(before[label]) {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		common.Log.Warnf("Nodes %s would have the same label %s", strings.Join(after[label], ", "), label)
+	}
+}
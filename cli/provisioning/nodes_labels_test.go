@@ -0,0 +1,88 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestNormalizeNodeLabels(t *testing.T) {
+	posted := []model.RequisitionNode{}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Net"}})
+		case req.URL.Path == "/rest/requisitions/Net" && req.Method == http.MethodGet:
+			sendData(res, model.Requisition{
+				Name: "Net",
+				Nodes: []model.RequisitionNode{
+					{ForeignID: "n1", NodeLabel: "srv01.example.com"},
+					{ForeignID: "n2", NodeLabel: "SRV02"},
+					{ForeignID: "n3", NodeLabel: "srv01"},
+					{ForeignID: "n4", NodeLabel: "rtr01.example.com"},
+					{ForeignID: "n5", NodeLabel: "10.0.0.1"},
+				},
+			})
+		case req.URL.Path == "/rest/requisitions/Net/nodes" && req.Method == http.MethodPost:
+			node := model.RequisitionNode{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, &node))
+			posted = append(posted, node)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() {
+		common.Log.Output = os.Stderr
+		common.ConfirmInput = nil
+	}()
+
+	app := test.CreateCli(NodesCliCommand, rest.WithURL(server.URL))
+
+	_, err := test.RunWithOutput(app, "table", "node", "normalize-labels", "Net")
+	assert.Error(t, err, "Strategy required, valid options: lower, short, fqdn")
+	_, err = test.RunWithOutput(app, "table", "node", "normalize-labels", "--strategy", "fqdn", "Net")
+	assert.Error(t, err, "The domain is required by the fqdn strategy")
+
+	// The collision introduced by the new labels is only a warning
+	output, err := test.RunWithOutput(app, "table", "node", "normalize-labels", "--strategy", "short", "--dry-run", "Net")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[1], "n1          srv01.example.com  srv01"), lines[1])
+	assert.Assert(t, strings.HasPrefix(lines[2], "n4          rtr01.example.com  rtr01"), lines[2])
+	assert.Equal(t, "WARNING: Nodes n1, n3 would have the same label srv01\n", logs.String())
+	assert.Equal(t, 0, len(posted))
+
+	common.ConfirmInput = strings.NewReader("n\n")
+	_, err = test.RunWithOutput(app, "table", "node", "normalize-labels", "--strategy", "lower", "Net")
+	assert.Error(t, err, "Operation cancelled")
+	assert.Equal(t, 0, len(posted))
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "node", "normalize-labels", "--strategy", "fqdn", "--domain", "example.com", "--match", "srv*", "--yes", "Net")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(logs.String(), "Labels of 2 nodes normalized on requisition Net\n"))
+	assert.Equal(t, 2, len(posted))
+	assert.Equal(t, "SRV02.example.com", posted[0].NodeLabel)
+	assert.Equal(t, "srv01.example.com", posted[1].NodeLabel)
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "node", "normalize-labels", "--strategy", "short", "--match", "10.*", "Net")
+	assert.NilError(t, err)
+	assert.Equal(t, "The labels of the nodes on requisition Net are already normalized\n", logs.String())
+}
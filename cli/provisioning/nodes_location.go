@@ -0,0 +1,162 @@
+package provisioning
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// setLocationCommand the CLI command to change the location of multiple nodes of a requisition
+var setLocationCommand = cli.Command{
+	Name:         "set-location",
+	Usage:        "Changes the location of the nodes of a given requisition that match all the criteria, after confirmation",
+	ArgsUsage:    "<foreignSource>",
+	Action:       setNodesLocation,
+	BashComplete: requisitionNameBashComplete,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "location, L",
+			Usage: "The new location for the nodes",
+		},
+		cli.StringFlag{
+			Name:  "match-label",
+			Usage: "Only nodes whose label matches the pattern, case insensitive (e.x. 'sw-*')",
+		},
+		cli.StringFlag{
+			Name:  "match-category",
+			Usage: "Only nodes with the given category",
+		},
+		cli.StringFlag{
+			Name:  "match-ip-cidr",
+			Usage: "Only nodes with at least one IP interface on the given CIDR block (e.x. 10.1.0.0/16)",
+		},
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "Change the location of all the nodes, instead of using the match flags",
+		},
+		cli.BoolFlag{
+			Name:  "skip-location-check",
+			Usage: "Don't verify that the location exists on the server",
+		},
+		cli.BoolFlag{
+			Name:  "import",
+			Usage: "Import the requisition after updating the nodes",
+		},
+		common.YesFlag,
+	},
+}
+
+func setNodesLocation(c *cli.Context) error {
+	foreignSource := c.Args().First()
+	if foreignSource == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	location := strings.TrimSpace(c.String("location"))
+	if location == "" {
+		return fmt.Errorf("Location required")
+	}
+	matches, err := buildNodeMatcher(c)
+	if err != nil {
+		return err
+	}
+	if !c.Bool("skip-location-check") && location != "Default" {
+		exists, err := getLocationsAPI(c).LocationExists(location)
+		if err != nil {
+			return fmt.Errorf("Cannot verify location %s: %w", location, err)
+		}
+		if !exists {
+			return common.ValidationError(fmt.Errorf("Location %s doesn't exist; use --skip-location-check to use it anyway", location))
+		}
+	}
+	requisition, err := getReqAPI(c).GetRequisition(foreignSource)
+	if err != nil {
+		return err
+	}
+	nodes := make([]model.RequisitionNode, 0)
+	table := common.NewTable("", "Foreign ID", "Label", "Current Location", "New Location")
+	for _, node := range requisition.Nodes {
+		if !matches(node) || node.Location == location {
+			continue
+		}
+		current := node.Location
+		if current == "" {
+			current = "Default"
+		}
+		table.AddRow(node.ForeignID, node.NodeLabel, current, location)
+		node.Location = location
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		common.Log.Infof("There are no nodes to update on requisition %s", foreignSource)
+		return nil
+	}
+	if err := common.Print(nodes, table); err != nil {
+		return err
+	}
+	if err := common.Confirm(c, fmt.Sprintf("The location of %d of %d nodes of requisition %s will be changed to %s", len(nodes), len(requisition.Nodes), foreignSource, location)); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if err := getReqAPI(c).SetNode(foreignSource, node); err != nil {
+			return fmt.Errorf("Cannot update node %s: %w", node.ForeignID, err)
+		}
+	}
+	common.Log.Infof("Location of %d nodes changed to %s on requisition %s", len(nodes), location, foreignSource)
+	if c.Bool("import") {
+		return getReqAPI(c).ImportRequisition(foreignSource, model.RescanAll)
+	}
+	return nil
+}
+
+// Builds a function that returns true when a node matches all the criteria from the match flags
+func buildNodeMatcher(c *cli.Context) (func(node model.RequisitionNode) bool, error) {
+	criteria := make([]func(node model.RequisitionNode) bool, 0)
+	if pattern := c.String("match-label"); pattern != "" {
+		matchLabel, err := getMatcher(pattern, false)
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, func(node model.RequisitionNode) bool {
+			return matchLabel(node.NodeLabel)
+		})
+	}
+	if category := c.String("match-category"); category != "" {
+		criteria = append(criteria, func(node model.RequisitionNode) bool {
+			for _, cat := range node.Categories {
+				if strings.EqualFold(cat.Name, category) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if cidr := c.String("match-ip-cidr"); cidr != "" {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CIDR block %s", cidr)
+		}
+		criteria = append(criteria, func(node model.RequisitionNode) bool {
+			for _, intf := range node.Interfaces {
+				if ip := net.ParseIP(intf.IPAddress); ip != nil && network.Contains(ip) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if c.Bool("all") == (len(criteria) > 0) {
+		return nil, fmt.Errorf("Either --all or at least one of --match-label, --match-category or --match-ip-cidr is required")
+	}
+	return func(node model.RequisitionNode) bool {
+		for _, matches := range criteria {
+			if !matches(node) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -0,0 +1,88 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestSetNodesLocation(t *testing.T) {
+	posted := []model.RequisitionNode{}
+	imports := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/api/v2/monitoringLocations":
+			res.Write([]byte(`{"count":1,"totalCount":1,"offset":0,"location":[{"location-name":"SiteA"}]}`))
+		case req.URL.Path == "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Net"}})
+		case req.URL.Path == "/rest/requisitions/Net" && req.Method == http.MethodGet:
+			sendData(res, model.Requisition{
+				Name: "Net",
+				Nodes: []model.RequisitionNode{
+					{ForeignID: "n1", NodeLabel: "sw-01", Interfaces: []model.RequisitionInterface{{IPAddress: "10.1.0.1"}}},
+					{ForeignID: "n2", NodeLabel: "SW-02", Location: "SiteB", Interfaces: []model.RequisitionInterface{{IPAddress: "10.2.0.1"}}, Categories: []model.RequisitionCategory{{Name: "Core"}}},
+					{ForeignID: "n3", NodeLabel: "rtr-01", Interfaces: []model.RequisitionInterface{{IPAddress: "10.1.0.254"}}, Categories: []model.RequisitionCategory{{Name: "Core"}}},
+					{ForeignID: "n4", NodeLabel: "sw-03", Location: "SiteA"},
+				},
+			})
+		case req.URL.Path == "/rest/requisitions/Net/nodes" && req.Method == http.MethodPost:
+			node := model.RequisitionNode{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, &node))
+			posted = append(posted, node)
+		case req.URL.Path == "/rest/requisitions/Net/import":
+			imports++
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer func() { common.ConfirmInput = nil }()
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	app := test.CreateCli(NodesCliCommand, rest.WithURL(server.URL))
+
+	_, err := test.RunWithOutput(app, "table", "node", "set-location", "--location", "SiteA", "Net")
+	assert.ErrorContains(t, err, "Either --all or at least one of")
+	_, err = test.RunWithOutput(app, "table", "node", "set-location", "--location", "SiteA", "--all", "--match-label", "sw-*", "Net")
+	assert.ErrorContains(t, err, "Either --all or at least one of")
+	_, err = test.RunWithOutput(app, "table", "node", "set-location", "--location", "SiteC", "--all", "Net")
+	assert.Error(t, err, "Location SiteC doesn't exist; use --skip-location-check to use it anyway")
+
+	common.ConfirmInput = strings.NewReader("n\n")
+	output, err := test.RunWithOutput(app, "table", "node", "set-location", "--location", "SiteA", "--match-label", "sw-*", "Net")
+	assert.Error(t, err, "Operation cancelled")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[1], "n1          sw-01  Default           SiteA"), lines[1])
+	assert.Assert(t, strings.HasPrefix(lines[2], "n2          SW-02  SiteB             SiteA"), lines[2])
+	assert.Equal(t, 0, len(posted))
+
+	_, err = test.RunWithOutput(app, "table", "node", "set-location", "--location", "SiteA", "--match-category", "core", "--match-ip-cidr", "10.1.0.0/16", "--yes", "--import", "Net")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(logs.String(), "Location of 1 nodes changed to SiteA on requisition Net\n"))
+	assert.Equal(t, 1, len(posted))
+	assert.Equal(t, "n3", posted[0].ForeignID)
+	assert.Equal(t, "SiteA", posted[0].Location)
+	assert.Equal(t, 1, imports)
+
+	posted = posted[:0]
+	_, err = test.RunWithOutput(app, "table", "node", "set-location", "--location", "SiteC", "--skip-location-check", "--all", "-y", "Net")
+	assert.NilError(t, err)
+	assert.Equal(t, 4, len(posted))
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "node", "set-location", "--location", "SiteA", "--match-ip-cidr", "192.168.0.0/24", "Net")
+	assert.NilError(t, err)
+	assert.Equal(t, "There are no nodes to update on requisition Net\n", logs.String())
+}
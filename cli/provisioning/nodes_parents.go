@@ -0,0 +1,220 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// The columns of the CSV files with the parents of the nodes, as displayed on errors
+const parentsCSVColumns = "child-foreign-id,parent-foreign-id[,parent-foreign-source]"
+
+// setParentsCommand the CLI command to set the parents of multiple nodes of a requisition from a CSV file
+var setParentsCommand = cli.Command{
+	Name:  "set-parents",
+	Usage: "Sets the parents of the nodes of a given requisition from a CSV file, to model the topology for path outages",
+	Description: "Each row has " + parentsCSVColumns + "; without a parent foreign source, the parent belongs to the same requisition,\n" +
+		"   and an empty parent foreign ID removes the parent of the node. A header row starting with child-foreign-id is optional.\n" +
+		"   The parents must exist, and cycles are rejected before sending anything; only the modified nodes are sent, after confirmation.",
+	ArgsUsage:    "<foreignSource> <csv>",
+	Action:       setNodeParents,
+	BashComplete: requisitionNameBashComplete,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "file, f",
+			Usage: "External CSV file (use '-' for STDIN Pipe)",
+		},
+		common.YesFlag,
+	}, importFlags()...),
+}
+
+// parentRow the parent of a node from a given line of a CSV file
+type parentRow struct {
+	line                int
+	foreignID           string
+	parentForeignID     string
+	parentForeignSource string
+}
+
+func setNodeParents(c *cli.Context) error {
+	foreignSource := c.Args().Get(0)
+	if foreignSource == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	mode, err := getImportMode(c)
+	if err != nil {
+		return err
+	}
+	data, err := common.ReadInput(c, 1)
+	if err != nil {
+		return err
+	}
+	rows, problems := parseParentsCSV(bytes.NewReader(data))
+	if len(problems) > 0 {
+		return reportParentProblems(problems)
+	}
+	api := getReqAPI(c)
+	requisition, err := api.GetRequisition(foreignSource)
+	if err != nil {
+		return err
+	}
+	others := make(map[string]*model.Requisition)
+	getRequisition := func(name string) (*model.Requisition, error) {
+		if name == "" || name == foreignSource {
+			return requisition, nil
+		}
+		if r, ok := others[name]; ok {
+			return r, nil
+		}
+		if !getUtilsAPI(c).RequisitionExists(name) {
+			return nil, fmt.Errorf("requisition %s doesn't exist", name)
+		}
+		r, err := api.GetRequisition(name)
+		if err != nil {
+			return nil, err
+		}
+		others[name] = r
+		return r, nil
+	}
+
+	updated := *requisition
+	updated.Nodes = append([]model.RequisitionNode{}, requisition.Nodes...)
+	modified := []string{}
+	table := common.NewTable("", "Foreign ID", "Label", "Current Parent", "New Parent")
+	for _, row := range rows {
+		node := updated.GetNode(row.foreignID)
+		if node == nil {
+			problems = append(problems, fmt.Sprintf("Line %d: node %s doesn't exist on requisition %s", row.line, row.foreignID, foreignSource))
+			continue
+		}
+		if row.parentForeignID != "" {
+			parents, err := getRequisition(row.parentForeignSource)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("Line %d: cannot verify the parent of node %s: %s", row.line, row.foreignID, err))
+				continue
+			}
+			if parents.GetNode(row.parentForeignID) == nil {
+				problems = append(problems, fmt.Sprintf("Line %d: parent %s of node %s doesn't exist on requisition %s", row.line, row.parentForeignID, row.foreignID, parents.Name))
+				continue
+			}
+		}
+		current := describeParent(*node, foreignSource)
+		node.ParentForeignSource = row.parentForeignSource
+		node.ParentForeignID = row.parentForeignID
+		node.ParentNodeLabel = ""
+		if err := node.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("Line %d: %s", row.line, err))
+			continue
+		}
+		if next := describeParent(*node, foreignSource); next != current {
+			modified = append(modified, node.ForeignID)
+			table.AddRow(node.ForeignID, node.NodeLabel, current, next)
+		}
+	}
+	if len(problems) > 0 {
+		return reportParentProblems(problems)
+	}
+
+	// The cycles may go through the parents on other requisitions, so all of them are verified together
+	set := []model.Requisition{updated}
+	for _, r := range others {
+		set = append(set, *r)
+	}
+	if cycle := model.ParentCycle(set...); cycle != nil {
+		return common.ValidationError(fmt.Errorf("The parents of the nodes would form a cycle: %s; nothing has been sent", strings.Join(cycle, " -> ")))
+	}
+
+	if len(modified) == 0 {
+		common.Log.Infof("The parents of the nodes on requisition %s are up to date", foreignSource)
+		return nil
+	}
+	nodes := make([]model.RequisitionNode, len(modified))
+	for i, foreignID := range modified {
+		nodes[i] = *updated.GetNode(foreignID)
+	}
+	if err := common.Print(nodes, table); err != nil {
+		return err
+	}
+	if common.DryRun {
+		return nil
+	}
+	if err := common.Confirm(c, fmt.Sprintf("The parents of %d of %d nodes of requisition %s will be changed", len(nodes), len(requisition.Nodes), foreignSource)); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if err := api.SetNode(foreignSource, node); err != nil {
+			return fmt.Errorf("Cannot update node %s: %w", node.ForeignID, err)
+		}
+	}
+	common.Log.Infof("Parents of %d nodes changed on requisition %s", len(nodes), foreignSource)
+	return importRequisitions(c, mode, foreignSource)
+}
+
+// Parses the rows of a CSV file with the parents of the nodes; it returns all the problems found instead of stopping on the first one
+func parseParentsCSV(input io.Reader) ([]parentRow, []string) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows := []parentRow{}
+	problems := []string{}
+	seen := make(map[string]int)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Line %d: %s", line, err))
+			continue
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "child-foreign-id") {
+			continue
+		}
+		if len(record) < 2 || len(record) > 3 {
+			problems = append(problems, fmt.Sprintf("Line %d: expected %s, found %d columns", line, parentsCSVColumns, len(record)))
+			continue
+		}
+		row := parentRow{line: line, foreignID: strings.TrimSpace(record[0]), parentForeignID: strings.TrimSpace(record[1])}
+		if len(record) == 3 {
+			row.parentForeignSource = strings.TrimSpace(record[2])
+		}
+		switch {
+		case row.foreignID == "":
+			problems = append(problems, fmt.Sprintf("Line %d: the child foreign ID cannot be empty", line))
+		case row.parentForeignID == "" && row.parentForeignSource != "":
+			problems = append(problems, fmt.Sprintf("Line %d: the parent foreign source of node %s requires a parent foreign ID", line, row.foreignID))
+		case seen[row.foreignID] > 0:
+			problems = append(problems, fmt.Sprintf("Line %d: duplicate node %s (first seen on line %d)", line, row.foreignID, seen[row.foreignID]))
+		default:
+			seen[row.foreignID] = line
+			rows = append(rows, row)
+		}
+	}
+	return rows, problems
+}
+
+func reportParentProblems(problems []string) error {
+	for _, p := range problems {
+		common.Log.Errorf("%s", p)
+	}
+	return common.ValidationError(fmt.Errorf("%d rows failed validation, nothing has been sent", len(problems)))
+}
+
+// Returns the parent of the node, with its requisition when it belongs to another one
+func describeParent(node model.RequisitionNode, foreignSource string) string {
+	switch {
+	case node.ParentForeignID != "" && (node.ParentForeignSource == "" || node.ParentForeignSource == foreignSource):
+		return node.ParentForeignID
+	case node.ParentForeignID != "":
+		return model.NodeCriteria(node.ParentForeignSource, node.ParentForeignID)
+	case node.ParentNodeLabel != "":
+		return node.ParentNodeLabel + " (label)"
+	}
+	return "-"
+}
@@ -0,0 +1,129 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestParseParentsCSV(t *testing.T) {
+	rows, problems := parseParentsCSV(strings.NewReader("child-foreign-id,parent-foreign-id,parent-foreign-source\nsw1,rtr1\nsw2, isp1, Providers\nsw3,\n"))
+	assert.Equal(t, 0, len(problems))
+	expected := []parentRow{
+		{line: 2, foreignID: "sw1", parentForeignID: "rtr1"},
+		{line: 3, foreignID: "sw2", parentForeignID: "isp1", parentForeignSource: "Providers"},
+		{line: 4, foreignID: "sw3"},
+	}
+	assert.Equal(t, len(expected), len(rows))
+	for i, row := range rows {
+		assert.Equal(t, expected[i].line, row.line)
+		assert.Equal(t, expected[i].foreignID, row.foreignID)
+		assert.Equal(t, expected[i].parentForeignID, row.parentForeignID)
+		assert.Equal(t, expected[i].parentForeignSource, row.parentForeignSource)
+	}
+
+	_, problems = parseParentsCSV(strings.NewReader("sw1\nsw1,rtr1\n,rtr1\nsw2,,Providers\nsw1,rtr2\nsw3,rtr1,Net,x\n"))
+	assert.DeepEqual(t, []string{
+		"Line 1: expected child-foreign-id,parent-foreign-id[,parent-foreign-source], found 1 columns",
+		"Line 3: the child foreign ID cannot be empty",
+		"Line 4: the parent foreign source of node sw2 requires a parent foreign ID",
+		"Line 5: duplicate node sw1 (first seen on line 2)",
+		"Line 6: expected child-foreign-id,parent-foreign-id[,parent-foreign-source], found 4 columns",
+	}, problems)
+}
+
+func TestSetNodeParents(t *testing.T) {
+	posted := []model.RequisitionNode{}
+	imports := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 2, ForeignSources: []string{"Net", "Providers"}})
+		case req.URL.Path == "/rest/requisitions/Net" && req.Method == http.MethodGet:
+			sendData(res, model.Requisition{
+				Name: "Net",
+				Nodes: []model.RequisitionNode{
+					{ForeignID: "rtr1", NodeLabel: "rtr1", ParentForeignSource: "Providers", ParentForeignID: "isp1"},
+					{ForeignID: "sw1", NodeLabel: "sw1"},
+					{ForeignID: "sw2", NodeLabel: "sw2", ParentForeignID: "rtr1"},
+					{ForeignID: "sw3", NodeLabel: "sw3", ParentNodeLabel: "sw2"},
+				},
+			})
+		case req.URL.Path == "/rest/requisitions/Providers" && req.Method == http.MethodGet:
+			sendData(res, model.Requisition{
+				Name:  "Providers",
+				Nodes: []model.RequisitionNode{{ForeignID: "isp1", NodeLabel: "isp1"}, {ForeignID: "isp2", NodeLabel: "isp2", ParentForeignSource: "Net", ParentForeignID: "sw1"}},
+			})
+		case req.URL.Path == "/rest/requisitions/Net/nodes" && req.Method == http.MethodPost:
+			node := model.RequisitionNode{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, &node))
+			posted = append(posted, node)
+		case req.URL.Path == "/rest/requisitions/Net/import":
+			imports++
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer func() { common.ConfirmInput = nil }()
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	app := test.CreateCli(NodesCliCommand, rest.WithURL(server.URL))
+
+	_, err := test.RunWithOutput(app, "table", "node", "set-parents", "Net", "sw1,rtr9\nsw9,rtr1\nsw2,isp9,Providers\nsw3,x,Unknown")
+	assert.Error(t, err, "4 rows failed validation, nothing has been sent")
+	assert.Equal(t, `ERROR: Line 1: parent rtr9 of node sw1 doesn't exist on requisition Net
+ERROR: Line 2: node sw9 doesn't exist on requisition Net
+ERROR: Line 3: parent isp9 of node sw2 doesn't exist on requisition Providers
+ERROR: Line 4: cannot verify the parent of node sw3: requisition Unknown doesn't exist
+`, logs.String())
+
+	// A 3-node cycle within the requisition
+	_, err = test.RunWithOutput(app, "table", "node", "set-parents", "Net", "rtr1,sw3\nsw3,sw2")
+	assert.Error(t, err, "The parents of the nodes would form a cycle: Net:rtr1 -> Net:sw3 -> Net:sw2 -> Net:rtr1; nothing has been sent")
+
+	// A cycle through another requisition
+	_, err = test.RunWithOutput(app, "table", "node", "set-parents", "Net", "sw1,isp2,Providers")
+	assert.Error(t, err, "The parents of the nodes would form a cycle: Net:sw1 -> Providers:isp2 -> Net:sw1; nothing has been sent")
+	assert.Equal(t, 0, len(posted))
+
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "node", "set-parents", "Net", "rtr1,isp1,Providers\nsw2,rtr1,Net")
+	assert.NilError(t, err)
+	assert.Equal(t, "The parents of the nodes on requisition Net are up to date\n", logs.String())
+
+	common.ConfirmInput = strings.NewReader("n\n")
+	output, err := test.RunWithOutput(app, "table", "node", "set-parents", "Net", "child-foreign-id,parent-foreign-id\nsw1,rtr1\nsw2,rtr1\nsw3,sw2\nrtr1,")
+	assert.Error(t, err, "Operation cancelled")
+	assert.Equal(t, `Foreign ID  Label  Current Parent  New Parent
+sw1         sw1    -               rtr1
+sw3         sw3    sw2 (label)     sw2
+rtr1        rtr1   Providers:isp1  -
+`, output)
+	assert.Equal(t, 0, len(posted))
+
+	_, err = test.RunWithOutput(app, "table", "node", "set-parents", "--yes", "--import", "Net", "sw1,rtr1\nsw2,rtr1\nsw3,sw2\nrtr1,")
+	assert.NilError(t, err)
+	assert.Equal(t, 3, len(posted))
+	assert.Equal(t, "sw1", posted[0].ForeignID)
+	assert.Equal(t, "rtr1", posted[0].ParentForeignID)
+	assert.Equal(t, "sw2", posted[1].ParentForeignID)
+	assert.Equal(t, "", posted[1].ParentNodeLabel)
+	assert.Equal(t, "", posted[2].ParentForeignSource)
+	assert.Equal(t, "", posted[2].ParentForeignID)
+	assert.Equal(t, 1, imports)
+}
@@ -1,8 +1,10 @@
 package provisioning
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/test"
 	"gopkg.in/yaml.v2"
@@ -13,6 +15,7 @@ func TestListNodes(t *testing.T) {
 	var err error
 	app := test.CreateCli(NodesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "node", "list"})
@@ -26,6 +29,7 @@ func TestGetNode(t *testing.T) {
 	var err error
 	app := test.CreateCli(NodesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "node", "get"})
@@ -42,6 +46,7 @@ func TestAddNode(t *testing.T) {
 	var err error
 	app := test.CreateCli(NodesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "node", "add"})
@@ -52,12 +57,50 @@ func TestAddNode(t *testing.T) {
 
 	err = app.Run([]string{app.Name, "node", "add", "Test", "n2"})
 	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "node", "add", "--validate-locations", "-L", "Apex", "Test", "n2"})
+	assert.Error(t, err, "Location Apex doesn't exist")
+
+	err = app.Run([]string{app.Name, "node", "add", "--validate-locations", "-L", "Durham", "Test", "n2"})
+	assert.NilError(t, err)
+}
+
+func TestAddNodeWithImport(t *testing.T) {
+	var err error
+	app := test.CreateCli(NodesCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	lastTestImport = ""
+	err = app.Run([]string{app.Name, "node", "add", "--no-import", "Test", "n2"})
+	assert.NilError(t, err)
+	assert.Equal(t, "", lastTestImport)
+
+	err = app.Run([]string{app.Name, "node", "add", "--import", "Test", "n2"})
+	assert.NilError(t, err)
+	assert.Equal(t, "dbonly", lastTestImport)
+
+	err = app.Run([]string{app.Name, "node", "add", "--import=rescan", "Test", "n2"})
+	assert.NilError(t, err)
+	assert.Equal(t, "true", lastTestImport)
+
+	err = app.Run([]string{app.Name, "node", "apply", "--import=no-rescan", "Test", "foreignID: n2\nnodeLabel: n2"})
+	assert.NilError(t, err)
+	assert.Equal(t, "false", lastTestImport)
+
+	err = app.Run([]string{app.Name, "node", "add", "--import", "--no-import", "Test", "n2"})
+	assert.Error(t, err, "--import and --no-import cannot be used together")
+
+	err = app.Run([]string{app.Name, "node", "add", "--import=full", "Test", "n2"})
+	assert.ErrorContains(t, err, "allowed values are dbonly, rescan, no-rescan")
 }
 
 func TestDeleteNode(t *testing.T) {
 	var err error
 	app := test.CreateCli(NodesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "node", "delete"})
@@ -66,14 +109,25 @@ func TestDeleteNode(t *testing.T) {
 	err = app.Run([]string{app.Name, "node", "delete", "Test"})
 	assert.Error(t, err, "Foreign ID required")
 
+	defer func() { common.ConfirmInput = nil }()
+	common.ConfirmInput = strings.NewReader("n\n")
+	err = app.Run([]string{app.Name, "node", "delete", "Test", "n2"})
+	assert.Error(t, err, "Operation cancelled")
+
+	common.ConfirmInput = strings.NewReader("y\n")
 	err = app.Run([]string{app.Name, "node", "delete", "Test", "n2"})
 	assert.NilError(t, err)
+
+	common.ConfirmInput = strings.NewReader("")
+	err = app.Run([]string{app.Name, "node", "delete", "--yes", "Test", "n2"})
+	assert.NilError(t, err)
 }
 
 func TestApplyNode(t *testing.T) {
 	var err error
 	app := test.CreateCli(NodesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "node", "apply"})
@@ -95,3 +149,40 @@ func TestApplyNode(t *testing.T) {
 	err = app.Run([]string{app.Name, "node", "apply", "Test", string(nodeYaml)})
 	assert.NilError(t, err)
 }
+
+func TestCopyNode(t *testing.T) {
+	var err error
+	app := test.CreateCli(NodesCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "node", "copy", "Test", "n1"})
+	assert.Error(t, err, "Destination requisition name required")
+
+	err = app.Run([]string{app.Name, "node", "copy", "Test", "n1", "Test"})
+	assert.Error(t, err, "The source and the destination of node n1 are the same")
+
+	err = app.Run([]string{app.Name, "node", "copy", "Test", "n1", "Test", "--new-foreign-id", "n4"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "node", "copy", "Test", "n1", "Local", "--new-foreign-id", "n3"})
+	assert.Error(t, err, "Node n3 already exists on requisition Local, use --force to overwrite it")
+
+	err = app.Run([]string{app.Name, "node", "copy", "--force", "--new-foreign-id", "n3", "Test", "n1", "Local"})
+	assert.NilError(t, err)
+}
+
+func TestMoveNode(t *testing.T) {
+	var err error
+	app := test.CreateCli(NodesCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "node", "move", "Test", "n1", "Go"})
+	assert.Error(t, err, "Requisition Go doesn't exist")
+
+	err = app.Run([]string{app.Name, "node", "move", "Test", "n1", "Local"})
+	assert.NilError(t, err)
+}
@@ -0,0 +1,362 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+type outputTestCase struct {
+	args     []string
+	format   string
+	expected string
+}
+
+func runOutputTests(t *testing.T, url string, cases []outputTestCase) {
+	for _, tc := range cases {
+		app := test.CreateCli(CliCommand, rest.WithURL(url))
+		output, err := test.RunWithOutput(app, tc.format, append([]string{"inv"}, tc.args...)...)
+		assert.NilError(t, err, tc.args)
+		assert.Equal(t, tc.expected, output, "%v with %s", tc.args, tc.format)
+	}
+}
+
+func TestListRequisitionsOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 2, ForeignSources: []string{"Test", "Local"}})
+		case "/rest/requisitions/deployed/stats":
+			sendData(res, model.RequisitionsStats{
+				Count:          1,
+				ForeignSources: []model.RequisitionStats{{Name: "Test", Count: 2, ForeignIDs: []string{"n1", "n2"}}},
+			})
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	runOutputTests(t, server.URL, []outputTestCase{
+		{[]string{"req", "list"}, "table", `Requisition  Nodes in DB  Last Import
+Test         2            Never
+Local        0            Never
+`},
+		{[]string{"req", "list"}, "yaml", `count: 2
+foreignSources:
+- name: Test
+  count: 2
+  foreignID:
+  - n1
+  - n2
+- name: Local
+  count: 0
+  foreignID: []
+`},
+		{[]string{"req", "list"}, "json", `{
+  "count": 2,
+  "foreign-source": [
+    {
+      "name": "Test",
+      "count": 2,
+      "foreign-id": [
+        "n1",
+        "n2"
+      ]
+    },
+    {
+      "name": "Local",
+      "count": 0,
+      "foreign-id": []
+    }
+  ]
+}
+`},
+		{[]string{"req", "list"}, "jsonpath=$.foreign-source[*].name", "Test\nLocal\n"},
+	})
+}
+
+func TestListNodesOutput(t *testing.T) {
+	server := createTestServer(t)
+	defer server.Close()
+
+	runOutputTests(t, server.URL, []outputTestCase{
+		{[]string{"node", "list", "Test"}, "table", `Foreign ID  Label  Location  Interfaces  Assets  Categories
+n1          n1     Default   1           1       1
+`},
+		{[]string{"node", "list", "Test"}, "yaml", `- nodeLabel: n1
+  foreignID: n1
+  interfaces:
+  - ipAddress: 10.0.0.1
+    snmpPrimary: P
+    status: 0
+    services:
+    - name: HTTP
+      metaData:
+      - key: url
+        value: /index.html
+    metaData:
+    - key: mpls
+      value: "false"
+  categories:
+  - name: Server
+  assets:
+  - name: city
+    value: Durham
+  metaData:
+  - key: owner
+    value: agalue
+`},
+		{[]string{"node", "list", "Test"}, "json", `[
+  {
+    "node-label": "n1",
+    "foreign-id": "n1",
+    "interface": [
+      {
+        "ip-addr": "10.0.0.1",
+        "snmp-primary": "P",
+        "status": 0,
+        "monitored-service": [
+          {
+            "service-name": "HTTP",
+            "meta-data": [
+              {
+                "key": "url",
+                "value": "/index.html"
+              }
+            ]
+          }
+        ],
+        "meta-data": [
+          {
+            "key": "mpls",
+            "value": "false"
+          }
+        ]
+      }
+    ],
+    "category": [
+      {
+        "name": "Server"
+      }
+    ],
+    "asset": [
+      {
+        "name": "city",
+        "value": "Durham"
+      }
+    ],
+    "meta-data": [
+      {
+        "key": "owner",
+        "value": "agalue"
+      }
+    ]
+  }
+]
+`},
+		{[]string{"node", "list", "Test"}, "jsonpath=$[0].interface[*].ip-addr", "10.0.0.1\n"},
+		{[]string{"node", "get", "Test", "n1"}, "jsonpath=$.category", "[{\"name\":\"Server\"}]\n"},
+	})
+}
+
+func TestListInterfacesOutput(t *testing.T) {
+	server := createTestServer(t)
+	defer server.Close()
+
+	runOutputTests(t, server.URL, []outputTestCase{
+		{[]string{"intf", "list", "Test", "n1"}, "table", `IP Address  Description  SNMP Primary  Services
+10.0.0.1    N/A          P             1
+`},
+		{[]string{"intf", "list", "Test", "n1"}, "yaml", `- ipAddress: 10.0.0.1
+  snmpPrimary: P
+  status: 0
+  services:
+  - name: HTTP
+    metaData:
+    - key: url
+      value: /index.html
+  metaData:
+  - key: mpls
+    value: "false"
+`},
+		{[]string{"intf", "list", "Test", "n1"}, "json", `[
+  {
+    "ip-addr": "10.0.0.1",
+    "snmp-primary": "P",
+    "status": 0,
+    "monitored-service": [
+      {
+        "service-name": "HTTP",
+        "meta-data": [
+          {
+            "key": "url",
+            "value": "/index.html"
+          }
+        ]
+      }
+    ],
+    "meta-data": [
+      {
+        "key": "mpls",
+        "value": "false"
+      }
+    ]
+  }
+]
+`},
+		{[]string{"intf", "list", "Test", "n1"}, "jsonpath=$..service-name", "HTTP\n"},
+		{[]string{"intf", "get", "Test", "n1", "10.0.0.1"}, "jsonpath=$['snmp-primary']", "P\n"},
+	})
+}
+
+func TestListNodeElementsOutput(t *testing.T) {
+	server := createTestServer(t)
+	defer server.Close()
+
+	runOutputTests(t, server.URL, []outputTestCase{
+		{[]string{"svc", "list", "Test", "n1", "10.0.0.1"}, "table", "Service Name\nHTTP\n"},
+		{[]string{"svc", "list", "Test", "n1", "10.0.0.1"}, "yaml", "- name: HTTP\n  metaData:\n  - key: url\n    value: /index.html\n"},
+		{[]string{"svc", "list", "Test", "n1", "10.0.0.1"}, "json", `[
+  {
+    "service-name": "HTTP",
+    "meta-data": [
+      {
+        "key": "url",
+        "value": "/index.html"
+      }
+    ]
+  }
+]
+`},
+		{[]string{"svc", "list", "Test", "n1", "10.0.0.1"}, "jsonpath=$[*].service-name", "HTTP\n"},
+
+		{[]string{"cat", "list", "Test", "n1"}, "table", "Category Name\nServer\n"},
+		{[]string{"cat", "list", "Test", "n1"}, "yaml", "- name: Server\n"},
+		{[]string{"cat", "list", "Test", "n1"}, "json", "[\n  {\n    \"name\": \"Server\"\n  }\n]\n"},
+		{[]string{"cat", "list", "Test", "n1"}, "jsonpath=$[*].name", "Server\n"},
+
+		{[]string{"asset", "list", "Test", "n1"}, "table", "Asset Name  Asset Value\ncity        Durham\n"},
+		{[]string{"asset", "list", "Test", "n1"}, "yaml", "- name: city\n  value: Durham\n"},
+		{[]string{"asset", "list", "Test", "n1"}, "json", "[\n  {\n    \"name\": \"city\",\n    \"value\": \"Durham\"\n  }\n]\n"},
+		{[]string{"asset", "list", "Test", "n1"}, "jsonpath=$[0].value", "Durham\n"},
+
+		{[]string{"meta", "list", "-i", "10.0.0.1", "Test", "n1"}, "table", "Context  Key   Value\n         mpls  false\n"},
+		{[]string{"meta", "list", "-i", "10.0.0.1", "Test", "n1"}, "yaml", "- key: mpls\n  value: \"false\"\n"},
+		{[]string{"meta", "list", "-i", "10.0.0.1", "Test", "n1"}, "json", "[\n  {\n    \"key\": \"mpls\",\n    \"value\": \"false\"\n  }\n]\n"},
+		{[]string{"meta", "list", "-i", "10.0.0.1", "Test", "n1"}, "jsonpath=$[*].key", "mpls\n"},
+	})
+}
+
+func TestListPluginsOutput(t *testing.T) {
+	server := createTestServer(t)
+	defer server.Close()
+
+	runOutputTests(t, server.URL, []outputTestCase{
+		{[]string{"detector", "list", "Test"}, "table", `Detector Name  Detector Class
+ICMP           org.opennms.netmgt.provision.detector.icmp.IcmpDetector
+`},
+		{[]string{"detector", "list", "Test"}, "yaml", `- name: ICMP
+  class: org.opennms.netmgt.provision.detector.icmp.IcmpDetector
+`},
+		{[]string{"detector", "list", "Test"}, "json", `[
+  {
+    "name": "ICMP",
+    "class": "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"
+  }
+]
+`},
+		{[]string{"detector", "list", "Test"}, "jsonpath=$[*].class", "org.opennms.netmgt.provision.detector.icmp.IcmpDetector\n"},
+
+		{[]string{"policy", "list", "Test"}, "table", `Policy Name  Policy Class
+Production   org.opennms.netmgt.provision.persist.policies.NodeCategorySettingPolicy
+`},
+		{[]string{"policy", "list", "Test"}, "yaml", `- name: Production
+  class: org.opennms.netmgt.provision.persist.policies.NodeCategorySettingPolicy
+  parameters:
+  - key: category
+    value: Production
+  - key: matchBehavior
+    value: NO_PARAMETERS
+`},
+		{[]string{"policy", "list", "Test"}, "json", `[
+  {
+    "name": "Production",
+    "class": "org.opennms.netmgt.provision.persist.policies.NodeCategorySettingPolicy",
+    "parameter": [
+      {
+        "key": "category",
+        "value": "Production"
+      },
+      {
+        "key": "matchBehavior",
+        "value": "NO_PARAMETERS"
+      }
+    ]
+  }
+]
+`},
+		{[]string{"policy", "list", "Test"}, "jsonpath=$[0].parameter[-1].value", "NO_PARAMETERS\n"},
+	})
+}
+
+// The debug and informational messages must go to stderr, so the JSON output can still be parsed
+func TestJSONOutputWithWarnings(t *testing.T) {
+	var logs bytes.Buffer
+	resolverLog := model.Resolver.Log
+	common.Log.Output = &logs
+	common.Log.Level = common.LevelDebug
+	model.Resolver.Log = common.Log
+	defer func() {
+		common.Log.Output = os.Stderr
+		common.Log.Level = common.LevelInfo
+		model.Resolver.Log = resolverLog
+	}()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls == 1 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Test"}})
+		case "/rest/requisitions/deployed/stats":
+			sendData(res, model.RequisitionsStats{Count: 0})
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL), rest.WithRetries(1, time.Millisecond))
+	output, err := test.RunWithOutput(app, "json", "inv", "req", "list")
+	assert.NilError(t, err)
+	var stats model.RequisitionsStats
+	assert.NilError(t, json.Unmarshal([]byte(output), &stats), output)
+	assert.Equal(t, "Test", stats.ForeignSources[0].Name)
+	assert.Assert(t, strings.HasPrefix(logs.String(), "DEBUG: GET /rest/requisitionNames failed on attempt 1"), logs.String())
+
+	logs.Reset()
+	common.Log.Level = common.LevelInfo
+	common.DryRun = true
+	defer func() { common.DryRun = false }()
+	output, err = test.RunWithOutput(app, "json", "inv", "node", "apply", "Test", "foreignID: n2\nlabel: n2\ninterfaces:\n- ipAddress: www.opennms.com\n")
+	assert.NilError(t, err)
+	var node model.RequisitionNode
+	assert.NilError(t, json.Unmarshal([]byte(output), &node), output)
+	assert.Equal(t, "34.194.50.139", node.Interfaces[0].IPAddress)
+	assert.Equal(t, "www.opennms.com translates to 34.194.50.139.\n", logs.String())
+}
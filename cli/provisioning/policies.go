@@ -1,14 +1,11 @@
 package provisioning
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/urfave/cli"
-
-	"gopkg.in/yaml.v2"
 )
 
 // PoliciesCliCommand the CLI command configuration for managing foreign source detectors
@@ -47,6 +44,7 @@ var PoliciesCliCommand = cli.Command{
 		},
 		{
 			Name:         "set",
+			Aliases:      []string{"add"},
 			Usage:        "Adds or update a policy for a given foreign source definition, overriding any existing content",
 			ArgsUsage:    "<foreignSource> <policyName> <className>",
 			Action:       setPolicy,
@@ -56,6 +54,7 @@ var PoliciesCliCommand = cli.Command{
 					Name:  "parameter, p",
 					Usage: "A policy parameter (e.x. -p 'matchBehavior=ALL_PARAMETERS')",
 				},
+				skipValidationFlag,
 			},
 		},
 		{
@@ -68,6 +67,7 @@ var PoliciesCliCommand = cli.Command{
 					Name:  "file, f",
 					Usage: "External YAML file (use '-' for STDIN Pipe)",
 				},
+				skipValidationFlag,
 			},
 			ArgsUsage: "<foreignSource> <yaml>",
 		},
@@ -83,55 +83,43 @@ var PoliciesCliCommand = cli.Command{
 }
 
 func listPolicies(c *cli.Context) error {
-	fsDef, err := getFsAPI().GetForeignSourceDef(c.Args().Get(0))
+	fsDef, err := getFsAPI(c).GetForeignSourceDef(c.Args().Get(0))
 	if err != nil {
 		return err
 	}
-	if len(fsDef.Policies) == 0 {
-		fmt.Println("There are no policies on the chosen foreign source definition")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Policy Name\tPolicy Class")
+	table := common.NewTable("There are no policies on the chosen foreign source definition", "Policy Name", "Policy Class")
 	for _, policy := range fsDef.Policies {
-		fmt.Fprintf(writer, "%s\t%s\n", policy.Name, policy.Class)
+		table.AddRow(policy.Name, policy.Class)
 	}
-	writer.Flush()
-	return nil
+	return common.Print(fsDef.Policies, table)
 }
 
 func enumeratePolicyClasses(c *cli.Context) error {
-	policies, err := getUtilsAPI().GetAvailablePolicies()
+	policies, err := getUtilsAPI(c).GetAvailablePolicies()
 	if err != nil {
 		return err
 	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Policy Name\tPolicy Class")
+	table := common.NewTable("", "Policy Name", "Policy Class")
 	for _, plugin := range policies.Plugins {
-		fmt.Fprintf(writer, "%s\t%s\n", plugin.Name, plugin.Class)
+		table.AddRow(plugin.Name, plugin.Class)
 	}
-	writer.Flush()
-	return nil
+	return common.Print(policies.Plugins, table)
 }
 
 func describePolicyClass(c *cli.Context) error {
-	plugin, err := getFsAPI().GetPolicyConfig(c.Args().Get(0))
+	plugin, err := getFsAPI(c).GetPolicyConfig(c.Args().Get(0))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(plugin)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(plugin, nil)
 }
 
 func getPolicy(c *cli.Context) error {
-	detector, err := getFsAPI().GetPolicy(c.Args().Get(0), c.Args().Get(1))
+	policy, err := getFsAPI(c).GetPolicy(c.Args().Get(0), c.Args().Get(1))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(detector)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(policy, nil)
 }
 
 func setPolicy(c *cli.Context) error {
@@ -142,7 +130,7 @@ func setPolicy(c *cli.Context) error {
 		param := model.Parameter{Key: data[0], Value: data[1]}
 		policy.Parameters = append(policy.Parameters, param)
 	}
-	return getFsAPI().SetPolicy(c.Args().Get(0), policy)
+	return getValidatingFsAPI(c).SetPolicy(c.Args().Get(0), policy)
 }
 
 func applyPolicy(c *cli.Context) error {
@@ -150,27 +138,25 @@ func applyPolicy(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	policy := model.Policy{}
-	err = yaml.Unmarshal(data, &policy)
-	if err != nil {
-		return err
-	}
-	return getFsAPI().SetPolicy(c.Args().Get(0), policy)
+	policy := &model.Policy{}
+	return common.ApplyYAML(data, policy, func() error {
+		return getValidatingFsAPI(c).SetPolicy(c.Args().Get(0), *policy)
+	})
 }
 
 func deletePolicy(c *cli.Context) error {
-	return getFsAPI().DeletePolicy(c.Args().Get(0), c.Args().Get(1))
+	return getFsAPI(c).DeletePolicy(c.Args().Get(0), c.Args().Get(1))
 }
 
 func policyBashComplete(c *cli.Context) {
 	requisitionNameBashComplete(c)
 	if c.NArg() == 1 {
-		fs, err := getFsAPI().GetForeignSourceDef(c.Args().Get(0))
+		fs, err := getFsAPI(c).GetForeignSourceDef(c.Args().Get(0))
 		if err != nil {
 			return
 		}
 		for _, d := range fs.Policies {
-			fmt.Println(zshNormalize(d.Name))
+			common.PrintCompletions(d.Name)
 		}
 	}
 }
@@ -178,12 +164,12 @@ func policyBashComplete(c *cli.Context) {
 func policyClassBashComplete(c *cli.Context) {
 	requisitionNameBashComplete(c)
 	if c.NArg() == 1 {
-		cfg, err := getUtilsAPI().GetAvailablePolicies()
+		cfg, err := getUtilsAPI(c).GetAvailablePolicies()
 		if err != nil {
 			return
 		}
 		for _, p := range cfg.Plugins {
-			fmt.Println(zshNormalize(p.Class))
+			common.PrintCompletions(p.Class)
 		}
 	}
 }
@@ -13,6 +13,7 @@ func TestEnumPolicies(t *testing.T) {
 	var err error
 	app := test.CreateCli(PoliciesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "policy", "enum"})
@@ -23,6 +24,7 @@ func TestDescribePolicy(t *testing.T) {
 	var err error
 	app := test.CreateCli(PoliciesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "policy", "desc"})
@@ -36,6 +38,7 @@ func TestListPolicy(t *testing.T) {
 	var err error
 	app := test.CreateCli(PoliciesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "policy", "list"})
@@ -49,6 +52,7 @@ func TestGetPolicy(t *testing.T) {
 	var err error
 	app := test.CreateCli(PoliciesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "policy", "get"})
@@ -65,6 +69,7 @@ func TestDeletePolicy(t *testing.T) {
 	var err error
 	app := test.CreateCli(PoliciesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "policy", "del"})
@@ -81,6 +86,7 @@ func TestApplyPolicy(t *testing.T) {
 	var err error
 	app := test.CreateCli(PoliciesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "policy", "apply"})
@@ -139,6 +145,7 @@ func TestSetPolicy(t *testing.T) {
 	var err error
 	app := test.CreateCli(PoliciesCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "policy", "set"})
@@ -11,13 +11,17 @@ var CliCommand = cli.Command{
 	Usage:     "Manage provisioning / inventory",
 	Subcommands: []cli.Command{
 		RequisitionsCliCommand,
+		FindCliCommand,
 		NodesCliCommand,
 		InterfacesCliCommand,
 		ServicesCliCommand,
 		CategoriesCliCommand,
 		AssetsCliCommand,
+		MetaDataCliCommand,
 		ForeignSourcesCliCommand,
 		DetectorsCliCommand,
 		PoliciesCliCommand,
+		BackupCliCommand,
+		RestoreCliCommand,
 	},
 }
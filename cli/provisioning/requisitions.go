@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io/ioutil"
 	"strings"
 	"time"
 
@@ -33,6 +34,24 @@ var RequisitionsCliCommand = cli.Command{
 			BashComplete: requisitionNameBashComplete,
 			ArgsUsage:    "<name>",
 		},
+		{
+			Name:         "stats",
+			Usage:        "Shows the deployed nodes and last import time of the requisitions; use --compare to find nodes pending to be imported",
+			Action:       showRequisitionStats,
+			BashComplete: requisitionNameBashComplete,
+			ArgsUsage:    "[name]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "compare, c",
+					Usage: "Compare the pending requisitions against the deployed ones, reporting nodes added but never imported, or deleted but still deployed",
+				},
+				cli.IntFlag{
+					Name:  "concurrency, n",
+					Value: 4,
+					Usage: "Number of requisitions fetched in parallel",
+				},
+			},
+		},
 		{
 			Name:      "add",
 			Usage:     "Adds a new requisition",
@@ -40,22 +59,55 @@ var RequisitionsCliCommand = cli.Command{
 			ArgsUsage: "<name>",
 		},
 		{
-			Name:   "apply",
-			Usage:  "Creates or updates a requisition from a external file, overriding any existing content",
+			Name:  "apply",
+			Usage: "Creates or updates a requisition from a external file, reporting the created, updated and unchanged nodes",
+			Description: "The nodes of the file replace the ones with the same foreign ID on the server, and the nodes that are only on the server are kept unless --prune is used. " +
+				"When the requisition on the server already matches the file, nothing is sent unless --force is used. " +
+				"A YAML requisition may include a defaults section with the location, categories, assets and meta-data of every node, " +
+				"expanded before validating it; the values of each node win on conflicts.",
 			Action: applyRequisition,
 			Flags: []cli.Flag{
 				cli.GenericFlag{
-					Name: "format, x",
-					Value: &model.EnumValue{
-						Enum:    Formats,
-						Default: "yaml",
-					},
+					Name:  "format, x",
+					Value: model.NewEnum(Formats...).WithDefault("yaml"),
 					Usage: "File Format: " + strings.Join(Formats, ", "),
 				},
 				cli.StringFlag{
 					Name:  "file, f",
-					Usage: "External file (use '-' for STDIN Pipe)",
+					Usage: "External file or HTTP(S) URL (use '-' for STDIN Pipe); YAML streams may contain many requisitions separated by '---'",
+				},
+				common.Sha256Flag,
+				cli.BoolFlag{
+					Name:  "chunked",
+					Usage: "Send the requisition without nodes, and then each node individually (recommended for large requisitions)",
+				},
+				cli.IntFlag{
+					Name:  "concurrency, n",
+					Value: 4,
+					Usage: "Number of nodes sent in parallel in chunked mode",
+				},
+				cli.BoolFlag{
+					Name:  "import",
+					Usage: "Import the requisition after sending it (with rescanExisting=true)",
 				},
+				cli.BoolFlag{
+					Name:  "prune",
+					Usage: "Remove the nodes that are on the server but not on the file",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Send the requisition even when the server already matches the file",
+				},
+				cli.StringFlag{
+					Name:  "defaults-file",
+					Usage: "YAML file with the location, categories, assets and meta-data added to every node, for files that cannot embed a defaults section",
+				},
+				cli.BoolFlag{
+					Name:  "detect-drift",
+					Usage: "Only compare the file with the server, without sending anything; exits with 2 when changes are required",
+				},
+				strictAssetsFlag,
+				extraAssetsFlag,
 			},
 			ArgsUsage: "<content>",
 		},
@@ -66,11 +118,8 @@ var RequisitionsCliCommand = cli.Command{
 			Action:    validateRequisition,
 			Flags: []cli.Flag{
 				cli.GenericFlag{
-					Name: "format, x",
-					Value: &model.EnumValue{
-						Enum:    Formats,
-						Default: "xml",
-					},
+					Name:  "format, x",
+					Value: model.NewEnum(Formats...).WithDefault("xml"),
 					Usage: "File Format: " + strings.Join(Formats, ", "),
 				},
 				cli.BoolFlag{
@@ -79,42 +128,203 @@ var RequisitionsCliCommand = cli.Command{
 				},
 				cli.StringFlag{
 					Name:  "file, f",
-					Usage: "External YAML file (use '-' for STDIN Pipe)",
+					Usage: "External file or HTTP(S) URL (use '-' for STDIN Pipe); YAML streams may contain many requisitions separated by '---'",
 				},
+				common.Sha256Flag,
 				cli.BoolFlag{
 					Name:  "yaml, y",
 					Usage: "To generate the YAML representation on success",
 				},
+				strictAssetsFlag,
+				extraAssetsFlag,
 			},
 			ArgsUsage: "<content>",
 		},
+		{
+			Name:         "lint",
+			Usage:        "Runs opinionated checks against a requisition from the server or an external file; exits with 1 on warnings and 2 on errors",
+			Action:       lintRequisition,
+			BashComplete: requisitionNameBashComplete,
+			ArgsUsage:    "<name>",
+			Flags: []cli.Flag{
+				cli.GenericFlag{
+					Name:  "format, x",
+					Value: model.NewEnum(Formats...).WithDefault("xml"),
+					Usage: "File Format: " + strings.Join(Formats, ", "),
+				},
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External file with the requisition instead of the one on the server (use '-' for STDIN Pipe)",
+				},
+				cli.StringFlag{
+					Name:  "config",
+					Value: lintSettingsFile,
+					Usage: "YAML file with the lint settings: enable, disable, severities, labelPattern and minions",
+				},
+				cli.StringSliceFlag{
+					Name:  "enable, e",
+					Usage: "A lint rule to run even when disabled on the settings (can be used multiple times)",
+				},
+				cli.StringSliceFlag{
+					Name:  "disable, d",
+					Usage: "A lint rule to skip (can be used multiple times)",
+				},
+				cli.StringFlag{
+					Name:  "label-pattern",
+					Usage: "Regular expression the node labels must match",
+				},
+				cli.BoolFlag{
+					Name:  "minions",
+					Usage: "Require a location on every node, as Minions are in use",
+				},
+				cli.BoolFlag{
+					Name:  "list-rules",
+					Usage: "Show the available lint rules",
+				},
+			},
+		},
+		{
+			Name:         "diff",
+			Usage:        "Compares a requisition from the server against an external file; exits with 1 when differences exist",
+			Action:       diffRequisition,
+			BashComplete: requisitionNameBashComplete,
+			Flags: []cli.Flag{
+				cli.GenericFlag{
+					Name:  "format, x",
+					Value: model.NewEnum(Formats...).WithDefault("yaml"),
+					Usage: "File Format: " + strings.Join(Formats, ", "),
+				},
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External file (use '-' for STDIN Pipe)",
+				},
+				cli.StringFlag{
+					Name:  "base, b",
+					Usage: "Another external file to compare against, instead of the requisition on the server; its format is taken from the extension",
+				},
+			},
+			ArgsUsage: "<name> <content>",
+		},
+		watchCommand,
+		{
+			Name:   "render",
+			Usage:  "Renders a requisition from a Go template, and optionally sends it to the server",
+			Action: renderRequisition,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "template, t",
+					Usage: "The template file, which must produce a requisition in YAML",
+				},
+				cli.StringFlag{
+					Name:  "values, f",
+					Usage: "A YAML file with the values for the template",
+				},
+				cli.StringSliceFlag{
+					Name:  "set",
+					Usage: "Sets or overrides a value, using dots for nested keys (e.x. --set site.name=Durham)",
+				},
+				cli.BoolFlag{
+					Name:  "apply",
+					Usage: "Creates or updates the rendered requisition on the server, overriding any existing content",
+				},
+			},
+		},
+		fromDNSCommand,
+		fromNetboxCommand,
+		{
+			Name:         "export",
+			Usage:        "Exports a requisition from the server or an external file to a file, or to a directory with a file per node",
+			Action:       exportRequisition,
+			BashComplete: requisitionNameBashComplete,
+			Flags: []cli.Flag{
+				cli.GenericFlag{
+					Name:  "format, x",
+					Value: model.NewEnum(Formats...).WithDefault("yaml"),
+					Usage: "File Format: " + strings.Join(Formats, ", "),
+				},
+				cli.StringFlag{
+					Name:  "dir",
+					Value: ".",
+					Usage: "Target directory",
+				},
+				cli.BoolFlag{
+					Name:  "split-nodes, s",
+					Usage: "Write a file per node, named after its foreign ID, under a directory named after the requisition",
+				},
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "An external file to export instead of the requisition on the server; its format is taken from the extension",
+				},
+			},
+			ArgsUsage: "<name>",
+		},
 		{
 			Name:         "import",
 			ShortName:    "sync",
-			Usage:        "Import or synchronize a requisition",
+			Usage:        "Import or synchronize a requisition; with --dir, the requisition exported with --split-nodes is sent before synchronizing it",
 			Action:       importRequisition,
 			BashComplete: requisitionNameBashComplete,
 			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "dir",
+					Usage: "Directory with a file per node, as created by export --split-nodes",
+				},
+				cli.BoolFlag{
+					Name:  "wait, w",
+					Usage: "Wait until the import finishes",
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Value: 5 * time.Minute,
+					Usage: "Maximum time to wait for the import to finish",
+				},
+				cli.DurationFlag{
+					Name:  "poll-interval",
+					Value: 5 * time.Second,
+					Usage: "Time between checks of the import status",
+				},
 				cli.GenericFlag{
-					Name: "rescanExisting, r",
-					Value: &model.EnumValue{
-						Enum:    []string{"true", "false", "dbonly"},
-						Default: "true",
-					},
-					Usage: `
+					Name:  "rescan, rescanExisting, r",
+					Value: model.NewEnum(model.RescanModes...).WithDefault(string(model.RescanAll)),
+					Usage: `How the existing nodes are handled:
 	true, to update the database and execute the scan phase
 	false, to add/delete nodes on the DB skipping the scan phase
-	dbonly, to add/detete/update nodes on the DB skipping the scan phase
+	dbonly, to add/delete/update nodes on the DB skipping the scan phase
 	`,
 				},
 			},
 			ArgsUsage: "<name>",
 		},
+		{
+			Name:         "prune",
+			Usage:        "Deletes the nodes whose foreign IDs are missing from a list or a local requisition (dry-run unless --apply is used)",
+			Action:       pruneRequisition,
+			Flags:        pruneRequisitionFlags(),
+			BashComplete: requisitionNameBashComplete,
+			ArgsUsage:    "<name>",
+		},
+		{
+			Name:         "rename",
+			Usage:        "Renames a requisition, deleting the old one after the new one is deployed",
+			Action:       renameRequisition,
+			Flags:        append(copyRequisitionFlags(), common.YesFlag),
+			BashComplete: requisitionNameBashComplete,
+			ArgsUsage:    "<name> <newName>",
+		},
+		{
+			Name:         "clone",
+			Usage:        "Copies a requisition under a new name and imports it",
+			Action:       cloneRequisition,
+			Flags:        copyRequisitionFlags(),
+			BashComplete: requisitionNameBashComplete,
+			ArgsUsage:    "<name> <newName>",
+		},
 		{
 			Name:         "delete",
 			ShortName:    "del",
 			Usage:        "Deletes a requisition",
 			Action:       deleteRequisition,
+			Flags:        []cli.Flag{common.YesFlag},
 			BashComplete: requisitionNameBashComplete,
 			ArgsUsage:    "<name>",
 		},
@@ -122,101 +332,352 @@ var RequisitionsCliCommand = cli.Command{
 }
 
 func listRequisitions(c *cli.Context) error {
-	requisitions, err := getUtilsAPI().GetRequisitionNames()
+	requisitions, err := getUtilsAPI(c).GetRequisitionNames()
 	if err != nil {
 		return err
 	}
+	list := model.RequisitionsStats{ForeignSources: make([]model.RequisitionStats, 0)}
+	table := common.NewTable("There are no requisitions", "Requisition", "Nodes in DB", "Last Import")
 	if len(requisitions.ForeignSources) == 0 {
-		fmt.Println("There are no requisitions")
-		return nil
+		return common.Print(list, table)
 	}
-	statistics, err := getReqAPI().GetRequisitionsStats()
+	statistics, err := getReqAPI(c).GetRequisitionsStats()
 	if err != nil {
 		return err
 	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Requisition\tNodes in DB\tLast Import")
 	for _, req := range requisitions.ForeignSources {
 		stats := statistics.GetRequisitionStats(req)
-		fmt.Fprintf(writer, "%s\t%d\t%s\n", req, len(stats.ForeignIDs), getDisplayTime(stats.LastImport))
+		stats.Name = req
+		stats.Count = len(stats.ForeignIDs)
+		if stats.ForeignIDs == nil {
+			stats.ForeignIDs = make([]string, 0)
+		}
+		list.ForeignSources = append(list.ForeignSources, stats)
+		table.AddRow(req, stats.Count, common.DisplayTime(stats.LastImport))
 	}
-	writer.Flush()
-	return nil
+	list.Count = len(list.ForeignSources)
+	return common.Print(list, table)
 }
 
 func showRequisition(c *cli.Context) error {
-	requisition, err := getReqAPI().GetRequisition(c.Args().First())
+	requisition, err := getReqAPI(c).GetRequisition(c.Args().First())
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(requisition)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(requisition, nil)
 }
 
 func addRequisition(c *cli.Context) error {
-	return getReqAPI().CreateRequisition(c.Args().First())
+	return getReqAPI(c).CreateRequisition(c.Args().First())
 }
 
 func applyRequisition(c *cli.Context) error {
-	requisition, err := parseRequisition(c)
+	requisitions, err := parseRequisitions(c)
+	if err != nil {
+		return err
+	}
+	if err := checkRequisitionAssets(c, requisitions); err != nil {
+		return err
+	}
+	drifted := 0
+	for i := range requisitions {
+		requisition := &requisitions[i]
+		err := common.Apply(requisition, func() error {
+			plan, err := planRequisitionApply(c, *requisition, c.Bool("prune"))
+			if err != nil {
+				return err
+			}
+			printApplyPlan(plan)
+			switch {
+			case c.Bool("detect-drift"):
+				if plan.HasChanges() {
+					drifted++
+					fmt.Fprintf(common.Output, "%s; changes required, nothing was sent\n", plan.Summary())
+				} else {
+					fmt.Fprintf(common.Output, "%s; no changes required\n", plan.Summary())
+				}
+				return nil
+			case !plan.HasChanges() && !c.Bool("force"):
+				fmt.Fprintf(common.Output, "%s; nothing was sent\n", plan.Summary())
+				return nil
+			}
+			if c.Bool("chunked") {
+				if err := applyRequisitionChunked(c, plan.target, c.Int("concurrency")); err != nil {
+					return err
+				}
+			} else if err := getReqAPI(c).SetRequisition(plan.target); err != nil {
+				return err
+			}
+			if c.Bool("import") {
+				if err := getReqAPI(c).ImportRequisition(requisition.Name, model.RescanAll); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintln(common.Output, plan.Summary())
+			return nil
+		})
+		if err != nil {
+			if len(requisitions) > 1 {
+				return fmt.Errorf("Requisition %s (document %d of %d): %w", requisition.Name, i+1, len(requisitions), err)
+			}
+			return err
+		}
+	}
+	if drifted > 0 {
+		return common.ExitError{Message: fmt.Sprintf("%d of %d requisitions differ from the server", drifted, len(requisitions)), Code: exitDrift}
+	}
+	return nil
+}
+
+func applyRequisitionChunked(c *cli.Context, requisition model.Requisition, concurrency int) error {
+	failures, err := getReqAPI(c).SetRequisitionChunked(requisition, concurrency, func(done int, total int) {
+		if !common.Log.Enabled(common.LevelInfo) {
+			return
+		}
+		fmt.Fprintf(common.Log.Output, "\rSent %d/%d nodes to requisition %s", done, total, requisition.Name)
+		if done == total {
+			fmt.Fprintln(common.Log.Output)
+		}
+	})
 	if err != nil {
 		return err
 	}
-	return getReqAPI().SetRequisition(*requisition)
+	if len(failures) == 0 {
+		return nil
+	}
+	lines := make([]string, len(failures))
+	for i, f := range failures {
+		lines[i] = "  " + f.Error()
+	}
+	return fmt.Errorf("Failed to send %d of %d nodes to requisition %s:\n%s", len(failures), len(requisition.Nodes), requisition.Name, strings.Join(lines, "\n"))
 }
 
 func validateRequisition(c *cli.Context) error {
-	requisition, err := parseRequisition(c)
+	requisitions, err := parseRequisitions(c)
 	if err != nil {
 		return err
 	}
-	if c.Bool("yaml") {
-		data, err := yaml.Marshal(requisition)
-		if err != nil {
-			return err
+	if err := checkRequisitionAssets(c, requisitions); err != nil {
+		return err
+	}
+	for i, requisition := range requisitions {
+		if c.Bool("yaml") {
+			data, err := yaml.Marshal(requisition)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				fmt.Fprintln(common.Output, "---")
+			}
+			fmt.Fprintln(common.Output, string(data))
+			continue
 		}
-		fmt.Println(string(data))
-		return nil
+		common.Log.Infof("Requisition %s is valid!", requisition.Name)
 	}
-	fmt.Printf("Requisition %s is valid!\n", requisition.Name)
 	return nil
 }
 
+func diffRequisition(c *cli.Context) error {
+	name := c.Args().First()
+	base := c.String("base")
+	if name == "" && base == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	data, err := common.ReadInput(c, 1)
+	if err != nil {
+		return err
+	}
+	local, err := unmarshalRequisition(c, data)
+	if err != nil {
+		return err
+	}
+	var current *model.Requisition
+	if base != "" {
+		current, err = readRequisitionFile(base)
+	} else {
+		current, err = getReqAPI(c).GetRequisition(name)
+	}
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = current.Name
+	}
+	diff := current.Diff(*local)
+	if diff.IsEmpty() {
+		fmt.Fprintf(common.Output, "Requisition %s is up to date\n", name)
+		return nil
+	}
+	fmt.Fprintf(common.Output, "Requisition %s: %d added, %d removed, %d changed nodes\n", name, len(diff.AddedNodes), len(diff.RemovedNodes), len(diff.ChangedNodes))
+	for _, foreignID := range diff.AddedNodes {
+		fmt.Fprintf(common.Output, "+ node %s\n", foreignID)
+	}
+	for _, foreignID := range diff.RemovedNodes {
+		fmt.Fprintf(common.Output, "- node %s\n", foreignID)
+	}
+	for _, node := range diff.ChangedNodes {
+		fmt.Fprintf(common.Output, "~ node %s\n", node.ForeignID)
+		for _, change := range node.Changes {
+			fmt.Fprintf(common.Output, "    %s\n", change)
+		}
+	}
+	return common.ExitError{Code: 1}
+}
+
 func importRequisition(c *cli.Context) error {
-	return getReqAPI().ImportRequisition(c.Args().First(), c.String("rescanExisting"))
+	name := c.Args().First()
+	if dir := c.String("dir"); dir != "" {
+		requisition, err := readRequisitionDir(dir)
+		if err != nil {
+			return err
+		}
+		if name != "" && name != requisition.Name {
+			return fmt.Errorf("Requisition name %s doesn't match %s from %s", name, requisition.Name, dir)
+		}
+		if err := requisition.Validate(); err != nil {
+			return err
+		}
+		if err := getReqAPI(c).SetRequisition(*requisition); err != nil {
+			return err
+		}
+		name = requisition.Name
+	}
+	if !c.Bool("wait") {
+		return getReqAPI(c).ImportRequisition(name, model.RescanMode(c.String("rescan")))
+	}
+	stats, err := getReqAPI(c).GetRequisitionsStats()
+	if err != nil {
+		return err
+	}
+	lastImport := stats.GetRequisitionStats(name).LastImport
+	if err := getReqAPI(c).ImportRequisition(name, model.RescanMode(c.String("rescan"))); err != nil {
+		return err
+	}
+	current, err := getReqAPI(c).WaitForImport(name, lastImport, c.Duration("timeout"), c.Duration("poll-interval"))
+	if err != nil {
+		return err
+	}
+	common.Log.Infof("Requisition %s imported with %d nodes", name, len(current.ForeignIDs))
+	return nil
 }
 
 func deleteRequisition(c *cli.Context) error {
-	return getReqAPI().DeleteRequisition(c.Args().First())
+	name := c.Args().First()
+	if !common.SkipConfirmation(c) {
+		requisition, err := getReqAPI(c).GetRequisition(name)
+		if err != nil {
+			return err
+		}
+		stats, err := getReqAPI(c).GetRequisitionsStats()
+		if err != nil {
+			return err
+		}
+		deployed := len(stats.GetRequisitionStats(name).ForeignIDs)
+		description := fmt.Sprintf("Requisition %s will be deleted with its foreign source definition, %d pending nodes and %d deployed nodes (removed from the database)", name, len(requisition.Nodes), deployed)
+		if err := common.ConfirmName(c, description, "requisition", name); err != nil {
+			return err
+		}
+	}
+	return getReqAPI(c).DeleteRequisition(name)
 }
 
-func getDisplayTime(lastImport *model.Time) string {
-	if lastImport == nil || lastImport.IsZero() {
-		return "Never"
+func parseRequisition(c *cli.Context) (*model.Requisition, error) {
+	data, err := common.ReadInput(c, 0)
+	if err != nil {
+		return &model.Requisition{}, err
 	}
-	return lastImport.In(time.Local).String()
+	return unmarshalRequisition(c, data)
 }
 
-func parseRequisition(c *cli.Context) (*model.Requisition, error) {
-	requisition := &model.Requisition{}
+// Parses the requisitions from the input; YAML streams may contain many documents separated by "---"
+func parseRequisitions(c *cli.Context) ([]model.Requisition, error) {
 	data, err := common.ReadInput(c, 0)
 	if err != nil {
-		return requisition, err
+		return nil, err
+	}
+	if c.String("format") != "yaml" {
+		requisition, err := unmarshalRequisition(c, data)
+		if err != nil {
+			return nil, err
+		}
+		return []model.Requisition{*requisition}, nil
 	}
-	forceParse := c.Bool("forceParseFQDN")
+	documents, err := common.SplitYAMLDocuments(data)
+	if err != nil {
+		return nil, common.ValidationError(err)
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("Content cannot be empty")
+	}
+	requisitions := make([]model.Requisition, 0, len(documents))
+	for i, doc := range documents {
+		requisition := &model.Requisition{}
+		err := doc.Decode(requisition)
+		if err == nil {
+			err = prepareRequisition(c, requisition)
+		}
+		if err != nil && len(documents) > 1 {
+			return nil, common.ValidationError(fmt.Errorf("Document %d of %d: %w", i+1, len(documents), err))
+		}
+		if err != nil {
+			return nil, err
+		}
+		requisitions = append(requisitions, *requisition)
+	}
+	return requisitions, nil
+}
+
+func unmarshalRequisition(c *cli.Context, data []byte) (*model.Requisition, error) {
+	requisition := &model.Requisition{}
+	var err error
 	switch c.String("format") {
 	case "xml":
 		err = xml.Unmarshal(data, requisition)
-		model.AllowFqdnOnRequisitionedInterfaces = forceParse
 	case "json":
 		err = json.Unmarshal(data, requisition)
-		model.AllowFqdnOnRequisitionedInterfaces = forceParse
 	case "yaml":
 		err = yaml.Unmarshal(data, requisition)
 	}
 	if err != nil {
 		return requisition, err
 	}
-	return requisition, requisition.Validate()
+	return requisition, prepareRequisition(c, requisition)
+}
+
+// Expands the defaults of a parsed requisition and validates it
+func prepareRequisition(c *cli.Context, requisition *model.Requisition) error {
+	// FQDNs are always translated on YAML files, but only when forced for XML and JSON
+	model.Resolver.Enabled = c.String("format") == "yaml" || c.Bool("forceParseFQDN")
+	if err := expandRequisitionDefaults(c, requisition); err != nil {
+		return common.ValidationError(err)
+	}
+	return common.ValidationError(requisition.Validate())
+}
+
+// Expands the defaults embedded on the requisition, and then the ones from --defaults-file, so on conflicts
+// the nodes win over the embedded defaults, and those win over the file
+func expandRequisitionDefaults(c *cli.Context, requisition *model.Requisition) error {
+	if requisition.Defaults != nil {
+		if err := requisition.Defaults.Validate(); err != nil {
+			return err
+		}
+		requisition.ExpandDefaults()
+	}
+	file := c.String("defaults-file")
+	if file == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	defaults := model.RequisitionDefaults{}
+	if err := yaml.Unmarshal(data, &defaults); err != nil {
+		return fmt.Errorf("Cannot parse %s: %w", file, err)
+	}
+	if err := defaults.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	requisition.ApplyDefaults(defaults)
+	return nil
 }
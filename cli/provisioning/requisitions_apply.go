@@ -0,0 +1,103 @@
+package provisioning
+
+import (
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// The exit status of req apply --detect-drift when the requisitions on the server differ from the files
+const exitDrift = 2
+
+// applyPlan what applying a requisition from a file changes on the server, per foreign ID
+type applyPlan struct {
+	Name      string
+	New       bool // The requisition doesn't exist on the server
+	Created   []string
+	Updated   []string
+	Unchanged []string
+	Removed   []string // The nodes only on the server, with --prune
+	Kept      []string // The nodes only on the server, without --prune
+	target    model.Requisition
+}
+
+// HasChanges returns true when the requisition on the server differs from the one to send
+func (p applyPlan) HasChanges() bool {
+	return p.New || len(p.Created) > 0 || len(p.Updated) > 0 || len(p.Removed) > 0
+}
+
+// Summary returns the amount of nodes on each status (e.x. "Requisition Local: 12 created, 3 updated, 240 unchanged")
+func (p applyPlan) Summary() string {
+	name := p.Name
+	if p.New {
+		name += " (new)"
+	}
+	summary := fmt.Sprintf("Requisition %s: %d created, %d updated, %d unchanged", name, len(p.Created), len(p.Updated), len(p.Unchanged))
+	if len(p.Removed) > 0 {
+		summary += fmt.Sprintf(", %d removed", len(p.Removed))
+	}
+	if len(p.Kept) > 0 {
+		summary += fmt.Sprintf(", %d kept (only on the server, use --prune to remove them)", len(p.Kept))
+	}
+	return summary
+}
+
+// Compares the requisition with the one on the server; the nodes that are only on the server are added to the target,
+// unless they have to be pruned
+func planRequisitionApply(c *cli.Context, requisition model.Requisition, prune bool) (*applyPlan, error) {
+	plan := &applyPlan{Name: requisition.Name, target: requisition}
+	plan.target.Nodes = append([]model.RequisitionNode{}, requisition.Nodes...)
+	current := &model.Requisition{Name: requisition.Name}
+	if getUtilsAPI(c).RequisitionExists(requisition.Name) {
+		existing, err := getReqAPI(c).GetRequisition(requisition.Name)
+		if err != nil {
+			return nil, err
+		}
+		current = existing
+	} else {
+		plan.New = true
+	}
+	diff := current.Diff(requisition)
+	created := make(map[string]bool)
+	for _, id := range diff.AddedNodes {
+		created[id] = true
+	}
+	updated := make(map[string]bool)
+	for _, n := range diff.ChangedNodes {
+		updated[n.ForeignID] = true
+	}
+	for _, n := range requisition.Nodes {
+		switch {
+		case created[n.ForeignID]:
+			plan.Created = append(plan.Created, n.ForeignID)
+		case updated[n.ForeignID]:
+			plan.Updated = append(plan.Updated, n.ForeignID)
+		default:
+			plan.Unchanged = append(plan.Unchanged, n.ForeignID)
+		}
+	}
+	for _, id := range diff.RemovedNodes {
+		if prune {
+			plan.Removed = append(plan.Removed, id)
+		} else {
+			plan.Kept = append(plan.Kept, id)
+			plan.target.Nodes = append(plan.target.Nodes, *current.GetNode(id))
+		}
+	}
+	return plan, nil
+}
+
+// Prints a line per created (+), updated (~) or removed (-) node
+func printApplyPlan(plan *applyPlan) {
+	for _, id := range plan.Created {
+		fmt.Fprintf(common.Output, "+ node %s\n", id)
+	}
+	for _, id := range plan.Updated {
+		fmt.Fprintf(common.Output, "~ node %s\n", id)
+	}
+	for _, id := range plan.Removed {
+		fmt.Fprintf(common.Output, "- node %s\n", id)
+	}
+}
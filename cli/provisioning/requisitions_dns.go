@@ -0,0 +1,184 @@
+package provisioning
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/dns"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fromDNSCommand the CLI command to generate a requisition from the address records of a DNS zone
+var fromDNSCommand = cli.Command{
+	Name:   "from-dns",
+	Usage:  "Generates a requisition from the A records of a DNS zone, through a zone transfer (AXFR) or a file of records",
+	Action: requisitionFromDNS,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "zone, z",
+			Usage: "The DNS zone (e.x. example.com)",
+		},
+		cli.StringFlag{
+			Name:  "server, s",
+			Usage: "The DNS server that allows zone transfers, with an optional port (e.x. 10.0.0.53 or 10.0.0.53:5353)",
+		},
+		cli.StringFlag{
+			Name:  "records-file",
+			Usage: "A file in zone file format (like the output of 'dig axfr'), used instead of a zone transfer",
+		},
+		cli.StringFlag{
+			Name:  "expression, e",
+			Usage: "A regular expression for the host names relative to the zone (e.x. '^(sw|rtr)-.*')",
+		},
+		cli.BoolFlag{
+			Name:  "include-aaaa",
+			Usage: "Include the IPv6 addresses from the AAAA records",
+		},
+		cli.StringFlag{
+			Name:  "name, n",
+			Usage: "The name of the requisition (defaults to the zone)",
+		},
+		cli.StringFlag{
+			Name:  "location, l",
+			Usage: "The monitoring location of the nodes",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Value: 30 * time.Second,
+			Usage: "The timeout of the zone transfer",
+		},
+		cli.BoolFlag{
+			Name:  "apply",
+			Usage: "Creates or updates the generated requisition on the server, overriding any existing content",
+		},
+	},
+}
+
+func requisitionFromDNS(c *cli.Context) error {
+	zone := dns.CanonicalName(c.String("zone"))
+	if zone == "" {
+		return fmt.Errorf("Zone required")
+	}
+	var expression *regexp.Regexp
+	if pattern := c.String("expression"); pattern != "" {
+		var err error
+		if expression, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("Invalid expression %s: %w", pattern, err)
+		}
+	}
+	records, err := getDNSRecords(c, zone)
+	if err != nil {
+		return err
+	}
+	name := c.String("name")
+	if name == "" {
+		name = zone
+	}
+	requisition, err := buildDNSRequisition(name, zone, records, expression, c.Bool("include-aaaa"), c.String("location"))
+	if err != nil {
+		return err
+	}
+	if !c.Bool("apply") {
+		if err := requisition.Validate(); err != nil {
+			return common.ValidationError(err)
+		}
+		data, _ := yaml.Marshal(requisition)
+		fmt.Fprintln(common.Output, string(data))
+		return nil
+	}
+	return common.Apply(requisition, func() error {
+		if err := getReqAPI(c).SetRequisition(*requisition); err != nil {
+			return err
+		}
+		common.Log.Infof("Requisition %s saved with %d nodes from zone %s", requisition.Name, len(requisition.Nodes), zone)
+		return nil
+	})
+}
+
+// Obtains the address records from the file, or through a zone transfer
+func getDNSRecords(c *cli.Context, zone string) ([]dns.Record, error) {
+	if file := c.String("records-file"); file != "" {
+		input, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer input.Close()
+		records, err := dns.ParseRecords(input, zone)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot parse %s: %w", file, err)
+		}
+		return records, nil
+	}
+	server := c.String("server")
+	if server == "" {
+		return nil, fmt.Errorf("Either --server or --records-file is required")
+	}
+	return dns.Transfer(zone, server, c.Duration("timeout"))
+}
+
+// Builds a requisition with a node per host name, whose IPv4 address is the primary interface;
+// it fails when a host name has more than one address of the same type, as the node would be ambiguous
+func buildDNSRequisition(name string, zone string, records []dns.Record, expression *regexp.Regexp, includeAAAA bool, location string) (*model.Requisition, error) {
+	hosts := make(map[string][]dns.Record)
+	order := make([]string, 0)
+	for _, r := range records {
+		host := r.HostName(zone)
+		if strings.Contains(host, "*") || (r.Type == dns.TypeAAAA && !includeAAAA) {
+			continue
+		}
+		if expression != nil && !expression.MatchString(host) {
+			continue
+		}
+		key := strings.ToLower(host)
+		if _, ok := hosts[key]; !ok {
+			order = append(order, key)
+		}
+		hosts[key] = append(hosts[key], r)
+	}
+	duplicates := make([]string, 0)
+	for _, key := range order {
+		seen := make(map[string][]string)
+		for _, r := range hosts[key] {
+			seen[r.Type] = append(seen[r.Type], r.Address)
+		}
+		for _, addresses := range seen {
+			if len(addresses) > 1 {
+				duplicates = append(duplicates, fmt.Sprintf("%s (%s)", hosts[key][0].Name, strings.Join(addresses, ", ")))
+			}
+		}
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		return nil, common.ValidationError(fmt.Errorf("Duplicate host names found: %s", strings.Join(duplicates, "; ")))
+	}
+	requisition := &model.Requisition{Name: name}
+	for _, key := range order {
+		first := hosts[key][0]
+		node := model.RequisitionNode{
+			ForeignID: first.HostName(zone),
+			NodeLabel: first.Name,
+			Location:  location,
+		}
+		// The IPv4 address is the primary interface, when the host has one
+		sort.SliceStable(hosts[key], func(i, j int) bool {
+			return hosts[key][i].Type == dns.TypeA && hosts[key][j].Type != dns.TypeA
+		})
+		for i, r := range hosts[key] {
+			primary := "N"
+			if i == 0 {
+				primary = "P"
+			}
+			node.Interfaces = append(node.Interfaces, model.RequisitionInterface{IPAddress: r.Address, SnmpPrimary: primary})
+		}
+		requisition.Nodes = append(requisition.Nodes, node)
+	}
+	return requisition, nil
+}
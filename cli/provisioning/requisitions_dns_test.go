@@ -0,0 +1,79 @@
+package provisioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+
+	"gopkg.in/yaml.v2"
+)
+
+const testZoneRecords = `$ORIGIN example.com.
+@       IN SOA ns1 admin 1 2 3 4 5
+@       IN A    10.0.0.1
+sw-01   IN A    10.0.0.2
+        IN AAAA 2001:db8::2
+rtr-01  IN A    10.0.0.3
+www     IN A    10.0.0.4
+*       IN A    10.0.0.5
+`
+
+func TestRequisitionFromDNS(t *testing.T) {
+	app := test.CreateCli(RequisitionsCliCommand)
+	logs, restore := test.CaptureLogs()
+	defer restore()
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	recordsFile := filepath.Join(dir, "example.com.zone")
+	assert.NilError(t, ioutil.WriteFile(recordsFile, []byte(testZoneRecords), 0644))
+	duplicatesFile := filepath.Join(dir, "duplicates.zone")
+	assert.NilError(t, ioutil.WriteFile(duplicatesFile, []byte(testZoneRecords+"sw-01 IN A 10.0.1.2\n"), 0644))
+
+	_, err = test.RunWithOutput(app, "table", "req", "from-dns")
+	assert.Error(t, err, "Zone required")
+
+	_, err = test.RunWithOutput(app, "table", "req", "from-dns", "--zone", "example.com")
+	assert.Error(t, err, "Either --server or --records-file is required")
+
+	output, err := test.RunWithOutput(app, "table", "req", "from-dns", "--zone", "example.com", "--records-file", recordsFile, "--expression", "^(sw|rtr)-.*", "--include-aaaa")
+	assert.NilError(t, err)
+	requisition := model.Requisition{}
+	assert.NilError(t, yaml.Unmarshal([]byte(output), &requisition))
+	assert.Equal(t, "example.com", requisition.Name)
+	assert.Equal(t, 2, len(requisition.Nodes))
+	node := requisition.Nodes[0]
+	assert.Equal(t, "sw-01", node.ForeignID)
+	assert.Equal(t, "sw-01.example.com", node.NodeLabel)
+	assert.Equal(t, 2, len(node.Interfaces))
+	assert.Equal(t, "10.0.0.2", node.Interfaces[0].IPAddress)
+	assert.Equal(t, "P", node.Interfaces[0].SnmpPrimary)
+	assert.Equal(t, "2001:db8::2", node.Interfaces[1].IPAddress)
+	assert.Equal(t, "N", node.Interfaces[1].SnmpPrimary)
+
+	// The apex and wildcard records are skipped, and IPv6 addresses are excluded by default
+	output, err = test.RunWithOutput(app, "table", "req", "from-dns", "--zone", "example.com", "--records-file", recordsFile, "--name", "Lab")
+	assert.NilError(t, err)
+	requisition = model.Requisition{}
+	assert.NilError(t, yaml.Unmarshal([]byte(output), &requisition))
+	assert.Equal(t, "Lab", requisition.Name)
+	assert.Equal(t, 4, len(requisition.Nodes))
+	assert.Equal(t, "example.com", requisition.Nodes[0].ForeignID)
+	assert.Equal(t, 1, len(requisition.Nodes[1].Interfaces))
+
+	_, err = test.RunWithOutput(app, "table", "req", "from-dns", "--zone", "example.com", "--records-file", duplicatesFile)
+	assert.Error(t, err, "Duplicate host names found: sw-01.example.com (10.0.0.2, 10.0.1.2)")
+
+	_, err = test.RunWithOutput(app, "table", "req", "from-dns", "--zone", "example.com", "--records-file", recordsFile, "-e", "^(sw|rtr)-", "--apply")
+	assert.NilError(t, err)
+	assert.Equal(t, "Requisition example.com saved with 2 nodes from zone example.com\n", logs.String())
+}
@@ -0,0 +1,285 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// requisitionHeaderFile the name (without extension) of the file with the requisition attributes when nodes are split into individual files
+const requisitionHeaderFile = "_requisition"
+
+// requisitionHeader the content of the file with the requisition attributes; NodeFiles lists the node files
+// in the order of the nodes on the requisition, so the import restores that order and skips any other file
+type requisitionHeader struct {
+	XMLName           xml.Name `xml:"model-import" json:"-" yaml:"-"`
+	model.Requisition `yaml:",inline"`
+	NodeFiles         []string `xml:"node-file,omitempty" json:"node-files,omitempty" yaml:"nodeFiles,omitempty"`
+}
+
+func exportRequisition(c *cli.Context) error {
+	name := c.Args().First()
+	file := c.String("file")
+	if name == "" && file == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	format := c.String("format")
+	dir := c.String("dir")
+	if file != "" {
+		requisition, err := readRequisitionFile(file)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = requisition.Name
+		}
+		if !c.Bool("split-nodes") {
+			return writeExportedRequisition(name, dir, format, requisition)
+		}
+		target := filepath.Join(dir, name)
+		if err := writeRequisitionDir(target, format, *requisition); err != nil {
+			return err
+		}
+		common.Log.Infof("Requisition %s exported to %s with %d node files", name, target, len(requisition.Nodes))
+		return nil
+	}
+	if !c.Bool("split-nodes") {
+		requisition, err := getReqAPI(c).GetRequisition(name)
+		if err != nil {
+			return err
+		}
+		return writeExportedRequisition(name, dir, format, requisition)
+	}
+	// Nodes are written as they are received, so the whole requisition is never held in memory
+	target := filepath.Join(dir, name)
+	writer, err := newRequisitionDirWriter(target, format)
+	if err != nil {
+		return err
+	}
+	header, err := getReqAPI(c).ForEachNode(name, writer.writeNode)
+	if err != nil {
+		return err
+	}
+	if err := writer.finish(*header); err != nil {
+		return err
+	}
+	common.Log.Infof("Requisition %s exported to %s with %d node files", name, target, writer.nodes)
+	return nil
+}
+
+func writeExportedRequisition(name string, dir string, format string, requisition *model.Requisition) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file := filepath.Join(dir, name+"."+format)
+	if err := writeRequisitionFile(file, format, requisition); err != nil {
+		return err
+	}
+	common.Log.Infof("Requisition %s exported to %s", name, file)
+	return nil
+}
+
+// Reads a requisition from a local file, whose format is taken from the extension
+func readRequisitionFile(file string) (*model.Requisition, error) {
+	format := getFileFormat(file)
+	if format == "" {
+		return nil, fmt.Errorf("Cannot infer the format of %s; the extension must be one of: %s", file, strings.Join(Formats, ", "))
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	requisition := &model.Requisition{}
+	if err := unmarshalData(format, data, requisition); err != nil {
+		return nil, fmt.Errorf("Cannot parse %s: %w", file, err)
+	}
+	return requisition, nil
+}
+
+// requisitionDirWriter writes a requisition into a directory one node at a time
+type requisitionDirWriter struct {
+	dir       string
+	format    string
+	files     map[string]bool
+	nodeFiles []string
+	nodes     int
+}
+
+func newRequisitionDirWriter(dir string, format string) (*requisitionDirWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &requisitionDirWriter{dir: dir, format: format, files: make(map[string]bool)}, nil
+}
+
+func (w *requisitionDirWriter) writeNode(node model.RequisitionNode) error {
+	if node.ForeignID == requisitionHeaderFile {
+		return fmt.Errorf("Foreign ID %s is reserved when splitting nodes", node.ForeignID)
+	}
+	file := node.ForeignID + "." + w.format
+	if err := writeRequisitionFile(filepath.Join(w.dir, file), w.format, &node); err != nil {
+		return err
+	}
+	if w.files[file] {
+		return fmt.Errorf("Duplicate foreign ID %s", node.ForeignID)
+	}
+	w.files[file] = true
+	w.nodeFiles = append(w.nodeFiles, file)
+	w.nodes++
+	return nil
+}
+
+// Writes the file with the requisition attributes and the list of node files, and removes any file that was not written
+func (w *requisitionDirWriter) finish(requisition model.Requisition) error {
+	header := requisitionHeader{Requisition: requisition, NodeFiles: w.nodeFiles}
+	header.Nodes = nil
+	file := requisitionHeaderFile + "." + w.format
+	if err := writeRequisitionFile(filepath.Join(w.dir, file), w.format, &header); err != nil {
+		return err
+	}
+	w.files[file] = true
+	entries, err := ioutil.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && getFileFormat(entry.Name()) != "" && !w.files[entry.Name()] {
+			if err := os.Remove(filepath.Join(w.dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Writes a requisition into a directory, with a file per node, plus a file with the requisition attributes;
+// any node file that is not part of the requisition is removed, so the directory reflects the requisition content.
+func writeRequisitionDir(dir string, format string, requisition model.Requisition) error {
+	writer, err := newRequisitionDirWriter(dir, format)
+	if err != nil {
+		return err
+	}
+	for _, node := range requisition.Nodes {
+		if err := writer.writeNode(node); err != nil {
+			return err
+		}
+	}
+	return writer.finish(requisition)
+}
+
+// Reads a requisition from a directory created by writeRequisitionDir, restoring the nodes in the order of the node files
+// listed on the file with the requisition attributes; other files are skipped. When the file with the requisition attributes
+// is missing, the name of the directory is used as the requisition name, and all the files are read in alphabetical order.
+func readRequisitionDir(dir string) (*model.Requisition, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	header := &requisitionHeader{}
+	headerFound := false
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		format := getFileFormat(entry.Name())
+		if entry.IsDir() || format == "" {
+			continue
+		}
+		if strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())) != requisitionHeaderFile {
+			files = append(files, entry.Name())
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := unmarshalData(format, data, header); err != nil {
+			return nil, fmt.Errorf("Cannot parse %s: %w", entry.Name(), err)
+		}
+		headerFound = true
+	}
+	requisition := &header.Requisition
+	if headerFound && header.NodeFiles != nil {
+		listed := make(map[string]bool, len(header.NodeFiles))
+		for _, file := range header.NodeFiles {
+			if filepath.Base(file) != file || getFileFormat(file) == "" {
+				return nil, fmt.Errorf("Invalid node file %s on the %s file of %s", file, requisitionHeaderFile, dir)
+			}
+			listed[file] = true
+		}
+		for _, file := range files {
+			if !listed[file] {
+				common.Log.Warnf("File %s is not listed on the %s file of %s, skipping it", file, requisitionHeaderFile, dir)
+			}
+		}
+		files = header.NodeFiles
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		node := model.RequisitionNode{}
+		if err := unmarshalData(getFileFormat(file), data, &node); err != nil {
+			return nil, fmt.Errorf("Cannot parse %s: %w", file, err)
+		}
+		requisition.Nodes = append(requisition.Nodes, node)
+	}
+	if requisition.Name == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		requisition.Name = filepath.Base(abs)
+	}
+	return requisition, nil
+}
+
+func writeRequisitionFile(file string, format string, data interface{}) error {
+	var bytes []byte
+	var err error
+	switch format {
+	case "xml":
+		bytes, err = xml.MarshalIndent(data, "", "  ")
+		bytes = append([]byte(xml.Header), bytes...)
+	case "json":
+		bytes, err = json.MarshalIndent(data, "", "  ")
+	default:
+		bytes, err = yaml.Marshal(data)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, bytes, 0644)
+}
+
+func unmarshalData(format string, data []byte, target interface{}) error {
+	switch format {
+	case "xml":
+		return xml.Unmarshal(data, target)
+	case "json":
+		return json.Unmarshal(data, target)
+	default:
+		return yaml.Unmarshal(data, target)
+	}
+}
+
+func getFileFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".xml":
+		return "xml"
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return ""
+}
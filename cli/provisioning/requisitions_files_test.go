@@ -0,0 +1,110 @@
+package provisioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+func TestRequisitionDirRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	stamp := &model.Time{Time: time.Unix(1571000000, 123000000).UTC()}
+	node := testNode
+	node.MetaData = []model.RequisitionMetaData{
+		{Context: "requisition", Key: "owner", Value: "agalue"},
+		{Context: "custom", Key: "team", Value: "netops"},
+	}
+	original := model.Requisition{
+		Name:      "Test",
+		DateStamp: stamp,
+		Nodes:     []model.RequisitionNode{node, {ForeignID: "n2", NodeLabel: "n2"}},
+	}
+	expected, _ := yaml.Marshal(original)
+
+	for _, format := range Formats {
+		target := filepath.Join(dir, format, "Test")
+		assert.NilError(t, writeRequisitionDir(target, format, original))
+		_, err := os.Stat(filepath.Join(target, "n1."+format))
+		assert.NilError(t, err)
+
+		requisition, err := readRequisitionDir(target)
+		assert.NilError(t, err)
+		assert.Assert(t, requisition.DateStamp.Equal(stamp.Time), format)
+		requisition.DateStamp = stamp
+		actual, _ := yaml.Marshal(requisition)
+		assert.Equal(t, string(expected), string(actual), format)
+	}
+
+	// Nodes removed from the requisition are removed from the directory
+	target := filepath.Join(dir, "yaml", "Test")
+	original.Nodes = original.Nodes[:1]
+	assert.NilError(t, writeRequisitionDir(target, "yaml", original))
+	_, err = os.Stat(filepath.Join(target, "n2.yaml"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	// The nodes keep the order of the requisition, and the files that are not part of the export are skipped
+	original.Nodes = []model.RequisitionNode{{ForeignID: "n2", NodeLabel: "n2"}, node}
+	assert.NilError(t, writeRequisitionDir(target, "yaml", original))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(target, "notes.yaml"), []byte("owner: netops\n"), 0644))
+	logs, restore := test.CaptureLogs()
+	defer restore()
+	requisition, err := readRequisitionDir(target)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(requisition.Nodes))
+	assert.Equal(t, "n2", requisition.Nodes[0].ForeignID)
+	assert.Equal(t, "n1", requisition.Nodes[1].ForeignID)
+	assert.Equal(t, "WARNING: File notes.yaml is not listed on the _requisition file of "+target+", skipping it\n", logs.String())
+
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(target, "_requisition.yaml"), []byte("name: Test\nnodeFiles: [../n1.yaml]\n"), 0644))
+	_, err = readRequisitionDir(target)
+	assert.Error(t, err, "Invalid node file ../n1.yaml on the _requisition file of "+target)
+
+	// Without the header file, the directory name is the requisition name
+	assert.NilError(t, os.Remove(filepath.Join(target, "_requisition.yaml")))
+	assert.NilError(t, os.Remove(filepath.Join(target, "notes.yaml")))
+	requisition, err = readRequisitionDir(target)
+	assert.NilError(t, err)
+	assert.Equal(t, "Test", requisition.Name)
+	assert.Equal(t, 2, len(requisition.Nodes))
+}
+
+func TestExportImportRequisition(t *testing.T) {
+	var err error
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = app.Run([]string{app.Name, "req", "export"})
+	assert.Error(t, err, "Requisition name required")
+
+	err = app.Run([]string{app.Name, "req", "export", "--dir", dir, "-x", "xml", "Test"})
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "Test.xml"))
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "req", "export", "--dir", dir, "-x", "yaml", "--split-nodes", "Test"})
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "Test", "n1.yaml"))
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "req", "import", "--dir", filepath.Join(dir, "Test"), "Local"})
+	assert.ErrorContains(t, err, "doesn't match")
+
+	err = app.Run([]string{app.Name, "req", "import", "--dir", filepath.Join(dir, "Test")})
+	assert.NilError(t, err)
+}
@@ -0,0 +1,147 @@
+package provisioning
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// lintSettingsFile the default location of the lint settings
+const lintSettingsFile = ".onmsctl-lint.yaml"
+
+// The exit status when the lint rules find warnings but no errors; errors use common.ExitValidationError
+const exitLintWarnings = 1
+
+func lintRequisition(c *cli.Context) error {
+	if c.Bool("list-rules") {
+		table := common.NewTable("There are no lint rules", "Rule", "Severity", "Description")
+		rules := make([]map[string]string, 0)
+		for _, r := range model.LintRules() {
+			rules = append(rules, map[string]string{"name": r.Name(), "severity": string(r.Severity()), "description": r.Description()})
+			table.AddRow(r.Name(), r.Severity(), r.Description())
+		}
+		return common.Print(rules, table)
+	}
+	settings, err := readLintSettings(c.String("config"), c.IsSet("config"))
+	if err != nil {
+		return err
+	}
+	settings.Enable = append(settings.Enable, c.StringSlice("enable")...)
+	settings.Disable = append(settings.Disable, c.StringSlice("disable")...)
+	if c.IsSet("label-pattern") {
+		settings.LabelPattern = c.String("label-pattern")
+	}
+	if c.Bool("minions") {
+		settings.Minions = true
+	}
+	linter, err := model.NewRequisitionLinter(settings)
+	if err != nil {
+		return err
+	}
+	name, issues, err := runLinter(c, linter)
+	if err != nil {
+		return err
+	}
+	table := common.NewTable(fmt.Sprintf("Requisition %s has no issues", name), "Severity", "Rule", "Foreign ID", "Message")
+	for _, issue := range issues {
+		table.AddRow(issue.Severity, issue.Rule, issue.ForeignID, issue.Message)
+	}
+	if err := common.Print(issues, table); err != nil {
+		return err
+	}
+	errors, warnings := model.CountLintIssues(issues)
+	switch {
+	case errors > 0:
+		return common.ExitError{Message: fmt.Sprintf("Requisition %s has %d errors and %d warnings", name, errors, warnings), Code: common.ExitValidationError}
+	case warnings > 0:
+		return common.ExitError{Message: fmt.Sprintf("Requisition %s has %d warnings", name, warnings), Code: exitLintWarnings}
+	}
+	return nil
+}
+
+// Lints the requisition from a file, or from the server by name; the nodes from the server are checked while they are received,
+// so the whole requisition is never held in memory
+func runLinter(c *cli.Context, linter *model.RequisitionLinter) (string, []model.LintIssue, error) {
+	if c.String("file") != "" {
+		requisition, err := parseRequisition(c)
+		if err != nil {
+			return "", nil, err
+		}
+		return requisition.Name, linter.Lint(*requisition), nil
+	}
+	name := c.Args().First()
+	if name == "" {
+		return "", nil, fmt.Errorf("Requisition name or --file required")
+	}
+	run := linter.Start(model.Requisition{Name: name})
+	validator := newStreamValidator(name)
+	_, err := getReqAPI(c).ForEachNode(name, func(node model.RequisitionNode) error {
+		if err := validator.validate(&node); err != nil {
+			return err
+		}
+		run.Check(node)
+		return nil
+	})
+	if err == nil {
+		err = validator.finish()
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return name, run.Issues(), nil
+}
+
+// streamValidator applies the checks of model.Requisition.Validate to nodes received one at a time,
+// keeping only what identifies each node and its parent
+type streamValidator struct {
+	topology   model.Requisition
+	foreignIDs map[string]bool
+}
+
+func newStreamValidator(name string) *streamValidator {
+	return &streamValidator{topology: model.Requisition{Name: name}, foreignIDs: make(map[string]bool)}
+}
+
+func (v *streamValidator) validate(node *model.RequisitionNode) error {
+	if err := node.Validate(); err != nil {
+		return common.ValidationError(fmt.Errorf("Problem on node %s on requisition %s: %s", node.NodeLabel, v.topology.Name, err.Error()))
+	}
+	if v.foreignIDs[node.ForeignID] {
+		return common.ValidationError(fmt.Errorf("Duplicate Foreign ID %s on requisition %s", node.ForeignID, v.topology.Name))
+	}
+	v.foreignIDs[node.ForeignID] = true
+	v.topology.Nodes = append(v.topology.Nodes, model.RequisitionNode{
+		ForeignID:           node.ForeignID,
+		NodeLabel:           node.NodeLabel,
+		ParentForeignSource: node.ParentForeignSource,
+		ParentForeignID:     node.ParentForeignID,
+		ParentNodeLabel:     node.ParentNodeLabel,
+	})
+	return nil
+}
+
+func (v *streamValidator) finish() error {
+	return common.ValidationError(v.topology.ValidateTopology())
+}
+
+// Reads the lint settings; a missing file is only an error when it was explicitly requested
+func readLintSettings(file string, explicit bool) (model.LintSettings, error) {
+	settings := model.LintSettings{}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("Cannot read lint settings: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, &settings); err != nil {
+		return settings, fmt.Errorf("Invalid lint settings on %s: %w", file, err)
+	}
+	return settings, nil
+}
@@ -0,0 +1,85 @@
+package provisioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestLintRequisition(t *testing.T) {
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "req", "lint", "Test")
+	assert.NilError(t, err)
+	assert.Equal(t, "Requisition Test has no issues\n", output)
+
+	output, err = test.RunWithOutput(app, "table", "req", "lint", "Local")
+	assert.Error(t, err, "Requisition Local has 1 warnings")
+	assert.Equal(t, exitLintWarnings, err.(common.ExitError).ExitStatus())
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Equal(t, "warning   no-categories  n3          There are no categories", lines[1])
+
+	_, err = test.RunWithOutput(app, "table", "req", "lint", "--disable", "no-categories", "--label-pattern", "^srv-", "Local")
+	assert.Error(t, err, "Requisition Local has 1 errors and 0 warnings")
+	assert.Equal(t, common.ExitValidationError, err.(common.ExitError).ExitStatus())
+
+	_, err = test.RunWithOutput(app, "table", "req", "lint", "--disable", "unknown", "Local")
+	assert.ErrorContains(t, err, "Unknown lint rule unknown")
+
+	output, err = test.RunWithOutput(app, "jsonpath=$[*].name", "req", "lint", "--list-rules")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(output, "snmp-primary\n"))
+}
+
+func TestLintRequisitionFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lint")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	reqFile := filepath.Join(dir, "Test.yaml")
+	configFile := filepath.Join(dir, "lint.yaml")
+	assert.NilError(t, ioutil.WriteFile(reqFile, []byte(`name: Test
+nodes:
+- foreignID: n1
+  nodeLabel: n1
+  interfaces:
+  - ipAddress: 10.0.0.1
+    snmpPrimary: N
+    status: 1
+`), 0644))
+	assert.NilError(t, ioutil.WriteFile(configFile, []byte(`disable:
+- snmp-primary
+minions: true
+severities:
+  no-categories: error
+`), 0644))
+	app := test.CreateCli(RequisitionsCliCommand)
+
+	output, err := test.RunWithOutput(app, "yaml", "req", "lint", "-x", "yaml", "-f", reqFile, "--config", configFile)
+	assert.Error(t, err, "Requisition Test has 2 errors and 0 warnings")
+	assert.Equal(t, `- rule: no-categories
+  severity: error
+  foreignID: n1
+  message: There are no categories
+- rule: missing-location
+  severity: error
+  foreignID: n1
+  message: There is no location
+`, output)
+
+	_, err = test.RunWithOutput(app, "yaml", "req", "lint", "-x", "yaml", "-f", reqFile, "--config", filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "Cannot read lint settings")
+
+	assert.NilError(t, ioutil.WriteFile(configFile, []byte("unknown: true\n"), 0644))
+	_, err = test.RunWithOutput(app, "yaml", "req", "lint", "-x", "yaml", "-f", reqFile, "--config", configFile)
+	assert.ErrorContains(t, err, "Invalid lint settings")
+}
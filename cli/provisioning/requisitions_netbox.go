@@ -0,0 +1,229 @@
+package provisioning
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/netbox"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fromNetboxCommand the CLI command to generate a requisition from the devices of a Netbox server
+var fromNetboxCommand = cli.Command{
+	Name:   "from-netbox",
+	Usage:  "Generates a requisition from the devices of a Netbox server",
+	Action: requisitionFromNetbox,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:   "url",
+			EnvVar: "NETBOX_URL",
+			Usage:  "The base URL of the Netbox server (e.x. https://netbox.example.com)",
+		},
+		cli.StringFlag{
+			Name:   "token",
+			EnvVar: "NETBOX_TOKEN",
+			Usage:  "A Netbox API token with read access to the devices",
+		},
+		cli.StringSliceFlag{
+			Name:  "site, s",
+			Usage: "Only the devices of the site with the given slug (e.x. ams1); can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  "filter",
+			Usage: "An additional filter of the devices API (e.x. 'status=active' or 'role=core-router'); can be repeated",
+		},
+		cli.StringFlag{
+			Name:  "requisition, r",
+			Usage: "The name of the requisition (defaults to the site, when there is only one)",
+		},
+		cli.StringFlag{
+			Name:  "mapping, m",
+			Usage: "YAML file with the mapping of the devices into nodes (foreignID, location, locations, categories and customFields)",
+		},
+		cli.IntFlag{
+			Name:  "page-size",
+			Value: netbox.DefaultPageSize,
+			Usage: "The number of devices requested per page",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Value: 30 * time.Second,
+			Usage: "The timeout of each request sent to Netbox",
+		},
+		cli.BoolFlag{
+			Name:  "apply",
+			Usage: "Creates or updates the nodes on the requisition; the nodes that are not on Netbox are kept unless --prune is used",
+		},
+		cli.BoolFlag{
+			Name:  "prune",
+			Usage: "With --apply, deletes the nodes of the requisition that are no longer on Netbox",
+		},
+		cli.IntFlag{
+			Name:  "max-delete-percent",
+			Value: 100,
+			Usage: "With --prune, abort when more than the given percentage of the nodes would be deleted",
+		},
+		cli.BoolFlag{
+			Name:  "import",
+			Usage: "With --apply, import the requisition after saving it",
+		},
+	},
+}
+
+func requisitionFromNetbox(c *cli.Context) error {
+	if c.String("url") == "" {
+		return fmt.Errorf("Netbox URL required")
+	}
+	sites := c.StringSlice("site")
+	name := c.String("requisition")
+	if name == "" && len(sites) == 1 {
+		name = sites[0]
+	}
+	if name == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	if c.Bool("prune") && !c.Bool("apply") {
+		return fmt.Errorf("--prune can only be used with --apply")
+	}
+	maxPercent := c.Int("max-delete-percent")
+	if maxPercent < 0 || maxPercent > 100 {
+		return fmt.Errorf("Invalid maximum percentage %d, expected a value between 0 and 100", maxPercent)
+	}
+	mapping, err := getNetboxMapping(c.String("mapping"))
+	if err != nil {
+		return err
+	}
+	filters := url.Values{}
+	for _, site := range sites {
+		filters.Add("site", site)
+	}
+	for _, filter := range c.StringSlice("filter") {
+		data := strings.SplitN(filter, "=", 2)
+		if len(data) != 2 || data[0] == "" {
+			return fmt.Errorf("Invalid filter %s, expected key=value", filter)
+		}
+		filters.Add(data[0], data[1])
+	}
+	client := netbox.Client{
+		URL:      c.String("url"),
+		Token:    c.String("token"),
+		Timeout:  c.Duration("timeout"),
+		PageSize: c.Int("page-size"),
+		Sleep:    common.GetClient(c).Sleep,
+		Progress: func(fetched int, total int) {
+			common.Log.Debugf("Obtained %d of %d devices from Netbox", fetched, total)
+		},
+	}
+	devices, err := client.GetDevices(filters)
+	if err != nil {
+		return err
+	}
+	requisition := buildNetboxRequisition(name, devices, *mapping)
+	if !c.Bool("apply") {
+		if err := requisition.Validate(); err != nil {
+			return common.ValidationError(err)
+		}
+		data, _ := yaml.Marshal(requisition)
+		fmt.Fprintln(common.Output, string(data))
+		return nil
+	}
+	return common.Apply(requisition, func() error {
+		return saveNetboxRequisition(c, requisition, maxPercent)
+	})
+}
+
+// Reads the mapping file, or returns the default mapping when there is none
+func getNetboxMapping(file string) (*netbox.Mapping, error) {
+	if file == "" {
+		mapping := netbox.DefaultMapping
+		return &mapping, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := netbox.ParseMapping(data)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot parse %s: %w", file, err)
+	}
+	return mapping, nil
+}
+
+// Builds a requisition with a node per device; the devices that cannot be mapped (e.x. without name) are skipped with a warning
+func buildNetboxRequisition(name string, devices []netbox.Device, mapping netbox.Mapping) *model.Requisition {
+	requisition := &model.Requisition{Name: name, Nodes: []model.RequisitionNode{}}
+	for _, device := range devices {
+		node, err := mapping.Map(device)
+		if err != nil {
+			common.Log.Warnf("Skipping device: %s", err)
+			continue
+		}
+		if len(node.Interfaces) == 0 {
+			common.Log.Warnf("Device %s has no primary IP address, the node won't have interfaces", device.Name)
+		}
+		requisition.Nodes = append(requisition.Nodes, *node)
+	}
+	return requisition
+}
+
+// Merges the nodes from Netbox into the existing requisition, removing the nodes that are not on Netbox with --prune
+func saveNetboxRequisition(c *cli.Context, generated *model.Requisition, maxPercent int) error {
+	name := generated.Name
+	target := &model.Requisition{Name: name}
+	if getUtilsAPI(c).RequisitionExists(name) {
+		existing, err := getReqAPI(c).GetRequisition(name)
+		if err != nil {
+			return err
+		}
+		target = existing
+	}
+	total := len(target.Nodes)
+	if c.Bool("prune") {
+		keep := make(map[string]bool)
+		for _, n := range generated.Nodes {
+			keep[n.ForeignID] = true
+		}
+		removed := target.Prune(keep)
+		for _, n := range removed {
+			fmt.Fprintf(common.Output, "- node %s (%s)\n", n.ForeignID, n.NodeLabel)
+		}
+		if len(removed)*100 > maxPercent*total {
+			return fmt.Errorf("Pruning would delete %d of %d nodes from requisition %s, more than the maximum of %d%%", len(removed), total, name, maxPercent)
+		}
+	}
+	added, updated := 0, 0
+	for _, node := range generated.Nodes {
+		replaced := false
+		for i := range target.Nodes {
+			if target.Nodes[i].ForeignID == node.ForeignID {
+				target.Nodes[i] = node
+				replaced = true
+				break
+			}
+		}
+		if replaced {
+			updated++
+		} else {
+			target.Nodes = append(target.Nodes, node)
+			added++
+		}
+	}
+	if err := target.Validate(); err != nil {
+		return common.ValidationError(err)
+	}
+	if err := getReqAPI(c).SetRequisition(*target); err != nil {
+		return err
+	}
+	common.Log.Infof("Requisition %s saved with %d nodes from Netbox (%d added, %d updated, %d deleted)", name, len(target.Nodes), added, updated, total+added-len(target.Nodes))
+	if c.Bool("import") {
+		return getReqAPI(c).ImportRequisition(name, model.RescanAll)
+	}
+	return nil
+}
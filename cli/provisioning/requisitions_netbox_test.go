@@ -0,0 +1,107 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+
+	"gopkg.in/yaml.v2"
+)
+
+const testNetboxDevices = `{"count": 3, "next": null, "results": [
+  {"id": 1, "name": "ams1-core-01", "device_role": {"id": 1, "name": "Core Router", "slug": "core-router"}, "site": {"id": 4, "name": "Amsterdam 1", "slug": "ams1"},
+   "primary_ip": {"id": 51, "address": "10.4.0.1/24"}, "tags": [{"id": 1, "name": "Production", "slug": "production"}], "custom_fields": {"contract": "CT-1"}},
+  {"id": 2, "name": "ams1-sw-01", "device_role": {"id": 2, "name": "Access Switch", "slug": "access-switch"}, "site": {"id": 4, "name": "Amsterdam 1", "slug": "ams1"},
+   "primary_ip": null, "tags": [], "custom_fields": {}},
+  {"id": 3, "name": null, "device_role": {"id": 3, "name": "PDU", "slug": "pdu"}, "site": {"id": 4, "name": "Amsterdam 1", "slug": "ams1"}}
+]}`
+
+func TestRequisitionFromNetbox(t *testing.T) {
+	var saved *model.Requisition
+	var imported bool
+	app := test.CreateCli(RequisitionsCliCommand)
+	logs, restore := test.CaptureLogs()
+	defer restore()
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/dcim/devices/":
+			assert.Equal(t, "Token secret", req.Header.Get("Authorization"))
+			assert.DeepEqual(t, []string{"ams1"}, req.URL.Query()["site"])
+			assert.Equal(t, "active", req.URL.Query().Get("status"))
+			res.Write([]byte(testNetboxDevices))
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"ams1"}})
+		case "/rest/requisitions/ams1":
+			sendData(res, model.Requisition{Name: "ams1", Nodes: []model.RequisitionNode{
+				{ForeignID: "ams1-core-01", NodeLabel: "old-label"},
+				{ForeignID: "ams1-old-01", NodeLabel: "ams1-old-01"},
+			}})
+		case "/rest/requisitions":
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			saved = &model.Requisition{}
+			assert.NilError(t, json.Unmarshal(bytes, saved))
+		case "/rest/requisitions/ams1/import":
+			imported = true
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	test.Client(app).URL = server.URL
+
+	_, err := test.RunWithOutput(app, "table", "req", "from-netbox", "--site", "ams1")
+	assert.Error(t, err, "Netbox URL required")
+
+	args := []string{"req", "from-netbox", "--url", server.URL, "--token", "secret", "--site", "ams1", "--filter", "status=active"}
+	_, err = test.RunWithOutput(app, "table", append(args, "--prune")...)
+	assert.Error(t, err, "--prune can only be used with --apply")
+
+	_, err = test.RunWithOutput(app, "table", append(args, "--filter", "active")...)
+	assert.Error(t, err, "Invalid filter active, expected key=value")
+
+	// The device without name is skipped
+	output, err := test.RunWithOutput(app, "table", args...)
+	assert.NilError(t, err)
+	requisition := model.Requisition{}
+	assert.NilError(t, yaml.Unmarshal([]byte(output), &requisition))
+	assert.Equal(t, "ams1", requisition.Name)
+	assert.Equal(t, 2, len(requisition.Nodes))
+	node := requisition.Nodes[0]
+	assert.Equal(t, "ams1-core-01", node.ForeignID)
+	assert.Equal(t, "Amsterdam 1", node.Location)
+	assert.Equal(t, "10.4.0.1", node.Interfaces[0].IPAddress)
+	assert.DeepEqual(t, []model.RequisitionCategory{{Name: "Core Router"}, {Name: "Production"}}, node.Categories)
+	assert.DeepEqual(t, []model.RequisitionMetaData{{Context: "requisition", Key: "contract", Value: "CT-1"}}, node.MetaData)
+	assert.Equal(t, 0, len(requisition.Nodes[1].Interfaces))
+	assert.Assert(t, saved == nil)
+
+	// Without --prune, the nodes that are not on Netbox are kept
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", append(args, "--apply")...)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(logs.String(), "Requisition ams1 saved with 3 nodes from Netbox (1 added, 1 updated, 0 deleted)\n"), logs.String())
+	assert.Equal(t, 3, len(saved.Nodes))
+	assert.Equal(t, "ams1-core-01", saved.Nodes[0].NodeLabel)
+	assert.Equal(t, "ams1-old-01", saved.Nodes[1].ForeignID)
+	assert.Assert(t, !imported)
+
+	_, err = test.RunWithOutput(app, "table", append(args, "--apply", "--prune", "--max-delete-percent", "10")...)
+	assert.Error(t, err, "Pruning would delete 1 of 2 nodes from requisition ams1, more than the maximum of 10%")
+
+	logs.Reset()
+	output, err = test.RunWithOutput(app, "table", append(args, "--apply", "--prune", "--import")...)
+	assert.NilError(t, err)
+	assert.Equal(t, "- node ams1-old-01 (ams1-old-01)\n", output)
+	assert.Assert(t, strings.HasSuffix(logs.String(), "Requisition ams1 saved with 2 nodes from Netbox (1 added, 1 updated, 1 deleted)\n"), logs.String())
+	assert.Equal(t, 2, len(saved.Nodes))
+	assert.Equal(t, "ams1-sw-01", saved.Nodes[1].ForeignID)
+	assert.Assert(t, imported)
+}
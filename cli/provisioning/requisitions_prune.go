@@ -0,0 +1,124 @@
+package provisioning
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The flags of the prune command
+func pruneRequisitionFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "keep-file, k",
+			Usage: "File with the foreign IDs to keep, one per line (empty lines and lines starting with # are ignored)",
+		},
+		cli.StringFlag{
+			Name:  "keep-from-yaml",
+			Usage: "Local requisition in YAML, whose foreign IDs are kept",
+		},
+		cli.BoolFlag{
+			Name:  "apply",
+			Usage: "Delete the nodes; otherwise, the nodes that would be deleted are only displayed",
+		},
+		cli.BoolFlag{
+			Name:  "import",
+			Usage: "Import the requisition after deleting the nodes, to remove them from the database",
+		},
+		cli.IntFlag{
+			Name:  "max-delete-percent",
+			Value: 100,
+			Usage: "Abort when more than the given percentage of the nodes would be deleted",
+		},
+	}
+}
+
+// Deletes the nodes of a requisition that are not on the keep set, typically generated from an external source of truth
+func pruneRequisition(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	maxPercent := c.Int("max-delete-percent")
+	if maxPercent < 0 || maxPercent > 100 {
+		return fmt.Errorf("Invalid maximum percentage %d, expected a value between 0 and 100", maxPercent)
+	}
+	keep, err := readKeepSet(c.String("keep-file"), c.String("keep-from-yaml"))
+	if err != nil {
+		return err
+	}
+	if len(keep) == 0 {
+		return fmt.Errorf("There are no foreign IDs to keep; refusing to delete all the nodes of requisition %s", name)
+	}
+	requisition, err := getReqAPI(c).GetRequisition(name)
+	if err != nil {
+		return err
+	}
+	total := len(requisition.Nodes)
+	removed := requisition.Prune(keep)
+	if len(removed) == 0 {
+		common.Log.Infof("Requisition %s has no nodes to prune", name)
+		return nil
+	}
+	for _, n := range removed {
+		fmt.Fprintf(common.Output, "- node %s (%s)\n", n.ForeignID, n.NodeLabel)
+	}
+	if len(removed)*100 > maxPercent*total {
+		return fmt.Errorf("Pruning would delete %d of %d nodes from requisition %s, more than the maximum of %d%%", len(removed), total, name, maxPercent)
+	}
+	if !c.Bool("apply") {
+		common.Log.Infof("%d of %d nodes would be deleted from requisition %s; use --apply to delete them", len(removed), total, name)
+		return nil
+	}
+	if err := getReqAPI(c).SetRequisition(*requisition); err != nil {
+		return err
+	}
+	common.Log.Infof("%d of %d nodes deleted from requisition %s", len(removed), total, name)
+	if c.Bool("import") {
+		return getReqAPI(c).ImportRequisition(name, model.RescanAll)
+	}
+	return nil
+}
+
+// Reads the foreign IDs to keep from a plain list or from a local requisition
+func readKeepSet(keepFile string, keepYAML string) (map[string]bool, error) {
+	if (keepFile == "") == (keepYAML == "") {
+		return nil, fmt.Errorf("Either --keep-file or --keep-from-yaml is required")
+	}
+	keep := make(map[string]bool)
+	if keepYAML != "" {
+		data, err := ioutil.ReadFile(keepYAML)
+		if err != nil {
+			return nil, err
+		}
+		requisition := &model.Requisition{}
+		if err := yaml.Unmarshal(data, requisition); err != nil {
+			return nil, fmt.Errorf("Cannot parse %s: %w", keepYAML, err)
+		}
+		for _, n := range requisition.Nodes {
+			keep[n.ForeignID] = true
+		}
+		return keep, nil
+	}
+	data, err := ioutil.ReadFile(keepFile)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keep[line] = true
+	}
+	return keep, scanner.Err()
+}
@@ -0,0 +1,87 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestPruneRequisition(t *testing.T) {
+	var posted *model.Requisition
+	imports := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"CMDB"}})
+		case req.URL.Path == "/rest/requisitions/CMDB" && req.Method == http.MethodGet:
+			sendData(res, model.Requisition{
+				Name: "CMDB",
+				Nodes: []model.RequisitionNode{
+					{ForeignID: "n1", NodeLabel: "srv01"},
+					{ForeignID: "n2", NodeLabel: "srv02"},
+					{ForeignID: "n3", NodeLabel: "srv03"},
+					{ForeignID: "n4", NodeLabel: "srv04"},
+				},
+			})
+		case req.URL.Path == "/rest/requisitions" && req.Method == http.MethodPost:
+			posted = &model.Requisition{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, posted))
+		case req.URL.Path == "/rest/requisitions/CMDB/import":
+			imports++
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	keepFile := filepath.Join(dir, "ids.txt")
+	assert.NilError(t, ioutil.WriteFile(keepFile, []byte("# Exported from the CMDB\nn1\n\nn2\n n3 \n"), 0644))
+	emptyFile := filepath.Join(dir, "empty.txt")
+	assert.NilError(t, ioutil.WriteFile(emptyFile, []byte("\n"), 0644))
+	keepYAML := filepath.Join(dir, "cmdb.yaml")
+	assert.NilError(t, ioutil.WriteFile(keepYAML, []byte("name: CMDB\nnodes:\n- foreignID: n1\n  nodeLabel: srv01\n"), 0644))
+
+	app := test.CreateCli(RequisitionsCliCommand, rest.WithURL(server.URL))
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err = test.RunWithOutput(app, "table", "req", "prune", "CMDB")
+	assert.Error(t, err, "Either --keep-file or --keep-from-yaml is required")
+	_, err = test.RunWithOutput(app, "table", "req", "prune", "--keep-file", emptyFile, "CMDB")
+	assert.Error(t, err, "There are no foreign IDs to keep; refusing to delete all the nodes of requisition CMDB")
+	_, err = test.RunWithOutput(app, "table", "req", "prune", "--keep-file", keepFile, "--max-delete-percent", "101", "CMDB")
+	assert.Error(t, err, "Invalid maximum percentage 101, expected a value between 0 and 100")
+
+	output, err := test.RunWithOutput(app, "table", "req", "prune", "--keep-file", keepFile, "CMDB")
+	assert.NilError(t, err)
+	assert.Equal(t, "- node n4 (srv04)\n", output)
+	assert.Equal(t, "1 of 4 nodes would be deleted from requisition CMDB; use --apply to delete them\n", logs.String())
+	assert.Assert(t, posted == nil)
+
+	output, err = test.RunWithOutput(app, "table", "req", "prune", "--keep-from-yaml", keepYAML, "--max-delete-percent", "50", "--apply", "CMDB")
+	assert.Error(t, err, "Pruning would delete 3 of 4 nodes from requisition CMDB, more than the maximum of 50%")
+	assert.Equal(t, "- node n2 (srv02)\n- node n3 (srv03)\n- node n4 (srv04)\n", output)
+	assert.Assert(t, posted == nil)
+
+	logs.Reset()
+	output, err = test.RunWithOutput(app, "table", "req", "prune", "--keep-file", keepFile, "--max-delete-percent", "25", "--apply", "--import", "CMDB")
+	assert.NilError(t, err)
+	assert.Equal(t, "- node n4 (srv04)\n", output)
+	assert.Equal(t, "1 of 4 nodes deleted from requisition CMDB\n", logs.String())
+	assert.Equal(t, 3, len(posted.Nodes))
+	assert.Assert(t, posted.GetNode("n4") == nil)
+	assert.Equal(t, 1, imports)
+}
@@ -0,0 +1,115 @@
+package provisioning
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// The flags shared by rename and clone
+func copyRequisitionFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:  "with-foreign-source",
+			Usage: "Copy the foreign source definition (detectors and policies) too; otherwise the new requisition uses the default one",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Value: 5 * time.Minute,
+			Usage: "Maximum time to wait for the import of the new requisition",
+		},
+		cli.DurationFlag{
+			Name:  "poll-interval",
+			Value: 5 * time.Second,
+			Usage: "Time between checks of the import status",
+		},
+	}
+}
+
+func renameRequisition(c *cli.Context) error {
+	return copyRequisition(c, true)
+}
+
+func cloneRequisition(c *cli.Context) error {
+	return copyRequisition(c, false)
+}
+
+// Sends a copy of a requisition under a new name and imports it; when renaming,
+// the old requisition is deleted only after the new one is deployed with all its nodes
+func copyRequisition(c *cli.Context, rename bool) error {
+	oldName := c.Args().Get(0)
+	newName := c.Args().Get(1)
+	if oldName == "" || newName == "" {
+		return fmt.Errorf("The current and the new requisition names are required")
+	}
+	if oldName == newName {
+		return fmt.Errorf("The new name must be different than %s", oldName)
+	}
+	if getUtilsAPI(c).RequisitionExists(newName) {
+		return fmt.Errorf("Requisition %s already exists", newName)
+	}
+	requisition, err := getReqAPI(c).GetRequisition(oldName)
+	if err != nil {
+		return err
+	}
+	changed := requisition.Rename(newName)
+	if err := requisition.Validate(); err != nil {
+		return common.ValidationError(err)
+	}
+	if len(changed) > 0 {
+		common.Log.Warnf("The parent foreign source of nodes %s was changed from %s to %s", strings.Join(changed, ", "), oldName, newName)
+	}
+	if rename {
+		stats, err := getReqAPI(c).GetRequisitionsStats()
+		if err != nil {
+			return err
+		}
+		deployed := len(stats.GetRequisitionStats(oldName).ForeignIDs)
+		description := fmt.Sprintf("Requisition %s will be copied to %s and imported; then %s will be deleted, removing its %d deployed nodes from the database (the new nodes won't keep their history)", oldName, newName, oldName, deployed)
+		if err := common.Confirm(c, description); err != nil {
+			return err
+		}
+	}
+	if c.Bool("with-foreign-source") {
+		fsDef, err := getFsAPI(c).GetForeignSourceDef(oldName)
+		if err != nil {
+			return err
+		}
+		fsDef.Name = newName
+		if err := getFsAPI(c).SetForeignSourceDef(*fsDef); err != nil {
+			return err
+		}
+	}
+	if err := getReqAPI(c).SetRequisition(*requisition); err != nil {
+		return err
+	}
+	if err := getReqAPI(c).ImportRequisition(newName, model.RescanAll); err != nil {
+		return err
+	}
+	current, err := getReqAPI(c).WaitForImport(newName, nil, c.Duration("timeout"), c.Duration("poll-interval"))
+	if err != nil {
+		if rename {
+			return fmt.Errorf("%s; requisition %s was not deleted", err, oldName)
+		}
+		return err
+	}
+	if len(current.ForeignIDs) != len(requisition.Nodes) {
+		if rename {
+			return fmt.Errorf("Requisition %s was imported with %d of %d nodes; requisition %s was not deleted", newName, len(current.ForeignIDs), len(requisition.Nodes), oldName)
+		}
+		return fmt.Errorf("Requisition %s was imported with %d of %d nodes", newName, len(current.ForeignIDs), len(requisition.Nodes))
+	}
+	if !rename {
+		common.Log.Infof("Requisition %s cloned to %s with %d nodes", oldName, newName, len(current.ForeignIDs))
+		return nil
+	}
+	if err := getReqAPI(c).DeleteRequisition(oldName); err != nil {
+		return fmt.Errorf("Requisition %s was deployed, but %s cannot be deleted: %w", newName, oldName, err)
+	}
+	common.Log.Infof("Requisition %s renamed to %s with %d nodes", oldName, newName, len(current.ForeignIDs))
+	return nil
+}
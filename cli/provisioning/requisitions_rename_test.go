@@ -0,0 +1,171 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+// renameTestServer a server with requisition Old, that deploys the requisitions when imported
+type renameTestServer struct {
+	*httptest.Server
+	lock         sync.Mutex
+	requisitions map[string]model.Requisition
+	fsDefs       map[string]model.ForeignSourceDef
+	deployed     map[string]int // The amount of nodes deployed when imported
+}
+
+func createRenameTestServer(t *testing.T) *renameTestServer {
+	s := &renameTestServer{
+		requisitions: map[string]model.Requisition{
+			"Old": {
+				Name: "Old",
+				Nodes: []model.RequisitionNode{
+					{ForeignID: "router", NodeLabel: "router"},
+					{ForeignID: "switch", NodeLabel: "switch", ParentForeignSource: "Old", ParentForeignID: "router"},
+				},
+			},
+		},
+		fsDefs:   map[string]model.ForeignSourceDef{"Old": {Name: "Old", ScanInterval: "1d"}},
+		deployed: make(map[string]int),
+	}
+	imported := make(map[string]bool)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		path := req.URL.Path
+		switch {
+		case path == "/rest/requisitionNames":
+			list := model.RequisitionsList{}
+			for name := range s.requisitions {
+				list.ForeignSources = append(list.ForeignSources, name)
+			}
+			list.Count = len(list.ForeignSources)
+			sendData(res, list)
+		case path == "/rest/requisitions/deployed/stats":
+			stats := model.RequisitionsStats{}
+			for name := range s.requisitions {
+				st := model.RequisitionStats{Name: name, ForeignIDs: []string{}}
+				if imported[name] {
+					st.LastImport = &model.Time{Time: time.Now()}
+					for i := 0; i < s.deployed[name]; i++ {
+						st.ForeignIDs = append(st.ForeignIDs, s.requisitions[name].Nodes[i].ForeignID)
+					}
+				}
+				stats.ForeignSources = append(stats.ForeignSources, st)
+			}
+			sendData(res, stats)
+		case path == "/rest/requisitions" && req.Method == http.MethodPost:
+			r := model.Requisition{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, &r))
+			s.requisitions[r.Name] = r
+			if _, ok := s.deployed[r.Name]; !ok {
+				s.deployed[r.Name] = len(r.Nodes)
+			}
+		case path == "/rest/foreignSources" && req.Method == http.MethodPost:
+			fs := model.ForeignSourceDef{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, &fs))
+			s.fsDefs[fs.Name] = fs
+		case strings.HasSuffix(path, "/import"):
+			imported[strings.Split(path, "/")[3]] = true
+		case strings.HasPrefix(path, "/rest/foreignSources/") && req.Method == http.MethodGet:
+			sendData(res, s.fsDefs[strings.TrimPrefix(path, "/rest/foreignSources/")])
+		case strings.HasPrefix(path, "/rest/requisitions/deployed/") && req.Method == http.MethodDelete:
+		case strings.HasPrefix(path, "/rest/foreignSources/") && req.Method == http.MethodDelete:
+			delete(s.fsDefs, path[strings.LastIndex(path, "/")+1:])
+		case strings.HasPrefix(path, "/rest/requisitions/") && req.Method == http.MethodDelete:
+			delete(s.requisitions, strings.TrimPrefix(path, "/rest/requisitions/"))
+		case strings.HasPrefix(path, "/rest/requisitions/") && req.Method == http.MethodGet:
+			r, ok := s.requisitions[strings.TrimPrefix(path, "/rest/requisitions/")]
+			if !ok {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			sendData(res, r)
+		default:
+			res.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	return s
+}
+
+func TestRenameRequisition(t *testing.T) {
+	var err error
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createRenameTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "req", "rename", "Old"})
+	assert.Error(t, err, "The current and the new requisition names are required")
+
+	err = app.Run([]string{app.Name, "req", "rename", "Old", "Old"})
+	assert.Error(t, err, "The new name must be different than Old")
+
+	err = app.Run([]string{app.Name, "req", "rename", "Old", "Bad/Name", "--yes"})
+	assert.ErrorContains(t, err, "Invalid characters on requisition name Bad/Name")
+
+	// The old requisition is kept when not all the nodes are deployed
+	server.deployed["Incomplete"] = 1
+	_, err = test.RunWithOutput(app, "table", "req", "rename", "Old", "Incomplete", "--yes", "--poll-interval", "1ms")
+	assert.Error(t, err, "Requisition Incomplete was imported with 1 of 2 nodes; requisition Old was not deleted")
+	_, ok := server.requisitions["Old"]
+	assert.Assert(t, ok)
+
+	err = app.Run([]string{app.Name, "req", "rename", "Old", "Incomplete", "--yes"})
+	assert.Error(t, err, "Requisition Incomplete already exists")
+
+	logs, restore := test.CaptureLogs()
+	defer restore()
+	_, err = test.RunWithOutput(app, "table", "req", "rename", "Old", "New", "--yes", "--with-foreign-source", "--poll-interval", "1ms")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(logs.String(), "WARNING: The parent foreign source of nodes switch was changed from Old to New\n"))
+	assert.Assert(t, strings.Contains(logs.String(), "Requisition Old renamed to New with 2 nodes\n"))
+	assert.Equal(t, "New", server.requisitions["New"].Nodes[1].ParentForeignSource)
+	assert.Equal(t, "1d", server.fsDefs["New"].ScanInterval)
+	_, ok = server.requisitions["Old"]
+	assert.Assert(t, !ok)
+}
+
+func TestRenameRequisitionCancelled(t *testing.T) {
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createRenameTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+	common.ConfirmInput = strings.NewReader("n\n")
+	defer func() { common.ConfirmInput = nil }()
+
+	err := app.Run([]string{app.Name, "req", "rename", "Old", "New"})
+	assert.Error(t, err, "Operation cancelled")
+	assert.Equal(t, 1, len(server.requisitions))
+}
+
+func TestCloneRequisition(t *testing.T) {
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createRenameTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "req", "clone", "Old", "Copy", "--poll-interval", "1ms")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(logs.String(), "Requisition Old cloned to Copy with 2 nodes\n"))
+	assert.Equal(t, "Old", server.requisitions["Old"].Nodes[1].ParentForeignSource)
+	assert.Equal(t, "Copy", server.requisitions["Copy"].Nodes[1].ParentForeignSource)
+	_, ok := server.fsDefs["Copy"]
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,58 @@
+package provisioning
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+func renderRequisition(c *cli.Context) error {
+	templateFile := c.String("template")
+	if templateFile == "" {
+		return fmt.Errorf("Template file required")
+	}
+	content, err := ioutil.ReadFile(templateFile)
+	if err != nil {
+		return fmt.Errorf("Cannot read template: %w", err)
+	}
+	values := make(map[string]interface{})
+	if valuesFile := c.String("values"); valuesFile != "" {
+		data, err := ioutil.ReadFile(valuesFile)
+		if err != nil {
+			return fmt.Errorf("Cannot read values: %w", err)
+		}
+		if values, err = common.ReadTemplateValues(data); err != nil {
+			return fmt.Errorf("Cannot parse values from %s: %w", valuesFile, err)
+		}
+	}
+	for _, expression := range c.StringSlice("set") {
+		if err := common.SetTemplateValue(values, expression); err != nil {
+			return err
+		}
+	}
+	data, err := common.RenderTemplate(filepath.Base(templateFile), content, values)
+	if err != nil {
+		return err
+	}
+	requisition := &model.Requisition{}
+	if err := yaml.Unmarshal(data, requisition); err != nil {
+		return fmt.Errorf("The rendered template is not a valid requisition: %w", err)
+	}
+	if !c.Bool("apply") {
+		if err := requisition.Validate(); err != nil {
+			return common.ValidationError(err)
+		}
+		data, _ := yaml.Marshal(requisition)
+		fmt.Fprintln(common.Output, string(data))
+		return nil
+	}
+	return common.Apply(requisition, func() error {
+		return getReqAPI(c).SetRequisition(*requisition)
+	})
+}
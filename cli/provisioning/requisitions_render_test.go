@@ -0,0 +1,53 @@
+package provisioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+const siteTemplate = `name: {{ .site.name }}
+nodes:
+{{- range $i := until .site.nodes }}
+- foreignID: {{ $.site.name | lower }}-{{ add $i 1 }}
+  interfaces:
+  - ipAddress: {{ $.site.network }}.{{ add $i 1 }}
+{{- end }}
+`
+
+func TestRenderRequisition(t *testing.T) {
+	var err error
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	templateFile := filepath.Join(dir, "site.yaml.tmpl")
+	assert.NilError(t, ioutil.WriteFile(templateFile, []byte(siteTemplate), 0644))
+	valuesFile := filepath.Join(dir, "site-A.yaml")
+	assert.NilError(t, ioutil.WriteFile(valuesFile, []byte("site:\n  name: Durham\n  nodes: 2\n  network: 10.0.0\n"), 0644))
+	brokenFile := filepath.Join(dir, "broken.yaml.tmpl")
+	assert.NilError(t, ioutil.WriteFile(brokenFile, []byte("name: Test\nnodes:\n{{ .nodes | unknown }}\n"), 0644))
+
+	err = app.Run([]string{app.Name, "req", "render"})
+	assert.Error(t, err, "Template file required")
+
+	err = app.Run([]string{app.Name, "req", "render", "-t", brokenFile})
+	assert.ErrorContains(t, err, "broken.yaml.tmpl:3")
+
+	err = app.Run([]string{app.Name, "req", "render", "-t", templateFile, "-f", valuesFile})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "req", "render", "-t", templateFile, "-f", valuesFile, "--set", "site.name=A/B"})
+	assert.ErrorContains(t, err, "Invalid characters on requisition name A/B")
+
+	err = app.Run([]string{app.Name, "req", "render", "-t", templateFile, "-f", valuesFile, "--set", "site.nodes=3", "--apply"})
+	assert.NilError(t, err)
+}
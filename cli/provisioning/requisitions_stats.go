@@ -0,0 +1,102 @@
+package provisioning
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// requisitionsComparison the result of comparing the pending requisitions against the deployed ones
+type requisitionsComparison struct {
+	Requisitions []model.DeployedComparison `json:"requisitions" yaml:"requisitions"`
+	PendingOnly  int                        `json:"pending-only" yaml:"pendingOnly"`
+	DeployedOnly int                        `json:"deployed-only" yaml:"deployedOnly"`
+	Summary      string                     `json:"summary" yaml:"summary"`
+}
+
+func showRequisitionStats(c *cli.Context) error {
+	names, err := getStatsRequisitionNames(c, c.Args().First())
+	if err != nil {
+		return err
+	}
+	statistics, err := getReqAPI(c).GetRequisitionsStats()
+	if err != nil {
+		return err
+	}
+	if c.Bool("compare") {
+		return compareRequisitions(c, names, statistics, c.Int("concurrency"))
+	}
+	list := model.RequisitionsStats{ForeignSources: make([]model.RequisitionStats, 0)}
+	table := common.NewTable("There are no requisitions", "Requisition", "Deployed Nodes", "Last Import")
+	for _, name := range names {
+		stats := statistics.GetRequisitionStats(name)
+		stats.Name = name
+		stats.Count = len(stats.ForeignIDs)
+		if stats.ForeignIDs == nil {
+			stats.ForeignIDs = make([]string, 0)
+		}
+		list.ForeignSources = append(list.ForeignSources, stats)
+		table.AddRow(name, stats.Count, common.DisplayTime(stats.LastImport))
+	}
+	list.Count = len(list.ForeignSources)
+	return common.Print(list, table)
+}
+
+// Fetches the pending requisitions, and reports the nodes that are not on both the pending and the deployed versions
+func compareRequisitions(c *cli.Context, names []string, statistics *model.RequisitionsStats, concurrency int) error {
+	requisitions, failures := getReqAPI(c).GetRequisitions(names, concurrency)
+	result := requisitionsComparison{Requisitions: make([]model.DeployedComparison, 0)}
+	table := common.NewTable("There are no requisitions", "Requisition", "Pending Nodes", "Deployed Nodes", "Pending Only", "Deployed Only", "Last Import")
+	for _, req := range requisitions {
+		cmp := req.CompareDeployed(statistics.GetRequisitionStats(req.Name))
+		result.Requisitions = append(result.Requisitions, cmp)
+		result.PendingOnly += len(cmp.PendingOnly)
+		result.DeployedOnly += len(cmp.DeployedOnly)
+		table.AddRow(cmp.Name, cmp.PendingNodes, cmp.DeployedNodes, formatForeignIDs(cmp.PendingOnly), formatForeignIDs(cmp.DeployedOnly), common.DisplayTime(cmp.LastImport))
+	}
+	result.Summary = fmt.Sprintf("%d pending-only, %d deployed-only", result.PendingOnly, result.DeployedOnly)
+	if err := common.Print(result, table); err != nil {
+		return err
+	}
+	if common.OutputFormat == common.OutputTable {
+		fmt.Fprintln(common.Output, result.Summary)
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	failed := make([]string, 0, len(failures))
+	for name := range failures {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	for _, name := range failed {
+		common.Log.Warnf("Cannot compare requisition %s: %s", name, failures[name])
+	}
+	return fmt.Errorf("Cannot compare %d of %d requisitions", len(failures), len(names))
+}
+
+// Returns the given requisition if it exists, or all of them when the name is empty
+func getStatsRequisitionNames(c *cli.Context, name string) ([]string, error) {
+	if name != "" {
+		if !getUtilsAPI(c).RequisitionExists(name) {
+			return nil, fmt.Errorf("Requisition %s doesn't exist", name)
+		}
+		return []string{name}, nil
+	}
+	list, err := getUtilsAPI(c).GetRequisitionNames()
+	if err != nil {
+		return nil, err
+	}
+	return list.ForeignSources, nil
+}
+
+func formatForeignIDs(ids []string) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,46 @@
+package provisioning
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestRequisitionStats(t *testing.T) {
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "jsonpath=$.foreign-source[*].count", "req", "stats")
+	assert.NilError(t, err)
+	assert.Equal(t, "0\n0\n", output)
+
+	_, err = test.RunWithOutput(app, "table", "req", "stats", "Unknown")
+	assert.Error(t, err, "Requisition Unknown doesn't exist")
+
+	output, err = test.RunWithOutput(app, "table", "req", "stats", "--compare")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.Assert(t, strings.HasPrefix(lines[0], "Requisition  Pending Nodes  Deployed Nodes  Pending Only  Deployed Only  Last Import"))
+	assert.Assert(t, strings.HasPrefix(lines[1], "Test         1              0               n1            -"))
+	assert.Assert(t, strings.HasPrefix(lines[2], "Local        1              0               n3            -              Never"))
+	assert.Equal(t, "2 pending-only, 0 deployed-only", lines[3])
+
+	output, err = test.RunWithOutput(app, "yaml", "req", "stats", "--compare", "Local")
+	assert.NilError(t, err)
+	assert.Equal(t, `requisitions:
+- name: Local
+  pendingNodes: 1
+  deployedNodes: 0
+  pendingOnly:
+  - n3
+  deployedOnly: []
+pendingOnly: 1
+deployedOnly: 0
+summary: 1 pending-only, 0 deployed-only
+`, output)
+}
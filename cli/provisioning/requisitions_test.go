@@ -1,9 +1,22 @@
 package provisioning
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/OpenNMS/onmsctl/test"
 	"gopkg.in/yaml.v2"
 	"gotest.tools/assert"
@@ -13,6 +26,7 @@ func TestListRequisitions(t *testing.T) {
 	var err error
 	app := test.CreateCli(RequisitionsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "req", "list"})
@@ -23,6 +37,7 @@ func TestGetRequisition(t *testing.T) {
 	var err error
 	app := test.CreateCli(RequisitionsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "req", "get"})
@@ -36,6 +51,7 @@ func TestAddRequisition(t *testing.T) {
 	var err error
 	app := test.CreateCli(RequisitionsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "req", "add"})
@@ -49,11 +65,18 @@ func TestDeleteRequisition(t *testing.T) {
 	var err error
 	app := test.CreateCli(RequisitionsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "req", "delete"})
 	assert.Error(t, err, "Requisition name required")
 
+	defer func() { common.ConfirmInput = nil }()
+	common.ConfirmInput = strings.NewReader("y\n")
+	err = app.Run([]string{app.Name, "req", "delete", "Local"})
+	assert.Error(t, err, "Operation cancelled, y doesn't match Local")
+
+	common.ConfirmInput = strings.NewReader("Local\n")
 	err = app.Run([]string{app.Name, "req", "delete", "Local"})
 	assert.NilError(t, err)
 }
@@ -62,6 +85,7 @@ func TestApplyRequisition(t *testing.T) {
 	var err error
 	app := test.CreateCli(RequisitionsCliCommand)
 	server := createTestServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "req", "apply"})
@@ -86,3 +110,343 @@ func TestApplyRequisition(t *testing.T) {
 	err = app.Run([]string{app.Name, "req", "apply", string(reqYaml)})
 	assert.NilError(t, err)
 }
+
+func TestApplyRequisitionChunked(t *testing.T) {
+	var err error
+	var mutex sync.Mutex
+	var imported bool
+	received := make(map[string]bool)
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Large"}})
+		case "/rest/requisitions/Large":
+			assert.Equal(t, http.MethodGet, req.Method)
+			sendData(res, model.Requisition{Name: "Large"})
+		case "/rest/requisitions":
+			assert.Equal(t, http.MethodPost, req.Method)
+		case "/rest/requisitions/Large/nodes":
+			assert.Equal(t, http.MethodPost, req.Method)
+			node := model.RequisitionNode{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			json.Unmarshal(bytes, &node)
+			if node.ForeignID == "n3" {
+				res.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			received[node.ForeignID] = true
+		case "/rest/requisitions/Large/import":
+			assert.Equal(t, http.MethodPut, req.Method)
+			assert.Equal(t, "true", req.URL.Query().Get("rescanExisting"))
+			imported = true
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	test.Client(app).URL = server.URL
+
+	testReq := model.Requisition{Name: "Large"}
+	for i := 1; i <= 10; i++ {
+		id := fmt.Sprintf("n%d", i)
+		testReq.Nodes = append(testReq.Nodes, model.RequisitionNode{
+			ForeignID:  id,
+			Interfaces: []model.RequisitionInterface{{IPAddress: fmt.Sprintf("10.0.0.%d", i)}},
+		})
+	}
+	reqYaml, _ := yaml.Marshal(testReq)
+
+	err = app.Run([]string{app.Name, "req", "apply", "--chunked", "-n", "3", "--import", string(reqYaml)})
+	assert.Error(t, err, "Failed to send 1 of 10 nodes to requisition Large:\n  n3: Invalid Response: 500 Internal Server Error")
+	assert.Equal(t, 9, len(received))
+	assert.Assert(t, !imported)
+
+	testReq.Nodes = append(testReq.Nodes[:2], testReq.Nodes[3:]...)
+	reqYaml, _ = yaml.Marshal(testReq)
+	err = app.Run([]string{app.Name, "req", "apply", "--chunked", "--import", string(reqYaml)})
+	assert.NilError(t, err)
+	assert.Assert(t, imported)
+}
+
+func TestDiffRequisition(t *testing.T) {
+	var err error
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "req", "diff"})
+	assert.Error(t, err, "Requisition name required")
+
+	err = app.Run([]string{app.Name, "req", "diff", "Test"})
+	assert.Error(t, err, "Content cannot be empty")
+
+	local := model.Requisition{
+		Name:  "Test",
+		Nodes: []model.RequisitionNode{testNode},
+	}
+	reqYaml, _ := yaml.Marshal(local)
+	err = app.Run([]string{app.Name, "req", "diff", "Test", string(reqYaml)})
+	assert.NilError(t, err)
+
+	changed := testNode
+	changed.Assets = []model.RequisitionAsset{{Name: "city", Value: "Apex"}}
+	local.Nodes = []model.RequisitionNode{changed, {ForeignID: "n2", NodeLabel: "n2"}}
+	reqYaml, _ = yaml.Marshal(local)
+	err = app.Run([]string{app.Name, "req", "diff", "Test", string(reqYaml)})
+	assert.Equal(t, 1, err.(common.ExitError).ExitStatus())
+}
+
+// The commands that only work with local files must not depend on the server configuration
+func TestLocalCommandsOffline(t *testing.T) {
+	var err error
+	app := test.CreateCli(RequisitionsCliCommand, rest.WithSetupError(fmt.Errorf("Profile prod doesn't exist")))
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	local := model.Requisition{Name: "Test", Nodes: []model.RequisitionNode{testNode}}
+	localFile := filepath.Join(dir, "Test.yaml")
+	assert.NilError(t, writeRequisitionFile(localFile, "yaml", local))
+	changed := testNode
+	changed.NodeLabel = "changed"
+	baseFile := filepath.Join(dir, "Base.json")
+	assert.NilError(t, writeRequisitionFile(baseFile, "json", model.Requisition{Name: "Test", Nodes: []model.RequisitionNode{changed}}))
+
+	err = app.Run([]string{app.Name, "req", "validate", "-x", "yaml", "-f", localFile})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "req", "lint", "-x", "yaml", "-f", localFile})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "req", "diff", "-f", localFile, "--base", localFile})
+	assert.NilError(t, err)
+	err = app.Run([]string{app.Name, "req", "diff", "-f", localFile, "--base", baseFile})
+	assert.Equal(t, 1, err.(common.ExitError).ExitStatus())
+
+	err = app.Run([]string{app.Name, "req", "export", "-f", baseFile, "--dir", dir, "--split-nodes"})
+	assert.NilError(t, err)
+	exported, err := readRequisitionDir(filepath.Join(dir, "Test"))
+	assert.NilError(t, err)
+	assert.Equal(t, "changed", exported.Nodes[0].NodeLabel)
+
+	// The commands that need the server report the deferred error
+	err = app.Run([]string{app.Name, "req", "list"})
+	assert.ErrorContains(t, err, "Profile prod doesn't exist")
+}
+
+func TestImportRequisitionWait(t *testing.T) {
+	var err error
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "req", "import", "--wait", "--poll-interval", "2ms", "Test"})
+	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "req", "import", "--wait", "--timeout", "10ms", "--poll-interval", "2ms", "Local"})
+	assert.Error(t, err, "Timed out after 10ms waiting for requisition Local to be imported")
+}
+
+func TestImportRequisitionRescan(t *testing.T) {
+	var err error
+	app := test.CreateCli(RequisitionsCliCommand)
+	server := createTestServer(t)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	err = app.Run([]string{app.Name, "req", "import", "Test"})
+	assert.NilError(t, err)
+	assert.Equal(t, "true", lastTestImport)
+
+	err = app.Run([]string{app.Name, "req", "import", "--rescan", "dbonly", "Test"})
+	assert.NilError(t, err)
+	assert.Equal(t, "dbonly", lastTestImport)
+
+	err = app.Run([]string{app.Name, "req", "import", "-r", "false", "Test"})
+	assert.NilError(t, err)
+	assert.Equal(t, "false", lastTestImport)
+
+	err = app.Run([]string{app.Name, "req", "import", "--rescan", "always", "Test"})
+	assert.ErrorContains(t, err, "valid options: true, false, dbonly")
+}
+
+func TestApplyRequisitionStream(t *testing.T) {
+	stream := "# Generated by CI\n---\nname: SiteA\nnodes:\n- foreignID: n1\n  interfaces:\n  - ipAddress: 10.0.0.1\n---\nname: SiteB\n...\n---\n"
+	posted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/reqs/sites.yaml":
+			_, _, ok := req.BasicAuth()
+			assert.Assert(t, !ok)
+			res.Write([]byte(stream))
+		case req.URL.Path == "/rest/requisitions" && req.Method == http.MethodPost:
+			r := model.Requisition{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, &r))
+			posted = append(posted, r.Name)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	app := test.CreateCli(RequisitionsCliCommand, rest.WithURL(server.URL))
+
+	sum := sha256.Sum256([]byte(stream))
+	checksum := hex.EncodeToString(sum[:])
+	_, err := test.RunWithOutput(app, "table", "req", "apply", "-f", server.URL+"/reqs/sites.yaml", "--sha256", strings.Repeat("0", 64))
+	assert.ErrorContains(t, err, "Checksum mismatch: expected 000")
+	assert.Equal(t, 0, len(posted))
+
+	output, err := test.RunWithOutput(app, "table", "req", "apply", "-f", server.URL+"/reqs/sites.yaml", "--sha256", checksum)
+	assert.NilError(t, err)
+	assert.Equal(t, "+ node n1\nRequisition SiteA (new): 1 created, 0 updated, 0 unchanged\nRequisition SiteB (new): 0 created, 0 updated, 0 unchanged\n", output)
+	assert.DeepEqual(t, []string{"SiteA", "SiteB"}, posted)
+
+	_, err = test.RunWithOutput(app, "table", "req", "apply", "-f", server.URL+"/reqs/unknown.yaml")
+	assert.Error(t, err, "Invalid Response: 404 Not Found")
+
+	// Nothing is sent when any of the documents is invalid
+	posted = []string{}
+	_, err = test.RunWithOutput(app, "table", "req", "apply", "name: SiteA\n---\nname: Site/B\n")
+	assert.ErrorContains(t, err, "Document 2 of 2: Invalid characters on requisition name Site/B")
+	assert.Equal(t, 0, len(posted))
+	_, err = test.RunWithOutput(app, "table", "req", "apply", "# Nothing\n---\n")
+	assert.Error(t, err, "Content cannot be empty")
+}
+
+func TestApplyRequisitionChanges(t *testing.T) {
+	node := func(id string, ip string) model.RequisitionNode {
+		return model.RequisitionNode{ForeignID: id, NodeLabel: id, Interfaces: []model.RequisitionInterface{{IPAddress: ip, SnmpPrimary: "P", Status: 1}}}
+	}
+	current := model.Requisition{Name: "Sites", Nodes: []model.RequisitionNode{node("n1", "10.0.0.1"), node("n2", "10.0.0.2"), node("n9", "10.0.0.9")}}
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Sites"}})
+		case "/rest/requisitions/Sites":
+			sendData(res, current)
+		case "/rest/requisitions":
+			assert.Equal(t, http.MethodPost, req.Method)
+			bytes, _ := ioutil.ReadAll(req.Body)
+			current = model.Requisition{}
+			assert.NilError(t, json.Unmarshal(bytes, &current))
+			posts++
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	app := test.CreateCli(RequisitionsCliCommand, rest.WithURL(server.URL))
+	file := model.Requisition{Name: "Sites", Nodes: []model.RequisitionNode{node("n1", "10.0.0.1"), node("n2", "10.0.0.22"), node("n3", "10.0.0.3")}}
+	data, _ := yaml.Marshal(file)
+
+	output, err := test.RunWithOutput(app, "table", "req", "apply", "--detect-drift", string(data))
+	assert.DeepEqual(t, common.ExitError{Message: "1 of 1 requisitions differ from the server", Code: exitDrift}, err)
+	assert.Equal(t, "+ node n3\n~ node n2\nRequisition Sites: 1 created, 1 updated, 1 unchanged, 1 kept (only on the server, use --prune to remove them); changes required, nothing was sent\n", output)
+	assert.Equal(t, 0, posts)
+
+	// The nodes that are only on the server are kept
+	output, err = test.RunWithOutput(app, "table", "req", "apply", string(data))
+	assert.NilError(t, err)
+	assert.Equal(t, "+ node n3\n~ node n2\nRequisition Sites: 1 created, 1 updated, 1 unchanged, 1 kept (only on the server, use --prune to remove them)\n", output)
+	assert.Equal(t, 1, posts)
+	assert.Equal(t, 4, len(current.Nodes))
+	assert.Equal(t, "10.0.0.22", current.GetNode("n2").Interfaces[0].IPAddress)
+
+	output, err = test.RunWithOutput(app, "table", "req", "apply", string(data))
+	assert.NilError(t, err)
+	assert.Equal(t, "Requisition Sites: 0 created, 0 updated, 3 unchanged, 1 kept (only on the server, use --prune to remove them); nothing was sent\n", output)
+	assert.Equal(t, 1, posts)
+
+	_, err = test.RunWithOutput(app, "table", "req", "apply", "--force", string(data))
+	assert.NilError(t, err)
+	assert.Equal(t, 2, posts)
+
+	output, err = test.RunWithOutput(app, "table", "req", "apply", "--prune", string(data))
+	assert.NilError(t, err)
+	assert.Equal(t, "- node n9\nRequisition Sites: 0 created, 0 updated, 3 unchanged, 1 removed\n", output)
+	assert.Equal(t, 3, posts)
+	assert.Equal(t, 3, len(current.Nodes))
+
+	output, err = test.RunWithOutput(app, "table", "req", "apply", "--detect-drift", "--prune", string(data))
+	assert.NilError(t, err)
+	assert.Equal(t, "Requisition Sites: 0 created, 0 updated, 3 unchanged; no changes required\n", output)
+	assert.Equal(t, 3, posts)
+}
+
+func TestApplyRequisitionDefaults(t *testing.T) {
+	var posted *model.Requisition
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/rest/requisitions" && req.Method == http.MethodPost:
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.Assert(t, !strings.Contains(string(bytes), "defaults"))
+			posted = &model.Requisition{}
+			assert.NilError(t, json.Unmarshal(bytes, posted))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	app := test.CreateCli(RequisitionsCliCommand, rest.WithURL(server.URL))
+
+	embedded := `name: Sites
+defaults:
+  location: Apex
+  categories:
+  - name: Production
+  metaData:
+  - key: owner
+    value: netops
+nodes:
+- foreignID: n1
+- foreignID: n2
+  location: Durham
+  metaData:
+  - key: owner
+    value: dba
+`
+	_, err := test.RunWithOutput(app, "table", "req", "apply", embedded)
+	assert.NilError(t, err)
+	assert.Assert(t, posted != nil)
+	assert.Equal(t, "Apex", posted.GetNode("n1").Location)
+	assert.Equal(t, "Durham", posted.GetNode("n2").Location)
+	assert.Equal(t, "Production", posted.GetNode("n2").Categories[0].Name)
+	assert.Equal(t, "netops", posted.GetNode("n1").MetaData[0].Value)
+	assert.Equal(t, "dba", posted.GetNode("n2").MetaData[0].Value)
+
+	// The embedded defaults win over the ones from the file
+	dir, err := ioutil.TempDir("", "defaults")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "defaults.yaml")
+	valid := []byte("location: RTP\nassets:\n- name: building\n  value: HQ\nmetaData:\n- key: owner\n  value: noc\n")
+	assert.NilError(t, ioutil.WriteFile(file, valid, 0644))
+	posted = nil
+	_, err = test.RunWithOutput(app, "table", "req", "apply", "--defaults-file", file, embedded)
+	assert.NilError(t, err)
+	assert.Equal(t, "Apex", posted.GetNode("n1").Location)
+	assert.Equal(t, "netops", posted.GetNode("n1").MetaData[0].Value)
+	assert.Equal(t, "HQ", posted.GetNode("n2").Assets[0].Value)
+
+	posted = nil
+	assert.NilError(t, ioutil.WriteFile(file, []byte("assets:\n- name: building\n"), 0644))
+	_, err = test.RunWithOutput(app, "table", "req", "apply", "--defaults-file", file, embedded)
+	assert.Error(t, err, file+": Invalid default asset: Asset value for building cannot be empty")
+	_, err = test.RunWithOutput(app, "table", "req", "apply", "name: Sites\ndefaults:\n  categories:\n  - name: A/B\n")
+	assert.ErrorContains(t, err, "Invalid default category")
+	assert.Assert(t, posted == nil)
+
+	// Formats that cannot embed the section (the last run, as the format flag keeps its value on the next ones)
+	assert.NilError(t, ioutil.WriteFile(file, valid, 0644))
+	_, err = test.RunWithOutput(app, "table", "req", "apply", "--format", "json", "--defaults-file", file, `{"foreign-source":"Sites","node":[{"foreign-id":"n1","node-label":"n1"}]}`)
+	assert.NilError(t, err)
+	assert.Equal(t, "RTP", posted.GetNode("n1").Location)
+	assert.Equal(t, "noc", posted.GetNode("n1").MetaData[0].Value)
+}
@@ -0,0 +1,100 @@
+package provisioning
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/urfave/cli"
+)
+
+// watchCommand the CLI command to follow the changes of a requisition
+var watchCommand = cli.Command{
+	Name:         "watch",
+	Usage:        "Polls a requisition and prints its changes, one per line prefixed with the time they were detected, until Ctrl-C is pressed",
+	Action:       watchRequisition,
+	BashComplete: requisitionNameBashComplete,
+	ArgsUsage:    "<name>",
+	Flags: []cli.Flag{
+		cli.DurationFlag{
+			Name:  "interval, i",
+			Value: 10 * time.Second,
+			Usage: "Time between polls",
+		},
+		cli.BoolFlag{
+			Name:  "until-imported",
+			Usage: "Exit after the requisition is imported (when its last import is newer than the one seen when the watch started)",
+		},
+	},
+}
+
+func watchRequisition(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("Interval must be greater than zero")
+	}
+	common.GetClient(c).CacheTTL = -1 // Every poll must reach the server
+	var previous *model.Requisition
+	for {
+		current, err := getReqAPI(c).GetRequisition(name)
+		if err == rest.ErrCancelled {
+			return nil
+		}
+		now := time.Now()
+		if err != nil {
+			common.Log.Warnf("%s cannot poll requisition %s: %s", formatWatchTime(now), name, err) // On stderr, so it doesn't mix with the changes
+		} else if previous == nil {
+			fmt.Fprintf(common.Output, "%s watching requisition %s with %d nodes, last imported %s\n", formatWatchTime(now), name, len(current.Nodes), common.DisplayTime(current.LastImport))
+			previous = current
+		} else {
+			for _, line := range describeRequisitionChanges(*previous, *current) {
+				fmt.Fprintf(common.Output, "%s %s\n", formatWatchTime(now), line)
+			}
+			if c.Bool("until-imported") && isNewerImport(previous.LastImport, current.LastImport) {
+				return nil
+			}
+			previous = current
+		}
+		if err := common.GetClient(c).Sleep(interval); err != nil {
+			return nil
+		}
+	}
+}
+
+// Returns a line per difference between two versions of a requisition
+func describeRequisitionChanges(previous model.Requisition, current model.Requisition) []string {
+	lines := make([]string, 0)
+	if isNewerImport(previous.LastImport, current.LastImport) {
+		lines = append(lines, "requisition imported at "+common.DisplayTime(current.LastImport))
+	}
+	diff := previous.Diff(current)
+	for _, id := range diff.AddedNodes {
+		lines = append(lines, "+ node "+id)
+	}
+	for _, id := range diff.RemovedNodes {
+		lines = append(lines, "- node "+id)
+	}
+	for _, node := range diff.ChangedNodes {
+		for _, change := range node.Changes {
+			lines = append(lines, fmt.Sprintf("node %s %s", node.ForeignID, change))
+		}
+	}
+	return lines
+}
+
+func isNewerImport(previous *model.Time, current *model.Time) bool {
+	if current == nil || current.IsZero() {
+		return false
+	}
+	return previous == nil || current.After(previous.Time)
+}
+
+func formatWatchTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
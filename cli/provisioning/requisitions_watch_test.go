@@ -0,0 +1,76 @@
+package provisioning
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestWatchRequisition(t *testing.T) {
+	imported := &model.Time{Time: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)}
+	reimported := &model.Time{Time: time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC)}
+	changed := testNode
+	changed.Interfaces = []model.RequisitionInterface{{IPAddress: "10.0.0.1", SnmpPrimary: "S"}}
+	// The versions returned by each poll; nil means a failure
+	versions := []*model.Requisition{
+		{Name: "Test", LastImport: imported, Nodes: []model.RequisitionNode{testNode}},
+		{Name: "Test", LastImport: imported, Nodes: []model.RequisitionNode{testNode}},
+		{Name: "Test", LastImport: imported, Nodes: []model.RequisitionNode{changed, {ForeignID: "n2", NodeLabel: "n2"}}},
+		nil,
+		{Name: "Test", LastImport: reimported, Nodes: []model.RequisitionNode{{ForeignID: "n2", NodeLabel: "n2"}}},
+		{Name: "Test", LastImport: reimported},
+	}
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/rest/requisitionNames" {
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Test"}})
+			return
+		}
+		assert.Equal(t, "/rest/requisitions/Test", req.URL.Path)
+		version := versions[polls]
+		polls++
+		if version == nil {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		sendData(res, version)
+	}))
+	defer server.Close()
+
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	app := test.CreateCli(RequisitionsCliCommand, rest.WithURL(server.URL))
+	output, err := test.RunWithOutput(app, "table", "req", "watch", "Test", "-i", "1ms", "--until-imported")
+	assert.NilError(t, err)
+	assert.Equal(t, 5, polls)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i, line := range lines {
+		// Remove the time stamps
+		parts := strings.SplitN(line, " ", 2)
+		_, err := time.Parse(time.RFC3339, parts[0])
+		assert.NilError(t, err)
+		lines[i] = parts[1]
+	}
+	assert.DeepEqual(t, []string{
+		"watching requisition Test with 1 nodes, last imported " + common.DisplayTime(imported),
+		"+ node n2",
+		"node n1 ~ interface 10.0.0.1 snmp-primary: P -> S",
+		"node n1 - service HTTP on 10.0.0.1",
+		"node n1 - interface 10.0.0.1 meta-data requisition:mpls",
+		"requisition imported at " + common.DisplayTime(reimported),
+		"- node n1",
+	}, lines)
+	assert.Assert(t, strings.Contains(logs.String(), " cannot poll requisition Test: "))
+
+	_, err = test.RunWithOutput(app, "table", "req", "watch")
+	assert.Error(t, err, "Requisition name required")
+}
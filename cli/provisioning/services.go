@@ -45,6 +45,34 @@ var ServicesCliCommand = cli.Command{
 			Action:       deleteService,
 			BashComplete: servicesBashComplete,
 		},
+		{
+			Name:         "ensure",
+			Usage:        "Adds the given services to every IP interface of a requisition that doesn't have them",
+			ArgsUsage:    "<foreignSource>",
+			Action:       ensureServices,
+			BashComplete: requisitionNameBashComplete,
+			Flags: []cli.Flag{
+				bulkServicesFlag,
+				cli.BoolFlag{
+					Name:  "primary-only",
+					Usage: "Only add the services to the primary SNMP interfaces",
+				},
+			},
+		},
+		{
+			Name:         "purge",
+			Usage:        "Removes the given services from every IP interface of a requisition",
+			ArgsUsage:    "<foreignSource>",
+			Action:       purgeServices,
+			BashComplete: requisitionNameBashComplete,
+			Flags: []cli.Flag{
+				bulkServicesFlag,
+				cli.StringFlag{
+					Name:  "where-ip",
+					Usage: "Only the IP interfaces matching the condition: 'primary', an IP address or a CIDR block, optionally preceded by == or != (e.x. '!= primary')",
+				},
+			},
+		},
 		{
 			Name:      "meta",
 			ShortName: "m",
@@ -77,21 +105,15 @@ var ServicesCliCommand = cli.Command{
 }
 
 func listServices(c *cli.Context) error {
-	intf, err := getReqAPI().GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	intf, err := getReqAPI(c).GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
 	if err != nil {
 		return err
 	}
-	if len(intf.Services) == 0 {
-		fmt.Println("There are no monitored services on the chosen IP interface")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Service Name")
+	table := common.NewTable("There are no monitored services on the chosen IP interface", "Service Name")
 	for _, svc := range intf.Services {
-		fmt.Fprintf(writer, "%s\n", svc.Name)
+		table.AddRow(svc.Name)
 	}
-	writer.Flush()
-	return nil
+	return common.Print(intf.Services, table)
 }
 
 func setService(c *cli.Context) error {
@@ -101,57 +123,47 @@ func setService(c *cli.Context) error {
 		data := strings.Split(p, "=")
 		svc.AddMetaData(data[0], data[1])
 	}
-	return getReqAPI().SetService(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), svc)
+	return getReqAPI(c).SetService(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), svc)
 }
 
 func deleteService(c *cli.Context) error {
-	return getReqAPI().DeleteService(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), c.Args().Get(3))
+	return getReqAPI(c).DeleteService(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), c.Args().Get(3))
 }
 
 func svcListMetaData(c *cli.Context) error {
 	service, err := getMonitoredService(c)
-	if err == nil {
+	if err != nil {
 		return err
 	}
-	if len(service.MetaData) == 0 {
-		fmt.Println("There is no meta-data for the chosen service")
-		return nil
-	}
-	writer := common.NewTableWriter()
-	fmt.Fprintln(writer, "Context\tKey\tValue")
-	for _, m := range service.MetaData {
-		fmt.Fprintf(writer, "%s\t%s\t%s\n", m.Context, m.Key, m.Value)
-	}
-	writer.Flush()
-	return nil
+	return printMetaData(service.MetaData, "There is no meta-data for the chosen service")
 }
 
 func svcSetMetaData(c *cli.Context) error {
 	service, err := getMonitoredService(c)
-	if err == nil {
+	if err != nil {
 		return err
 	}
 	service.SetMetaData(c.Args().Get(4), c.Args().Get(5))
 	if err := service.Validate(); err != nil {
 		return err
 	}
-	return getReqAPI().SetService(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), *service)
+	return getReqAPI(c).SetService(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), *service)
 }
 
 func svcDeleteMetaData(c *cli.Context) error {
 	service, err := getMonitoredService(c)
-	if err == nil {
+	if err != nil {
 		return err
 	}
 	service.DeleteMetaData(c.Args().Get(4))
 	if err := service.Validate(); err != nil {
 		return err
 	}
-	return getReqAPI().SetService(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), *service)
+	return getReqAPI(c).SetService(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), *service)
 }
 
 func getMonitoredService(c *cli.Context) (*model.RequisitionMonitoredService, error) {
-	intf, err := getReqAPI().GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
+	intf, err := getReqAPI(c).GetInterface(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2))
 	if err != nil {
 		return nil, err
 	}
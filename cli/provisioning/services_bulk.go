@@ -0,0 +1,142 @@
+package provisioning
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// The flag with the services of the ensure and purge commands
+var bulkServicesFlag = cli.StringFlag{
+	Name:  "services, s",
+	Usage: "Comma separated list of service names (e.x. ICMP,SNMP)",
+}
+
+// Adds the missing services to the interfaces of every node of a requisition
+func ensureServices(c *cli.Context) error {
+	primaryOnly := c.Bool("primary-only")
+	return updateServices(c, func(intf *model.RequisitionInterface, services []string) []string {
+		changes := make([]string, 0)
+		if primaryOnly && intf.SnmpPrimary != "P" {
+			return changes
+		}
+		for _, name := range services {
+			if intf.GetService(name) == nil {
+				intf.AddService(&model.RequisitionMonitoredService{Name: name})
+				changes = append(changes, "+"+name)
+			}
+		}
+		return changes
+	})
+}
+
+// Removes the services from the interfaces of every node of a requisition
+func purgeServices(c *cli.Context) error {
+	matches, err := parseIPCondition(c.String("where-ip"))
+	if err != nil {
+		return err
+	}
+	return updateServices(c, func(intf *model.RequisitionInterface, services []string) []string {
+		changes := make([]string, 0)
+		if !matches(intf) {
+			return changes
+		}
+		for _, name := range services {
+			if intf.DeleteService(name) {
+				changes = append(changes, "-"+name)
+			}
+		}
+		return changes
+	})
+}
+
+// Applies the update to every interface of the requisition, and sends only the nodes that changed
+func updateServices(c *cli.Context, update func(intf *model.RequisitionInterface, services []string) []string) error {
+	foreignSource := c.Args().First()
+	if foreignSource == "" {
+		return fmt.Errorf("Requisition name required")
+	}
+	services := make([]string, 0)
+	for _, name := range strings.Split(c.String("services"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			services = append(services, name)
+		}
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("At least one service is required")
+	}
+	for _, name := range services {
+		if err := (model.RequisitionMonitoredService{Name: name}).Validate(); err != nil {
+			return err
+		}
+	}
+	requisition, err := getReqAPI(c).GetRequisition(foreignSource)
+	if err != nil {
+		return err
+	}
+	changed := make([]model.RequisitionNode, 0)
+	for i := range requisition.Nodes {
+		node := &requisition.Nodes[i]
+		modified := false
+		for j := range node.Interfaces {
+			intf := &node.Interfaces[j]
+			if changes := update(intf, services); len(changes) > 0 {
+				fmt.Fprintf(common.Output, "~ node %s (%s) interface %s: %s\n", node.ForeignID, node.NodeLabel, intf.IPAddress, strings.Join(changes, ","))
+				modified = true
+			}
+		}
+		if modified {
+			if err := node.Validate(); err != nil {
+				return common.ValidationError(fmt.Errorf("Node %s: %w", node.ForeignID, err))
+			}
+			changed = append(changed, *node)
+		}
+	}
+	if len(changed) == 0 {
+		common.Log.Infof("Requisition %s has no nodes to update", foreignSource)
+		return nil
+	}
+	for _, node := range changed {
+		if err := getReqAPI(c).SetNode(foreignSource, node); err != nil {
+			return fmt.Errorf("Cannot update node %s: %w", node.ForeignID, err)
+		}
+	}
+	common.Log.Infof("%d of %d nodes updated on requisition %s", len(changed), len(requisition.Nodes), foreignSource)
+	return nil
+}
+
+// Parses the condition for the IP interfaces, an optional operator (== or !=) followed by 'primary', an IP address or a CIDR block
+func parseIPCondition(condition string) (func(intf *model.RequisitionInterface) bool, error) {
+	condition = strings.TrimSpace(condition)
+	if condition == "" {
+		return func(intf *model.RequisitionInterface) bool { return true }, nil
+	}
+	negate := false
+	if strings.HasPrefix(condition, "!=") {
+		negate = true
+		condition = strings.TrimSpace(condition[2:])
+	} else if strings.HasPrefix(condition, "==") {
+		condition = strings.TrimSpace(condition[2:])
+	}
+	var matches func(intf *model.RequisitionInterface) bool
+	if condition == "primary" {
+		matches = func(intf *model.RequisitionInterface) bool { return intf.SnmpPrimary == "P" }
+	} else if _, network, err := net.ParseCIDR(condition); err == nil {
+		matches = func(intf *model.RequisitionInterface) bool {
+			ip := net.ParseIP(intf.IPAddress)
+			return ip != nil && network.Contains(ip)
+		}
+	} else if ip := net.ParseIP(condition); ip != nil {
+		matches = func(intf *model.RequisitionInterface) bool { return ip.Equal(net.ParseIP(intf.IPAddress)) }
+	} else {
+		return nil, fmt.Errorf("Invalid IP condition %s; expected an optional == or != followed by primary, an IP address or a CIDR block", condition)
+	}
+	if negate {
+		return func(intf *model.RequisitionInterface) bool { return !matches(intf) }, nil
+	}
+	return matches, nil
+}
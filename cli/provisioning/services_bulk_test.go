@@ -0,0 +1,102 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func createServicesTestServer(t *testing.T, posted *[]model.RequisitionNode) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/rest/requisitionNames":
+			sendData(res, model.RequisitionsList{Count: 1, ForeignSources: []string{"Sites"}})
+		case req.URL.Path == "/rest/requisitions/Sites" && req.Method == http.MethodGet:
+			sendData(res, model.Requisition{
+				Name: "Sites",
+				Nodes: []model.RequisitionNode{
+					{ForeignID: "n1", NodeLabel: "srv01", Interfaces: []model.RequisitionInterface{
+						{IPAddress: "10.0.0.1", SnmpPrimary: "P", Services: []model.RequisitionMonitoredService{{Name: "ICMP"}, {Name: "SNMP"}}},
+					}},
+					{ForeignID: "n2", NodeLabel: "srv02", Interfaces: []model.RequisitionInterface{
+						{IPAddress: "10.0.0.2", SnmpPrimary: "P", Services: []model.RequisitionMonitoredService{{Name: "ICMP"}, {Name: "HTTP"}}},
+						{IPAddress: "192.168.0.2", SnmpPrimary: "N", Services: []model.RequisitionMonitoredService{{Name: "HTTP"}}},
+					}},
+				},
+			})
+		case req.URL.Path == "/rest/requisitions/Sites/nodes" && req.Method == http.MethodPost:
+			node := model.RequisitionNode{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, &node))
+			*posted = append(*posted, node)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestEnsureServices(t *testing.T) {
+	posted := []model.RequisitionNode{}
+	server := createServicesTestServer(t, &posted)
+	defer server.Close()
+	app := test.CreateCli(ServicesCliCommand, rest.WithURL(server.URL))
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "svc", "ensure", "Sites")
+	assert.Error(t, err, "At least one service is required")
+
+	output, err := test.RunWithOutput(app, "table", "svc", "ensure", "--services", "ICMP,SNMP", "--primary-only", "Sites")
+	assert.NilError(t, err)
+	assert.Equal(t, "~ node n2 (srv02) interface 10.0.0.2: +SNMP\n", output)
+	assert.Equal(t, "1 of 2 nodes updated on requisition Sites\n", logs.String())
+	assert.Equal(t, 1, len(posted))
+	assert.Equal(t, 3, len(posted[0].Interfaces[0].Services))
+
+	posted = posted[:0]
+	logs.Reset()
+	output, err = test.RunWithOutput(app, "table", "svc", "ensure", "-s", "ICMP", "Sites")
+	assert.NilError(t, err)
+	assert.Equal(t, "~ node n2 (srv02) interface 192.168.0.2: +ICMP\n", output)
+	assert.Equal(t, "1 of 2 nodes updated on requisition Sites\n", logs.String())
+	assert.Equal(t, 1, len(posted))
+}
+
+func TestPurgeServices(t *testing.T) {
+	posted := []model.RequisitionNode{}
+	server := createServicesTestServer(t, &posted)
+	defer server.Close()
+	app := test.CreateCli(ServicesCliCommand, rest.WithURL(server.URL))
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	_, err := test.RunWithOutput(app, "table", "svc", "purge", "-s", "HTTP", "--where-ip", "secondary", "Sites")
+	assert.ErrorContains(t, err, "Invalid IP condition secondary")
+
+	output, err := test.RunWithOutput(app, "table", "svc", "purge", "-s", "HTTP", "--where-ip", "!= primary", "Sites")
+	assert.NilError(t, err)
+	assert.Equal(t, "~ node n2 (srv02) interface 192.168.0.2: -HTTP\n", output)
+	assert.Equal(t, "1 of 2 nodes updated on requisition Sites\n", logs.String())
+	assert.Equal(t, 1, len(posted))
+	assert.Equal(t, 0, len(posted[0].Interfaces[1].Services))
+	assert.Equal(t, 2, len(posted[0].Interfaces[0].Services))
+
+	logs.Reset()
+	output, err = test.RunWithOutput(app, "table", "svc", "purge", "-s", "SNMP", "--where-ip", "10.0.0.0/24", "Sites")
+	assert.NilError(t, err)
+	assert.Equal(t, "~ node n1 (srv01) interface 10.0.0.1: -SNMP\n", output)
+	assert.Equal(t, "1 of 2 nodes updated on requisition Sites\n", logs.String())
+
+	logs.Reset()
+	output, err = test.RunWithOutput(app, "table", "svc", "purge", "-s", "DNS", "Sites")
+	assert.NilError(t, err)
+	assert.Equal(t, "Requisition Sites has no nodes to update\n", logs.String())
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/OpenNMS/onmsctl/model"
-	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/OpenNMS/onmsctl/test"
 	"gotest.tools/assert"
 )
@@ -75,6 +74,9 @@ var testForeignSource = model.ForeignSourceDef{
 	},
 }
 
+// The rescanExisting parameter of the last import of requisition Test received by the test server
+var lastTestImport string
+
 func createTestServer(t *testing.T) *httptest.Server {
 	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		fmt.Printf("Received %s request from %s\n", req.Method, req.URL.Path)
@@ -89,6 +91,13 @@ func createTestServer(t *testing.T) *httptest.Server {
 			res.WriteHeader(http.StatusOK)
 			res.Write([]byte(test.DetectorsJSON))
 
+		case "/rest/foreignSourcesConfig/services/Test":
+			assert.Equal(t, http.MethodGet, req.Method)
+			sendData(res, model.ElementList{
+				Count:   3,
+				Element: []string{"ICMP", "SNMP", "HTTP"},
+			})
+
 		case "/rest/foreignSourcesConfig/assets":
 			assert.Equal(t, http.MethodGet, req.Method)
 			sendData(res, model.ElementList{
@@ -96,6 +105,13 @@ func createTestServer(t *testing.T) *httptest.Server {
 				Element: []string{"address1", "city", "state", "zip"},
 			})
 
+		case "/api/v2/monitoringLocations":
+			assert.Equal(t, http.MethodGet, req.Method)
+			sendData(res, model.MonitoringLocationList{
+				Count:     1,
+				Locations: []model.MonitoringLocation{{LocationName: "Durham"}},
+			})
+
 		case "/rest/requisitionNames":
 			assert.Equal(t, http.MethodGet, req.Method)
 			sendData(res, model.RequisitionsList{
@@ -133,17 +149,51 @@ func createTestServer(t *testing.T) *httptest.Server {
 				assert.Equal(t, "opennms.com", node.NodeLabel)
 				assert.Equal(t, publicIP, node.Interfaces[0].IPAddress)
 			}
+			if r.Name == "Durham" { // From a rendered template
+				assert.Equal(t, 3, len(r.Nodes))
+				assert.Equal(t, "durham-3", r.Nodes[2].ForeignID)
+				assert.Equal(t, "10.0.0.3", r.Nodes[2].Interfaces[0].IPAddress)
+			}
+			if r.Name == "example.com" { // From DNS records
+				assert.Equal(t, 2, len(r.Nodes))
+				assert.Equal(t, "sw-01", r.Nodes[0].ForeignID)
+				assert.Equal(t, "sw-01.example.com", r.Nodes[0].NodeLabel)
+			}
 
 		case "/rest/requisitions/Local/import":
 			assert.Equal(t, http.MethodPut, req.Method)
 
+		case "/rest/requisitions/Test/import":
+			assert.Equal(t, http.MethodPut, req.Method)
+			lastTestImport = req.URL.Query().Get("rescanExisting")
+
 		case "/rest/requisitions/Local":
+			if req.Method == http.MethodGet {
+				sendData(res, model.Requisition{
+					Name:  "Local",
+					Nodes: []model.RequisitionNode{{ForeignID: "n3", NodeLabel: "n3"}},
+				})
+				return
+			}
 			assert.Equal(t, http.MethodDelete, req.Method)
 
+		case "/rest/requisitions/Local/nodes":
+			assert.Equal(t, http.MethodPost, req.Method)
+			var node model.RequisitionNode
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			json.Unmarshal(bytes, &node)
+			assert.Equal(t, "n1", node.NodeLabel)
+			assert.Equal(t, 1, len(node.Interfaces))
+
 		case "/rest/requisitions/deployed/Local":
 			assert.Equal(t, http.MethodDelete, req.Method)
 
 		case "/rest/foreignSources/Local":
+			if req.Method == http.MethodGet {
+				sendData(res, model.ForeignSourceDef{Name: "Local"})
+				return
+			}
 			assert.Equal(t, http.MethodDelete, req.Method)
 
 		case "/rest/foreignSources/deployed/Local":
@@ -153,6 +203,9 @@ func createTestServer(t *testing.T) *httptest.Server {
 			assert.Equal(t, http.MethodDelete, req.Method)
 
 		case "/rest/requisitions/Test/nodes/n1":
+			if req.Method == http.MethodDelete {
+				return
+			}
 			assert.Equal(t, http.MethodGet, req.Method)
 			sendData(res, testNode)
 
@@ -169,9 +222,27 @@ func createTestServer(t *testing.T) *httptest.Server {
 				assert.Equal(t, "opennms.com", node.NodeLabel)
 				assert.Equal(t, publicIP, node.Interfaces[0].IPAddress)
 			}
+			if node.ForeignID == "n1" && len(node.Interfaces) > 1 { // From a CIDR block, merged with the existing 10.0.0.1
+				assert.Equal(t, 6, len(node.Interfaces))
+				assert.Equal(t, "10.0.0.6", node.Interfaces[5].IPAddress)
+			}
+			if node.ForeignID == "n1" && len(node.Interfaces) == 1 { // From meta-data set, which must not duplicate entries
+				entries := make(map[string]int)
+				for _, m := range node.MetaData {
+					entries[m.Context+"/"+m.Key]++
+				}
+				for entry, count := range entries {
+					assert.Equal(t, 1, count, entry)
+				}
+				assert.Assert(t, len(node.MetaData) > 1 || node.MetaData[0].Value == "team")
+			}
 
 		case "/rest/requisitions/Test/nodes/n2":
-			assert.Assert(t, http.MethodDelete == req.Method || http.MethodGet == req.Method)
+			if req.Method == http.MethodGet {
+				sendData(res, model.RequisitionNode{ForeignID: "n2", NodeLabel: "n2"})
+				return
+			}
+			assert.Equal(t, http.MethodDelete, req.Method)
 
 		case "/rest/requisitions/Test/nodes/n1/interfaces":
 			assert.Equal(t, http.MethodPost, req.Method)
@@ -190,12 +261,26 @@ func createTestServer(t *testing.T) *httptest.Server {
 				}
 			}
 
+		case "/rest/requisitions/Test/nodes/n1/interfaces/10.0.0.1/services":
+			assert.Equal(t, http.MethodPost, req.Method)
+			var svc model.RequisitionMonitoredService
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			json.Unmarshal(bytes, &svc)
+			assert.Equal(t, "HTTP", svc.Name)
+			assert.Equal(t, 1, len(svc.MetaData))
+			assert.Equal(t, "/status", svc.MetaData[0].Value)
+
 		case "/rest/requisitions/Test/nodes/n1/interfaces/10.0.0.1":
 			assert.Equal(t, http.MethodGet, req.Method)
 			sendData(res, testNode.Interfaces[0])
 
 		case "/rest/requisitions/Test/nodes/n1/interfaces/10.0.0.10":
-			assert.Assert(t, http.MethodDelete == req.Method || http.MethodGet == req.Method)
+			if req.Method == http.MethodGet {
+				sendData(res, model.RequisitionInterface{IPAddress: "10.0.0.10"})
+				return
+			}
+			assert.Equal(t, http.MethodDelete, req.Method)
 
 		case "/rest/requisitions/Test/nodes/n1/assets":
 			assert.Equal(t, http.MethodPost, req.Method)
@@ -266,8 +351,6 @@ func createTestServer(t *testing.T) *httptest.Server {
 			res.WriteHeader(http.StatusForbidden)
 		}
 	}))
-
-	rest.Instance.URL = server.URL
 	return server
 }
 
@@ -0,0 +1,219 @@
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// The oldest version that provides the database reports through the ReST API
+const minReportsVersion = "26.0.0"
+
+// The prefix of the --deliver option to send the report through e-mail
+const emailDelivery = "email:"
+
+// CliCommand the CLI command to manage database reports
+var CliCommand = cli.Command{
+	Name:  "reports",
+	Usage: "List and run database reports",
+	Before: func(c *cli.Context) error {
+		return common.GetClient(c).MinServerVersion(minReportsVersion, "Running database reports through the ReST API")
+	},
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List the database reports",
+			Action: listReports,
+		},
+		{
+			Name:      "show",
+			Usage:     "Shows the parameters and the output formats of a report",
+			ArgsUsage: "<reportId>",
+			Action:    showReport,
+		},
+		{
+			Name:  "run",
+			Usage: "Runs a report; the result is stored on the server, and optionally sent through e-mail",
+			Description: "The parameters not passed through --param keep the default values displayed by 'reports show'.\n" +
+				"   With --wait, the command waits until the server stores the result, and --output-file saves it locally.",
+			ArgsUsage: "<reportId>",
+			Action:    runReport,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "param, p",
+					Usage: "A parameter of the report (e.x. --param 'endDate=2020-01-31'); can be repeated",
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "PDF",
+					Usage: "The output format, one of the formats supported by the report",
+				},
+				cli.StringSliceFlag{
+					Name:  "deliver, d",
+					Usage: "Send the report through e-mail (e.x. --deliver 'email:noc@example.com'); can be repeated",
+				},
+				cli.BoolFlag{
+					Name:  "wait, w",
+					Usage: "Wait until the server stores the result of the report",
+				},
+				cli.StringFlag{
+					Name:  "output-file, O",
+					Usage: "Save the result of the report on the given file; implies --wait",
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Value: 10 * time.Minute,
+					Usage: "Maximum time to wait for the report",
+				},
+				cli.DurationFlag{
+					Name:  "poll-interval",
+					Value: 5 * time.Second,
+					Usage: "Time between checks of the stored reports",
+				},
+			},
+		},
+	},
+}
+
+func listReports(c *cli.Context) error {
+	list, err := getAPI(c).GetReports()
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no reports", "ID", "NAME", "ONLINE", "DESCRIPTION")
+	for _, r := range list.Reports {
+		table.AddRow(r.ID, r.Name, r.Online, common.Truncate(r.Description, 60))
+	}
+	return common.Print(list.Reports, table)
+}
+
+func showReport(c *cli.Context) error {
+	details, err := getAPI(c).GetReport(c.Args().First())
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("The report has no parameters", "PARAMETER", "TYPE", "DEFAULT", "DESCRIPTION")
+	for _, p := range details.Parameters {
+		value := ""
+		if p.Value != nil {
+			value = fmt.Sprint(p.Value)
+		}
+		table.AddRow(p.Name, p.Type, value, p.DisplayName)
+	}
+	if err := common.Print(details, table); err != nil {
+		return err
+	}
+	if common.OutputFormat == common.OutputTable && len(details.Formats) > 0 {
+		fmt.Fprintf(common.Output, "\nFormats: %s\n", strings.Join(details.Formats, ", "))
+	}
+	return nil
+}
+
+func runReport(c *cli.Context) error {
+	api := getAPI(c)
+	details, err := api.GetReport(c.Args().First())
+	if err != nil {
+		return err
+	}
+	request, err := buildReportRequest(c, details)
+	if err != nil {
+		return err
+	}
+	outputFile := c.String("output-file")
+	if !c.Bool("wait") && outputFile == "" {
+		if err := api.RunReport(*request); err != nil {
+			return err
+		}
+		common.Log.Infof("Report %s has been requested", request.ID)
+		return nil
+	}
+	previous, err := api.GetPersistedReports()
+	if err != nil {
+		return err
+	}
+	if err := api.RunReport(*request); err != nil {
+		return err
+	}
+	common.Log.Infof("Report %s has been requested, waiting for the result...", request.ID)
+	result, err := api.WaitForReport(request.ID, *previous, c.Duration("timeout"), c.Duration("poll-interval"))
+	if err != nil {
+		return err
+	}
+	common.Log.Infof("Report %s has been stored with ID %d", request.ID, result.ID)
+	if outputFile == "" {
+		return nil
+	}
+	data, err := api.DownloadReport(result.ID, request.Format)
+	var e *rest.APIError
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("The server doesn't support downloading the stored reports; report %d is available on the web UI", result.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(outputFile, data, 0644); err != nil {
+		return err
+	}
+	common.Log.Infof("Report %s saved on %s", request.ID, outputFile)
+	return nil
+}
+
+// Builds the request from the flags, using the default values of the parameters that were not specified
+func buildReportRequest(c *cli.Context, details *model.ReportDetails) (*model.ReportRequest, error) {
+	request := &model.ReportRequest{
+		ID:         details.ID,
+		Parameters: append([]model.ReportParameter{}, details.Parameters...),
+		Persist:    true,
+	}
+	report := &model.ReportDetails{ID: details.ID, Parameters: request.Parameters}
+	for _, param := range c.StringSlice("param") {
+		parts := strings.SplitN(param, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid parameter %s, expected key=value", param)
+		}
+		p := report.GetParameter(parts[0])
+		if p == nil {
+			return nil, fmt.Errorf("Unknown parameter %s for report %s, valid options: %s", parts[0], details.ID, strings.Join(details.ParameterNames(), ", "))
+		}
+		if err := p.SetValue(parts[1]); err != nil {
+			return nil, err
+		}
+	}
+	request.Format = c.String("format")
+	if len(details.Formats) > 0 {
+		formats := model.NewEnum(details.Formats...)
+		if err := formats.Set(request.Format); err != nil {
+			return nil, fmt.Errorf("Invalid format %s for report %s, valid options: %s", request.Format, details.ID, formats.EnumAsString())
+		}
+		request.Format = formats.String()
+	}
+	addresses := []string{}
+	for _, deliver := range c.StringSlice("deliver") {
+		if !strings.HasPrefix(deliver, emailDelivery) {
+			return nil, fmt.Errorf("Invalid delivery %s, expected %s<address>", deliver, emailDelivery)
+		}
+		addresses = append(addresses, strings.TrimPrefix(deliver, emailDelivery))
+	}
+	if len(addresses) > 0 {
+		request.SendMail = true
+		request.MailTo = strings.Join(addresses, ",")
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func getAPI(c *cli.Context) api.ReportsAPI {
+	return services.GetReportsAPI(common.GetClient(c))
+}
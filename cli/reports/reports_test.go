@@ -0,0 +1,149 @@
+package reports
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+const reportDetails = `{
+  "id": "local_Availability-Summary",
+  "parameters": [
+    {"name": "endDate", "displayName": "End of the period", "type": "date", "value": "2020-01-31"},
+    {"name": "offenders", "displayName": "Number of offenders", "type": "integer", "value": 20}
+  ],
+  "formats": ["PDF", "CSV"]
+}`
+
+func createMockServer(t *testing.T, requests *[]model.ReportRequest, download bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/reports":
+			res.Write([]byte(`[{"id": "local_Availability-Summary", "name": "Availability Summary", "description": "Availability by category", "online": true}]`))
+		case "/rest/reports/local_Availability-Summary":
+			res.Write([]byte(reportDetails))
+		case "/rest/reports/local_Availability-Summary/deliver":
+			assert.Equal(t, http.MethodPost, req.Method)
+			request := model.ReportRequest{}
+			data, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(data, &request))
+			*requests = append(*requests, request)
+			res.WriteHeader(http.StatusAccepted)
+		case "/rest/reports/persisted":
+			persisted := `[{"id": 1, "reportId": "local_Availability-Summary"}`
+			if len(*requests) > 0 {
+				persisted += `, {"id": 7, "reportId": "local_Other"}, {"id": 8, "reportId": "local_Availability-Summary"}`
+			}
+			res.Write([]byte(persisted + "]"))
+		case "/rest/reports/persisted/8":
+			if !download {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			assert.Equal(t, "CSV", req.URL.Query().Get("format"))
+			res.Write([]byte("category,availability\nServers,99.9\n"))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestListReports(t *testing.T) {
+	var requests []model.ReportRequest
+	server := createMockServer(t, &requests, true)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+	test.Client(app).ServerVersion = &rest.Version{Major: 26}
+
+	output, err := test.RunWithOutput(app, "table", "reports", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `ID                          NAME                  ONLINE  DESCRIPTION
+local_Availability-Summary  Availability Summary  true    Availability by category
+`, output)
+
+	output, err = test.RunWithOutput(app, "table", "reports", "show", "local_Availability-Summary")
+	assert.NilError(t, err)
+	assert.Equal(t, `PARAMETER  TYPE     DEFAULT     DESCRIPTION
+endDate    date     2020-01-31  End of the period
+offenders  integer  20          Number of offenders
+
+Formats: PDF, CSV
+`, output)
+}
+
+func TestRunReport(t *testing.T) {
+	var requests []model.ReportRequest
+	server := createMockServer(t, &requests, true)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+	test.Client(app).ServerVersion = &rest.Version{Major: 26}
+
+	_, err := test.RunWithOutput(app, "table", "reports", "run", "local_Availability-Summary", "-p", "startDate=2020-01-01")
+	assert.Error(t, err, "Unknown parameter startDate for report local_Availability-Summary, valid options: endDate, offenders")
+	_, err = test.RunWithOutput(app, "table", "reports", "run", "local_Availability-Summary", "-p", "offenders=many")
+	assert.Error(t, err, "Invalid integer value many for parameter offenders")
+	_, err = test.RunWithOutput(app, "table", "reports", "run", "local_Availability-Summary", "--format", "XLS")
+	assert.Error(t, err, "Invalid format XLS for report local_Availability-Summary, valid options: PDF, CSV")
+	_, err = test.RunWithOutput(app, "table", "reports", "run", "local_Availability-Summary", "--deliver", "noc@example.com")
+	assert.Error(t, err, "Invalid delivery noc@example.com, expected email:<address>")
+	_, err = test.RunWithOutput(app, "table", "reports", "run", "local_Availability-Summary", "--deliver", "email:noc")
+	assert.Error(t, err, "Invalid e-mail address noc")
+	assert.Equal(t, 0, len(requests))
+
+	_, err = test.RunWithOutput(app, "table", "reports", "run", "local_Availability-Summary", "-p", "offenders=10", "--deliver", "email:noc@example.com", "--deliver", "email:ops@example.com")
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(requests))
+	request := requests[0]
+	assert.Equal(t, "PDF", request.Format)
+	assert.Assert(t, request.Persist)
+	assert.Assert(t, request.SendMail)
+	assert.Equal(t, "noc@example.com,ops@example.com", request.MailTo)
+	assert.Equal(t, "2020-01-31", request.Parameters[0].Value)
+	assert.Equal(t, float64(10), request.Parameters[1].Value)
+
+	requests = nil
+	dir, err := ioutil.TempDir("", "onmsctl-reports")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "availability.csv")
+	_, err = test.RunWithOutput(app, "table", "reports", "run", "local_Availability-Summary", "--format", "csv", "--output-file", file, "--poll-interval", "10ms")
+	assert.NilError(t, err)
+	assert.Equal(t, "CSV", requests[0].Format)
+	data, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(string(data), "category,availability\n"))
+}
+
+func TestRunReportWithoutDownloads(t *testing.T) {
+	var requests []model.ReportRequest
+	server := createMockServer(t, &requests, false)
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+	test.Client(app).ServerVersion = &rest.Version{Major: 26}
+
+	dir, err := ioutil.TempDir("", "onmsctl-reports")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "availability.csv")
+	_, err = test.RunWithOutput(app, "table", "reports", "run", "local_Availability-Summary", "--format", "CSV", "-O", file, "--poll-interval", "10ms")
+	assert.Error(t, err, "The server doesn't support downloading the stored reports; report 8 is available on the web UI")
+}
+
+func TestReportsRequireRecentServer(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	test.Client(app).ServerVersion = &rest.Version{Major: 25, Minor: 2, Patch: 1}
+
+	_, err := test.RunWithOutput(app, "table", "reports", "list")
+	assert.Error(t, err, "Running database reports through the ReST API requires OpenNMS >= 26.0.0, the server runs 25.2.1")
+}
@@ -2,15 +2,18 @@ package resources
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/OpenNMS/onmsctl/api"
-	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/services"
 	"github.com/urfave/cli"
-
-	"gopkg.in/yaml.v2"
 )
 
+// The maximum width of the list of attributes on tables, unless --wide is used
+const attributesWidth = 60
+
 // CliCommand the CLI command to manage events
 var CliCommand = cli.Command{
 	Name:  "resources",
@@ -18,8 +21,32 @@ var CliCommand = cli.Command{
 	Subcommands: []cli.Command{
 		{
 			Name:   "list",
-			Usage:  "Shows the ID of each resource and its children",
+			Usage:  "Shows the tree of resources, with their labels and attributes",
 			Action: showResources,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "node, n",
+					Usage: "Only show the resources of a given node (nodeId or FS:FID)",
+				},
+				cli.IntFlag{
+					Name:  "depth, d",
+					Value: 1,
+					Usage: "The levels of children to retrieve; use -1 for all of them",
+				},
+				cli.StringFlag{
+					Name:  "search, s",
+					Usage: "Only show the resources exposing a given attribute (e.x. ifHCInOctets), and their parents",
+				},
+				cli.IntFlag{
+					Name:  "max-children",
+					Value: 20,
+					Usage: "The maximum number of children displayed per resource on tables, summarizing the rest; use 0 for all of them",
+				},
+				cli.BoolFlag{
+					Name:  "wide",
+					Usage: "Do not truncate the list of attributes on tables",
+				},
+			},
 		},
 		{
 			Name:      "show",
@@ -44,40 +71,85 @@ var CliCommand = cli.Command{
 }
 
 func showResources(c *cli.Context) error {
-	resourceList, err := getAPI().GetResources()
+	resourceList, err := getResources(c, c.String("node"), c.Int("depth"))
 	if err != nil {
 		return err
 	}
-	resourceList.Enumerate("")
-	return nil
+	empty := "There are no resources"
+	if attribute := c.String("search"); attribute != "" {
+		*resourceList = resourceList.Search(attribute)
+		empty = fmt.Sprintf("There are no resources with the attribute %s at depth %d", attribute, c.Int("depth"))
+	}
+	table := common.NewTable(empty, "ID", "LABEL", "TYPE", "ATTRIBUTES")
+	width := attributesWidth
+	if c.Bool("wide") {
+		width = 0
+	}
+	addResourceRows(table, resourceList.Resources, "", c.Int("max-children"), width)
+	return common.Print(resourceList, table)
+}
+
+func getResources(c *cli.Context, node string, depth int) (*model.ResourceList, error) {
+	if node == "" {
+		return getAPI(c).GetResources(depth)
+	}
+	resource, err := getAPI(c).GetResourceForNode(node, depth)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ResourceList{Count: 1, Resources: []model.Resource{*resource}}, nil
+}
+
+// Adds a row per resource, indenting the children; long lists of children are summarized with a count
+func addResourceRows(table *common.Table, resources []model.Resource, indent string, maxChildren int, width int) {
+	for _, r := range resources {
+		attributes := strings.Join(r.Attributes(), ",")
+		if width > 0 {
+			attributes = common.Truncate(attributes, width)
+		}
+		table.AddRow(indent+r.ID, valueOrDash(r.Label), valueOrDash(r.TypeLabel), valueOrDash(attributes))
+		if r.Children == nil {
+			continue
+		}
+		children := r.Children.Resources
+		if maxChildren > 0 && len(children) > maxChildren {
+			addResourceRows(table, children[:maxChildren], indent+"  ", maxChildren, width)
+			table.AddRow(fmt.Sprintf("%s  (%d more, use --max-children 0 to see them)", indent, len(children)-maxChildren), "", "", "")
+			continue
+		}
+		addResourceRows(table, children, indent+"  ", maxChildren, width)
+	}
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
 }
 
 func showResource(c *cli.Context) error {
-	resource, err := getAPI().GetResource(c.Args().Get(0))
+	resource, err := getAPI(c).GetResource(c.Args().Get(0))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(resource)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(resource, nil)
 }
 
 func showNode(c *cli.Context) error {
-	resource, err := getAPI().GetResourceForNode(c.Args().Get(0))
+	resource, err := getAPI(c).GetResourceForNode(c.Args().Get(0), -1)
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(resource)
-	fmt.Println(string(data))
-	return nil
+	return common.Print(resource, nil)
 }
 
 func deleteResource(c *cli.Context) error {
-	getAPI().DeleteResource(c.Args().Get(0))
-	fmt.Println("Resource has been deleted.")
+	getAPI(c).DeleteResource(c.Args().Get(0))
+	common.Log.Infof("Resource has been deleted.")
 	return nil
 }
 
-func getAPI() api.ResourcesAPI {
-	return services.GetResourcesAPI(rest.Instance)
+func getAPI(c *cli.Context) api.ResourcesAPI {
+	return services.GetResourcesAPI(common.GetClient(c))
 }
@@ -0,0 +1,79 @@
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func createMockServer(t *testing.T, interfaces int) *httptest.Server {
+	children := []string{`{"id": "node[Servers:web01].nodeSnmp[]", "label": "Node-level Performance Data", "typeLabel": "SNMP Node Data", "rrdGraphAttributes": {"loadavg1": {"name": "loadavg1"}, "memAvailReal": {"name": "memAvailReal"}}}`}
+	for i := 0; i < interfaces; i++ {
+		children = append(children, fmt.Sprintf(`{"id": "node[Servers:web01].interfaceSnmp[eth%d]", "label": "eth%d", "typeLabel": "SNMP Interface Data", "rrdGraphAttributes": {"ifHCInOctets": {"name": "ifHCInOctets"}, "ifHCOutOctets": {"name": "ifHCOutOctets"}}}`, i, i))
+	}
+	node := fmt.Sprintf(`{"id": "node[Servers:web01]", "label": "web01", "typeLabel": "Node", "children": {"count": %d, "resource": [%s]}}`, len(children), strings.Join(children, ","))
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.Path {
+		case "/rest/resources":
+			assert.Equal(t, "1", req.URL.Query().Get("depth"))
+			res.Write([]byte(`{"count": 1, "resource": [` + node + `]}`))
+		case "/rest/resources/fornode/Servers:web01":
+			assert.Equal(t, "2", req.URL.Query().Get("depth"))
+			res.Write([]byte(node))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestListResources(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, 1)
+	defer server.Close()
+	test.Client(app).URL = server.URL
+
+	output, err := test.RunWithOutput(app, "table", "resources", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `ID                                         LABEL                        TYPE                 ATTRIBUTES
+node[Servers:web01]                        web01                        Node                 -
+  node[Servers:web01].nodeSnmp[]           Node-level Performance Data  SNMP Node Data       loadavg1,memAvailReal
+  node[Servers:web01].interfaceSnmp[eth0]  eth0                         SNMP Interface Data  ifHCInOctets,ifHCOutOctets
+`, output)
+
+	output, err = test.RunWithOutput(app, "table", "resources", "list", "--node", "Servers:web01", "--depth", "2", "--search", "ifHCInOctets")
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(output, "nodeSnmp"))
+	assert.Assert(t, strings.Contains(output, "interfaceSnmp[eth0]"))
+
+	output, err = test.RunWithOutput(app, "table", "resources", "list", "--search", "ifInErrors")
+	assert.NilError(t, err)
+	assert.Equal(t, "There are no resources with the attribute ifInErrors at depth 1\n", output)
+
+	output, err = test.RunWithOutput(app, "json", "resources", "list", "--search", "loadavg1")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, `"rrdGraphAttributes"`))
+	assert.Assert(t, !strings.Contains(output, "interfaceSnmp"))
+}
+
+func TestListResourcesSummarizesChildren(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, 250)
+	defer server.Close()
+	test.Client(app).URL = server.URL
+
+	output, err := test.RunWithOutput(app, "table", "resources", "list", "--max-children", "5")
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Equal(t, 8, len(lines))
+	assert.Equal(t, "  (246 more, use --max-children 0 to see them)", strings.TrimRight(lines[7], " "))
+
+	output, err = test.RunWithOutput(app, "table", "resources", "list", "--max-children", "0")
+	assert.NilError(t, err)
+	assert.Equal(t, 253, len(strings.Split(strings.TrimSpace(output), "\n")))
+}
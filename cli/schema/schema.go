@@ -0,0 +1,40 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// CliCommand the CLI command to generate the JSON Schema of the YAML resources
+var CliCommand = cli.Command{
+	Name:      "schema",
+	Usage:     "Generates the JSON Schema of a YAML resource, for editors with schema validation (e.x. the YAML extension of VS Code)",
+	ArgsUsage: "<" + strings.Join(model.GetSchemaResourceNames(), "|") + ">",
+	Action:    showSchema,
+	BashComplete: func(c *cli.Context) {
+		if c.NArg() == 0 {
+			common.PrintCompletions(model.GetSchemaResourceNames()...)
+		}
+	},
+}
+
+func showSchema(c *cli.Context) error {
+	resource := c.Args().First()
+	if resource == "" {
+		return fmt.Errorf("Resource required, valid options: %s", strings.Join(model.GetSchemaResourceNames(), ", "))
+	}
+	schema, err := model.GetSchema(resource)
+	if err != nil {
+		return err
+	}
+	// The patterns would be unreadable with the HTML characters escaped
+	encoder := json.NewEncoder(common.Output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(schema)
+}
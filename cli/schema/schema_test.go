@@ -0,0 +1,33 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+func TestSchema(t *testing.T) {
+	app := test.CreateCli(CliCommand)
+
+	output, err := test.RunWithOutput(app, "table", "schema", "node")
+	assert.NilError(t, err)
+	schema := &model.Schema{}
+	assert.NilError(t, json.Unmarshal([]byte(output), schema))
+	assert.Equal(t, model.SchemaDraft, schema.Schema)
+	assert.Equal(t, "node", schema.Title)
+	assert.Equal(t, `^[^/\\?:&*'"]+$`, schema.Properties["foreignID"].Pattern)
+	assert.Equal(t, "#/definitions/RequisitionInterface", schema.Properties["interfaces"].Items.Ref)
+
+	_, err = test.RunWithOutput(app, "table", "schema", "alarm")
+	assert.ErrorContains(t, err, "Invalid resource alarm; allowed values are requisition, node, interface")
+	_, err = test.RunWithOutput(app, "table", "schema")
+	assert.ErrorContains(t, err, "Resource required")
+
+	app.EnableBashCompletion = true
+	output, err = test.RunWithOutput(app, "table", "schema", "--generate-bash-completion")
+	assert.NilError(t, err)
+	assert.Equal(t, "requisition\nnode\ninterface\nforeign-source\nevent\nsnmp-config\nsnmp-range\n", output)
+}
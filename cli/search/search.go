@@ -4,16 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/fiql"
 	"github.com/OpenNMS/onmsctl/model"
-	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/urfave/cli"
 	"gopkg.in/yaml.v2"
 )
 
 // Entities list of valid searchable entities
-var Entities = &model.EnumValue{
-	Enum: []string{"nodes", "events", "alarms", "outages"},
-}
+var Entities = model.NewEnum("nodes", "events", "alarms", "outages")
 
 // CliCommand the CLI command to provide search capabilities information
 var CliCommand = cli.Command{
@@ -47,15 +46,18 @@ var CliCommand = cli.Command{
 		}
 		url := fmt.Sprintf("/api/v2/%s?limit=%d&offset=%d", entity, c.Int("limit"), c.Int("offset"))
 		filter := c.String("filter")
+		if err := fiql.Validate(filter); err != nil {
+			return err
+		}
 		if filter != "" {
 			url += "&_s=" + filter
 		}
-		jsonBytes, err := rest.Instance.Get(url)
+		jsonBytes, err := common.GetClient(c).Get(url)
 		if err != nil {
 			return err
 		}
 		if len(jsonBytes) == 0 {
-			fmt.Printf("There is no data for %s\n", entity)
+			common.Log.Infof("There is no data for %s", entity)
 			return nil
 		}
 		var data interface{}
@@ -74,7 +76,7 @@ var CliCommand = cli.Command{
 			return err
 		}
 		yamlBytes, _ := yaml.Marshal(data)
-		fmt.Println(string(yamlBytes))
+		fmt.Fprintln(common.Output, string(yamlBytes))
 		return nil
 	},
 }
@@ -1,12 +1,13 @@
 package snmp
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/OpenNMS/onmsctl/api"
 	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
-	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/OpenNMS/onmsctl/services"
 	"github.com/urfave/cli"
 
@@ -28,6 +29,11 @@ var CliCommand = cli.Command{
 					Name:  "location, l",
 					Usage: "Minion Location",
 				},
+				cli.GenericFlag{
+					Name:  "format, x",
+					Value: model.NewEnum("yaml", "json").WithDefault("yaml"),
+					Usage: "Output Format: yaml, json",
+				},
 			},
 		},
 		{
@@ -51,7 +57,7 @@ var CliCommand = cli.Command{
 					Usage: "The UDP Port of the SNMP agent",
 				},
 				cli.IntFlag{
-					Name:  "retry, r",
+					Name:  "retry, retries, r",
 					Value: 2,
 					Usage: "The number of retries before giving up",
 				},
@@ -131,16 +137,40 @@ var CliCommand = cli.Command{
 				},
 			},
 		},
+		{
+			Name:      "set-bulk",
+			Usage:     "Sets the SNMP configuration for IP ranges from a CSV file (" + strings.Join(CSVColumns, ",") + "), or a YAML rollback file",
+			Action:    setBulkSnmpConfig,
+			ArgsUsage: "<csv>",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External CSV file with a header row, where firstIP can be a CIDR block (use '-' for STDIN Pipe); YAML is expected for files ending with .yaml or .yml",
+				},
+				cli.StringFlag{
+					Name:  "rollback-file",
+					Usage: "File to save the current configuration of each range before changing it; use it with set-bulk to undo the changes",
+				},
+			},
+		},
 	},
 }
 
 func showSnmpConfig(c *cli.Context) error {
-	snmp, err := getAPI().GetConfig(c.Args().Get(0), c.String("location"))
+	snmp, err := getAPI(c).GetConfig(c.Args().Get(0), c.String("location"))
 	if err != nil {
 		return err
 	}
-	data, _ := yaml.Marshal(&snmp)
-	fmt.Println(string(data))
+	var data []byte
+	if c.String("format") == "json" {
+		data, err = json.MarshalIndent(snmp, "", "  ")
+	} else {
+		data, err = yaml.Marshal(snmp)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(common.Output, string(data))
 	return nil
 }
 
@@ -166,10 +196,10 @@ func setSnmpConfig(c *cli.Context) error {
 		MaxRepetitions:  c.Int("maxRepetitions"),
 		MaxVarsPerPdu:   c.Int("maxVarsPerPdu"),
 	}
-	if err := checkLocation(snmp); err != nil {
+	if err := checkLocation(c, snmp); err != nil {
 		return err
 	}
-	return getAPI().SetConfig(c.Args().Get(0), snmp)
+	return getAPI(c).SetConfig(c.Args().Get(0), snmp)
 }
 
 func applySnmpConfig(c *cli.Context) error {
@@ -177,20 +207,18 @@ func applySnmpConfig(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	snmp := model.SnmpInfo{}
-	err = yaml.Unmarshal(data, &snmp)
-	if err != nil {
-		return err
-	}
-	if err = checkLocation(snmp); err != nil {
-		return err
-	}
-	return getAPI().SetConfig(c.Args().Get(0), snmp)
+	snmp := &model.SnmpInfo{}
+	return common.ApplyYAML(data, snmp, func() error {
+		if err := checkLocation(c, *snmp); err != nil {
+			return err
+		}
+		return getAPI(c).SetConfig(c.Args().Get(0), *snmp)
+	})
 }
 
-func checkLocation(snmp model.SnmpInfo) error {
+func checkLocation(c *cli.Context, snmp model.SnmpInfo) error {
 	if snmp.Location != "" {
-		ok, err := getMonitoringLocationsAPI().LocationExists(snmp.Location)
+		ok, err := getMonitoringLocationsAPI(c).LocationExists(snmp.Location)
 		if err == nil && !ok {
 			return fmt.Errorf("Location %s doesn't exists", snmp.Location)
 		}
@@ -198,10 +226,10 @@ func checkLocation(snmp model.SnmpInfo) error {
 	return nil
 }
 
-func getAPI() api.SnmpAPI {
-	return services.GetSnmpAPI(rest.Instance)
+func getAPI(c *cli.Context) api.SnmpAPI {
+	return services.GetSnmpAPI(common.GetClient(c))
 }
 
-func getMonitoringLocationsAPI() api.MonitoringLocationsAPI {
-	return services.GetMonitoringLocationsAPI(rest.Instance)
+func getMonitoringLocationsAPI(c *cli.Context) api.MonitoringLocationsAPI {
+	return services.GetMonitoringLocationsAPI(common.GetClient(c))
 }
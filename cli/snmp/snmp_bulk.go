@@ -0,0 +1,217 @@
+package snmp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CSVColumns the columns accepted when setting the SNMP configuration from a CSV file
+var CSVColumns = []string{"firstIP", "lastIP", "version", "community", "securityName", "securityLevel", "authProtocol", "authPassPhrase", "privProtocol", "privPassPhrase", "location"}
+
+// snmpRangeRow an SNMP range parsed from a given line of the input file
+type snmpRangeRow struct {
+	line   int
+	config model.SnmpRange
+}
+
+// Sets the SNMP configuration for multiple IP ranges; everything is validated before sending any request
+func setBulkSnmpConfig(c *cli.Context) error {
+	data, err := common.ReadInput(c, 0)
+	if err != nil {
+		return err
+	}
+	var rows []snmpRangeRow
+	var problems []string
+	if ext := strings.ToLower(filepath.Ext(c.String("file"))); ext == ".yaml" || ext == ".yml" {
+		rows, problems = parseSnmpRangesYAML(data)
+	} else {
+		rows, problems = parseSnmpRangesCSV(bytes.NewReader(data))
+	}
+	for _, row := range rows {
+		if err := checkLocation(c, row.config.SnmpInfo); err != nil {
+			problems = append(problems, fmt.Sprintf("Line %d: %s", row.line, err))
+		}
+	}
+	if len(problems) > 0 {
+		for _, p := range problems {
+			common.Log.Errorf("%s", p)
+		}
+		return common.ValidationError(fmt.Errorf("%d rows failed validation, nothing has been sent", len(problems)))
+	}
+	if rollbackFile := c.String("rollback-file"); rollbackFile != "" {
+		if err := writeRollbackFile(c, rollbackFile, rows); err != nil {
+			return err
+		}
+		common.Log.Infof("Previous configuration of %d ranges saved to %s", len(rows), rollbackFile)
+	}
+	for _, row := range rows {
+		r := row.config
+		if err := getAPI(c).SetRangeConfig(r); err != nil {
+			return fmt.Errorf("Line %d: cannot set SNMP configuration for %s-%s: %w", row.line, r.FirstIPAddress, r.LastIPAddress, err)
+		}
+		common.Log.Infof("Line %d: SNMP %s configuration set for %s-%s", row.line, r.Version, r.FirstIPAddress, r.LastIPAddress)
+	}
+	return nil
+}
+
+// Saves the current configuration of the first IP address of each range, in a format accepted by set-bulk
+func writeRollbackFile(c *cli.Context, file string, rows []snmpRangeRow) error {
+	previous := make([]model.SnmpRange, 0, len(rows))
+	for _, row := range rows {
+		r := row.config
+		snmp, err := getAPI(c).GetConfig(r.FirstIPAddress, r.Location)
+		if err != nil {
+			return fmt.Errorf("Line %d: cannot get the SNMP configuration for %s: %w", row.line, r.FirstIPAddress, err)
+		}
+		if snmp.Location == "" {
+			snmp.Location = r.Location
+		}
+		previous = append(previous, model.SnmpRange{FirstIPAddress: r.FirstIPAddress, LastIPAddress: r.LastIPAddress, SnmpInfo: *snmp})
+	}
+	data, err := yaml.Marshal(previous)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+// Parses the ranges from a YAML list, like the one written as rollback file
+func parseSnmpRangesYAML(data []byte) ([]snmpRangeRow, []string) {
+	ranges := []model.SnmpRange{}
+	if err := yaml.Unmarshal(data, &ranges); err != nil {
+		return nil, []string{fmt.Sprintf("Cannot parse YAML: %s", err)}
+	}
+	rows := []snmpRangeRow{}
+	problems := []string{}
+	for i, r := range ranges {
+		if err := r.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("Range %d: %s", i+1, err))
+			continue
+		}
+		rows = append(rows, snmpRangeRow{i + 1, r})
+	}
+	return rows, problems
+}
+
+// Parses and validates the ranges from a CSV with a header row; it returns all the problems found instead of stopping on the first one
+func parseSnmpRangesCSV(input io.Reader) ([]snmpRangeRow, []string) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, []string{fmt.Sprintf("Line 1: cannot read CSV header: %s", err)}
+	}
+	columns := make(map[string]int)
+	for i, h := range header {
+		for _, col := range CSVColumns {
+			if strings.EqualFold(strings.TrimSpace(h), col) {
+				columns[col] = i
+			}
+		}
+	}
+	if _, ok := columns["firstIP"]; !ok {
+		return nil, []string{"Line 1: the firstIP column is required; valid columns: " + strings.Join(CSVColumns, ", ")}
+	}
+	rows := []snmpRangeRow{}
+	problems := []string{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Line %d: %s", line, err))
+			continue
+		}
+		config, err := buildSnmpRangeFromCSV(record, columns)
+		if err == nil {
+			err = config.Validate()
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Line %d: %s", line, err))
+			continue
+		}
+		rows = append(rows, snmpRangeRow{line, *config})
+	}
+	return rows, problems
+}
+
+func buildSnmpRangeFromCSV(record []string, columns map[string]int) (*model.SnmpRange, error) {
+	get := func(col string) string {
+		if i, ok := columns[col]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	config := &model.SnmpRange{
+		FirstIPAddress: get("firstIP"),
+		LastIPAddress:  get("lastIP"),
+		SnmpInfo: model.SnmpInfo{
+			Version:        get("version"),
+			Community:      get("community"),
+			SecurityName:   get("securityName"),
+			AuthProtocol:   get("authProtocol"),
+			AuthPassPhrase: get("authPassPhrase"),
+			PrivProtocol:   get("privProtocol"),
+			PrivPassPhrase: get("privPassPhrase"),
+			Location:       get("location"),
+		},
+	}
+	if strings.Contains(config.FirstIPAddress, "/") {
+		if config.LastIPAddress != "" {
+			return nil, fmt.Errorf("lastIP must be empty when firstIP is a CIDR block")
+		}
+		first, last, err := model.GetCIDRRange(config.FirstIPAddress)
+		if err != nil {
+			return nil, err
+		}
+		config.FirstIPAddress, config.LastIPAddress = first, last
+	} else if config.LastIPAddress == "" {
+		config.LastIPAddress = config.FirstIPAddress
+	}
+	if config.Version == "" {
+		config.Version = model.SNMPVersions.Default
+	}
+	if config.Version != "v3" && config.SecurityName+config.AuthPassPhrase+config.PrivPassPhrase+get("securityLevel") != "" {
+		return nil, fmt.Errorf("SNMPv3 fields are not used with SNMP %s", config.Version)
+	}
+	if config.PrivPassPhrase != "" && config.AuthPassPhrase == "" {
+		return nil, fmt.Errorf("SNMPv3 Priv Pass Phrase requires an Auth Pass Phrase")
+	}
+	if level := get("securityLevel"); level != "" {
+		value, err := strconv.Atoi(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid security level %s", level)
+		}
+		config.SecurityLevel = value
+	} else if config.Version == "v3" {
+		config.SecurityLevel = inferSecurityLevel(config.SnmpInfo)
+	}
+	return config, nil
+}
+
+// Infers the SNMPv3 security level from the pass phrases when it is not explicitly set
+func inferSecurityLevel(snmp model.SnmpInfo) int {
+	switch {
+	case snmp.PrivPassPhrase != "":
+		return 3
+	case snmp.AuthPassPhrase != "":
+		return 2
+	}
+	return 1
+}
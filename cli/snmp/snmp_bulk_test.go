@@ -0,0 +1,88 @@
+package snmp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+func TestSetBulkSnmp(t *testing.T) {
+	updates := []model.SnmpRange{}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			bytes, _ := json.Marshal(model.SnmpInfo{Version: "v2c", Community: "old", Port: 161})
+			res.Write(bytes)
+		case http.MethodPut:
+			config := model.SnmpRange{}
+			bytes, _ := ioutil.ReadAll(req.Body)
+			assert.NilError(t, json.Unmarshal(bytes, &config))
+			assert.Equal(t, "/rest/snmpConfig/"+config.FirstIPAddress, req.URL.Path)
+			updates = append(updates, config)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	rollbackFile := filepath.Join(dir, "rollback.yaml")
+
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+	logs, restore := test.CaptureLogs()
+	defer restore()
+
+	invalid := "firstIP,lastIP,version,community,securityName,authPassPhrase,privPassPhrase\n" +
+		"10.0.0.10,10.0.0.1,v2c,public,,,\n" +
+		"10.0.1.0/24,,v1,public,admin,,\n" +
+		"10.0.2.1,,v3,,admin,,secret\n" +
+		"10.0.3.1,,v2c,public,,,\n"
+	_, err = test.RunWithOutput(app, "table", "snmp", "set-bulk", invalid)
+	assert.Error(t, err, "3 rows failed validation, nothing has been sent")
+	assert.Equal(t, "ERROR: Line 2: begin 10.0.0.10 must be lower or equal than end 10.0.0.1\n"+
+		"ERROR: Line 3: SNMPv3 fields are not used with SNMP v1\n"+
+		"ERROR: Line 4: SNMPv3 Priv Pass Phrase requires an Auth Pass Phrase\n", logs.String())
+	assert.Equal(t, 0, len(updates))
+
+	valid := "firstIP,lastIP,version,community,securityName,authProtocol,authPassPhrase\n" +
+		"10.0.1.0/30,,v2c,public,,,\n" +
+		"10.0.2.1,10.0.2.20,v3,,admin,SHA,0p3nNMS\n"
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "snmp", "set-bulk", "--rollback-file", rollbackFile, valid)
+	assert.NilError(t, err)
+	assert.Equal(t, "Previous configuration of 2 ranges saved to "+rollbackFile+"\n"+
+		"Line 2: SNMP v2c configuration set for 10.0.1.0-10.0.1.3\n"+
+		"Line 3: SNMP v3 configuration set for 10.0.2.1-10.0.2.20\n", logs.String())
+	assert.Equal(t, 2, len(updates))
+	assert.Equal(t, "public", updates[0].Community)
+	assert.Equal(t, 2, updates[1].SecurityLevel)
+
+	previous := []model.SnmpRange{}
+	data, err := ioutil.ReadFile(rollbackFile)
+	assert.NilError(t, err)
+	assert.NilError(t, yaml.Unmarshal(data, &previous))
+	assert.Equal(t, 2, len(previous))
+	assert.Equal(t, "10.0.2.20", previous[1].LastIPAddress)
+	assert.Equal(t, "old", previous[1].Community)
+
+	updates = updates[:0]
+	logs.Reset()
+	_, err = test.RunWithOutput(app, "table", "snmp", "set-bulk", "-f", rollbackFile)
+	assert.NilError(t, err)
+	assert.Equal(t, "Line 1: SNMP v2c configuration set for 10.0.1.0-10.0.1.3\n"+
+		"Line 2: SNMP v2c configuration set for 10.0.2.1-10.0.2.20\n", logs.String())
+	assert.Equal(t, "old", updates[1].Community)
+}
@@ -9,7 +9,6 @@ import (
 	"testing"
 
 	"github.com/OpenNMS/onmsctl/model"
-	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/OpenNMS/onmsctl/test"
 	"github.com/google/go-cmp/cmp"
 
@@ -46,7 +45,6 @@ func createMockServer(t *testing.T) *httptest.Server {
 			res.WriteHeader(http.StatusForbidden)
 		}
 	}))
-	rest.Instance.URL = server.URL
 	return server
 }
 
@@ -54,6 +52,7 @@ func TestGetSnmp(t *testing.T) {
 	var err error
 	app := test.CreateCli(CliCommand)
 	server := createMockServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "snmp", "get"})
@@ -67,17 +66,24 @@ func TestGetSnmp(t *testing.T) {
 
 	err = app.Run([]string{app.Name, "snmp", "get", "localhost"})
 	assert.NilError(t, err)
+
+	err = app.Run([]string{app.Name, "snmp", "get", "-x", "json", "10.0.0.1"})
+	assert.NilError(t, err)
 }
 
 func TestSetSnmp(t *testing.T) {
 	var err error
 	app := test.CreateCli(CliCommand)
 	server := createMockServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	err = app.Run([]string{app.Name, "snmp", "set"})
 	assert.Error(t, err, "IP Address or FQDN required")
 
+	err = app.Run([]string{app.Name, "snmp", "set", "-v", "v3", "-sn", "opennms", "-sl", "3", "-app", "0p3nNMSv3", "10.0.0.1"})
+	assert.Error(t, err, "SNMPv3 Priv Pass Phrase required for authPriv")
+
 	err = app.Run([]string{app.Name, "snmp", "set", "-c", mockData.Community, "-v", mockData.Version, "10.0.0.1"})
 	assert.NilError(t, err)
 }
@@ -86,6 +92,7 @@ func TestApplySnmp(t *testing.T) {
 	var err error
 	app := test.CreateCli(CliCommand)
 	server := createMockServer(t)
+	test.Client(app).URL = server.URL
 	defer server.Close()
 
 	yamlBytes, _ := yaml.Marshal(mockData)
@@ -0,0 +1,150 @@
+package status
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// The amount of nodes or minions named on each line of the summary
+const maxListed = 5
+
+// The ANSI sequence that moves the cursor home and clears the screen, to refresh the summary in place
+const clearScreen = "\033[H\033[2J"
+
+// CliCommand the CLI command to display a summary of the state of the server
+var CliCommand = cli.Command{
+	Name:  "status",
+	Usage: "Displays a summary of alarms, outages, minions and requisition imports",
+	Description: "The sections are requested concurrently; a section whose endpoint is missing or that doesn't answer on time is reported as n/a.\n" +
+		"   The table output fits on a single screen, for a terminal dashboard or a cron email.",
+	Action: showStatus,
+	Flags: []cli.Flag{
+		cli.DurationFlag{
+			Name:  "watch, w",
+			Usage: "Refreshes the summary in place with the given interval (e.x. 30s), until interrupted",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Value: 10 * time.Second,
+			Usage: "The time to wait for all the sections; the ones that are not ready are reported as n/a",
+		},
+		cli.DurationFlag{
+			Name:  "stale",
+			Value: 5 * time.Minute,
+			Usage: "A minion whose last heartbeat is older than this is considered down",
+		},
+	},
+}
+
+func showStatus(c *cli.Context) error {
+	interval := c.Duration("watch")
+	if interval < 0 {
+		return fmt.Errorf("Watch interval cannot be negative")
+	}
+	if interval == 0 {
+		return printStatus(c)
+	}
+	common.GetClient(c).CacheTTL = -1 // Every refresh must reach the server
+	for {
+		if common.OutputFormat == common.OutputTable {
+			fmt.Fprint(common.Output, clearScreen)
+		}
+		if err := printStatus(c); err != nil {
+			return err
+		}
+		if err := common.GetClient(c).Sleep(interval); err != nil {
+			return nil
+		}
+	}
+}
+
+func printStatus(c *cli.Context) error {
+	status, err := getAPI(c).GetStatus(c.Duration("timeout"), c.Duration("stale"))
+	if err != nil {
+		return err
+	}
+	if common.OutputFormat != common.OutputTable {
+		return common.Print(status, nil)
+	}
+	writeStatus(common.Output, *status, common.GetClient(c).URL)
+	return nil
+}
+
+// Writes the summary as a few aligned lines
+func writeStatus(output io.Writer, status model.ServerStatus, url string) {
+	fmt.Fprintf(output, "OpenNMS status of %s at %s\n", url, status.Time.Format(time.RFC3339))
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Alarms:\t%s\n", describeAlarms(status.Alarms))
+	fmt.Fprintf(w, "Outages:\t%s\n", describeOutages(status.Outages))
+	fmt.Fprintf(w, "Minions:\t%s\n", describeMinions(status.Minions))
+	if !status.Imports.IsAvailable() {
+		fmt.Fprintf(w, "Imports:\t%s\n", status.Imports.Unavailable())
+	} else if len(status.Imports.Requisitions) == 0 {
+		fmt.Fprintf(w, "Imports:\tthere are no requisitions\n")
+	} else {
+		fmt.Fprintf(w, "Imports:\n") // Ends the column of the headers, so the requisitions are aligned on their own
+		for _, r := range status.Imports.Requisitions {
+			fmt.Fprintf(w, "  %s\t%s, %d nodes\n", r.Name, describeImport(r.LastImport, status.Time.Time), r.Nodes)
+		}
+	}
+	w.Flush()
+}
+
+func describeAlarms(alarms model.AlarmsStatus) string {
+	if !alarms.IsAvailable() {
+		return alarms.Unavailable()
+	}
+	counts := make([]string, len(alarms.Severities))
+	for i, s := range alarms.Severities {
+		counts[i] = fmt.Sprintf("%d %s", s.Count, s.Severity)
+	}
+	return strings.Join(counts, ", ")
+}
+
+func describeOutages(outages model.OutagesStatus) string {
+	if !outages.IsAvailable() {
+		return outages.Unavailable()
+	}
+	if outages.Outages == 0 {
+		return "no current outages"
+	}
+	return fmt.Sprintf("%d current outages, %d nodes down (%s)", outages.Outages, len(outages.NodesDown), describeList(outages.NodesDown))
+}
+
+func describeMinions(minions model.MinionsStatus) string {
+	if !minions.IsAvailable() {
+		return minions.Unavailable()
+	}
+	if len(minions.Down) == 0 {
+		return fmt.Sprintf("%d up", minions.Total)
+	}
+	return fmt.Sprintf("%d of %d down (%s)", len(minions.Down), minions.Total, describeList(minions.Down))
+}
+
+func describeImport(lastImport *model.Time, current time.Time) string {
+	if lastImport == nil || lastImport.IsZero() {
+		return "never imported"
+	}
+	return "imported " + common.HumanizeDuration(current.Sub(lastImport.Time)) + " ago"
+}
+
+// Returns the first items of a list, followed by how many were left out to keep the line short
+func describeList(items []string) string {
+	if len(items) <= maxListed {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:maxListed], ", "), len(items)-maxListed)
+}
+
+func getAPI(c *cli.Context) api.StatusAPI {
+	return services.GetStatusAPI(common.GetClient(c))
+}
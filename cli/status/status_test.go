@@ -0,0 +1,67 @@
+package status
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+
+	"gotest.tools/assert"
+)
+
+func createMockServer(t *testing.T, lastImport time.Time) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.Path {
+		case "/api/v2/outages":
+			res.Write([]byte(`{"count":7,"totalCount":7,"offset":0,"outage":[
+				{"id":1,"nodeId":1,"nodeLabel":"srv01"},{"id":2,"nodeId":2,"nodeLabel":"srv02"},
+				{"id":3,"nodeId":3,"nodeLabel":"srv03"},{"id":4,"nodeId":4,"nodeLabel":"srv04"},
+				{"id":5,"nodeId":5,"nodeLabel":"srv05"},{"id":6,"nodeId":6,"nodeLabel":"srv06"},
+				{"id":7,"nodeId":1,"nodeLabel":"srv01"}]}`))
+		case "/api/v2/minions":
+			res.Write([]byte(`{"count":2,"totalCount":2,"offset":0,"minion":[
+				{"id":"minion-01","lastUpdated":1600000000000},{"id":"minion-02","lastUpdated":1600000000000}]}`))
+		case "/rest/requisitions/deployed/stats":
+			res.Write([]byte(fmt.Sprintf(`{"count":2,"foreign-source":[
+				{"name":"Servers","count":10,"foreign-id":[],"last-imported":%d},
+				{"name":"Routers","count":3,"foreign-id":[]}]}`, lastImport.UnixNano()/int64(time.Millisecond))))
+		default:
+			res.WriteHeader(http.StatusNotFound) // The alarms endpoint is missing
+		}
+	}))
+	return server
+}
+
+func TestStatus(t *testing.T) {
+	server := createMockServer(t, time.Now().Add(-2*time.Hour))
+	defer server.Close()
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+
+	output, err := test.RunWithOutput(app, "table", "status")
+	assert.NilError(t, err)
+	lines := strings.SplitN(output, "\n", 2)
+	assert.Assert(t, strings.HasPrefix(lines[0], "OpenNMS status of "+server.URL+" at "))
+	assert.Equal(t, `Alarms:   n/a (endpoint missing)
+Outages:  7 current outages, 6 nodes down (srv01, srv02, srv03, srv04, srv05 and 1 more)
+Minions:  2 of 2 down (minion-01, minion-02)
+Imports:
+  Routers  never imported, 3 nodes
+  Servers  imported 2h ago, 10 nodes
+`, lines[1])
+
+	output, err = test.RunWithOutput(app, "json", "status")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output, `"state": "missing"`))
+	assert.Assert(t, strings.Contains(output, `"nodesDown": [`))
+
+	_, err = test.RunWithOutput(app, "table", "status", "--timeout", "0s")
+	assert.Error(t, err, "Timeout must be greater than zero")
+	_, err = test.RunWithOutput(app, "table", "status", "--watch", "-1s")
+	assert.Error(t, err, "Watch interval cannot be negative")
+}
@@ -0,0 +1,232 @@
+package thresholds
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/cli/daemon"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// The oldest version that provides the threshold groups through the ReST API
+const minThresholdsVersion = "32.0.0"
+
+// The feature reported when the server is too old
+const thresholdsFeature = "Managing thresholds through the ReST API"
+
+// reloadFlag the flag to reload Threshd and Collectd after a successful change
+var reloadFlag = cli.BoolFlag{
+	Name:  "reload",
+	Usage: "Request Threshd and Collectd to reload their configuration after the change",
+}
+
+// CliCommand the CLI command to manage thresholds
+var CliCommand = cli.Command{
+	Name:  "thresholds",
+	Usage: "Manage threshold groups",
+	Before: func(c *cli.Context) error {
+		return common.GetClient(c).MinServerVersion(minThresholdsVersion, thresholdsFeature)
+	},
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List the threshold groups",
+			Action: listGroups,
+		},
+		{
+			Name:      "get",
+			Usage:     "Gets a threshold group",
+			ArgsUsage: "<group>",
+			Action:    showGroup,
+		},
+		{
+			Name:      "apply",
+			Usage:     "Creates or replaces a threshold group from a external YAML file",
+			ArgsUsage: "<yaml>",
+			Action:    applyGroup,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "External YAML file (use '-' for STDIN Pipe)",
+				},
+				reloadFlag,
+			},
+		},
+		{
+			Name:  "threshold",
+			Usage: "Manage the thresholds of a group",
+			Subcommands: []cli.Command{
+				{
+					Name:        "add",
+					Usage:       "Adds a threshold to a group, or replaces the one with the same type, resource type and metric",
+					Description: "For high thresholds the rearm value must be below the value, and for low thresholds above it.",
+					Action:      addThreshold,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "group, g",
+							Usage: "The name of the threshold group",
+						},
+						cli.StringFlag{
+							Name:  "metric, m",
+							Usage: "The metric evaluated by the threshold (e.x. ifHCInOctets)",
+						},
+						cli.StringFlag{
+							Name:  "type, t",
+							Value: model.ThresholdTypes.Default,
+							Usage: "The type of threshold: " + model.ThresholdTypes.EnumAsString(),
+						},
+						cli.StringFlag{
+							Name:  "resource-type, r",
+							Value: "node",
+							Usage: "The type of the resources with the metric: node, if, or the name of a generic resource type",
+						},
+						cli.Float64Flag{
+							Name:  "value, v",
+							Usage: "The value that triggers the threshold",
+						},
+						cli.Float64Flag{
+							Name:  "rearm",
+							Usage: "The value that rearms the threshold",
+						},
+						cli.IntFlag{
+							Name:  "trigger",
+							Value: 1,
+							Usage: "The amount of consecutive times the value must be exceeded to trigger the threshold",
+						},
+						cli.StringFlag{
+							Name:  "label, l",
+							Usage: "The metric used to label the resource on the events (e.x. ifName)",
+						},
+						cli.StringFlag{
+							Name:  "description, d",
+							Usage: "A description for the threshold",
+						},
+						cli.StringFlag{
+							Name:  "triggered-uei",
+							Usage: "A custom UEI for the event sent when the threshold is triggered",
+						},
+						cli.StringFlag{
+							Name:  "rearmed-uei",
+							Usage: "A custom UEI for the event sent when the threshold is rearmed",
+						},
+						reloadFlag,
+					},
+				},
+			},
+		},
+	},
+}
+
+func listGroups(c *cli.Context) error {
+	list, err := getAPI(c).GetGroups()
+	if err != nil {
+		return checkEndpoint(err)
+	}
+	table := common.NewTable("There are no threshold groups", "GROUP", "THRESHOLDS", "EXPRESSIONS", "RRD REPOSITORY")
+	for _, g := range list.Groups {
+		table.AddRow(g.Name, len(g.Thresholds), len(g.Expressions), g.RRDRepository)
+	}
+	return common.Print(list.Groups, table)
+}
+
+func showGroup(c *cli.Context) error {
+	group, err := getGroup(c, c.Args().First())
+	if err != nil {
+		return err
+	}
+	return common.Print(group, nil)
+}
+
+func applyGroup(c *cli.Context) error {
+	data, err := common.ReadInput(c, 0)
+	if err != nil {
+		return err
+	}
+	group := &model.ThresholdGroup{}
+	return common.ApplyYAML(data, group, func() error {
+		if err := getAPI(c).SetGroup(*group); err != nil {
+			return checkEndpoint(err)
+		}
+		common.Log.Infof("Threshold group %s has been updated", group.Name)
+		return reload(c)
+	})
+}
+
+func addThreshold(c *cli.Context) error {
+	threshold := model.Threshold{
+		DsName: c.String("metric"),
+		ThresholdSettings: model.ThresholdSettings{
+			Type:         c.String("type"),
+			DsType:       c.String("resource-type"),
+			Value:        c.Float64("value"),
+			Rearm:        c.Float64("rearm"),
+			Trigger:      c.Int("trigger"),
+			DsLabel:      c.String("label"),
+			Description:  c.String("description"),
+			TriggeredUEI: c.String("triggered-uei"),
+			RearmedUEI:   c.String("rearmed-uei"),
+		},
+	}
+	if err := threshold.Validate(); err != nil {
+		return common.ValidationError(err)
+	}
+	group, err := getGroup(c, c.String("group"))
+	if err != nil {
+		return err
+	}
+	replaced := group.SetThreshold(threshold)
+	return common.Apply(group, func() error {
+		if err := getAPI(c).SetGroup(*group); err != nil {
+			return checkEndpoint(err)
+		}
+		if replaced {
+			common.Log.Infof("The %s threshold for %s on group %s has been replaced", threshold.Type, threshold.DsName, group.Name)
+		} else {
+			common.Log.Infof("The %s threshold for %s has been added to group %s", threshold.Type, threshold.DsName, group.Name)
+		}
+		return reload(c)
+	})
+}
+
+// Gets a group, reporting when it doesn't exist
+func getGroup(c *cli.Context, name string) (*model.ThresholdGroup, error) {
+	group, err := getAPI(c).GetGroup(name)
+	var e *rest.APIError
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("Threshold group %s doesn't exist", name)
+	}
+	return group, err
+}
+
+// Translates a missing endpoint into the error reported for old servers, as the version check accepts
+// builds that report a newer version without including the endpoint
+func checkEndpoint(err error) error {
+	var e *rest.APIError
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s requires OpenNMS >= %s, and the server doesn't provide the thresholds endpoint", thresholdsFeature, minThresholdsVersion)
+	}
+	return err
+}
+
+// Requests Threshd and Collectd to reload their configuration when the reload flag is present
+func reload(c *cli.Context) error {
+	if !c.Bool("reload") {
+		return nil
+	}
+	for _, name := range []string{"threshd", "collectd"} {
+		if err := services.GetEventsAPI(common.GetClient(c)).SendEvent(daemon.ReloadEvent(name, "")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getAPI(c *cli.Context) api.ThresholdsAPI {
+	return services.GetThresholdsAPI(common.GetClient(c))
+}
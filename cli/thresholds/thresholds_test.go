@@ -0,0 +1,153 @@
+package thresholds
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/OpenNMS/onmsctl/test"
+	"github.com/urfave/cli"
+	"gotest.tools/assert"
+)
+
+const mib2Group = `{"name": "mib2", "rrdRepository": "/opt/opennms/share/rrd/snmp/", "threshold": [
+  {"ds-name": "ifHCInOctets", "type": "high", "ds-type": "if", "value": 1e9, "rearm": 8e8, "trigger": 3, "ds-label": "ifName"}
+]}`
+
+type mockServer struct {
+	*httptest.Server
+	groups map[string]model.ThresholdGroup
+	events []model.Event
+}
+
+func createMockServer(t *testing.T, endpoint bool) *mockServer {
+	mock := &mockServer{groups: make(map[string]model.ThresholdGroup)}
+	mock.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		request := req.Method + " " + req.URL.Path
+		if !endpoint && request != "POST /rest/events" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch request {
+		case "GET /rest/thresholds":
+			res.Write([]byte(`{"count": 1, "group": [` + mib2Group + `]}`))
+		case "GET /rest/thresholds/mib2":
+			res.Write([]byte(mib2Group))
+		case "PUT /rest/thresholds/mib2", "PUT /rest/thresholds/hrstorage":
+			group := model.ThresholdGroup{}
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			assert.NilError(t, json.Unmarshal(bytes, &group))
+			mock.groups[group.Name] = group
+			res.WriteHeader(http.StatusNoContent)
+		case "POST /rest/events":
+			event := model.Event{}
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			json.Unmarshal(bytes, &event)
+			mock.events = append(mock.events, event)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return mock
+}
+
+// Creates the CLI with a client for the mock server, which runs a version with the thresholds endpoint
+func createCli(server *mockServer) *cli.App {
+	app := test.CreateCli(CliCommand, rest.WithURL(server.URL))
+	test.Client(app).ServerVersion = &rest.Version{Major: 32}
+	return app
+}
+
+func TestListThresholdGroups(t *testing.T) {
+	server := createMockServer(t, true)
+	defer server.Close()
+	app := createCli(server)
+
+	output, err := test.RunWithOutput(app, "table", "thresholds", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `GROUP  THRESHOLDS  EXPRESSIONS  RRD REPOSITORY
+mib2   1           0            /opt/opennms/share/rrd/snmp/
+`, output)
+
+	output, err = test.RunWithOutput(app, "jsonpath=$.threshold[0].ds-label", "thresholds", "get", "mib2")
+	assert.NilError(t, err)
+	assert.Equal(t, "ifName\n", output)
+
+	_, err = test.RunWithOutput(app, "yaml", "thresholds", "get", "cpu")
+	assert.Error(t, err, "Threshold group cpu doesn't exist")
+	_, err = test.RunWithOutput(app, "yaml", "thresholds", "get")
+	assert.Error(t, err, "Threshold group name required")
+}
+
+func TestApplyThresholdGroup(t *testing.T) {
+	server := createMockServer(t, true)
+	defer server.Close()
+	app := createCli(server)
+
+	err := app.Run([]string{app.Name, "thresholds", "apply", "name: hrstorage\nthresholds:\n- metric: hrStorageUsed\n  dsType: hrStorageIndex\n  value: 90\n  rearm: 95\n  trigger: 2"})
+	assert.ErrorContains(t, err, "The rearm value (95) of a high threshold must be below its value (90)")
+	assert.Equal(t, 0, len(server.groups))
+
+	err = app.Run([]string{app.Name, "thresholds", "apply", "--reload", "name: hrstorage\nthresholds:\n- metric: hrStorageUsed\n  dsType: hrStorageIndex\n  value: 90\n  rearm: 85\n  trigger: 2"})
+	assert.NilError(t, err)
+	group := server.groups["hrstorage"]
+	assert.Equal(t, model.ThresholdHigh, group.Thresholds[0].Type)
+	assert.Equal(t, 2, len(server.events))
+	assert.Equal(t, "Threshd", server.events[0].Parameters[0].Value)
+	assert.Equal(t, "Collectd", server.events[1].Parameters[0].Value)
+}
+
+func TestAddThreshold(t *testing.T) {
+	server := createMockServer(t, true)
+	defer server.Close()
+	app := createCli(server)
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	defer func() { common.Log.Output = os.Stderr }()
+
+	err := app.Run([]string{app.Name, "thresholds", "threshold", "add", "--group", "mib2", "--metric", "ifHCOutOctets", "--resource-type", "if", "--value", "1e9", "--rearm", "8e8", "--trigger", "0"})
+	assert.ErrorContains(t, err, "Trigger must be at least 1, got 0")
+	err = app.Run([]string{app.Name, "thresholds", "threshold", "add", "--group", "cpu", "--metric", "cpuPercentBusy", "--value", "90", "--rearm", "80"})
+	assert.Error(t, err, "Threshold group cpu doesn't exist")
+	assert.Equal(t, 0, len(server.groups))
+
+	err = app.Run([]string{app.Name, "thresholds", "threshold", "add", "--group", "mib2", "--metric", "ifHCOutOctets", "--resource-type", "if", "--type", "high", "--value", "1e9", "--rearm", "8e8", "--trigger", "3"})
+	assert.NilError(t, err)
+	group := server.groups["mib2"]
+	assert.Equal(t, 2, len(group.Thresholds))
+	assert.Equal(t, "ifHCOutOctets", group.Thresholds[1].DsName)
+	assert.Equal(t, float64(1e9), group.Thresholds[1].Value)
+	assert.Equal(t, 0, len(server.events))
+	assert.Equal(t, "The high threshold for ifHCOutOctets has been added to group mib2\n", logs.String())
+	logs.Reset()
+
+	err = app.Run([]string{app.Name, "thresholds", "threshold", "add", "--reload", "-g", "mib2", "-m", "ifHCInOctets", "-r", "if", "-v", "2e9", "--rearm", "1.5e9", "--trigger", "2"})
+	assert.NilError(t, err)
+	group = server.groups["mib2"]
+	assert.Equal(t, 1, len(group.Thresholds))
+	assert.Equal(t, float64(2e9), group.Thresholds[0].Value)
+	assert.Equal(t, 2, len(server.events))
+	assert.Equal(t, "The high threshold for ifHCInOctets on group mib2 has been replaced\n", logs.String())
+}
+
+func TestThresholdsOnOldServers(t *testing.T) {
+	server := createMockServer(t, false)
+	defer server.Close()
+	app := createCli(server)
+
+	_, err := test.RunWithOutput(app, "table", "thresholds", "list")
+	assert.Error(t, err, "Managing thresholds through the ReST API requires OpenNMS >= 32.0.0, and the server doesn't provide the thresholds endpoint")
+
+	test.Client(app).ServerVersion = &rest.Version{Major: 31, Minor: 0, Patch: 4}
+	_, err = test.RunWithOutput(app, "table", "thresholds", "list")
+	assert.Error(t, err, "Managing thresholds through the ReST API requires OpenNMS >= 32.0.0, the server runs 31.0.4")
+}
@@ -0,0 +1,208 @@
+package users
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/services"
+	"github.com/urfave/cli"
+)
+
+// Where the password is read from when using --password-stdin
+var stdin io.Reader = os.Stdin
+
+// passwordFlags the flags to provide a password without echoing it
+var passwordFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "password",
+		Usage: "The password of the user (visible on the process list and the shell history; prefer --password-stdin)",
+	},
+	cli.BoolFlag{
+		Name:  "password-stdin",
+		Usage: "Read the password from STDIN",
+	},
+}
+
+// CliCommand the CLI command to manage users
+var CliCommand = cli.Command{
+	Name:  "users",
+	Usage: "Manage users",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List all users",
+			Action: listUsers,
+		},
+		{
+			Name:         "get",
+			Usage:        "Gets a user",
+			ArgsUsage:    "<userId>",
+			Action:       showUser,
+			BashComplete: userIDBashComplete,
+		},
+		{
+			Name:      "add",
+			Usage:     "Adds or updates a user",
+			ArgsUsage: "<userId>",
+			Action:    addUser,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "full-name, n",
+					Usage: "The full name of the user",
+				},
+				cli.StringFlag{
+					Name:  "email, e",
+					Usage: "The email address of the user",
+				},
+				cli.StringFlag{
+					Name:  "comments, c",
+					Usage: "Comments about the user",
+				},
+				cli.StringSliceFlag{
+					Name:  "role, r",
+					Usage: "A security role for the user (e.x. ROLE_REST); can be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "duty-schedule, s",
+					Usage: "A duty schedule for notifications (e.x. MoTuWeThFr800-1700); can be repeated",
+				},
+			}, passwordFlags...),
+		},
+		{
+			Name:         "delete",
+			ShortName:    "del",
+			Usage:        "Deletes a user",
+			ArgsUsage:    "<userId>",
+			Action:       deleteUser,
+			BashComplete: userIDBashComplete,
+		},
+		{
+			Name:         "set-password",
+			Usage:        "Changes the password of a user",
+			ArgsUsage:    "<userId>",
+			Action:       setPassword,
+			BashComplete: userIDBashComplete,
+			Flags:        passwordFlags,
+		},
+		{
+			Name:         "add-role",
+			Usage:        "Adds a security role to a user",
+			ArgsUsage:    "<userId> <role>",
+			Action:       addRole,
+			BashComplete: userIDBashComplete,
+		},
+		{
+			Name:         "remove-role",
+			Usage:        "Removes a security role from a user",
+			ArgsUsage:    "<userId> <role>",
+			Action:       removeRole,
+			BashComplete: userIDBashComplete,
+		},
+	},
+}
+
+func listUsers(c *cli.Context) error {
+	list, err := getAPI(c).GetUsers()
+	if err != nil {
+		return err
+	}
+	table := common.NewTable("There are no users", "User ID", "Full Name", "Email", "Roles")
+	for i := range list.Users {
+		user := &list.Users[i]
+		user.Password = "" // Never display password hashes
+		table.AddRow(user.ID, user.FullName, user.Email, strings.Join(user.Roles, ","))
+	}
+	return common.Print(list.Users, table)
+}
+
+func showUser(c *cli.Context) error {
+	user, err := getAPI(c).GetUser(c.Args().First())
+	if err != nil {
+		return err
+	}
+	user.Password = "" // Never display password hashes
+	user.PasswordSalt = false
+	return common.Print(user, nil)
+}
+
+func addUser(c *cli.Context) error {
+	password, err := getPassword(c)
+	if err != nil {
+		return err
+	}
+	user := model.OnmsUser{
+		ID:            c.Args().First(),
+		FullName:      c.String("full-name"),
+		Email:         c.String("email"),
+		Comments:      c.String("comments"),
+		Roles:         c.StringSlice("role"),
+		DutySchedules: c.StringSlice("duty-schedule"),
+		Password:      password,
+	}
+	return getAPI(c).AddUser(user)
+}
+
+func deleteUser(c *cli.Context) error {
+	return getAPI(c).DeleteUser(c.Args().First())
+}
+
+func setPassword(c *cli.Context) error {
+	id := c.Args().First()
+	if id == "" {
+		return fmt.Errorf("User ID required")
+	}
+	password, err := getPassword(c)
+	if err != nil {
+		return err
+	}
+	return getAPI(c).SetPassword(id, password)
+}
+
+func addRole(c *cli.Context) error {
+	return getAPI(c).AddRole(c.Args().Get(0), c.Args().Get(1))
+}
+
+func removeRole(c *cli.Context) error {
+	return getAPI(c).RemoveRole(c.Args().Get(0), c.Args().Get(1))
+}
+
+// Obtains the password from either --password or --password-stdin, ignoring the trailing line break
+func getPassword(c *cli.Context) (string, error) {
+	if c.IsSet("password") && c.Bool("password-stdin") {
+		return "", fmt.Errorf("Use either --password or --password-stdin")
+	}
+	if c.Bool("password-stdin") {
+		data, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("Cannot read password from STDIN: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if c.String("password") == "" {
+		return "", fmt.Errorf("Password required, use --password or --password-stdin")
+	}
+	return c.String("password"), nil
+}
+
+func userIDBashComplete(c *cli.Context) {
+	if c.NArg() > 0 {
+		return
+	}
+	list, err := getAPI(c).GetUsers()
+	if err != nil {
+		return
+	}
+	for _, user := range list.Users {
+		fmt.Println(user.ID)
+	}
+}
+
+func getAPI(c *cli.Context) api.UsersAPI {
+	return services.GetUsersAPI(common.GetClient(c))
+}
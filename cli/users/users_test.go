@@ -0,0 +1,150 @@
+package users
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/test"
+	"gotest.tools/assert"
+)
+
+// A mock server that keeps the users in memory, to verify the full CRUD cycle
+func createMockServer(t *testing.T, users map[string]*model.OnmsUser, passwords map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		path := strings.TrimPrefix(req.URL.Path, "/rest/users")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		switch {
+		case req.Method == http.MethodGet && path == "":
+			assert.Equal(t, "0", req.URL.Query().Get("limit"))
+			list := model.OnmsUserList{}
+			ids := make([]string, 0, len(users))
+			for id := range users {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				list.Users = append(list.Users, *users[id])
+			}
+			list.Count = len(list.Users)
+			list.TotalCount = list.Count
+			bytes, _ := json.Marshal(list)
+			res.Write(bytes)
+		case req.Method == http.MethodPost && path == "":
+			assert.Equal(t, "true", req.URL.Query().Get("hashPassword"))
+			bytes, err := ioutil.ReadAll(req.Body)
+			assert.NilError(t, err)
+			user := &model.OnmsUser{}
+			assert.NilError(t, json.Unmarshal(bytes, user))
+			passwords[user.ID] = user.Password
+			user.Password = "hashed"
+			user.PasswordSalt = true
+			users[user.ID] = user
+			res.WriteHeader(http.StatusOK)
+		case users[parts[0]] == nil:
+			res.WriteHeader(http.StatusNotFound)
+		case req.Method == http.MethodGet && len(parts) == 1:
+			bytes, _ := json.Marshal(users[parts[0]])
+			res.Write(bytes)
+		case req.Method == http.MethodPut && len(parts) == 1:
+			assert.Equal(t, "true", req.URL.Query().Get("hashPassword"))
+			assert.NilError(t, req.ParseForm())
+			passwords[parts[0]] = req.PostForm.Get("password")
+			res.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodDelete && len(parts) == 1:
+			delete(users, parts[0])
+			res.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodPut && len(parts) == 3 && parts[1] == "roles":
+			users[parts[0]].Roles = append(users[parts[0]].Roles, parts[2])
+			res.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodDelete && len(parts) == 3 && parts[1] == "roles":
+			user := users[parts[0]]
+			roles := make([]string, 0)
+			for _, role := range user.Roles {
+				if role != parts[2] {
+					roles = append(roles, role)
+				}
+			}
+			user.Roles = roles
+			res.WriteHeader(http.StatusNoContent)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestUsersCrud(t *testing.T) {
+	users := map[string]*model.OnmsUser{}
+	passwords := map[string]string{}
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, users, passwords)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "users", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, "There are no users\n", output)
+
+	_, err = test.RunWithOutput(app, "table", "users", "add", "--full-name", "John Doe", "jdoe")
+	assert.Error(t, err, "Password required, use --password or --password-stdin")
+
+	_, err = test.RunWithOutput(app, "table", "users", "add", "--password", "s3cr3t", "j/doe")
+	assert.ErrorContains(t, err, "cannot contain spaces")
+
+	_, err = test.RunWithOutput(app, "table", "users", "add", "--full-name", "John Doe", "--email", "jdoe@example.com", "--role", "ROLE_USER", "--duty-schedule", "MoTuWeThFr800-1700", "--password", "s3cr3t", "jdoe")
+	assert.NilError(t, err)
+	assert.Equal(t, "s3cr3t", passwords["jdoe"])
+
+	output, err = test.RunWithOutput(app, "table", "users", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, `User ID  Full Name  Email             Roles
+jdoe     John Doe   jdoe@example.com  ROLE_USER
+`, output)
+
+	output, err = test.RunWithOutput(app, "json", "users", "get", "jdoe")
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(output, "hashed"))
+	assert.Assert(t, !strings.Contains(output, "passwordSalt"))
+
+	assert.NilError(t, app.Run([]string{app.Name, "users", "add-role", "jdoe", "ROLE_ADMIN"}))
+	assert.DeepEqual(t, []string{"ROLE_USER", "ROLE_ADMIN"}, users["jdoe"].Roles)
+	assert.NilError(t, app.Run([]string{app.Name, "users", "remove-role", "jdoe", "ROLE_USER"}))
+	assert.DeepEqual(t, []string{"ROLE_ADMIN"}, users["jdoe"].Roles)
+	assert.Error(t, app.Run([]string{app.Name, "users", "add-role", "jdoe", "ADMIN"}), "Invalid role ADMIN, roles must start with ROLE_ (e.x. ROLE_REST)")
+
+	assert.NilError(t, app.Run([]string{app.Name, "users", "delete", "jdoe"}))
+	assert.Equal(t, 0, len(users))
+}
+
+func TestSetPassword(t *testing.T) {
+	users := map[string]*model.OnmsUser{"jdoe": {ID: "jdoe"}}
+	passwords := map[string]string{}
+	app := test.CreateCli(CliCommand)
+	server := createMockServer(t, users, passwords)
+	test.Client(app).URL = server.URL
+	defer server.Close()
+
+	output, err := test.RunWithOutput(app, "table", "users", "set-password", "jdoe")
+	assert.Error(t, err, "Password required, use --password or --password-stdin")
+	assert.Equal(t, "", output)
+
+	output, err = test.RunWithOutput(app, "table", "users", "set-password", "--password", "n3w&p4ss", "jdoe")
+	assert.NilError(t, err)
+	assert.Equal(t, "", output)
+	assert.Equal(t, "n3w&p4ss", passwords["jdoe"])
+
+	stdin = strings.NewReader("fr0m-std1n\n")
+	output, err = test.RunWithOutput(app, "table", "users", "set-password", "--password-stdin", "jdoe")
+	assert.NilError(t, err)
+	assert.Equal(t, "", output)
+	assert.Equal(t, "fr0m-std1n", passwords["jdoe"])
+
+	_, err = test.RunWithOutput(app, "table", "users", "set-password", "--password", "x", "--password-stdin", "jdoe")
+	assert.Error(t, err, "Use either --password or --password-stdin")
+}
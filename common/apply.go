@@ -0,0 +1,42 @@
+package common
+
+import "gopkg.in/yaml.v2"
+
+// ExitValidationError the exit status when the content to apply is invalid
+const ExitValidationError = 2
+
+// DryRun when enabled, apply commands validate and print the content instead of sending it to the server
+var DryRun = false
+
+// Validatable an object that can verify its own content
+type Validatable interface {
+	Validate() error
+}
+
+// ValidationError wraps a validation problem, so the CLI exits with ExitValidationError instead of the generic failure status
+func ValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ExitError{Message: err.Error(), Code: ExitValidationError}
+}
+
+// ApplyYAML parses the YAML content into the target object and applies it
+func ApplyYAML(data []byte, target Validatable, send func() error) error {
+	if err := yaml.Unmarshal(data, target); err != nil {
+		return err
+	}
+	return Apply(target, send)
+}
+
+// Apply validates the target object and calls the send function;
+// in dry-run mode, the normalized object is printed instead (as YAML, unless another output format was chosen), without contacting the server
+func Apply(target Validatable, send func() error) error {
+	if err := target.Validate(); err != nil {
+		return ValidationError(err)
+	}
+	if DryRun {
+		return Print(target, nil)
+	}
+	return send()
+}
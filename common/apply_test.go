@@ -0,0 +1,59 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type testObject struct {
+	Name string `yaml:"name"`
+}
+
+func (o *testObject) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+func TestApplyYAML(t *testing.T) {
+	var sent bool
+	send := func() error {
+		sent = true
+		return nil
+	}
+
+	err := ApplyYAML([]byte("name: [broken"), &testObject{}, send)
+	assert.Assert(t, err != nil)
+	_, isExit := err.(ExitError)
+	assert.Assert(t, !isExit)
+	assert.Assert(t, !sent)
+
+	err = ApplyYAML([]byte("name: ''"), &testObject{}, send)
+	exitErr, isExit := err.(ExitError)
+	assert.Assert(t, isExit)
+	assert.Equal(t, ExitValidationError, exitErr.ExitStatus())
+	assert.Assert(t, !sent)
+
+	obj := &testObject{}
+	assert.NilError(t, ApplyYAML([]byte("name: test"), obj, send))
+	assert.Equal(t, "test", obj.Name)
+	assert.Assert(t, sent)
+}
+
+func TestApplyDryRun(t *testing.T) {
+	DryRun = true
+	defer func() { DryRun = false }()
+
+	err := Apply(&testObject{Name: "test"}, func() error {
+		return fmt.Errorf("should not be called")
+	})
+	assert.NilError(t, err)
+
+	err = Apply(&testObject{}, func() error {
+		return fmt.Errorf("should not be called")
+	})
+	assert.Equal(t, ExitValidationError, err.(ExitError).ExitStatus())
+}
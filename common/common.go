@@ -1,9 +1,15 @@
 package common
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/OpenNMS/onmsctl/rest"
@@ -14,17 +20,81 @@ import (
 // TableWriterOutput the default output for table writers
 var TableWriterOutput = os.Stdout
 
-// Reads YAML configuration from file and place it on a target object
-func init() {
-	configFile := getConfigFile()
-	if fileExists(configFile) {
-		data, _ := ioutil.ReadFile(configFile)
-		err := yaml.Unmarshal(data, &rest.Instance)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "ERROR: cannot read configuration file %s; %s\n", configFile, err)
-			os.Exit(1)
-		}
+// The key of the ReST client on the metadata of the application
+const clientKey = "client"
+
+// LoadConfig reads the configuration file to build the ReST client;
+// unknown keys are reported as a warning and ignored, so 'config validate' can be used to find them
+func LoadConfig() (*rest.Config, error) {
+	cfg, err := ReadConfig()
+	if err != nil {
+		return readConfigLenient(err)
+	}
+	return cfg, nil
+}
+
+// SetClient sets the ReST client used by the commands of the application (built before running them)
+func SetClient(app *cli.App, client *rest.Client) {
+	if app.Metadata == nil {
+		app.Metadata = make(map[string]interface{})
+	}
+	app.Metadata[clientKey] = client
+}
+
+// GetClient returns the ReST client of the application; when none was set, one with the built-in settings is used
+func GetClient(c *cli.Context) *rest.Client {
+	if client, ok := c.App.Metadata[clientKey].(*rest.Client); ok {
+		return client
+	}
+	client, _ := rest.NewClient()
+	SetClient(c.App, client)
+	return client
+}
+
+// ReadConfig reads the content of the configuration file (if it exists)
+func ReadConfig() (*rest.Config, error) {
+	cfg := &rest.Config{}
+	configFile := ConfigFile()
+	if !fileExists(configFile) {
+		return cfg, nil
 	}
+	data, err := ioutil.ReadFile(configFile)
+	if err == nil {
+		cfg, err = rest.ParseConfig(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read configuration file %s; %w", configFile, err)
+	}
+	return cfg, nil
+}
+
+// Reads the configuration file ignoring unknown keys, warning about the error of the strict decoder
+func readConfigLenient(strictErr error) (*rest.Config, error) {
+	data, err := ioutil.ReadFile(ConfigFile())
+	if err != nil {
+		return nil, strictErr
+	}
+	cfg := &rest.Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, strictErr
+	}
+	if !IsCompleting(os.Args) {
+		Log.Warnf("%s; run 'onmsctl config validate' for details", strictErr)
+	}
+	return cfg, nil
+}
+
+// SaveConfig writes the configuration file, readable only by its owner as it contains credentials
+func SaveConfig(cfg rest.Config) error {
+	configFile := ConfigFile()
+	if err := os.MkdirAll(filepath.Dir(configFile), 0700); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(configFile, data, 0600)
 }
 
 // NewTableWriter creates a new table writer
@@ -32,7 +102,20 @@ func NewTableWriter() *tabwriter.Writer {
 	return tabwriter.NewWriter(TableWriterOutput, 0, 8, 1, '\t', tabwriter.AlignRight)
 }
 
-// ReadInput reads data from a file specified on the CLI context
+// Sha256Flag the flag to verify the checksum of the content read through ReadInput
+var Sha256Flag = cli.StringFlag{
+	Name:  "sha256",
+	Usage: "The expected SHA-256 checksum of the content (as hex), verified before parsing it",
+}
+
+// AllPagesFlag the flag of the list commands to request all the pages, instead of the one chosen through --limit and --offset
+var AllPagesFlag = cli.BoolFlag{
+	Name:  "all, A",
+	Usage: "Show all the matching elements, requested in pages, ignoring --limit and --offset",
+}
+
+// ReadInput reads data from a file, an HTTP(S) URL or STDIN specified on the CLI context, or from an argument;
+// when the sha256 flag is used, the checksum of the content is verified
 func ReadInput(c *cli.Context, dataIndex int) ([]byte, error) {
 	var data []byte
 	ymlFile := c.String("file")
@@ -51,6 +134,11 @@ func ReadInput(c *cli.Context, dataIndex int) ([]byte, error) {
 			return nil, fmt.Errorf("There is no YAML content on STDIN pipe")
 		}
 		data, _ = ioutil.ReadAll(os.Stdin)
+	} else if rest.IsURL(ymlFile) {
+		var err error
+		if data, err = GetClient(c).Download(ymlFile); err != nil {
+			return nil, err
+		}
 	} else {
 		if fileExists(ymlFile) {
 			data, _ = ioutil.ReadFile(ymlFile)
@@ -58,9 +146,60 @@ func ReadInput(c *cli.Context, dataIndex int) ([]byte, error) {
 			return nil, fmt.Errorf("YAML file %s doesn't exist", ymlFile)
 		}
 	}
+	if err := VerifyChecksum(data, c.String("sha256")); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
+// VerifyChecksum returns an error when the SHA-256 checksum of the data doesn't match the expected one (nothing is verified when empty)
+func VerifyChecksum(data []byte, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("Checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// YAMLDocument a document of a YAML stream, decoded later into the value chosen by the caller
+type YAMLDocument struct {
+	unmarshal func(interface{}) error
+}
+
+// UnmarshalYAML keeps the document to decode it through Decode; it is not called for empty documents
+func (d *YAMLDocument) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	d.unmarshal = unmarshal
+	return nil
+}
+
+// Decode decodes the document into the target
+func (d YAMLDocument) Decode(target interface{}) error {
+	return d.unmarshal(target)
+}
+
+// SplitYAMLDocuments returns the documents of a YAML stream separated by "---", skipping the empty ones
+func SplitYAMLDocuments(data []byte) ([]YAMLDocument, error) {
+	documents := make([]YAMLDocument, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := YAMLDocument{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Document %d: %w", len(documents)+1, err)
+		}
+		if doc.unmarshal != nil {
+			documents = append(documents, doc)
+		}
+	}
+}
+
 func fileExists(filename string) bool {
 	_, err := os.Stat(filename)
 	if err != nil {
@@ -78,7 +217,7 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
-func getConfigFile() string {
+func ConfigFile() string {
 	homeDir, _ := os.UserHomeDir()
 	configFile := homeDir + string(os.PathSeparator) + ".onms" + string(os.PathSeparator) + "config.yaml"
 	return getEnv("ONMSCONFIG", configFile)
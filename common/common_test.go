@@ -1,7 +1,13 @@
 package common
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"gotest.tools/assert"
@@ -11,13 +17,78 @@ func TestGetConfigFile(t *testing.T) {
 	var file string
 
 	os.Setenv("HOME", "/home/agalue")
-	file = getConfigFile()
+	file = ConfigFile()
 	assert.Equal(t, "/home/agalue/.onms/config.yaml", file)
 
 	os.Setenv("ONMSCONFIG", "/opt/opennms/etc/onmsctl.yaml")
-	file = getConfigFile()
+	file = ConfigFile()
 	assert.Equal(t, "/opt/opennms/etc/onmsctl.yaml", file)
 
 	assert.Equal(t, true, fileExists("/etc/hosts"))
 	assert.Equal(t, false, fileExists("/_unknown"))
 }
+
+func TestReadConfigUnknownKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yaml")
+	os.Setenv("ONMSCONFIG", file)
+	defer os.Unsetenv("ONMSCONFIG")
+	var warnings bytes.Buffer
+	Log.Output = &warnings
+	defer func() { Log.Output = os.Stderr }()
+
+	assert.NilError(t, ioutil.WriteFile(file, []byte("url: http://onms:8980/opennms\nusername: demo\nprofiles:\n  lab:\n    urll: http://lab:8980/opennms\n"), 0600))
+	_, err = ReadConfig()
+	assert.ErrorContains(t, err, "cannot read configuration file "+file)
+	assert.ErrorContains(t, err, "field urll not found")
+
+	// The commands can still run, ignoring the unknown keys
+	cfg, err := LoadConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, "http://onms:8980/opennms", cfg.URL)
+	assert.Equal(t, "demo", cfg.Username)
+	assert.Assert(t, strings.HasPrefix(warnings.String(), "WARNING: cannot read configuration file"))
+	assert.Assert(t, strings.HasSuffix(warnings.String(), "run 'onmsctl config validate' for details\n"))
+
+	assert.NilError(t, ioutil.WriteFile(file, []byte("url: [http://onms:8980/opennms]\n"), 0600))
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "cannot read configuration file")
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("name: SiteA\n")
+	assert.NilError(t, VerifyChecksum(data, ""))
+	sum := sha256.Sum256(data)
+	expected := hex.EncodeToString(sum[:])
+	assert.NilError(t, VerifyChecksum(data, expected))
+	assert.NilError(t, VerifyChecksum(data, strings.ToUpper(expected)))
+	assert.Error(t, VerifyChecksum([]byte("name: SiteB\n"), expected), "Checksum mismatch: expected "+expected+", got 68686743fedb7f02dec7a156d7a2c178c97ada49c90b030c4ec1e3aba07980a3")
+}
+
+func TestSplitYAMLDocuments(t *testing.T) {
+	type document struct {
+		Name  string `yaml:"name"`
+		Flags []string
+	}
+	docs, err := SplitYAMLDocuments([]byte("# Header\n---\nname: A\n--- # second\nname: B\nflags: [N, 'y', on]\n...\n---\n\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(docs))
+	var doc document
+	assert.NilError(t, docs[0].Decode(&doc))
+	assert.DeepEqual(t, document{Name: "A"}, doc)
+	doc = document{}
+	assert.NilError(t, docs[1].Decode(&doc))
+	assert.DeepEqual(t, document{Name: "B", Flags: []string{"N", "y", "on"}}, doc)
+
+	docs, err = SplitYAMLDocuments([]byte("name: A\nnodes:\n- foreignID: '---'\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(docs))
+	docs, err = SplitYAMLDocuments([]byte("---\n# Nothing\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(docs))
+
+	_, err = SplitYAMLDocuments([]byte("name: A\n---\nname: [B\n"))
+	assert.ErrorContains(t, err, "Document 2: ")
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/urfave/cli"
+)
+
+// completionFlag the flag appended by the shell completion scripts to request suggestions
+const completionFlag = "--generate-bash-completion"
+
+// CompletionTimeout the timeout in seconds for the requests that obtain suggestions, as completion must be fast
+const CompletionTimeout = 2
+
+// CompletionTTL how long the suggestions obtained from the server are cached
+var CompletionTTL = 30 * time.Second
+
+// CompletionCacheDir where the suggestions are cached; empty means the user's cache directory
+var CompletionCacheDir = ""
+
+// IsCompleting returns true when the CLI was invoked by a shell to obtain suggestions
+func IsCompleting(args []string) bool {
+	return len(args) > 0 && args[len(args)-1] == completionFlag
+}
+
+// CompletingFlag returns true when the suggestions are requested for the value of one of the given flags (e.x. --severity <TAB>)
+func CompletingFlag(args []string, names ...string) bool {
+	if !IsCompleting(args) || len(args) < 2 {
+		return false
+	}
+	previous := args[len(args)-2]
+	for _, name := range names {
+		if previous == "-"+name || previous == "--"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintCompletions writes the suggestions, one per line
+func PrintCompletions(values ...string) {
+	for _, v := range values {
+		fmt.Fprintln(Output, v)
+	}
+}
+
+// FlagValuesBashComplete suggests the values when completing any of the given flags (e.x. the options of an enum)
+func FlagValuesBashComplete(values []string, flags ...string) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		if CompletingFlag(os.Args, flags...) {
+			PrintCompletions(values...)
+		}
+	}
+}
+
+// CachedCompletions returns the suggestions for the key from the cache of the current server, or from fetch when expired;
+// errors are ignored, as completion must not print anything unexpected when the server is unreachable
+func CachedCompletions(c *cli.Context, key string, fetch func() ([]string, error)) []string {
+	file := completionCacheFile(GetClient(c), key)
+	if file != "" {
+		if info, err := os.Stat(file); err == nil && time.Since(info.ModTime()) < CompletionTTL {
+			if data, err := ioutil.ReadFile(file); err == nil {
+				return splitLines(string(data))
+			}
+		}
+	}
+	values, err := fetch()
+	if err != nil {
+		return nil
+	}
+	if file != "" && os.MkdirAll(filepath.Dir(file), 0700) == nil {
+		ioutil.WriteFile(file, []byte(strings.Join(values, "\n")), 0600)
+	}
+	return values
+}
+
+func completionCacheFile(client *rest.Client, key string) string {
+	dir := CompletionCacheDir
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(cache, "onmsctl", "completion")
+	}
+	hash := sha1.Sum([]byte(client.URL + "|" + client.Username + "|" + key))
+	return filepath.Join(dir, hex.EncodeToString(hash[:]))
+}
+
+func splitLines(data string) []string {
+	values := make([]string, 0)
+	for _, line := range strings.Split(data, "\n") {
+		if line != "" {
+			values = append(values, line)
+		}
+	}
+	return values
+}
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli"
+	"gotest.tools/assert"
+)
+
+func TestIsCompleting(t *testing.T) {
+	assert.Assert(t, IsCompleting([]string{"onmsctl", "inv", "req", "get", "--generate-bash-completion"}))
+	assert.Assert(t, !IsCompleting([]string{"onmsctl", "inv", "req", "get"}))
+	assert.Assert(t, CompletingFlag([]string{"onmsctl", "alarms", "list", "-s", "--generate-bash-completion"}, "severity", "s"))
+	assert.Assert(t, CompletingFlag([]string{"onmsctl", "alarms", "list", "--severity", "--generate-bash-completion"}, "severity", "s"))
+	assert.Assert(t, !CompletingFlag([]string{"onmsctl", "alarms", "list", "--severity"}, "severity", "s"))
+	assert.Assert(t, !CompletingFlag([]string{"onmsctl", "alarms", "list", "--generate-bash-completion"}, "severity", "s"))
+}
+
+func TestCachedCompletions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	CompletionCacheDir = dir
+	defer func() { CompletionCacheDir = "" }()
+
+	c := cli.NewContext(cli.NewApp(), nil, nil)
+	calls := 0
+	fetch := func() ([]string, error) {
+		calls++
+		return []string{"Test", "Local Servers"}, nil
+	}
+	assert.DeepEqual(t, []string{"Test", "Local Servers"}, CachedCompletions(c, "requisitions", fetch))
+	assert.DeepEqual(t, []string{"Test", "Local Servers"}, CachedCompletions(c, "requisitions", fetch))
+	assert.Equal(t, 1, calls)
+
+	CompletionTTL = 0
+	defer func() { CompletionTTL = 30 * time.Second }()
+	CachedCompletions(c, "requisitions", fetch)
+	assert.Equal(t, 2, calls)
+
+	values := CachedCompletions(c, "failure", func() ([]string, error) {
+		return nil, fmt.Errorf("connection refused")
+	})
+	assert.Equal(t, 0, len(values))
+}
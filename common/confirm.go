@@ -0,0 +1,120 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// AssumeYes when enabled, destructive operations run without asking for confirmation (global --yes flag)
+var AssumeYes = false
+
+// ConfirmInput where the answers to the confirmation prompts are read from;
+// when not set, STDIN is used, but only when it is a terminal
+var ConfirmInput io.Reader
+
+// ConfirmOutput where the confirmation prompts are written
+var ConfirmOutput io.Writer = os.Stderr
+
+// YesFlag the flag to skip the confirmation of destructive operations
+var YesFlag = cli.BoolFlag{
+	Name:  "yes, y",
+	Usage: "Skip the confirmation prompt (required when STDIN is not a terminal)",
+}
+
+// SkipConfirmation returns true when the global or the command's --yes flag was used
+func SkipConfirmation(c *cli.Context) bool {
+	return AssumeYes || c.Bool("yes") || c.GlobalBool("yes")
+}
+
+// Confirm displays what is about to change, and asks for a yes/no confirmation unless --yes was used
+func Confirm(c *cli.Context, description string) error {
+	if SkipConfirmation(c) {
+		return nil
+	}
+	fmt.Fprintln(ConfirmOutput, description)
+	answer, err := prompt("Do you want to continue? [y/N] ")
+	if err != nil {
+		return err
+	}
+	answer = strings.ToLower(answer)
+	if answer != "y" && answer != "yes" {
+		return fmt.Errorf("Operation cancelled")
+	}
+	return nil
+}
+
+// ConfirmName displays what is about to change, and requires typing the name of the object unless --yes was used
+func ConfirmName(c *cli.Context, description string, kind string, name string) error {
+	if SkipConfirmation(c) {
+		return nil
+	}
+	fmt.Fprintln(ConfirmOutput, description)
+	answer, err := prompt(fmt.Sprintf("Type the %s name to confirm: ", kind))
+	if err != nil {
+		return err
+	}
+	if answer != name {
+		return fmt.Errorf("Operation cancelled, %s doesn't match %s", answer, name)
+	}
+	return nil
+}
+
+// Writes the question and returns the answer without surrounding spaces
+func prompt(question string) (string, error) {
+	input := ConfirmInput
+	if input == nil {
+		if !isTerminal(os.Stdin) {
+			return "", fmt.Errorf("Confirmation required, use --yes when STDIN is not a terminal")
+		}
+		input = os.Stdin
+	}
+	fmt.Fprint(ConfirmOutput, question)
+	answer, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// PromptSecret asks for a secret (e.x. a password) without echoing it on terminals; when STDIN is not a terminal,
+// the first line is read without prompting, so the secret can be piped
+func PromptSecret(question string) (string, error) {
+	input := ConfirmInput
+	if input == nil {
+		input = os.Stdin
+		if isTerminal(os.Stdin) {
+			fmt.Fprint(ConfirmOutput, question)
+			defer fmt.Fprintln(ConfirmOutput)
+			if setEcho(false) == nil {
+				defer setEcho(true)
+			}
+		}
+	}
+	secret, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(secret, "\r\n"), nil
+}
+
+// Enables or disables the echo of the terminal attached to STDIN
+func setEcho(enabled bool) error {
+	mode := "-echo"
+	if enabled {
+		mode = "echo"
+	}
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
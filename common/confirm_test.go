@@ -0,0 +1,65 @@
+package common
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli"
+	"gotest.tools/assert"
+)
+
+func createContext(yes bool) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Bool("yes", yes, "")
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestConfirm(t *testing.T) {
+	var output bytes.Buffer
+	ConfirmOutput = &output
+	defer func() {
+		ConfirmOutput = os.Stderr
+		ConfirmInput = nil
+	}()
+
+	ConfirmInput = strings.NewReader("y\n")
+	assert.NilError(t, Confirm(createContext(false), "Node n1 will be deleted"))
+	assert.Equal(t, "Node n1 will be deleted\nDo you want to continue? [y/N] ", output.String())
+
+	ConfirmInput = strings.NewReader("no\n")
+	assert.Error(t, Confirm(createContext(false), "Node n1 will be deleted"), "Operation cancelled")
+
+	ConfirmInput = strings.NewReader("")
+	assert.Error(t, Confirm(createContext(false), "Node n1 will be deleted"), "Operation cancelled")
+
+	output.Reset()
+	ConfirmInput = strings.NewReader("n\n")
+	assert.NilError(t, Confirm(createContext(true), "Node n1 will be deleted"))
+	assert.Equal(t, "", output.String())
+
+	AssumeYes = true
+	assert.NilError(t, Confirm(createContext(false), "Node n1 will be deleted"))
+	AssumeYes = false
+}
+
+func TestConfirmName(t *testing.T) {
+	var output bytes.Buffer
+	ConfirmOutput = &output
+	defer func() {
+		ConfirmOutput = os.Stderr
+		ConfirmInput = nil
+	}()
+
+	ConfirmInput = strings.NewReader("Servers\n")
+	assert.NilError(t, ConfirmName(createContext(false), "Requisition Servers will be deleted", "requisition", "Servers"))
+	assert.Equal(t, "Requisition Servers will be deleted\nType the requisition name to confirm: ", output.String())
+
+	ConfirmInput = strings.NewReader("y\n")
+	assert.Error(t, ConfirmName(createContext(false), "Requisition Servers will be deleted", "requisition", "Servers"), "Operation cancelled, y doesn't match Servers")
+
+	ConfirmInput = strings.NewReader("")
+	assert.NilError(t, ConfirmName(createContext(true), "Requisition Servers will be deleted", "requisition", "Servers"))
+}
@@ -0,0 +1,350 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Decoder decodes the content received from the server; in strict mode, it fails when the content has fields unknown
+// to the target, or when a field tagged with required:"true" is empty. Strict mode detects the changes of the ReST API
+// after upgrading the server, instead of silently using zero values (set from --strict-decode or the strictDecode setting
+// of the profile, through the client).
+type Decoder struct {
+	Strict bool
+}
+
+// DecodeJSON unmarshals JSON into the target like json.Unmarshal, verifying it against the target in strict mode
+func (d Decoder) DecodeJSON(data []byte, target interface{}) error {
+	if !d.Strict {
+		return json.Unmarshal(data, target)
+	}
+	decoder := d.NewJSONDecoder(bytes.NewReader(data))
+	if err := d.CheckDecodedJSON(target, decoder.Decode(target)); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("invalid content after the JSON value")
+	}
+	return nil
+}
+
+// NewJSONDecoder returns a JSON decoder for streaming, which rejects the unknown fields in strict mode;
+// the result of each Decode must be verified with CheckDecodedJSON
+func (d Decoder) NewJSONDecoder(r io.Reader) *json.Decoder {
+	decoder := json.NewDecoder(r)
+	if d.Strict {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder
+}
+
+// CheckDecodedJSON verifies the result of decoding the target with a decoder from NewJSONDecoder,
+// returning the schema errors explained in strict mode
+func (d Decoder) CheckDecodedJSON(target interface{}, err error) error {
+	if err != nil {
+		if d.Strict && strings.HasPrefix(err.Error(), "json: unknown field") {
+			return strictDecodeError(target, err)
+		}
+		return err
+	}
+	if !d.Strict {
+		return nil
+	}
+	if err := CheckRequired(target, "json"); err != nil {
+		return strictDecodeError(target, err)
+	}
+	return nil
+}
+
+// DecodeXML unmarshals XML into the target like xml.Unmarshal, verifying it against the target in strict mode
+func (d Decoder) DecodeXML(data []byte, target interface{}) error {
+	if err := xml.Unmarshal(data, target); err != nil {
+		return err
+	}
+	if !d.Strict {
+		return nil
+	}
+	if err := checkUnknownXML(data, reflect.TypeOf(target)); err != nil {
+		return strictDecodeError(target, err)
+	}
+	if err := CheckRequired(target, "xml"); err != nil {
+		return strictDecodeError(target, err)
+	}
+	return nil
+}
+
+// CheckRequired returns an error naming the first field tagged with required:"true" that is empty, searching
+// through nested structs, pointers, slices and maps; the fields are named after the given tag (e.x. json)
+func CheckRequired(value interface{}, tag string) error {
+	return checkRequired(reflect.ValueOf(value), "", tag)
+}
+
+func strictDecodeError(target interface{}, err error) error {
+	t := reflect.TypeOf(target)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return fmt.Errorf("The response doesn't match %s (strict decode): %w", t, err)
+}
+
+func checkRequired(v reflect.Value, path string, tag string) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return checkRequired(v.Elem(), path, tag)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkRequired(v.Index(i), fmt.Sprintf("%s[%d]", path, i), tag); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			if err := checkRequired(v.MapIndex(k), fmt.Sprintf("%s[%v]", path, k), tag); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue // Unexported
+			}
+			fieldPath := path
+			if !f.Anonymous {
+				fieldPath = joinFieldPath(path, fieldName(f, tag))
+			}
+			if f.Tag.Get("required") == "true" && isEmptyField(v.Field(i)) {
+				return fmt.Errorf("required field %s is empty", fieldPath)
+			}
+			if err := checkRequired(v.Field(i), fieldPath, tag); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the name of a field on the given tag, or its Go name when the tag doesn't have one
+func fieldName(f reflect.StructField, tag string) string {
+	name := strings.Split(f.Tag.Get(tag), ",")[0]
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Returns true for the zero value of the type, and for empty slices and maps
+func isEmptyField(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Struct:
+		return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+	}
+	return false
+}
+
+var xmlUnmarshalerType = reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()
+
+// xmlField how a struct field is mapped to XML
+type xmlField struct {
+	path     []string // The element names, more than one for fields tagged as a>b
+	typ      reflect.Type
+	attr     bool
+	any      bool
+	innerXML bool
+	text     bool // chardata, cdata or comment
+}
+
+// xmlScope the struct whose fields can appear within the current element
+type xmlScope struct {
+	typ  reflect.Type
+	path []string // The elements already matched for fields tagged as a>b
+}
+
+// Verifies that all the elements and attributes of the XML content are mapped to fields of the type
+func checkUnknownXML(data []byte, t reflect.Type) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	stack := []*xmlScope{} // A nil scope accepts any content
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch e := token.(type) {
+		case xml.StartElement:
+			var scope *xmlScope
+			if len(stack) == 0 {
+				scope = newXMLScope(t)
+			} else if parent := stack[len(stack)-1]; parent != nil {
+				if scope, err = parent.child(e.Name.Local); err != nil {
+					return err
+				}
+			}
+			if scope != nil && len(scope.path) == 0 {
+				if err := scope.checkAttributes(e); err != nil {
+					return err
+				}
+			}
+			stack = append(stack, scope)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// Returns the scope of the struct behind the type, or nil when its content is not verified
+// (e.x. values, or types with their own XML unmarshalling)
+func newXMLScope(t reflect.Type) *xmlScope {
+	for {
+		if t.Implements(xmlUnmarshalerType) || reflect.PtrTo(t).Implements(xmlUnmarshalerType) {
+			return nil
+		}
+		if t.Kind() != reflect.Ptr && t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			break
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return &xmlScope{typ: t}
+}
+
+// Returns the scope of a child element, or an error when no field accepts it
+func (s *xmlScope) child(name string) (*xmlScope, error) {
+	anyElement := false
+	for _, f := range xmlFields(s.typ) {
+		if f.attr || f.text {
+			continue
+		}
+		if len(s.path) == 0 && (f.innerXML || f.any) {
+			anyElement = true
+			continue
+		}
+		if f.innerXML || f.any || len(f.path) <= len(s.path) || f.path[len(s.path)] != name || !hasPrefix(f.path, s.path) {
+			continue
+		}
+		if len(f.path) == len(s.path)+1 {
+			return newXMLScope(f.typ), nil
+		}
+		return &xmlScope{typ: s.typ, path: append(append([]string{}, s.path...), name)}, nil
+	}
+	if anyElement {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown element <%s> in %s", name, s.describe())
+}
+
+// Verifies the attributes of the element of the scope, ignoring the namespace declarations and qualified attributes
+func (s *xmlScope) checkAttributes(e xml.StartElement) error {
+	names := make(map[string]bool)
+	anyAttribute := false
+	for _, f := range xmlFields(s.typ) {
+		if f.attr && f.any {
+			anyAttribute = true
+		} else if f.attr {
+			names[f.path[0]] = true
+		}
+	}
+	for _, a := range e.Attr {
+		if a.Name.Space != "" || a.Name.Local == "xmlns" || anyAttribute || names[a.Name.Local] {
+			continue
+		}
+		return fmt.Errorf("unknown attribute %s of <%s> in %s", a.Name.Local, e.Name.Local, s.describe())
+	}
+	return nil
+}
+
+func (s *xmlScope) describe() string {
+	if len(s.path) == 0 {
+		return s.typ.Name()
+	}
+	return s.typ.Name() + " (" + strings.Join(s.path, ">") + ")"
+}
+
+// Returns how the fields of a struct are mapped to XML, including the ones of embedded structs
+func xmlFields(t reflect.Type) []xmlField {
+	fields := []xmlField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("xml")
+		if tag == "-" || f.Name == "XMLName" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		if f.Anonymous && options[0] == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, xmlFields(embedded)...)
+				continue
+			}
+		}
+		name := options[0]
+		if space := strings.LastIndex(name, " "); space >= 0 {
+			name = name[space+1:] // Without the namespace
+		}
+		if name == "" {
+			name = f.Name
+		}
+		field := xmlField{path: strings.Split(name, ">"), typ: f.Type}
+		for _, o := range options[1:] {
+			switch o {
+			case "attr":
+				field.attr = true
+			case "any":
+				field.any = true
+			case "innerxml":
+				field.innerXML = true
+			case "chardata", "cdata", "comment":
+				field.text = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func hasPrefix(path []string, prefix []string) bool {
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type testDecodeItem struct {
+	Name  string `json:"name" xml:"name,attr" required:"true"`
+	Value int    `json:"value,omitempty" xml:"value,omitempty"`
+}
+
+type testDecodeList struct {
+	Title string           `json:"title" xml:"title"`
+	Items []testDecodeItem `json:"item" xml:"items>item"`
+	Extra *testDecodeItem  `json:"extra,omitempty" xml:"extra,omitempty"`
+	Meta  map[string]testDecodeItem
+}
+
+func TestDecodeJSONNotStrict(t *testing.T) {
+	list := &testDecodeList{}
+	assert.NilError(t, Decoder{}.DecodeJSON([]byte(`{"title":"t","item":[{"value":1,"unit":"ms"}],"count":1}`), list))
+	assert.Equal(t, 1, list.Items[0].Value)
+	assert.Assert(t, Decoder{}.DecodeJSON([]byte(`{"title":`), list) != nil)
+}
+
+func TestDecodeJSONStrict(t *testing.T) {
+	strict := Decoder{Strict: true}
+
+	list := &testDecodeList{}
+	assert.NilError(t, strict.DecodeJSON([]byte(`{"title":"t","item":[{"name":"a","value":1}],"extra":{"name":"b"}}`), list))
+	assert.Equal(t, "b", list.Extra.Name)
+
+	err := strict.DecodeJSON([]byte(`{"title":"t","item":[{"name":"a","unit":"ms"}]}`), &testDecodeList{})
+	assert.Error(t, err, `The response doesn't match common.testDecodeList (strict decode): json: unknown field "unit"`)
+
+	err = strict.DecodeJSON([]byte(`{"title":"t","item":[{"name":"a"},{"value":2}]}`), &testDecodeList{})
+	assert.Error(t, err, "The response doesn't match common.testDecodeList (strict decode): required field item[1].name is empty")
+
+	err = strict.DecodeJSON([]byte(`{"Meta":{"x":{"value":2}}}`), &testDecodeList{})
+	assert.Error(t, err, "The response doesn't match common.testDecodeList (strict decode): required field Meta[x].name is empty")
+
+	// Syntax errors are not related to the schema
+	err = strict.DecodeJSON([]byte(`{"title":1}`), &testDecodeList{})
+	assert.ErrorContains(t, err, "json: cannot unmarshal number")
+	err = strict.DecodeJSON([]byte(`{"title":"t"} {}`), &testDecodeList{})
+	assert.Error(t, err, "invalid content after the JSON value")
+}
+
+func TestDecodeXMLStrict(t *testing.T) {
+	data := []byte(`<list xmlns="http://example.org" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="x">
+  <title>t</title>
+  <items><item name="a"><value>1</value></item><item name="b" unit="ms"/></items>
+</list>`)
+	list := &testDecodeList{}
+	assert.NilError(t, Decoder{}.DecodeXML(data, list))
+	assert.Equal(t, 2, len(list.Items))
+
+	strict := Decoder{Strict: true}
+	err := strict.DecodeXML(data, &testDecodeList{})
+	assert.Error(t, err, "The response doesn't match common.testDecodeList (strict decode): unknown attribute unit of <item> in testDecodeItem")
+
+	err = strict.DecodeXML([]byte(`<list><items><item name="a"/><entry/></items></list>`), &testDecodeList{})
+	assert.Error(t, err, "The response doesn't match common.testDecodeList (strict decode): unknown element <entry> in testDecodeList (items)")
+
+	err = strict.DecodeXML([]byte(`<list><items><item/></items></list>`), &testDecodeList{})
+	assert.Error(t, err, "The response doesn't match common.testDecodeList (strict decode): required field items>item[0].name is empty")
+
+	assert.NilError(t, strict.DecodeXML([]byte(`<list><title>t</title><extra name="e"><value>1</value></extra></list>`), &testDecodeList{}))
+}
+
+func TestDecodeRequisitionStrict(t *testing.T) {
+	strict := Decoder{Strict: true}
+
+	requisition := &model.Requisition{}
+	assert.NilError(t, strict.DecodeJSON([]byte(`{"foreign-source":"Local","node":[{"foreign-id":"n1","node-label":"n1","interface":[{"ip-addr":"10.0.0.1","snmp-primary":"P"}]}]}`), requisition))
+	assert.Equal(t, "10.0.0.1", requisition.Nodes[0].Interfaces[0].IPAddress)
+
+	err := strict.DecodeJSON([]byte(`{"foreign-source":"Local","node":[{"foreign-id":"n1","node-label":"n1","interface":[{"ip-address":"10.0.0.1"}]}]}`), &model.Requisition{})
+	assert.Error(t, err, `The response doesn't match model.Requisition (strict decode): json: unknown field "ip-address"`)
+
+	err = strict.DecodeJSON([]byte(`{"foreign-source":"Local","node":[{"foreign-id":"n1","node-label":"n1","interface":[{"snmp-primary":"P"}]}]}`), &model.Requisition{})
+	assert.Error(t, err, "The response doesn't match model.Requisition (strict decode): required field node[0].interface[0].ip-addr is empty")
+
+	err = strict.DecodeXML([]byte(`<model-import foreign-source="Local" date-stamp="2020-01-01T00:00:00.000Z"><node foreign-id="n1"/></model-import>`), &model.Requisition{})
+	assert.Error(t, err, "The response doesn't match model.Requisition (strict decode): required field node[0].node-label is empty")
+}
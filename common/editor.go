@@ -0,0 +1,35 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// EditorAvailable verifies that content can be edited interactively: STDIN and STDOUT must be terminals, and EDITOR must be set
+var EditorAvailable = func() error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return fmt.Errorf("Interactive editing requires a terminal")
+	}
+	if strings.TrimSpace(os.Getenv("EDITOR")) == "" {
+		return fmt.Errorf("Interactive editing requires the EDITOR environment variable (e.x. EDITOR=vim)")
+	}
+	return nil
+}
+
+// RunEditor opens a file with the command from the EDITOR environment variable, and waits until it exits
+var RunEditor = func(file string) error {
+	args := strings.Fields(os.Getenv("EDITOR")) // The editor can have arguments, like 'code --wait'
+	if len(args) == 0 {
+		return fmt.Errorf("EDITOR environment variable is not set")
+	}
+	cmd := exec.Command(args[0], append(args[1:], file)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Editor %s failed: %w", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package common
+
+import (
+	"os"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+)
+
+// EnumFlag returns the value of an enum flag of the current command;
+// as the flags keep their values between runs of the same application (e.x. on tests), the default is returned when the flag was not used
+func EnumFlag(c *cli.Context, name string) string {
+	if c.IsSet(name) {
+		return c.String(name)
+	}
+	for _, flag := range c.Command.Flags {
+		if enum, names := enumOf(flag); enum != nil && containsName(names, name) {
+			return enum.Default
+		}
+	}
+	return c.String(name)
+}
+
+// AddEnumCompletions makes the enum flags of the given commands, and their subcommands, suggest their values (e.x. --severity <TAB>);
+// the existing completion functions are used for everything else
+func AddEnumCompletions(commands []cli.Command) {
+	for i := range commands {
+		cmd := &commands[i]
+		if len(cmd.Subcommands) > 0 {
+			AddEnumCompletions(cmd.Subcommands)
+			continue
+		}
+		completions := enumCompletions(cmd.Flags)
+		if len(completions) == 0 {
+			continue
+		}
+		next := cmd.BashComplete
+		cmd.BashComplete = func(c *cli.Context) {
+			for _, completion := range completions {
+				if CompletingFlag(os.Args, completion.names...) {
+					PrintCompletions(completion.enum.Enum...)
+					return
+				}
+			}
+			if next != nil {
+				next(c)
+			}
+		}
+	}
+}
+
+type enumCompletion struct {
+	names []string
+	enum  *model.EnumValue
+}
+
+func enumCompletions(flags []cli.Flag) []enumCompletion {
+	completions := []enumCompletion{}
+	for _, flag := range flags {
+		if enum, names := enumOf(flag); enum != nil {
+			completions = append(completions, enumCompletion{names, enum})
+		}
+	}
+	return completions
+}
+
+// Returns the enum of a generic flag and its names, or nil when the flag is not an enum
+func enumOf(flag cli.Flag) (*model.EnumValue, []string) {
+	generic, ok := flag.(cli.GenericFlag)
+	if !ok {
+		return nil, nil
+	}
+	enum, ok := generic.Value.(*model.EnumValue)
+	if !ok {
+		return nil, nil
+	}
+	names := strings.Split(generic.Name, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return enum, names
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
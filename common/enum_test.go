@@ -0,0 +1,64 @@
+package common
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/urfave/cli"
+	"gotest.tools/assert"
+)
+
+func TestEnumFlag(t *testing.T) {
+	var status string
+	app := cli.NewApp()
+	app.Commands = []cli.Command{{
+		Name: "set",
+		Flags: []cli.Flag{
+			cli.GenericFlag{Name: "status, s", Value: model.NewEnum("1", "3").WithDefault("1")},
+		},
+		Action: func(c *cli.Context) error {
+			status = EnumFlag(c, "status")
+			return nil
+		},
+	}}
+	assert.NilError(t, app.Run([]string{"onmsctl", "set", "-s", "3"}))
+	assert.Equal(t, "3", status)
+	assert.NilError(t, app.Run([]string{"onmsctl", "set"})) // The enum still holds 3
+	assert.Equal(t, "1", status)
+	assert.ErrorContains(t, app.Run([]string{"onmsctl", "set", "-s", "2"}), "Invalid value 2, valid options: 1, 3")
+}
+
+func TestAddEnumCompletions(t *testing.T) {
+	app := cli.NewApp()
+	app.EnableBashCompletion = true
+	app.Commands = []cli.Command{{
+		Name: "alarms",
+		Subcommands: []cli.Command{{
+			Name: "list",
+			Flags: []cli.Flag{
+				cli.GenericFlag{Name: "severity, s", Value: model.NewEnum("Minor", "Major")},
+				cli.GenericFlag{Name: "sort", Value: model.NewEnum("count", "age")},
+			},
+			BashComplete: func(c *cli.Context) {
+				PrintCompletions("other")
+			},
+			Action: func(c *cli.Context) error { return nil },
+		}},
+	}}
+	AddEnumCompletions(app.Commands)
+
+	args := os.Args
+	defer func() { os.Args, Output = args, os.Stdout }()
+	complete := func(args ...string) string {
+		output := &bytes.Buffer{}
+		Output = output
+		os.Args = append([]string{"onmsctl", "alarms", "list"}, append(args, completionFlag)...)
+		assert.NilError(t, app.Run(os.Args))
+		return output.String()
+	}
+	assert.Equal(t, "Minor\nMajor\n", complete("-s"))
+	assert.Equal(t, "count\nage\n", complete("--sort"))
+	assert.Equal(t, "other\n", complete())
+}
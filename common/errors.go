@@ -0,0 +1,17 @@
+package common
+
+// ExitError an error that carries the exit status the CLI should return;
+// when the message is empty, nothing is printed on exit
+type ExitError struct {
+	Message string
+	Code    int
+}
+
+func (e ExitError) Error() string {
+	return e.Message
+}
+
+// ExitStatus returns the exit status for the CLI
+func (e ExitError) ExitStatus() int {
+	return e.Code
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
+	"gopkg.in/yaml.v3"
+)
+
+// EvalJSONPath evaluates a JSONPath expression against a JSON document, returning the decoded values it selects;
+// besides $, .name, ['name'], [index], [*], .* and ..name, it supports slices, unions and filters (e.x. [?(@.count > 1)])
+func EvalJSONPath(document []byte, expression string) ([]interface{}, error) {
+	path, err := compileJSONPath(expression)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML, and the YAML nodes keep the order of the fields
+	var root yaml.Node
+	if err := yaml.Unmarshal(document, &root); err != nil {
+		return nil, err
+	}
+	nodes, err := path.Find(&root)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		if err := node.Decode(&results[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func compileJSONPath(expression string) (*yamlpath.Path, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("JSONPath expression cannot be empty")
+	}
+	path, err := yamlpath.NewPath(strings.TrimSpace(expression))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid JSONPath expression %s: %w", expression, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LogLevel the verbosity of the messages that are not part of the payload of a command
+type LogLevel int
+
+// Log levels, from the least to the most verbose
+const (
+	LevelError LogLevel = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+// Logger writes leveled messages to stderr, so stdout only contains the payload of the commands (tables, YAML, JSON);
+// it is injected into the model, services and ReST layers, which can't depend on this package
+type Logger struct {
+	Output io.Writer
+	Level  LogLevel
+}
+
+// Log the logger of the CLI, controlled by the global --quiet, --verbose and --debug flags
+var Log = &Logger{Output: os.Stderr, Level: LevelInfo}
+
+// SetLogLevel chooses the level of Log from the global flags; by default, informational messages are displayed
+func SetLogLevel(quiet bool, verbose bool) error {
+	if quiet && verbose {
+		return fmt.Errorf("Use either --quiet or --verbose, not both")
+	}
+	switch {
+	case quiet:
+		Log.Level = LevelError
+	case verbose:
+		Log.Level = LevelDebug
+	default:
+		Log.Level = LevelInfo
+	}
+	return nil
+}
+
+// Enabled returns true when the messages of the given level are written
+func (l *Logger) Enabled(level LogLevel) bool {
+	return level <= l.Level
+}
+
+// Errorf writes an error, displayed even with --quiet
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, "ERROR: ", format, args...)
+}
+
+// Warnf writes a warning about something that didn't prevent the command from running
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, "WARNING: ", format, args...)
+}
+
+// Infof writes an informational message (e.x. the progress or the result of a command that doesn't display content)
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, "", format, args...)
+}
+
+// Debugf writes a message that is only displayed with --verbose or --debug
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, "DEBUG: ", format, args...)
+}
+
+func (l *Logger) logf(level LogLevel, prefix string, format string, args ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	fmt.Fprint(l.Output, prefix+message)
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLogger(t *testing.T) {
+	var output bytes.Buffer
+	logger := &Logger{Output: &output, Level: LevelInfo}
+	logger.Errorf("cannot send %s", "n1")
+	logger.Warnf("n1 has no interfaces")
+	logger.Infof("Requisition %s imported\n", "Test")
+	logger.Debugf("hidden")
+	assert.Equal(t, "ERROR: cannot send n1\nWARNING: n1 has no interfaces\nRequisition Test imported\n", output.String())
+
+	output.Reset()
+	logger.Level = LevelError
+	logger.Warnf("hidden")
+	logger.Errorf("failed")
+	assert.Equal(t, "ERROR: failed\n", output.String())
+}
+
+func TestSetLogLevel(t *testing.T) {
+	defer func() {
+		Log = &Logger{Output: os.Stderr, Level: LevelInfo}
+	}()
+	assert.NilError(t, SetLogLevel(false, false))
+	assert.Equal(t, LevelInfo, Log.Level)
+	assert.NilError(t, SetLogLevel(true, false))
+	assert.Equal(t, LevelError, Log.Level)
+	assert.Assert(t, !Log.Enabled(LevelWarn))
+	assert.NilError(t, SetLogLevel(false, true))
+	assert.Equal(t, LevelDebug, Log.Level)
+	assert.Error(t, SetLogLevel(true, true), "Use either --quiet or --verbose, not both")
+}
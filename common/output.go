@@ -0,0 +1,173 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats for the commands that display content
+const (
+	OutputTable    = "table"
+	OutputYAML     = "yaml"
+	OutputJSON     = "json"
+	OutputJSONPath = "jsonpath"
+)
+
+// OutputFormats the supported output formats
+var OutputFormats = []string{OutputTable, OutputYAML, OutputJSON, OutputJSONPath + "=<expression>"}
+
+// OutputFormat the format used by the commands that display content
+var OutputFormat = OutputTable
+
+// NoHeaders when enabled, tables are printed without the header row
+var NoHeaders = false
+
+// Output where the content displayed by the commands is written
+var Output io.Writer = os.Stdout
+
+// Table the tabular representation of the content displayed by a command
+type Table struct {
+	Headers []string
+	Rows    [][]string
+	Empty   string // The message displayed when there are no rows
+}
+
+// NewTable creates a new table with the given headers
+func NewTable(empty string, headers ...string) *Table {
+	return &Table{Headers: headers, Empty: empty}
+}
+
+// AddRow appends a row to the table
+func (t *Table) AddRow(fields ...interface{}) {
+	row := make([]string, len(fields))
+	for i, f := range fields {
+		row[i] = fmt.Sprint(f)
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+// Truncate shortens a value to the given amount of characters, ending with "..." when it was cut
+func Truncate(value string, max int) string {
+	runes := []rune(value)
+	if max <= 3 || len(runes) <= max {
+		return value
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+// HumanizeDuration formats a duration with its two most significant units (e.x. 2d5h, 3h12m, 4m30s)
+func HumanizeDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	seconds := int64(d / time.Second)
+	days, hours, minutes := seconds/86400, seconds/3600%24, seconds/60%60
+	seconds = seconds % 60
+	switch {
+	case days > 0:
+		return joinUnits(days, "d", hours, "h")
+	case hours > 0:
+		return joinUnits(hours, "h", minutes, "m")
+	case minutes > 0:
+		return joinUnits(minutes, "m", seconds, "s")
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+// Formats two units, omitting the second one when it is zero
+func joinUnits(major int64, majorUnit string, minor int64, minorUnit string) string {
+	if minor == 0 {
+		return fmt.Sprintf("%d%s", major, majorUnit)
+	}
+	return fmt.Sprintf("%d%s%d%s", major, majorUnit, minor, minorUnit)
+}
+
+// ValidateOutputFormat returns an error if the output format is not supported
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputTable, OutputYAML, OutputJSON:
+		return nil
+	}
+	if strings.HasPrefix(format, OutputJSONPath+"=") {
+		_, err := compileJSONPath(strings.TrimPrefix(format, OutputJSONPath+"="))
+		return err
+	}
+	return fmt.Errorf("Invalid output format %s, valid options: %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// Print writes the data using the chosen output format;
+// for tables, the rows provided by the command are used, or YAML when the command has no tabular representation
+func Print(data interface{}, table *Table) error {
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Slice && v.IsNil() {
+		data = reflect.MakeSlice(v.Type(), 0, 0).Interface() // Empty lists instead of null
+	}
+	switch {
+	case OutputFormat == OutputTable && table != nil:
+		return printTable(table)
+	case OutputFormat == OutputTable || OutputFormat == OutputYAML:
+		bytes, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = Output.Write(bytes)
+		return err
+	case OutputFormat == OutputJSON:
+		bytes, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(Output, string(bytes))
+		return err
+	case strings.HasPrefix(OutputFormat, OutputJSONPath+"="):
+		return printJSONPath(data, strings.TrimPrefix(OutputFormat, OutputJSONPath+"="))
+	}
+	return ValidateOutputFormat(OutputFormat)
+}
+
+func printTable(table *Table) error {
+	if len(table.Rows) == 0 && table.Empty != "" {
+		_, err := fmt.Fprintln(Output, table.Empty)
+		return err
+	}
+	writer := tabwriter.NewWriter(Output, 0, 8, 2, ' ', 0)
+	if !NoHeaders {
+		fmt.Fprintln(writer, strings.Join(table.Headers, "\t"))
+	}
+	for _, row := range table.Rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+func printJSONPath(data interface{}, expression string) error {
+	// Use the JSON representation, so the expressions refer to the field names of the ReST API
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	results, err := EvalJSONPath(bytes, expression)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		switch v := result.(type) {
+		case string:
+			fmt.Fprintln(Output, v)
+		default:
+			bytes, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(Output, string(bytes))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package common
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+type outputObject struct {
+	Name  string   `json:"name" yaml:"name"`
+	Items []string `json:"items" yaml:"items"`
+}
+
+func printWith(t *testing.T, format string, data interface{}, table *Table) string {
+	var output bytes.Buffer
+	Output = &output
+	OutputFormat = format
+	defer func() {
+		Output = os.Stdout
+		OutputFormat = OutputTable
+		NoHeaders = false
+	}()
+	assert.NilError(t, Print(data, table))
+	return output.String()
+}
+
+func TestPrint(t *testing.T) {
+	data := []outputObject{{Name: "first", Items: []string{"a", "b"}}, {Name: "second"}}
+	table := NewTable("There are no objects", "Name", "Items")
+	for _, o := range data {
+		table.AddRow(o.Name, len(o.Items))
+	}
+
+	assert.Equal(t, "Name    Items\nfirst   2\nsecond  0\n", printWith(t, OutputTable, data, table))
+	assert.Equal(t, "- name: first\n  items:\n  - a\n  - b\n- name: second\n  items: []\n", printWith(t, OutputYAML, data, table))
+	assert.Equal(t, `[
+  {
+    "name": "first",
+    "items": [
+      "a",
+      "b"
+    ]
+  },
+  {
+    "name": "second",
+    "items": null
+  }
+]
+`, printWith(t, OutputJSON, data, table))
+	assert.Equal(t, "first\nsecond\n", printWith(t, "jsonpath=$[*].name", data, table))
+	assert.Equal(t, "[\"a\",\"b\"]\nnull\n", printWith(t, "jsonpath=$[*].items", data, table))
+
+	NoHeaders = true
+	assert.Equal(t, "first   2\nsecond  0\n", printWith(t, OutputTable, data, table))
+
+	// Without a tabular representation, tables fallback to YAML
+	assert.Equal(t, "name: first\nitems:\n- a\n- b\n", printWith(t, OutputTable, data[0], nil))
+}
+
+func TestPrintEmpty(t *testing.T) {
+	var data []outputObject
+	table := NewTable("There are no objects", "Name", "Items")
+	assert.Equal(t, "There are no objects\n", printWith(t, OutputTable, data, table))
+	assert.Equal(t, "[]\n", printWith(t, OutputYAML, data, table))
+	assert.Equal(t, "[]\n", printWith(t, OutputJSON, data, table))
+	assert.Equal(t, "", printWith(t, "jsonpath=$[*].name", data, table))
+
+	table = NewTable("", "Name", "Items")
+	assert.Equal(t, "Name  Items\n", printWith(t, OutputTable, data, table))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "eth0", Truncate("eth0", 10))
+	assert.Equal(t, "GigabitEth...", Truncate("GigabitEthernet0/0/1", 13))
+	assert.Equal(t, "Ethernet", Truncate("Ethernet", 0))
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	assert.Equal(t, "0s", HumanizeDuration(-time.Second))
+	assert.Equal(t, "45s", HumanizeDuration(45*time.Second+300*time.Millisecond))
+	assert.Equal(t, "4m30s", HumanizeDuration(270*time.Second))
+	assert.Equal(t, "3h12m", HumanizeDuration(3*time.Hour+12*time.Minute+5*time.Second))
+	assert.Equal(t, "3h", HumanizeDuration(3*time.Hour+40*time.Second))
+	assert.Equal(t, "2d5h", HumanizeDuration(53*time.Hour+59*time.Minute))
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	assert.NilError(t, ValidateOutputFormat("table"))
+	assert.NilError(t, ValidateOutputFormat("yaml"))
+	assert.NilError(t, ValidateOutputFormat("json"))
+	assert.NilError(t, ValidateOutputFormat("jsonpath=$.name"))
+	assert.Error(t, ValidateOutputFormat("xml"), "Invalid output format xml, valid options: table, yaml, json, jsonpath=<expression>")
+	assert.Error(t, ValidateOutputFormat("jsonpath="), "JSONPath expression cannot be empty")
+	assert.NilError(t, ValidateOutputFormat("jsonpath=$[?(@.name == 'first')].items[-1]"))
+	assert.ErrorContains(t, ValidateOutputFormat("jsonpath=$.name[0"), "Invalid JSONPath expression $.name[0: unmatched [")
+}
+
+func TestEvalJSONPath(t *testing.T) {
+	document := []byte(`{
+		"count": 2,
+		"foreign-source": [
+			{"name": "Test", "foreign-id": ["n1", "n2"]},
+			{"name": "Local", "foreign-id": []}
+		]
+	}`)
+
+	testCases := []struct {
+		expression string
+		expected   []interface{}
+	}{
+		{"$.count", []interface{}{2}},
+		{"$.foreign-source[*].name", []interface{}{"Test", "Local"}},
+		{"$['foreign-source'][0]['foreign-id'][1]", []interface{}{"n2"}},
+		{"$.foreign-source[-1].name", []interface{}{"Local"}},
+		{"$.foreign-source[5].name", []interface{}{}},
+		{"$..name", []interface{}{"Test", "Local"}},
+		{"$.foreign-source[0].*", []interface{}{"Test", []interface{}{"n1", "n2"}}},
+		{"$.foreign-source[?(@.name == 'Local')].foreign-id", []interface{}{[]interface{}{}}},
+		{"$.unknown", []interface{}{}},
+	}
+	for _, tc := range testCases {
+		results, err := EvalJSONPath(document, tc.expression)
+		assert.NilError(t, err, tc.expression)
+		assert.DeepEqual(t, tc.expected, results)
+	}
+
+	_, err := EvalJSONPath(document, "$.name.")
+	assert.ErrorContains(t, err, "Invalid JSONPath expression $.name.: child name missing")
+	_, err = EvalJSONPath(document, " ")
+	assert.Error(t, err, "JSONPath expression cannot be empty")
+}
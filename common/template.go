@@ -0,0 +1,118 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"gopkg.in/yaml.v2"
+)
+
+// TemplateFuncs the helper functions available to templates: the sprig library, plus required and toYaml like on Helm
+var TemplateFuncs = templateFuncs()
+
+func templateFuncs() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	funcs["required"] = func(msg string, value interface{}) (interface{}, error) {
+		if isEmptyValue(value) {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return value, nil
+	}
+	funcs["toYaml"] = func(value interface{}) (string, error) {
+		data, err := yaml.Marshal(value)
+		return strings.TrimSuffix(string(data), "\n"), err
+	}
+	return funcs
+}
+
+// RenderTemplate executes a Go template with the given values;
+// the name is used on error messages, which include the line number within the template
+func RenderTemplate(name string, content []byte, values map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, values); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// ReadTemplateValues parses YAML content into a map of values for a template
+func ReadTemplateValues(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		values[key] = normalizeValue(value)
+	}
+	return values, nil
+}
+
+// SetTemplateValue sets a value using a dotted path (e.x. site.location=Durham), creating the intermediate maps when needed;
+// the value is parsed as YAML, so numbers and booleans keep their types
+func SetTemplateValue(values map[string]interface{}, expression string) error {
+	data := strings.SplitN(expression, "=", 2)
+	if len(data) != 2 || data[0] == "" {
+		return fmt.Errorf("Invalid value %s, expected key=value", expression)
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(data[1]), &value); err != nil || value == nil {
+		value = data[1]
+	}
+	path := strings.Split(data[0], ".")
+	current := values
+	for _, key := range path[:len(path)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	current[path[len(path)-1]] = normalizeValue(value)
+	return nil
+}
+
+// normalizeValue converts the maps produced by the YAML parser into maps with string keys
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalizeValue(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeValue(item)
+		}
+		return v
+	}
+	return value
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
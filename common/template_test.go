@@ -0,0 +1,40 @@
+package common
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values, err := ReadTemplateValues([]byte("site:\n  name: durham\n  nodes: 2\n"))
+	assert.NilError(t, err)
+	assert.NilError(t, SetTemplateValue(values, "site.nodes=3"))
+	assert.NilError(t, SetTemplateValue(values, "owner.team=ops=east"))
+	assert.ErrorContains(t, SetTemplateValue(values, "owner"), "expected key=value")
+
+	tmpl := `name: {{ .site.name | upper }}
+nodes:{{ range $i := until .site.nodes }}
+- foreignID: {{ $.site.name }}-{{ add $i 1 }}{{ end }}
+owner: {{ .owner.team | quote }}
+city: {{ .site.city | default "Durham" }}
+code: {{ .site.name | trunc 3 | upper }}`
+	data, err := RenderTemplate("site.tmpl", []byte(tmpl), values)
+	assert.NilError(t, err)
+	expected := `name: DURHAM
+nodes:
+- foreignID: durham-1
+- foreignID: durham-2
+- foreignID: durham-3
+owner: "ops=east"
+city: Durham
+code: DUR`
+	assert.Equal(t, expected, string(data))
+
+	_, err = RenderTemplate("site.tmpl", []byte("name: x\n{{ .site.name | unknown }}"), values)
+	assert.ErrorContains(t, err, "site.tmpl:2")
+
+	_, err = RenderTemplate("site.tmpl", []byte("name: x\n\n{{ required \"city is required\" .site.city }}"), values)
+	assert.ErrorContains(t, err, "site.tmpl:3")
+	assert.ErrorContains(t, err, "city is required")
+}
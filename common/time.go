@@ -0,0 +1,60 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+// FIQLTimeFormat the format of the timestamps on FIQL expressions
+const FIQLTimeFormat = "2006-01-02T15:04:05.000-0700"
+
+// A duration without sign (e.x. 2h)
+var durationPattern = regexp.MustCompile(`^\d+(ms|s|m|h|d|w)$`)
+
+// ParseRelativeTime parses a time relative to now (e.x. "now", "-2h", "now-1d", "now+30m"),
+// an RFC3339 timestamp, or the milliseconds since the epoch; see model.ParseHumanTime for all the formats
+func ParseRelativeTime(value string, now time.Time) (time.Time, error) {
+	return model.ParseHumanTime(value, now)
+}
+
+// TimeRangeFilter returns the FIQL expressions to restrict a time field to the range from since to until;
+// both are optional, accept the formats of model.ParseHumanTime, and a plain duration (e.x. 2h) means 2 hours ago
+func TimeRangeFilter(field string, since string, until string, now time.Time) ([]string, error) {
+	expressions := make([]string, 0)
+	var start, end time.Time
+	var err error
+	if since != "" {
+		if start, err = parseRangeTime(since, now); err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, field+"=gt="+start.Format(FIQLTimeFormat))
+	}
+	if until != "" {
+		if end, err = parseRangeTime(until, now); err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, field+"=lt="+end.Format(FIQLTimeFormat))
+	}
+	if since != "" && until != "" && !start.Before(end) {
+		return nil, fmt.Errorf("The --since time (%s) must be before the --until time (%s)", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	return expressions, nil
+}
+
+func parseRangeTime(value string, now time.Time) (time.Time, error) {
+	if durationPattern.MatchString(value) {
+		value += " ago"
+	}
+	return model.ParseHumanTime(value, now)
+}
+
+// DisplayTime formats a timestamp on the local time zone for tables and messages, or returns "Never" when it is not set
+func DisplayTime(t *model.Time) string {
+	if t == nil || t.IsZero() {
+		return "Never"
+	}
+	return t.In(time.Local).Format(time.RFC3339)
+}
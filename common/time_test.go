@@ -0,0 +1,42 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+func TestParseRelativeTime(t *testing.T) {
+	now := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"now":                  now,
+		"NOW":                  now,
+		"-2h":                  now.Add(-2 * time.Hour),
+		"now-1d":               now.Add(-24 * time.Hour),
+		"now - 30m":            now.Add(-30 * time.Minute),
+		"now+1w":               now.Add(7 * 24 * time.Hour),
+		"-90s":                 now.Add(-90 * time.Second),
+		"-500ms":               now.Add(-500 * time.Millisecond),
+		"2020-01-14T08:00:00Z": time.Date(2020, 1, 14, 8, 0, 0, 0, time.UTC),
+		"1579089600000":        now,
+	}
+	for value, expected := range tests {
+		parsed, err := ParseRelativeTime(value, now)
+		assert.NilError(t, err, value)
+		assert.Assert(t, expected.Equal(parsed), "%s: expected %s, got %s", value, expected, parsed)
+	}
+
+	for _, value := range []string{"", "yesterday", "now-2y", "-h", "2h", "now-"} {
+		_, err := ParseRelativeTime(value, now)
+		assert.Assert(t, err != nil, value)
+	}
+}
+
+func TestDisplayTime(t *testing.T) {
+	assert.Equal(t, "Never", DisplayTime(nil))
+	assert.Equal(t, "Never", DisplayTime(&model.Time{}))
+	stamp := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, stamp.In(time.Local).Format(time.RFC3339), DisplayTime(&model.Time{Time: stamp}))
+}
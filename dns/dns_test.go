@@ -0,0 +1,101 @@
+package dns
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"gotest.tools/assert"
+)
+
+// Starts a DNS server that answers the zone transfer with the given messages, or with the response code when it is not successful
+func startFakeServer(t *testing.T, zone string, rcode int, messages ...string) *dns.Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	server := &dns.Server{Listener: listener, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, request *dns.Msg) {
+		assert.Equal(t, dns.Fqdn(zone), request.Question[0].Name)
+		assert.Equal(t, dns.TypeAXFR, request.Question[0].Qtype)
+		if rcode != dns.RcodeSuccess {
+			response := new(dns.Msg)
+			w.WriteMsg(response.SetRcode(request, rcode))
+			return
+		}
+		envelopes := make(chan *dns.Envelope)
+		transfer := new(dns.Transfer)
+		go transfer.Out(w, request, envelopes)
+		for _, message := range messages {
+			envelope := &dns.Envelope{}
+			for _, line := range strings.Split(strings.TrimSpace(message), "\n") {
+				rr, err := dns.NewRR(line)
+				assert.NilError(t, err)
+				envelope.RR = append(envelope.RR, rr)
+			}
+			envelopes <- envelope
+		}
+		close(envelopes)
+		w.Hijack()
+	})}
+	go server.ActivateAndServe()
+	return server
+}
+
+func TestTransfer(t *testing.T) {
+	soa := "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 1 7200 3600 1209600 3600"
+	fake := startFakeServer(t, "example.com", dns.RcodeSuccess, soa+`
+example.com. 3600 IN A 10.0.0.1
+sw-01.example.com. 3600 IN A 10.0.0.2
+www.example.com. 3600 IN CNAME sw-01.example.com.`, `
+rtr-01.example.com. 3600 IN AAAA 2001:db8::1
+`+soa)
+	defer fake.Shutdown()
+	records, err := Transfer("example.com.", fake.Listener.Addr().String(), time.Second)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []Record{
+		{"example.com", TypeA, "10.0.0.1"},
+		{"sw-01.example.com", TypeA, "10.0.0.2"},
+		{"rtr-01.example.com", TypeAAAA, "2001:db8::1"},
+	}, records)
+	assert.Equal(t, "sw-01", records[1].HostName("example.com"))
+	assert.Equal(t, "example.com", records[0].HostName("example.com"))
+}
+
+func TestTransferRefused(t *testing.T) {
+	fake := startFakeServer(t, "example.com", dns.RcodeRefused)
+	defer fake.Shutdown()
+	server := fake.Listener.Addr().String()
+	_, err := Transfer("example.com", server, time.Second)
+	assert.ErrorContains(t, err, "Zone transfer of example.com from "+server+" failed")
+
+	_, err = Transfer("", server, time.Second)
+	assert.Error(t, err, "Zone required")
+}
+
+func TestParseRecords(t *testing.T) {
+	content := `
+$TTL 3600
+; The apex records
+@        IN SOA ns1 admin 1 2 3 4 5
+@        IN A   10.0.0.1
+sw-01    300 IN A 10.0.0.2 ; a switch
+         IN AAAA 2001:db8::2
+rtr-01.example.com. A 10.0.0.3
+www      CNAME sw-01
+$ORIGIN lab.example.com.
+srv-01   IN A 10.1.0.1
+`
+	records, err := ParseRecords(strings.NewReader(content), "example.com")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []Record{
+		{"example.com", TypeA, "10.0.0.1"},
+		{"sw-01.example.com", TypeA, "10.0.0.2"},
+		{"sw-01.example.com", TypeAAAA, "2001:db8::2"},
+		{"rtr-01.example.com", TypeA, "10.0.0.3"},
+		{"srv-01.lab.example.com", TypeA, "10.1.0.1"},
+	}, records)
+	assert.Equal(t, "srv-01.lab", records[4].HostName("example.com"))
+
+	_, err = ParseRecords(strings.NewReader("sw-01 IN A 2001:db8::1\n"), "example.com")
+	assert.ErrorContains(t, err, `bad A A: "2001:db8::1" at line: 1`)
+}
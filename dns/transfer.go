@@ -0,0 +1,58 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Transfer performs a zone transfer (AXFR) from the server through TCP, and returns the A and AAAA records of the zone;
+// the port 53 is used when the server doesn't have one
+func Transfer(zone string, server string, timeout time.Duration) ([]Record, error) {
+	zone = CanonicalName(zone)
+	if zone == "" {
+		return nil, fmt.Errorf("Zone required")
+	}
+	if _, ok := dns.IsDomainName(zone); !ok {
+		return nil, fmt.Errorf("Invalid zone name %s", zone)
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	query := new(dns.Msg)
+	query.SetAxfr(dns.Fqdn(zone))
+	transfer := &dns.Transfer{DialTimeout: timeout, ReadTimeout: timeout, WriteTimeout: timeout}
+	envelopes, err := transfer.In(query, server)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot request the zone transfer of %s from %s: %w", zone, server, err)
+	}
+	records := make([]Record, 0)
+	var failure error
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			failure = envelope.Error
+			continue // The channel is closed after the error
+		}
+		records = append(records, addressRecords(envelope.RR)...)
+	}
+	if failure != nil {
+		return nil, fmt.Errorf("Zone transfer of %s from %s failed: %w", zone, server, failure)
+	}
+	return records, nil
+}
+
+// Returns the A and AAAA records, ignoring the other types
+func addressRecords(rrs []dns.RR) []Record {
+	records := make([]Record, 0)
+	for _, rr := range rrs {
+		switch r := rr.(type) {
+		case *dns.A:
+			records = append(records, Record{Name: CanonicalName(r.Hdr.Name), Type: TypeA, Address: r.A.String()})
+		case *dns.AAAA:
+			records = append(records, Record{Name: CanonicalName(r.Hdr.Name), Type: TypeAAAA, Address: r.AAAA.String()})
+		}
+	}
+	return records
+}
@@ -0,0 +1,49 @@
+package dns
+
+import (
+	"io"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// The types of the address records
+const (
+	TypeA    = "A"
+	TypeAAAA = "AAAA"
+)
+
+// Record an address record of a DNS zone
+type Record struct {
+	Name    string // The FQDN without the trailing dot
+	Type    string // A or AAAA
+	Address string
+}
+
+// HostName returns the name of the record relative to the zone, or the FQDN for the zone apex or names outside the zone
+func (r Record) HostName(zone string) string {
+	zone = CanonicalName(zone)
+	if suffix := "." + zone; strings.HasSuffix(strings.ToLower(r.Name), strings.ToLower(suffix)) {
+		return r.Name[:len(r.Name)-len(suffix)]
+	}
+	return r.Name
+}
+
+// CanonicalName returns the name without the trailing dot
+func CanonicalName(name string) string {
+	return strings.TrimSuffix(strings.TrimSpace(name), ".")
+}
+
+// ParseRecords reads the A and AAAA records from a file in zone file format, like the output of 'dig axfr';
+// relative names are completed with the zone (or the last $ORIGIN), and the other record types are ignored
+func ParseRecords(input io.Reader, zone string) ([]Record, error) {
+	parser := dns.NewZoneParser(input, dns.Fqdn(CanonicalName(zone)), "")
+	rrs := make([]dns.RR, 0)
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		rrs = append(rrs, rr)
+	}
+	if err := parser.Err(); err != nil {
+		return nil, err
+	}
+	return addressRecords(rrs), nil
+}
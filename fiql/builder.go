@@ -0,0 +1,76 @@
+package fiql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Builder composes an expression from constraints and raw filters, all of them joined by And;
+// the first error is kept, and returned by Build
+type Builder struct {
+	parts []string
+	err   error
+}
+
+// NewBuilder returns an empty builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Add adds a constraint; the value is quoted when required
+func (b *Builder) Add(selector string, operator string, value string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	constraint := Constraint{Selector: selector, Operator: operator, Value: value}
+	if selector == "" || strings.IndexFunc(selector, func(r rune) bool { return r > 0x7f || !isSelectorChar(byte(r)) }) >= 0 {
+		b.err = fmt.Errorf("Invalid FIQL selector %q", selector)
+		return b
+	}
+	if !isOperator(operator) {
+		b.err = fmt.Errorf("Invalid FIQL operator %s, expected one of %s", operator, strings.Join(Operators, ", "))
+		return b
+	}
+	b.parts = append(b.parts, constraint.String())
+	return b
+}
+
+// Equals adds a constraint with the Equal operator
+func (b *Builder) Equals(selector string, value string) *Builder {
+	return b.Add(selector, Equal, value)
+}
+
+// Filter adds a raw expression (e.x. from --filter) as it is, after validating it; when it joins terms with Or,
+// it is enclosed in parenthesis, so the rest of the constraints apply to all of them. Empty expressions are ignored
+func (b *Builder) Filter(expression string) *Builder {
+	if b.err != nil || expression == "" {
+		return b
+	}
+	node, err := Parse(expression)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	if group, ok := node.(Group); ok && group.Operator == Or {
+		expression = "(" + expression + ")"
+	}
+	b.parts = append(b.parts, expression)
+	return b
+}
+
+// Build returns the expression, or an empty string when nothing was added
+func (b *Builder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return strings.Join(b.parts, And), nil
+}
+
+func isOperator(operator string) bool {
+	for _, o := range Operators {
+		if o == operator {
+			return true
+		}
+	}
+	return false
+}
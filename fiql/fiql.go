@@ -0,0 +1,264 @@
+// Package fiql parses, validates and builds the FIQL expressions used to filter the entities of the ReST API v2,
+// to report malformed filters on the client side instead of through an error of the server.
+package fiql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// The comparison operators supported by the ReST API v2
+const (
+	Equal        = "=="
+	NotEqual     = "!="
+	GreaterEqual = "=ge="
+	LessEqual    = "=le="
+	GreaterThan  = "=gt="
+	LessThan     = "=lt="
+)
+
+// Operators the valid comparison operators
+var Operators = []string{Equal, NotEqual, GreaterEqual, LessEqual, GreaterThan, LessThan}
+
+// The logical operators, from the lowest precedence
+const (
+	Or  = ","
+	And = ";"
+)
+
+// The characters that cannot appear on an unquoted value
+const specialChars = `;,()"'`
+
+// Node a constraint or a group of nodes joined by a logical operator
+type Node interface {
+	String() string
+}
+
+// Constraint compares a selector (e.x. node.label) with a value
+type Constraint struct {
+	Selector string
+	Operator string
+	Value    string
+}
+
+// Group nodes joined by And or Or
+type Group struct {
+	Operator string
+	Nodes    []Node
+}
+
+// String returns the constraint as FIQL, quoting the value when required
+func (c Constraint) String() string {
+	return c.Selector + c.Operator + QuoteValue(c.Value)
+}
+
+// String returns the group as FIQL, with parenthesis around nested groups with a lower precedence
+func (g Group) String() string {
+	parts := make([]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		parts[i] = n.String()
+		if child, ok := n.(Group); ok && g.Operator == And && child.Operator == Or {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, g.Operator)
+}
+
+// QuoteValue returns the value between double quotes, escaping quotes and backslashes,
+// when it is empty, or contains spaces or characters with a meaning on FIQL; otherwise, it is returned unchanged
+func QuoteValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, specialChars+" \t\r\n") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// SyntaxError an invalid expression, with the position of the offending character
+type SyntaxError struct {
+	Expression string
+	Offset     int // In bytes
+	Message    string
+}
+
+// Position returns the position of the offending character, starting from 1
+func (e SyntaxError) Position() int {
+	return utf8.RuneCountInString(e.Expression[:e.Offset]) + 1
+}
+
+// Error returns the message followed by the expression and a pointer to the offending character
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("Invalid FIQL expression, %s at position %d\n%s\n%s^", e.Message, e.Position(), e.Expression, strings.Repeat(" ", e.Position()-1))
+}
+
+// Validate returns a SyntaxError when the expression cannot be parsed; an empty expression is valid
+func Validate(expression string) error {
+	if expression == "" {
+		return nil
+	}
+	_, err := Parse(expression)
+	return err
+}
+
+// Parse returns the tree of an expression; groups with a single node are replaced by the node,
+// and nested groups with the same operator are merged
+func Parse(expression string) (Node, error) {
+	p := &parser{text: expression}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.text) {
+		if p.text[p.pos] == ')' {
+			return nil, p.errorf("unexpected closing parenthesis")
+		}
+		return nil, p.errorf("unexpected character %q", p.peek())
+	}
+	return node, nil
+}
+
+type parser struct {
+	text string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return SyntaxError{Expression: p.text, Offset: p.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.text[p.pos:])
+	return r
+}
+
+func (p *parser) parseOr() (Node, error) {
+	return p.parseGroup(Or, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	return p.parseGroup(And, p.parseTerm)
+}
+
+// Parses terms separated by the given operator
+func (p *parser) parseGroup(operator string, term func() (Node, error)) (Node, error) {
+	group := Group{Operator: operator}
+	for {
+		node, err := term()
+		if err != nil {
+			return nil, err
+		}
+		if child, ok := node.(Group); ok && child.Operator == operator {
+			group.Nodes = append(group.Nodes, child.Nodes...)
+		} else {
+			group.Nodes = append(group.Nodes, node)
+		}
+		if !strings.HasPrefix(p.text[p.pos:], operator) {
+			break
+		}
+		p.pos += len(operator)
+	}
+	if len(group.Nodes) == 1 {
+		return group.Nodes[0], nil
+	}
+	return group, nil
+}
+
+func (p *parser) parseTerm() (Node, error) {
+	if p.pos < len(p.text) && p.text[p.pos] == '(' {
+		open := p.pos
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.text) || p.text[p.pos] != ')' {
+			p.pos = open
+			return nil, p.errorf("unbalanced parenthesis")
+		}
+		p.pos++
+		return node, nil
+	}
+	return p.parseConstraint()
+}
+
+func (p *parser) parseConstraint() (Node, error) {
+	start := p.pos
+	for p.pos < len(p.text) && isSelectorChar(p.text[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.pos == len(p.text) {
+			return nil, p.errorf("expected a selector at the end")
+		}
+		return nil, p.errorf("expected a selector, found %q", p.peek())
+	}
+	constraint := Constraint{Selector: p.text[start:p.pos]}
+	operator, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	constraint.Operator = operator
+	if constraint.Value, err = p.parseValue(); err != nil {
+		return nil, err
+	}
+	return constraint, nil
+}
+
+func (p *parser) parseOperator() (string, error) {
+	rest := p.text[p.pos:]
+	if strings.HasPrefix(rest, Equal) || strings.HasPrefix(rest, NotEqual) {
+		p.pos += 2
+		return rest[:2], nil
+	}
+	if strings.HasPrefix(rest, "=") {
+		if end := strings.Index(rest[1:], "="); end >= 0 {
+			operator := rest[:end+2]
+			if isOperator(operator) {
+				p.pos += len(operator)
+				return operator, nil
+			}
+			return "", p.errorf("unknown operator %s, expected one of %s", operator, strings.Join(Operators, ", "))
+		}
+	}
+	return "", p.errorf("expected an operator (%s)", strings.Join(Operators, ", "))
+}
+
+func (p *parser) parseValue() (string, error) {
+	if p.pos < len(p.text) && p.text[p.pos] == '"' {
+		return p.parseQuotedValue()
+	}
+	start := p.pos
+	for p.pos < len(p.text) && !strings.ContainsRune(specialChars, rune(p.text[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a value")
+	}
+	return p.text[start:p.pos], nil
+}
+
+func (p *parser) parseQuotedValue() (string, error) {
+	start := p.pos
+	value := strings.Builder{}
+	for p.pos++; p.pos < len(p.text); p.pos++ {
+		switch p.text[p.pos] {
+		case '\\':
+			p.pos++
+			if p.pos == len(p.text) {
+				break
+			}
+			value.WriteByte(p.text[p.pos])
+		case '"':
+			p.pos++
+			return value.String(), nil
+		default:
+			value.WriteByte(p.text[p.pos])
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated quoted value")
+}
+
+func isSelectorChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-'
+}
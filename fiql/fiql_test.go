@@ -0,0 +1,190 @@
+package fiql
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"gotest.tools/assert"
+)
+
+func TestParse(t *testing.T) {
+	node, err := Parse(`alarm.severity=ge=MAJOR;(node.label==web*,node.label=="db, primary");alarmAckUser==\u0000`)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, Group{Operator: And, Nodes: []Node{
+		Constraint{"alarm.severity", GreaterEqual, "MAJOR"},
+		Group{Operator: Or, Nodes: []Node{
+			Constraint{"node.label", Equal, "web*"},
+			Constraint{"node.label", Equal, "db, primary"},
+		}},
+		Constraint{"alarmAckUser", Equal, `\u0000`},
+	}}, node)
+
+	// Nested groups with the same operator are merged, and parenthesis around a single constraint are dropped
+	node, err = Parse("(a==1;(b!=2;c=lt=3));(d=gt=4)")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, Group{Operator: And, Nodes: []Node{
+		Constraint{"a", Equal, "1"}, Constraint{"b", NotEqual, "2"}, Constraint{"c", LessThan, "3"}, Constraint{"d", GreaterThan, "4"},
+	}}, node)
+	assert.Equal(t, "a==1;b!=2;c=lt=3;d=gt=4", node.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		expression string
+		message    string
+		position   int
+	}{
+		{"", "expected a selector at the end", 1},
+		{"label", "expected an operator (==, !=, =ge=, =le=, =gt=, =lt=)", 6},
+		{"severity=foo=MAJOR", "unknown operator =foo=, expected one of ==, !=, =ge=, =le=, =gt=, =lt=", 9},
+		{"label==", "expected a value", 8},
+		{"label==a;", "expected a selector at the end", 10},
+		{"label==a;;id==1", `expected a selector, found ';'`, 10},
+		{"(label==a,id==1;id==2", "unbalanced parenthesis", 1},
+		{"label==a)", "unexpected closing parenthesis", 9},
+		{`label=="web`, "unterminated quoted value", 8},
+		{"label==it's", `unexpected character '\''`, 10},
+		{"café==1", `expected an operator (==, !=, =ge=, =le=, =gt=, =lt=)`, 4},
+	}
+	for _, tc := range testCases {
+		_, err := Parse(tc.expression)
+		assert.Assert(t, err != nil, tc.expression)
+		syntaxError, ok := err.(SyntaxError)
+		assert.Assert(t, ok, tc.expression)
+		assert.Equal(t, tc.message, syntaxError.Message, tc.expression)
+		assert.Equal(t, tc.position, syntaxError.Position(), tc.expression)
+	}
+
+	err := Validate("severity=foo=MAJOR")
+	assert.Error(t, err, "Invalid FIQL expression, unknown operator =foo=, expected one of ==, !=, =ge=, =le=, =gt=, =lt= at position 9\nseverity=foo=MAJOR\n        ^")
+	assert.NilError(t, Validate(""))
+}
+
+func TestQuoteValue(t *testing.T) {
+	assert.Equal(t, "web*", QuoteValue("web*"))
+	assert.Equal(t, "2024-01-02T15:04:05.000+0100", QuoteValue("2024-01-02T15:04:05.000+0100"))
+	assert.Equal(t, `""`, QuoteValue(""))
+	assert.Equal(t, `"db, primary"`, QuoteValue("db, primary"))
+	assert.Equal(t, `"say \"hi\" \\o/"`, QuoteValue(`say "hi" \o/`))
+}
+
+func TestBuilder(t *testing.T) {
+	expression, err := NewBuilder().
+		Equals("alarm.severity", "MAJOR").
+		Equals("node.label", "rtr (core); 1").
+		Filter("alarm.uei==*nodeDown,alarm.uei==*nodeLostService").
+		Filter("").
+		Build()
+	assert.NilError(t, err)
+	assert.Equal(t, `alarm.severity==MAJOR;node.label=="rtr (core); 1";(alarm.uei==*nodeDown,alarm.uei==*nodeLostService)`, expression)
+
+	expression, err = NewBuilder().Build()
+	assert.NilError(t, err)
+	assert.Equal(t, "", expression)
+
+	_, err = NewBuilder().Add("node label", Equal, "a").Build()
+	assert.Error(t, err, `Invalid FIQL selector "node label"`)
+	_, err = NewBuilder().Add("label", "=like=", "a").Build()
+	assert.Error(t, err, "Invalid FIQL operator =like=, expected one of ==, !=, =ge=, =le=, =gt=, =lt=")
+	_, err = NewBuilder().Equals("label", "a").Filter("label=x").Build()
+	assert.ErrorContains(t, err, "Invalid FIQL expression, expected an operator")
+}
+
+// A constraint with a random selector, operator and value; the value may include any character
+type randomConstraint Constraint
+
+func (randomConstraint) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(randomConstraint(generateConstraint(r, size)))
+}
+
+func generateConstraint(r *rand.Rand, size int) Constraint {
+	const selectorChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789._-"
+	selector := make([]byte, 1+r.Intn(12))
+	for i := range selector {
+		selector[i] = selectorChars[r.Intn(len(selectorChars))]
+	}
+	value, _ := quick.Value(reflect.TypeOf(""), r)
+	// Make the characters with a meaning on FIQL frequent
+	extra := []string{"", ";", ",", "(", ")", `"`, "'", `\`, " ", "=", "==", "*"}
+	return Constraint{
+		Selector: string(selector),
+		Operator: Operators[r.Intn(len(Operators))],
+		Value:    extra[r.Intn(len(extra))] + value.String() + extra[r.Intn(len(extra))],
+	}
+}
+
+// A random tree, normalized as the parser returns it: groups have at least two nodes, and their groups use the other operator
+type randomTree struct {
+	Node Node
+}
+
+func (randomTree) Generate(r *rand.Rand, size int) reflect.Value {
+	operators := []string{And, Or}
+	return reflect.ValueOf(randomTree{generateNode(r, 3, operators[r.Intn(2)])})
+}
+
+func generateNode(r *rand.Rand, depth int, operator string) Node {
+	if depth == 0 || r.Intn(3) == 0 {
+		return generateConstraint(r, 10)
+	}
+	child := And
+	if operator == And {
+		child = Or
+	}
+	group := Group{Operator: operator}
+	for i := 0; i < 2+r.Intn(3); i++ {
+		group.Nodes = append(group.Nodes, generateNode(r, depth-1, child))
+	}
+	return group
+}
+
+func TestBuiltExpressionsRoundTrip(t *testing.T) {
+	property := func(constraints []randomConstraint) bool {
+		builder := NewBuilder()
+		expected := Group{Operator: And}
+		for _, c := range constraints {
+			builder.Add(c.Selector, c.Operator, c.Value)
+			expected.Nodes = append(expected.Nodes, Constraint(c))
+		}
+		expression, err := builder.Build()
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		if len(constraints) == 0 {
+			return expression == ""
+		}
+		node, err := Parse(expression)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		if len(constraints) == 1 {
+			return reflect.DeepEqual(expected.Nodes[0], node)
+		}
+		return reflect.DeepEqual(expected, node)
+	}
+	assert.NilError(t, quick.Check(property, &quick.Config{MaxCount: 500}))
+}
+
+func TestTreesRoundTrip(t *testing.T) {
+	property := func(tree randomTree) bool {
+		expression := tree.Node.String()
+		node, err := Parse(expression)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		// Raw filters are added as they are, and must also survive the builder
+		built, err := NewBuilder().Equals("id", "1").Filter(expression).Build()
+		if err != nil || !strings.HasPrefix(built, "id==1;") {
+			t.Log(built, err)
+			return false
+		}
+		return reflect.DeepEqual(tree.Node, node) && Validate(built) == nil
+	}
+	assert.NilError(t, quick.Check(property, &quick.Config{MaxCount: 500}))
+}
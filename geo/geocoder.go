@@ -0,0 +1,105 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultNominatimURL the search endpoint of the public Nominatim server of OpenStreetMap
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org/search"
+
+// Coordinates a geographic location in decimal degrees
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Validate returns an error if the coordinates are out of range
+func (c Coordinates) Validate() error {
+	if c.Latitude < -90 || c.Latitude > 90 {
+		return fmt.Errorf("Invalid latitude %v, it must be between -90 and 90", c.Latitude)
+	}
+	if c.Longitude < -180 || c.Longitude > 180 {
+		return fmt.Errorf("Invalid longitude %v, it must be between -180 and 180", c.Longitude)
+	}
+	return nil
+}
+
+// LatitudeString returns the latitude as used on the asset fields
+func (c Coordinates) LatitudeString() string {
+	return strconv.FormatFloat(c.Latitude, 'f', -1, 64)
+}
+
+// LongitudeString returns the longitude as used on the asset fields
+func (c Coordinates) LongitudeString() string {
+	return strconv.FormatFloat(c.Longitude, 'f', -1, 64)
+}
+
+// Geocoder translates postal addresses into coordinates
+type Geocoder interface {
+	Geocode(address string) (*Coordinates, error)
+}
+
+// NominatimGeocoder a geocoder based on the search API of Nominatim (https://nominatim.org)
+type NominatimGeocoder struct {
+	URL       string
+	UserAgent string // Required by the usage policy of the public server
+	Timeout   time.Duration
+}
+
+// The relevant fields of a Nominatim search result, where the coordinates are strings
+type nominatimPlace struct {
+	Latitude  string `json:"lat"`
+	Longitude string `json:"lon"`
+}
+
+// Geocode returns the coordinates of the best match for the address
+func (g NominatimGeocoder) Geocode(address string) (*Coordinates, error) {
+	if address == "" {
+		return nil, fmt.Errorf("Address required")
+	}
+	endpoint := g.URL
+	if endpoint == "" {
+		endpoint = DefaultNominatimURL
+	}
+	params := url.Values{}
+	params.Set("q", address)
+	params.Set("format", "json")
+	params.Set("limit", "1")
+	request, err := http.NewRequest(http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if g.UserAgent != "" {
+		request.Header.Set("User-Agent", g.UserAgent)
+	}
+	client := &http.Client{Timeout: g.Timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot geocode %s: %w", address, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Cannot geocode %s: %s", address, response.Status)
+	}
+	places := make([]nominatimPlace, 0)
+	if err := json.NewDecoder(response.Body).Decode(&places); err != nil {
+		return nil, fmt.Errorf("Cannot parse the geocoding response: %w", err)
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("Address %s not found", address)
+	}
+	coordinates := &Coordinates{}
+	if coordinates.Latitude, err = strconv.ParseFloat(places[0].Latitude, 64); err != nil {
+		return nil, fmt.Errorf("Invalid latitude %s on the geocoding response", places[0].Latitude)
+	}
+	if coordinates.Longitude, err = strconv.ParseFloat(places[0].Longitude, 64); err != nil {
+		return nil, fmt.Errorf("Invalid longitude %s on the geocoding response", places[0].Longitude)
+	}
+	return coordinates, coordinates.Validate()
+}
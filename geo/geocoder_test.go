@@ -0,0 +1,42 @@
+package geo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestNominatimGeocoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "onmsctl-test", req.Header.Get("User-Agent"))
+		assert.Equal(t, "json", req.URL.Query().Get("format"))
+		switch req.URL.Query().Get("q") {
+		case "220 Chatham Business Dr, Pittsboro, NC":
+			res.Write([]byte(`[{"place_id":1,"lat":"35.7174","lon":"-79.1619","display_name":"Chatham Business Drive"}]`))
+		case "Nowhere":
+			res.Write([]byte(`[]`))
+		default:
+			res.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	geocoder := NominatimGeocoder{URL: server.URL, UserAgent: "onmsctl-test"}
+
+	coordinates, err := geocoder.Geocode("220 Chatham Business Dr, Pittsboro, NC")
+	assert.NilError(t, err)
+	assert.Equal(t, "35.7174", coordinates.LatitudeString())
+	assert.Equal(t, "-79.1619", coordinates.LongitudeString())
+
+	_, err = geocoder.Geocode("Nowhere")
+	assert.Error(t, err, "Address Nowhere not found")
+	_, err = geocoder.Geocode("Bad")
+	assert.Error(t, err, "Cannot geocode Bad: 400 Bad Request")
+}
+
+func TestValidateCoordinates(t *testing.T) {
+	assert.NilError(t, Coordinates{-90, 180}.Validate())
+	assert.Error(t, Coordinates{90.5, 0}.Validate(), "Invalid latitude 90.5, it must be between -90 and 90")
+	assert.Error(t, Coordinates{0, -181}.Validate(), "Invalid longitude -181, it must be between -180 and 180")
+}
@@ -1,10 +1,15 @@
 package model
 
+// AlarmSeverities list of valid alarm severities
+var AlarmSeverities = EnumValue{
+	Enum: []string{"Indeterminate", "Cleared", "Normal", "Warning", "Minor", "Major", "Critical"},
+}
+
 // OnmsAlarm OpenNMS alarm entity
 type OnmsAlarm struct {
 	// Inherit from Events
-	ID                   int              `json:"id" yaml:"id"`
-	UEI                  string           `json:"uei" yaml:"uei"`
+	ID                   int              `json:"id" yaml:"id" required:"true"`
+	UEI                  string           `json:"uei" yaml:"uei" required:"true"`
 	EventTime            *Time            `json:"time,omitempty" yaml:"time,omitempty"`
 	EventHost            string           `json:"host,omitempty" yaml:"host,omitempty"`
 	EventSource          string           `json:"source,omitempty" yaml:"source,omitempty"`
@@ -16,7 +21,7 @@ type OnmsAlarm struct {
 	IPAddress            string           `json:"ipAddress,omitempty" yaml:"ipAddress,omitempty"`
 	ServiceType          OnmsServiceType  `json:"serviceType,omitempty" yaml:"serviceType,omitempty"`
 	IfIndex              int              `json:"ifIndex,omitempty" yaml:"ifIndex,omitempty"`
-	Severity             string           `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Severity             string           `json:"severity,omitempty" yaml:"severity,omitempty" required:"true"`
 	Log                  string           `json:"log,omitempty" yaml:"log,omitempty"`
 	LogGroup             string           `json:"logGroup,omitempty" yaml:"logGroup,omitempty"`
 	LogMessage           string           `json:"logMessage,omitempty" yaml:"logMessage,omitempty"`
@@ -54,6 +59,18 @@ type OnmsAlarm struct {
 	FirstEventTime        *Time      `json:"firstEventTime,omitempty" yaml:"firstEventTime,omitempty"`
 	LastEventTime         *Time      `json:"lastEventTime,omitempty" yaml:"lastEventTime,omitempty"`
 	LastEvent             *OnmsEvent `json:"lastEvent,omitempty" yaml:"-"`
+	StickyMemo            *OnmsMemo  `json:"stickyMemo,omitempty" yaml:"stickyMemo,omitempty"`
+	ReductionKeyMemo      *OnmsMemo  `json:"reductionKeyMemo,omitempty" yaml:"journalMemo,omitempty"`
+}
+
+// OnmsMemo a note attached to an alarm; the sticky memo belongs to the alarm,
+// while the journal memo belongs to its reduction key, so it is kept when the alarm is recreated
+type OnmsMemo struct {
+	ID      int    `json:"id,omitempty" yaml:"-"`
+	Body    string `json:"body" yaml:"body"`
+	Author  string `json:"author,omitempty" yaml:"author,omitempty"`
+	Created *Time  `json:"created,omitempty" yaml:"created,omitempty"`
+	Updated *Time  `json:"updated,omitempty" yaml:"updated,omitempty"`
 }
 
 // OnmsAlarmList a list of alarms
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"fmt"
+)
+
+// AssetField a field of the asset record of a node, as named on requisitions
+type AssetField struct {
+	Name     string `json:"name" yaml:"name"`
+	Category string `json:"category" yaml:"category"`
+}
+
+// AssetFields the asset fields that OpenNMS accepts on requisitions, grouped as on the asset page of the node
+var AssetFields = []AssetField{
+	{"description", "identification"},
+	{"category", "identification"},
+	{"manufacturer", "identification"},
+	{"modelNumber", "identification"},
+	{"serialNumber", "identification"},
+	{"assetNumber", "identification"},
+	{"dateInstalled", "identification"},
+	{"operatingSystem", "identification"},
+	{"state", "location"},
+	{"region", "location"},
+	{"address1", "location"},
+	{"address2", "location"},
+	{"city", "location"},
+	{"zip", "location"},
+	{"country", "location"},
+	{"longitude", "location"},
+	{"latitude", "location"},
+	{"division", "location"},
+	{"department", "location"},
+	{"building", "location"},
+	{"floor", "location"},
+	{"room", "location"},
+	{"rack", "location"},
+	{"rackunitheight", "location"},
+	{"slot", "location"},
+	{"port", "location"},
+	{"circuitId", "location"},
+	{"admin", "location"},
+	{"vendor", "vendor"},
+	{"vendorPhone", "vendor"},
+	{"vendorFax", "vendor"},
+	{"vendorAssetNumber", "vendor"},
+	{"supportPhone", "vendor"},
+	{"lease", "vendor"},
+	{"leaseExpires", "vendor"},
+	{"maintcontract", "vendor"},
+	{"maintContractExpiration", "vendor"},
+	{"cpu", "hardware"},
+	{"ram", "hardware"},
+	{"additionalhardware", "hardware"},
+	{"numpowersupplies", "hardware"},
+	{"inputpower", "hardware"},
+	{"storagectrl", "hardware"},
+	{"hdd1", "hardware"},
+	{"hdd2", "hardware"},
+	{"hdd3", "hardware"},
+	{"hdd4", "hardware"},
+	{"hdd5", "hardware"},
+	{"hdd6", "hardware"},
+	{"username", "authentication"},
+	{"password", "authentication"},
+	{"enable", "authentication"},
+	{"autoenable", "authentication"},
+	{"connection", "authentication"},
+	{"snmpcommunity", "authentication"},
+	{"displayCategory", "categories"},
+	{"notifyCategory", "categories"},
+	{"pollerCategory", "categories"},
+	{"thresholdCategory", "categories"},
+	{"vmwareManagedObjectId", "vmware"},
+	{"vmwareManagedEntityType", "vmware"},
+	{"vmwareManagementServer", "vmware"},
+	{"vmwareState", "vmware"},
+	{"vmwareTopologyInfo", "vmware"},
+	{"comment", "general"},
+	{"managedObjectType", "general"},
+	{"managedObjectInstance", "general"},
+}
+
+// AssetFieldNames returns the names of the known asset fields, followed by the extra ones
+func AssetFieldNames(extra ...string) []string {
+	names := make([]string, 0, len(AssetFields)+len(extra))
+	for _, f := range AssetFields {
+		names = append(names, f.Name)
+	}
+	return append(names, extra...)
+}
+
+// IsKnownAssetField returns true when the name is one of the known asset fields or of the extra ones;
+// names are case sensitive, as the server ignores the fields that don't match exactly
+func IsKnownAssetField(name string, extra ...string) bool {
+	for _, n := range AssetFieldNames(extra...) {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckName returns an error, with a suggestion when possible, if the asset is not a known field or one of the extra ones;
+// extra fields allow the ones added by a newer version of the server (e.x. Meridian) before this list is updated
+func (a RequisitionAsset) CheckName(extra ...string) error {
+	if IsKnownAssetField(a.Name, extra...) {
+		return nil
+	}
+	return fmt.Errorf("Unknown asset field %s%s", a.Name, DidYouMean(a.Name, AssetFieldNames(extra...)))
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// Every known asset field must be a property of the asset record, named as on the ReST API
+func TestAssetFieldsMatchAssetRecord(t *testing.T) {
+	properties := make(map[string]bool)
+	record := reflect.TypeOf(OnmsAssetRecord{})
+	for i := 0; i < record.NumField(); i++ {
+		properties[strings.Split(record.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	seen := make(map[string]bool)
+	for _, f := range AssetFields {
+		assert.Assert(t, properties[f.Name], "%s is not a property of the asset record", f.Name)
+		assert.Assert(t, !seen[f.Name], "%s is duplicated", f.Name)
+		assert.Assert(t, f.Category != "", "%s has no category", f.Name)
+		seen[f.Name] = true
+	}
+}
+
+func TestCheckAssetName(t *testing.T) {
+	assert.NilError(t, RequisitionAsset{Name: "serialNumber", Value: "ABC123"}.CheckName())
+	assert.Error(t, RequisitionAsset{Name: "serialnumber", Value: "ABC123"}.CheckName(), "Unknown asset field serialnumber; did you mean 'serialNumber'?")
+	assert.Error(t, RequisitionAsset{Name: "warrantyEnd", Value: "2030"}.CheckName(), "Unknown asset field warrantyEnd")
+	assert.NilError(t, RequisitionAsset{Name: "warrantyEnd", Value: "2030"}.CheckName("supportTier", "warrantyEnd"))
+	assert.Error(t, RequisitionAsset{Name: "warantyEnd", Value: "2030"}.CheckName("warrantyEnd"), "Unknown asset field warantyEnd; did you mean 'warrantyEnd'?")
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// BSMStatuses the operational statuses of business services
+var BSMStatuses = EnumValue{
+	Enum: []string{"Indeterminate", "Normal", "Warning", "Minor", "Major", "Critical"},
+}
+
+// BSMMapFunctions the functions to map the status of an edge
+var BSMMapFunctions = EnumValue{
+	Enum:    []string{"Identity", "Increase", "Decrease", "SetTo", "Ignore"},
+	Default: "Identity",
+}
+
+// BSMReduceFunctions the functions to reduce the statuses of the edges into the status of the business service
+var BSMReduceFunctions = EnumValue{
+	Enum:    []string{"HighestSeverity", "HighestSeverityAbove", "Threshold", "ExponentialPropagation"},
+	Default: "HighestSeverity",
+}
+
+// BSMFunction a map or reduce function with its properties
+type BSMFunction struct {
+	Type       string            `json:"type" yaml:"type"`
+	Properties map[string]string `json:"properties,omitempty" yaml:"properties,omitempty"`
+}
+
+// ValidateMap returns an error if the map function is invalid
+func (f *BSMFunction) ValidateMap() error {
+	if f.Type == "" {
+		f.Type = BSMMapFunctions.Default
+	}
+	if !BSMMapFunctions.Contains(f.Type) {
+		return fmt.Errorf("Invalid map function %s, valid options: %s", f.Type, BSMMapFunctions.EnumAsString())
+	}
+	if f.Type == "SetTo" && !BSMStatuses.Contains(f.Properties["status"]) {
+		return fmt.Errorf("The SetTo map function requires a status property, valid options: %s", BSMStatuses.EnumAsString())
+	}
+	return nil
+}
+
+// ValidateReduce returns an error if the reduce function is invalid
+func (f *BSMFunction) ValidateReduce() error {
+	if f.Type == "" {
+		f.Type = BSMReduceFunctions.Default
+	}
+	switch f.Type {
+	case "HighestSeverity":
+		return nil
+	case "HighestSeverityAbove":
+		if !BSMStatuses.Contains(f.Properties["threshold"]) {
+			return fmt.Errorf("The HighestSeverityAbove reduce function requires a threshold property, valid options: %s", BSMStatuses.EnumAsString())
+		}
+	case "Threshold":
+		threshold, err := strconv.ParseFloat(f.Properties["threshold"], 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("The Threshold reduce function requires a threshold property greater than 0 and lower or equal than 1")
+		}
+	case "ExponentialPropagation":
+		base, err := strconv.ParseFloat(f.Properties["base"], 64)
+		if err != nil || base <= 1 {
+			return fmt.Errorf("The ExponentialPropagation reduce function requires a base property greater than 1")
+		}
+	default:
+		return fmt.Errorf("Invalid reduce function %s, valid options: %s", f.Type, BSMReduceFunctions.EnumAsString())
+	}
+	return nil
+}
+
+// BSMIPService the monitored service of an IP service edge, as returned by the server
+type BSMIPService struct {
+	ID          int    `json:"id" yaml:"id"`
+	ServiceName string `json:"service-name,omitempty" yaml:"serviceName,omitempty"`
+	NodeLabel   string `json:"node-label,omitempty" yaml:"nodeLabel,omitempty"`
+	IPAddress   string `json:"ip-address,omitempty" yaml:"ipAddress,omitempty"`
+}
+
+// IPServiceEdge an edge to a monitored service
+type IPServiceEdge struct {
+	ID                int           `json:"id,omitempty" yaml:"id,omitempty"`
+	IPServiceID       int           `json:"ip-service-id,omitempty" yaml:"ipServiceID,omitempty"`
+	IPService         *BSMIPService `json:"ip-service,omitempty" yaml:"ipService,omitempty"`
+	MapFunction       BSMFunction   `json:"map-function" yaml:"mapFunction"`
+	Weight            int           `json:"weight" yaml:"weight"`
+	FriendlyName      string        `json:"friendly-name,omitempty" yaml:"friendlyName,omitempty"`
+	OperationalStatus string        `json:"operational-status,omitempty" yaml:"operationalStatus,omitempty"`
+}
+
+// Validate returns an error if the edge is invalid
+func (e *IPServiceEdge) Validate() error {
+	if e.IPServiceID == 0 && e.IPService != nil {
+		e.IPServiceID = e.IPService.ID
+	}
+	if e.IPServiceID <= 0 {
+		return fmt.Errorf("Valid IP service ID required")
+	}
+	return validateEdge(&e.MapFunction, &e.Weight)
+}
+
+// ReductionKeyEdge an edge to the alarms with a given reduction key
+type ReductionKeyEdge struct {
+	ID                int         `json:"id,omitempty" yaml:"id,omitempty"`
+	ReductionKey      string      `json:"reduction-key" yaml:"reductionKey"`
+	MapFunction       BSMFunction `json:"map-function" yaml:"mapFunction"`
+	Weight            int         `json:"weight" yaml:"weight"`
+	FriendlyName      string      `json:"friendly-name,omitempty" yaml:"friendlyName,omitempty"`
+	OperationalStatus string      `json:"operational-status,omitempty" yaml:"operationalStatus,omitempty"`
+}
+
+// Validate returns an error if the edge is invalid
+func (e *ReductionKeyEdge) Validate() error {
+	if e.ReductionKey == "" {
+		return fmt.Errorf("Reduction key required")
+	}
+	return validateEdge(&e.MapFunction, &e.Weight)
+}
+
+// ChildEdge an edge to another business service
+type ChildEdge struct {
+	ID                int         `json:"id,omitempty" yaml:"id,omitempty"`
+	ChildID           int         `json:"child-id" yaml:"childID"`
+	MapFunction       BSMFunction `json:"map-function" yaml:"mapFunction"`
+	Weight            int         `json:"weight" yaml:"weight"`
+	OperationalStatus string      `json:"operational-status,omitempty" yaml:"operationalStatus,omitempty"`
+}
+
+// Validate returns an error if the edge is invalid
+func (e *ChildEdge) Validate() error {
+	if e.ChildID <= 0 {
+		return fmt.Errorf("Valid child business service ID required")
+	}
+	return validateEdge(&e.MapFunction, &e.Weight)
+}
+
+func validateEdge(mapFunction *BSMFunction, weight *int) error {
+	if *weight == 0 {
+		*weight = 1
+	}
+	if *weight < 0 {
+		return fmt.Errorf("Weight cannot be negative")
+	}
+	return mapFunction.ValidateMap()
+}
+
+// BusinessService a business service with its edges
+type BusinessService struct {
+	ID                int                `json:"id,omitempty" yaml:"id,omitempty"`
+	Name              string             `json:"name" yaml:"name"`
+	Attributes        map[string]string  `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	ReduceFunction    BSMFunction        `json:"reduce-function" yaml:"reduceFunction"`
+	IPServiceEdges    []IPServiceEdge    `json:"ip-service-edges,omitempty" yaml:"ipServiceEdges,omitempty"`
+	ReductionKeyEdges []ReductionKeyEdge `json:"reduction-key-edges,omitempty" yaml:"reductionKeyEdges,omitempty"`
+	ChildEdges        []ChildEdge        `json:"child-edges,omitempty" yaml:"childEdges,omitempty"`
+	ParentServices    []int              `json:"parent-services,omitempty" yaml:"parentServices,omitempty"`
+	OperationalStatus string             `json:"operational-status,omitempty" yaml:"operationalStatus,omitempty"`
+}
+
+// Validate returns an error if the business service is invalid
+func (bs *BusinessService) Validate() error {
+	if bs.Name == "" {
+		return fmt.Errorf("Business service name cannot be empty")
+	}
+	if err := bs.ReduceFunction.ValidateReduce(); err != nil {
+		return err
+	}
+	for i := range bs.IPServiceEdges {
+		if err := bs.IPServiceEdges[i].Validate(); err != nil {
+			return fmt.Errorf("Invalid IP service edge: %w", err)
+		}
+	}
+	for i := range bs.ReductionKeyEdges {
+		if err := bs.ReductionKeyEdges[i].Validate(); err != nil {
+			return fmt.Errorf("Invalid reduction key edge: %w", err)
+		}
+	}
+	for i := range bs.ChildEdges {
+		if err := bs.ChildEdges[i].Validate(); err != nil {
+			return fmt.Errorf("Invalid child edge: %w", err)
+		}
+		if bs.ID != 0 && bs.ChildEdges[i].ChildID == bs.ID {
+			return fmt.Errorf("Business service %s cannot be a child of itself", bs.Name)
+		}
+	}
+	return nil
+}
+
+// EdgeCount returns the amount of edges of all types
+func (bs BusinessService) EdgeCount() int {
+	return len(bs.IPServiceEdges) + len(bs.ReductionKeyEdges) + len(bs.ChildEdges)
+}
+
+// BusinessServiceList the list of business services, as locations of each of them
+type BusinessServiceList struct {
+	Services []ResourceLocation `json:"business-services" yaml:"businessServices"`
+}
+
+// ResourceLocation the location of a resource on the ReST API
+type ResourceLocation struct {
+	Location string `json:"location" yaml:"location"`
+}
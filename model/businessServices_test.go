@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestBusinessServiceValidate(t *testing.T) {
+	bs := &BusinessService{}
+	assert.Error(t, bs.Validate(), "Business service name cannot be empty")
+
+	bs.Name = "Web Portal"
+	assert.NilError(t, bs.Validate())
+	assert.Equal(t, "HighestSeverity", bs.ReduceFunction.Type)
+
+	bs.ReduceFunction = BSMFunction{Type: "Threshold", Properties: map[string]string{"threshold": "1.5"}}
+	assert.ErrorContains(t, bs.Validate(), "requires a threshold property greater than 0")
+	bs.ReduceFunction.Properties["threshold"] = "0.75"
+	assert.NilError(t, bs.Validate())
+
+	bs.ReduceFunction = BSMFunction{Type: "Average"}
+	assert.ErrorContains(t, bs.Validate(), "Invalid reduce function Average")
+
+	bs.ReduceFunction = BSMFunction{Type: "HighestSeverityAbove", Properties: map[string]string{"threshold": "Minor"}}
+	assert.NilError(t, bs.Validate())
+
+	bs.IPServiceEdges = []IPServiceEdge{{IPService: &BSMIPService{ID: 10}}}
+	assert.NilError(t, bs.Validate())
+	assert.Equal(t, 10, bs.IPServiceEdges[0].IPServiceID)
+	assert.Equal(t, 1, bs.IPServiceEdges[0].Weight)
+	assert.Equal(t, "Identity", bs.IPServiceEdges[0].MapFunction.Type)
+
+	bs.ReductionKeyEdges = []ReductionKeyEdge{{ReductionKey: "uei.opennms.org/nodes/nodeDown::1", MapFunction: BSMFunction{Type: "SetTo"}}}
+	assert.ErrorContains(t, bs.Validate(), "Invalid reduction key edge: The SetTo map function requires a status property")
+	bs.ReductionKeyEdges[0].MapFunction.Properties = map[string]string{"status": "Major"}
+	assert.NilError(t, bs.Validate())
+
+	bs.ID = 3
+	bs.ChildEdges = []ChildEdge{{ChildID: 3}}
+	assert.Error(t, bs.Validate(), "Business service Web Portal cannot be a child of itself")
+	bs.ChildEdges[0].Weight = -1
+	assert.Error(t, bs.Validate(), "Invalid child edge: Weight cannot be negative")
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OnmsCategory an entity that represents an OpenNMS category, used by surveillance views, notifications and rules on the database side
+type OnmsCategory struct {
+	ID          int      `json:"id,omitempty" yaml:"id,omitempty"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Groups      []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// Validate verifies the category
+func (c *OnmsCategory) Validate() error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("Category name required")
+	}
+	if strings.ContainsAny(c.Name, "/;,") {
+		return fmt.Errorf("Invalid category name %s: it cannot contain '/', ';' or ','", c.Name)
+	}
+	return nil
+}
+
+// OnmsCategoryList a list of categories
+type OnmsCategoryList struct {
+	Count      int            `json:"count" yaml:"count"`
+	TotalCount int            `json:"totalCount" yaml:"totalCount"`
+	Offset     int            `json:"offset" yaml:"offset"`
+	Categories []OnmsCategory `json:"category" yaml:"categories"`
+}
+
+// GetCategory returns the category with the given name, or nil if it doesn't exist
+func (list OnmsCategoryList) GetCategory(name string) *OnmsCategory {
+	for i := range list.Categories {
+		if list.Categories[i].Name == name {
+			return &list.Categories[i]
+		}
+	}
+	return nil
+}
+
+// HasCategory verifies if the node belongs to the category with the given name
+func (n OnmsNode) HasCategory(name string) bool {
+	for _, c := range n.Categories {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCategoryNames returns the names of the categories of the node
+func (n OnmsNode) GetCategoryNames() []string {
+	names := make([]string, len(n.Categories))
+	for i, c := range n.Categories {
+		names[i] = c.Name
+	}
+	return names
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCategoryValidate(t *testing.T) {
+	category := &OnmsCategory{Name: " Routers "}
+	assert.NilError(t, category.Validate())
+	assert.Equal(t, "Routers", category.Name)
+
+	category = &OnmsCategory{}
+	assert.Error(t, category.Validate(), "Category name required")
+
+	category = &OnmsCategory{Name: "Core;Edge"}
+	assert.Error(t, category.Validate(), "Invalid category name Core;Edge: it cannot contain '/', ';' or ','")
+}
+
+func TestNodeCategories(t *testing.T) {
+	node := OnmsNode{ID: "1", Categories: []OnmsCategory{{ID: 1, Name: "Routers"}, {ID: 3, Name: "Production"}}}
+	assert.Assert(t, node.HasCategory("Production"))
+	assert.Assert(t, !node.HasCategory("Servers"))
+	assert.DeepEqual(t, []string{"Routers", "Production"}, node.GetCategoryNames())
+
+	list := OnmsCategoryList{Categories: node.Categories}
+	assert.Equal(t, 3, list.GetCategory("Production").ID)
+	assert.Assert(t, list.GetCategory("Servers") == nil)
+}
@@ -4,28 +4,40 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
-
-	"gopkg.in/yaml.v2"
 )
 
-// EnumValue a enumaration array of strings
+// EnumValue a enumaration array of strings;
+// when used as the value of a flag, the matching is case-insensitive, and the value is stored as declared on the enum
 type EnumValue struct {
 	Enum     []string
 	Default  string
 	selected string
 }
 
-// Set sets a value of the enum
+// NewEnum creates an enum with the given values, without a default value
+func NewEnum(values ...string) *EnumValue {
+	return &EnumValue{Enum: values}
+}
+
+// WithDefault sets the value returned when the enum has not been set
+func (e *EnumValue) WithDefault(value string) *EnumValue {
+	e.Default = value
+	return e
+}
+
+// Set sets a value of the enum, ignoring the case
 func (e *EnumValue) Set(value string) error {
 	for _, enum := range e.Enum {
-		if enum == value {
-			e.selected = value
+		if strings.EqualFold(enum, value) {
+			e.selected = enum
 			return nil
 		}
 	}
-	return fmt.Errorf("allowed values are %s", strings.Join(e.Enum, ", "))
+	return e.InvalidValueError(value)
 }
 
 // String gets the value of the enum as string
@@ -36,11 +48,27 @@ func (e EnumValue) String() string {
 	return e.selected
 }
 
+// Contains returns true if the value is part of the enum, without selecting it;
+// unlike Set, the case must match, as it is used to validate the content sent to the server
+func (e EnumValue) Contains(value string) bool {
+	for _, enum := range e.Enum {
+		if enum == value {
+			return true
+		}
+	}
+	return false
+}
+
 // EnumAsString gets a CSV with all the values on the enum
 func (e EnumValue) EnumAsString() string {
 	return strings.Join(e.Enum, ", ")
 }
 
+// InvalidValueError returns the error reported when a value is not part of the enum
+func (e EnumValue) InvalidValueError(value string) error {
+	return fmt.Errorf("Invalid value %s, valid options: %s", value, e.EnumAsString())
+}
+
 // Time an object to seamlessly manage times in multiple formats
 type Time struct {
 	time.Time
@@ -54,58 +82,146 @@ func (t Time) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.UnixNano() / int64(time.Millisecond))
 }
 
-// UnmarshalJSON converts timestamp in milliseconds into time object
+// UnmarshalJSON converts timestamp in milliseconds, or an RFC3339 string, into time object
 func (t *Time) UnmarshalJSON(data []byte) error {
-	var i int64
-	if err := json.Unmarshal(data, &i); err != nil {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
 		return err
 	}
-	sec := i / 1000
-	nsec := (i % 1000) * 1000
-	t.Time = time.Unix(sec, nsec)
-	return nil
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case float64:
+		t.Time = fromMillis(int64(v))
+		return nil
+	case string:
+		return t.parse(v)
+	}
+	return fmt.Errorf("Invalid time %s, expected milliseconds since the epoch or an RFC3339 string", string(data))
 }
 
-// MarshalYAML converts time object into time as string
-func (t Time) MarshalYAML() ([]byte, error) {
+// MarshalYAML converts time object into an RFC3339 string, keeping the milliseconds and the time zone
+func (t Time) MarshalYAML() (interface{}, error) {
 	if t.IsZero() {
-		return json.Marshal("")
+		return "", nil
 	}
-	return yaml.Marshal(t.String())
+	return t.Format(time.RFC3339Nano), nil
 }
 
-// UnmarshalYAML converts time string into time object
-func (t *Time) UnmarshalYAML(data []byte) error {
+// UnmarshalYAML converts an RFC3339 string, or timestamp in milliseconds, into time object
+func (t *Time) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var s string
-	var err error
-	if err = yaml.Unmarshal(data, &s); err != nil {
+	if err := unmarshal(&s); err != nil {
 		return err
 	}
-	t.Time, err = time.Parse(s, s)
+	return t.parse(s)
+}
+
+// Parses an RFC3339 string or timestamp in milliseconds; an empty string is the zero time
+func (t *Time) parse(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		t.Time = fromMillis(ms)
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
 	if err != nil {
-		return err
+		return fmt.Errorf("Invalid time %s, expected milliseconds since the epoch or an RFC3339 string", value)
 	}
+	t.Time = parsed
 	return nil
 }
 
+func fromMillis(ms int64) time.Time {
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
 // MarshalXML converts time object into time as string
 func (t Time) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	if t.IsZero() {
 		return e.EncodeElement("", start)
 	}
-	return e.EncodeElement(t.String(), start)
+	return e.EncodeElement(t.Format(time.RFC3339Nano), start)
 }
 
 // UnmarshalXML converts time string into time object
 func (t *Time) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var s string
-	var err error
 	if err := d.DecodeElement(&s, &start); err != nil {
 		return err
 	}
-	t.Time, err = time.Parse(s, s)
-	if err != nil {
-		return err
+	return t.parse(s)
+}
+
+// The offsets accepted by ParseHumanTime (e.x. -2h, now-1d, now+30m)
+var relativeTimePattern = regexp.MustCompile(`^(now)?\s*(?:([+-])\s*(\d+)\s*(ms|s|m|h|d|w))?$`)
+
+// The elapsed time accepted by ParseHumanTime (e.x. 2h ago, 1d 12h ago)
+var agoPattern = regexp.MustCompile(`^((?:\d+\s*(?:ms|s|m|h|d|w)\s*)+)ago$`)
+
+var agoPartPattern = regexp.MustCompile(`(\d+)\s*(ms|s|m|h|d|w)`)
+
+var timeUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+// The layouts of the local dates and times accepted by ParseHumanTime
+var localTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// ParseHumanTime parses a time relative to now (e.x. "now", "2h ago", "-30m", "now-1d"),
+// a date and time without zone (e.x. "2024-01-02 15:04", interpreted on the time zone of now),
+// an RFC3339 timestamp, or the milliseconds since the epoch (as returned by OpenNMS)
+func ParseHumanTime(input string, now time.Time) (time.Time, error) {
+	value := strings.ToLower(strings.TrimSpace(input))
+	if value == "" {
+		return time.Time{}, fmt.Errorf("Time cannot be empty")
 	}
-	return nil
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return fromMillis(ms), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, strings.ToUpper(value)); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.ToUpper(value), now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if match := agoPattern.FindStringSubmatch(value); match != nil {
+		var elapsed time.Duration
+		for _, part := range agoPartPattern.FindAllStringSubmatch(match[1], -1) {
+			amount, _ := strconv.Atoi(part[1])
+			elapsed += time.Duration(amount) * timeUnits[part[2]]
+		}
+		return now.Add(-elapsed), nil
+	}
+	match := relativeTimePattern.FindStringSubmatch(value)
+	if match == nil || (match[1] == "" && match[2] == "") {
+		return time.Time{}, fmt.Errorf("Invalid time %s; use now, an elapsed time like 2h ago, an offset like -2h or now-1d (units: ms, s, m, h, d, w), a local time like 2024-01-02 15:04, an RFC3339 timestamp or milliseconds since the epoch", input)
+	}
+	if match[2] == "" {
+		return now, nil
+	}
+	amount, _ := strconv.Atoi(match[3])
+	offset := time.Duration(amount) * timeUnits[match[4]]
+	if match[2] == "-" {
+		offset = -offset
+	}
+	return now.Add(offset), nil
 }
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+type timeHolder struct {
+	XMLName xml.Name `xml:"holder" json:"-" yaml:"-"`
+	When    *Time    `xml:"when" json:"when,omitempty" yaml:"when,omitempty"`
+}
+
+func TestTimeJSON(t *testing.T) {
+	expected := time.Date(2020, 1, 1, 10, 0, 0, 123000000, time.UTC)
+	for _, data := range []string{
+		`{"when":1577872800123}`,
+		`{"when":"1577872800123"}`,
+		`{"when":"2020-01-01T10:00:00.123Z"}`,
+		`{"when":"2020-01-01T05:00:00.123-05:00"}`,
+	} {
+		holder := timeHolder{}
+		assert.NilError(t, json.Unmarshal([]byte(data), &holder), data)
+		assert.Assert(t, expected.Equal(holder.When.Time), "%s: got %s", data, holder.When)
+	}
+
+	holder := timeHolder{}
+	assert.NilError(t, json.Unmarshal([]byte(`{"when":""}`), &holder))
+	assert.Assert(t, holder.When.IsZero())
+	assert.NilError(t, json.Unmarshal([]byte(`{"when":null}`), &holder))
+	assert.Assert(t, holder.When == nil || holder.When.IsZero())
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"when":"yesterday"}`), &holder), "Invalid time yesterday")
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"when":true}`), &holder), "Invalid time true")
+
+	data, err := json.Marshal(timeHolder{When: &Time{expected}})
+	assert.NilError(t, err)
+	assert.Equal(t, `{"when":1577872800123}`, string(data))
+}
+
+func TestTimeYAML(t *testing.T) {
+	zone := time.FixedZone("EST", -5*3600)
+	original := timeHolder{When: &Time{time.Date(2020, 1, 1, 5, 0, 0, 123000000, zone)}}
+	data, err := yaml.Marshal(original)
+	assert.NilError(t, err)
+	assert.Equal(t, "when: \"2020-01-01T05:00:00.123-05:00\"\n", string(data))
+
+	holder := timeHolder{}
+	assert.NilError(t, yaml.Unmarshal(data, &holder))
+	assert.Assert(t, original.When.Equal(holder.When.Time))
+	_, offset := holder.When.Zone()
+	assert.Equal(t, -5*3600, offset)
+
+	assert.NilError(t, yaml.Unmarshal([]byte("when: 1577872800123\n"), &holder))
+	assert.Assert(t, original.When.Equal(holder.When.Time))
+	assert.ErrorContains(t, yaml.Unmarshal([]byte("when: tomorrow\n"), &holder), "Invalid time tomorrow")
+}
+
+func TestTimeXML(t *testing.T) {
+	original := timeHolder{When: &Time{time.Date(2020, 1, 1, 10, 0, 0, 123000000, time.UTC)}}
+	data, err := xml.Marshal(original)
+	assert.NilError(t, err)
+	assert.Equal(t, "<holder><when>2020-01-01T10:00:00.123Z</when></holder>", string(data))
+	holder := timeHolder{}
+	assert.NilError(t, xml.Unmarshal(data, &holder))
+	assert.Assert(t, original.When.Equal(holder.When.Time))
+}
+
+func TestParseHumanTime(t *testing.T) {
+	zone := time.FixedZone("EST", -5*3600)
+	now := time.Date(2024, 1, 2, 15, 4, 0, 0, zone)
+	tests := map[string]time.Time{
+		"now":                           now,
+		" NOW ":                         now,
+		"2h ago":                        now.Add(-2 * time.Hour),
+		"2h  ago":                       now.Add(-2 * time.Hour),
+		"1d 12h ago":                    now.Add(-36 * time.Hour),
+		"1h30m ago":                     now.Add(-90 * time.Minute),
+		"500ms ago":                     now.Add(-500 * time.Millisecond),
+		"1w ago":                        now.Add(-7 * 24 * time.Hour),
+		"-30m":                          now.Add(-30 * time.Minute),
+		"now-1d":                        now.Add(-24 * time.Hour),
+		"now + 1h":                      now.Add(time.Hour),
+		"2024-01-01 08:30":              time.Date(2024, 1, 1, 8, 30, 0, 0, zone),
+		"2024-01-01 08:30:15":           time.Date(2024, 1, 1, 8, 30, 15, 0, zone),
+		"2024-01-01T08:30":              time.Date(2024, 1, 1, 8, 30, 0, 0, zone),
+		"2024-01-01":                    time.Date(2024, 1, 1, 0, 0, 0, 0, zone),
+		"2024-01-01T08:30:00Z":          time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC),
+		"2024-01-01t08:30:00z":          time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC),
+		"2024-01-01T08:30:00.250+01:00": time.Date(2024, 1, 1, 7, 30, 0, 250000000, time.UTC),
+		"1704225840000":                 now,
+	}
+	for value, expected := range tests {
+		parsed, err := ParseHumanTime(value, now)
+		assert.NilError(t, err, value)
+		assert.Assert(t, expected.Equal(parsed), "%s: expected %s, got %s", value, expected, parsed)
+	}
+
+	// Local times use the time zone of the reference
+	parsed, err := ParseHumanTime("2024-01-01 08:30", now.UTC())
+	assert.NilError(t, err)
+	assert.Equal(t, "2024-01-01T08:30:00Z", parsed.Format(time.RFC3339))
+
+	for _, value := range []string{"", "yesterday", "2h", "ago", "2y ago", "now-2y", "-h", "now-", "2024-13-01", "2024-01-01 25:00"} {
+		_, err := ParseHumanTime(value, now)
+		assert.Assert(t, err != nil, value)
+	}
+	_, err = ParseHumanTime("Tomorrow", now)
+	assert.ErrorContains(t, err, "Invalid time Tomorrow;")
+}
+
+func TestEnum(t *testing.T) {
+	enum := NewEnum("Minor", "Major", "Critical").WithDefault("Major")
+	assert.Equal(t, "Major", enum.String())
+	assert.NilError(t, enum.Set("CRITICAL"))
+	assert.Equal(t, "Critical", enum.String()) // Stored as declared
+	assert.NilError(t, enum.Set("minor"))
+	assert.Equal(t, "Minor", enum.String())
+	assert.Error(t, enum.Set("Warning"), "Invalid value Warning, valid options: Minor, Major, Critical")
+	assert.Equal(t, "Minor", enum.String())
+
+	assert.Assert(t, enum.Contains("Major"))
+	assert.Assert(t, !enum.Contains("major"))
+	assert.Equal(t, "", NewEnum("a", "b").String())
+}
@@ -0,0 +1,49 @@
+package model
+
+import "strings"
+
+// Daemon reload states reported by OpenNMS
+const (
+	DaemonReloadUnknown   = "Unknown"
+	DaemonReloadReloading = "Reloading"
+	DaemonReloadSuccess   = "Success"
+	DaemonReloadFailed    = "Failed"
+)
+
+// Daemon an OpenNMS daemon
+type Daemon struct {
+	Name       string `json:"name" yaml:"name"`
+	Internal   bool   `json:"internal" yaml:"internal"`
+	Enabled    bool   `json:"enabled" yaml:"enabled"`
+	Reloadable bool   `json:"reloadable" yaml:"reloadable"`
+}
+
+// DaemonReloadState the state of the last reload request of a daemon
+type DaemonReloadState struct {
+	RequestTime *Time  `json:"reloadRequestEventTime,omitempty" yaml:"requestTime,omitempty"`
+	ResultTime  *Time  `json:"reloadResultEventTime,omitempty" yaml:"resultTime,omitempty"`
+	State       string `json:"reloadState" yaml:"state"`
+}
+
+// IsFinished returns true if the daemon has reported the result of the reload
+func (s DaemonReloadState) IsFinished() bool {
+	return s.State == DaemonReloadSuccess || s.State == DaemonReloadFailed
+}
+
+// IsNewerThan returns true if the reload was requested after the given time
+func (s DaemonReloadState) IsNewerThan(t *Time) bool {
+	if s.RequestTime == nil {
+		return false
+	}
+	return t == nil || s.RequestTime.After(t.Time)
+}
+
+// FindDaemon returns the daemon with a given name, ignoring case, or nil if it doesn't exist
+func FindDaemon(daemons []Daemon, name string) *Daemon {
+	for i := range daemons {
+		if strings.EqualFold(daemons[i].Name, name) {
+			return &daemons[i]
+		}
+	}
+	return nil
+}
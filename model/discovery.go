@@ -0,0 +1,191 @@
+package model
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// DiscoveryConfiguration the content of discovery-configuration.xml
+type DiscoveryConfiguration struct {
+	XMLName          xml.Name       `xml:"discovery-configuration" json:"-" yaml:"-"`
+	PacketsPerSecond float64        `xml:"packets-per-second,attr,omitempty" json:"packets-per-second,omitempty" yaml:"packetsPerSecond,omitempty"`
+	InitialSleepTime int64          `xml:"initial-sleep-time,attr,omitempty" json:"initial-sleep-time,omitempty" yaml:"initialSleepTime,omitempty"`
+	RestartSleepTime int64          `xml:"restart-sleep-time,attr,omitempty" json:"restart-sleep-time,omitempty" yaml:"restartSleepTime,omitempty"`
+	Retries          int            `xml:"retries,attr,omitempty" json:"retries,omitempty" yaml:"retries,omitempty"`
+	Timeout          int64          `xml:"timeout,attr,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ForeignSource    string         `xml:"foreign-source,attr,omitempty" json:"foreign-source,omitempty" yaml:"foreignSource,omitempty"`
+	Location         string         `xml:"location,attr,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
+	ChunkSize        int            `xml:"chunk-size,attr,omitempty" json:"chunk-size,omitempty" yaml:"chunkSize,omitempty"`
+	Specifics        []Specific     `xml:"specific,omitempty" json:"specific,omitempty" yaml:"specifics,omitempty"`
+	IncludeRanges    []IncludeRange `xml:"include-range,omitempty" json:"include-range,omitempty" yaml:"includeRanges,omitempty"`
+	ExcludeRanges    []ExcludeRange `xml:"exclude-range,omitempty" json:"exclude-range,omitempty" yaml:"excludeRanges,omitempty"`
+	IncludeURLs      []IncludeURL   `xml:"include-url,omitempty" json:"include-url,omitempty" yaml:"includeURLs,omitempty"`
+}
+
+// Specific a single IP address to discover
+type Specific struct {
+	XMLName       xml.Name `xml:"specific" json:"-" yaml:"-"`
+	Address       string   `xml:",chardata" json:"content" yaml:"address"`
+	Location      string   `xml:"location,attr,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
+	Retries       int      `xml:"retries,attr,omitempty" json:"retries,omitempty" yaml:"retries,omitempty"`
+	Timeout       int64    `xml:"timeout,attr,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ForeignSource string   `xml:"foreign-source,attr,omitempty" json:"foreign-source,omitempty" yaml:"foreignSource,omitempty"`
+}
+
+// IncludeRange a range of IP addresses to discover
+type IncludeRange struct {
+	XMLName       xml.Name `xml:"include-range" json:"-" yaml:"-"`
+	Location      string   `xml:"location,attr,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
+	Retries       int      `xml:"retries,attr,omitempty" json:"retries,omitempty" yaml:"retries,omitempty"`
+	Timeout       int64    `xml:"timeout,attr,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ForeignSource string   `xml:"foreign-source,attr,omitempty" json:"foreign-source,omitempty" yaml:"foreignSource,omitempty"`
+	Begin         string   `xml:"begin" json:"begin" yaml:"begin"`
+	End           string   `xml:"end" json:"end" yaml:"end"`
+}
+
+// ExcludeRange a range of IP addresses to skip during discovery
+type ExcludeRange struct {
+	XMLName  xml.Name `xml:"exclude-range" json:"-" yaml:"-"`
+	Location string   `xml:"location,attr,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
+	Begin    string   `xml:"begin" json:"begin" yaml:"begin"`
+	End      string   `xml:"end" json:"end" yaml:"end"`
+}
+
+// IncludeURL a URL with a list of IP addresses to discover
+type IncludeURL struct {
+	XMLName       xml.Name `xml:"include-url" json:"-" yaml:"-"`
+	URL           string   `xml:",chardata" json:"content" yaml:"url"`
+	Location      string   `xml:"location,attr,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
+	Retries       int      `xml:"retries,attr,omitempty" json:"retries,omitempty" yaml:"retries,omitempty"`
+	Timeout       int64    `xml:"timeout,attr,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ForeignSource string   `xml:"foreign-source,attr,omitempty" json:"foreign-source,omitempty" yaml:"foreignSource,omitempty"`
+}
+
+// Validate returns an error if the discovery configuration is invalid
+func (cfg *DiscoveryConfiguration) Validate() error {
+	if cfg.Retries < 0 {
+		return fmt.Errorf("Retries cannot be negative")
+	}
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("Timeout cannot be negative")
+	}
+	for _, s := range cfg.Specifics {
+		if net.ParseIP(s.Address) == nil {
+			return fmt.Errorf("Invalid specific IP address %s", s.Address)
+		}
+	}
+	for _, r := range cfg.IncludeRanges {
+		if err := validateRange(r.Begin, r.End); err != nil {
+			return fmt.Errorf("Invalid include range: %w", err)
+		}
+	}
+	for _, r := range cfg.ExcludeRanges {
+		if err := validateRange(r.Begin, r.End); err != nil {
+			return fmt.Errorf("Invalid exclude range: %w", err)
+		}
+	}
+	for _, u := range cfg.IncludeURLs {
+		if parsed, err := url.Parse(u.URL); err != nil || parsed.Scheme == "" {
+			return fmt.Errorf("Invalid include URL %s", u.URL)
+		}
+	}
+	return nil
+}
+
+// AddSpecific adds or replaces a specific IP address
+func (cfg *DiscoveryConfiguration) AddSpecific(specific Specific) {
+	cfg.DeleteSpecific(specific.Address)
+	cfg.Specifics = append(cfg.Specifics, specific)
+}
+
+// DeleteSpecific removes a specific IP address; returns false if it doesn't exist
+func (cfg *DiscoveryConfiguration) DeleteSpecific(address string) bool {
+	for i, s := range cfg.Specifics {
+		if sameIP(s.Address, address) {
+			cfg.Specifics = append(cfg.Specifics[:i], cfg.Specifics[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AddIncludeRange adds or replaces an include range with the same boundaries
+func (cfg *DiscoveryConfiguration) AddIncludeRange(r IncludeRange) {
+	cfg.DeleteIncludeRange(r.Begin, r.End)
+	cfg.IncludeRanges = append(cfg.IncludeRanges, r)
+}
+
+// DeleteIncludeRange removes an include range; returns false if it doesn't exist
+func (cfg *DiscoveryConfiguration) DeleteIncludeRange(begin string, end string) bool {
+	for i, r := range cfg.IncludeRanges {
+		if sameIP(r.Begin, begin) && sameIP(r.End, end) {
+			cfg.IncludeRanges = append(cfg.IncludeRanges[:i], cfg.IncludeRanges[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AddExcludeRange adds or replaces an exclude range with the same boundaries
+func (cfg *DiscoveryConfiguration) AddExcludeRange(r ExcludeRange) {
+	cfg.DeleteExcludeRange(r.Begin, r.End)
+	cfg.ExcludeRanges = append(cfg.ExcludeRanges, r)
+}
+
+// DeleteExcludeRange removes an exclude range; returns false if it doesn't exist
+func (cfg *DiscoveryConfiguration) DeleteExcludeRange(begin string, end string) bool {
+	for i, r := range cfg.ExcludeRanges {
+		if sameIP(r.Begin, begin) && sameIP(r.End, end) {
+			cfg.ExcludeRanges = append(cfg.ExcludeRanges[:i], cfg.ExcludeRanges[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AddIncludeURL adds or replaces an include URL
+func (cfg *DiscoveryConfiguration) AddIncludeURL(u IncludeURL) {
+	cfg.DeleteIncludeURL(u.URL)
+	cfg.IncludeURLs = append(cfg.IncludeURLs, u)
+}
+
+// DeleteIncludeURL removes an include URL; returns false if it doesn't exist
+func (cfg *DiscoveryConfiguration) DeleteIncludeURL(u string) bool {
+	for i, item := range cfg.IncludeURLs {
+		if item.URL == u {
+			cfg.IncludeURLs = append(cfg.IncludeURLs[:i], cfg.IncludeURLs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func validateRange(begin string, end string) error {
+	b := net.ParseIP(begin)
+	if b == nil {
+		return fmt.Errorf("Invalid begin IP address %s", begin)
+	}
+	e := net.ParseIP(end)
+	if e == nil {
+		return fmt.Errorf("Invalid end IP address %s", end)
+	}
+	if (b.To4() == nil) != (e.To4() == nil) {
+		return fmt.Errorf("%s and %s must be on the same address family", begin, end)
+	}
+	if bytes.Compare(b.To16(), e.To16()) > 0 {
+		return fmt.Errorf("begin %s must be lower or equal than end %s", begin, end)
+	}
+	return nil
+}
+
+func sameIP(a string, b string) bool {
+	ipA := net.ParseIP(a)
+	ipB := net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a == b
+	}
+	return ipA.Equal(ipB)
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestDiscoveryConfigurationValidate(t *testing.T) {
+	cfg := DiscoveryConfiguration{}
+	assert.NilError(t, cfg.Validate())
+
+	cfg.AddSpecific(Specific{Address: "fe80::1"})
+	cfg.AddIncludeRange(IncludeRange{Begin: "10.0.0.1", End: "10.0.0.1"})
+	cfg.AddExcludeRange(ExcludeRange{Begin: "2001:db8::1", End: "2001:db8::ff"})
+	cfg.AddIncludeURL(IncludeURL{URL: "http://192.168.0.1/include.txt"})
+	assert.NilError(t, cfg.Validate())
+
+	cfg.AddSpecific(Specific{Address: "fe80:0::1", Location: "Durham"})
+	assert.Equal(t, 1, len(cfg.Specifics))
+	assert.Equal(t, "Durham", cfg.Specifics[0].Location)
+
+	cfg.AddExcludeRange(ExcludeRange{Begin: "2001:db8::ff", End: "2001:db8::1"})
+	assert.Error(t, cfg.Validate(), "Invalid exclude range: begin 2001:db8::ff must be lower or equal than end 2001:db8::1")
+	assert.Assert(t, cfg.DeleteExcludeRange("2001:db8::ff", "2001:db8::1"))
+	assert.Assert(t, !cfg.DeleteExcludeRange("2001:db8::ff", "2001:db8::1"))
+
+	cfg.AddIncludeRange(IncludeRange{Begin: "10.0.0.x", End: "10.0.0.1"})
+	assert.Error(t, cfg.Validate(), "Invalid include range: Invalid begin IP address 10.0.0.x")
+	assert.Assert(t, cfg.DeleteIncludeRange("10.0.0.x", "10.0.0.1"))
+
+	cfg.Retries = -1
+	assert.Error(t, cfg.Validate(), "Retries cannot be negative")
+}
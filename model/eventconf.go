@@ -0,0 +1,47 @@
+package model
+
+import "strings"
+
+// EventDefinition an event definition from the event configuration (eventconf) of the server
+type EventDefinition struct {
+	UEI      string `json:"uei" yaml:"uei"`
+	Label    string `json:"event-label" yaml:"label"`
+	Severity string `json:"severity" yaml:"severity"`
+}
+
+// EventDefinitionList a list of event definitions
+type EventDefinitionList struct {
+	Count  int               `json:"count" yaml:"count"`
+	Events []EventDefinition `json:"event" yaml:"events"`
+}
+
+// GetEvent returns the definition of a given UEI, or nil when it doesn't exist
+func (list EventDefinitionList) GetEvent(uei string) *EventDefinition {
+	for i := range list.Events {
+		if list.Events[i].UEI == uei {
+			return &list.Events[i]
+		}
+	}
+	return nil
+}
+
+// Filter returns the definitions whose UEI or label contain the given text (ignoring case)
+func (list EventDefinitionList) Filter(text string) []EventDefinition {
+	text = strings.ToLower(text)
+	events := make([]EventDefinition, 0)
+	for _, e := range list.Events {
+		if strings.Contains(strings.ToLower(e.UEI), text) || strings.Contains(strings.ToLower(e.Label), text) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// UEIs returns the UEIs of the definitions
+func (list EventDefinitionList) UEIs() []string {
+	ueis := make([]string, len(list.Events))
+	for i, e := range list.Events {
+		ueis[i] = e.UEI
+	}
+	return ueis
+}
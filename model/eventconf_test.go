@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestEventDefinitionList(t *testing.T) {
+	list := EventDefinitionList{
+		Count: 2,
+		Events: []EventDefinition{
+			{UEI: "uei.opennms.org/nodes/nodeDown", Label: "OpenNMS-defined node event: nodeDown", Severity: "Major"},
+			{UEI: "uei.opennms.org/custom/backupFailed", Label: "Custom: Backup Failed", Severity: "Minor"},
+		},
+	}
+	assert.Equal(t, "Major", list.GetEvent("uei.opennms.org/nodes/nodeDown").Severity)
+	assert.Assert(t, list.GetEvent("uei.opennms.org/nodes/nodeUp") == nil)
+	assert.DeepEqual(t, list.Events[1:], list.Filter("BACKUP"))
+	assert.DeepEqual(t, list.Events[:1], list.Filter("node event"))
+	assert.Equal(t, 0, len(list.Filter("trap")))
+	assert.DeepEqual(t, []string{"uei.opennms.org/nodes/nodeDown", "uei.opennms.org/custom/backupFailed"}, list.UEIs())
+}
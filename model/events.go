@@ -1,8 +1,12 @@
 package model
 
 import (
+	"encoding/base64"
+	"encoding/xml"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,40 +15,138 @@ var (
 	Severities = EnumValue{
 		Enum: []string{"Indeterminate", "Normal", "Warning", "Minor", "Major", "Critical"},
 	}
+
+	// EventParamTypes list of valid event parameter types
+	EventParamTypes = EnumValue{
+		Enum:    []string{"string", "int", "timestamp"},
+		Default: "string",
+	}
+
+	// EventParamEncodings list of valid encodings for the values of event parameters
+	EventParamEncodings = EnumValue{
+		Enum:    []string{"text", "base64"},
+		Default: "text",
+	}
 )
 
 // SNMP an event SNMP object
 type SNMP struct {
-	ID        string `json:"id" yaml:"id"`
-	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
-	Specific  int    `json:"specific" yaml:"specific,omitempty"`
-	Generic   int    `json:"generic" yaml:"generic,omitempty"`
-	Community string `json:"community,omitempty" yaml:"community,omitempty"`
-	Timestamp *Time  `json:"time-stamp,omitempty" yaml:"timeStamp,omitempty"`
+	ID        string `json:"id" yaml:"id" xml:"id"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty" xml:"version,omitempty"`
+	Specific  int    `json:"specific" yaml:"specific,omitempty" xml:"specific"`
+	Generic   int    `json:"generic" yaml:"generic,omitempty" xml:"generic"`
+	Community string `json:"community,omitempty" yaml:"community,omitempty" xml:"community,omitempty"`
+	Timestamp *Time  `json:"time-stamp,omitempty" yaml:"timeStamp,omitempty" xml:"-"` // The XML format uses milliseconds
 }
 
 // EventParam an event parameter object
 type EventParam struct {
-	Name  string `json:"parmName" yaml:"name"`
-	Value string `json:"value" yaml:"value"`
+	Name     string `json:"parmName" yaml:"name"`
+	Value    string `json:"value" yaml:"value"`
+	Type     string `json:"type,omitempty" yaml:"type,omitempty"`
+	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"` // text (when empty) or base64
+}
+
+// The XML representation of an event parameter, where the type is an attribute of the value
+type xmlEventParam struct {
+	XMLName xml.Name `xml:"parm"`
+	Name    string   `xml:"parmName"`
+	Value   struct {
+		Type     string `xml:"type,attr,omitempty"`
+		Encoding string `xml:"encoding,attr,omitempty"`
+		Content  string `xml:",chardata"`
+	} `xml:"value"`
+}
+
+// The XML representation of an event parameter whose value contains markup (e.x. XML or HTML), wrapped in CDATA
+type xmlEventParamCDATA struct {
+	XMLName xml.Name `xml:"parm"`
+	Name    string   `xml:"parmName"`
+	Value   struct {
+		Type     string `xml:"type,attr,omitempty"`
+		Encoding string `xml:"encoding,attr,omitempty"`
+		Content  string `xml:",cdata"`
+	} `xml:"value"`
+}
+
+// MarshalXML converts the parameter into its XML representation
+func (p EventParam) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	encoding := p.Encoding
+	if encoding == "" {
+		encoding = EventParamEncodings.Default
+	}
+	if strings.ContainsAny(p.Value, "<>&") {
+		parm := xmlEventParamCDATA{Name: p.Name}
+		parm.Value.Type = p.Type
+		parm.Value.Encoding = encoding
+		parm.Value.Content = p.Value
+		return e.Encode(parm)
+	}
+	parm := xmlEventParam{Name: p.Name}
+	parm.Value.Type = p.Type
+	parm.Value.Encoding = encoding
+	parm.Value.Content = p.Value
+	return e.Encode(parm)
+}
+
+// UnmarshalXML converts the XML representation into a parameter (CDATA sections are taken as text)
+func (p *EventParam) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	parm := xmlEventParam{}
+	if err := d.DecodeElement(&parm, &start); err != nil {
+		return err
+	}
+	p.Name = parm.Name
+	p.Value = parm.Value.Content
+	p.Type = parm.Value.Type
+	if parm.Value.Encoding != EventParamEncodings.Default {
+		p.Encoding = parm.Value.Encoding
+	}
+	return nil
+}
+
+// Validate returns an error if the parameter is invalid
+func (p EventParam) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("Parameter name cannot be empty")
+	}
+	if p.Encoding != "" {
+		if !EventParamEncodings.Contains(p.Encoding) {
+			return fmt.Errorf("Invalid encoding %s for parameter %s, valid options: %s", p.Encoding, p.Name, EventParamEncodings.EnumAsString())
+		}
+		if _, err := base64.StdEncoding.DecodeString(p.Value); p.Encoding == "base64" && err != nil {
+			return fmt.Errorf("Invalid base64 value for parameter %s: %w", p.Name, err)
+		}
+	}
+	if p.Type == "" {
+		return nil
+	}
+	if !EventParamTypes.Contains(p.Type) {
+		return fmt.Errorf("Invalid type %s for parameter %s, valid options: %s", p.Type, p.Name, EventParamTypes.EnumAsString())
+	}
+	if p.Type == "int" {
+		if _, err := strconv.ParseInt(p.Value, 10, 64); err != nil {
+			return fmt.Errorf("Invalid integer value %s for parameter %s", p.Value, p.Name)
+		}
+	}
+	return nil
 }
 
 // MaskElement an event mask element object
 type MaskElement struct {
-	Name   string   `json:"mename" yaml:"mename"`
-	Values []string `json:"mevalue" yaml:"mevalue"`
+	Name   string   `json:"mename" yaml:"mename" xml:"mename"`
+	Values []string `json:"mevalue" yaml:"mevalue" xml:"mevalue"`
 }
 
 // Mask an event mask object
 type Mask struct {
-	Elements []MaskElement `json:"maskelement,omitempty" yaml:"maskElement,omitempty"`
+	Elements []MaskElement `json:"maskelement,omitempty" yaml:"maskElement,omitempty" xml:"maskelement"`
 }
 
 // LogMsg the event log message
 type LogMsg struct {
-	Message     string `json:"value" yaml:"message"`
-	Notify      bool   `json:"notify" yaml:"notify"`
-	Destination string `json:"dest" yaml:"destination"`
+	Message     string `json:"value" yaml:"message" xml:",chardata"`
+	Notify      bool   `json:"notify" yaml:"notify" xml:"notify,attr"`
+	Destination string `json:"dest" yaml:"destination" xml:"dest,attr"`
 }
 
 // Validate returns an error if the log message is invalid
@@ -61,24 +163,30 @@ func (lm *LogMsg) Validate() error {
 // Event an event object
 // Time uses a string format. Example: "Saturday, July 13, 2019 2:13:43 PM GMT"
 type Event struct {
-	SnmpMask      *Mask        `json:"mask,omitempty" json:"mask,omitempty"`
-	Snmp          *SNMP        `json:"snmp,omitempty" json:"snmp,omitempty"`
-	LogMessage    *LogMsg      `json:"logmsg,omitempty" json:"logmsg,omitempty"`
-	UEI           string       `json:"uei" yaml:"uei"`
-	Source        string       `json:"source" yaml:"source"`
-	Time          string       `json:"time,omitempty" yaml:"time,omitempty"`
-	Host          string       `json:"host,omitempty" yaml:"host,omitempty"`
-	MasterStation string       `json:"master-station,omitempty" yaml:"masterStation,omitempty"`
-	NodeID        int64        `json:"nodeid,omitempty" yaml:"nodeID,omitempty"`
-	Interface     string       `json:"interface,omitempty" yaml:"interface,omitempty"`
-	Service       string       `json:"service,omitempty" yaml:"service,omitempty"`
-	IfIndex       int          `json:"ifindex,omitempty" yaml:"ifIndex,omitempty"`
-	SnmpHost      string       `json:"snmphost,omitempty" yaml:"snmpHost,omitempty"`
-	Parameters    []EventParam `json:"parms,omitempty" yaml:"parameters,omitempty"`
-	Description   string       `json:"descr,omitempty" yaml:"description,omitempty"`
-	Severity      string       `json:"severity,omitempty" yaml:"severity,omitempty"`
-	PathOutage    string       `json:"pathoutage,omitempty" yaml:"pathOutage,omitempty"`
-	OperInstruct  string       `json:"operinstruct,omitempty" yaml:"operInstruct,omitempty"`
+	SnmpMask      *Mask        `json:"mask,omitempty" json:"mask,omitempty" xml:"mask,omitempty"`
+	Snmp          *SNMP        `json:"snmp,omitempty" json:"snmp,omitempty" xml:"snmp,omitempty"`
+	LogMessage    *LogMsg      `json:"logmsg,omitempty" json:"logmsg,omitempty" xml:"logmsg,omitempty"`
+	UEI           string       `json:"uei" yaml:"uei" xml:"uei"`
+	Source        string       `json:"source" yaml:"source" xml:"source,omitempty"`
+	Time          string       `json:"time,omitempty" yaml:"time,omitempty" xml:"time,omitempty"`
+	Host          string       `json:"host,omitempty" yaml:"host,omitempty" xml:"host,omitempty"`
+	MasterStation string       `json:"master-station,omitempty" yaml:"masterStation,omitempty" xml:"master-station,omitempty"`
+	NodeID        int64        `json:"nodeid,omitempty" yaml:"nodeID,omitempty" xml:"nodeid,omitempty"`
+	Interface     string       `json:"interface,omitempty" yaml:"interface,omitempty" xml:"interface,omitempty"`
+	Service       string       `json:"service,omitempty" yaml:"service,omitempty" xml:"service,omitempty"`
+	IfIndex       int          `json:"ifindex,omitempty" yaml:"ifIndex,omitempty" xml:"ifIndex,omitempty"`
+	SnmpHost      string       `json:"snmphost,omitempty" yaml:"snmpHost,omitempty" xml:"snmphost,omitempty"`
+	Parameters    []EventParam `json:"parms,omitempty" yaml:"parameters,omitempty" xml:"parms>parm,omitempty"`
+	Description   string       `json:"descr,omitempty" yaml:"description,omitempty" xml:"descr,omitempty"`
+	Severity      string       `json:"severity,omitempty" yaml:"severity,omitempty" xml:"severity,omitempty"`
+	PathOutage    string       `json:"pathoutage,omitempty" yaml:"pathOutage,omitempty" xml:"pathoutage,omitempty"`
+	OperInstruct  string       `json:"operinstruct,omitempty" yaml:"operInstruct,omitempty" xml:"operinstruct,omitempty"`
+}
+
+// EventLog the XML representation of a list of events, as consumed by OpenNMS from a message broker
+type EventLog struct {
+	XMLName xml.Name `xml:"http://xmlns.opennms.org/xsd/event log"`
+	Events  []Event  `xml:"events>event"`
 }
 
 // AddParameter adds a new parameter to the event
@@ -86,6 +194,11 @@ func (e *Event) AddParameter(key string, value string) {
 	e.Parameters = append(e.Parameters, EventParam{Name: key, Value: value})
 }
 
+// AddTypedParameter adds a new parameter with an explicit type to the event
+func (e *Event) AddTypedParameter(key string, value string, paramType string) {
+	e.Parameters = append(e.Parameters, EventParam{Name: key, Value: value, Type: paramType})
+}
+
 // SetTime sets the string date based on a Time object
 func (e *Event) SetTime(date time.Time) {
 	d := date.UTC()
@@ -117,8 +230,11 @@ func (e Event) Validate() error {
 			return fmt.Errorf("Invalid Interface: %s", e.Interface)
 		}
 	}
-	if e.Severity != "" {
-		if err := Severities.Set(e.Severity); err != nil {
+	if e.Severity != "" && !Severities.Contains(e.Severity) {
+		return fmt.Errorf("Invalid severity %s, valid options: %s", e.Severity, Severities.EnumAsString())
+	}
+	for _, p := range e.Parameters {
+		if err := p.Validate(); err != nil {
 			return err
 		}
 	}
@@ -1,10 +1,14 @@
 package model
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"gopkg.in/yaml.v2"
 	"gotest.tools/assert"
 )
 
@@ -19,3 +23,99 @@ func TestSetTime(t *testing.T) {
 	fmt.Println(e.Time)
 	assert.Equal(t, "Monday, January 2, 2006 10:04:05 PM GMT", e.Time)
 }
+
+func TestEventParamSerialization(t *testing.T) {
+	e := Event{UEI: "uei.opennms.org/test"}
+	e.AddParameter("url", "http://host/?a=b")
+	e.AddTypedParameter("count", "5", "int")
+	assert.NilError(t, e.Validate())
+
+	bytes, err := json.Marshal(e.Parameters)
+	assert.NilError(t, err)
+	assert.Equal(t, `[{"parmName":"url","value":"http://host/?a=b"},{"parmName":"count","value":"5","type":"int"}]`, string(bytes))
+
+	bytes, err = xml.Marshal(e.Parameters[1])
+	assert.NilError(t, err)
+	assert.Equal(t, `<parm><parmName>count</parmName><value type="int" encoding="text">5</value></parm>`, string(bytes))
+	param := EventParam{}
+	assert.NilError(t, xml.Unmarshal(bytes, &param))
+	assert.DeepEqual(t, e.Parameters[1], param)
+
+	e.AddTypedParameter("when", "now", "date")
+	assert.ErrorContains(t, e.Validate(), "Invalid type date for parameter when")
+}
+
+func TestEventLog(t *testing.T) {
+	log := EventLog{
+		Events: []Event{
+			{
+				UEI:        "uei.opennms.org/test",
+				Source:     "onmsctl",
+				NodeID:     1,
+				Interface:  "10.0.0.1",
+				LogMessage: &LogMsg{Message: "Test event", Destination: "logndisplay"},
+				Parameters: []EventParam{{Name: "owner", Value: "agalue"}},
+			},
+		},
+	}
+	data, err := xml.Marshal(log)
+	assert.NilError(t, err)
+	assert.Equal(t, `<log xmlns="http://xmlns.opennms.org/xsd/event"><events><event><logmsg notify="false" dest="logndisplay">Test event</logmsg><uei>uei.opennms.org/test</uei><source>onmsctl</source><nodeid>1</nodeid><interface>10.0.0.1</interface><parms><parm><parmName>owner</parmName><value encoding="text">agalue</value></parm></parms></event></events></log>`, string(data))
+
+	parsed := EventLog{}
+	assert.NilError(t, xml.Unmarshal(data, &parsed))
+	assert.DeepEqual(t, log.Events, parsed.Events)
+}
+
+func TestEventParamMarkup(t *testing.T) {
+	param := EventParam{Name: "payload", Value: "<alert severity=\"major\">disk & cpu</alert>"}
+	bytes, err := xml.Marshal(param)
+	assert.NilError(t, err)
+	assert.Equal(t, `<parm><parmName>payload</parmName><value encoding="text"><![CDATA[<alert severity="major">disk & cpu</alert>]]></value></parm>`, string(bytes))
+	parsed := EventParam{}
+	assert.NilError(t, xml.Unmarshal(bytes, &parsed))
+	assert.DeepEqual(t, param, parsed)
+
+	// The end of a CDATA section within the value is split into multiple sections
+	param.Value = "<a>]]></a>"
+	bytes, err = xml.Marshal(param)
+	assert.NilError(t, err)
+	parsed = EventParam{}
+	assert.NilError(t, xml.Unmarshal(bytes, &parsed))
+	assert.Equal(t, param.Value, parsed.Value)
+
+	param = EventParam{Name: "blob", Value: "AAEC/w==", Encoding: "base64"}
+	assert.NilError(t, param.Validate())
+	bytes, err = xml.Marshal(param)
+	assert.NilError(t, err)
+	assert.Equal(t, `<parm><parmName>blob</parmName><value encoding="base64">AAEC/w==</value></parm>`, string(bytes))
+	parsed = EventParam{}
+	assert.NilError(t, xml.Unmarshal(bytes, &parsed))
+	assert.DeepEqual(t, param, parsed)
+
+	param.Value = "not base64!"
+	assert.ErrorContains(t, param.Validate(), "Invalid base64 value for parameter blob")
+	param.Encoding = "hex"
+	assert.ErrorContains(t, param.Validate(), "Invalid encoding hex for parameter blob")
+}
+
+func TestEventMultilineJSONRoundTrip(t *testing.T) {
+	payload := "{\n  \"service\": \"HTTP\",\n  \"url\": \"http://host/?a=b&c=d\",\n  \"body\": \"<html></html>\"\n}\n"
+	event := Event{}
+	assert.NilError(t, yaml.Unmarshal([]byte("uei: uei.opennms.org/test\nparameters:\n- name: payload\n  value: |\n    {\n      \"service\": \"HTTP\",\n      \"url\": \"http://host/?a=b&c=d\",\n      \"body\": \"<html></html>\"\n    }\n"), &event))
+	assert.NilError(t, event.Validate())
+	assert.Equal(t, payload, event.Parameters[0].Value)
+
+	data, err := xml.Marshal(EventLog{Events: []Event{event}})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(data), "<![CDATA["), string(data))
+	parsed := EventLog{}
+	assert.NilError(t, xml.Unmarshal(data, &parsed))
+	assert.Equal(t, payload, parsed.Events[0].Parameters[0].Value)
+
+	data, err = json.Marshal(event)
+	assert.NilError(t, err)
+	decoded := Event{}
+	assert.NilError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, payload, decoded.Parameters[0].Value)
+}
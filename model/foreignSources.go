@@ -33,6 +33,9 @@ func (p *Detector) Validate() error {
 	if p.Name == "" {
 		return fmt.Errorf("Detector name cannot be empty")
 	}
+	if matched, _ := regexp.MatchString(`[/\\?:&*'"]`, p.Name); matched {
+		return fmt.Errorf("Invalid characters on detector name %s:, /, \\, ?, &, *, ', \"", p.Name)
+	}
 	if p.Class == "" {
 		return fmt.Errorf("Detector class cannot be empty")
 	}
@@ -52,6 +55,9 @@ func (p *Policy) Validate() error {
 	if p.Name == "" {
 		return fmt.Errorf("Policy name cannot be empty")
 	}
+	if matched, _ := regexp.MatchString(`[/\\?:&*'"]`, p.Name); matched {
+		return fmt.Errorf("Invalid characters on policy name %s:, /, \\, ?, &, *, ', \"", p.Name)
+	}
 	if p.Class == "" {
 		return fmt.Errorf("Policy class cannot be empty")
 	}
@@ -145,7 +151,7 @@ func (p Plugin) VerifyParameters(parameters []Parameter) error {
 	for _, param := range parameters {
 		config := p.FindParameter(param.Key)
 		if config == nil {
-			return fmt.Errorf("Invalid parameter %s for %s", param.Key, p.Class)
+			return fmt.Errorf("Invalid parameter %s for %s%s", param.Key, p.Class, p.suggestParameter(param.Key))
 		}
 	}
 	for _, param := range p.Parameters {
@@ -171,6 +177,21 @@ func (p Plugin) VerifyParameters(parameters []Parameter) error {
 	return nil
 }
 
+// Returns a hint with the closest parameter to an unknown key, or the list of valid parameters
+func (p Plugin) suggestParameter(key string) string {
+	keys := make([]string, len(p.Parameters))
+	for i, param := range p.Parameters {
+		keys[i] = param.Key
+	}
+	if hint := DidYouMean(key, keys); hint != "" {
+		return hint
+	}
+	if len(keys) == 0 {
+		return "; it doesn't accept parameters"
+	}
+	return "; valid parameters: " + strings.Join(keys, ", ")
+}
+
 // PluginParam a parameter of a given plugin
 type PluginParam struct {
 	Key      string   `json:"key" yaml:"key"`
@@ -184,6 +205,15 @@ type PluginList struct {
 	Plugins []Plugin `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
+// SuggestClass returns a hint with the closest plugin class to an unknown one
+func (list PluginList) SuggestClass(cls string) string {
+	classes := make([]string, len(list.Plugins))
+	for i, p := range list.Plugins {
+		classes[i] = p.Class
+	}
+	return DidYouMean(cls, classes)
+}
+
 // FindPlugin finds a plugin by class name
 func (list PluginList) FindPlugin(cls string) *Plugin {
 	for _, p := range list.Plugins {
@@ -49,6 +49,14 @@ func TestForeignSourceObject(t *testing.T) {
 	fsDef.ScanInterval = "2w 1d"
 	assert.NilError(t, fsDef.Validate())
 
+	fsDef.Detectors[0].Name = "ICMP/v6"
+	assert.ErrorContains(t, fsDef.Validate(), "Invalid characters on detector name")
+	fsDef.Detectors[0].Name = "ICMP"
+
+	fsDef.Policies[0].Name = "Prod?"
+	assert.ErrorContains(t, fsDef.Validate(), "Invalid characters on policy name")
+	fsDef.Policies[0].Name = "Production"
+
 	bytes, err := json.MarshalIndent(fsDef, "", "  ")
 	assert.NilError(t, err)
 	fmt.Println(string(bytes))
@@ -0,0 +1,252 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PrefabGraph a graph definition of the server (e.x. mib2.HCbits), based on an RRDtool graph command
+type PrefabGraph struct {
+	Name        string   `json:"name" yaml:"name"`
+	Title       string   `json:"title" yaml:"title"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Columns     []string `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Types       []string `json:"types,omitempty" yaml:"types,omitempty"`
+	Command     string   `json:"command" yaml:"command"`
+	Order       int      `json:"order,omitempty" yaml:"order,omitempty"`
+	Width       *int     `json:"width,omitempty" yaml:"width,omitempty"`
+	Height      *int     `json:"height,omitempty" yaml:"height,omitempty"`
+}
+
+// GraphNameList the names of the graphs available for a resource
+type GraphNameList struct {
+	Names []string `json:"name" yaml:"names"`
+}
+
+// UnmarshalJSON accepts the wrapped list, a plain array of names, and a single name (as the server doesn't wrap lists with one element)
+func (list *GraphNameList) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		return json.Unmarshal(data, &list.Names)
+	}
+	wrapper := struct {
+		Names json.RawMessage `json:"name"`
+	}{}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return err
+	}
+	list.Names = []string{}
+	if len(wrapper.Names) == 0 {
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(wrapper.Names, &name); err == nil {
+		list.Names = append(list.Names, name)
+		return nil
+	}
+	return json.Unmarshal(wrapper.Names, &list.Names)
+}
+
+// Contains returns true when the list includes the given graph
+func (list GraphNameList) Contains(name string) bool {
+	for _, n := range list.Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GraphSeries a line or area drawn by a graph definition
+type GraphSeries struct {
+	Label  string `json:"label" yaml:"label"`                       // The source or expression with the values
+	Legend string `json:"legend,omitempty" yaml:"legend,omitempty"` // The text displayed on the legend
+	Color  string `json:"color" yaml:"color"`                       // As #rrggbb or #rrggbbaa
+	Type   string `json:"type" yaml:"type"`                         // LINE, AREA or STACK
+	Width  int    `json:"width,omitempty" yaml:"width,omitempty"`   // Of lines in pixels
+}
+
+// GraphDefinition what a prefab graph fetches through the Measurements API, and how it is drawn
+type GraphDefinition struct {
+	Title         string            `json:"title" yaml:"title"`
+	VerticalLabel string            `json:"verticalLabel,omitempty" yaml:"verticalLabel,omitempty"`
+	Sources       []QuerySource     `json:"sources" yaml:"sources"`
+	Expressions   []QueryExpression `json:"expressions,omitempty" yaml:"expressions,omitempty"`
+	Series        []GraphSeries     `json:"series" yaml:"series"`
+}
+
+// QueryRequest returns the measurements request for the sources and expressions of the definition on a given resource;
+// times are in milliseconds
+func (def GraphDefinition) QueryRequest(resourceID string, start int64, end int64, step int64) QueryRequest {
+	request := QueryRequest{Start: start, End: end, Step: step, Relaxed: true}
+	for _, s := range def.Sources {
+		s.ResourceID = resourceID
+		request.Sources = append(request.Sources, s)
+	}
+	request.Expressions = append(request.Expressions, def.Expressions...)
+	return request
+}
+
+// Splits the graph command in tokens, keeping quoted text (e.x. legends with spaces) together
+var graphTokenPattern = regexp.MustCompile(`(?:[^\s"]+|"(?:[^"\\]|\\.)*")+`)
+
+// The RPN operators of RRDtool that have a JEXL equivalent, and how many operands they take
+var rpnOperators = map[string]struct {
+	operands int
+	format   string
+}{
+	"+":   {2, "(%s + %s)"},
+	"-":   {2, "(%s - %s)"},
+	"*":   {2, "(%s * %s)"},
+	"/":   {2, "(%s / %s)"},
+	"%":   {2, "(%s %% %s)"},
+	"LT":  {2, "(%s < %s)"},
+	"LE":  {2, "(%s <= %s)"},
+	"GT":  {2, "(%s > %s)"},
+	"GE":  {2, "(%s >= %s)"},
+	"EQ":  {2, "(%s == %s)"},
+	"NE":  {2, "(%s != %s)"},
+	"MIN": {2, "math:min(%s, %s)"},
+	"MAX": {2, "math:max(%s, %s)"},
+	"ABS": {1, "math:abs(%s)"},
+	"UN":  {1, "(%[1]s != %[1]s)"}, // Only NaN is not equal to itself
+	"IF":  {3, "(%s ? %s : %s)"},
+}
+
+// Definition parses the graph command, converting the DEF entries into sources, the CDEF entries into JEXL expressions,
+// and the LINE, AREA and STACK entries into series; the rest of the entries (e.x. GPRINT or VDEF) are ignored
+func (g PrefabGraph) Definition() (*GraphDefinition, error) {
+	def := &GraphDefinition{Title: g.Title}
+	labels := make(map[string]bool)
+	for _, token := range graphTokenPattern.FindAllString(g.Command, -1) {
+		switch {
+		case strings.HasPrefix(token, "--title="):
+			def.Title = unquote(strings.TrimPrefix(token, "--title="))
+		case strings.HasPrefix(token, "--vertical-label="):
+			def.VerticalLabel = unquote(strings.TrimPrefix(token, "--vertical-label="))
+		case strings.HasPrefix(token, "DEF:"):
+			source, err := parseGraphDef(token)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid graph %s: %w", g.Name, err)
+			}
+			labels[source.Label] = true
+			def.Sources = append(def.Sources, *source)
+		case strings.HasPrefix(token, "CDEF:"):
+			expression, err := parseGraphCdef(token, labels)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid graph %s: %w", g.Name, err)
+			}
+			labels[expression.Label] = true
+			def.Expressions = append(def.Expressions, *expression)
+		case strings.HasPrefix(token, "LINE"), strings.HasPrefix(token, "AREA:"), strings.HasPrefix(token, "STACK:"):
+			series, err := parseGraphSeries(token)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid graph %s: %w", g.Name, err)
+			}
+			if series == nil { // Without color, RRDtool doesn't draw it
+				continue
+			}
+			if !labels[series.Label] {
+				return nil, fmt.Errorf("Invalid graph %s: %s uses an unknown variable %s", g.Name, token, series.Label)
+			}
+			def.Series = append(def.Series, *series)
+		}
+	}
+	if len(def.Sources) == 0 {
+		return nil, fmt.Errorf("Invalid graph %s: the command has no data sources", g.Name)
+	}
+	return def, nil
+}
+
+// Parses DEF:<vname>=<rrdfile>:<ds-name>:<CF>
+func parseGraphDef(token string) (*QuerySource, error) {
+	data := strings.Split(strings.TrimPrefix(token, "DEF:"), ":")
+	if len(data) < 3 || !strings.Contains(data[0], "=") {
+		return nil, fmt.Errorf("cannot parse %s", token)
+	}
+	source := &QuerySource{
+		Label:     strings.SplitN(data[0], "=", 2)[0],
+		Attribute: data[1],
+	}
+	source.Aggregation = strings.ToUpper(data[2])
+	if !MeasurementAggregations.Contains(source.Aggregation) {
+		return nil, fmt.Errorf("unsupported consolidation function %s on %s", data[2], token)
+	}
+	return source, nil
+}
+
+// Parses CDEF:<vname>=<RPN expression>, converting the expression into JEXL
+func parseGraphCdef(token string, labels map[string]bool) (*QueryExpression, error) {
+	data := strings.SplitN(strings.TrimPrefix(token, "CDEF:"), "=", 2)
+	if len(data) != 2 {
+		return nil, fmt.Errorf("cannot parse %s", token)
+	}
+	stack := []string{}
+	for _, item := range strings.Split(data[1], ",") {
+		if op, ok := rpnOperators[item]; ok {
+			if len(stack) < op.operands {
+				return nil, fmt.Errorf("not enough operands for %s on %s", item, token)
+			}
+			args := make([]interface{}, op.operands)
+			for i := range args {
+				args[i] = stack[len(stack)-op.operands+i]
+			}
+			stack = append(stack[:len(stack)-op.operands], fmt.Sprintf(op.format, args...))
+			continue
+		}
+		if _, err := strconv.ParseFloat(item, 64); err == nil || labels[item] {
+			stack = append(stack, item)
+			continue
+		}
+		return nil, fmt.Errorf("unsupported operand %s on %s", item, token)
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("cannot parse the expression of %s", token)
+	}
+	return &QueryExpression{Label: data[0], Value: strings.TrimSuffix(strings.TrimPrefix(stack[0], "("), ")")}, nil
+}
+
+// Parses LINE[width]:<vname>[#color][:legend][:STACK] and AREA:<vname>[#color][:legend][:STACK];
+// returns nil when the series has no color
+func parseGraphSeries(token string) (*GraphSeries, error) {
+	parts := strings.SplitN(token, ":", 3)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("cannot parse %s", token)
+	}
+	series := &GraphSeries{Type: parts[0]}
+	if strings.HasPrefix(series.Type, "LINE") {
+		series.Width = 1
+		if width := strings.TrimPrefix(series.Type, "LINE"); width != "" {
+			w, err := strconv.ParseFloat(width, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid line width on %s", token)
+			}
+			series.Width = int(w + 0.5)
+		}
+		series.Type = "LINE"
+	}
+	vname := strings.SplitN(parts[1], "#", 2)
+	series.Label = vname[0]
+	if len(vname) < 2 {
+		return nil, nil
+	}
+	series.Color = "#" + vname[1]
+	if len(parts) == 3 {
+		legend := parts[2]
+		if strings.HasSuffix(legend, ":STACK") {
+			legend = strings.TrimSuffix(legend, ":STACK")
+			series.Type = "STACK"
+		}
+		series.Legend = strings.TrimSpace(strings.NewReplacer(`\n`, "", `\l`, "", `\r`, "", `\c`, "", `\j`, "", `\:`, ":").Replace(unquote(legend)))
+	}
+	return series, nil
+}
+
+func unquote(text string) string {
+	if len(text) >= 2 && strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) {
+		return strings.Replace(text[1:len(text)-1], `\"`, `"`, -1)
+	}
+	return text
+}
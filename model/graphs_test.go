@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// Based on mib2.HCbits from snmp-graph.properties
+const mockBitsCommand = `--title="Bits In/Out (High Speed)" --vertical-label="Bits per second" ` +
+	`DEF:octIn={rrd1}:ifHCInOctets:AVERAGE DEF:octOut={rrd2}:ifHCOutOctets:AVERAGE ` +
+	`CDEF:rawbitsIn=octIn,8,* CDEF:rawbitsOut=octOut,8,* CDEF:rawbitsOutNeg=0,rawbitsOut,- ` +
+	`CDEF:bitsIn=octIn,UN,0,rawbitsIn,IF ` +
+	`AREA:rawbitsIn#73d216 LINE1:rawbitsIn#4e9a06:"In " GPRINT:rawbitsIn:AVERAGE:"Avg  \: %8.2lf %s" ` +
+	`AREA:rawbitsOutNeg#729fcf LINE1:rawbitsOutNeg#3465a4:"Out\n" LINE1:bitsIn`
+
+func TestGraphDefinition(t *testing.T) {
+	graph := PrefabGraph{Name: "mib2.HCbits", Title: "Bits In/Out", Command: mockBitsCommand}
+	def, err := graph.Definition()
+	assert.NilError(t, err)
+	assert.Equal(t, "Bits In/Out (High Speed)", def.Title)
+	assert.Equal(t, "Bits per second", def.VerticalLabel)
+	assert.DeepEqual(t, []QuerySource{
+		{Label: "octIn", Attribute: "ifHCInOctets", Aggregation: "AVERAGE"},
+		{Label: "octOut", Attribute: "ifHCOutOctets", Aggregation: "AVERAGE"},
+	}, def.Sources)
+	assert.DeepEqual(t, []QueryExpression{
+		{Label: "rawbitsIn", Value: "octIn * 8"},
+		{Label: "rawbitsOut", Value: "octOut * 8"},
+		{Label: "rawbitsOutNeg", Value: "0 - rawbitsOut"},
+		{Label: "bitsIn", Value: "(octIn != octIn) ? 0 : rawbitsIn"},
+	}, def.Expressions)
+	assert.DeepEqual(t, []GraphSeries{
+		{Label: "rawbitsIn", Color: "#73d216", Type: "AREA"},
+		{Label: "rawbitsIn", Legend: "In", Color: "#4e9a06", Type: "LINE", Width: 1},
+		{Label: "rawbitsOutNeg", Color: "#729fcf", Type: "AREA"},
+		{Label: "rawbitsOutNeg", Legend: "Out", Color: "#3465a4", Type: "LINE", Width: 1},
+	}, def.Series)
+
+	request := def.QueryRequest("node[1].interfaceSnmp[eth0]", 0, 3600000, 60000)
+	assert.NilError(t, request.Validate())
+	assert.Equal(t, "node[1].interfaceSnmp[eth0]", request.Sources[1].ResourceID)
+	assert.Equal(t, 4, len(request.Expressions))
+}
+
+func TestInvalidGraphDefinition(t *testing.T) {
+	graph := PrefabGraph{Name: "broken", Command: "DEF:a={rrd1}:ds:AVERAGE CDEF:b=a,{diffTime},/"}
+	_, err := graph.Definition()
+	assert.Error(t, err, "Invalid graph broken: unsupported operand {diffTime} on CDEF:b=a,{diffTime},/")
+
+	graph.Command = "DEF:a={rrd1}:ds:AVERAGE LINE2:c#ff0000"
+	_, err = graph.Definition()
+	assert.Error(t, err, "Invalid graph broken: LINE2:c#ff0000 uses an unknown variable c")
+
+	graph.Command = "--title=Nothing"
+	_, err = graph.Definition()
+	assert.Error(t, err, "Invalid graph broken: the command has no data sources")
+}
+
+func TestGraphNameList(t *testing.T) {
+	list := GraphNameList{}
+	assert.NilError(t, json.Unmarshal([]byte(`["mib2.bits","mib2.errors"]`), &list))
+	assert.DeepEqual(t, []string{"mib2.bits", "mib2.errors"}, list.Names)
+	assert.NilError(t, json.Unmarshal([]byte(`{"name":"mib2.bits"}`), &list))
+	assert.DeepEqual(t, []string{"mib2.bits"}, list.Names)
+	assert.NilError(t, json.Unmarshal([]byte(`{}`), &list))
+	assert.Equal(t, 0, len(list.Names))
+}
@@ -0,0 +1,72 @@
+package model
+
+import "encoding/json"
+
+// HwEntity a physical entity from the hardware inventory of a node (ENTITY-MIB entPhysicalTable)
+type HwEntity struct {
+	Index        int        `json:"entPhysicalIndex" yaml:"index"`
+	ParentIndex  int        `json:"parentIndex,omitempty" yaml:"parentIndex,omitempty"` // Set when reading the inventory, for flat listings
+	Class        string     `json:"entPhysicalClass,omitempty" yaml:"class,omitempty"`
+	Name         string     `json:"entPhysicalName,omitempty" yaml:"name,omitempty"`
+	Description  string     `json:"entPhysicalDescr,omitempty" yaml:"description,omitempty"`
+	Vendor       string     `json:"entPhysicalMfgName,omitempty" yaml:"vendor,omitempty"`
+	ModelName    string     `json:"entPhysicalModelName,omitempty" yaml:"model,omitempty"`
+	SerialNumber string     `json:"entPhysicalSerialNum,omitempty" yaml:"serialNumber,omitempty"`
+	FirmwareRev  string     `json:"entPhysicalFirmwareRev,omitempty" yaml:"firmwareRev,omitempty"`
+	HardwareRev  string     `json:"entPhysicalHardwareRev,omitempty" yaml:"hardwareRev,omitempty"`
+	SoftwareRev  string     `json:"entPhysicalSoftwareRev,omitempty" yaml:"softwareRev,omitempty"`
+	IsFRU        bool       `json:"entPhysicalIsFRU,omitempty" yaml:"isFRU,omitempty"`
+	Children     []HwEntity `json:"hwEntity,omitempty" yaml:"children,omitempty"`
+}
+
+// UnmarshalJSON accepts the children of the entity as hwEntity (as the server names them) or as children
+func (e *HwEntity) UnmarshalJSON(data []byte) error {
+	type plain HwEntity // Avoids the recursion
+	entity := struct {
+		*plain
+		Children []HwEntity `json:"children,omitempty"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &entity); err != nil {
+		return err
+	}
+	if len(e.Children) == 0 {
+		e.Children = entity.Children
+	}
+	for i := range e.Children {
+		e.Children[i].ParentIndex = e.Index
+	}
+	return nil
+}
+
+// Walk visits the entity and its descendants depth-first, with their depth on the tree (0 for this entity)
+func (e HwEntity) Walk(visit func(entity HwEntity, depth int)) {
+	e.walk(visit, 0)
+}
+
+func (e HwEntity) walk(visit func(entity HwEntity, depth int), depth int) {
+	visit(e, depth)
+	for _, child := range e.Children {
+		child.walk(visit, depth+1)
+	}
+}
+
+// Flatten returns the entity and its descendants depth-first, without their children
+func (e HwEntity) Flatten() []HwEntity {
+	entities := []HwEntity{}
+	e.Walk(func(entity HwEntity, depth int) {
+		entity.Children = nil
+		entities = append(entities, entity)
+	})
+	return entities
+}
+
+// SerialNumbers returns the serial numbers of the entity and its descendants, skipping the empty ones
+func (e HwEntity) SerialNumbers() []string {
+	serials := []string{}
+	e.Walk(func(entity HwEntity, depth int) {
+		if entity.SerialNumber != "" {
+			serials = append(serials, entity.SerialNumber)
+		}
+	})
+	return serials
+}
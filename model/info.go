@@ -6,6 +6,12 @@ type OnmsInfoDatetimeFormat struct {
 	Format string `json:"datetimeformat" yaml:"format"`
 }
 
+// OnmsInfoTicketerConfig provides information about the trouble ticketing integration
+type OnmsInfoTicketerConfig struct {
+	Plugin  string `json:"plugin" yaml:"plugin"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+}
+
 // OnmsInfo provides information about the OpenNMS server
 type OnmsInfo struct {
 	DisplayVersion     string                  `json:"displayVersion" yaml:"displayVersion"`
@@ -13,4 +19,5 @@ type OnmsInfo struct {
 	PackageName        string                  `json:"packageName" yaml:"packageName"`
 	PackageDescription string                  `json:"packageDescription" yaml:"packageDescription"`
 	DatetimeFormat     *OnmsInfoDatetimeFormat `json:"datetimeformatConfig" yaml:"datetimeFormat"`
+	TicketerConfig     *OnmsInfoTicketerConfig `json:"ticketerConfig,omitempty" yaml:"ticketer,omitempty"`
 }
@@ -0,0 +1,21 @@
+package model
+
+// Logger receives the messages of the model and services layers that are not part of the payload of a command;
+// the CLI injects a leveled logger writing to stderr, so the output of the commands can be parsed
+type Logger interface {
+	Warnf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Debugf(format string, args ...interface{})
+}
+
+// NopLogger a logger that discards every message
+type NopLogger struct{}
+
+// Warnf discards the warning
+func (NopLogger) Warnf(format string, args ...interface{}) {}
+
+// Infof discards the message
+func (NopLogger) Infof(format string, args ...interface{}) {}
+
+// Debugf discards the message
+func (NopLogger) Debugf(format string, args ...interface{}) {}
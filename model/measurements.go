@@ -0,0 +1,141 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// MeasurementAggregations the consolidation functions for the sources of a measurements query
+var MeasurementAggregations = EnumValue{
+	Enum:    []string{"AVERAGE", "MIN", "MAX", "LAST"},
+	Default: "AVERAGE",
+}
+
+// QuerySource a metric to fetch from a resource
+type QuerySource struct {
+	Label       string `json:"label" yaml:"label"`
+	ResourceID  string `json:"resourceId" yaml:"resourceId"`
+	Attribute   string `json:"attribute" yaml:"attribute"`
+	Aggregation string `json:"aggregation,omitempty" yaml:"aggregation,omitempty"`
+	Transient   bool   `json:"transient" yaml:"transient"`
+}
+
+// QueryExpression a JEXL expression that can use the labels of the sources as variables
+type QueryExpression struct {
+	Label     string `json:"label" yaml:"label"`
+	Value     string `json:"value" yaml:"value"`
+	Transient bool   `json:"transient" yaml:"transient"`
+}
+
+// QueryRequest a request for the Measurements API; times are in milliseconds
+type QueryRequest struct {
+	Start       int64             `json:"start" yaml:"start"`
+	End         int64             `json:"end" yaml:"end"`
+	Step        int64             `json:"step" yaml:"step"`
+	MaxRows     int               `json:"maxrows,omitempty" yaml:"maxRows,omitempty"`
+	Relaxed     bool              `json:"relaxed" yaml:"relaxed"`
+	Sources     []QuerySource     `json:"source" yaml:"sources"`
+	Expressions []QueryExpression `json:"expression,omitempty" yaml:"expressions,omitempty"`
+}
+
+// Validate returns an error if the query request is invalid
+func (q *QueryRequest) Validate() error {
+	if len(q.Sources) == 0 {
+		return fmt.Errorf("At least one source required")
+	}
+	if q.End <= q.Start {
+		return fmt.Errorf("The end time must be after the start time")
+	}
+	if q.Step <= 0 {
+		return fmt.Errorf("Step must be greater than zero")
+	}
+	labels := make(map[string]bool)
+	for i := range q.Sources {
+		s := &q.Sources[i]
+		if s.ResourceID == "" {
+			return fmt.Errorf("Resource ID required for source %s", s.Label)
+		}
+		if s.Attribute == "" {
+			return fmt.Errorf("Attribute required for source on %s", s.ResourceID)
+		}
+		if s.Label == "" {
+			s.Label = s.Attribute
+		}
+		if s.Aggregation == "" {
+			s.Aggregation = MeasurementAggregations.Default
+		}
+		if !MeasurementAggregations.Contains(s.Aggregation) {
+			return fmt.Errorf("Invalid aggregation %s, valid options: %s", s.Aggregation, MeasurementAggregations.EnumAsString())
+		}
+		if labels[s.Label] {
+			return fmt.Errorf("Duplicated label %s", s.Label)
+		}
+		labels[s.Label] = true
+	}
+	for _, e := range q.Expressions {
+		if e.Label == "" || e.Value == "" {
+			return fmt.Errorf("Expressions require a label and a value")
+		}
+		if labels[e.Label] {
+			return fmt.Errorf("Duplicated label %s", e.Label)
+		}
+		labels[e.Label] = true
+	}
+	return nil
+}
+
+// MetricValue a value of a time series; missing values are NaN
+type MetricValue float64
+
+// UnmarshalJSON accepts numbers, null, and the strings used by the server for special values (e.x. "NaN")
+func (v *MetricValue) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch n := value.(type) {
+	case float64:
+		*v = MetricValue(n)
+	case nil:
+		*v = MetricValue(math.NaN())
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid metric value %s", n)
+		}
+		*v = MetricValue(f)
+	default:
+		return fmt.Errorf("Invalid metric value %s", string(data))
+	}
+	return nil
+}
+
+// MarshalJSON uses null for NaN and infinite values, as they are not valid on JSON
+func (v MetricValue) MarshalJSON() ([]byte, error) {
+	if v.IsNaN() || math.IsInf(float64(v), 0) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(v))
+}
+
+// IsNaN returns true when the value is missing
+func (v MetricValue) IsNaN() bool {
+	return math.IsNaN(float64(v))
+}
+
+// QueryColumn the values of a source or expression
+type QueryColumn struct {
+	Values []MetricValue `json:"values" yaml:"values"`
+}
+
+// QueryResponse the response of the Measurements API; times are in milliseconds
+type QueryResponse struct {
+	Step       int64         `json:"step" yaml:"step"`
+	Start      int64         `json:"start" yaml:"start"`
+	End        int64         `json:"end" yaml:"end"`
+	Timestamps []int64       `json:"timestamps" yaml:"timestamps"`
+	Labels     []string      `json:"labels" yaml:"labels"`
+	Columns    []QueryColumn `json:"columns" yaml:"columns"`
+}
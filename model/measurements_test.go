@@ -0,0 +1,42 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestQueryRequestValidate(t *testing.T) {
+	q := &QueryRequest{Start: 1000, End: 2000, Step: 300000}
+	assert.Error(t, q.Validate(), "At least one source required")
+
+	q.Sources = []QuerySource{{ResourceID: "node[1].nodeSnmp[]"}}
+	assert.Error(t, q.Validate(), "Attribute required for source on node[1].nodeSnmp[]")
+
+	q.Sources[0].Attribute = "loadavg1"
+	assert.NilError(t, q.Validate())
+	assert.Equal(t, "loadavg1", q.Sources[0].Label)
+	assert.Equal(t, "AVERAGE", q.Sources[0].Aggregation)
+
+	q.Expressions = []QueryExpression{{Label: "loadavg1", Value: "loadavg1 / 100"}}
+	assert.Error(t, q.Validate(), "Duplicated label loadavg1")
+
+	q.Expressions[0].Label = "load"
+	q.End = 500
+	assert.Error(t, q.Validate(), "The end time must be after the start time")
+}
+
+func TestQueryResponseWithGaps(t *testing.T) {
+	data := `{"step":300000,"start":0,"end":900000,"timestamps":[0,300000,600000],"labels":["in"],"columns":[{"values":[1.5,"NaN",null]}]}`
+	response := &QueryResponse{}
+	assert.NilError(t, json.Unmarshal([]byte(data), response))
+	values := response.Columns[0].Values
+	assert.Equal(t, MetricValue(1.5), values[0])
+	assert.Assert(t, values[1].IsNaN())
+	assert.Assert(t, values[2].IsNaN())
+
+	bytes, err := json.Marshal(response.Columns[0])
+	assert.NilError(t, err)
+	assert.Equal(t, `{"values":[1.5,null,null]}`, string(bytes))
+}
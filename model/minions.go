@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// MinionStatusUp the status of a minion whose heartbeat is being received
+const MinionStatusUp = "UP"
+
+// MinionStatusDown the status of a minion whose heartbeat stopped, or that is considered stale by the CLI
+const MinionStatusDown = "DOWN"
+
+// OnmsMinion OpenNMS minion entity, as reported by its heartbeat
+type OnmsMinion struct {
+	ID          string `json:"id" yaml:"id"`
+	Label       string `json:"label,omitempty" yaml:"label,omitempty"`
+	Location    string `json:"location,omitempty" yaml:"location,omitempty"`
+	Type        string `json:"type,omitempty" yaml:"type,omitempty"`
+	Status      string `json:"status,omitempty" yaml:"status,omitempty"`
+	LastUpdated *Time  `json:"lastUpdated,omitempty" yaml:"lastUpdated,omitempty"`
+}
+
+// OnmsMinionList a list of minions
+type OnmsMinionList struct {
+	Count      int          `json:"count" yaml:"count"`
+	TotalCount int          `json:"totalCount" yaml:"totalCount"`
+	Offset     int          `json:"offset" yaml:"offset"`
+	Minions    []OnmsMinion `json:"minion" yaml:"minions"`
+}
+
+// HeartbeatAge returns the time since the last heartbeat, or a negative duration when it was never received
+func (m OnmsMinion) HeartbeatAge(now time.Time) time.Duration {
+	if m.LastUpdated == nil || m.LastUpdated.IsZero() {
+		return -1
+	}
+	return now.Sub(m.LastUpdated.Time)
+}
+
+// IsStale returns true when the last heartbeat is older than the threshold, or was never received
+func (m OnmsMinion) IsStale(now time.Time, threshold time.Duration) bool {
+	age := m.HeartbeatAge(now)
+	return age < 0 || age > threshold
+}
+
+// GetState returns the status of the minion, or DOWN when its heartbeat is stale regardless of what the server reports
+func (m OnmsMinion) GetState(now time.Time, threshold time.Duration) string {
+	if m.IsStale(now, threshold) {
+		return MinionStatusDown
+	}
+	if m.Status == "" {
+		return MinionStatusUp
+	}
+	return m.Status
+}
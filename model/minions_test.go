@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestMinionState(t *testing.T) {
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	minion := OnmsMinion{ID: "minion-01", Status: MinionStatusUp, LastUpdated: &Time{now.Add(-2 * time.Minute)}}
+	assert.Equal(t, 2*time.Minute, minion.HeartbeatAge(now))
+	assert.Assert(t, !minion.IsStale(now, 5*time.Minute))
+	assert.Equal(t, MinionStatusUp, minion.GetState(now, 5*time.Minute))
+	assert.Assert(t, minion.IsStale(now, time.Minute))
+	assert.Equal(t, MinionStatusDown, minion.GetState(now, time.Minute))
+
+	minion.LastUpdated = nil
+	assert.Assert(t, minion.HeartbeatAge(now) < 0)
+	assert.Assert(t, minion.IsStale(now, 5*time.Minute))
+	assert.Equal(t, MinionStatusDown, minion.GetState(now, 5*time.Minute))
+}
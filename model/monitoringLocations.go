@@ -1,5 +1,7 @@
 package model
 
+import "fmt"
+
 // MonitoringLocation an OpenNMS Location
 type MonitoringLocation struct {
 	Tags                   []string `json:"tags,omitempty" yaml:"tags,omitempty"`
@@ -9,7 +11,7 @@ type MonitoringLocation struct {
 	LocationName           string   `json:"location-name,omitempty" yaml:"name,omitempty"`
 	Priority               int      `json:"priority,omitempty" yaml:"priority,omitempty"`
 	MonitoringArea         string   `json:"monitoring-area,omitempty" yaml:"monitoringArea,omitempty"`
-	PollingPackageNames    []string `json:"polling-package-names,omitempty" yaml:pollingPackageNames,omitempty"`
+	PollingPackageNames    []string `json:"polling-package-names,omitempty" yaml:"pollingPackageNames,omitempty"`
 	CollectionPackageNames []string `json:"collection-package-names,omitempty" yaml:"collectionPackageNames,omitempty"`
 }
 
@@ -20,3 +22,23 @@ type MonitoringLocationList struct {
 	Offset     int                  `json:"offset" yaml:"offset"`
 	Locations  []MonitoringLocation `json:"location" yaml:"locations"`
 }
+
+// Validate returns an error if the location is invalid; the monitoring area defaults to the location name
+func (loc *MonitoringLocation) Validate() error {
+	if loc.LocationName == "" {
+		return fmt.Errorf("Location name cannot be empty")
+	}
+	if loc.Latitude < -90 || loc.Latitude > 90 {
+		return fmt.Errorf("Invalid latitude %g, it must be between -90 and 90", loc.Latitude)
+	}
+	if loc.Longitude < -180 || loc.Longitude > 180 {
+		return fmt.Errorf("Invalid longitude %g, it must be between -180 and 180", loc.Longitude)
+	}
+	if loc.Priority < 0 {
+		return fmt.Errorf("Priority cannot be negative")
+	}
+	if loc.MonitoringArea == "" {
+		loc.MonitoringArea = loc.LocationName
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestMonitoringLocationValidate(t *testing.T) {
+	loc := &MonitoringLocation{}
+	assert.Error(t, loc.Validate(), "Location name cannot be empty")
+
+	loc.LocationName = "Durham"
+	loc.Latitude = 91
+	assert.Error(t, loc.Validate(), "Invalid latitude 91, it must be between -90 and 90")
+
+	loc.Latitude = 35.99
+	loc.Longitude = -181.5
+	assert.Error(t, loc.Validate(), "Invalid longitude -181.5, it must be between -180 and 180")
+
+	loc.Longitude = -78.9
+	assert.NilError(t, loc.Validate())
+	assert.Equal(t, "Durham", loc.MonitoringArea)
+}
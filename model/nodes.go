@@ -1,12 +1,5 @@
 package model
 
-// OnmsCategory an entity that represents an OpenNMS category
-type OnmsCategory struct {
-	ID     int      `json:"id" yaml:"id"`
-	Name   string   `json:"name" yaml:"name"`
-	Groups []string `json:"groups,omitempty" yaml:"groups,omitempty"`
-}
-
 // OnmsAssetRecord an entity that represents an OpenNMS asset record
 type OnmsAssetRecord struct {
 	ID int `json:"id,omitempty" yaml:"id,omitempty"`
@@ -160,6 +153,7 @@ type OnmsSnmpInterface struct {
 	Poll                    bool   `json:"poll" yaml:"poll"`
 	PollFlag                string `json:"pollFlag,omitempty" yaml:"pollFlag,omitempty"`
 	LastPoll                *Time  `json:"lastCapsdPoll,omitempty" yaml:"lastPoll,omitempty"`
+	LastSnmpPoll            *Time  `json:"lastSnmpPoll,omitempty" yaml:"lastSnmpPoll,omitempty"`
 	HasFlows                bool   `json:"hasFlows" yaml:"hasFlows"`
 }
 
@@ -173,8 +167,8 @@ type OnmsSnmpInterfaceList struct {
 
 // OnmsNode an entity that represents an OpenNMS node
 type OnmsNode struct {
-	ID             string           `json:"id" yaml:"id"` // TODO the JSON returns a string instead of an integer
-	Label          string           `json:"label,omitempty" yaml:"label,omitempty"`
+	ID             string           `json:"id" yaml:"id" required:"true"` // TODO the JSON returns a string instead of an integer
+	Label          string           `json:"label,omitempty" yaml:"label,omitempty" required:"true"`
 	LabelSource    string           `json:"labelSource,omitempty" yaml:"labelSource,omitempty"`
 	ForeignSource  string           `json:"foreignSource,omitempty" yaml:"foreignSource,omitempty"`
 	ForeignID      string           `json:"foreignId,omitempty" yaml:"foreignId,omitempty"`
@@ -0,0 +1,216 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+)
+
+// The intervals on the notification configuration, as milliseconds or a combination of units (e.x. 0s, 5m, 1h30m)
+var notificationIntervalPattern = regexp.MustCompile(`^(\d+|(\d+(ms|s|m|h|d|w))+)$`)
+
+// NotificationStatusOn the status of Notifd and event notifications when notifications are sent
+const NotificationStatusOn = "on"
+
+// NotificationStatusOff the status of Notifd and event notifications when notifications are not sent
+const NotificationStatusOff = "off"
+
+// NotificationStatus the status of Notifd and event notifications
+var NotificationStatus = &EnumValue{
+	Enum: []string{NotificationStatusOn, NotificationStatusOff},
+}
+
+// AutoNotifyModes the valid values for the auto-notify setting of a target
+var AutoNotifyModes = &EnumValue{
+	Enum: []string{"auto", "on", "off"},
+}
+
+// ValidateNotificationInterval returns an error if the interval is not a valid notification interval
+func ValidateNotificationInterval(interval string) error {
+	if !notificationIntervalPattern.MatchString(interval) {
+		return fmt.Errorf("Invalid interval %s, expected milliseconds or a duration like 30s, 5m or 1h30m", interval)
+	}
+	return nil
+}
+
+// NotificationTarget a user, group, role or e-mail address notified through a list of commands
+type NotificationTarget struct {
+	XMLName    xml.Name `xml:"target" json:"-" yaml:"-"`
+	Interval   string   `xml:"interval,attr,omitempty" json:"interval,omitempty" yaml:"interval,omitempty"`
+	Name       string   `xml:"name" json:"name" yaml:"name"`
+	AutoNotify string   `xml:"autoNotify,omitempty" json:"autoNotify,omitempty" yaml:"autoNotify,omitempty"`
+	Commands   []string `xml:"command" json:"command" yaml:"commands"`
+}
+
+// Validate returns an error if the target is invalid
+func (t *NotificationTarget) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("Target name cannot be empty")
+	}
+	if t.Interval != "" {
+		if err := ValidateNotificationInterval(t.Interval); err != nil {
+			return fmt.Errorf("Target %s: %w", t.Name, err)
+		}
+	}
+	if t.AutoNotify != "" {
+		if !AutoNotifyModes.Contains(t.AutoNotify) {
+			return fmt.Errorf("Invalid auto-notify for target %s: %s, valid options: %s", t.Name, t.AutoNotify, AutoNotifyModes.EnumAsString())
+		}
+	}
+	if len(t.Commands) == 0 {
+		return fmt.Errorf("Target %s requires at least one command", t.Name)
+	}
+	for _, cmd := range t.Commands {
+		if cmd == "" {
+			return fmt.Errorf("Target %s cannot have empty commands", t.Name)
+		}
+	}
+	return nil
+}
+
+// NotificationEscalate the targets notified when a notification is not acknowledged after a delay
+type NotificationEscalate struct {
+	XMLName xml.Name             `xml:"escalate" json:"-" yaml:"-"`
+	Delay   string               `xml:"delay,attr" json:"delay" yaml:"delay"`
+	Targets []NotificationTarget `xml:"target" json:"target" yaml:"targets"`
+}
+
+// Validate returns an error if the escalation is invalid
+func (e *NotificationEscalate) Validate() error {
+	if e.Delay == "" {
+		return fmt.Errorf("Escalation delay cannot be empty")
+	}
+	if err := ValidateNotificationInterval(e.Delay); err != nil {
+		return fmt.Errorf("Escalation delay: %w", err)
+	}
+	if len(e.Targets) == 0 {
+		return fmt.Errorf("Escalation after %s requires at least one target", e.Delay)
+	}
+	for i := range e.Targets {
+		if err := e.Targets[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DestinationPath who is notified, and how, when a notification is sent
+type DestinationPath struct {
+	XMLName      xml.Name               `xml:"path" json:"-" yaml:"-"`
+	Name         string                 `xml:"name,attr" json:"name" yaml:"name"`
+	InitialDelay string                 `xml:"initial-delay,attr,omitempty" json:"initial-delay,omitempty" yaml:"initialDelay,omitempty"`
+	Targets      []NotificationTarget   `xml:"target" json:"target" yaml:"targets"`
+	Escalations  []NotificationEscalate `xml:"escalate,omitempty" json:"escalate,omitempty" yaml:"escalations,omitempty"`
+}
+
+// Validate returns an error if the destination path is invalid
+func (p *DestinationPath) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("Destination path name cannot be empty")
+	}
+	if p.InitialDelay != "" {
+		if err := ValidateNotificationInterval(p.InitialDelay); err != nil {
+			return fmt.Errorf("Initial delay of destination path %s: %w", p.Name, err)
+		}
+	}
+	if len(p.Targets) == 0 {
+		return fmt.Errorf("Destination path %s requires at least one target", p.Name)
+	}
+	for i := range p.Targets {
+		if err := p.Targets[i].Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range p.Escalations {
+		if err := p.Escalations[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DestinationPathList a list of destination paths
+type DestinationPathList struct {
+	Count int               `json:"count" yaml:"count"`
+	Paths []DestinationPath `json:"path" yaml:"paths"`
+}
+
+// NotificationCommandArgument an argument of a notification command
+type NotificationCommandArgument struct {
+	XMLName      xml.Name `xml:"argument" json:"-" yaml:"-"`
+	Streamed     bool     `xml:"streamed,attr" json:"streamed" yaml:"streamed"`
+	Substitution string   `xml:"substitution,omitempty" json:"substitution,omitempty" yaml:"substitution,omitempty"`
+	Switch       string   `xml:"switch,omitempty" json:"switch,omitempty" yaml:"switch,omitempty"`
+}
+
+// NotificationCommand a method to send notifications (e.x. javaEmail), referenced by name from the targets
+type NotificationCommand struct {
+	XMLName   xml.Name                      `xml:"command" json:"-" yaml:"-"`
+	Binary    bool                          `xml:"binary,attr" json:"binary" yaml:"binary"`
+	Name      string                        `xml:"name" json:"name" yaml:"name"`
+	Execute   string                        `xml:"execute" json:"execute" yaml:"execute"`
+	Comment   string                        `xml:"comment,omitempty" json:"comment,omitempty" yaml:"comment,omitempty"`
+	Arguments []NotificationCommandArgument `xml:"argument,omitempty" json:"argument,omitempty" yaml:"arguments,omitempty"`
+}
+
+// Validate returns an error if the command is invalid
+func (c *NotificationCommand) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("Command name cannot be empty")
+	}
+	if c.Execute == "" {
+		return fmt.Errorf("Command %s requires the class or binary to execute", c.Name)
+	}
+	for _, arg := range c.Arguments {
+		if arg.Substitution == "" && arg.Switch == "" {
+			return fmt.Errorf("Arguments of command %s require a substitution or a switch", c.Name)
+		}
+	}
+	return nil
+}
+
+// EventNotification a notification sent to a destination path when a given event is received
+type EventNotification struct {
+	XMLName         xml.Name `xml:"notification" json:"-" yaml:"-"`
+	Name            string   `xml:"name,attr" json:"name" yaml:"name"`
+	Status          string   `xml:"status,attr" json:"status" yaml:"status"`
+	UEI             string   `xml:"uei" json:"uei" yaml:"uei"`
+	Description     string   `xml:"description,omitempty" json:"description,omitempty" yaml:"description,omitempty"`
+	Rule            string   `xml:"rule" json:"rule" yaml:"rule"`
+	DestinationPath string   `xml:"destinationPath" json:"destinationPath" yaml:"destinationPath"`
+	TextMessage     string   `xml:"text-message" json:"text-message" yaml:"textMessage"`
+	Subject         string   `xml:"subject,omitempty" json:"subject,omitempty" yaml:"subject,omitempty"`
+	NumericMessage  string   `xml:"numeric-message,omitempty" json:"numeric-message,omitempty" yaml:"numericMessage,omitempty"`
+}
+
+// Validate returns an error if the notification is invalid
+func (n *EventNotification) Validate() error {
+	if n.Name == "" {
+		return fmt.Errorf("Notification name cannot be empty")
+	}
+	if n.UEI == "" {
+		return fmt.Errorf("Notification %s requires an UEI", n.Name)
+	}
+	if n.DestinationPath == "" {
+		return fmt.Errorf("Notification %s requires a destination path", n.Name)
+	}
+	if n.Status == "" { // Set a reasonable default when the status is not initialized
+		n.Status = NotificationStatusOn
+	}
+	if !NotificationStatus.Contains(n.Status) {
+		return fmt.Errorf("Invalid status for notification %s: %s, valid options: %s", n.Name, n.Status, NotificationStatus.EnumAsString())
+	}
+	if n.Rule == "" { // Matches all the interfaces, like the default rule of the WebUI
+		n.Rule = "IPADDR != '0.0.0.0'"
+	}
+	if n.TextMessage == "" {
+		n.TextMessage = "%logmsg%"
+	}
+	return nil
+}
+
+// EventNotificationList a list of event notifications
+type EventNotificationList struct {
+	Count         int                 `json:"count" yaml:"count"`
+	Notifications []EventNotification `json:"notification" yaml:"notifications"`
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+const destinationPathYAML = `name: Email-Admin
+initialDelay: 1m
+targets:
+- interval: 0s
+  name: Admin
+  autoNotify: auto
+  commands:
+  - javaEmail
+escalations:
+- delay: 15m
+  targets:
+  - name: NetworkOps
+    commands:
+    - javaEmail
+    - javaPagerEmail
+- delay: 1h30m
+  targets:
+  - interval: "300000"
+    name: oncall@example.com
+    commands:
+    - javaEmail
+`
+
+func TestDestinationPathYAML(t *testing.T) {
+	path := &DestinationPath{}
+	assert.NilError(t, yaml.Unmarshal([]byte(destinationPathYAML), path))
+	assert.NilError(t, path.Validate())
+	assert.Equal(t, 2, len(path.Escalations))
+	assert.Equal(t, "1h30m", path.Escalations[1].Delay)
+	assert.DeepEqual(t, []string{"javaEmail", "javaPagerEmail"}, path.Escalations[0].Targets[0].Commands)
+
+	data, err := yaml.Marshal(path)
+	assert.NilError(t, err)
+	assert.Equal(t, destinationPathYAML, string(data))
+
+	data, err = xml.Marshal(path)
+	assert.NilError(t, err)
+	assert.Equal(t, `<path name="Email-Admin" initial-delay="1m"><target interval="0s"><name>Admin</name><autoNotify>auto</autoNotify><command>javaEmail</command></target><escalate delay="15m"><target><name>NetworkOps</name><command>javaEmail</command><command>javaPagerEmail</command></target></escalate><escalate delay="1h30m"><target interval="300000"><name>oncall@example.com</name><command>javaEmail</command></target></escalate></path>`, string(data))
+	parsed := &DestinationPath{}
+	assert.NilError(t, xml.Unmarshal(data, parsed))
+	assert.Equal(t, "oncall@example.com", parsed.Escalations[1].Targets[0].Name)
+}
+
+func TestInvalidDestinationPath(t *testing.T) {
+	testCases := []struct {
+		content string
+		err     string
+	}{
+		{"targets: [{name: Admin, commands: [javaEmail]}]", "Destination path name cannot be empty"},
+		{"name: Empty", "Destination path Empty requires at least one target"},
+		{"name: P1\ninitialDelay: 5 minutes\ntargets: [{name: Admin, commands: [javaEmail]}]", "Initial delay of destination path P1: Invalid interval 5 minutes, expected milliseconds or a duration like 30s, 5m or 1h30m"},
+		{"name: P1\ntargets: [{name: '', commands: [javaEmail]}]", "Target name cannot be empty"},
+		{"name: P1\ntargets: [{name: Admin}]", "Target Admin requires at least one command"},
+		{"name: P1\ntargets: [{name: Admin, commands: ['']}]", "Target Admin cannot have empty commands"},
+		{"name: P1\ntargets: [{name: Admin, autoNotify: always, commands: [javaEmail]}]", "Invalid auto-notify for target Admin: always, valid options: auto, on, off"},
+		{"name: P1\ntargets: [{name: Admin, commands: [javaEmail]}]\nescalations: [{targets: [{name: Ops, commands: [javaEmail]}]}]", "Escalation delay cannot be empty"},
+		{"name: P1\ntargets: [{name: Admin, commands: [javaEmail]}]\nescalations: [{delay: 5m}]", "Escalation after 5m requires at least one target"},
+		{"name: P1\ntargets: [{name: Admin, commands: [javaEmail]}]\nescalations: [{delay: 5m, targets: [{name: '', commands: [javaEmail]}]}]", "Target name cannot be empty"},
+	}
+	for _, tc := range testCases {
+		path := &DestinationPath{}
+		assert.NilError(t, yaml.Unmarshal([]byte(tc.content), path))
+		assert.Error(t, path.Validate(), tc.err)
+	}
+}
+
+func TestEventNotification(t *testing.T) {
+	n := &EventNotification{Name: "Node Down", UEI: "uei.opennms.org/nodes/nodeDown", DestinationPath: "Email-Admin"}
+	assert.NilError(t, n.Validate())
+	assert.Equal(t, NotificationStatusOn, n.Status)
+	assert.Equal(t, "IPADDR != '0.0.0.0'", n.Rule)
+
+	n.Status = "disabled"
+	assert.Error(t, n.Validate(), "Invalid status for notification Node Down: disabled, valid options: on, off")
+	n = &EventNotification{Name: "Node Down", UEI: "uei.opennms.org/nodes/nodeDown"}
+	assert.Error(t, n.Validate(), "Notification Node Down requires a destination path")
+
+	cmd := &NotificationCommand{Name: "javaEmail", Execute: "org.opennms.netmgt.notifd.JavaMailNotificationStrategy", Arguments: []NotificationCommandArgument{{Switch: "-subject"}}}
+	assert.NilError(t, cmd.Validate())
+	cmd.Arguments = append(cmd.Arguments, NotificationCommandArgument{Streamed: true})
+	assert.Error(t, cmd.Validate(), "Arguments of command javaEmail require a substitution or a switch")
+}
@@ -1,5 +1,13 @@
 package model
 
+import (
+	"sort"
+	"time"
+)
+
+// CurrentOutagesFilter the FIQL expression for the outages whose service was not regained; \u0000 represents null on the ReST API v2
+const CurrentOutagesFilter = `ifRegainedService==\u0000`
+
 // OnmsOutage OpenNMS outage entity
 type OnmsOutage struct {
 	ID                   int                   `json:"id" yaml:"id"`
@@ -26,3 +34,65 @@ type OnmsOutageList struct {
 	Offset     int          `json:"offset" yaml:"offset"`
 	Outages    []OnmsOutage `json:"outage" yaml:"outages"`
 }
+
+// IsCurrent returns true when the service was not regained yet
+func (o OnmsOutage) IsCurrent() bool {
+	return o.ServiceRegainedTime == nil || o.ServiceRegainedTime.IsZero()
+}
+
+// ServiceName returns the name of the service that was lost
+func (o OnmsOutage) ServiceName() string {
+	if o.MonitoredService == nil || o.MonitoredService.ServiceType == nil {
+		return ""
+	}
+	return o.MonitoredService.ServiceType.Name
+}
+
+// Duration returns how long the service was lost, or has been lost until now for current outages
+func (o OnmsOutage) Duration(now time.Time) time.Duration {
+	if o.ServiceLostTime == nil || o.ServiceLostTime.IsZero() {
+		return 0
+	}
+	if o.IsCurrent() {
+		return now.Sub(o.ServiceLostTime.Time)
+	}
+	return o.ServiceRegainedTime.Sub(o.ServiceLostTime.Time)
+}
+
+// OutageGroup the outages that share a node category, location or foreign source
+type OutageGroup struct {
+	Name    string `json:"name" yaml:"name"`
+	Outages int    `json:"outages" yaml:"outages"`
+	Nodes   int    `json:"nodes" yaml:"nodes"`
+	Oldest  *Time  `json:"oldest,omitempty" yaml:"oldest,omitempty"`
+	nodeIDs map[int]bool
+}
+
+// GroupOutages aggregates the outages by the groups returned for each of them (an outage may belong to many groups, e.x. categories),
+// sorted by name
+func GroupOutages(outages []OnmsOutage, groupsOf func(OnmsOutage) []string) []OutageGroup {
+	groups := make(map[string]*OutageGroup)
+	for _, o := range outages {
+		for _, name := range groupsOf(o) {
+			g, ok := groups[name]
+			if !ok {
+				g = &OutageGroup{Name: name, nodeIDs: make(map[int]bool)}
+				groups[name] = g
+			}
+			g.Outages++
+			g.nodeIDs[o.NodeID] = true
+			g.Nodes = len(g.nodeIDs)
+			if o.ServiceLostTime != nil && (g.Oldest == nil || o.ServiceLostTime.Before(g.Oldest.Time)) {
+				g.Oldest = o.ServiceLostTime
+			}
+		}
+	}
+	result := make([]OutageGroup, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestOutageDuration(t *testing.T) {
+	lost := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	outage := OnmsOutage{ServiceLostTime: &Time{lost}}
+	assert.Assert(t, outage.IsCurrent())
+	assert.Equal(t, 2*time.Hour, outage.Duration(lost.Add(2*time.Hour)))
+	assert.Equal(t, "", outage.ServiceName())
+
+	outage.ServiceRegainedTime = &Time{lost.Add(5 * time.Minute)}
+	outage.MonitoredService = &OnmsMonitoredService{ServiceType: &OnmsServiceType{Name: "ICMP"}}
+	assert.Assert(t, !outage.IsCurrent())
+	assert.Equal(t, 5*time.Minute, outage.Duration(lost.Add(2*time.Hour)))
+	assert.Equal(t, "ICMP", outage.ServiceName())
+}
+
+func TestGroupOutages(t *testing.T) {
+	base := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	outages := []OnmsOutage{
+		{ID: 1, NodeID: 1, Location: "Durham", ServiceLostTime: &Time{base.Add(time.Hour)}},
+		{ID: 2, NodeID: 1, Location: "Durham", ServiceLostTime: &Time{base}},
+		{ID: 3, NodeID: 2, Location: "Durham", ServiceLostTime: &Time{base.Add(2 * time.Hour)}},
+		{ID: 4, NodeID: 3, Location: "Apex"},
+	}
+	groups := GroupOutages(outages, func(o OnmsOutage) []string {
+		if o.ID == 3 {
+			return []string{o.Location, "Servers"}
+		}
+		return []string{o.Location}
+	})
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, "Apex", groups[0].Name)
+	assert.Assert(t, groups[0].Oldest == nil)
+	assert.Equal(t, "Durham", groups[1].Name)
+	assert.Equal(t, 3, groups[1].Outages)
+	assert.Equal(t, 2, groups[1].Nodes)
+	assert.Assert(t, groups[1].Oldest.Equal(base))
+	assert.Equal(t, "Servers", groups[2].Name)
+	assert.Equal(t, 1, groups[2].Outages)
+}
@@ -0,0 +1,266 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// PollerFilterTarget an IP interface evaluated against the filter of a package, with the categories of its node
+type PollerFilterTarget struct {
+	IPAddress  string
+	Categories []string
+}
+
+// UnsupportedFilterError a construct of a package that cannot be evaluated on the client side
+type UnsupportedFilterError struct {
+	Construct string
+}
+
+func (e UnsupportedFilterError) Error() string {
+	return fmt.Sprintf("%s cannot be evaluated on the client side", e.Construct)
+}
+
+// EvaluatePollerFilter evaluates the filter of a package against an IP interface. Only catinc<Category> and comparisons
+// of IPADDR (IPLIKE, == and !=) joined by &, |, ! (or AND, OR, NOT) and parenthesis are supported; the rest of the
+// constructs return an UnsupportedFilterError. An empty filter matches every interface
+func EvaluatePollerFilter(filter string, target PollerFilterTarget) (bool, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return false, fmt.Errorf("Invalid filter %s: %w", filter, err)
+	}
+	if len(tokens) == 0 {
+		return true, nil
+	}
+	p := &filterParser{tokens: tokens, target: target}
+	result, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	if err != nil {
+		if _, ok := err.(UnsupportedFilterError); ok {
+			return false, err
+		}
+		return false, fmt.Errorf("Invalid filter %s: %w", filter, err)
+	}
+	return result, nil
+}
+
+// The characters that end a word of a filter
+const filterSpecialChars = "()&|!=<>'\" \t\r\n"
+
+// Splits a filter into parenthesis, operators, quoted values (keeping the quotes) and words
+func tokenizeFilter(filter string) ([]string, error) {
+	tokens := make([]string, 0)
+	for i := 0; i < len(filter); {
+		c := filter[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(filter[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted value")
+			}
+			tokens = append(tokens, filter[i:i+end+2])
+			i += end + 2
+		case strings.IndexByte(filterSpecialChars, c) >= 0:
+			token := filter[i : i+1]
+			for _, operator := range []string{"&&", "||", "==", "!=", "<>"} {
+				if strings.HasPrefix(filter[i:], operator) {
+					token = operator
+				}
+			}
+			tokens = append(tokens, token)
+			i += len(token)
+		default:
+			start := i
+			for i < len(filter) && strings.IndexByte(filterSpecialChars, filter[i]) < 0 {
+				i++
+			}
+			tokens = append(tokens, filter[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []string
+	pos    int
+	target PollerFilterTarget
+}
+
+// Advances to the next token when the current one is one of the given ones (case insensitive)
+func (p *filterParser) accept(tokens ...string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	for _, t := range tokens {
+		if strings.EqualFold(p.tokens[p.pos], t) {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (bool, error) {
+	result, err := p.parseAnd()
+	for err == nil && p.accept("|", "||", "OR") {
+		var next bool
+		next, err = p.parseAnd()
+		result = result || next
+	}
+	return result, err
+}
+
+func (p *filterParser) parseAnd() (bool, error) {
+	result, err := p.parseNot()
+	for err == nil && p.accept("&", "&&", "AND") {
+		var next bool
+		next, err = p.parseNot()
+		result = result && next
+	}
+	return result, err
+}
+
+func (p *filterParser) parseNot() (bool, error) {
+	if p.accept("!", "NOT") {
+		result, err := p.parseNot()
+		return !result, err
+	}
+	if p.accept("(") {
+		result, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if !p.accept(")") {
+			return false, fmt.Errorf("unbalanced parenthesis")
+		}
+		return result, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *filterParser) parsePredicate() (bool, error) {
+	if p.pos >= len(p.tokens) {
+		return false, fmt.Errorf("unexpected end of the filter")
+	}
+	word := p.tokens[p.pos]
+	if strings.IndexByte(filterSpecialChars, word[0]) >= 0 {
+		return false, fmt.Errorf("unexpected %s", word)
+	}
+	p.pos++
+	switch {
+	case len(word) > len("catinc") && strings.EqualFold(word[:len("catinc")], "catinc"):
+		category := word[len("catinc"):]
+		for _, c := range p.target.Categories {
+			if c == category {
+				return true, nil
+			}
+		}
+		return false, nil
+	case strings.EqualFold(word, "IPADDR"):
+		return p.parseAddressComparison()
+	}
+	return false, UnsupportedFilterError{Construct: word}
+}
+
+func (p *filterParser) parseAddressComparison() (bool, error) {
+	if p.pos >= len(p.tokens) {
+		return false, fmt.Errorf("expected an operator after IPADDR")
+	}
+	operator := strings.ToUpper(p.tokens[p.pos])
+	p.pos++
+	if p.pos >= len(p.tokens) {
+		return false, fmt.Errorf("expected a value after IPADDR %s", operator)
+	}
+	value := strings.Trim(p.tokens[p.pos], `'"`)
+	p.pos++
+	switch operator {
+	case "IPLIKE":
+		return MatchIPLike(value, p.target.IPAddress)
+	case "=", "==", "!=", "<>":
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return false, fmt.Errorf("invalid IP address %s", value)
+		}
+		equals := ip.Equal(net.ParseIP(p.target.IPAddress))
+		return equals == (operator == "=" || operator == "=="), nil
+	}
+	return false, UnsupportedFilterError{Construct: "IPADDR " + operator}
+}
+
+// MatchIPLike returns true when the IP address matches an IPLIKE pattern; each octet of an IPv4 pattern,
+// or each hextet of an expanded IPv6 pattern, can be a value, a range (e.x. 1-10), a list of them separated
+// by commas, or * for any value
+func MatchIPLike(pattern string, ipAddress string) (bool, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return false, fmt.Errorf("invalid IP address %s", ipAddress)
+	}
+	if i := strings.Index(pattern, "%"); i >= 0 {
+		pattern = pattern[:i] // The scope of IPv6 patterns is ignored
+	}
+	var parts []string
+	var values []int64
+	base := 10
+	if strings.Contains(pattern, ":") {
+		parts = strings.Split(pattern, ":")
+		if len(parts) != 8 {
+			return false, fmt.Errorf("invalid IPLIKE pattern %s, expected 8 hextets", pattern)
+		}
+		if ip.To4() != nil {
+			return false, nil
+		}
+		for i := 0; i < 16; i += 2 {
+			values = append(values, int64(ip[i])<<8|int64(ip[i+1]))
+		}
+		base = 16
+	} else {
+		parts = strings.Split(pattern, ".")
+		if len(parts) != 4 {
+			return false, fmt.Errorf("invalid IPLIKE pattern %s, expected 4 octets", pattern)
+		}
+		ip4 := ip.To4()
+		if ip4 == nil {
+			return false, nil
+		}
+		for _, b := range ip4 {
+			values = append(values, int64(b))
+		}
+	}
+	result := true
+	for i, part := range parts {
+		matches, err := matchIPLikePart(part, values[i], base)
+		if err != nil {
+			return false, fmt.Errorf("invalid IPLIKE pattern %s: %w", pattern, err)
+		}
+		result = result && matches
+	}
+	return result, nil
+}
+
+func matchIPLikePart(part string, value int64, base int) (bool, error) {
+	if part == "*" {
+		return true, nil
+	}
+	for _, item := range strings.Split(part, ",") {
+		bounds := strings.SplitN(item, "-", 2)
+		low, err := strconv.ParseInt(bounds[0], base, 32)
+		if err != nil {
+			return false, fmt.Errorf("invalid value %s", item)
+		}
+		high := low
+		if len(bounds) == 2 {
+			if high, err = strconv.ParseInt(bounds[1], base, 32); err != nil || high < low {
+				return false, fmt.Errorf("invalid range %s", item)
+			}
+		}
+		if value >= low && value <= high {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestEvaluatePollerFilter(t *testing.T) {
+	target := PollerFilterTarget{IPAddress: "10.0.1.5", Categories: []string{"Production", "Servers"}}
+	tests := []struct {
+		filter   string
+		expected bool
+	}{
+		{"", true},
+		{"IPADDR != '0.0.0.0'", true},
+		{"IPADDR == '10.0.1.5'", true},
+		{"IPADDR = '10.0.1.6'", false},
+		{"IPADDR IPLIKE *.*.*.*", true},
+		{"ipaddr iplike '10.0.1-3.1,5,7'", true},
+		{"IPADDR IPLIKE 10.0.2-3.*", false},
+		{"IPADDR IPLIKE *:*:*:*:*:*:*:*", false},
+		{"catincProduction", true},
+		{"catincProduction & catincRouters", false},
+		{"catincProduction && !catincRouters", true},
+		{"catincRouters | catincServers", true},
+		{"catincRouters OR (catincServers AND NOT IPADDR IPLIKE 10.*.*.*)", false},
+		{"(catincRouters || catincServers) & IPADDR IPLIKE 10.*.*.*", true},
+	}
+	for _, test := range tests {
+		matches, err := EvaluatePollerFilter(test.filter, target)
+		assert.NilError(t, err, test.filter)
+		assert.Equal(t, test.expected, matches, test.filter)
+	}
+
+	matches, err := EvaluatePollerFilter("IPADDR IPLIKE fe80:*:*:*:*:*:*:1-ff%eth0", PollerFilterTarget{IPAddress: "fe80::12"})
+	assert.NilError(t, err)
+	assert.Assert(t, matches)
+}
+
+func TestEvaluateInvalidPollerFilter(t *testing.T) {
+	target := PollerFilterTarget{IPAddress: "10.0.1.5"}
+	_, err := EvaluatePollerFilter("nodeLabel LIKE 'srv%'", target)
+	assert.Error(t, err, "nodeLabel cannot be evaluated on the client side")
+	_, ok := err.(UnsupportedFilterError)
+	assert.Assert(t, ok)
+	_, err = EvaluatePollerFilter("catincProduction & isICMP", target)
+	assert.Error(t, err, "isICMP cannot be evaluated on the client side")
+	_, err = EvaluatePollerFilter("IPADDR > '10.0.0.1'", target)
+	assert.Error(t, err, "IPADDR > cannot be evaluated on the client side")
+
+	_, err = EvaluatePollerFilter("(catincProduction", target)
+	assert.Error(t, err, "Invalid filter (catincProduction: unbalanced parenthesis")
+	_, err = EvaluatePollerFilter("catincProduction)", target)
+	assert.Error(t, err, "Invalid filter catincProduction): unexpected )")
+	_, err = EvaluatePollerFilter("IPADDR == '10.0.0.1", target)
+	assert.Error(t, err, "Invalid filter IPADDR == '10.0.0.1: unterminated quoted value")
+	_, err = EvaluatePollerFilter("IPADDR IPLIKE 10.*.*", target)
+	assert.Error(t, err, "Invalid filter IPADDR IPLIKE 10.*.*: invalid IPLIKE pattern 10.*.*, expected 4 octets")
+	_, err = EvaluatePollerFilter("IPADDR IPLIKE 10.5-1.*.*", target)
+	assert.Error(t, err, "Invalid filter IPADDR IPLIKE 10.5-1.*.*: invalid IPLIKE pattern 10.5-1.*.*: invalid range 5-1")
+	_, err = EvaluatePollerFilter("catincProduction &", target)
+	assert.Error(t, err, "Invalid filter catincProduction &: unexpected end of the filter")
+}
@@ -0,0 +1,232 @@
+package model
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net"
+)
+
+// The values of the status of a poller service
+const (
+	PollerServiceOn  = "on"
+	PollerServiceOff = "off"
+)
+
+// PollerConfiguration the content of poller-configuration.xml
+type PollerConfiguration struct {
+	XMLName                    xml.Name          `xml:"poller-configuration" json:"-" yaml:"-"`
+	Threads                    int               `xml:"threads,attr,omitempty" json:"threads,omitempty" yaml:"threads,omitempty"`
+	NextOutageID               string            `xml:"nextOutageId,attr,omitempty" json:"nextOutageId,omitempty" yaml:"nextOutageId,omitempty"`
+	ServiceUnresponsiveEnabled string            `xml:"serviceUnresponsiveEnabled,attr,omitempty" json:"serviceUnresponsiveEnabled,omitempty" yaml:"serviceUnresponsiveEnabled,omitempty"`
+	PathOutageEnabled          string            `xml:"pathOutageEnabled,attr,omitempty" json:"pathOutageEnabled,omitempty" yaml:"pathOutageEnabled,omitempty"`
+	NodeOutage                 *PollerNodeOutage `xml:"node-outage,omitempty" json:"node-outage,omitempty" yaml:"nodeOutage,omitempty"`
+	Packages                   []PollerPackage   `xml:"package" json:"package" yaml:"packages"`
+	Monitors                   []Monitor         `xml:"monitor" json:"monitor" yaml:"monitors"`
+}
+
+// PollerNodeOutage how the outages of the critical service are propagated to the node
+type PollerNodeOutage struct {
+	Status                            string                 `xml:"status,attr,omitempty" json:"status,omitempty" yaml:"status,omitempty"`
+	PollAllIfNoCriticalServiceDefined string                 `xml:"pollAllIfNoCriticalServiceDefined,attr,omitempty" json:"pollAllIfNoCriticalServiceDefined,omitempty" yaml:"pollAllIfNoCriticalServiceDefined,omitempty"`
+	CriticalService                   *PollerCriticalService `xml:"critical-service,omitempty" json:"critical-service,omitempty" yaml:"criticalService,omitempty"`
+}
+
+// PollerCriticalService the service that determines whether a node is down
+type PollerCriticalService struct {
+	Name string `xml:"name,attr" json:"name" yaml:"name"`
+}
+
+// PollerPackage a group of services polled on the interfaces selected by a filter and a set of addresses
+type PollerPackage struct {
+	Name            string           `xml:"name,attr" json:"name" yaml:"name" required:"true"`
+	Remote          bool             `xml:"remote,attr,omitempty" json:"remote,omitempty" yaml:"remote,omitempty"` // Remote packages are ignored by Pollerd
+	Filter          string           `xml:"filter" json:"filter" yaml:"filter"`
+	Specifics       []string         `xml:"specific,omitempty" json:"specific,omitempty" yaml:"specifics,omitempty"`
+	IncludeRanges   []PollerRange    `xml:"include-range,omitempty" json:"include-range,omitempty" yaml:"includeRanges,omitempty"`
+	ExcludeRanges   []PollerRange    `xml:"exclude-range,omitempty" json:"exclude-range,omitempty" yaml:"excludeRanges,omitempty"`
+	IncludeURLs     []string         `xml:"include-url,omitempty" json:"include-url,omitempty" yaml:"includeURLs,omitempty"`
+	RRD             *PollerRRD       `xml:"rrd,omitempty" json:"rrd,omitempty" yaml:"rrd,omitempty"`
+	Services        []PollerService  `xml:"service,omitempty" json:"service,omitempty" yaml:"services,omitempty"`
+	OutageCalendars []string         `xml:"outage-calendar,omitempty" json:"outage-calendar,omitempty" yaml:"outageCalendars,omitempty"`
+	Downtimes       []PollerDowntime `xml:"downtime,omitempty" json:"downtime,omitempty" yaml:"downtimes,omitempty"`
+}
+
+// PollerRange a range of IP addresses of a poller package
+type PollerRange struct {
+	Begin string `xml:"begin,attr" json:"begin" yaml:"begin"`
+	End   string `xml:"end,attr" json:"end" yaml:"end"`
+}
+
+// PollerRRD how the response times of a poller package are stored
+type PollerRRD struct {
+	Step int      `xml:"step,attr,omitempty" json:"step,omitempty" yaml:"step,omitempty"`
+	RRAs []string `xml:"rra,omitempty" json:"rra,omitempty" yaml:"rras,omitempty"`
+}
+
+// PollerService a service polled by a package, with the parameters of its monitor
+type PollerService struct {
+	Name        string            `xml:"name,attr" json:"name" yaml:"name" required:"true"`
+	Interval    int64             `xml:"interval,attr" json:"interval" yaml:"interval"` // In milliseconds
+	UserDefined string            `xml:"user-defined,attr,omitempty" json:"user-defined,omitempty" yaml:"userDefined,omitempty"`
+	Status      string            `xml:"status,attr,omitempty" json:"status,omitempty" yaml:"status,omitempty"`
+	Pattern     string            `xml:"pattern,omitempty" json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Parameters  []PollerParameter `xml:"parameter,omitempty" json:"parameter,omitempty" yaml:"parameters,omitempty"`
+}
+
+// IsEnabled returns true when the service is polled; services without a status are enabled
+func (s PollerService) IsEnabled() bool {
+	return s.Status == "" || s.Status == PollerServiceOn
+}
+
+// PollerParameter a parameter of a poller service
+type PollerParameter struct {
+	Key   string `xml:"key,attr" json:"key" yaml:"key"`
+	Value string `xml:"value,attr,omitempty" json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// PollerDowntime how the polling interval changes while a service is down
+type PollerDowntime struct {
+	Begin    int64  `xml:"begin,attr" json:"begin" yaml:"begin"`
+	End      int64  `xml:"end,attr,omitempty" json:"end,omitempty" yaml:"end,omitempty"`
+	Interval int64  `xml:"interval,attr,omitempty" json:"interval,omitempty" yaml:"interval,omitempty"`
+	Delete   string `xml:"delete,attr,omitempty" json:"delete,omitempty" yaml:"delete,omitempty"`
+}
+
+// Monitor the class that polls a service
+type Monitor struct {
+	Service    string            `xml:"service,attr" json:"service" yaml:"service" required:"true"`
+	ClassName  string            `xml:"class-name,attr" json:"class-name" yaml:"className"`
+	Parameters []PollerParameter `xml:"parameter,omitempty" json:"parameter,omitempty" yaml:"parameters,omitempty"`
+}
+
+// GetMonitor returns the monitor of a service, or nil when the service has no monitor
+func (c PollerConfiguration) GetMonitor(service string) *Monitor {
+	for i, m := range c.Monitors {
+		if m.Service == service {
+			return &c.Monitors[i]
+		}
+	}
+	return nil
+}
+
+// IncludesAddress returns true when the IP address is part of the specifics or the include ranges of the package,
+// and not part of its exclude ranges; the include URLs are files on the server, so an address that only
+// could be included through them returns an UnsupportedFilterError
+func (p PollerPackage) IncludesAddress(ipAddress string) (bool, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return false, fmt.Errorf("Invalid IP address %s", ipAddress)
+	}
+	for _, r := range p.ExcludeRanges {
+		if inRange(ip, r) {
+			return false, nil
+		}
+	}
+	for _, s := range p.Specifics {
+		if specific := net.ParseIP(s); specific != nil && specific.Equal(ip) {
+			return true, nil
+		}
+	}
+	for _, r := range p.IncludeRanges {
+		if inRange(ip, r) {
+			return true, nil
+		}
+	}
+	if len(p.IncludeURLs) > 0 {
+		return false, UnsupportedFilterError{Construct: "include-url " + p.IncludeURLs[0]}
+	}
+	return false, nil
+}
+
+func inRange(ip net.IP, r PollerRange) bool {
+	begin := net.ParseIP(r.Begin)
+	end := net.ParseIP(r.End)
+	if begin == nil || end == nil {
+		return false
+	}
+	return bytes.Compare(ip.To16(), begin.To16()) >= 0 && bytes.Compare(ip.To16(), end.To16()) <= 0
+}
+
+// PollerEvaluation the poller packages and services that apply to the IP interfaces of a node
+type PollerEvaluation struct {
+	Node        string                     `json:"node" yaml:"node"`
+	Source      string                     `json:"source" yaml:"source"` // server, or client when evaluated by onmsctl
+	Services    []PollerServiceMatch       `json:"services" yaml:"services"`
+	Unevaluated []PollerUnevaluatedPackage `json:"unevaluated,omitempty" yaml:"unevaluated,omitempty"`
+}
+
+// PollerServiceMatch a service of a package that applies to an IP interface
+type PollerServiceMatch struct {
+	IPAddress string `json:"ipAddress" yaml:"ipAddress"`
+	Package   string `json:"package" yaml:"package"`
+	Service   string `json:"service" yaml:"service"`
+	Interval  int64  `json:"interval" yaml:"interval"`
+	Status    string `json:"status,omitempty" yaml:"status,omitempty"`
+	Effective bool   `json:"effective" yaml:"effective"` // Whether Pollerd uses this package for the service on the interface
+}
+
+// PollerUnevaluatedPackage a package that couldn't be evaluated on the client side, with the reason
+type PollerUnevaluatedPackage struct {
+	Package string `json:"package" yaml:"package"`
+	Reason  string `json:"reason" yaml:"reason"`
+}
+
+// The sources of a poller evaluation
+const (
+	PollerEvaluationServer = "server"
+	PollerEvaluationClient = "client"
+)
+
+// EvaluateNode evaluates the packages against each IP interface of a node with the given categories.
+// Like Pollerd, a service is polled through the last enabled package that includes the interface, so only that match
+// is effective; packages whose filter or addresses cannot be evaluated on the client side are reported as unevaluated
+func (c PollerConfiguration) EvaluateNode(node string, categories []string, addresses []string) *PollerEvaluation {
+	evaluation := &PollerEvaluation{Node: node, Source: PollerEvaluationClient, Services: make([]PollerServiceMatch, 0)}
+	unevaluated := make(map[string]bool)
+	for _, address := range addresses {
+		effective := make(map[string]int)
+		for _, pkg := range c.Packages {
+			if pkg.Remote {
+				continue
+			}
+			matches, err := pkg.appliesTo(PollerFilterTarget{IPAddress: address, Categories: categories})
+			if err != nil {
+				if !unevaluated[pkg.Name] {
+					unevaluated[pkg.Name] = true
+					evaluation.Unevaluated = append(evaluation.Unevaluated, PollerUnevaluatedPackage{Package: pkg.Name, Reason: err.Error()})
+				}
+				continue
+			}
+			if !matches {
+				continue
+			}
+			for _, svc := range pkg.Services {
+				if svc.IsEnabled() {
+					if previous, ok := effective[svc.Name]; ok {
+						evaluation.Services[previous].Effective = false
+					}
+					effective[svc.Name] = len(evaluation.Services)
+				}
+				evaluation.Services = append(evaluation.Services, PollerServiceMatch{
+					IPAddress: address,
+					Package:   pkg.Name,
+					Service:   svc.Name,
+					Interval:  svc.Interval,
+					Status:    svc.Status,
+					Effective: svc.IsEnabled(),
+				})
+			}
+		}
+	}
+	return evaluation
+}
+
+// Returns true when both the filter and the addresses of the package include the target
+func (p PollerPackage) appliesTo(target PollerFilterTarget) (bool, error) {
+	matches, err := EvaluatePollerFilter(p.Filter, target)
+	if err != nil || !matches {
+		return false, err
+	}
+	return p.IncludesAddress(target.IPAddress)
+}
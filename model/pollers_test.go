@@ -0,0 +1,102 @@
+package model
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const pollerConfigXML = `<?xml version="1.0"?>
+<poller-configuration threads="30" serviceUnresponsiveEnabled="false" pathOutageEnabled="false">
+  <node-outage status="on" pollAllIfNoCriticalServiceDefined="true">
+    <critical-service name="ICMP"/>
+  </node-outage>
+  <package name="example1">
+    <filter>IPADDR != '0.0.0.0'</filter>
+    <include-range begin="1.1.1.1" end="254.254.254.254"/>
+    <include-range begin="::1" end="ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"/>
+    <rrd step="300"><rra>RRA:AVERAGE:0.5:1:2016</rra></rrd>
+    <service name="ICMP" interval="300000" user-defined="false" status="on">
+      <parameter key="retry" value="2"/>
+    </service>
+    <service name="SNMP" interval="300000" user-defined="false" status="off"/>
+    <downtime begin="0" end="300000" interval="30000"/>
+    <downtime begin="432000000" delete="never"/>
+  </package>
+  <package name="production">
+    <filter>catincProduction &amp; IPADDR IPLIKE 10.*.*.*</filter>
+    <include-range begin="10.0.0.1" end="10.255.255.254"/>
+    <exclude-range begin="10.0.9.1" end="10.0.9.254"/>
+    <service name="ICMP" interval="60000" status="on"/>
+    <service name="SNMP" interval="60000" status="on"/>
+  </package>
+  <package name="switches">
+    <filter>nodeSysOID LIKE '.1.3.6.1.4.1.9.%'</filter>
+    <include-range begin="1.1.1.1" end="254.254.254.254"/>
+    <service name="SNMP" interval="30000"/>
+  </package>
+  <monitor service="ICMP" class-name="org.opennms.netmgt.poller.monitors.IcmpMonitor"/>
+  <monitor service="SNMP" class-name="org.opennms.netmgt.poller.monitors.SnmpMonitor"/>
+</poller-configuration>`
+
+func TestParsePollerConfiguration(t *testing.T) {
+	config := PollerConfiguration{}
+	assert.NilError(t, xml.Unmarshal([]byte(pollerConfigXML), &config))
+	assert.Equal(t, 3, len(config.Packages))
+	pkg := config.Packages[0]
+	assert.Equal(t, "IPADDR != '0.0.0.0'", pkg.Filter)
+	assert.Equal(t, 2, len(pkg.Services))
+	assert.Equal(t, "2", pkg.Services[0].Parameters[0].Value)
+	assert.Assert(t, pkg.Services[0].IsEnabled())
+	assert.Assert(t, !pkg.Services[1].IsEnabled())
+	assert.Equal(t, "never", pkg.Downtimes[1].Delete)
+	assert.Equal(t, "ICMP", config.NodeOutage.CriticalService.Name)
+	assert.Equal(t, "org.opennms.netmgt.poller.monitors.SnmpMonitor", config.GetMonitor("SNMP").ClassName)
+	assert.Assert(t, config.GetMonitor("HTTP") == nil)
+}
+
+func TestPackageIncludesAddress(t *testing.T) {
+	pkg := PollerPackage{
+		Specifics:     []string{"192.168.0.1"},
+		IncludeRanges: []PollerRange{{Begin: "10.0.0.1", End: "10.0.0.254"}},
+		ExcludeRanges: []PollerRange{{Begin: "10.0.0.100", End: "10.0.0.110"}},
+	}
+	for address, expected := range map[string]bool{"192.168.0.1": true, "10.0.0.5": true, "10.0.0.105": false, "10.0.1.5": false} {
+		included, err := pkg.IncludesAddress(address)
+		assert.NilError(t, err)
+		assert.Equal(t, expected, included, address)
+	}
+	_, err := pkg.IncludesAddress("10.0.0")
+	assert.Error(t, err, "Invalid IP address 10.0.0")
+
+	pkg.IncludeURLs = []string{"file:/opt/opennms/etc/include"}
+	included, err := pkg.IncludesAddress("10.0.0.5")
+	assert.NilError(t, err)
+	assert.Assert(t, included)
+	_, err = pkg.IncludesAddress("172.16.0.1")
+	assert.Error(t, err, "include-url file:/opt/opennms/etc/include cannot be evaluated on the client side")
+}
+
+func TestEvaluateNode(t *testing.T) {
+	config := PollerConfiguration{}
+	assert.NilError(t, xml.Unmarshal([]byte(pollerConfigXML), &config))
+
+	evaluation := config.EvaluateNode("Servers:web01", []string{"Production"}, []string{"10.0.0.1", "10.0.9.1"})
+	assert.Equal(t, PollerEvaluationClient, evaluation.Source)
+	assert.DeepEqual(t, []PollerServiceMatch{
+		{IPAddress: "10.0.0.1", Package: "example1", Service: "ICMP", Interval: 300000, Status: "on", Effective: false},
+		{IPAddress: "10.0.0.1", Package: "example1", Service: "SNMP", Interval: 300000, Status: "off", Effective: false},
+		{IPAddress: "10.0.0.1", Package: "production", Service: "ICMP", Interval: 60000, Status: "on", Effective: true},
+		{IPAddress: "10.0.0.1", Package: "production", Service: "SNMP", Interval: 60000, Status: "on", Effective: true},
+		{IPAddress: "10.0.9.1", Package: "example1", Service: "ICMP", Interval: 300000, Status: "on", Effective: true},
+		{IPAddress: "10.0.9.1", Package: "example1", Service: "SNMP", Interval: 300000, Status: "off", Effective: false},
+	}, evaluation.Services)
+	assert.DeepEqual(t, []PollerUnevaluatedPackage{
+		{Package: "switches", Reason: "nodeSysOID cannot be evaluated on the client side"},
+	}, evaluation.Unevaluated)
+
+	evaluation = config.EvaluateNode("Servers:web02", nil, []string{"10.0.0.2"})
+	assert.Equal(t, 2, len(evaluation.Services))
+	assert.Equal(t, "example1", evaluation.Services[0].Package)
+}
@@ -5,12 +5,108 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"strings"
 
 	"github.com/imdario/mergo"
 )
 
-// AllowFqdnOnRequisitionedInterfaces when this is true, if the content of an IP Address is a FQDN it will be translated into a valid IPv4
-var AllowFqdnOnRequisitionedInterfaces = true
+// Strategies to handle a FQDN that resolves to multiple addresses of the preferred family on requisitioned interfaces
+const (
+	ResolveFail  = "fail"  // Reject the interface
+	ResolveFirst = "first" // Use the first address
+	ResolveAll   = "all"   // Create one interface per resolved address (only on nodes)
+)
+
+// ResolveStrategies the valid strategies to handle a FQDN with multiple addresses
+var ResolveStrategies = []string{ResolveFail, ResolveFirst, ResolveAll}
+
+// ResolverConfig defines how a FQDN used as the IP address of a requisitioned interface is translated
+type ResolverConfig struct {
+	Enabled    bool // When false, a FQDN is rejected as an invalid IP address
+	PreferIPv6 bool // By default, IPv4 addresses are preferred
+	Strategy   string
+	LookupIP   func(host string) ([]net.IP, error) // Replaceable, to avoid live DNS queries on tests
+	Log        Logger                              // Where the translations are reported
+}
+
+// NewResolverConfig returns the default resolver configuration, that uses the system resolver
+func NewResolverConfig() *ResolverConfig {
+	return &ResolverConfig{
+		Enabled:  true,
+		Strategy: ResolveFail,
+		LookupIP: net.LookupIP,
+		Log:      NopLogger{},
+	}
+}
+
+// Resolver the configuration used to translate FQDNs while validating requisitioned interfaces
+var Resolver = NewResolverConfig()
+
+// Validate returns an error if the resolver configuration is invalid
+func (r *ResolverConfig) Validate() error {
+	for _, s := range ResolveStrategies {
+		if r.Strategy == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid resolve strategy %s, valid options: %s", r.Strategy, strings.Join(ResolveStrategies, ", "))
+}
+
+// Resolve returns all the addresses of a FQDN without duplicates, those of the preferred family first
+func (r *ResolverConfig) Resolve(name string) ([]string, error) {
+	ips, err := r.LookupIP(name)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot get address from %s (invalid IP or FQDN); %w", name, err)
+	}
+	preferred := make([]string, 0, len(ips))
+	others := make([]string, 0, len(ips))
+	seen := make(map[string]bool)
+	for _, ip := range ips {
+		address := ip.String()
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		if (ip.To4() == nil) == r.PreferIPv6 {
+			preferred = append(preferred, address)
+		} else {
+			others = append(others, address)
+		}
+	}
+	if len(preferred)+len(others) == 0 {
+		return nil, fmt.Errorf("Cannot get address from %s (invalid IP or FQDN); no addresses found", name)
+	}
+	return append(preferred, others...), nil
+}
+
+// Returns the addresses to use for a FQDN according to the strategy;
+// only the addresses of the preferred family are considered, unless there are none of them
+func (r *ResolverConfig) translate(name string) ([]string, error) {
+	addresses, err := r.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if r.Strategy == ResolveAll {
+		return addresses, nil
+	}
+	candidates := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if len(candidates) == 0 || isIPv6(address) == isIPv6(candidates[0]) {
+			candidates = append(candidates, address)
+		}
+	}
+	if len(candidates) > 1 && r.Strategy != ResolveFirst {
+		return nil, fmt.Errorf("%s resolves to multiple addresses (%s); use a resolve strategy of first or all", name, strings.Join(candidates, ", "))
+	}
+	return candidates[:1], nil
+}
+
+func isIPv6(address string) bool {
+	return net.ParseIP(address).To4() == nil
+}
+
+// DefaultMaxExpandedInterfaces the default maximum number of IP interfaces that can be generated from a CIDR block
+const DefaultMaxExpandedInterfaces = 1024
 
 // RequisitionMetaData a meta-data entry
 type RequisitionMetaData struct {
@@ -34,6 +130,43 @@ func (m *RequisitionMetaData) Validate() error {
 	return nil
 }
 
+// DefaultMetaDataContext the context used for meta-data entries when none is specified
+const DefaultMetaDataContext = "requisition"
+
+// SetMetaDataEntry adds an entry to a list of meta-data, or replaces the value of the existing entry with the same context and key
+func SetMetaDataEntry(entries []RequisitionMetaData, context string, key string, value string) []RequisitionMetaData {
+	if context == "" {
+		context = DefaultMetaDataContext
+	}
+	for i := range entries {
+		if metaDataContext(entries[i]) == context && entries[i].Key == key {
+			entries[i].Value = value
+			return entries
+		}
+	}
+	return append(entries, RequisitionMetaData{Context: context, Key: key, Value: value})
+}
+
+// DeleteMetaDataEntry removes the entry with a given context and key from a list of meta-data, returns false if the entry doesn't exist
+func DeleteMetaDataEntry(entries []RequisitionMetaData, context string, key string) ([]RequisitionMetaData, bool) {
+	if context == "" {
+		context = DefaultMetaDataContext
+	}
+	for i := range entries {
+		if metaDataContext(entries[i]) == context && entries[i].Key == key {
+			return append(entries[:i], entries[i+1:]...), true
+		}
+	}
+	return entries, false
+}
+
+func metaDataContext(m RequisitionMetaData) string {
+	if m.Context == "" {
+		return DefaultMetaDataContext
+	}
+	return m.Context
+}
+
 // RequisitionMonitoredService an IP interface monitored service
 type RequisitionMonitoredService struct {
 	XMLName  xml.Name              `xml:"monitored-service" json:"-" yaml:"-"`
@@ -134,7 +267,7 @@ func (c RequisitionCategory) Validate() error {
 // RequisitionInterface an IP interface of a requisition node
 type RequisitionInterface struct {
 	XMLName     xml.Name                      `xml:"interface" json:"-" yaml:"-"`
-	IPAddress   string                        `xml:"ip-addr,attr" json:"ip-addr" yaml:"ipAddress"`
+	IPAddress   string                        `xml:"ip-addr,attr" json:"ip-addr" yaml:"ipAddress" required:"true"`
 	Description string                        `xml:"descr,attr,omitempty" json:"descr,omitempty" yaml:"description,omitempty"`
 	SnmpPrimary string                        `xml:"snmp-primary,attr,omitempty" json:"snmp-primary" yaml:"snmpPrimary"`
 	Status      int                           `xml:"status,attr,omitempty" json:"status" yaml:"status"`
@@ -193,6 +326,19 @@ func (intf *RequisitionInterface) AddService(svc *RequisitionMonitoredService) {
 	intf.Services = append(intf.Services, *svc)
 }
 
+// DeleteService removes all the occurrences of a given monitored service from the IP interface; returns false if it doesn't exist
+func (intf *RequisitionInterface) DeleteService(serviceName string) bool {
+	services := make([]RequisitionMonitoredService, 0, len(intf.Services))
+	for _, svc := range intf.Services {
+		if svc.Name != serviceName {
+			services = append(services, svc)
+		}
+	}
+	found := len(services) != len(intf.Services)
+	intf.Services = services
+	return found
+}
+
 // Validate returns an error if the interface definition is invalid
 func (intf *RequisitionInterface) Validate() error {
 	if intf.IPAddress == "" {
@@ -231,22 +377,109 @@ func (intf *RequisitionInterface) Merge(source RequisitionInterface) error {
 }
 
 func (intf *RequisitionInterface) validateIP() error {
-	ip := net.ParseIP(intf.IPAddress)
+	address, zone := SplitIPZone(intf.IPAddress)
+	ip := net.ParseIP(address)
+	if ip != nil && zone != "" {
+		if ip.To4() != nil {
+			return fmt.Errorf("Zone identifiers are only valid for IPv6 addresses: %s", intf.IPAddress)
+		}
+		intf.IPAddress = address // OpenNMS doesn't accept zone identifiers
+	}
 	if ip == nil {
-		if AllowFqdnOnRequisitionedInterfaces {
-			addresses, err := net.LookupIP(intf.IPAddress)
-			if err != nil || len(addresses) == 0 {
-				return fmt.Errorf("Cannot get address from %s (invalid IP or FQDN); %s", intf.IPAddress, err)
-			}
-			fmt.Printf("%s translates to %s.\n", intf.IPAddress, addresses[0].String())
-			intf.IPAddress = addresses[0].String()
-		} else {
+		if !Resolver.Enabled {
 			return fmt.Errorf("%s is not a valid IPv4 or IPv6 address", intf.IPAddress)
 		}
+		addresses, err := Resolver.translate(intf.IPAddress)
+		if err != nil {
+			return err
+		}
+		if len(addresses) > 1 {
+			return fmt.Errorf("%s resolves to multiple addresses (%s); one interface per address can only be created when validating its node", intf.IPAddress, strings.Join(addresses, ", "))
+		}
+		Resolver.Log.Infof("%s translates to %s.", intf.IPAddress, addresses[0])
+		intf.IPAddress = addresses[0]
 	}
 	return nil
 }
 
+// Returns true if the IP address of the interface is a FQDN that should be translated
+func (intf *RequisitionInterface) hasHostname() bool {
+	address, _ := SplitIPZone(intf.IPAddress)
+	return Resolver.Enabled && intf.IPAddress != "" && net.ParseIP(address) == nil
+}
+
+// Returns a copy of the interface with another IP address, that doesn't share its services or meta-data
+func (intf RequisitionInterface) withAddress(address string) RequisitionInterface {
+	intf.IPAddress = address
+	intf.MetaData = append([]RequisitionMetaData(nil), intf.MetaData...)
+	services := make([]RequisitionMonitoredService, len(intf.Services))
+	for i, svc := range intf.Services {
+		svc.MetaData = append([]RequisitionMetaData(nil), svc.MetaData...)
+		services[i] = svc
+	}
+	if intf.Services == nil {
+		services = nil
+	}
+	intf.Services = services
+	return intf
+}
+
+// SplitIPZone splits an IPv6 address like fe80::1%eth0 into the address and the zone identifier
+func SplitIPZone(ipAddress string) (string, string) {
+	if idx := strings.LastIndex(ipAddress, "%"); idx >= 0 {
+		return ipAddress[:idx], ipAddress[idx+1:]
+	}
+	return ipAddress, ""
+}
+
+// ExpandCIDR returns the IP addresses of a given CIDR block, failing when there are more than maxInterfaces;
+// for IPv4, the network and broadcast addresses are excluded, except for /31 and /32 blocks
+func ExpandCIDR(cidr string, maxInterfaces int) ([]string, error) {
+	ip, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid CIDR block %s", cidr)
+	}
+	ones, bits := network.Mask.Size()
+	if bits-ones >= 31 || 1<<uint(bits-ones) > maxInterfaces+2 {
+		return nil, fmt.Errorf("CIDR block %s generates more than %d IP interfaces", cidr, maxInterfaces)
+	}
+	skipEdges := ip.To4() != nil && bits-ones > 1
+	addresses := make([]string, 0)
+	current := make(net.IP, len(network.IP))
+	copy(current, network.IP)
+	for ; network.Contains(current); incrementIP(current) {
+		addresses = append(addresses, current.String())
+		if isLastIP(current) {
+			break
+		}
+	}
+	if skipEdges {
+		addresses = addresses[1 : len(addresses)-1]
+	}
+	if len(addresses) > maxInterfaces {
+		return nil, fmt.Errorf("CIDR block %s generates more than %d IP interfaces", cidr, maxInterfaces)
+	}
+	return addresses, nil
+}
+
+func incrementIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+}
+
+func isLastIP(ip net.IP) bool {
+	for _, b := range ip {
+		if b != 0xff {
+			return false
+		}
+	}
+	return true
+}
+
 func (intf *RequisitionInterface) validateServices() error {
 	serviceMap := make(map[string]int)
 	for i := range intf.Services {
@@ -267,8 +500,8 @@ func (intf *RequisitionInterface) validateServices() error {
 // RequisitionNode a requisitioned node
 type RequisitionNode struct {
 	XMLName             xml.Name               `xml:"node" json:"-" yaml:"-"`
-	NodeLabel           string                 `xml:"node-label,attr" json:"node-label" yaml:"nodeLabel"`
-	ForeignID           string                 `xml:"foreign-id,attr" json:"foreign-id" yaml:"foreignID"`
+	NodeLabel           string                 `xml:"node-label,attr" json:"node-label" yaml:"nodeLabel" required:"true"`
+	ForeignID           string                 `xml:"foreign-id,attr" json:"foreign-id" yaml:"foreignID" required:"true"`
 	Location            string                 `xml:"location,attr,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
 	City                string                 `xml:"city,attr,omitempty" json:"city,omitempty" yaml:"city,omitempty"`
 	Building            string                 `xml:"building,attr,omitempty" json:"building,omitempty" yaml:"building,omitempty"`
@@ -330,11 +563,148 @@ func (n *RequisitionNode) GetInterface(ipAddress string) *RequisitionInterface {
 	return nil
 }
 
+// SetInterfaceAddress changes the IP address of an existing interface, keeping the rest of its content (services, meta-data, flags)
+func (n *RequisitionNode) SetInterfaceAddress(oldIP string, newIP string) error {
+	if oldIP == newIP {
+		return fmt.Errorf("The new IP address must be different than %s", oldIP)
+	}
+	if n.GetInterface(newIP) != nil {
+		return fmt.Errorf("IP Address %s already exists on node %s", newIP, n.NodeLabel)
+	}
+	intf := n.findInterface(oldIP)
+	if intf == nil {
+		return fmt.Errorf("IP Address %s doesn't exist on node %s", oldIP, n.NodeLabel)
+	}
+	intf.IPAddress = newIP
+	return nil
+}
+
+// SwapInterfaceAddresses exchanges the IP addresses and the primary flags of two interfaces,
+// so the services and meta-data of each interface move to the address of the other one
+func (n *RequisitionNode) SwapInterfaceAddresses(ip1 string, ip2 string) error {
+	if ip1 == ip2 {
+		return fmt.Errorf("Cannot swap IP Address %s with itself", ip1)
+	}
+	intf1 := n.findInterface(ip1)
+	if intf1 == nil {
+		return fmt.Errorf("IP Address %s doesn't exist on node %s", ip1, n.NodeLabel)
+	}
+	intf2 := n.findInterface(ip2)
+	if intf2 == nil {
+		return fmt.Errorf("IP Address %s doesn't exist on node %s", ip2, n.NodeLabel)
+	}
+	intf1.IPAddress, intf2.IPAddress = intf2.IPAddress, intf1.IPAddress
+	intf1.SnmpPrimary, intf2.SnmpPrimary = intf2.SnmpPrimary, intf1.SnmpPrimary
+	return nil
+}
+
+func (n *RequisitionNode) findInterface(ipAddress string) *RequisitionInterface {
+	for i := range n.Interfaces {
+		if n.Interfaces[i].IPAddress == ipAddress {
+			return &n.Interfaces[i]
+		}
+	}
+	return nil
+}
+
 // Merge merges the fields from the provided source
 func (n *RequisitionNode) Merge(source RequisitionNode) error {
 	return mergo.Merge(n, source, mergo.WithOverride)
 }
 
+// SetCategories replaces all the categories of the node, ignoring duplicates;
+// returns the names of the categories that were removed and added
+func (n *RequisitionNode) SetCategories(names []string) ([]string, []string) {
+	current := make(map[string]bool)
+	for _, c := range n.Categories {
+		current[c.Name] = true
+	}
+	desired := make(map[string]bool)
+	categories := make([]RequisitionCategory, 0, len(names))
+	added := make([]string, 0)
+	for _, name := range names {
+		if desired[name] {
+			continue
+		}
+		desired[name] = true
+		categories = append(categories, RequisitionCategory{Name: name})
+		if !current[name] {
+			added = append(added, name)
+		}
+	}
+	removed := make([]string, 0)
+	for _, c := range n.Categories {
+		if !desired[c.Name] {
+			removed = append(removed, c.Name)
+		}
+	}
+	n.Categories = categories
+	return removed, added
+}
+
+// SetAssets replaces all the assets of the node, where the last value wins for duplicated names;
+// returns the names of the assets that were removed, added and changed
+func (n *RequisitionNode) SetAssets(assets []RequisitionAsset) ([]string, []string, []string) {
+	current := make(map[string]string)
+	for _, a := range n.Assets {
+		current[a.Name] = a.Value
+	}
+	index := make(map[string]int)
+	desired := make([]RequisitionAsset, 0, len(assets))
+	for _, a := range assets {
+		if i, ok := index[a.Name]; ok {
+			desired[i].Value = a.Value
+			continue
+		}
+		index[a.Name] = len(desired)
+		desired = append(desired, RequisitionAsset{Name: a.Name, Value: a.Value})
+	}
+	removed := make([]string, 0)
+	for _, a := range n.Assets {
+		if _, ok := index[a.Name]; !ok {
+			removed = append(removed, a.Name)
+		}
+	}
+	added := make([]string, 0)
+	changed := make([]string, 0)
+	for _, a := range desired {
+		if value, ok := current[a.Name]; !ok {
+			added = append(added, a.Name)
+		} else if value != a.Value {
+			changed = append(changed, a.Name)
+		}
+	}
+	n.Assets = desired
+	return removed, added, changed
+}
+
+// SetAsset adds an asset to the node, or replaces the value of the existing one (removing duplicates with the same name);
+// returns true when the assets changed
+func (n *RequisitionNode) SetAsset(name string, value string) bool {
+	changed := true
+	assets := make([]RequisitionAsset, 0, len(n.Assets)+1)
+	found := false
+	for _, a := range n.Assets {
+		if a.Name != name {
+			assets = append(assets, a)
+			continue
+		}
+		if found {
+			continue
+		}
+		found = true
+		changed = a.Value != value
+		assets = append(assets, RequisitionAsset{Name: name, Value: value})
+	}
+	if !found {
+		assets = append(assets, RequisitionAsset{Name: name, Value: value})
+	} else if len(assets) < len(n.Assets) {
+		changed = true
+	}
+	n.Assets = assets
+	return changed
+}
+
 // Validate returns an error if the node definition is invalid
 func (n *RequisitionNode) Validate() error {
 	if n.ForeignID == "" {
@@ -380,17 +750,20 @@ func (n *RequisitionNode) Validate() error {
 }
 
 func (n *RequisitionNode) validateInterfaces() error {
+	if err := n.expandHostnames(); err != nil {
+		return err
+	}
 	primaryCount := 0
 	intfMap := make(map[string]int)
 	for i := range n.Interfaces {
 		intf := &n.Interfaces[i]
+		if err := intf.Validate(); err != nil {
+			return err
+		}
 		intfMap[intf.IPAddress]++
 		if intf.SnmpPrimary == "P" {
 			primaryCount++
 		}
-		if err := intf.Validate(); err != nil {
-			return err
-		}
 	}
 	if primaryCount > 1 {
 		return fmt.Errorf("Node %s cannot have more than one primary interface", n.NodeLabel)
@@ -403,13 +776,66 @@ func (n *RequisitionNode) validateInterfaces() error {
 	return nil
 }
 
+// Replaces the interfaces with a FQDN resolving to multiple addresses with one interface per address,
+// when the resolve strategy is all; only the first of them keeps the primary flag
+func (n *RequisitionNode) expandHostnames() error {
+	if Resolver.Strategy != ResolveAll {
+		return nil
+	}
+	interfaces := make([]RequisitionInterface, 0, len(n.Interfaces))
+	for _, intf := range n.Interfaces {
+		if !intf.hasHostname() {
+			interfaces = append(interfaces, intf)
+			continue
+		}
+		addresses, err := Resolver.translate(intf.IPAddress)
+		if err != nil {
+			return err
+		}
+		Resolver.Log.Infof("%s translates to %s.", intf.IPAddress, strings.Join(addresses, ", "))
+		for i, address := range addresses {
+			expanded := intf.withAddress(address)
+			if i > 0 && expanded.SnmpPrimary == "P" {
+				expanded.SnmpPrimary = "N"
+			}
+			interfaces = append(interfaces, expanded)
+		}
+	}
+	n.Interfaces = interfaces
+	return nil
+}
+
+// RescanMode how the existing nodes are handled when a requisition is imported (the rescanExisting parameter)
+type RescanMode string
+
+// The rescan modes of an import
+const (
+	RescanAll    RescanMode = "true"   // Update the database and execute the scan phase
+	RescanNone   RescanMode = "false"  // Add and delete nodes on the database, skipping the scan phase of the existing ones
+	RescanDBOnly RescanMode = "dbonly" // Add, delete and update nodes on the database, skipping the scan phase
+)
+
+// RescanModes the valid rescan modes
+var RescanModes = []string{string(RescanAll), string(RescanNone), string(RescanDBOnly)}
+
+// Validate returns an error if the rescan mode is invalid
+func (m RescanMode) Validate() error {
+	for _, mode := range RescanModes {
+		if string(m) == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid rescan mode %s; allowed values are %s", m, strings.Join(RescanModes, ", "))
+}
+
 // Requisition a requisition or set of nodes
 type Requisition struct {
-	XMLName    xml.Name          `xml:"model-import" json:"-" yaml:"-"`
-	DateStamp  *Time             `xml:"date-stamp,attr,omitempty" json:"date-stamp,omitempty" yaml:"dateStamp,omitempty"`
-	LastImport *Time             `xml:"last-import,attr,omitempty" json:"last-import,omitempty" yaml:"lastImport,omitempty"`
-	Name       string            `xml:"foreign-source,attr" json:"foreign-source" yaml:"name"`
-	Nodes      []RequisitionNode `xml:"node,omitempty" json:"node,omitempty" yaml:"nodes,omitempty"`
+	XMLName    xml.Name             `xml:"model-import" json:"-" yaml:"-"`
+	DateStamp  *Time                `xml:"date-stamp,attr,omitempty" json:"date-stamp,omitempty" yaml:"dateStamp,omitempty"`
+	LastImport *Time                `xml:"last-import,attr,omitempty" json:"last-import,omitempty" yaml:"lastImport,omitempty"`
+	Name       string               `xml:"foreign-source,attr" json:"foreign-source" yaml:"name" required:"true"`
+	Nodes      []RequisitionNode    `xml:"node,omitempty" json:"node,omitempty" yaml:"nodes,omitempty"`
+	Defaults   *RequisitionDefaults `xml:"-" json:"-" yaml:"defaults,omitempty"` // Client-side only, expanded into the nodes
 }
 
 // AddNode add a node to the requisition
@@ -417,6 +843,66 @@ func (r *Requisition) AddNode(node *RequisitionNode) {
 	r.Nodes = append(r.Nodes, *node)
 }
 
+// GetNode gets an existing node from the requisition
+func (r *Requisition) GetNode(foreignID string) *RequisitionNode {
+	for i := range r.Nodes {
+		if r.Nodes[i].ForeignID == foreignID {
+			return &r.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// NodesWithInterface returns the foreign IDs of the nodes that have an interface with the IP address
+func (r *Requisition) NodesWithInterface(ipAddress string) []string {
+	foreignIDs := make([]string, 0)
+	for i := range r.Nodes {
+		if r.Nodes[i].GetInterface(ipAddress) != nil {
+			foreignIDs = append(foreignIDs, r.Nodes[i].ForeignID)
+		}
+	}
+	return foreignIDs
+}
+
+// NodeError an error produced while sending a given node from a requisition
+type NodeError struct {
+	ForeignID string
+	Err       error
+}
+
+func (e NodeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.ForeignID, e.Err)
+}
+
+// Rename changes the name of the requisition, and the parent references to its own nodes;
+// returns the foreign IDs of the nodes whose parent foreign source was changed
+func (r *Requisition) Rename(name string) []string {
+	changed := make([]string, 0)
+	for i := range r.Nodes {
+		if n := &r.Nodes[i]; n.ParentForeignSource != "" && n.ParentForeignSource == r.Name {
+			n.ParentForeignSource = name
+			changed = append(changed, n.ForeignID)
+		}
+	}
+	r.Name = name
+	return changed
+}
+
+// Prune removes the nodes whose foreign ID is not on the keep set; returns the removed nodes
+func (r *Requisition) Prune(keep map[string]bool) []RequisitionNode {
+	kept := make([]RequisitionNode, 0, len(r.Nodes))
+	removed := make([]RequisitionNode, 0)
+	for _, n := range r.Nodes {
+		if keep[n.ForeignID] {
+			kept = append(kept, n)
+		} else {
+			removed = append(removed, n)
+		}
+	}
+	r.Nodes = kept
+	return removed
+}
+
 // Validate returns an error if the requisition definition is invalid
 func (r *Requisition) Validate() error {
 	if r.Name == "" {
@@ -439,7 +925,13 @@ func (r *Requisition) Validate() error {
 			return fmt.Errorf("Duplicate Foreign ID %s on requisition %s", id, r.Name)
 		}
 	}
-	return nil
+	return r.ValidateTopology()
+}
+
+// RequisitionCollection a list of requisitions
+type RequisitionCollection struct {
+	Count        int           `json:"count" yaml:"count"`
+	Requisitions []Requisition `json:"model-import" yaml:"requisitions"`
 }
 
 // RequisitionsList a list of requisitions names
@@ -458,8 +950,8 @@ type RequisitionStats struct {
 
 // RequisitionsStats statistics about all the requisitions
 type RequisitionsStats struct {
-	Count          int                `json:"count"`
-	ForeignSources []RequisitionStats `json:"foreign-source"`
+	Count          int                `json:"count" yaml:"count"`
+	ForeignSources []RequisitionStats `json:"foreign-source" yaml:"foreignSources"`
 }
 
 // GetRequisitionStats gets the stats of a given requisition
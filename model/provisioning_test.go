@@ -1,9 +1,11 @@
 package model
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"net"
 	"testing"
 	"time"
 
@@ -55,7 +57,45 @@ var testNode = RequisitionNode{
 	},
 }
 
+// bufferLogger writes the messages of the model to a buffer, one per line
+type bufferLogger struct {
+	*bytes.Buffer
+}
+
+func (l bufferLogger) Warnf(format string, args ...interface{}) {
+	fmt.Fprintf(l, "WARNING: "+format+"\n", args...)
+}
+
+func (l bufferLogger) Infof(format string, args ...interface{}) {
+	fmt.Fprintf(l, format+"\n", args...)
+}
+
+func (l bufferLogger) Debugf(format string, args ...interface{}) {
+	fmt.Fprintf(l, "DEBUG: "+format+"\n", args...)
+}
+
+// Replaces the system resolver with a static table, returning a function to restore the default configuration
+func useStaticResolver(hosts map[string][]string) (*bytes.Buffer, func()) {
+	log := &bytes.Buffer{}
+	Resolver = NewResolverConfig()
+	Resolver.Log = bufferLogger{log}
+	Resolver.LookupIP = func(host string) ([]net.IP, error) {
+		addresses, ok := hosts[host]
+		if !ok {
+			return nil, fmt.Errorf("lookup %s: no such host", host)
+		}
+		ips := make([]net.IP, len(addresses))
+		for i, a := range addresses {
+			ips[i] = net.ParseIP(a)
+		}
+		return ips, nil
+	}
+	return log, func() { Resolver = NewResolverConfig() }
+}
+
 func TestRequisitionObject(t *testing.T) {
+	_, restore := useStaticResolver(map[string][]string{"www.opennms.com": {"34.194.50.139"}})
+	defer restore()
 	req := &Requisition{
 		Name:      "Test",
 		DateStamp: &Time{time.Now()},
@@ -134,11 +174,70 @@ func TestInvalidRequisitionXML(t *testing.T) {
 	req := &Requisition{}
 	err := xml.Unmarshal([]byte(reqXML), req)
 	assert.NilError(t, err)
-	AllowFqdnOnRequisitionedInterfaces = false
+	Resolver.Enabled = false
+	defer func() { Resolver.Enabled = true }()
 	err = req.Validate()
 	assert.ErrorContains(t, err, "not a valid IPv4")
 }
 
+func TestResolver(t *testing.T) {
+	log, restore := useStaticResolver(map[string][]string{
+		"single":     {"10.0.0.1"},
+		"dual-stack": {"2001:db8::1", "10.0.0.1", "10.0.0.1"},
+		"multiple":   {"10.0.0.1", "10.0.0.2", "2001:db8::1"},
+		"ipv6-only":  {"2001:db8::1", "2001:db8::2"},
+	})
+	defer restore()
+
+	addresses, err := Resolver.Resolve("dual-stack")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"10.0.0.1", "2001:db8::1"}, addresses)
+	Resolver.PreferIPv6 = true
+	addresses, err = Resolver.Resolve("dual-stack")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"2001:db8::1", "10.0.0.1"}, addresses)
+	Resolver.PreferIPv6 = false
+
+	_, err = Resolver.Resolve("unknown")
+	assert.ErrorContains(t, err, "Cannot get address from unknown")
+
+	// Only the addresses of the preferred family are ambiguous
+	intf := RequisitionInterface{IPAddress: "dual-stack"}
+	assert.NilError(t, intf.Validate())
+	assert.Equal(t, "10.0.0.1", intf.IPAddress)
+	assert.Equal(t, "dual-stack translates to 10.0.0.1.\n", log.String())
+
+	intf = RequisitionInterface{IPAddress: "multiple"}
+	assert.Error(t, intf.Validate(), "multiple resolves to multiple addresses (10.0.0.1, 10.0.0.2); use a resolve strategy of first or all")
+
+	Resolver.Strategy = ResolveFirst
+	assert.NilError(t, intf.Validate())
+	assert.Equal(t, "10.0.0.1", intf.IPAddress)
+
+	Resolver.Strategy = ResolveAll
+	intf = RequisitionInterface{IPAddress: "ipv6-only"}
+	assert.ErrorContains(t, intf.Validate(), "one interface per address can only be created when validating its node")
+
+	node := RequisitionNode{
+		ForeignID: "n1",
+		Interfaces: []RequisitionInterface{
+			{IPAddress: "multiple", SnmpPrimary: "P", Services: []RequisitionMonitoredService{{Name: "ICMP"}}},
+			{IPAddress: "single"},
+		},
+	}
+	assert.Error(t, node.Validate(), "IP Address 10.0.0.1 is defined more than once on node n1")
+	node.Interfaces = []RequisitionInterface{{IPAddress: "multiple", SnmpPrimary: "P", Services: []RequisitionMonitoredService{{Name: "ICMP"}}}}
+	assert.NilError(t, node.Validate())
+	assert.Equal(t, 3, len(node.Interfaces))
+	assert.Equal(t, "10.0.0.2", node.Interfaces[1].IPAddress)
+	assert.Equal(t, "P", node.Interfaces[0].SnmpPrimary)
+	assert.Equal(t, "N", node.Interfaces[2].SnmpPrimary)
+	assert.Equal(t, "ICMP", node.Interfaces[2].Services[0].Name)
+
+	Resolver.Strategy = "any"
+	assert.Error(t, Resolver.Validate(), "Invalid resolve strategy any, valid options: fail, first, all")
+}
+
 func TestMetaData(t *testing.T) {
 	node := &RequisitionNode{
 		ForeignID: "n1",
@@ -218,3 +317,177 @@ func TestMergeNodes(t *testing.T) {
 	assert.Equal(t, "SW01", testNode.ParentForeignID)
 	assert.Equal(t, "important", testNode.MetaData[0].Key)
 }
+
+func TestSetCategoriesAndAssets(t *testing.T) {
+	node := RequisitionNode{
+		ForeignID:  "n1",
+		Categories: []RequisitionCategory{{Name: "Servers"}, {Name: "Dev"}},
+		Assets:     []RequisitionAsset{{Name: "city", Value: "Durham"}, {Name: "state", Value: "NC"}},
+	}
+
+	removed, added := node.SetCategories([]string{"Servers", "Production", "Servers"})
+	assert.DeepEqual(t, []string{"Dev"}, removed)
+	assert.DeepEqual(t, []string{"Production"}, added)
+	assert.DeepEqual(t, []RequisitionCategory{{Name: "Servers"}, {Name: "Production"}}, node.Categories)
+
+	removed, added, changed := node.SetAssets([]RequisitionAsset{{Name: "city", Value: "Apex"}, {Name: "building", Value: "HQ"}, {Name: "city", Value: "Raleigh"}})
+	assert.DeepEqual(t, []string{"state"}, removed)
+	assert.DeepEqual(t, []string{"building"}, added)
+	assert.DeepEqual(t, []string{"city"}, changed)
+	assert.DeepEqual(t, []RequisitionAsset{{Name: "city", Value: "Raleigh"}, {Name: "building", Value: "HQ"}}, node.Assets)
+}
+
+func TestSetAsset(t *testing.T) {
+	node := RequisitionNode{
+		ForeignID: "n1",
+		Assets:    []RequisitionAsset{{Name: "city", Value: "Durham"}, {Name: "state", Value: "NC"}, {Name: "city", Value: "Apex"}},
+	}
+	assert.Assert(t, node.SetAsset("city", "Raleigh"))
+	assert.Assert(t, !node.SetAsset("state", "NC"))
+	assert.Assert(t, node.SetAsset("zip", "27601"))
+	assert.DeepEqual(t, []RequisitionAsset{{Name: "city", Value: "Raleigh"}, {Name: "state", Value: "NC"}, {Name: "zip", Value: "27601"}}, node.Assets)
+	// Removing a duplicate is a change, even when the value is the same
+	node.Assets = append(node.Assets, RequisitionAsset{Name: "zip", Value: "27601"})
+	assert.Assert(t, node.SetAsset("zip", "27601"))
+	assert.Equal(t, 3, len(node.Assets))
+}
+
+func TestInterfaceZoneID(t *testing.T) {
+	intf := &RequisitionInterface{IPAddress: "fe80::1%eth0"}
+	assert.NilError(t, intf.Validate())
+	assert.Equal(t, "fe80::1", intf.IPAddress)
+
+	intf = &RequisitionInterface{IPAddress: "10.0.0.1%eth0"}
+	assert.ErrorContains(t, intf.Validate(), "Zone identifiers are only valid for IPv6")
+
+	node := &RequisitionNode{
+		ForeignID: "n1",
+		Interfaces: []RequisitionInterface{
+			{IPAddress: "fe80::1%eth0"},
+			{IPAddress: "fe80::1%eth1"},
+		},
+	}
+	assert.ErrorContains(t, node.Validate(), "IP Address fe80::1 is defined more than once")
+}
+
+func TestInterfaceDeleteService(t *testing.T) {
+	intf := &RequisitionInterface{IPAddress: "10.0.0.1", Services: []RequisitionMonitoredService{{Name: "ICMP"}, {Name: "HTTP"}, {Name: "HTTP"}}}
+	assert.ErrorContains(t, intf.Validate(), "Service HTTP is defined more than once on interface 10.0.0.1")
+	assert.Assert(t, intf.DeleteService("HTTP"))
+	assert.Assert(t, !intf.DeleteService("SNMP"))
+	assert.DeepEqual(t, []RequisitionMonitoredService{{Name: "ICMP"}}, intf.Services)
+	assert.NilError(t, intf.Validate())
+}
+
+func TestExpandCIDR(t *testing.T) {
+	addresses, err := ExpandCIDR("10.0.0.0/29", DefaultMaxExpandedInterfaces)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}, addresses)
+
+	addresses, err = ExpandCIDR("10.0.0.5/32", DefaultMaxExpandedInterfaces)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"10.0.0.5"}, addresses)
+
+	addresses, err = ExpandCIDR("2001:db8::/126", DefaultMaxExpandedInterfaces)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"2001:db8::", "2001:db8::1", "2001:db8::2", "2001:db8::3"}, addresses)
+
+	addresses, err = ExpandCIDR("10.0.0.0/22", DefaultMaxExpandedInterfaces)
+	assert.NilError(t, err)
+	assert.Equal(t, 1022, len(addresses))
+
+	_, err = ExpandCIDR("10.0.0.0/21", DefaultMaxExpandedInterfaces)
+	assert.ErrorContains(t, err, "generates more than 1024 IP interfaces")
+
+	_, err = ExpandCIDR("2001:db8::/64", DefaultMaxExpandedInterfaces)
+	assert.ErrorContains(t, err, "generates more than 1024 IP interfaces")
+
+	_, err = ExpandCIDR("10.0.0.0/29", 4)
+	assert.Error(t, err, "CIDR block 10.0.0.0/29 generates more than 4 IP interfaces")
+
+	_, err = ExpandCIDR("10.0.0.0", DefaultMaxExpandedInterfaces)
+	assert.Error(t, err, "Invalid CIDR block 10.0.0.0")
+}
+
+func TestMetaDataEntries(t *testing.T) {
+	entries := []RequisitionMetaData{{Key: "owner", Value: "agalue"}}
+	entries = SetMetaDataEntry(entries, "", "owner", "team")
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "team", entries[0].Value)
+
+	entries = SetMetaDataEntry(entries, "custom", "owner", "agalue")
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "custom", entries[1].Context)
+
+	entries, found := DeleteMetaDataEntry(entries, "other", "owner")
+	assert.Assert(t, !found)
+	assert.Equal(t, 2, len(entries))
+
+	entries, found = DeleteMetaDataEntry(entries, "requisition", "owner")
+	assert.Assert(t, found)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "custom", entries[0].Context)
+}
+
+func TestRenameRequisition(t *testing.T) {
+	req := &Requisition{
+		Name: "Old",
+		Nodes: []RequisitionNode{
+			{ForeignID: "n1", ParentForeignSource: "Old", ParentForeignID: "n2"},
+			{ForeignID: "n2", ParentForeignSource: "Other", ParentForeignID: "r1"},
+			{ForeignID: "n3"},
+		},
+	}
+	changed := req.Rename("New")
+	assert.Equal(t, "New", req.Name)
+	assert.DeepEqual(t, []string{"n1"}, changed)
+	assert.Equal(t, "New", req.Nodes[0].ParentForeignSource)
+	assert.Equal(t, "Other", req.Nodes[1].ParentForeignSource)
+	assert.Equal(t, "", req.Nodes[2].ParentForeignSource)
+}
+
+func TestPruneRequisition(t *testing.T) {
+	req := &Requisition{
+		Name:  "Test",
+		Nodes: []RequisitionNode{{ForeignID: "n1"}, {ForeignID: "n2"}, {ForeignID: "n3"}},
+	}
+	removed := req.Prune(map[string]bool{"n2": true, "unknown": true})
+	assert.Equal(t, 2, len(removed))
+	assert.Equal(t, "n1", removed[0].ForeignID)
+	assert.Equal(t, "n3", removed[1].ForeignID)
+	assert.Equal(t, 1, len(req.Nodes))
+	assert.Equal(t, "n2", req.Nodes[0].ForeignID)
+	assert.Equal(t, 0, len(req.Prune(map[string]bool{"n2": true})))
+}
+
+func TestSetInterfaceAddress(t *testing.T) {
+	node := &RequisitionNode{
+		ForeignID: "n1",
+		NodeLabel: "n1",
+		Interfaces: []RequisitionInterface{
+			{IPAddress: "10.0.0.1", SnmpPrimary: "P", Status: 1, Services: []RequisitionMonitoredService{{Name: "ICMP"}}, MetaData: []RequisitionMetaData{{Key: "owner", Value: "ops"}}},
+			{IPAddress: "10.0.0.2", SnmpPrimary: "S", Status: 3},
+		},
+	}
+	assert.NilError(t, node.SetInterfaceAddress("10.0.0.1", "10.0.0.10"))
+	intf := node.GetInterface("10.0.0.10")
+	assert.Assert(t, intf != nil)
+	assert.Assert(t, node.GetInterface("10.0.0.1") == nil)
+	assert.Equal(t, "P", intf.SnmpPrimary)
+	assert.Equal(t, "ICMP", intf.Services[0].Name)
+	assert.Equal(t, "ops", intf.MetaData[0].Value)
+
+	assert.Error(t, node.SetInterfaceAddress("10.0.0.1", "10.0.0.3"), "IP Address 10.0.0.1 doesn't exist on node n1")
+	assert.Error(t, node.SetInterfaceAddress("10.0.0.10", "10.0.0.2"), "IP Address 10.0.0.2 already exists on node n1")
+	assert.Error(t, node.SetInterfaceAddress("10.0.0.2", "10.0.0.2"), "The new IP address must be different than 10.0.0.2")
+
+	assert.NilError(t, node.SwapInterfaceAddresses("10.0.0.10", "10.0.0.2"))
+	assert.DeepEqual(t, RequisitionInterface{IPAddress: "10.0.0.2", SnmpPrimary: "S", Status: 1, Services: []RequisitionMonitoredService{{Name: "ICMP"}}, MetaData: []RequisitionMetaData{{Key: "owner", Value: "ops"}}}, node.Interfaces[0])
+	assert.DeepEqual(t, RequisitionInterface{IPAddress: "10.0.0.10", SnmpPrimary: "P", Status: 3}, node.Interfaces[1])
+	assert.Error(t, node.SwapInterfaceAddresses("10.0.0.2", "10.0.0.3"), "IP Address 10.0.0.3 doesn't exist on node n1")
+	assert.Error(t, node.SwapInterfaceAddresses("10.0.0.2", "10.0.0.2"), "Cannot swap IP Address 10.0.0.2 with itself")
+
+	req := &Requisition{Name: "Test", Nodes: []RequisitionNode{*node, {ForeignID: "n2", Interfaces: []RequisitionInterface{{IPAddress: "10.0.0.2"}}}}}
+	assert.DeepEqual(t, []string{"n1", "n2"}, req.NodesWithInterface("10.0.0.2"))
+	assert.DeepEqual(t, []string{}, req.NodesWithInterface("10.0.0.3"))
+}
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report parameter types
+const (
+	ReportParamString  = "string"
+	ReportParamInteger = "integer"
+	ReportParamFloat   = "float"
+	ReportParamDate    = "date"
+)
+
+// ReportDateFormat the format of the values of the date parameters of a report
+const ReportDateFormat = "2006-01-02"
+
+// Report a database report available on the server
+type Report struct {
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Online      bool   `json:"online" yaml:"online"`
+}
+
+// ReportList a list of reports
+type ReportList struct {
+	Count   int      `json:"count" yaml:"count"`
+	Reports []Report `json:"report" yaml:"reports"`
+}
+
+// UnmarshalJSON accepts the list as an object, or as a plain array of reports
+func (list *ReportList) UnmarshalJSON(data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		list.Reports = []Report{}
+		if err := json.Unmarshal(data, &list.Reports); err != nil {
+			return err
+		}
+		list.Count = len(list.Reports)
+		return nil
+	}
+	type plain ReportList // Avoids the recursion
+	return json.Unmarshal(data, (*plain)(list))
+}
+
+// ReportParameter a parameter of a report, with its default value
+type ReportParameter struct {
+	Name        string      `json:"name" yaml:"name"`
+	DisplayName string      `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	Type        string      `json:"type,omitempty" yaml:"type,omitempty"`
+	Value       interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// SetValue converts the value according to the type of the parameter, and replaces the current one
+func (p *ReportParameter) SetValue(value string) error {
+	switch p.Type {
+	case ReportParamInteger:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("Invalid integer value %s for parameter %s", value, p.Name)
+		}
+		p.Value = v
+	case ReportParamFloat:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid float value %s for parameter %s", value, p.Name)
+		}
+		p.Value = v
+	case ReportParamDate:
+		if _, err := time.Parse(ReportDateFormat, value); err != nil {
+			return fmt.Errorf("Invalid date %s for parameter %s, expected YYYY-MM-DD", value, p.Name)
+		}
+		p.Value = value
+	default:
+		p.Value = value
+	}
+	return nil
+}
+
+// ReportDetails the parameters and the output formats of a report
+type ReportDetails struct {
+	ID         string            `json:"id" yaml:"id"`
+	Parameters []ReportParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Formats    []string          `json:"formats,omitempty" yaml:"formats,omitempty"`
+}
+
+// GetParameter returns the parameter with the given name, or nil if it doesn't exist
+func (d *ReportDetails) GetParameter(name string) *ReportParameter {
+	for i := range d.Parameters {
+		if d.Parameters[i].Name == name {
+			return &d.Parameters[i]
+		}
+	}
+	return nil
+}
+
+// ParameterNames returns the sorted names of the parameters
+func (d ReportDetails) ParameterNames() []string {
+	names := make([]string, len(d.Parameters))
+	for i, p := range d.Parameters {
+		names[i] = p.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReportRequest the request to run a report, whose result is persisted on the server and optionally sent through e-mail
+type ReportRequest struct {
+	ID         string            `json:"id" yaml:"id"`
+	Format     string            `json:"format" yaml:"format"`
+	Parameters []ReportParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Persist    bool              `json:"persist" yaml:"persist"`
+	SendMail   bool              `json:"sendMail" yaml:"sendMail"`
+	MailTo     string            `json:"mailTo,omitempty" yaml:"mailTo,omitempty"`
+}
+
+// Validate returns an error if the request is invalid
+func (r ReportRequest) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("Report ID required")
+	}
+	if r.Format == "" {
+		return fmt.Errorf("Report format required")
+	}
+	if r.SendMail {
+		if r.MailTo == "" {
+			return fmt.Errorf("E-mail address required to deliver report %s", r.ID)
+		}
+		for _, address := range strings.Split(r.MailTo, ",") {
+			if _, err := mail.ParseAddress(strings.TrimSpace(address)); err != nil {
+				return fmt.Errorf("Invalid e-mail address %s", address)
+			}
+		}
+	}
+	return nil
+}
+
+// PersistedReport the result of a run of a report stored on the server
+type PersistedReport struct {
+	ID       int    `json:"id" yaml:"id"`
+	ReportID string `json:"reportId" yaml:"reportId"`
+	Title    string `json:"title,omitempty" yaml:"title,omitempty"`
+	Date     *Time  `json:"date,omitempty" yaml:"date,omitempty"`
+}
+
+// PersistedReportList a list of persisted reports
+type PersistedReportList struct {
+	Count   int               `json:"count" yaml:"count"`
+	Reports []PersistedReport `json:"report" yaml:"reports"`
+}
+
+// UnmarshalJSON accepts the list as an object, or as a plain array of persisted reports
+func (list *PersistedReportList) UnmarshalJSON(data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		list.Reports = []PersistedReport{}
+		if err := json.Unmarshal(data, &list.Reports); err != nil {
+			return err
+		}
+		list.Count = len(list.Reports)
+		return nil
+	}
+	type plain PersistedReportList // Avoids the recursion
+	return json.Unmarshal(data, (*plain)(list))
+}
+
+// FindNew returns the newest run of the given report that is not part of the previous list, or nil if there are none
+func (list PersistedReportList) FindNew(reportID string, previous PersistedReportList) *PersistedReport {
+	known := make(map[int]bool)
+	for _, r := range previous.Reports {
+		known[r.ID] = true
+	}
+	var newest *PersistedReport
+	for i, r := range list.Reports {
+		if r.ReportID == reportID && !known[r.ID] && (newest == nil || r.ID > newest.ID) {
+			newest = &list.Reports[i]
+		}
+	}
+	return newest
+}
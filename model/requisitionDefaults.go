@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+)
+
+// RequisitionDefaults the content shared by all the nodes of a requisition; it is a client-side convention,
+// expanded into each node before validating and sending the requisition, so the server never receives it.
+// The values of a node win on conflicts: the location is only set on nodes without one, the categories are
+// appended when missing, and the assets (by name) and meta-data (by context and key) are only added when the
+// node doesn't define them.
+type RequisitionDefaults struct {
+	Location   string                `json:"location,omitempty" yaml:"location,omitempty"`
+	Categories []RequisitionCategory `json:"categories,omitempty" yaml:"categories,omitempty"`
+	Assets     []RequisitionAsset    `json:"assets,omitempty" yaml:"assets,omitempty"`
+	MetaData   []RequisitionMetaData `json:"metaData,omitempty" yaml:"metaData,omitempty"`
+}
+
+// Validate returns an error if any of the defaults is invalid
+func (d *RequisitionDefaults) Validate() error {
+	for _, c := range d.Categories {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("Invalid default category: %w", err)
+		}
+	}
+	for _, a := range d.Assets {
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("Invalid default asset: %w", err)
+		}
+	}
+	for i := range d.MetaData {
+		if err := d.MetaData[i].Validate(); err != nil {
+			return fmt.Errorf("Invalid default meta-data: %w", err)
+		}
+	}
+	return nil
+}
+
+// ApplyDefaults expands the defaults into every node of the requisition, keeping the values of the nodes on conflicts
+func (r *Requisition) ApplyDefaults(d RequisitionDefaults) {
+	for i := range r.Nodes {
+		r.Nodes[i].applyDefaults(d)
+	}
+}
+
+// ExpandDefaults expands the defaults embedded on the requisition into its nodes, and removes them
+func (r *Requisition) ExpandDefaults() {
+	if r.Defaults == nil {
+		return
+	}
+	r.ApplyDefaults(*r.Defaults)
+	r.Defaults = nil
+}
+
+func (n *RequisitionNode) applyDefaults(d RequisitionDefaults) {
+	if n.Location == "" {
+		n.Location = d.Location
+	}
+	for _, c := range d.Categories {
+		if !n.hasCategory(c.Name) {
+			n.Categories = append(n.Categories, RequisitionCategory{Name: c.Name})
+		}
+	}
+	for _, a := range d.Assets {
+		if !n.hasAsset(a.Name) {
+			n.Assets = append(n.Assets, RequisitionAsset{Name: a.Name, Value: a.Value})
+		}
+	}
+	for _, m := range d.MetaData {
+		if !n.hasMetaData(metaDataContext(m), m.Key) {
+			n.MetaData = append(n.MetaData, RequisitionMetaData{Context: metaDataContext(m), Key: m.Key, Value: m.Value})
+		}
+	}
+}
+
+func (n *RequisitionNode) hasCategory(name string) bool {
+	for _, c := range n.Categories {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *RequisitionNode) hasAsset(name string) bool {
+	for _, a := range n.Assets {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *RequisitionNode) hasMetaData(context string, key string) bool {
+	for _, m := range n.MetaData {
+		if metaDataContext(m) == context && m.Key == key {
+			return true
+		}
+	}
+	return false
+}
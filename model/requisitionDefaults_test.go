@@ -0,0 +1,134 @@
+package model
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+var testDefaults = RequisitionDefaults{
+	Location:   "Apex",
+	Categories: []RequisitionCategory{{Name: "Production"}, {Name: "Servers"}},
+	Assets:     []RequisitionAsset{{Name: "city", Value: "Apex"}, {Name: "building", Value: "HQ"}},
+	MetaData: []RequisitionMetaData{
+		{Key: "owner", Value: "netops"},
+		{Context: "custom", Key: "tier", Value: "gold"},
+	},
+}
+
+func TestApplyDefaultsToEmptyNode(t *testing.T) {
+	requisition := Requisition{Name: "Local", Nodes: []RequisitionNode{{ForeignID: "n1", NodeLabel: "n1"}}}
+	requisition.ApplyDefaults(testDefaults)
+	node := requisition.Nodes[0]
+	assert.Equal(t, "Apex", node.Location)
+	assert.DeepEqual(t, []RequisitionCategory{{Name: "Production"}, {Name: "Servers"}}, node.Categories)
+	assert.DeepEqual(t, []RequisitionAsset{{Name: "city", Value: "Apex"}, {Name: "building", Value: "HQ"}}, node.Assets)
+	assert.DeepEqual(t, []RequisitionMetaData{
+		{Context: "requisition", Key: "owner", Value: "netops"},
+		{Context: "custom", Key: "tier", Value: "gold"},
+	}, node.MetaData)
+	assert.NilError(t, requisition.Validate())
+}
+
+func TestApplyDefaultsKeepsNodeValues(t *testing.T) {
+	requisition := Requisition{Name: "Local", Nodes: []RequisitionNode{{
+		ForeignID:  "n1",
+		NodeLabel:  "n1",
+		Location:   "Durham",
+		Categories: []RequisitionCategory{{Name: "Servers"}, {Name: "Linux"}},
+		Assets:     []RequisitionAsset{{Name: "city", Value: "Durham"}},
+		MetaData: []RequisitionMetaData{
+			{Key: "owner", Value: "sysadmins"},                // Same context as the default, when omitted
+			{Context: "requisition", Key: "tier", Value: "1"}, // Same key, different context
+		},
+	}}}
+	requisition.ApplyDefaults(testDefaults)
+	node := requisition.Nodes[0]
+
+	// Replaced only when missing
+	assert.Equal(t, "Durham", node.Location)
+
+	// Appended when missing, keeping the order of the node first
+	assert.DeepEqual(t, []RequisitionCategory{{Name: "Servers"}, {Name: "Linux"}, {Name: "Production"}}, node.Categories)
+
+	// Merged by name, with the value of the node
+	assert.DeepEqual(t, []RequisitionAsset{{Name: "city", Value: "Durham"}, {Name: "building", Value: "HQ"}}, node.Assets)
+
+	// Merged by context and key, with the value of the node
+	assert.DeepEqual(t, []RequisitionMetaData{
+		{Key: "owner", Value: "sysadmins"},
+		{Context: "requisition", Key: "tier", Value: "1"},
+		{Context: "custom", Key: "tier", Value: "gold"},
+	}, node.MetaData)
+}
+
+func TestApplyDefaultsTwice(t *testing.T) {
+	requisition := Requisition{Name: "Local", Nodes: []RequisitionNode{{ForeignID: "n1"}, {ForeignID: "n2", Location: "Durham"}}}
+	requisition.ApplyDefaults(testDefaults)
+	requisition.ApplyDefaults(testDefaults)
+	for _, node := range requisition.Nodes {
+		assert.Equal(t, 2, len(node.Categories))
+		assert.Equal(t, 2, len(node.Assets))
+		assert.Equal(t, 2, len(node.MetaData))
+	}
+	assert.Equal(t, "Apex", requisition.Nodes[0].Location)
+	assert.Equal(t, "Durham", requisition.Nodes[1].Location)
+
+	// The first defaults win over the next ones
+	requisition.ApplyDefaults(RequisitionDefaults{Location: "RTP", Assets: []RequisitionAsset{{Name: "city", Value: "RTP"}}})
+	assert.Equal(t, "Apex", requisition.Nodes[0].Location)
+	assert.Equal(t, "Apex", requisition.Nodes[0].Assets[0].Value)
+
+	// The defaults are copied, so changing a node doesn't change the others
+	requisition.Nodes[0].Assets[0].Value = "Cary"
+	assert.Equal(t, "Apex", requisition.Nodes[1].Assets[0].Value)
+}
+
+func TestExpandDefaults(t *testing.T) {
+	requisition := Requisition{}
+	err := yaml.Unmarshal([]byte(`
+name: Local
+defaults:
+  location: Apex
+  categories:
+  - name: Production
+  metaData:
+  - key: owner
+    value: netops
+nodes:
+- foreignID: n1
+  nodeLabel: n1
+- foreignID: n2
+  nodeLabel: n2
+  metaData:
+  - key: owner
+    value: dba
+`), &requisition)
+	assert.NilError(t, err)
+	assert.Assert(t, requisition.Defaults != nil)
+	requisition.ExpandDefaults()
+	assert.Assert(t, requisition.Defaults == nil)
+	assert.Equal(t, "Apex", requisition.Nodes[1].Location)
+	assert.Equal(t, "Production", requisition.Nodes[1].Categories[0].Name)
+	assert.Equal(t, "netops", requisition.Nodes[0].MetaData[0].Value)
+	assert.Equal(t, "dba", requisition.Nodes[1].MetaData[0].Value)
+
+	// Nothing to expand
+	requisition.ExpandDefaults()
+	assert.Equal(t, 1, len(requisition.Nodes[1].MetaData))
+}
+
+func TestValidateDefaults(t *testing.T) {
+	defaults := testDefaults
+	defaults.MetaData = append([]RequisitionMetaData{}, testDefaults.MetaData...) // Validate sets the missing contexts
+	assert.NilError(t, defaults.Validate())
+	assert.Equal(t, "requisition", defaults.MetaData[0].Context)
+
+	defaults = RequisitionDefaults{Categories: []RequisitionCategory{{Name: "A/B"}}}
+	assert.Error(t, defaults.Validate(), `Invalid default category: Invalid characters on category name A/B:, /, \, ?, &, *, ', "`)
+	defaults = RequisitionDefaults{Assets: []RequisitionAsset{{Name: "city"}}}
+	assert.Error(t, defaults.Validate(), "Invalid default asset: Asset value for city cannot be empty")
+	defaults = RequisitionDefaults{MetaData: []RequisitionMetaData{{Value: "netops"}}}
+	assert.Error(t, defaults.Validate(), "Invalid default meta-data: Meta-data key cannot be empty")
+}
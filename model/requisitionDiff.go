@@ -0,0 +1,282 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Diff actions
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffEntry a single difference between two versions of a requisition node
+type DiffEntry struct {
+	Action  string `json:"action" yaml:"action"`
+	Element string `json:"element" yaml:"element"`
+	From    string `json:"from,omitempty" yaml:"from,omitempty"`
+	To      string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// String returns a human readable representation of the difference
+func (d DiffEntry) String() string {
+	switch d.Action {
+	case DiffAdded:
+		if d.To == "" {
+			return "+ " + d.Element
+		}
+		return fmt.Sprintf("+ %s: %s", d.Element, d.To)
+	case DiffRemoved:
+		return "- " + d.Element
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", d.Element, d.From, d.To)
+	}
+}
+
+// NodeDiff the differences found on a given requisition node
+type NodeDiff struct {
+	ForeignID string      `json:"foreign-id" yaml:"foreignID"`
+	Changes   []DiffEntry `json:"changes" yaml:"changes"`
+}
+
+// RequisitionDiff the differences between two versions of a requisition
+type RequisitionDiff struct {
+	Name         string     `json:"name" yaml:"name"`
+	AddedNodes   []string   `json:"added,omitempty" yaml:"added,omitempty"`
+	RemovedNodes []string   `json:"removed,omitempty" yaml:"removed,omitempty"`
+	ChangedNodes []NodeDiff `json:"changed,omitempty" yaml:"changed,omitempty"`
+}
+
+// IsEmpty returns true when there are no differences
+func (d RequisitionDiff) IsEmpty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 && len(d.ChangedNodes) == 0
+}
+
+// Diff compares the requisition against another version of it; the result describes the changes required to go from the current requisition to the other one
+func (r Requisition) Diff(other Requisition) RequisitionDiff {
+	diff := RequisitionDiff{Name: r.Name}
+	current := make(map[string]RequisitionNode)
+	for _, n := range r.Nodes {
+		current[n.ForeignID] = n
+	}
+	target := make(map[string]bool)
+	for _, n := range other.Nodes {
+		target[n.ForeignID] = true
+		c, ok := current[n.ForeignID]
+		if !ok {
+			diff.AddedNodes = append(diff.AddedNodes, n.ForeignID)
+			continue
+		}
+		if changes := c.Diff(n); len(changes) > 0 {
+			diff.ChangedNodes = append(diff.ChangedNodes, NodeDiff{ForeignID: n.ForeignID, Changes: changes})
+		}
+	}
+	for _, n := range r.Nodes {
+		if !target[n.ForeignID] {
+			diff.RemovedNodes = append(diff.RemovedNodes, n.ForeignID)
+		}
+	}
+	return diff
+}
+
+// Diff compares the node against another version of it, including interfaces, services, categories, assets and meta-data
+func (n RequisitionNode) Diff(other RequisitionNode) []DiffEntry {
+	changes := []DiffEntry{}
+	changes = diffField(changes, "node-label", n.NodeLabel, other.NodeLabel)
+	changes = diffField(changes, "location", n.Location, other.Location)
+	changes = diffField(changes, "city", n.City, other.City)
+	changes = diffField(changes, "building", n.Building, other.Building)
+	changes = diffField(changes, "parent-foreign-source", n.ParentForeignSource, other.ParentForeignSource)
+	changes = diffField(changes, "parent-foreign-id", n.ParentForeignID, other.ParentForeignID)
+	changes = diffField(changes, "parent-node-label", n.ParentNodeLabel, other.ParentNodeLabel)
+
+	current := make(map[string]RequisitionInterface)
+	for _, intf := range n.Interfaces {
+		current[intf.IPAddress] = intf
+	}
+	target := make(map[string]bool)
+	for _, intf := range other.Interfaces {
+		target[intf.IPAddress] = true
+		c, ok := current[intf.IPAddress]
+		if !ok {
+			changes = append(changes, DiffEntry{Action: DiffAdded, Element: "interface " + intf.IPAddress})
+			continue
+		}
+		changes = append(changes, c.Diff(intf)...)
+	}
+	for _, intf := range n.Interfaces {
+		if !target[intf.IPAddress] {
+			changes = append(changes, DiffEntry{Action: DiffRemoved, Element: "interface " + intf.IPAddress})
+		}
+	}
+
+	currentCategories := make(map[string]bool)
+	for _, cat := range n.Categories {
+		currentCategories[cat.Name] = true
+	}
+	targetCategories := make(map[string]bool)
+	for _, cat := range other.Categories {
+		targetCategories[cat.Name] = true
+		if !currentCategories[cat.Name] {
+			changes = append(changes, DiffEntry{Action: DiffAdded, Element: "category " + cat.Name})
+		}
+	}
+	for _, cat := range n.Categories {
+		if !targetCategories[cat.Name] {
+			changes = append(changes, DiffEntry{Action: DiffRemoved, Element: "category " + cat.Name})
+		}
+	}
+
+	currentAssets := make(map[string]string)
+	for _, a := range n.Assets {
+		currentAssets[a.Name] = a.Value
+	}
+	targetAssets := make(map[string]bool)
+	for _, a := range other.Assets {
+		targetAssets[a.Name] = true
+		value, ok := currentAssets[a.Name]
+		if !ok {
+			changes = append(changes, DiffEntry{Action: DiffAdded, Element: "asset " + a.Name, To: a.Value})
+		} else if value != a.Value {
+			changes = append(changes, DiffEntry{Action: DiffChanged, Element: "asset " + a.Name, From: value, To: a.Value})
+		}
+	}
+	for _, a := range n.Assets {
+		if !targetAssets[a.Name] {
+			changes = append(changes, DiffEntry{Action: DiffRemoved, Element: "asset " + a.Name})
+		}
+	}
+
+	return append(changes, diffMetaData("node", n.MetaData, other.MetaData)...)
+}
+
+// Diff compares the IP interface against another version of it, including services and meta-data
+func (intf RequisitionInterface) Diff(other RequisitionInterface) []DiffEntry {
+	prefix := "interface " + intf.IPAddress + " "
+	changes := []DiffEntry{}
+	changes = diffField(changes, prefix+"description", intf.Description, other.Description)
+	changes = diffField(changes, prefix+"snmp-primary", snmpPrimaryOf(intf), snmpPrimaryOf(other))
+	changes = diffField(changes, prefix+"status", strconv.Itoa(statusOf(intf)), strconv.Itoa(statusOf(other)))
+
+	current := make(map[string]RequisitionMonitoredService)
+	for _, svc := range intf.Services {
+		current[svc.Name] = svc
+	}
+	target := make(map[string]bool)
+	for _, svc := range other.Services {
+		target[svc.Name] = true
+		element := "service " + svc.Name + " on " + intf.IPAddress
+		c, ok := current[svc.Name]
+		if !ok {
+			changes = append(changes, DiffEntry{Action: DiffAdded, Element: element})
+			continue
+		}
+		changes = append(changes, diffMetaData(element, c.MetaData, svc.MetaData)...)
+	}
+	for _, svc := range intf.Services {
+		if !target[svc.Name] {
+			changes = append(changes, DiffEntry{Action: DiffRemoved, Element: "service " + svc.Name + " on " + intf.IPAddress})
+		}
+	}
+
+	return append(changes, diffMetaData("interface "+intf.IPAddress, intf.MetaData, other.MetaData)...)
+}
+
+// Applies the same defaults used by Validate, so unset fields are not reported as differences
+func snmpPrimaryOf(intf RequisitionInterface) string {
+	if intf.SnmpPrimary == "" {
+		return "N"
+	}
+	return intf.SnmpPrimary
+}
+
+func statusOf(intf RequisitionInterface) int {
+	if intf.Status == 0 {
+		return 1
+	}
+	return intf.Status
+}
+
+func diffField(changes []DiffEntry, element string, from string, to string) []DiffEntry {
+	if from == to {
+		return changes
+	}
+	return append(changes, DiffEntry{Action: DiffChanged, Element: element, From: from, To: to})
+}
+
+func diffMetaData(owner string, current []RequisitionMetaData, target []RequisitionMetaData) []DiffEntry {
+	changes := []DiffEntry{}
+	key := func(m RequisitionMetaData) string {
+		ctx := m.Context
+		if ctx == "" {
+			ctx = "requisition"
+		}
+		return ctx + ":" + m.Key
+	}
+	values := make(map[string]string)
+	for _, m := range current {
+		values[key(m)] = m.Value
+	}
+	found := make(map[string]bool)
+	for _, m := range target {
+		k := key(m)
+		found[k] = true
+		element := owner + " meta-data " + k
+		value, ok := values[k]
+		if !ok {
+			changes = append(changes, DiffEntry{Action: DiffAdded, Element: element, To: m.Value})
+		} else if value != m.Value {
+			changes = append(changes, DiffEntry{Action: DiffChanged, Element: element, From: value, To: m.Value})
+		}
+	}
+	for _, m := range current {
+		if k := key(m); !found[k] {
+			changes = append(changes, DiffEntry{Action: DiffRemoved, Element: owner + " meta-data " + k})
+		}
+	}
+	return changes
+}
+
+// DeployedComparison the nodes of a pending requisition that differ from the deployed version (the last import)
+type DeployedComparison struct {
+	Name          string   `json:"name" yaml:"name"`
+	PendingNodes  int      `json:"pending-nodes" yaml:"pendingNodes"`
+	DeployedNodes int      `json:"deployed-nodes" yaml:"deployedNodes"`
+	LastImport    *Time    `json:"last-imported,omitempty" yaml:"lastImport,omitempty"`
+	PendingOnly   []string `json:"pending-only" yaml:"pendingOnly"`   // Added but never imported
+	DeployedOnly  []string `json:"deployed-only" yaml:"deployedOnly"` // Deleted but still deployed
+}
+
+// CompareDeployed compares the foreign IDs of the requisition against the deployed statistics
+func (r Requisition) CompareDeployed(stats RequisitionStats) DeployedComparison {
+	cmp := DeployedComparison{
+		Name:          r.Name,
+		PendingNodes:  len(r.Nodes),
+		DeployedNodes: len(stats.ForeignIDs),
+		LastImport:    stats.LastImport,
+		PendingOnly:   make([]string, 0),
+		DeployedOnly:  make([]string, 0),
+	}
+	deployed := make(map[string]bool)
+	for _, id := range stats.ForeignIDs {
+		deployed[id] = true
+	}
+	pending := make(map[string]bool)
+	for _, n := range r.Nodes {
+		pending[n.ForeignID] = true
+		if !deployed[n.ForeignID] {
+			cmp.PendingOnly = append(cmp.PendingOnly, n.ForeignID)
+		}
+	}
+	for _, id := range stats.ForeignIDs {
+		if !pending[id] {
+			cmp.DeployedOnly = append(cmp.DeployedOnly, id)
+		}
+	}
+	sort.Strings(cmp.PendingOnly)
+	sort.Strings(cmp.DeployedOnly)
+	return cmp
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRequisitionDiff(t *testing.T) {
+	current := Requisition{
+		Name: "Test",
+		Nodes: []RequisitionNode{
+			{
+				ForeignID: "n1",
+				NodeLabel: "n1",
+				Interfaces: []RequisitionInterface{
+					{
+						IPAddress:   "10.0.0.1",
+						SnmpPrimary: "P",
+						Status:      1,
+						Services: []RequisitionMonitoredService{
+							{Name: "ICMP"},
+							{Name: "HTTP", MetaData: []RequisitionMetaData{{Context: "requisition", Key: "url", Value: "/"}}},
+						},
+					},
+					{IPAddress: "10.0.0.2", SnmpPrimary: "N", Status: 1},
+				},
+				Categories: []RequisitionCategory{{Name: "Servers"}},
+				Assets:     []RequisitionAsset{{Name: "city", Value: "Durham"}, {Name: "state", Value: "NC"}},
+				MetaData:   []RequisitionMetaData{{Context: "requisition", Key: "owner", Value: "agalue"}},
+			},
+			{ForeignID: "n2", NodeLabel: "n2"},
+		},
+	}
+	other := Requisition{
+		Name: "Test",
+		Nodes: []RequisitionNode{
+			{
+				ForeignID: "n1",
+				NodeLabel: "n1",
+				Location:  "Apex",
+				Interfaces: []RequisitionInterface{
+					{
+						IPAddress:   "10.0.0.1",
+						SnmpPrimary: "P",
+						Status:      1,
+						Services: []RequisitionMonitoredService{
+							{Name: "ICMP"},
+							{Name: "HTTP", MetaData: []RequisitionMetaData{{Context: "requisition", Key: "url", Value: "/index.html"}}},
+							{Name: "SNMP"},
+						},
+					},
+					{IPAddress: "10.0.0.3", SnmpPrimary: "N", Status: 1},
+				},
+				Categories: []RequisitionCategory{{Name: "Production"}},
+				Assets:     []RequisitionAsset{{Name: "city", Value: "Apex"}, {Name: "zip", Value: "27502"}},
+				MetaData:   []RequisitionMetaData{{Key: "owner", Value: "agalue"}, {Key: "team", Value: "netops"}},
+			},
+			{ForeignID: "n3", NodeLabel: "n3"},
+		},
+	}
+
+	assert.Assert(t, current.Diff(current).IsEmpty())
+
+	diff := current.Diff(other)
+	assert.Assert(t, !diff.IsEmpty())
+	assert.DeepEqual(t, []string{"n3"}, diff.AddedNodes)
+	assert.DeepEqual(t, []string{"n2"}, diff.RemovedNodes)
+	assert.Equal(t, 1, len(diff.ChangedNodes))
+	assert.Equal(t, "n1", diff.ChangedNodes[0].ForeignID)
+
+	changes := []string{}
+	for _, c := range diff.ChangedNodes[0].Changes {
+		changes = append(changes, c.String())
+	}
+	assert.DeepEqual(t, []string{
+		"~ location:  -> Apex",
+		"~ service HTTP on 10.0.0.1 meta-data requisition:url: / -> /index.html",
+		"+ service SNMP on 10.0.0.1",
+		"+ interface 10.0.0.3",
+		"- interface 10.0.0.2",
+		"+ category Production",
+		"- category Servers",
+		"~ asset city: Durham -> Apex",
+		"+ asset zip: 27502",
+		"- asset state",
+		"+ node meta-data requisition:team: netops",
+	}, changes)
+}
+
+func TestCompareDeployed(t *testing.T) {
+	req := Requisition{
+		Name:  "Test",
+		Nodes: []RequisitionNode{{ForeignID: "n3"}, {ForeignID: "n1"}, {ForeignID: "n4"}},
+	}
+	stats := RequisitionStats{Name: "Test", ForeignIDs: []string{"n1", "n2"}}
+	cmp := req.CompareDeployed(stats)
+	assert.Equal(t, 3, cmp.PendingNodes)
+	assert.Equal(t, 2, cmp.DeployedNodes)
+	assert.DeepEqual(t, []string{"n3", "n4"}, cmp.PendingOnly)
+	assert.DeepEqual(t, []string{"n2"}, cmp.DeployedOnly)
+
+	cmp = Requisition{Name: "Empty"}.CompareDeployed(RequisitionStats{})
+	assert.Equal(t, 0, len(cmp.PendingOnly))
+	assert.Equal(t, 0, len(cmp.DeployedOnly))
+}
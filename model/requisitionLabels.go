@@ -0,0 +1,98 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// The strategies to normalize the labels of requisitioned nodes
+const (
+	LabelStrategyLower = "lower" // Lowercase labels
+	LabelStrategyShort = "short" // Labels without the domain
+	LabelStrategyFQDN  = "fqdn"  // Labels with the domain appended to short names
+)
+
+// LabelStrategies the valid strategies to normalize labels
+var LabelStrategies = []string{LabelStrategyLower, LabelStrategyShort, LabelStrategyFQDN}
+
+// LabelNormalization how the labels of the nodes are normalized;
+// labels that are IP addresses are never changed by short and fqdn
+type LabelNormalization struct {
+	Strategy string
+	Domain   string // Appended by fqdn (required); when set, short only strips this domain, otherwise anything after the first dot
+}
+
+// Validate returns an error if the normalization options are invalid, removing the surrounding dots of the domain
+func (o *LabelNormalization) Validate() error {
+	o.Domain = strings.Trim(strings.TrimSpace(o.Domain), ".")
+	switch o.Strategy {
+	case LabelStrategyLower, LabelStrategyShort:
+	case LabelStrategyFQDN:
+		if o.Domain == "" {
+			return fmt.Errorf("The domain is required by the %s strategy", LabelStrategyFQDN)
+		}
+	default:
+		return fmt.Errorf("Invalid label strategy %s, valid options: %s", o.Strategy, strings.Join(LabelStrategies, ", "))
+	}
+	if strings.ContainsAny(o.Domain, " /\\") {
+		return fmt.Errorf("Invalid domain %s", o.Domain)
+	}
+	return nil
+}
+
+// NormalizeLabel rewrites the label of the node with the given strategy; returns true when the label was changed
+func (n *RequisitionNode) NormalizeLabel(opts LabelNormalization) bool {
+	label := normalizeLabel(n.NodeLabel, opts)
+	if label == n.NodeLabel {
+		return false
+	}
+	n.NodeLabel = label
+	return true
+}
+
+func normalizeLabel(label string, opts LabelNormalization) string {
+	domain := strings.Trim(opts.Domain, ".")
+	switch opts.Strategy {
+	case LabelStrategyLower:
+		return strings.ToLower(label)
+	case LabelStrategyShort:
+		if label == "" || net.ParseIP(label) != nil {
+			return label
+		}
+		if domain != "" {
+			suffix := "." + strings.ToLower(domain)
+			if strings.HasSuffix(strings.ToLower(label), suffix) && len(label) > len(suffix) {
+				return label[:len(label)-len(suffix)]
+			}
+			return label
+		}
+		if i := strings.Index(label, "."); i > 0 {
+			return label[:i]
+		}
+	case LabelStrategyFQDN:
+		if label == "" || domain == "" || net.ParseIP(label) != nil {
+			return label
+		}
+		if !strings.Contains(strings.TrimSuffix(label, "."), ".") {
+			return strings.TrimSuffix(label, ".") + "." + domain
+		}
+	}
+	return label
+}
+
+// LabelCollisions returns the labels shared by more than one node of the requisition, with the foreign IDs of those nodes
+func (r *Requisition) LabelCollisions() map[string][]string {
+	nodes := make(map[string][]string)
+	for _, n := range r.Nodes {
+		label := strings.ToLower(n.NodeLabel)
+		nodes[label] = append(nodes[label], n.ForeignID)
+	}
+	collisions := make(map[string][]string)
+	for label, foreignIDs := range nodes {
+		if len(foreignIDs) > 1 {
+			collisions[label] = foreignIDs
+		}
+	}
+	return collisions
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestNormalizeLabel(t *testing.T) {
+	tests := []struct {
+		opts     LabelNormalization
+		label    string
+		expected string
+	}{
+		{LabelNormalization{Strategy: LabelStrategyLower}, "SRV01.Example.COM", "srv01.example.com"},
+		{LabelNormalization{Strategy: LabelStrategyShort}, "srv01.example.com", "srv01"},
+		{LabelNormalization{Strategy: LabelStrategyShort}, "srv01", "srv01"},
+		{LabelNormalization{Strategy: LabelStrategyShort}, "10.0.0.1", "10.0.0.1"},
+		{LabelNormalization{Strategy: LabelStrategyShort}, "fe80::1", "fe80::1"},
+		{LabelNormalization{Strategy: LabelStrategyShort, Domain: "example.com"}, "srv01.dc1.EXAMPLE.com", "srv01.dc1"},
+		{LabelNormalization{Strategy: LabelStrategyShort, Domain: "example.com"}, "srv01.example.org", "srv01.example.org"},
+		{LabelNormalization{Strategy: LabelStrategyShort, Domain: "example.com"}, ".example.com", ".example.com"},
+		{LabelNormalization{Strategy: LabelStrategyFQDN, Domain: "example.com"}, "srv01", "srv01.example.com"},
+		{LabelNormalization{Strategy: LabelStrategyFQDN, Domain: "example.com"}, "srv01.", "srv01.example.com"},
+		{LabelNormalization{Strategy: LabelStrategyFQDN, Domain: "example.com"}, "srv01.example.org", "srv01.example.org"},
+		{LabelNormalization{Strategy: LabelStrategyFQDN, Domain: "example.com"}, "10.0.0.1", "10.0.0.1"},
+	}
+	for _, test := range tests {
+		node := RequisitionNode{ForeignID: "n1", NodeLabel: test.label}
+		changed := node.NormalizeLabel(test.opts)
+		assert.Equal(t, test.expected, node.NodeLabel, "%s of %s", test.opts.Strategy, test.label)
+		assert.Equal(t, test.expected != test.label, changed)
+	}
+}
+
+func TestValidateLabelNormalization(t *testing.T) {
+	opts := LabelNormalization{Strategy: LabelStrategyFQDN, Domain: ".example.com."}
+	assert.NilError(t, opts.Validate())
+	assert.Equal(t, "example.com", opts.Domain)
+
+	opts = LabelNormalization{Strategy: LabelStrategyFQDN}
+	assert.Error(t, opts.Validate(), "The domain is required by the fqdn strategy")
+	opts = LabelNormalization{Strategy: "upper"}
+	assert.Error(t, opts.Validate(), "Invalid label strategy upper, valid options: lower, short, fqdn")
+	opts = LabelNormalization{Strategy: LabelStrategyShort, Domain: "example com"}
+	assert.Error(t, opts.Validate(), "Invalid domain example com")
+}
+
+func TestLabelCollisions(t *testing.T) {
+	requisition := Requisition{Name: "Test", Nodes: []RequisitionNode{
+		{ForeignID: "n1", NodeLabel: "srv01"},
+		{ForeignID: "n2", NodeLabel: "SRV01"},
+		{ForeignID: "n3", NodeLabel: "srv02"},
+	}}
+	assert.DeepEqual(t, map[string][]string{"srv01": {"n1", "n2"}}, requisition.LabelCollisions())
+}
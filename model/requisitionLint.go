@@ -0,0 +1,319 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LintSeverity the importance of the issues reported by a lint rule
+type LintSeverity string
+
+// Lint severities
+const (
+	LintWarning LintSeverity = "warning"
+	LintError   LintSeverity = "error"
+)
+
+// LintIssue a problem found by a lint rule
+type LintIssue struct {
+	Rule      string       `json:"rule" yaml:"rule"`
+	Severity  LintSeverity `json:"severity" yaml:"severity"`
+	ForeignID string       `json:"foreign-id" yaml:"foreignID"`
+	Message   string       `json:"message" yaml:"message"`
+}
+
+// LintSettings the preferences of the requisition linter, usually from .onmsctl-lint.yaml
+type LintSettings struct {
+	Enable       []string                `json:"enable,omitempty" yaml:"enable,omitempty"`             // Rules to run even when disabled
+	Disable      []string                `json:"disable,omitempty" yaml:"disable,omitempty"`           // Rules to skip
+	Severities   map[string]LintSeverity `json:"severities,omitempty" yaml:"severities,omitempty"`     // Overrides the default severity of a rule
+	LabelPattern string                  `json:"labelPattern,omitempty" yaml:"labelPattern,omitempty"` // Regular expression for the node labels
+	Minions      bool                    `json:"minions,omitempty" yaml:"minions,omitempty"`           // When true, nodes must have a location
+
+	labelRegexp *regexp.Regexp
+}
+
+// MatchLabel returns true if the label matches the naming pattern, or when there is no pattern
+func (s LintSettings) MatchLabel(label string) bool {
+	if s.LabelPattern == "" {
+		return true
+	}
+	re := s.labelRegexp
+	if re == nil {
+		var err error
+		if re, err = regexp.Compile(s.LabelPattern); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(label)
+}
+
+// Rule a check applied to every node of a requisition; it returns a message per problem found
+type Rule interface {
+	Name() string
+	Description() string
+	Severity() LintSeverity
+	Check(requisition Requisition, node RequisitionNode, settings LintSettings) []string
+}
+
+// IncrementalRule a rule that compares each node with the nodes checked before it, keeping only what it needs from them,
+// so the requisition can be linted while its nodes are received; the linter calls Start instead of Check
+type IncrementalRule interface {
+	Rule
+	// Start returns the function that checks the nodes one at a time; the problems can be reported on the node being checked,
+	// or on one of the nodes checked before
+	Start(settings LintSettings, report func(foreignID string, message string)) func(node RequisitionNode)
+}
+
+// ruleFunc a rule implemented by a function
+type ruleFunc struct {
+	name        string
+	description string
+	severity    LintSeverity
+	check       func(requisition Requisition, node RequisitionNode, settings LintSettings) []string
+}
+
+func (r ruleFunc) Name() string           { return r.name }
+func (r ruleFunc) Description() string    { return r.description }
+func (r ruleFunc) Severity() LintSeverity { return r.severity }
+func (r ruleFunc) Check(requisition Requisition, node RequisitionNode, settings LintSettings) []string {
+	return r.check(requisition, node, settings)
+}
+
+// NewRule creates a rule from a function, to simplify adding site-specific rules through RegisterLintRule
+func NewRule(name string, description string, severity LintSeverity, check func(requisition Requisition, node RequisitionNode, settings LintSettings) []string) Rule {
+	return ruleFunc{name, description, severity, check}
+}
+
+var lintRules = []Rule{
+	NewRule("snmp-primary", "Nodes with IP interfaces must have a primary SNMP interface", LintWarning, checkSnmpPrimary),
+	NewRule("unmanaged-interface", "IP interfaces should not be unmanaged (status 3)", LintWarning, checkUnmanagedInterfaces),
+	NewRule("no-categories", "Nodes should have at least one category", LintWarning, checkCategories),
+	NewRule("label-pattern", "Node labels must match the labelPattern from the settings", LintError, checkLabelPattern),
+	NewRule("missing-location", "Nodes must have a location when Minions are in use", LintError, checkLocation),
+	duplicateIPRule{NewRule("duplicate-ip", "An IP address should belong to a single node", LintWarning, nil)},
+}
+
+// RegisterLintRule adds a rule to the ones available to the linter; it replaces an existing rule with the same name
+func RegisterLintRule(rule Rule) {
+	for i, r := range lintRules {
+		if r.Name() == rule.Name() {
+			lintRules[i] = rule
+			return
+		}
+	}
+	lintRules = append(lintRules, rule)
+}
+
+// LintRules returns the registered rules
+func LintRules() []Rule {
+	return append([]Rule{}, lintRules...)
+}
+
+// RequisitionLinter runs a set of rules against requisitions
+type RequisitionLinter struct {
+	Rules    []Rule
+	Settings LintSettings
+}
+
+// NewRequisitionLinter creates a linter with the registered rules, honoring the enabled and disabled rules from the settings
+func NewRequisitionLinter(settings LintSettings) (*RequisitionLinter, error) {
+	known := make(map[string]bool)
+	for _, r := range lintRules {
+		known[r.Name()] = true
+	}
+	disabled := make(map[string]bool)
+	for _, name := range append(append([]string{}, settings.Enable...), settings.Disable...) {
+		if !known[name] {
+			return nil, fmt.Errorf("Unknown lint rule %s, valid options: %s", name, strings.Join(lintRuleNames(), ", "))
+		}
+	}
+	for _, name := range settings.Disable {
+		disabled[name] = true
+	}
+	for _, name := range settings.Enable {
+		disabled[name] = false
+	}
+	for name, severity := range settings.Severities {
+		if !known[name] {
+			return nil, fmt.Errorf("Unknown lint rule %s, valid options: %s", name, strings.Join(lintRuleNames(), ", "))
+		}
+		if severity != LintWarning && severity != LintError {
+			return nil, fmt.Errorf("Invalid severity %s for lint rule %s, valid options: %s, %s", severity, name, LintWarning, LintError)
+		}
+	}
+	if settings.LabelPattern != "" {
+		re, err := regexp.Compile(settings.LabelPattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid label pattern %s: %w", settings.LabelPattern, err)
+		}
+		settings.labelRegexp = re
+	}
+	linter := &RequisitionLinter{Settings: settings}
+	for _, r := range lintRules {
+		if !disabled[r.Name()] {
+			linter.Rules = append(linter.Rules, r)
+		}
+	}
+	return linter, nil
+}
+
+// Lint returns the issues found on the requisition, sorted by foreign ID
+func (l RequisitionLinter) Lint(requisition Requisition) []LintIssue {
+	run := l.Start(requisition)
+	for _, node := range requisition.Nodes {
+		run.Check(node)
+	}
+	return run.Issues()
+}
+
+// Start prepares the linter to check the nodes of a requisition one at a time, as they are received;
+// the requisition passed to the rules is the given one, which may not have the nodes
+func (l RequisitionLinter) Start(requisition Requisition) *LintRun {
+	run := &LintRun{requisition: requisition, settings: l.Settings, issues: make([]LintIssue, 0)}
+	for _, rule := range l.Rules {
+		rule := rule
+		severity := rule.Severity()
+		if s, ok := l.Settings.Severities[rule.Name()]; ok {
+			severity = s
+		}
+		report := func(foreignID string, message string) {
+			run.issues = append(run.issues, LintIssue{rule.Name(), severity, foreignID, message})
+		}
+		if r, ok := rule.(IncrementalRule); ok {
+			run.checks = append(run.checks, r.Start(l.Settings, report))
+			continue
+		}
+		run.checks = append(run.checks, func(node RequisitionNode) {
+			for _, message := range rule.Check(run.requisition, node, run.settings) {
+				report(node.ForeignID, message)
+			}
+		})
+	}
+	return run
+}
+
+// LintRun the state of a linter while the nodes of a requisition are checked
+type LintRun struct {
+	requisition Requisition
+	settings    LintSettings
+	checks      []func(node RequisitionNode)
+	issues      []LintIssue
+}
+
+// Check applies the rules to a node of the requisition
+func (r *LintRun) Check(node RequisitionNode) {
+	for _, check := range r.checks {
+		check(node)
+	}
+}
+
+// Issues returns the issues found on the nodes checked so far, sorted by foreign ID
+func (r *LintRun) Issues() []LintIssue {
+	issues := append([]LintIssue{}, r.issues...)
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].ForeignID < issues[j].ForeignID
+	})
+	return issues
+}
+
+// CountLintIssues returns the number of errors and warnings
+func CountLintIssues(issues []LintIssue) (errors int, warnings int) {
+	for _, issue := range issues {
+		if issue.Severity == LintError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return
+}
+
+func lintRuleNames() []string {
+	names := make([]string, len(lintRules))
+	for i, r := range lintRules {
+		names[i] = r.Name()
+	}
+	return names
+}
+
+func checkSnmpPrimary(requisition Requisition, node RequisitionNode, settings LintSettings) []string {
+	if len(node.Interfaces) == 0 {
+		return nil
+	}
+	for _, intf := range node.Interfaces {
+		if intf.SnmpPrimary == "P" {
+			return nil
+		}
+	}
+	return []string{"There is no primary SNMP interface"}
+}
+
+func checkUnmanagedInterfaces(requisition Requisition, node RequisitionNode, settings LintSettings) []string {
+	var messages []string
+	for _, intf := range node.Interfaces {
+		if intf.Status == 3 {
+			messages = append(messages, fmt.Sprintf("Interface %s is unmanaged", intf.IPAddress))
+		}
+	}
+	return messages
+}
+
+func checkCategories(requisition Requisition, node RequisitionNode, settings LintSettings) []string {
+	if len(node.Categories) == 0 {
+		return []string{"There are no categories"}
+	}
+	return nil
+}
+
+func checkLabelPattern(requisition Requisition, node RequisitionNode, settings LintSettings) []string {
+	if settings.MatchLabel(node.NodeLabel) {
+		return nil
+	}
+	return []string{fmt.Sprintf("Label %s doesn't match %s", node.NodeLabel, settings.LabelPattern)}
+}
+
+func checkLocation(requisition Requisition, node RequisitionNode, settings LintSettings) []string {
+	if settings.Minions && node.Location == "" {
+		return []string{"There is no location"}
+	}
+	return nil
+}
+
+// duplicateIPRule remembers the owners of each IP address, instead of comparing every node with the whole requisition
+type duplicateIPRule struct {
+	Rule
+}
+
+func (r duplicateIPRule) Start(settings LintSettings, report func(foreignID string, message string)) func(node RequisitionNode) {
+	owners := make(map[string][]string)
+	return func(node RequisitionNode) {
+		for _, intf := range node.Interfaces {
+			ids := owners[intf.IPAddress]
+			for _, id := range ids {
+				if id != node.ForeignID {
+					report(node.ForeignID, fmt.Sprintf("Interface %s also belongs to node %s", intf.IPAddress, id))
+					report(id, fmt.Sprintf("Interface %s also belongs to node %s", intf.IPAddress, node.ForeignID))
+				}
+			}
+			if len(ids) == 0 || ids[len(ids)-1] != node.ForeignID {
+				owners[intf.IPAddress] = append(ids, node.ForeignID)
+			}
+		}
+	}
+}
+
+func (r duplicateIPRule) Check(requisition Requisition, node RequisitionNode, settings LintSettings) []string {
+	var messages []string
+	check := r.Start(settings, func(foreignID string, message string) {
+		if foreignID == node.ForeignID {
+			messages = append(messages, message)
+		}
+	})
+	for _, n := range requisition.Nodes {
+		check(n)
+	}
+	return messages
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+var lintRequisition = Requisition{
+	Name: "Test",
+	Nodes: []RequisitionNode{
+		{
+			ForeignID:  "n1",
+			NodeLabel:  "srv-n1",
+			Location:   "Remote",
+			Interfaces: []RequisitionInterface{{IPAddress: "10.0.0.1", SnmpPrimary: "P", Status: 1}},
+			Categories: []RequisitionCategory{{Name: "Servers"}},
+		},
+		{
+			ForeignID:  "n2",
+			NodeLabel:  "n2",
+			Interfaces: []RequisitionInterface{{IPAddress: "10.0.0.1", SnmpPrimary: "N", Status: 3}},
+		},
+	},
+}
+
+func TestRequisitionLinter(t *testing.T) {
+	linter, err := NewRequisitionLinter(LintSettings{})
+	assert.NilError(t, err)
+	issues := linter.Lint(lintRequisition)
+	assert.DeepEqual(t, []LintIssue{
+		{"duplicate-ip", LintWarning, "n1", "Interface 10.0.0.1 also belongs to node n2"},
+		{"snmp-primary", LintWarning, "n2", "There is no primary SNMP interface"},
+		{"unmanaged-interface", LintWarning, "n2", "Interface 10.0.0.1 is unmanaged"},
+		{"no-categories", LintWarning, "n2", "There are no categories"},
+		{"duplicate-ip", LintWarning, "n2", "Interface 10.0.0.1 also belongs to node n1"},
+	}, issues)
+
+	linter, err = NewRequisitionLinter(LintSettings{
+		Disable:      []string{"duplicate-ip", "unmanaged-interface", "snmp-primary"},
+		Enable:       []string{"snmp-primary"},
+		Severities:   map[string]LintSeverity{"no-categories": LintError},
+		LabelPattern: "^srv-",
+		Minions:      true,
+	})
+	assert.NilError(t, err)
+	issues = linter.Lint(lintRequisition)
+	assert.DeepEqual(t, []LintIssue{
+		{"snmp-primary", LintWarning, "n2", "There is no primary SNMP interface"},
+		{"no-categories", LintError, "n2", "There are no categories"},
+		{"label-pattern", LintError, "n2", "Label n2 doesn't match ^srv-"},
+		{"missing-location", LintError, "n2", "There is no location"},
+	}, issues)
+	errors, warnings := CountLintIssues(issues)
+	assert.Equal(t, 3, errors)
+	assert.Equal(t, 1, warnings)
+}
+
+func TestLintRun(t *testing.T) {
+	linter, err := NewRequisitionLinter(LintSettings{Disable: []string{"snmp-primary", "unmanaged-interface", "no-categories"}})
+	assert.NilError(t, err)
+	run := linter.Start(Requisition{Name: lintRequisition.Name})
+	for _, node := range lintRequisition.Nodes {
+		run.Check(node)
+	}
+	run.Check(RequisitionNode{ForeignID: "n3", NodeLabel: "n3", Interfaces: []RequisitionInterface{{IPAddress: "10.0.0.1"}}})
+	assert.DeepEqual(t, []LintIssue{
+		{"duplicate-ip", LintWarning, "n1", "Interface 10.0.0.1 also belongs to node n2"},
+		{"duplicate-ip", LintWarning, "n1", "Interface 10.0.0.1 also belongs to node n3"},
+		{"duplicate-ip", LintWarning, "n2", "Interface 10.0.0.1 also belongs to node n1"},
+		{"duplicate-ip", LintWarning, "n2", "Interface 10.0.0.1 also belongs to node n3"},
+		{"duplicate-ip", LintWarning, "n3", "Interface 10.0.0.1 also belongs to node n1"},
+		{"duplicate-ip", LintWarning, "n3", "Interface 10.0.0.1 also belongs to node n2"},
+	}, run.Issues())
+}
+
+func TestInvalidLintSettings(t *testing.T) {
+	_, err := NewRequisitionLinter(LintSettings{Disable: []string{"unknown"}})
+	assert.ErrorContains(t, err, "Unknown lint rule unknown")
+	_, err = NewRequisitionLinter(LintSettings{Severities: map[string]LintSeverity{"no-categories": "fatal"}})
+	assert.ErrorContains(t, err, "Invalid severity fatal")
+	_, err = NewRequisitionLinter(LintSettings{LabelPattern: "["})
+	assert.ErrorContains(t, err, "Invalid label pattern")
+}
+
+func TestRegisterLintRule(t *testing.T) {
+	defaultRules := LintRules()
+	defer func() { lintRules = defaultRules }()
+
+	RegisterLintRule(NewRule("building", "Nodes must have a building", LintError, func(r Requisition, n RequisitionNode, s LintSettings) []string {
+		if n.Building == "" {
+			return []string{"There is no building"}
+		}
+		return nil
+	}))
+	assert.Equal(t, len(defaultRules)+1, len(LintRules()))
+	linter, err := NewRequisitionLinter(LintSettings{Disable: []string{"duplicate-ip", "unmanaged-interface", "snmp-primary", "no-categories"}})
+	assert.NilError(t, err)
+	issues := linter.Lint(lintRequisition)
+	assert.Equal(t, 2, len(issues))
+	assert.Equal(t, "building", issues[0].Rule)
+}
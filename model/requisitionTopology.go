@@ -0,0 +1,91 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NodeCriteria returns the criteria that identifies a node across requisitions (e.x. Routers:rtr01);
+// requisition names and foreign IDs cannot contain ':'
+func NodeCriteria(foreignSource string, foreignID string) string {
+	return foreignSource + ":" + foreignID
+}
+
+// ParentCycle returns the first cycle found following the parents of the nodes of all the requisitions,
+// as the criteria of each node, starting and ending with the same node; or nil when there are no cycles.
+// Parents referenced by label are resolved within the requisition of the node, and parents on requisitions
+// that are not part of the set end the chain.
+func ParentCycle(requisitions ...Requisition) []string {
+	parents := make(map[string]string)
+	for _, r := range requisitions {
+		labels := make(map[string]string)
+		for _, n := range r.Nodes {
+			labels[nodeLabelOrID(n)] = n.ForeignID
+		}
+		for _, n := range r.Nodes {
+			node := NodeCriteria(r.Name, n.ForeignID)
+			switch {
+			case n.ParentForeignID != "":
+				foreignSource := n.ParentForeignSource
+				if foreignSource == "" {
+					foreignSource = r.Name
+				}
+				parents[node] = NodeCriteria(foreignSource, n.ParentForeignID)
+			case n.ParentNodeLabel != "":
+				if id, ok := labels[n.ParentNodeLabel]; ok {
+					parents[node] = NodeCriteria(r.Name, id)
+				}
+			}
+		}
+	}
+	nodes := make([]string, 0, len(parents))
+	for node := range parents {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes) // Reports the same cycle on every run
+
+	// Every node has at most one parent, so following the chain from each node is enough
+	const visiting, visited = 1, 2
+	state := make(map[string]int)
+	for _, start := range nodes {
+		path := []string{}
+		for node := start; state[node] != visited; {
+			if state[node] == visiting {
+				for i, n := range path {
+					if n == node {
+						return append(path[i:], node)
+					}
+				}
+			}
+			state[node] = visiting
+			path = append(path, node)
+			parent, ok := parents[node]
+			if !ok {
+				break
+			}
+			node = parent
+		}
+		for _, n := range path {
+			state[n] = visited
+		}
+	}
+	return nil
+}
+
+// ValidateTopology returns an error when the parents of the nodes form a cycle, as OpenNMS cannot
+// build the paths used to suppress outages behind a failed parent
+func (r Requisition) ValidateTopology() error {
+	cycle := ParentCycle(r)
+	if cycle == nil {
+		return nil
+	}
+	return fmt.Errorf("The parents of the nodes on requisition %s form a cycle: %s", r.Name, strings.Join(cycle, " -> "))
+}
+
+func nodeLabelOrID(n RequisitionNode) string {
+	if n.NodeLabel == "" {
+		return n.ForeignID
+	}
+	return n.NodeLabel
+}
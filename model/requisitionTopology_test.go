@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestValidateTopology(t *testing.T) {
+	requisition := Requisition{
+		Name: "Routers",
+		Nodes: []RequisitionNode{
+			{ForeignID: "core", NodeLabel: "core"},
+			{ForeignID: "dist1", NodeLabel: "dist1", ParentForeignID: "core"},
+			{ForeignID: "access1", NodeLabel: "access1", ParentNodeLabel: "dist1"},
+			{ForeignID: "wan", NodeLabel: "wan", ParentForeignSource: "Providers", ParentForeignID: "isp"},
+		},
+	}
+	assert.NilError(t, requisition.ValidateTopology())
+	assert.NilError(t, requisition.Validate())
+
+	// A 3-node cycle
+	requisition.Nodes[0].ParentForeignSource = "Routers"
+	requisition.Nodes[0].ParentForeignID = "access1"
+	assert.DeepEqual(t, []string{"Routers:access1", "Routers:dist1", "Routers:core", "Routers:access1"}, ParentCycle(requisition))
+	assert.Error(t, requisition.ValidateTopology(), "The parents of the nodes on requisition Routers form a cycle: Routers:access1 -> Routers:dist1 -> Routers:core -> Routers:access1")
+	assert.Error(t, requisition.Validate(), "The parents of the nodes on requisition Routers form a cycle: Routers:access1 -> Routers:dist1 -> Routers:core -> Routers:access1")
+}
+
+func TestParentCycleAcrossRequisitions(t *testing.T) {
+	routers := Requisition{
+		Name: "Routers",
+		Nodes: []RequisitionNode{
+			{ForeignID: "wan", ParentForeignSource: "Providers", ParentForeignID: "isp"},
+		},
+	}
+	providers := Requisition{
+		Name: "Providers",
+		Nodes: []RequisitionNode{
+			{ForeignID: "isp"},
+		},
+	}
+	assert.Assert(t, ParentCycle(routers, providers) == nil)
+
+	providers.Nodes[0].ParentForeignSource = "Routers"
+	providers.Nodes[0].ParentForeignID = "wan"
+	assert.Assert(t, ParentCycle(providers) == nil) // Routers is not part of the set
+	assert.DeepEqual(t, []string{"Providers:isp", "Routers:wan", "Providers:isp"}, ParentCycle(routers, providers))
+}
@@ -1,6 +1,8 @@
 package model
 
-import "fmt"
+import (
+	"sort"
+)
 
 // NumericAttribute a numeric attribute
 type NumericAttribute struct {
@@ -23,18 +25,47 @@ type Resource struct {
 	Children           *ResourceList               `json:"children,omitempty" yaml:"Children,omitempty"`
 }
 
+// Attributes returns the sorted names of the numeric attributes (i.e. the metrics) of the resource
+func (r Resource) Attributes() []string {
+	names := make([]string, 0, len(r.NumericAttributes))
+	for name := range r.NumericAttributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasAttribute returns true if the resource exposes a numeric or string attribute with the given name
+func (r Resource) HasAttribute(name string) bool {
+	if _, ok := r.NumericAttributes[name]; ok {
+		return true
+	}
+	_, ok := r.StringAttributes[name]
+	return ok
+}
+
 // ResourceList a list of resources
 type ResourceList struct {
 	Count     int        `json:"count" yaml:"count,omitempty"`
 	Resources []Resource `json:"resource" yaml:"resources,omitempty"`
 }
 
-// Enumerate shows the ID of each resource and its children
-func (list ResourceList) Enumerate(prefix string) {
+// Search returns a copy of the list with the resources exposing the given attribute, keeping their ancestors to preserve the tree
+func (list ResourceList) Search(attribute string) ResourceList {
+	result := ResourceList{}
 	for _, r := range list.Resources {
-		fmt.Println(prefix, r.ID)
+		var children *ResourceList
 		if r.Children != nil {
-			r.Children.Enumerate("   ")
+			if found := r.Children.Search(attribute); len(found.Resources) > 0 {
+				children = &found
+			}
+		}
+		if children == nil && !r.HasAttribute(attribute) {
+			continue
 		}
+		r.Children = children
+		result.Resources = append(result.Resources, r)
 	}
+	result.Count = len(result.Resources)
+	return result
 }
@@ -58,3 +58,37 @@ func TestResources(t *testing.T) {
 	assert.NilError(t, err)
 	fmt.Println(string(yamlBytes))
 }
+
+func TestSearchResources(t *testing.T) {
+	jsonBytes := []byte(`{
+  "count": 2,
+  "resource": [{
+    "id": "node[Servers:web01]", "label": "web01",
+    "children": {"count": 2, "resource": [
+      {"id": "node[Servers:web01].nodeSnmp[]", "rrdGraphAttributes": {"loadavg1": {"name": "loadavg1"}}},
+      {"id": "node[Servers:web01].interfaceSnmp[eth0]", "rrdGraphAttributes": {"ifHCInOctets": {"name": "ifHCInOctets"}, "ifHCOutOctets": {"name": "ifHCOutOctets"}}}
+    ]}
+  }, {
+    "id": "node[Servers:db01]", "label": "db01",
+    "children": {"count": 1, "resource": [
+      {"id": "node[Servers:db01].nodeSnmp[]", "rrdGraphAttributes": {"loadavg1": {"name": "loadavg1"}}, "stringPropertyAttributes": {"sysName": "db01"}}
+    ]}
+  }]
+}`)
+	list := ResourceList{}
+	assert.NilError(t, json.Unmarshal(jsonBytes, &list))
+	assert.DeepEqual(t, []string{"ifHCInOctets", "ifHCOutOctets"}, list.Resources[0].Children.Resources[1].Attributes())
+
+	found := list.Search("ifHCInOctets")
+	assert.Equal(t, 1, found.Count)
+	assert.Equal(t, "node[Servers:web01]", found.Resources[0].ID)
+	assert.Equal(t, 1, found.Resources[0].Children.Count)
+	assert.Equal(t, "node[Servers:web01].interfaceSnmp[eth0]", found.Resources[0].Children.Resources[0].ID)
+	assert.Equal(t, 2, list.Resources[0].Children.Count) // The original list is not modified
+
+	found = list.Search("sysName")
+	assert.Equal(t, 1, found.Count)
+	assert.Equal(t, "node[Servers:db01]", found.Resources[0].ID)
+
+	assert.Equal(t, 0, len(list.Search("unknown").Resources))
+}
@@ -0,0 +1,442 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaDraft the version of the JSON Schema specification used by the generated schemas
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// The pattern for names that cannot contain the characters rejected by the Validate methods: / \ ? : & * ' "
+const namePattern = `^[^/\\?:&*'"]+$`
+
+// Schema a JSON Schema object, with the subset of keywords required to describe the YAML resources
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // A name, or a list of names
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// SchemaResource a YAML resource with a schema
+type SchemaResource struct {
+	Name        string
+	Description string
+	Type        reflect.Type
+}
+
+// SchemaResources the resources that can be described through a JSON Schema
+var SchemaResources = []SchemaResource{
+	{"requisition", "A requisition with its nodes", reflect.TypeOf(Requisition{})},
+	{"node", "A requisitioned node", reflect.TypeOf(RequisitionNode{})},
+	{"interface", "An IP interface of a requisitioned node", reflect.TypeOf(RequisitionInterface{})},
+	{"foreign-source", "A foreign source definition", reflect.TypeOf(ForeignSourceDef{})},
+	{"event", "An event to send to OpenNMS", reflect.TypeOf(Event{})},
+	{"snmp-config", "The SNMP configuration of an IP address", reflect.TypeOf(SnmpInfo{})},
+	{"snmp-range", "The SNMP configuration of a range of IP addresses, used on the bulk files", reflect.TypeOf(SnmpRange{})},
+}
+
+// schemaType the hand-written part of the schema of a struct, with the constraints enforced by its Validate method
+type schemaType struct {
+	description string
+	required    []string
+	properties  map[string]*Schema // Indexed by the YAML name of the field
+}
+
+// The constraints of the structs used by the resources, indexed by the name of the Go type;
+// every YAML field must have an entry here (enforced by the tests), so new fields are documented
+var schemaTypes = map[string]schemaType{
+	"Requisition": {
+		description: "A requisition or set of nodes",
+		required:    []string{"name"},
+		properties: map[string]*Schema{
+			"name":       {Description: "The name of the requisition (or foreign source)", Pattern: namePattern},
+			"dateStamp":  {Description: "When the requisition was last modified (managed by OpenNMS)"},
+			"lastImport": {Description: "When the requisition was last imported (managed by OpenNMS)"},
+			"nodes":      {Description: "The nodes of the requisition; the foreign IDs must be unique"},
+			"defaults":   {Description: "The content added to every node by onmsctl before sending the requisition; the values of each node win on conflicts"},
+		},
+	},
+	"RequisitionDefaults": {
+		description: "The content shared by all the nodes of a requisition",
+		properties: map[string]*Schema{
+			"location":   {Description: "The monitoring location of the nodes without one"},
+			"categories": {Description: "The surveillance categories added to every node"},
+			"assets":     {Description: "The asset fields added to the nodes that don't define them"},
+			"metaData":   {Description: "The meta-data entries added to the nodes that don't define them, by context and key"},
+		},
+	},
+	"RequisitionNode": {
+		description: "A requisitioned node",
+		required:    []string{"foreignID"},
+		properties: map[string]*Schema{
+			"foreignID":           {Description: "The unique ID of the node within the requisition", Pattern: namePattern},
+			"nodeLabel":           {Description: "The label of the node (defaults to the foreign ID)"},
+			"location":            {Description: "The monitoring location of the node (defaults to the central location)"},
+			"city":                {Description: "The city where the node is located"},
+			"building":            {Description: "The building where the node is located"},
+			"parentForeignSource": {Description: "The requisition of the parent node (defaults to the requisition of the node)"},
+			"parentForeignID":     {Description: "The foreign ID of the parent node; cannot be combined with parentNodeLabel"},
+			"parentNodeLabel":     {Description: "The label of the parent node; cannot be combined with parentForeignID"},
+			"interfaces":          {Description: "The IP interfaces of the node; only one can be the SNMP primary interface"},
+			"categories":          {Description: "The surveillance categories of the node"},
+			"assets":              {Description: "The asset fields of the node"},
+			"metaData":            {Description: "The meta-data entries of the node"},
+		},
+	},
+	"RequisitionInterface": {
+		description: "An IP interface of a requisitioned node",
+		required:    []string{"ipAddress"},
+		properties: map[string]*Schema{
+			"ipAddress":   {Description: "The IPv4 or IPv6 address, or a FQDN translated into an address when the requisition is validated"},
+			"description": {Description: "The description of the interface"},
+			"snmpPrimary": {Description: "P for primary, S for secondary, or N for not eligible (the default)", Enum: []interface{}{"P", "S", "N"}},
+			"status":      {Description: "1 for managed (the default), or 3 for unmanaged", Enum: []interface{}{1, 3}},
+			"services":    {Description: "The monitored services of the interface"},
+			"metaData":    {Description: "The meta-data entries of the interface"},
+		},
+	},
+	"RequisitionMonitoredService": {
+		description: "A monitored service of an IP interface",
+		required:    []string{"name"},
+		properties: map[string]*Schema{
+			"name":     {Description: "The name of the service (e.x. ICMP)", Pattern: namePattern},
+			"metaData": {Description: "The meta-data entries of the service"},
+		},
+	},
+	"RequisitionCategory": {
+		description: "A surveillance category",
+		required:    []string{"name"},
+		properties: map[string]*Schema{
+			"name": {Description: "The name of the category", Pattern: namePattern},
+		},
+	},
+	"RequisitionAsset": {
+		description: "An asset field",
+		required:    []string{"name", "value"},
+		properties: map[string]*Schema{
+			"name":  {Description: "The name of the asset field (e.x. address1)", Pattern: namePattern},
+			"value": {Description: "The value of the asset field"},
+		},
+	},
+	"RequisitionMetaData": {
+		description: "A meta-data entry",
+		required:    []string{"key", "value"},
+		properties: map[string]*Schema{
+			"key":     {Description: "The key of the entry"},
+			"value":   {Description: "The value of the entry"},
+			"context": {Description: "The context of the entry (defaults to requisition)"},
+		},
+	},
+	"ForeignSourceDef": {
+		description: "A foreign source definition",
+		required:    []string{"name", "scanInterval"},
+		properties: map[string]*Schema{
+			"name":         {Description: "The name of the foreign source, which matches the name of the requisition", Pattern: namePattern},
+			"dateStamp":    {Description: "When the definition was last modified (managed by OpenNMS)"},
+			"scanInterval": {Description: "How often the nodes are rescanned, as a list of durations (e.x. 1d, or 1w 2d 12h)", Pattern: `^[0-9]+(w|d|h|m|s|ms)( [0-9]+(w|d|h|m|s|ms))*$`},
+			"detectors":    {Description: "The detectors used to discover services"},
+			"policies":     {Description: "The policies applied to the scanned entities"},
+		},
+	},
+	"Detector": {
+		description: "A provisioning detector",
+		required:    []string{"name", "class"},
+		properties: map[string]*Schema{
+			"name":       {Description: "The name of the detector, which is the name of the detected service", Pattern: namePattern},
+			"class":      {Description: "The Java class of the detector (see 'onmsctl inv detector enum')"},
+			"parameters": {Description: "The parameters of the detector"},
+		},
+	},
+	"Policy": {
+		description: "A provisioning policy",
+		required:    []string{"name", "class"},
+		properties: map[string]*Schema{
+			"name":       {Description: "The name of the policy", Pattern: namePattern},
+			"class":      {Description: "The Java class of the policy (see 'onmsctl inv policy enum')"},
+			"parameters": {Description: "The parameters of the policy"},
+		},
+	},
+	"Parameter": {
+		description: "A parameter of a detector or a policy",
+		required:    []string{"key", "value"},
+		properties: map[string]*Schema{
+			"key":   {Description: "The name of the parameter"},
+			"value": {Description: "The value of the parameter"},
+		},
+	},
+	"Event": {
+		description: "An event",
+		required:    []string{"uei"},
+		properties: map[string]*Schema{
+			"uei":           {Description: "The unique event identifier"},
+			"source":        {Description: "The source of the event (defaults to onmsctl)"},
+			"time":          {Description: "When the event was generated (defaults to now)"},
+			"host":          {Description: "The host that generated the event"},
+			"masterStation": {Description: "The OpenNMS server that processes the event"},
+			"nodeID":        {Description: "The ID of the node associated with the event", Minimum: intPtr(0)},
+			"interface":     {Description: "The IPv4 or IPv6 address of the interface associated with the event"},
+			"service":       {Description: "The service associated with the event"},
+			"ifIndex":       {Description: "The SNMP interface index associated with the event"},
+			"snmpHost":      {Description: "The SNMP host that generated the event"},
+			"parameters":    {Description: "The parameters of the event"},
+			"description":   {Description: "The description of the event"},
+			"severity":      {Description: "The severity of the event", Enum: enumValues(Severities)},
+			"pathOutage":    {Description: "The path outage of the event"},
+			"operInstruct":  {Description: "The operator instructions of the event"},
+			"logmessage":    {Description: "The log message of the event"},
+			"snmp":          {Description: "The SNMP information of traps"},
+			"snmpmask":      {Description: "The mask elements of the event"},
+		},
+	},
+	"EventParam": {
+		description: "A parameter of an event",
+		required:    []string{"name"},
+		properties: map[string]*Schema{
+			"name":     {Description: "The name of the parameter"},
+			"value":    {Description: "The value of the parameter; it must be an integer when the type is int"},
+			"type":     {Description: "The type of the value (defaults to string)", Enum: enumValues(EventParamTypes)},
+			"encoding": {Description: "The encoding of the value (defaults to text); use base64 for binary content", Enum: enumValues(EventParamEncodings)},
+		},
+	},
+	"LogMsg": {
+		description: "The log message of an event",
+		required:    []string{"message"},
+		properties: map[string]*Schema{
+			"message":     {Description: "The text of the message"},
+			"notify":      {Description: "Whether notifications are sent for the event"},
+			"destination": {Description: "Where the event is stored and displayed (defaults to logndisplay)"},
+		},
+	},
+	"SNMP": {
+		description: "The SNMP information of a trap",
+		properties: map[string]*Schema{
+			"id":        {Description: "The enterprise OID"},
+			"version":   {Description: "The SNMP version of the trap"},
+			"specific":  {Description: "The specific trap number"},
+			"generic":   {Description: "The generic trap number"},
+			"community": {Description: "The community string of the trap"},
+			"timeStamp": {Description: "The time stamp of the trap"},
+		},
+	},
+	"Mask": {
+		description: "The mask of an event",
+		properties: map[string]*Schema{
+			"maskElement": {Description: "The elements of the mask"},
+		},
+	},
+	"MaskElement": {
+		description: "A mask element of an event",
+		properties: map[string]*Schema{
+			"mename":  {Description: "The name of the element"},
+			"mevalue": {Description: "The values of the element"},
+		},
+	},
+	"SnmpInfo": {
+		description: "The SNMP configuration; the community is required except for v3, which requires the security name",
+		properties: map[string]*Schema{
+			"version":         {Description: "The SNMP version", Enum: enumValues(*SNMPVersions)},
+			"location":        {Description: "The monitoring location of the IP addresses"},
+			"port":            {Description: "The UDP port", Minimum: intPtr(1), Maximum: intPtr(65535)},
+			"retries":         {Description: "The number of retries", Minimum: intPtr(0)},
+			"timeout":         {Description: "The timeout in milliseconds", Minimum: intPtr(0)},
+			"community":       {Description: "The community string (v1 and v2c)"},
+			"contextName":     {Description: "The context name (v3)"},
+			"securityLevel":   {Description: "1 for noAuthNoPriv, 2 for authNoPriv, or 3 for authPriv (v3)", Minimum: intPtr(0), Maximum: intPtr(3)},
+			"securityName":    {Description: "The security name (v3)"},
+			"privProtocol":    {Description: "The privacy protocol (v3)", Enum: enumValues(*SNMPPrivProtocols)},
+			"privPassPhrase":  {Description: "The privacy pass phrase, required for authPriv (v3)"},
+			"authProtocol":    {Description: "The authentication protocol (v3)", Enum: enumValues(*SNMPAuthProtocols)},
+			"authPassPhrase":  {Description: "The authentication pass phrase, required for authNoPriv and authPriv (v3)"},
+			"engineID":        {Description: "The engine ID (v3)"},
+			"ContextEngineID": {Description: "The context engine ID (v3)"},
+			"enterpriseID":    {Description: "The enterprise ID (v3)"},
+			"maxRequestSize":  {Description: "The maximum size of a request in bytes", Minimum: intPtr(0)},
+			"maxRepetitions":  {Description: "The maximum repetitions of bulk requests", Minimum: intPtr(0)},
+			"maxVarsPerPdu":   {Description: "The maximum variables per PDU", Minimum: intPtr(0)},
+			"proxyHost":       {Description: "The proxy host"},
+			"ttl":             {Description: "The time to live of the entry in milliseconds", Minimum: intPtr(0)},
+		},
+	},
+	"SnmpRange": {
+		description: "The SNMP configuration of a range of IP addresses",
+		required:    []string{"firstIPAddress", "lastIPAddress"},
+		properties: map[string]*Schema{
+			"firstIPAddress": {Description: "The first IP address of the range, or a CIDR block"},
+			"lastIPAddress":  {Description: "The last IP address of the range (ignored for CIDR blocks)"},
+		},
+	},
+}
+
+// GetSchemaResourceNames returns the names of the resources with a schema
+func GetSchemaResourceNames() []string {
+	names := make([]string, len(SchemaResources))
+	for i, r := range SchemaResources {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// GetSchema returns the JSON Schema of a resource
+func GetSchema(resource string) (*Schema, error) {
+	for _, r := range SchemaResources {
+		if r.Name == resource {
+			definitions := make(map[string]*Schema)
+			schema, err := buildStructSchema(r.Type, definitions)
+			if err != nil {
+				return nil, err
+			}
+			schema.Schema = SchemaDraft
+			schema.Title = r.Name
+			if len(definitions) > 0 {
+				schema.Definitions = definitions
+			}
+			return schema, nil
+		}
+	}
+	return nil, fmt.Errorf("Invalid resource %s; allowed values are %s", resource, strings.Join(GetSchemaResourceNames(), ", "))
+}
+
+// Builds the schema of a struct; the nested structs are added to the definitions
+func buildStructSchema(t reflect.Type, definitions map[string]*Schema) (*Schema, error) {
+	def, ok := schemaTypes[t.Name()]
+	if !ok {
+		return nil, fmt.Errorf("There is no schema for %s", t.Name())
+	}
+	closed := false
+	schema := &Schema{
+		Type:                 "object",
+		Description:          def.description,
+		Required:             def.required,
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: &closed,
+	}
+	if err := addStructProperties(t, def, schema, definitions); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Adds the YAML fields of a struct to the schema, including the inlined structs
+func addStructProperties(t reflect.Type, def schemaType, schema *Schema, definitions map[string]*Schema) error {
+	for _, field := range getYAMLFields(t) {
+		if field.inline {
+			inlined, ok := schemaTypes[field.Type.Name()]
+			if !ok {
+				return fmt.Errorf("There is no schema for %s", field.Type.Name())
+			}
+			schema.Required = append(schema.Required, inlined.required...)
+			if err := addStructProperties(field.Type, inlined, schema, definitions); err != nil {
+				return err
+			}
+			continue
+		}
+		constraints, ok := def.properties[field.name]
+		if !ok {
+			return fmt.Errorf("There is no schema for field %s of %s", field.name, t.Name())
+		}
+		property, err := buildFieldSchema(field.Type, definitions)
+		if err != nil {
+			return err
+		}
+		property.Description = constraints.Description
+		property.Enum = constraints.Enum
+		property.Pattern = constraints.Pattern
+		property.Minimum = constraints.Minimum
+		property.Maximum = constraints.Maximum
+		schema.Properties[field.name] = property
+	}
+	return nil
+}
+
+// Builds the schema of a field based on its Go type
+func buildFieldSchema(t reflect.Type, definitions map[string]*Schema) (*Schema, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(Time{}) {
+		return &Schema{Type: []string{"string", "integer"}}, nil // RFC3339, or milliseconds since the epoch
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Slice:
+		items, err := buildFieldSchema(t.Elem(), definitions)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Struct:
+		if _, ok := definitions[t.Name()]; !ok {
+			definitions[t.Name()] = nil // Reserved, in case of recursive types
+			schema, err := buildStructSchema(t, definitions)
+			if err != nil {
+				return nil, err
+			}
+			definitions[t.Name()] = schema
+		}
+		return &Schema{Ref: "#/definitions/" + t.Name()}, nil
+	}
+	return nil, fmt.Errorf("Unsupported type %s", t)
+}
+
+// yamlField a struct field serialized to YAML
+type yamlField struct {
+	reflect.StructField
+	name   string
+	inline bool
+}
+
+// Returns the fields of a struct that are serialized to YAML, with the name used by the YAML tag
+func getYAMLFields(t reflect.Type) []yamlField {
+	fields := make([]yamlField, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		field := yamlField{StructField: f, name: parts[0]}
+		for _, option := range parts[1:] {
+			if option == "inline" {
+				field.inline = true
+			}
+		}
+		if field.name == "" && !field.inline {
+			field.name = strings.ToLower(f.Name) // The default of the YAML encoder
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func enumValues(enum EnumValue) []interface{} {
+	values := make([]interface{}, len(enum.Enum))
+	for i, v := range enum.Enum {
+		values[i] = v
+	}
+	return values
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// Every YAML field of the structs used by the resources must have a hand-written schema, and vice versa
+func TestSchemaMatchesStructFields(t *testing.T) {
+	types := make(map[string]reflect.Type)
+	for _, r := range SchemaResources {
+		collectTypes(r.Type, types)
+	}
+	for name := range schemaTypes {
+		_, ok := types[name]
+		assert.Assert(t, ok, "the schema of %s is not used by any resource", name)
+	}
+	for name, structType := range types {
+		def, ok := schemaTypes[name]
+		assert.Assert(t, ok, "there is no schema for %s", name)
+		fields := make(map[string]bool)
+		for _, f := range getYAMLFields(structType) {
+			if f.inline {
+				continue
+			}
+			fields[f.name] = true
+			_, ok := def.properties[f.name]
+			assert.Assert(t, ok, "there is no schema for field %s of %s", f.name, name)
+		}
+		for property := range def.properties {
+			assert.Assert(t, fields[property], "the schema of %s has the unknown field %s", name, property)
+		}
+		for _, property := range def.required {
+			assert.Assert(t, fields[property], "the schema of %s requires the unknown field %s", name, property)
+		}
+	}
+}
+
+func TestGetSchema(t *testing.T) {
+	for _, r := range SchemaResources {
+		schema, err := GetSchema(r.Name)
+		assert.NilError(t, err)
+		assert.Equal(t, SchemaDraft, schema.Schema)
+		_, err = json.Marshal(schema)
+		assert.NilError(t, err)
+	}
+
+	schema, err := GetSchema("requisition")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"name"}, schema.Required)
+	assert.DeepEqual(t, []string{"string", "integer"}, schema.Properties["dateStamp"].Type)
+	assert.Equal(t, "#/definitions/RequisitionNode", schema.Properties["nodes"].Items.Ref)
+	intf := schema.Definitions["RequisitionInterface"]
+	assert.Assert(t, intf != nil)
+	assert.DeepEqual(t, []interface{}{"P", "S", "N"}, intf.Properties["snmpPrimary"].Enum)
+	assert.DeepEqual(t, []interface{}{1, 3}, intf.Properties["status"].Enum)
+	assert.Equal(t, "integer", intf.Properties["status"].Type)
+	assert.Equal(t, false, *intf.AdditionalProperties)
+
+	schema, err = GetSchema("snmp-range")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"firstIPAddress", "lastIPAddress"}, schema.Required)
+	assert.Equal(t, "string", schema.Properties["community"].Type)
+	assert.Equal(t, 0, len(schema.Definitions))
+
+	_, err = GetSchema("alarm")
+	assert.ErrorContains(t, err, "Invalid resource alarm")
+}
+
+// The name pattern must reject the same characters as the Validate methods
+func TestSchemaNamePattern(t *testing.T) {
+	pattern := regexp.MustCompile(namePattern)
+	for _, name := range []string{"srv01", "Web Servers", "n-1.example.com"} {
+		assert.Assert(t, pattern.MatchString(name), name)
+		assert.NilError(t, RequisitionCategory{Name: name}.Validate())
+	}
+	for _, name := range []string{"a/b", `a\b`, "a?", "a:b", "a&b", "a*", "a'b", `a"b`} {
+		assert.Assert(t, !pattern.MatchString(name), name)
+		assert.Assert(t, RequisitionCategory{Name: name}.Validate() != nil, name)
+	}
+}
+
+func collectTypes(t reflect.Type, types map[string]reflect.Type) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(Time{}) {
+		return
+	}
+	if _, ok := types[t.Name()]; ok {
+		return
+	}
+	types[t.Name()] = t
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Tag.Get("yaml") != "-" {
+			collectTypes(f.Type, types)
+		}
+	}
+}
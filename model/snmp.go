@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"net"
 )
 
 // SNMPVersions the SNMP version enumeration
@@ -49,7 +50,7 @@ type SnmpInfo struct {
 // Validate returns an error if the service is invalid
 func (s *SnmpInfo) Validate() error {
 	if s.Version != "" {
-		if err := SNMPVersions.Set(s.Version); err != nil {
+		if !SNMPVersions.Contains(s.Version) {
 			return fmt.Errorf("Invalid SNMP Version. Allowed values: %s", SNMPVersions.EnumAsString())
 		}
 	}
@@ -64,15 +65,57 @@ func (s *SnmpInfo) Validate() error {
 			s.SecurityLevel = 0
 		}
 	}
+	if s.Version == "v3" {
+		if s.Community != "" {
+			return fmt.Errorf("SNMP Community String is not used with SNMPv3")
+		}
+		if s.SecurityName == "" {
+			return fmt.Errorf("SNMPv3 Security Name cannot be null")
+		}
+		if s.SecurityLevel >= 2 && s.AuthPassPhrase == "" {
+			return fmt.Errorf("SNMPv3 Auth Pass Phrase required for authNoPriv and authPriv")
+		}
+		if s.SecurityLevel == 3 && s.PrivPassPhrase == "" {
+			return fmt.Errorf("SNMPv3 Priv Pass Phrase required for authPriv")
+		}
+	}
 	if s.PrivProtocol != "" {
-		if err := SNMPPrivProtocols.Set(s.PrivProtocol); err != nil {
+		if !SNMPPrivProtocols.Contains(s.PrivProtocol) {
 			return fmt.Errorf("Invalid Priv Protocol. Allowed values: %s", SNMPPrivProtocols.EnumAsString())
 		}
 	}
 	if s.AuthProtocol != "" {
-		if err := SNMPAuthProtocols.Set(s.AuthProtocol); err != nil {
+		if !SNMPAuthProtocols.Contains(s.AuthProtocol) {
 			return fmt.Errorf("Invalid Auth Protocol. Allowed values: %s", SNMPAuthProtocols.EnumAsString())
 		}
 	}
 	return nil
 }
+
+// SnmpRange SNMP Configuration for a range of IP addresses
+type SnmpRange struct {
+	FirstIPAddress string `json:"firstIPAddress" yaml:"firstIPAddress"`
+	LastIPAddress  string `json:"lastIPAddress" yaml:"lastIPAddress"`
+	SnmpInfo       `yaml:",inline"`
+}
+
+// Validate returns an error if the range or its configuration is invalid
+func (r *SnmpRange) Validate() error {
+	if err := validateRange(r.FirstIPAddress, r.LastIPAddress); err != nil {
+		return err
+	}
+	return r.SnmpInfo.Validate()
+}
+
+// GetCIDRRange returns the first and the last IP addresses of a CIDR block
+func GetCIDRRange(cidr string) (string, string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid CIDR block %s", cidr)
+	}
+	last := make(net.IP, len(network.IP))
+	for i := range network.IP {
+		last[i] = network.IP[i] | ^network.Mask[i]
+	}
+	return network.IP.String(), last.String(), nil
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSnmpInfoValidate(t *testing.T) {
+	v2 := SnmpInfo{Version: "v2c"}
+	assert.Error(t, v2.Validate(), "SNMP Community String cannot be null")
+	v2.Community = "public"
+	assert.NilError(t, v2.Validate())
+
+	v3 := SnmpInfo{Version: "v3", Community: "public", SecurityName: "opennms", SecurityLevel: 3}
+	assert.Error(t, v3.Validate(), "SNMP Community String is not used with SNMPv3")
+
+	v3.Community = ""
+	v3.SecurityName = ""
+	assert.Error(t, v3.Validate(), "SNMPv3 Security Name cannot be null")
+
+	v3.SecurityName = "opennms"
+	assert.Error(t, v3.Validate(), "SNMPv3 Auth Pass Phrase required for authNoPriv and authPriv")
+
+	v3.AuthProtocol = "SHA"
+	v3.AuthPassPhrase = "0p3nNMSv3"
+	assert.Error(t, v3.Validate(), "SNMPv3 Priv Pass Phrase required for authPriv")
+
+	v3.SecurityLevel = 2
+	assert.NilError(t, v3.Validate())
+
+	v3.SecurityLevel = 3
+	v3.PrivProtocol = "AES"
+	v3.PrivPassPhrase = "0p3nNMSv3"
+	assert.NilError(t, v3.Validate())
+}
+
+func TestSnmpRangeValidate(t *testing.T) {
+	r := SnmpRange{FirstIPAddress: "10.0.0.10", LastIPAddress: "10.0.0.1", SnmpInfo: SnmpInfo{Version: "v2c", Community: "public"}}
+	assert.Error(t, r.Validate(), "begin 10.0.0.10 must be lower or equal than end 10.0.0.1")
+	r.LastIPAddress = "10.0.0.100"
+	assert.NilError(t, r.Validate())
+	r.Community = ""
+	assert.Error(t, r.Validate(), "SNMP Community String cannot be null")
+}
+
+func TestGetCIDRRange(t *testing.T) {
+	first, last, err := GetCIDRRange("10.0.1.7/24")
+	assert.NilError(t, err)
+	assert.Equal(t, "10.0.1.0", first)
+	assert.Equal(t, "10.0.1.255", last)
+	first, last, err = GetCIDRRange("2001:db8::/126")
+	assert.NilError(t, err)
+	assert.Equal(t, "2001:db8::", first)
+	assert.Equal(t, "2001:db8::3", last)
+	_, _, err = GetCIDRRange("10.0.1.0")
+	assert.Error(t, err, "Invalid CIDR block 10.0.1.0")
+}
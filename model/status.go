@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+)
+
+// The states of each section of the status summary
+const (
+	StatusSectionOK      = "ok"
+	StatusSectionMissing = "missing" // The server doesn't provide the endpoint of the section
+	StatusSectionFailed  = "failed"
+)
+
+// StatusSeverities the alarm severities counted on the status summary, from the most severe
+var StatusSeverities = []string{"Critical", "Major", "Minor", "Warning", "Indeterminate"}
+
+// StatusSection the outcome of fetching a section of the status summary
+type StatusSection struct {
+	State string `json:"state" yaml:"state"`
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// IsAvailable returns true when the data of the section was obtained
+func (s StatusSection) IsAvailable() bool {
+	return s.State == StatusSectionOK
+}
+
+// Unavailable returns the text displayed instead of the data of an unavailable section
+func (s StatusSection) Unavailable() string {
+	if s.State == StatusSectionMissing {
+		return "n/a (endpoint missing)"
+	}
+	return fmt.Sprintf("n/a (%s)", s.Error)
+}
+
+// SeverityCount the amount of alarms with a given severity
+type SeverityCount struct {
+	Severity string `json:"severity" yaml:"severity"`
+	Count    int    `json:"count" yaml:"count"`
+}
+
+// AlarmsStatus the alarms by severity
+type AlarmsStatus struct {
+	StatusSection `yaml:",inline"`
+	Severities    []SeverityCount `json:"severities,omitempty" yaml:"severities,omitempty"`
+}
+
+// OutagesStatus the current outages and the nodes affected by them
+type OutagesStatus struct {
+	StatusSection `yaml:",inline"`
+	Outages       int      `json:"outages" yaml:"outages"`
+	NodesDown     []string `json:"nodesDown,omitempty" yaml:"nodesDown,omitempty"`
+}
+
+// MinionsStatus the minions whose heartbeat stopped
+type MinionsStatus struct {
+	StatusSection `yaml:",inline"`
+	Total         int      `json:"total" yaml:"total"`
+	Down          []string `json:"down,omitempty" yaml:"down,omitempty"`
+}
+
+// RequisitionImport the last time a requisition was imported by Provisiond
+type RequisitionImport struct {
+	Name       string `json:"name" yaml:"name"`
+	Nodes      int    `json:"nodes" yaml:"nodes"`
+	LastImport *Time  `json:"lastImport,omitempty" yaml:"lastImport,omitempty"`
+}
+
+// ImportsStatus the last imports of the requisitions
+type ImportsStatus struct {
+	StatusSection `yaml:",inline"`
+	Requisitions  []RequisitionImport `json:"requisitions,omitempty" yaml:"requisitions,omitempty"`
+}
+
+// ServerStatus a summary of the state of the server and its monitored nodes
+type ServerStatus struct {
+	Time    Time          `json:"time" yaml:"time"`
+	Alarms  AlarmsStatus  `json:"alarms" yaml:"alarms"`
+	Outages OutagesStatus `json:"outages" yaml:"outages"`
+	Minions MinionsStatus `json:"minions" yaml:"minions"`
+	Imports ImportsStatus `json:"imports" yaml:"imports"`
+}
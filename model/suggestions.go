@@ -0,0 +1,56 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The maximum edit distance for a candidate to be suggested as the intended value
+const maxSuggestionDistance = 2
+
+// EditDistance returns the Levenshtein distance between two strings (the number of insertions, deletions or substitutions to turn one into the other)
+func EditDistance(a string, b string) int {
+	s, t := []rune(a), []rune(b)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(t)]
+}
+
+// ClosestMatch returns the candidate that is most likely a typo of the value (ignoring case), or an empty string when none is close enough
+func ClosestMatch(value string, candidates []string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, c := range candidates {
+		if d := EditDistance(strings.ToLower(value), strings.ToLower(c)); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// DidYouMean returns a hint with the closest candidate to the value, like "; did you mean 'retries'?", or an empty string when none is close enough
+func DidYouMean(value string, candidates []string) string {
+	if match := ClosestMatch(value, candidates); match != "" {
+		return fmt.Sprintf("; did you mean '%s'?", match)
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, EditDistance("retries", "retries"))
+	assert.Equal(t, 2, EditDistance("retires", "retries"))
+	assert.Equal(t, 1, EditDistance("timout", "timeout"))
+	assert.Equal(t, 3, EditDistance("kitten", "sitting"))
+	assert.Equal(t, 4, EditDistance("", "port"))
+	assert.Equal(t, 1, EditDistance("café", "cafe"))
+}
+
+func TestClosestMatch(t *testing.T) {
+	keys := []string{"allowFragmentation", "dscp", "ipMatch", "port", "retries", "timeout"}
+	assert.Equal(t, "retries", ClosestMatch("retires", keys))
+	assert.Equal(t, "timeout", ClosestMatch("Timeout", keys))
+	assert.Equal(t, "", ClosestMatch("community", keys))
+	assert.Equal(t, "; did you mean 'ipMatch'?", DidYouMean("ipmatch", keys))
+	assert.Equal(t, "", DidYouMean("community", keys))
+}
+
+func TestVerifyParametersSuggestions(t *testing.T) {
+	plugin := Plugin{
+		Class:      "org.opennms.netmgt.provision.detector.icmp.IcmpDetector",
+		Parameters: []PluginParam{{Key: "port"}, {Key: "retries"}, {Key: "timeout"}},
+	}
+	assert.NilError(t, plugin.VerifyParameters([]Parameter{{Key: "retries", Value: "2"}}))
+	assert.Error(t, plugin.VerifyParameters([]Parameter{{Key: "retires", Value: "2"}}),
+		"Invalid parameter retires for org.opennms.netmgt.provision.detector.icmp.IcmpDetector; did you mean 'retries'?")
+	assert.Error(t, plugin.VerifyParameters([]Parameter{{Key: "community", Value: "public"}}),
+		"Invalid parameter community for org.opennms.netmgt.provision.detector.icmp.IcmpDetector; valid parameters: port, retries, timeout")
+
+	plugin.Parameters = nil
+	assert.Error(t, plugin.VerifyParameters([]Parameter{{Key: "port", Value: "80"}}),
+		"Invalid parameter port for org.opennms.netmgt.provision.detector.icmp.IcmpDetector; it doesn't accept parameters")
+
+	list := PluginList{Plugins: []Plugin{{Class: "org.opennms.netmgt.provision.detector.icmp.IcmpDetector"}}}
+	assert.Equal(t, "; did you mean 'org.opennms.netmgt.provision.detector.icmp.IcmpDetector'?", list.SuggestClass("org.opennms.netmgt.provision.detector.icmp.IcmpDetecter"))
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"fmt"
+)
+
+// Threshold types
+const (
+	ThresholdHigh                   = "high"
+	ThresholdLow                    = "low"
+	ThresholdRelativeChange         = "relativeChange"
+	ThresholdAbsoluteChange         = "absoluteChange"
+	ThresholdRearmingAbsoluteChange = "rearmingAbsoluteChange"
+)
+
+// ThresholdTypes the types of thresholds supported by Threshd
+var ThresholdTypes = EnumValue{
+	Enum:    []string{ThresholdHigh, ThresholdLow, ThresholdRelativeChange, ThresholdAbsoluteChange, ThresholdRearmingAbsoluteChange},
+	Default: ThresholdHigh,
+}
+
+// ThresholdSettings the settings shared by thresholds and expressions
+type ThresholdSettings struct {
+	Type         string  `json:"type" yaml:"type"`
+	DsType       string  `json:"ds-type" yaml:"dsType"` // node, if, or the name of a generic resource type
+	Value        float64 `json:"value" yaml:"value"`
+	Rearm        float64 `json:"rearm" yaml:"rearm"`
+	Trigger      int     `json:"trigger" yaml:"trigger"`
+	DsLabel      string  `json:"ds-label,omitempty" yaml:"dsLabel,omitempty"`
+	Description  string  `json:"description,omitempty" yaml:"description,omitempty"`
+	TriggeredUEI string  `json:"triggeredUEI,omitempty" yaml:"triggeredUEI,omitempty"`
+	RearmedUEI   string  `json:"rearmedUEI,omitempty" yaml:"rearmedUEI,omitempty"`
+}
+
+// Validate returns an error if the settings are invalid
+func (s *ThresholdSettings) Validate() error {
+	if s.Type == "" {
+		s.Type = ThresholdTypes.Default
+	}
+	types := ThresholdTypes
+	if err := types.Set(s.Type); err != nil {
+		return fmt.Errorf("Invalid threshold type %s, valid options: %s", s.Type, ThresholdTypes.EnumAsString())
+	}
+	s.Type = types.String()
+	if s.DsType == "" {
+		return fmt.Errorf("Resource type (ds-type) required")
+	}
+	if s.Trigger < 1 {
+		return fmt.Errorf("Trigger must be at least 1, got %d", s.Trigger)
+	}
+	switch s.Type {
+	case ThresholdHigh:
+		if s.Rearm >= s.Value {
+			return fmt.Errorf("The rearm value (%g) of a high threshold must be below its value (%g)", s.Rearm, s.Value)
+		}
+	case ThresholdLow:
+		if s.Rearm <= s.Value {
+			return fmt.Errorf("The rearm value (%g) of a low threshold must be above its value (%g)", s.Rearm, s.Value)
+		}
+	}
+	return nil
+}
+
+// Threshold a threshold evaluated against a single metric
+type Threshold struct {
+	DsName            string `json:"ds-name" yaml:"metric"`
+	ThresholdSettings `yaml:",inline"`
+}
+
+// Validate returns an error if the threshold is invalid
+func (t *Threshold) Validate() error {
+	if t.DsName == "" {
+		return fmt.Errorf("Metric (ds-name) required")
+	}
+	if err := t.ThresholdSettings.Validate(); err != nil {
+		return fmt.Errorf("Invalid threshold for %s: %w", t.DsName, err)
+	}
+	return nil
+}
+
+// Expression a threshold evaluated against a mathematical expression of metrics
+type Expression struct {
+	Expression        string `json:"expression" yaml:"expression"`
+	ThresholdSettings `yaml:",inline"`
+}
+
+// Validate returns an error if the expression is invalid
+func (e *Expression) Validate() error {
+	if e.Expression == "" {
+		return fmt.Errorf("Expression required")
+	}
+	if err := e.ThresholdSettings.Validate(); err != nil {
+		return fmt.Errorf("Invalid expression %s: %w", e.Expression, err)
+	}
+	return nil
+}
+
+// ThresholdGroup a group of thresholds, assigned to packages on threshd-configuration.xml
+type ThresholdGroup struct {
+	Name          string       `json:"name" yaml:"name"`
+	RRDRepository string       `json:"rrdRepository,omitempty" yaml:"rrdRepository,omitempty"`
+	Thresholds    []Threshold  `json:"threshold,omitempty" yaml:"thresholds,omitempty"`
+	Expressions   []Expression `json:"expression,omitempty" yaml:"expressions,omitempty"`
+}
+
+// Validate returns an error if the group is invalid
+func (g *ThresholdGroup) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("Threshold group name required")
+	}
+	keys := make(map[string]bool)
+	for i := range g.Thresholds {
+		t := &g.Thresholds[i]
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		key := t.Type + "/" + t.DsType + "/" + t.DsName
+		if keys[key] {
+			return fmt.Errorf("Duplicate %s threshold for %s on %s resources", t.Type, t.DsName, t.DsType)
+		}
+		keys[key] = true
+	}
+	for i := range g.Expressions {
+		e := &g.Expressions[i]
+		if err := e.Validate(); err != nil {
+			return err
+		}
+		key := e.Type + "/" + e.DsType + "/" + e.Expression
+		if keys[key] {
+			return fmt.Errorf("Duplicate %s threshold for expression %s on %s resources", e.Type, e.Expression, e.DsType)
+		}
+		keys[key] = true
+	}
+	return nil
+}
+
+// SetThreshold adds a threshold to the group, or replaces the one with the same type, resource type and metric;
+// returns true when an existing threshold was replaced
+func (g *ThresholdGroup) SetThreshold(threshold Threshold) bool {
+	for i, t := range g.Thresholds {
+		if t.Type == threshold.Type && t.DsType == threshold.DsType && t.DsName == threshold.DsName {
+			g.Thresholds[i] = threshold
+			return true
+		}
+	}
+	g.Thresholds = append(g.Thresholds, threshold)
+	return false
+}
+
+// ThresholdGroupList a list of threshold groups
+type ThresholdGroupList struct {
+	Count  int              `json:"count" yaml:"count"`
+	Groups []ThresholdGroup `json:"group" yaml:"groups"`
+}
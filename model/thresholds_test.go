@@ -0,0 +1,82 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+func TestValidateThresholdGroup(t *testing.T) {
+	group := ThresholdGroup{
+		Name: "mib2",
+		Thresholds: []Threshold{
+			{DsName: "ifHCInOctets", ThresholdSettings: ThresholdSettings{Type: "HIGH", DsType: "if", Value: 1e9, Rearm: 8e8, Trigger: 3}},
+			{DsName: "ifOperStatus", ThresholdSettings: ThresholdSettings{Type: ThresholdLow, DsType: "if", Value: 1, Rearm: 2, Trigger: 1}},
+		},
+		Expressions: []Expression{
+			{Expression: "ifHCInOctets * 8 / 1000000", ThresholdSettings: ThresholdSettings{DsType: "if", Value: 900, Rearm: 800, Trigger: 2}},
+		},
+	}
+	assert.NilError(t, group.Validate())
+	assert.Equal(t, ThresholdHigh, group.Thresholds[0].Type)
+	assert.Equal(t, ThresholdHigh, group.Expressions[0].Type)
+
+	group.Thresholds[0].Rearm = 1e9
+	assert.Error(t, group.Validate(), "Invalid threshold for ifHCInOctets: The rearm value (1e+09) of a high threshold must be below its value (1e+09)")
+	group.Thresholds[0].Rearm = 8e8
+	group.Thresholds[1].Rearm = 0
+	assert.Error(t, group.Validate(), "Invalid threshold for ifOperStatus: The rearm value (0) of a low threshold must be above its value (1)")
+	group.Thresholds[1].Rearm = 2
+	group.Expressions[0].Trigger = 0
+	assert.Error(t, group.Validate(), "Invalid expression ifHCInOctets * 8 / 1000000: Trigger must be at least 1, got 0")
+	group.Expressions[0].Trigger = 2
+
+	group.Thresholds = append(group.Thresholds, Threshold{DsName: "ifHCInOctets", ThresholdSettings: ThresholdSettings{Type: ThresholdHigh, DsType: "if", Value: 2e9, Rearm: 1e9, Trigger: 1}})
+	assert.Error(t, group.Validate(), "Duplicate high threshold for ifHCInOctets on if resources")
+
+	invalid := Threshold{DsName: "cpu", ThresholdSettings: ThresholdSettings{Type: "above", DsType: "node", Trigger: 1}}
+	assert.Error(t, invalid.Validate(), "Invalid threshold for cpu: Invalid threshold type above, valid options: high, low, relativeChange, absoluteChange, rearmingAbsoluteChange")
+	invalid = Threshold{DsName: "cpu", ThresholdSettings: ThresholdSettings{Type: ThresholdRelativeChange, Value: 1.5, Trigger: 1}}
+	assert.Error(t, invalid.Validate(), "Invalid threshold for cpu: Resource type (ds-type) required")
+	invalid.DsType = "node"
+	assert.NilError(t, invalid.Validate())
+
+	assert.Error(t, (&ThresholdGroup{}).Validate(), "Threshold group name required")
+}
+
+func TestSetThreshold(t *testing.T) {
+	group := ThresholdGroup{Name: "mib2"}
+	threshold := Threshold{DsName: "ifHCInOctets", ThresholdSettings: ThresholdSettings{Type: ThresholdHigh, DsType: "if", Value: 1e9, Rearm: 8e8, Trigger: 3}}
+	assert.Assert(t, !group.SetThreshold(threshold))
+	threshold.Trigger = 5
+	assert.Assert(t, group.SetThreshold(threshold))
+	threshold.Type = ThresholdLow
+	assert.Assert(t, !group.SetThreshold(threshold))
+	assert.Equal(t, 2, len(group.Thresholds))
+	assert.Equal(t, 5, group.Thresholds[0].Trigger)
+}
+
+func TestThresholdGroupEncoding(t *testing.T) {
+	group := ThresholdGroup{}
+	err := yaml.Unmarshal([]byte(`
+name: mib2
+rrdRepository: /opt/opennms/share/rrd/snmp/
+thresholds:
+- metric: ifHCInOctets
+  type: high
+  dsType: if
+  value: 1000000000
+  rearm: 8.0e+8
+  trigger: 3
+  dsLabel: ifName
+`), &group)
+	assert.NilError(t, err)
+	assert.NilError(t, group.Validate())
+	assert.Equal(t, "ifName", group.Thresholds[0].DsLabel)
+
+	bytes, err := json.Marshal(group)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"name":"mib2","rrdRepository":"/opt/opennms/share/rrd/snmp/","threshold":[{"ds-name":"ifHCInOctets","type":"high","ds-type":"if","value":1000000000,"rearm":800000000,"trigger":3,"ds-label":"ifName"}]}`, string(bytes))
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// userIDForbiddenChars the characters that cannot be used on user IDs
+const userIDForbiddenChars = " \t&<>\"'/\\%:;,?#"
+
+var dutySchedulePattern = regexp.MustCompile(`^(?:Mo|Tu|We|Th|Fr|Sa|Su)+(\d{1,4})-(\d{1,4})$`)
+
+// OnmsUser an OpenNMS user
+type OnmsUser struct {
+	XMLName       xml.Name `xml:"user" json:"-" yaml:"-"`
+	ID            string   `xml:"user-id" json:"user-id" yaml:"id"`
+	FullName      string   `xml:"full-name,omitempty" json:"full-name,omitempty" yaml:"fullName,omitempty"`
+	Comments      string   `xml:"user-comments,omitempty" json:"user-comments,omitempty" yaml:"comments,omitempty"`
+	Email         string   `xml:"email,omitempty" json:"email,omitempty" yaml:"email,omitempty"`
+	Password      string   `xml:"password,omitempty" json:"password,omitempty" yaml:"-"`
+	PasswordSalt  bool     `xml:"passwordSalt,omitempty" json:"passwordSalt,omitempty" yaml:"-"`
+	DutySchedules []string `xml:"duty-schedule,omitempty" json:"duty-schedule,omitempty" yaml:"dutySchedules,omitempty"`
+	Roles         []string `xml:"role,omitempty" json:"role,omitempty" yaml:"roles,omitempty"`
+}
+
+// Validate returns an error if the user is invalid
+func (u *OnmsUser) Validate() error {
+	if err := ValidateUserID(u.ID); err != nil {
+		return err
+	}
+	for _, schedule := range u.DutySchedules {
+		if err := ValidateDutySchedule(schedule); err != nil {
+			return err
+		}
+	}
+	for _, role := range u.Roles {
+		if err := ValidateRole(role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasRole returns true if the user has the given role
+func (u OnmsUser) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// OnmsUserList a list of users
+type OnmsUserList struct {
+	Count      int        `json:"count" yaml:"count"`
+	TotalCount int        `json:"totalCount" yaml:"totalCount"`
+	Offset     int        `json:"offset" yaml:"offset"`
+	Users      []OnmsUser `json:"user" yaml:"users"`
+}
+
+// OnmsGroup an OpenNMS group of users
+type OnmsGroup struct {
+	XMLName       xml.Name `xml:"group" json:"-" yaml:"-"`
+	Name          string   `xml:"name" json:"name" yaml:"name"`
+	DefaultMap    string   `xml:"default-map,omitempty" json:"default-map,omitempty" yaml:"defaultMap,omitempty"`
+	Comments      string   `xml:"comments,omitempty" json:"comments,omitempty" yaml:"comments,omitempty"`
+	Users         []string `xml:"user,omitempty" json:"user,omitempty" yaml:"users,omitempty"`
+	DutySchedules []string `xml:"duty-schedule,omitempty" json:"duty-schedule,omitempty" yaml:"dutySchedules,omitempty"`
+}
+
+// Validate returns an error if the group is invalid
+func (g *OnmsGroup) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("Group name cannot be empty")
+	}
+	if strings.ContainsAny(g.Name, userIDForbiddenChars) {
+		return fmt.Errorf("Group name %s cannot contain any of the following characters: %s", g.Name, strings.TrimSpace(userIDForbiddenChars))
+	}
+	for _, user := range g.Users {
+		if err := ValidateUserID(user); err != nil {
+			return err
+		}
+	}
+	for _, schedule := range g.DutySchedules {
+		if err := ValidateDutySchedule(schedule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasUser returns true if the user is a member of the group
+func (g OnmsGroup) HasUser(user string) bool {
+	for _, u := range g.Users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+// OnmsGroupList a list of groups
+type OnmsGroupList struct {
+	Count      int         `json:"count" yaml:"count"`
+	TotalCount int         `json:"totalCount" yaml:"totalCount"`
+	Offset     int         `json:"offset" yaml:"offset"`
+	Groups     []OnmsGroup `json:"group" yaml:"groups"`
+}
+
+// ValidateUserID returns an error if the user ID is empty or has forbidden characters
+func ValidateUserID(id string) error {
+	if id == "" {
+		return fmt.Errorf("User ID cannot be empty")
+	}
+	if strings.ContainsAny(id, userIDForbiddenChars) {
+		return fmt.Errorf("User ID %s cannot contain spaces or any of the following characters: %s", id, strings.TrimSpace(userIDForbiddenChars))
+	}
+	return nil
+}
+
+// ValidateRole returns an error if the role name is invalid (e.x. ROLE_REST)
+func ValidateRole(role string) error {
+	if !strings.HasPrefix(role, "ROLE_") || len(role) == len("ROLE_") {
+		return fmt.Errorf("Invalid role %s, roles must start with ROLE_ (e.x. ROLE_REST)", role)
+	}
+	return nil
+}
+
+// ValidateDutySchedule returns an error if the duty schedule is invalid;
+// a schedule has the days of the week followed by the start and end times (e.x. MoTuWeThFr800-1700)
+func ValidateDutySchedule(schedule string) error {
+	match := dutySchedulePattern.FindStringSubmatch(schedule)
+	if match == nil {
+		return fmt.Errorf("Invalid duty schedule %s, expected days and hours (e.x. MoTuWeThFr800-1700)", schedule)
+	}
+	for _, value := range match[1:] {
+		t, _ := strconv.Atoi(value)
+		if t/100 > 24 || t%100 > 59 || t > 2400 {
+			return fmt.Errorf("Invalid hours on duty schedule %s", schedule)
+		}
+	}
+	return nil
+}
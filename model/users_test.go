@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+func TestUserValidate(t *testing.T) {
+	user := &OnmsUser{}
+	assert.Error(t, user.Validate(), "User ID cannot be empty")
+
+	user.ID = "john doe"
+	assert.ErrorContains(t, user.Validate(), "User ID john doe cannot contain spaces")
+
+	user.ID = "jdoe@example.com"
+	assert.NilError(t, user.Validate())
+
+	user.Roles = []string{"ROLE_REST", "admin"}
+	assert.Error(t, user.Validate(), "Invalid role admin, roles must start with ROLE_ (e.x. ROLE_REST)")
+
+	user.Roles = []string{"ROLE_REST"}
+	user.DutySchedules = []string{"MoTuWeThFr800-1700", "SaSu0-2400"}
+	assert.NilError(t, user.Validate())
+
+	user.DutySchedules = []string{"Weekdays800-1700"}
+	assert.ErrorContains(t, user.Validate(), "Invalid duty schedule Weekdays800-1700")
+
+	user.DutySchedules = []string{"Mo800-1775"}
+	assert.Error(t, user.Validate(), "Invalid hours on duty schedule Mo800-1775")
+}
+
+func TestUserPasswordNotPrinted(t *testing.T) {
+	user := OnmsUser{ID: "jdoe", Password: "21232F297A57A5A743894A0E4A801FC3", PasswordSalt: true}
+	bytes, err := yaml.Marshal(user)
+	assert.NilError(t, err)
+	assert.Equal(t, "id: jdoe\n", string(bytes))
+
+	// The password is part of the JSON payload, as it is required to create users
+	bytes, err = json.Marshal(user)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"user-id":"jdoe","password":"21232F297A57A5A743894A0E4A801FC3","passwordSalt":true}`, string(bytes))
+}
+
+func TestGroupValidate(t *testing.T) {
+	group := &OnmsGroup{}
+	assert.Error(t, group.Validate(), "Group name cannot be empty")
+
+	group.Name = "Network Admins"
+	assert.ErrorContains(t, group.Validate(), "Group name Network Admins cannot contain")
+
+	group.Name = "NetworkAdmins"
+	group.Users = []string{"admin", "j<doe>"}
+	assert.ErrorContains(t, group.Validate(), "User ID j<doe> cannot contain")
+
+	group.Users = []string{"admin"}
+	assert.NilError(t, group.Validate())
+	assert.Assert(t, group.HasUser("admin"))
+}
@@ -0,0 +1,134 @@
+// Package netbox obtains the devices of a Netbox server (https://netbox.dev) and maps them into requisition nodes
+package netbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPageSize the number of devices requested per page
+const DefaultPageSize = 100
+
+// DefaultMaxRetries how many times a request is retried when the server limits the rate of requests
+const DefaultMaxRetries = 5
+
+// Client the settings to use the ReST API of Netbox
+type Client struct {
+	URL        string // The base URL of the server (e.x. https://netbox.example.com)
+	Token      string // An API token with read access to the devices
+	Timeout    time.Duration
+	PageSize   int                          // 0 means DefaultPageSize
+	MaxRetries int                          // 0 means DefaultMaxRetries, negative values disable the retries
+	Sleep      func(d time.Duration) error  // Waits before retrying, time.Sleep when not set (e.x. replaced to honor Ctrl-C)
+	Progress   func(fetched int, total int) // Called after every page, when set
+	Transport  http.RoundTripper            // The default transport when not set
+}
+
+// devicePage a page of the list of devices
+type devicePage struct {
+	Count   int      `json:"count"`
+	Next    *string  `json:"next"`
+	Results []Device `json:"results"`
+}
+
+// GetDevices returns all the devices that match the filters (e.x. site=ams1 or status=active), following the pages of the list
+func (c Client) GetDevices(filters url.Values) ([]Device, error) {
+	if c.URL == "" {
+		return nil, fmt.Errorf("Netbox URL required")
+	}
+	params := url.Values{}
+	for key, values := range filters {
+		params[key] = values
+	}
+	pageSize := c.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	params.Set("limit", strconv.Itoa(pageSize))
+	next := strings.TrimSuffix(c.URL, "/") + "/api/dcim/devices/?" + params.Encode()
+	devices := make([]Device, 0)
+	for next != "" {
+		page := &devicePage{}
+		if err := c.get(next, page); err != nil {
+			return nil, err
+		}
+		devices = append(devices, page.Results...)
+		if c.Progress != nil {
+			c.Progress(len(devices), page.Count)
+		}
+		next = ""
+		if page.Next != nil && len(page.Results) > 0 {
+			next = *page.Next
+		}
+	}
+	return devices, nil
+}
+
+// Sends a GET request and decodes the JSON response, waiting and retrying while the server limits the rate of requests
+func (c Client) get(location string, target interface{}) error {
+	client := &http.Client{Timeout: c.Timeout, Transport: c.Transport}
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequest(http.MethodGet, location, nil)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Accept", "application/json")
+		if c.Token != "" {
+			request.Header.Set("Authorization", "Token "+c.Token)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return fmt.Errorf("Cannot obtain the devices from Netbox: %w", err)
+		}
+		data, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return fmt.Errorf("Cannot obtain the devices from Netbox: %w", err)
+		}
+		switch {
+		case response.StatusCode == http.StatusOK:
+			if err := json.Unmarshal(data, target); err != nil {
+				return fmt.Errorf("Cannot parse the devices from Netbox: %w", err)
+			}
+			return nil
+		case response.StatusCode == http.StatusTooManyRequests && attempt < c.maxRetries():
+			if err := c.sleep(retryDelay(response.Header.Get("Retry-After"), attempt)); err != nil {
+				return err
+			}
+		case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("Netbox rejected the request (%s), verify the API token", response.Status)
+		default:
+			return fmt.Errorf("Cannot obtain the devices from Netbox: %s", response.Status)
+		}
+	}
+}
+
+func (c Client) maxRetries() int {
+	if c.MaxRetries == 0 {
+		return DefaultMaxRetries
+	}
+	return c.MaxRetries
+}
+
+func (c Client) sleep(d time.Duration) error {
+	if c.Sleep != nil {
+		return c.Sleep(d)
+	}
+	time.Sleep(d)
+	return nil
+}
+
+// Returns how long to wait before retrying: the seconds of the Retry-After header when present,
+// or an exponential delay starting at one second
+func retryDelay(retryAfter string, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Second << uint(attempt)
+}
@@ -0,0 +1,60 @@
+package netbox
+
+import "strings"
+
+// Ref a reference to another object (e.x. the site or the role of a device)
+type Ref struct {
+	ID   int    `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	Slug string `json:"slug,omitempty" yaml:"slug,omitempty"`
+}
+
+// IPAddress an IP address of a device, with its prefix length (e.x. 10.0.0.1/24)
+type IPAddress struct {
+	ID      int    `json:"id" yaml:"id"`
+	Address string `json:"address" yaml:"address"`
+}
+
+// DeviceType the model of a device
+type DeviceType struct {
+	Model        string `json:"model" yaml:"model"`
+	Manufacturer *Ref   `json:"manufacturer,omitempty" yaml:"manufacturer,omitempty"`
+}
+
+// Device the relevant fields of a Netbox device
+type Device struct {
+	ID           int                    `json:"id" yaml:"id"`
+	Name         string                 `json:"name" yaml:"name"`
+	DeviceRole   *Ref                   `json:"device_role,omitempty" yaml:"deviceRole,omitempty"` // Until Netbox 3.x
+	Role         *Ref                   `json:"role,omitempty" yaml:"role,omitempty"`              // Since Netbox 4.0
+	DeviceType   *DeviceType            `json:"device_type,omitempty" yaml:"deviceType,omitempty"`
+	Site         *Ref                   `json:"site,omitempty" yaml:"site,omitempty"`
+	Location     *Ref                   `json:"location,omitempty" yaml:"location,omitempty"`
+	Tenant       *Ref                   `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	Platform     *Ref                   `json:"platform,omitempty" yaml:"platform,omitempty"`
+	PrimaryIP    *IPAddress             `json:"primary_ip,omitempty" yaml:"primaryIP,omitempty"`
+	PrimaryIP4   *IPAddress             `json:"primary_ip4,omitempty" yaml:"primaryIP4,omitempty"`
+	PrimaryIP6   *IPAddress             `json:"primary_ip6,omitempty" yaml:"primaryIP6,omitempty"`
+	Tags         []Ref                  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty" yaml:"customFields,omitempty"`
+	Serial       string                 `json:"serial,omitempty" yaml:"serial,omitempty"`
+}
+
+// GetRole returns the role of the device, regardless of the version of Netbox
+func (d Device) GetRole() *Ref {
+	if d.Role != nil {
+		return d.Role
+	}
+	return d.DeviceRole
+}
+
+// GetPrimaryIP returns the primary address of the device without the prefix length, or an empty string when it doesn't have one;
+// primary_ip is used when present, as it reflects the preferred family of the server
+func (d Device) GetPrimaryIP() string {
+	for _, ip := range []*IPAddress{d.PrimaryIP, d.PrimaryIP4, d.PrimaryIP6} {
+		if ip != nil && ip.Address != "" {
+			return strings.SplitN(ip.Address, "/", 2)[0]
+		}
+	}
+	return ""
+}
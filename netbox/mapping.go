@@ -0,0 +1,176 @@
+package netbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gopkg.in/yaml.v2"
+)
+
+// The sources of the foreign ID, the location and the categories of the nodes
+const (
+	SourceName     = "name"
+	SourceID       = "id"
+	SourceSite     = "site"
+	SourceLocation = "location"
+	SourceNone     = "none"
+	SourceRole     = "role"
+	SourceTags     = "tags"
+	SourcePlatform = "platform"
+	SourceTenant   = "tenant"
+)
+
+// Mapping how the devices are converted into requisition nodes; it is read from a YAML file, and anything not defined uses DefaultMapping
+type Mapping struct {
+	ForeignID    string            `yaml:"foreignID,omitempty"`    // name or id
+	Location     string            `yaml:"location,omitempty"`     // site, location or none
+	Locations    map[string]string `yaml:"locations,omitempty"`    // The monitoring location for each site or location slug; the name is used for the rest
+	Categories   []string          `yaml:"categories,omitempty"`   // Any of role, tags, site, platform and tenant
+	CustomFields map[string]string `yaml:"customFields,omitempty"` // The meta-data key for each custom field; empty means all the fields, with their names as keys
+}
+
+// DefaultMapping the mapping used when there is no mapping file
+var DefaultMapping = Mapping{
+	ForeignID:  SourceName,
+	Location:   SourceSite,
+	Categories: []string{SourceRole, SourceTags},
+}
+
+// ParseMapping parses the content of a mapping file; unknown keys are errors, to catch typos
+func ParseMapping(data []byte) (*Mapping, error) {
+	mapping := &Mapping{}
+	if err := yaml.UnmarshalStrict(data, mapping); err != nil {
+		return nil, err
+	}
+	if mapping.ForeignID == "" {
+		mapping.ForeignID = DefaultMapping.ForeignID
+	}
+	if mapping.Location == "" {
+		mapping.Location = DefaultMapping.Location
+	}
+	if mapping.Categories == nil {
+		mapping.Categories = DefaultMapping.Categories
+	}
+	return mapping, mapping.Validate()
+}
+
+// Validate returns an error when a source is invalid
+func (m Mapping) Validate() error {
+	if m.ForeignID != SourceName && m.ForeignID != SourceID {
+		return fmt.Errorf("Invalid foreignID source %s, valid options: %s, %s", m.ForeignID, SourceName, SourceID)
+	}
+	if m.Location != SourceSite && m.Location != SourceLocation && m.Location != SourceNone {
+		return fmt.Errorf("Invalid location source %s, valid options: %s, %s, %s", m.Location, SourceSite, SourceLocation, SourceNone)
+	}
+	for _, c := range m.Categories {
+		switch c {
+		case SourceRole, SourceTags, SourceSite, SourcePlatform, SourceTenant:
+		default:
+			return fmt.Errorf("Invalid category source %s, valid options: %s, %s, %s, %s, %s", c, SourceRole, SourceTags, SourceSite, SourcePlatform, SourceTenant)
+		}
+	}
+	return nil
+}
+
+// Map converts a device into a requisition node: the name is the label, the primary IP is the primary interface,
+// and the location, categories and meta-data come from the sources of the mapping
+func (m Mapping) Map(device Device) (*model.RequisitionNode, error) {
+	if device.Name == "" {
+		return nil, fmt.Errorf("Device %d has no name", device.ID)
+	}
+	node := &model.RequisitionNode{NodeLabel: device.Name, ForeignID: device.Name}
+	if m.ForeignID == SourceID {
+		node.ForeignID = strconv.Itoa(device.ID)
+	}
+	if ref := m.locationRef(device); ref != nil {
+		node.Location = ref.Name
+		if location, ok := m.Locations[ref.Slug]; ok {
+			node.Location = location
+		}
+	}
+	if ip := device.GetPrimaryIP(); ip != "" {
+		node.AddInterface(&model.RequisitionInterface{IPAddress: ip, SnmpPrimary: "P"})
+	}
+	seen := make(map[string]bool)
+	for _, name := range m.categoryNames(device) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			node.Categories = append(node.Categories, model.RequisitionCategory{Name: name})
+		}
+	}
+	fields := make([]string, 0, len(device.CustomFields))
+	for field := range device.CustomFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		key := field
+		if len(m.CustomFields) > 0 {
+			var ok bool
+			if key, ok = m.CustomFields[field]; !ok {
+				continue
+			}
+		}
+		if value, ok := formatCustomField(device.CustomFields[field]); ok {
+			node.AddMetaData(key, value)
+		}
+	}
+	return node, nil
+}
+
+func (m Mapping) locationRef(device Device) *Ref {
+	switch m.Location {
+	case SourceSite:
+		return device.Site
+	case SourceLocation:
+		return device.Location
+	}
+	return nil
+}
+
+func (m Mapping) categoryNames(device Device) []string {
+	names := []string{}
+	for _, source := range m.Categories {
+		var ref *Ref
+		switch source {
+		case SourceRole:
+			ref = device.GetRole()
+		case SourceSite:
+			ref = device.Site
+		case SourcePlatform:
+			ref = device.Platform
+		case SourceTenant:
+			ref = device.Tenant
+		case SourceTags:
+			for _, tag := range device.Tags {
+				names = append(names, tag.Name)
+			}
+		}
+		if ref != nil {
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
+// Formats the value of a custom field as text; empty values are skipped, and objects (e.x. references) are stored as JSON
+func formatCustomField(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
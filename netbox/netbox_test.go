@@ -0,0 +1,169 @@
+package netbox
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+// Reads a recorded response, replacing the placeholder of the links with the URL of the test server
+func readFixture(t *testing.T, name string, server string) []byte {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	assert.NilError(t, err)
+	return []byte(strings.Replace(string(data), "{{server}}", server, -1))
+}
+
+func TestGetDevices(t *testing.T) {
+	var requests int
+	var delays []time.Duration
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests++
+		assert.Equal(t, "/api/dcim/devices/", req.URL.Path)
+		assert.Equal(t, "ams1", req.URL.Query().Get("site"))
+		if req.Header.Get("Authorization") != "Token secret" {
+			res.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if requests == 2 { // The second page is rate limited once
+			res.Header().Set("Retry-After", "3")
+			res.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if req.URL.Query().Get("offset") == "2" {
+			res.Write(readFixture(t, "devices-page2.json", server.URL))
+			return
+		}
+		assert.Equal(t, "2", req.URL.Query().Get("limit"))
+		res.Write(readFixture(t, "devices-page1.json", server.URL))
+	}))
+	defer server.Close()
+
+	client := Client{URL: server.URL + "/", Token: "secret", PageSize: 2, Sleep: func(d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}}
+	devices, err := client.GetDevices(url.Values{"site": {"ams1"}})
+	assert.NilError(t, err)
+	assert.Equal(t, 3, len(devices))
+	assert.Equal(t, 3, requests)
+	assert.DeepEqual(t, []time.Duration{3 * time.Second}, delays)
+	assert.Equal(t, "ams1-core-01", devices[0].Name)
+	assert.Equal(t, "Core Router", devices[0].GetRole().Name)
+	assert.Equal(t, "", devices[2].Name)
+
+	client.Token = "wrong"
+	_, err = client.GetDevices(url.Values{"site": {"ams1"}})
+	assert.Error(t, err, "Netbox rejected the request (403 Forbidden), verify the API token")
+}
+
+func TestRateLimitExhausted(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests++
+		res.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	client := Client{URL: server.URL, MaxRetries: 3, Sleep: func(d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}}
+	_, err := client.GetDevices(nil)
+	assert.Error(t, err, "Cannot obtain the devices from Netbox: 429 Too Many Requests")
+	assert.Equal(t, 4, requests)
+	assert.DeepEqual(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, delays)
+}
+
+func loadDevices(t *testing.T) []Device {
+	page := devicePage{}
+	assert.NilError(t, json.Unmarshal(readFixture(t, "devices-page1.json", "https://netbox"), &page))
+	return page.Results
+}
+
+func TestDefaultMapping(t *testing.T) {
+	devices := loadDevices(t)
+
+	node, err := DefaultMapping.Map(devices[0])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, &model.RequisitionNode{
+		NodeLabel:  "ams1-core-01",
+		ForeignID:  "ams1-core-01",
+		Location:   "Amsterdam 1",
+		Interfaces: []model.RequisitionInterface{{IPAddress: "10.4.0.1", SnmpPrimary: "P"}},
+		Categories: []model.RequisitionCategory{{Name: "Core Router"}, {Name: "Production"}, {Name: "BGP"}},
+		MetaData: []model.RequisitionMetaData{
+			{Context: "requisition", Key: "contract", Value: "CT-2020-118"},
+			{Context: "requisition", Key: "monitored", Value: "true"},
+			{Context: "requisition", Key: "rack_units", Value: "1"},
+		},
+	}, node)
+	assert.NilError(t, node.Validate())
+
+	node, err = DefaultMapping.Map(devices[1])
+	assert.NilError(t, err)
+	assert.Equal(t, "2001:db8:4::10", node.Interfaces[0].IPAddress)
+	assert.DeepEqual(t, []model.RequisitionMetaData{
+		{Context: "requisition", Key: "monitored", Value: "false"},
+		{Context: "requisition", Key: "owner", Value: `{"id":12,"name":"Network Team"}`},
+	}, node.MetaData)
+
+	_, err = DefaultMapping.Map(Device{ID: 103})
+	assert.Error(t, err, "Device 103 has no name")
+
+	// Netbox 4 renamed device_role to role
+	device := Device{}
+	assert.NilError(t, json.Unmarshal(readFixture(t, "netbox4-device.json", ""), &device))
+	node, err = DefaultMapping.Map(device)
+	assert.NilError(t, err)
+	assert.Equal(t, "Firewall", node.Categories[0].Name)
+	assert.Equal(t, "10.6.0.1", node.Interfaces[0].IPAddress)
+}
+
+func TestMappingFile(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "mapping.yaml"))
+	assert.NilError(t, err)
+	mapping, err := ParseMapping(data)
+	assert.NilError(t, err)
+	devices := loadDevices(t)
+
+	node, err := mapping.Map(devices[0])
+	assert.NilError(t, err)
+	assert.Equal(t, "101", node.ForeignID)
+	assert.Equal(t, "ams1-core-01", node.NodeLabel)
+	assert.Equal(t, "Amsterdam", node.Location)
+	assert.DeepEqual(t, []model.RequisitionCategory{{Name: "Core Router"}, {Name: "Junos"}}, node.Categories)
+	assert.DeepEqual(t, []model.RequisitionMetaData{
+		{Context: "requisition", Key: "contract-id", Value: "CT-2020-118"},
+		{Context: "requisition", Key: "units", Value: "1"},
+	}, node.MetaData)
+
+	node, err = mapping.Map(devices[1])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []model.RequisitionCategory{{Name: "Access Switch"}, {Name: "Operations"}}, node.Categories)
+	assert.Equal(t, 0, len(node.MetaData))
+
+	_, err = ParseMapping([]byte("foreignId: id\n"))
+	assert.ErrorContains(t, err, "field foreignId not found")
+	_, err = ParseMapping([]byte("location: region\n"))
+	assert.Error(t, err, "Invalid location source region, valid options: site, location, none")
+	_, err = ParseMapping([]byte("categories: [rack]\n"))
+	assert.Error(t, err, "Invalid category source rack, valid options: role, tags, site, platform, tenant")
+
+	mapping, err = ParseMapping([]byte("location: none\n"))
+	assert.NilError(t, err)
+	node, err = mapping.Map(devices[0])
+	assert.NilError(t, err)
+	assert.Equal(t, "", node.Location)
+	assert.Equal(t, 3, len(node.Categories))
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/rest"
+	"gotest.tools/assert"
+)
+
+func TestDescribeError(t *testing.T) {
+	notFound := &rest.APIError{Method: http.MethodGet, URL: "http://onms:8980/opennms/rest/requisitions/Test", StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+
+	code, message := describeError(notFound)
+	assert.Equal(t, rest.ExitClientError, code)
+	assert.Equal(t, "GET http://onms:8980/opennms/rest/requisitions/Test failed with 404 Not Found", message)
+
+	code, message = describeError(fmt.Errorf("Cannot import requisition Test: %w", notFound))
+	assert.Equal(t, rest.ExitClientError, code)
+	assert.Equal(t, "Cannot import requisition Test: Invalid Response: 404 Not Found\nGET http://onms:8980/opennms/rest/requisitions/Test failed with 404 Not Found", message)
+
+	code, message = describeError(fmt.Errorf("Cannot verify location %s: %w", "Durham", &rest.ConnectionError{URL: "http://onms:8980", Err: fmt.Errorf("connection refused")}))
+	assert.Equal(t, rest.ExitConnectionError, code)
+	assert.Equal(t, "Cannot verify location Durham: Cannot connect to http://onms:8980: connection refused\nCannot connect to http://onms:8980: connection refused", message)
+
+	code, message = describeError(fmt.Errorf("Profile name required"))
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "Profile name required", message)
+}
@@ -0,0 +1,35 @@
+package plot
+
+import (
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// The size of the text in pixels, the height of its capital letters, and the average advance of its characters
+const (
+	fontSize   = 11
+	fontHeight = 8
+	charWidth  = 7
+)
+
+// The Go Regular font, embedded so the PNG images look the same on every system
+var regular = mustParseFont(goregular.TTF)
+
+func mustParseFont(ttf []byte) *truetype.Font {
+	f, err := truetype.Parse(ttf)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Returns a face of the font to draw or measure text; faces are not safe for concurrent use
+func newFace() font.Face {
+	return truetype.NewFace(regular, &truetype.Options{Size: fontSize, DPI: 72, Hinting: font.HintingFull})
+}
+
+// Returns the width of a text in pixels
+func textWidth(text string) int {
+	return font.MeasureString(newFace(), text).Ceil()
+}
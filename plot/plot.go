@@ -0,0 +1,365 @@
+// Package plot lays out time series charts and renders them as PNG images with gg, or as SVG documents with svgo;
+// the text is drawn with the embedded Go Regular font on PNG images, and with the fonts of the viewer on SVG images.
+package plot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind how a series is drawn
+type Kind int
+
+const (
+	// Line draws the values as a line
+	Line Kind = iota
+	// Area fills the space between the values and the base of the series
+	Area
+)
+
+// Series a set of values drawn on a chart, one per timestamp of the chart; missing values are NaN
+type Series struct {
+	Label  string
+	Color  color.NRGBA
+	Kind   Kind
+	Width  int  // Of lines in pixels, 1 when not set
+	Stack  bool // Drawn on top of the previous series, instead of from zero
+	Values []float64
+}
+
+// Chart a set of series that share the time axis
+type Chart struct {
+	Title         string
+	VerticalLabel string
+	Width         int // In pixels, including the title, the axes and the legend
+	Height        int
+	Timestamps    []time.Time
+	Series        []Series
+}
+
+var (
+	background = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	foreground = color.NRGBA{0x20, 0x20, 0x20, 0xff}
+	gridColor  = color.NRGBA{0xdd, 0xdd, 0xdd, 0xff}
+)
+
+// The height of a line of text
+const lineHeight = 14
+
+// The intervals tried for the ticks of the time axis, from the shortest
+var timeSteps = []time.Duration{
+	time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour,
+}
+
+// point a position on the image; missing values are not drawn
+type point struct {
+	x, y    float64
+	missing bool
+}
+
+// The drawing operations implemented by each output format
+type canvas interface {
+	fillRect(r image.Rectangle, c color.NRGBA)
+	polyline(points []point, c color.NRGBA, width int)
+	area(top []point, base []point, c color.NRGBA)
+	text(x int, y int, text string, c color.NRGBA, anchor string) // The anchor is start, middle or end; y is the baseline
+}
+
+// layout the position of every element of the chart
+type layout struct {
+	plot       image.Rectangle // The area where the series are drawn
+	start, end time.Time
+	yMin, yMax float64
+	yTicks     []float64
+	xTicks     []time.Time
+	xFormat    string
+	legend     [][]int // The indexes of the series on each row of the legend
+	tops       [][]float64
+	bases      [][]float64
+}
+
+// Validate returns an error when the chart cannot be drawn
+func (c Chart) Validate() error {
+	if c.Width < 200 || c.Height < 100 {
+		return fmt.Errorf("The chart must be at least 200x100 pixels")
+	}
+	if len(c.Timestamps) < 2 {
+		return fmt.Errorf("At least two timestamps required")
+	}
+	for _, s := range c.Series {
+		if len(s.Values) != len(c.Timestamps) {
+			return fmt.Errorf("Series %s has %d values, but there are %d timestamps", s.Label, len(s.Values), len(c.Timestamps))
+		}
+	}
+	return nil
+}
+
+// Computes where everything goes, and the values of the stacked series
+func (c Chart) layout() layout {
+	l := layout{start: c.Timestamps[0], end: c.Timestamps[len(c.Timestamps)-1]}
+	l.tops, l.bases = c.stackValues()
+	l.yMin, l.yMax = 0, 0
+	for i := range c.Series {
+		for j := range c.Timestamps {
+			for _, v := range []float64{l.tops[i][j], l.bases[i][j]} {
+				if !math.IsNaN(v) {
+					l.yMin, l.yMax = math.Min(l.yMin, v), math.Max(l.yMax, v)
+				}
+			}
+		}
+	}
+	left := 10 + charWidth*7
+	if c.VerticalLabel != "" {
+		left += lineHeight
+	}
+	top := 10
+	if c.Title != "" {
+		top += lineHeight + 6
+	}
+	l.legend = c.legendRows(c.Width - 20)
+	bottom := c.Height - 10 - lineHeight*(1+len(l.legend)) - 6
+	l.plot = image.Rect(left, top, c.Width-20, bottom)
+	l.yTicks, l.yMin, l.yMax = niceTicks(l.yMin, l.yMax, l.plot.Dy()/40)
+	l.xTicks, l.xFormat = timeTicks(l.start, l.end, l.plot.Dx()/(charWidth*8))
+	return l
+}
+
+// Returns the top and the base of each series, adding the values of the previous series to the stacked ones
+func (c Chart) stackValues() ([][]float64, [][]float64) {
+	tops := make([][]float64, len(c.Series))
+	bases := make([][]float64, len(c.Series))
+	for i, s := range c.Series {
+		tops[i] = make([]float64, len(s.Values))
+		bases[i] = make([]float64, len(s.Values))
+		for j, v := range s.Values {
+			base := 0.0
+			if s.Stack && i > 0 {
+				base = tops[i-1][j]
+				if math.IsNaN(base) {
+					base = 0
+				}
+			}
+			bases[i][j] = base
+			tops[i][j] = base + v
+			if s.Kind == Line && !s.Stack {
+				bases[i][j] = math.NaN()
+			}
+		}
+	}
+	return tops, bases
+}
+
+// Splits the series with a label in rows that fit the given width
+func (c Chart) legendRows(width int) [][]int {
+	rows := [][]int{}
+	used := width
+	for i, s := range c.Series {
+		if s.Label == "" {
+			continue
+		}
+		entry := lineHeight + textWidth(s.Label) + 2*charWidth
+		if used+entry > width {
+			rows = append(rows, []int{})
+			used = 0
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], i)
+		used += entry
+	}
+	return rows
+}
+
+func (l layout) x(t time.Time) float64 {
+	return float64(l.plot.Min.X) + float64(t.Sub(l.start))/float64(l.end.Sub(l.start))*float64(l.plot.Dx())
+}
+
+func (l layout) y(v float64) float64 {
+	return float64(l.plot.Max.Y) - (v-l.yMin)/(l.yMax-l.yMin)*float64(l.plot.Dy())
+}
+
+func (l layout) points(timestamps []time.Time, values []float64) []point {
+	points := make([]point, len(values))
+	for i, v := range values {
+		points[i] = point{x: l.x(timestamps[i]), y: l.y(v), missing: math.IsNaN(v)}
+	}
+	return points
+}
+
+// Draws the chart on a canvas
+func (c Chart) draw(cv canvas) {
+	l := c.layout()
+	cv.fillRect(image.Rect(0, 0, c.Width, c.Height), background)
+	if c.Title != "" {
+		cv.text(c.Width/2, 10+lineHeight-2, c.Title, foreground, "middle")
+	}
+	if c.VerticalLabel != "" {
+		// The label goes above the axis, so it doesn't take horizontal space from the plot area
+		cv.text(l.plot.Min.X, l.plot.Min.Y-4, c.VerticalLabel, foreground, "start")
+	}
+	for _, v := range l.yTicks {
+		y := int(math.Round(l.y(v)))
+		cv.fillRect(image.Rect(l.plot.Min.X, y, l.plot.Max.X, y+1), gridColor)
+		cv.text(l.plot.Min.X-6, y+fontHeight/2, FormatValue(v), foreground, "end")
+	}
+	for _, t := range l.xTicks {
+		x := int(math.Round(l.x(t)))
+		cv.fillRect(image.Rect(x, l.plot.Min.Y, x+1, l.plot.Max.Y), gridColor)
+		cv.text(x, l.plot.Max.Y+lineHeight, t.Format(l.xFormat), foreground, "middle")
+	}
+	for i, s := range c.Series {
+		top := l.points(c.Timestamps, l.tops[i])
+		if s.Kind == Area {
+			cv.area(top, l.points(c.Timestamps, l.bases[i]), s.Color)
+			continue
+		}
+		width := s.Width
+		if width < 1 {
+			width = 1
+		}
+		cv.polyline(top, s.Color, width)
+	}
+	cv.fillRect(image.Rect(l.plot.Min.X, l.plot.Max.Y, l.plot.Max.X+1, l.plot.Max.Y+1), foreground)
+	cv.fillRect(image.Rect(l.plot.Min.X, l.plot.Min.Y, l.plot.Min.X+1, l.plot.Max.Y+1), foreground)
+	y := l.plot.Max.Y + lineHeight + 6
+	for _, row := range l.legend {
+		y += lineHeight
+		x := 10
+		for _, i := range row {
+			s := c.Series[i]
+			cv.fillRect(image.Rect(x, y-fontHeight, x+fontHeight, y), s.Color)
+			cv.text(x+lineHeight, y, s.Label, foreground, "start")
+			x += lineHeight + textWidth(s.Label) + 2*charWidth
+		}
+	}
+}
+
+// Splits the points in runs without missing values
+func splitRuns(points []point) [][]point {
+	runs := [][]point{}
+	var run []point
+	for _, p := range points {
+		if p.missing {
+			if len(run) > 0 {
+				runs = append(runs, run)
+			}
+			run = nil
+			continue
+		}
+		run = append(run, p)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// Returns the outline of the area of each run of top points without missing values (with two points at least),
+// followed by its base points in reverse order
+func areaRuns(top []point, base []point) [][]point {
+	outlines := [][]point{}
+	start := 0
+	for _, run := range splitRuns(top) {
+		for start < len(top) && top[start].missing {
+			start++
+		}
+		outline := append([]point{}, run...)
+		for i := len(run) - 1; i >= 0; i-- {
+			outline = append(outline, base[start+i])
+		}
+		start += len(run)
+		if len(run) >= 2 {
+			outlines = append(outlines, outline)
+		}
+	}
+	return outlines
+}
+
+// Returns the ticks for a range of values, with round numbers (e.x. 0, 250, 500), and the range extended to the closest ticks
+func niceTicks(min float64, max float64, count int) ([]float64, float64, float64) {
+	if max == min {
+		max = min + 1
+	}
+	if count < 2 {
+		count = 2
+	}
+	raw := (max - min) / float64(count)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, m := range []float64{1, 2, 2.5, 5} {
+		if m*magnitude >= raw {
+			step = m * magnitude
+			break
+		}
+	}
+	min = math.Floor(min/step) * step
+	max = math.Ceil(max/step) * step
+	ticks := []float64{}
+	for v := min; v <= max+step/2; v += step {
+		ticks = append(ticks, math.Round(v/step)*step)
+	}
+	return ticks, min, max
+}
+
+// Returns the ticks for a time range, aligned to the chosen interval, and the layout to format them
+func timeTicks(start time.Time, end time.Time, count int) ([]time.Time, string) {
+	if count < 1 {
+		count = 1
+	}
+	step := timeSteps[len(timeSteps)-1]
+	for _, s := range timeSteps {
+		if int(end.Sub(start)/s) <= count {
+			step = s
+			break
+		}
+	}
+	format := "15:04"
+	if step >= 24*time.Hour {
+		format = "01-02"
+	}
+	ticks := []time.Time{}
+	for t := start.Truncate(step); !t.After(end); t = t.Add(step) {
+		if !t.Before(start) {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks, format
+}
+
+// FormatValue formats a value with an SI prefix (e.x. 1.5M or 200k)
+func FormatValue(v float64) string {
+	prefixes := []struct {
+		factor float64
+		symbol string
+	}{{1e12, "T"}, {1e9, "G"}, {1e6, "M"}, {1e3, "k"}, {1, ""}, {1e-3, "m"}}
+	if v == 0 {
+		return "0"
+	}
+	for _, p := range prefixes {
+		if math.Abs(v) >= p.factor || p.symbol == "m" {
+			return strconv.FormatFloat(math.Round(v/p.factor*100)/100, 'f', -1, 64) + p.symbol
+		}
+	}
+	return strconv.FormatFloat(v, 'g', 3, 64)
+}
+
+// ParseColor parses a color as #rrggbb or #rrggbbaa
+func ParseColor(text string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(text, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("Invalid color %s, expected #rrggbb or #rrggbbaa", text)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("Invalid color %s, expected #rrggbb or #rrggbbaa", text)
+	}
+	return color.NRGBA{uint8(value >> 24), uint8(value >> 16), uint8(value >> 8), uint8(value)}, nil
+}
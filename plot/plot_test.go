@@ -0,0 +1,241 @@
+package plot
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// The distance in pixels accepted between the extents of the data and the golden files
+const tolerance = 2
+
+// extents the bounding box of what was drawn with a color, as minX, minY, maxX, maxY
+type extents [4]int
+
+func (e *extents) add(x int, y int) {
+	if e[2] < e[0] {
+		*e = extents{x, y, x, y}
+		return
+	}
+	e[0], e[1] = min(e[0], x), min(e[1], y)
+	e[2], e[3] = max(e[2], x), max(e[3], y)
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func buildChart() Chart {
+	start := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	chart := Chart{Title: "Bits In/Out", VerticalLabel: "Bits per second", Width: 600, Height: 240}
+	in := Series{Label: "In", Color: parseColor("#4e9a06"), Kind: Line, Width: 2}
+	area := Series{Label: "Out", Color: parseColor("#729fcf"), Kind: Area}
+	stacked := Series{Label: "Errors", Color: parseColor("#cc0000"), Kind: Line, Stack: true}
+	for i := 0; i < 73; i++ {
+		chart.Timestamps = append(chart.Timestamps, start.Add(time.Duration(i)*5*time.Minute))
+		in.Values = append(in.Values, 5e6+4e6*math.Sin(float64(i)/8))
+		area.Values = append(area.Values, -2e6-1e6*math.Cos(float64(i)/10))
+		stacked.Values = append(stacked.Values, 5e5)
+	}
+	in.Values[30] = math.NaN()
+	chart.Series = []Series{area, in, stacked}
+	return chart
+}
+
+func parseColor(text string) (c color.NRGBA) {
+	c, _ = ParseColor(text)
+	return c
+}
+
+// Finds the extents of the colors of the series inside the plot area of a PNG image
+func pngExtents(t *testing.T, chart Chart, data []byte) map[string]extents {
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NilError(t, err)
+	result := make(map[string]extents)
+	area := chart.layout().plot
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			pixel := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			for _, s := range chart.Series {
+				if !blendedOverWhite(pixel, s.Color) {
+					continue
+				}
+				name := hexColor(s.Color)
+				e, found := result[name]
+				if !found {
+					e = extents{x, y, x, y}
+				}
+				e.add(x, y)
+				result[name] = e
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Whether a pixel is mostly the given color blended over the white background, as the antialiased edges of the lines are
+func blendedOverWhite(pixel color.NRGBA, c color.NRGBA) bool {
+	channels := [][2]uint8{{pixel.R, c.R}, {pixel.G, c.G}, {pixel.B, c.B}}
+	alpha := -1.0
+	for _, ch := range channels {
+		if ch[1] == 255 {
+			if ch[0] != 255 {
+				return false
+			}
+			continue
+		}
+		a := float64(255-int(ch[0])) / float64(255-int(ch[1]))
+		if alpha >= 0 && math.Abs(a-alpha) > 0.1 {
+			return false
+		}
+		alpha = a
+	}
+	return alpha >= 0.5 && alpha <= 1.05
+}
+
+var svgSeriesPattern = regexp.MustCompile(`<(?:polyline|polygon) points="([^"]+)" class="series"[^>]* (?:stroke|fill)="(#[0-9a-f]{6})"`)
+
+// Finds the extents of the points of the series of an SVG document
+func svgExtents(t *testing.T, data []byte) map[string]extents {
+	result := make(map[string]extents)
+	for _, match := range svgSeriesPattern.FindAllStringSubmatch(string(data), -1) {
+		e, found := result[match[2]]
+		for _, coords := range strings.Fields(match[1]) {
+			xy := strings.Split(coords, ",")
+			x, err := strconv.ParseFloat(xy[0], 64)
+			assert.NilError(t, err)
+			y, err := strconv.ParseFloat(xy[1], 64)
+			assert.NilError(t, err)
+			if !found {
+				e, found = extents{int(math.Round(x)), int(math.Round(y)), int(math.Round(x)), int(math.Round(y))}, true
+			}
+			e.add(int(math.Round(x)), int(math.Round(y)))
+		}
+		result[match[2]] = e
+	}
+	return result
+}
+
+func compareGolden(t *testing.T, name string, actual map[string]extents) {
+	file := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		data, _ := json.MarshalIndent(actual, "", "  ")
+		assert.NilError(t, ioutil.WriteFile(file, append(data, '\n'), 0644))
+	}
+	data, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	expected := make(map[string]extents)
+	assert.NilError(t, json.Unmarshal(data, &expected))
+	assert.Equal(t, len(expected), len(actual), "series drawn on %s", name)
+	for hex, e := range expected {
+		a, ok := actual[hex]
+		assert.Assert(t, ok, "series with color %s on %s", hex, name)
+		for i := range e {
+			assert.Assert(t, abs(e[i]-a[i]) <= tolerance, "extents of %s on %s: expected %v, got %v", hex, name, e, a)
+		}
+	}
+}
+
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func TestRenderPNG(t *testing.T) {
+	chart := buildChart()
+	var out bytes.Buffer
+	assert.NilError(t, chart.RenderPNG(&out))
+	config, err := png.DecodeConfig(bytes.NewReader(out.Bytes()))
+	assert.NilError(t, err)
+	assert.Equal(t, 600, config.Width)
+	assert.Equal(t, 240, config.Height)
+	compareGolden(t, "bits", pngExtents(t, chart, out.Bytes()))
+}
+
+func TestRenderSVG(t *testing.T) {
+	chart := buildChart()
+	var out bytes.Buffer
+	assert.NilError(t, chart.RenderSVG(&out))
+	assert.Assert(t, strings.Contains(out.String(), `<text x="300" y="22" text-anchor="middle" fill="#202020" >Bits In/Out</text>`))
+	// The missing value splits the line
+	assert.Equal(t, 2, strings.Count(out.String(), `stroke="#4e9a06"`))
+	compareGolden(t, "bits", svgExtents(t, out.Bytes()))
+}
+
+func TestValidate(t *testing.T) {
+	chart := buildChart()
+	chart.Width = 100
+	assert.Error(t, chart.RenderPNG(ioutil.Discard), "The chart must be at least 200x100 pixels")
+
+	chart = buildChart()
+	chart.Series[0].Values = chart.Series[0].Values[1:]
+	assert.Error(t, chart.RenderSVG(ioutil.Discard), "Series Out has 72 values, but there are 73 timestamps")
+}
+
+func TestTicks(t *testing.T) {
+	ticks, min, max := niceTicks(-3e6, 9.2e6, 5)
+	assert.DeepEqual(t, []float64{-5e6, -2.5e6, 0, 2.5e6, 5e6, 7.5e6, 1e7}, ticks)
+	assert.Equal(t, -5e6, min)
+	assert.Equal(t, 1e7, max)
+
+	start := time.Date(2020, 1, 15, 12, 7, 0, 0, time.UTC)
+	times, format := timeTicks(start, start.Add(6*time.Hour), 7)
+	assert.Equal(t, "15:04", format)
+	assert.Equal(t, 6, len(times))
+	assert.Equal(t, time.Date(2020, 1, 15, 13, 0, 0, 0, time.UTC), times[0])
+
+	_, format = timeTicks(start, start.Add(30*24*time.Hour), 10)
+	assert.Equal(t, "01-02", format)
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "0", FormatValue(0))
+	assert.Equal(t, "1.5M", FormatValue(1.5e6))
+	assert.Equal(t, "-250k", FormatValue(-2.5e5))
+	assert.Equal(t, "42", FormatValue(42))
+	assert.Equal(t, "500m", FormatValue(0.5))
+	assert.Equal(t, "10G", FormatValue(1e10))
+}
+
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("#73d216")
+	assert.NilError(t, err)
+	assert.Equal(t, color.NRGBA{0x73, 0xd2, 0x16, 0xff}, c)
+	c, err = ParseColor("#73d21680")
+	assert.NilError(t, err)
+	assert.Equal(t, uint8(0x80), c.A)
+	_, err = ParseColor("green")
+	assert.Error(t, err, "Invalid color green, expected #rrggbb or #rrggbbaa")
+}
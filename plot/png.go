@@ -0,0 +1,75 @@
+package plot
+
+import (
+	"image"
+	"image/color"
+	"io"
+
+	"github.com/fogleman/gg"
+)
+
+// pngCanvas draws on a raster image through gg, with antialiasing
+type pngCanvas struct {
+	dc *gg.Context
+}
+
+// RenderPNG draws the chart as a PNG image
+func (c Chart) RenderPNG(w io.Writer) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	cv := &pngCanvas{gg.NewContext(c.Width, c.Height)}
+	cv.dc.SetFontFace(newFace())
+	c.draw(cv)
+	return cv.dc.EncodePNG(w)
+}
+
+func (cv *pngCanvas) fillRect(r image.Rectangle, c color.NRGBA) {
+	cv.dc.DrawRectangle(float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy()))
+	cv.dc.SetColor(c)
+	cv.dc.Fill()
+}
+
+// Draws a line per run of points that are not missing
+func (cv *pngCanvas) polyline(points []point, c color.NRGBA, width int) {
+	for _, run := range splitRuns(points) {
+		if len(run) < 2 {
+			continue
+		}
+		for _, p := range run {
+			cv.dc.LineTo(p.x, p.y)
+		}
+		cv.dc.SetColor(c)
+		cv.dc.SetLineWidth(float64(width))
+		cv.dc.SetLineJoin(gg.LineJoinRound)
+		cv.dc.Stroke()
+	}
+}
+
+// Fills a polygon per run of points that are not missing, from the top to the base in reverse order
+func (cv *pngCanvas) area(top []point, base []point, c color.NRGBA) {
+	for _, run := range areaRuns(top, base) {
+		for _, p := range run {
+			cv.dc.LineTo(p.x, p.y)
+		}
+		cv.dc.ClosePath()
+		cv.dc.SetColor(c)
+		cv.dc.Fill()
+	}
+}
+
+func (cv *pngCanvas) text(x int, y int, text string, c color.NRGBA, anchor string) {
+	cv.dc.SetColor(c)
+	cv.dc.DrawStringAnchored(text, float64(x), float64(y), anchorRatio(anchor), 0)
+}
+
+// Returns the part of the width of a text that goes before the anchor point
+func anchorRatio(anchor string) float64 {
+	switch anchor {
+	case "middle":
+		return 0.5
+	case "end":
+		return 1
+	}
+	return 0
+}
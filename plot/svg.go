@@ -0,0 +1,76 @@
+package plot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+
+	svg "github.com/ajstarks/svgo"
+)
+
+// svgCanvas writes the elements of an SVG document through svgo
+type svgCanvas struct {
+	doc *svg.SVG
+}
+
+// RenderSVG draws the chart as an SVG document
+func (c Chart) RenderSVG(w io.Writer) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	cv := &svgCanvas{svg.New(&buf)}
+	cv.doc.Start(c.Width, c.Height, fmt.Sprintf(`viewBox="0 0 %d %d"`, c.Width, c.Height), `font-family="Go, sans-serif"`, fmt.Sprintf(`font-size="%d"`, fontSize))
+	c.draw(cv)
+	cv.doc.End()
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func (cv *svgCanvas) fillRect(r image.Rectangle, c color.NRGBA) {
+	cv.doc.Rect(r.Min.X, r.Min.Y, r.Dx(), r.Dy(), svgPaint("fill", c)...)
+}
+
+// Draws a polyline per run of points that are not missing
+func (cv *svgCanvas) polyline(points []point, c color.NRGBA, width int) {
+	for _, run := range splitRuns(points) {
+		if len(run) < 2 {
+			continue
+		}
+		xs, ys := svgCoords(run)
+		attributes := append([]string{`class="series"`, `fill="none"`}, svgPaint("stroke", c)...)
+		cv.doc.Polyline(xs, ys, append(attributes, fmt.Sprintf(`stroke-width="%d"`, width), `stroke-linejoin="round"`)...)
+	}
+}
+
+// Draws a polygon per run of points that are not missing, from the top to the base in reverse order
+func (cv *svgCanvas) area(top []point, base []point, c color.NRGBA) {
+	for _, run := range areaRuns(top, base) {
+		xs, ys := svgCoords(run)
+		cv.doc.Polygon(xs, ys, append([]string{`class="series"`}, svgPaint("fill", c)...)...)
+	}
+}
+
+func (cv *svgCanvas) text(x int, y int, text string, c color.NRGBA, anchor string) {
+	cv.doc.Text(x, y, text, append([]string{fmt.Sprintf(`text-anchor="%s"`, anchor)}, svgPaint("fill", c)...)...)
+}
+
+func svgCoords(points []point) ([]int, []int) {
+	xs := make([]int, len(points))
+	ys := make([]int, len(points))
+	for i, p := range points {
+		xs[i], ys[i] = int(math.Round(p.x)), int(math.Round(p.y))
+	}
+	return xs, ys
+}
+
+func svgPaint(attribute string, c color.NRGBA) []string {
+	paint := []string{fmt.Sprintf(`%s="#%02x%02x%02x"`, attribute, c.R, c.G, c.B)}
+	if c.A != 0xff {
+		paint = append(paint, fmt.Sprintf(`%s-opacity="%.2f"`, attribute, float64(c.A)/0xff))
+	}
+	return paint
+}
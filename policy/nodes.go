@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The attributes of the entities that the policies can match, named like the parameters of the policies on OpenNMS
+var (
+	nodeAttributes          = []string{"foreignSource", "foreignId", "label", "location", "sysObjectId", "sysName", "sysDescription", "sysContact", "sysLocation", "operatingSystem", "netBiosName", "netBiosDomain"}
+	ipInterfaceAttributes   = []string{"ipAddress", "hostName"}
+	snmpInterfaceAttributes = []string{"ifIndex", "ifName", "ifDescr", "ifAlias", "ifType", "ifSpeed", "physAddr", "ifAdminStatus", "ifOperStatus"}
+)
+
+// Node the attributes of a node as they would be discovered during a scan
+type Node struct {
+	ForeignID       string          `json:"foreignId" yaml:"foreignId"`
+	Label           string          `json:"label,omitempty" yaml:"label,omitempty"`
+	Location        string          `json:"location,omitempty" yaml:"location,omitempty"`
+	SysObjectID     string          `json:"sysObjectId,omitempty" yaml:"sysObjectId,omitempty"`
+	SysName         string          `json:"sysName,omitempty" yaml:"sysName,omitempty"`
+	SysDescription  string          `json:"sysDescription,omitempty" yaml:"sysDescription,omitempty"`
+	SysContact      string          `json:"sysContact,omitempty" yaml:"sysContact,omitempty"`
+	SysLocation     string          `json:"sysLocation,omitempty" yaml:"sysLocation,omitempty"`
+	OperatingSystem string          `json:"operatingSystem,omitempty" yaml:"operatingSystem,omitempty"`
+	NetBiosName     string          `json:"netBiosName,omitempty" yaml:"netBiosName,omitempty"`
+	NetBiosDomain   string          `json:"netBiosDomain,omitempty" yaml:"netBiosDomain,omitempty"`
+	Interfaces      []IPInterface   `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
+	SnmpInterfaces  []SnmpInterface `json:"snmpInterfaces,omitempty" yaml:"snmpInterfaces,omitempty"`
+}
+
+// Validate returns an error if the node is invalid
+func (n Node) Validate() error {
+	if n.ForeignID == "" {
+		return fmt.Errorf("Foreign ID cannot be empty")
+	}
+	for _, intf := range n.Interfaces {
+		if intf.IPAddress == "" {
+			return fmt.Errorf("IP Address cannot be empty on the interfaces of node %s", n.ForeignID)
+		}
+	}
+	return nil
+}
+
+func (n Node) attributes(foreignSource string) map[string]string {
+	return map[string]string{
+		"foreignSource":   foreignSource,
+		"foreignId":       n.ForeignID,
+		"label":           n.Label,
+		"location":        n.Location,
+		"sysObjectId":     n.SysObjectID,
+		"sysName":         n.SysName,
+		"sysDescription":  n.SysDescription,
+		"sysContact":      n.SysContact,
+		"sysLocation":     n.SysLocation,
+		"operatingSystem": n.OperatingSystem,
+		"netBiosName":     n.NetBiosName,
+		"netBiosDomain":   n.NetBiosDomain,
+	}
+}
+
+// NodeList the content of a file with the nodes to simulate
+type NodeList struct {
+	Nodes []Node `json:"nodes" yaml:"nodes"`
+}
+
+// Validate returns an error if a node is invalid
+func (list NodeList) Validate() error {
+	if len(list.Nodes) == 0 {
+		return fmt.Errorf("There are no nodes to simulate")
+	}
+	for _, n := range list.Nodes {
+		if err := n.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IPInterface the attributes of an IP interface
+type IPInterface struct {
+	IPAddress string `json:"ipAddress" yaml:"ipAddress"`
+	HostName  string `json:"hostName,omitempty" yaml:"hostName,omitempty"`
+}
+
+func (intf IPInterface) attributes() map[string]string {
+	return map[string]string{
+		"ipAddress": intf.IPAddress,
+		"hostName":  intf.HostName,
+	}
+}
+
+// SnmpInterface the attributes of an SNMP interface, as found on the ifTable and ifXTable
+type SnmpInterface struct {
+	IfIndex       int    `json:"ifIndex" yaml:"ifIndex"`
+	IfName        string `json:"ifName,omitempty" yaml:"ifName,omitempty"`
+	IfDescr       string `json:"ifDescr,omitempty" yaml:"ifDescr,omitempty"`
+	IfAlias       string `json:"ifAlias,omitempty" yaml:"ifAlias,omitempty"`
+	IfType        int    `json:"ifType,omitempty" yaml:"ifType,omitempty"`
+	IfSpeed       int64  `json:"ifSpeed,omitempty" yaml:"ifSpeed,omitempty"`
+	PhysAddr      string `json:"physAddr,omitempty" yaml:"physAddr,omitempty"`
+	IfAdminStatus int    `json:"ifAdminStatus,omitempty" yaml:"ifAdminStatus,omitempty"`
+	IfOperStatus  int    `json:"ifOperStatus,omitempty" yaml:"ifOperStatus,omitempty"`
+}
+
+func (intf SnmpInterface) attributes() map[string]string {
+	attributes := map[string]string{
+		"ifIndex":  strconv.Itoa(intf.IfIndex),
+		"ifName":   intf.IfName,
+		"ifDescr":  intf.IfDescr,
+		"ifAlias":  intf.IfAlias,
+		"physAddr": intf.PhysAddr,
+	}
+	// Unknown numeric attributes are treated as missing, like null values on OpenNMS
+	if intf.IfType > 0 {
+		attributes["ifType"] = strconv.Itoa(intf.IfType)
+	}
+	if intf.IfSpeed > 0 {
+		attributes["ifSpeed"] = strconv.FormatInt(intf.IfSpeed, 10)
+	}
+	if intf.IfAdminStatus > 0 {
+		attributes["ifAdminStatus"] = strconv.Itoa(intf.IfAdminStatus)
+	}
+	if intf.IfOperStatus > 0 {
+		attributes["ifOperStatus"] = strconv.Itoa(intf.IfOperStatus)
+	}
+	return attributes
+}
+
+func (intf SnmpInterface) target() string {
+	if intf.IfName == "" {
+		return fmt.Sprintf("ifIndex %d", intf.IfIndex)
+	}
+	return fmt.Sprintf("ifIndex %d (%s)", intf.IfIndex, intf.IfName)
+}
@@ -0,0 +1,229 @@
+// Package policy evaluates the provisioning policies of a foreign source definition on the client side,
+// to preview their effect on a set of nodes before the requisition is imported.
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+// The classes of the policies that can be simulated
+const (
+	NodeCategorySettingPolicy   = "org.opennms.netmgt.provision.persist.policies.NodeCategorySettingPolicy"
+	MatchingIPInterfacePolicy   = "org.opennms.netmgt.provision.persist.policies.MatchingIpInterfacePolicy"
+	MatchingSnmpInterfacePolicy = "org.opennms.netmgt.provision.persist.policies.MatchingSnmpInterfacePolicy"
+)
+
+// The behaviors that decide how the criteria of a policy are combined
+const (
+	MatchAll  = "ALL_PARAMETERS"
+	MatchAny  = "ANY_PARAMETER"
+	MatchNone = "NO_PARAMETERS"
+)
+
+// MatchBehaviors the valid values of the matchBehavior parameter
+var MatchBehaviors = []string{MatchAll, MatchAny, MatchNone}
+
+// DoNotPersist the default action of the interface policies, which discards the interface
+const DoNotPersist = "DO_NOT_PERSIST"
+
+// IPInterfaceActions the valid actions of a MatchingIpInterfacePolicy
+var IPInterfaceActions = []string{DoNotPersist, "MANAGE", "UNMANAGE"}
+
+// SnmpInterfaceActions the valid actions of a MatchingSnmpInterfacePolicy
+var SnmpInterfaceActions = []string{DoNotPersist, "ENABLE_POLLING", "DISABLE_POLLING", "ENABLE_COLLECTION", "DISABLE_COLLECTION"}
+
+// The parameters that configure a policy, instead of being matched against the entities
+var settings = map[string]bool{"matchBehavior": true, "action": true, "category": true}
+
+// Decision the effect of a policy on a node or one of its interfaces
+type Decision struct {
+	ForeignID string `json:"foreignId" yaml:"foreignId"`
+	Target    string `json:"target" yaml:"target"` // node, the IP address of an IP interface, or ifIndex:ifName of an SNMP interface
+	Policy    string `json:"policy,omitempty" yaml:"policy,omitempty"`
+	Decision  string `json:"decision" yaml:"decision"`
+}
+
+// Skipped a policy that cannot be evaluated on the client side
+type Skipped struct {
+	Policy string `json:"policy" yaml:"policy"`
+	Class  string `json:"class" yaml:"class"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// Simulation the result of evaluating the policies of a foreign source on a set of nodes
+type Simulation struct {
+	Decisions    []Decision `json:"decisions" yaml:"decisions"`
+	NotSimulated []Skipped  `json:"notSimulated,omitempty" yaml:"notSimulated,omitempty"`
+}
+
+// A policy ready to be evaluated
+type rule struct {
+	policy   model.Policy
+	behavior string
+	action   string
+	category string
+	criteria map[string]*regexp.Regexp
+	values   map[string]string
+}
+
+// Returns true when the attributes of an entity satisfy the criteria of the policy; like on OpenNMS,
+// values starting with ~ are regular expressions that must match the whole attribute, otherwise the attribute must be equal
+func (r rule) matches(attributes map[string]string) bool {
+	matched := 0
+	for key, expected := range r.values {
+		actual, ok := attributes[key]
+		if !ok || actual == "" {
+			continue
+		}
+		if pattern := r.criteria[key]; pattern != nil {
+			if pattern.MatchString(actual) {
+				matched++
+			}
+		} else if actual == expected {
+			matched++
+		}
+	}
+	switch r.behavior {
+	case MatchAny:
+		return matched > 0
+	case MatchNone:
+		return matched == 0
+	}
+	return matched == len(r.values)
+}
+
+// Simulate evaluates the supported policies of a foreign source definition, in order, on the given nodes;
+// the policies of other classes, or with parameters that can't be evaluated, are reported as not simulated
+func Simulate(fsDef model.ForeignSourceDef, nodes []Node) (*Simulation, error) {
+	simulation := &Simulation{Decisions: make([]Decision, 0)}
+	rules := make(map[string][]rule)
+	for _, p := range fsDef.Policies {
+		r, reason, err := compile(p)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			simulation.NotSimulated = append(simulation.NotSimulated, Skipped{Policy: p.Name, Class: p.Class, Reason: reason})
+			continue
+		}
+		rules[p.Class] = append(rules[p.Class], *r)
+	}
+	for _, node := range nodes {
+		simulation.Decisions = append(simulation.Decisions, simulateNode(fsDef.Name, node, rules)...)
+	}
+	return simulation, nil
+}
+
+func simulateNode(foreignSource string, node Node, rules map[string][]rule) []Decision {
+	decisions := make([]Decision, 0)
+	add := func(target string, r rule, decision string) {
+		decisions = append(decisions, Decision{ForeignID: node.ForeignID, Target: target, Policy: r.policy.Name, Decision: decision})
+	}
+	attributes := node.attributes(foreignSource)
+	for _, r := range rules[NodeCategorySettingPolicy] {
+		if r.matches(attributes) {
+			add("node", r, "category "+r.category)
+		}
+	}
+	for _, intf := range node.Interfaces {
+		for _, r := range rules[MatchingIPInterfacePolicy] {
+			if r.matches(intf.attributes()) {
+				add(intf.IPAddress, r, r.action)
+				if r.action == DoNotPersist {
+					break
+				}
+			}
+		}
+	}
+	for _, intf := range node.SnmpInterfaces {
+		for _, r := range rules[MatchingSnmpInterfacePolicy] {
+			if r.matches(intf.attributes()) {
+				add(intf.target(), r, r.action)
+				if r.action == DoNotPersist {
+					break
+				}
+			}
+		}
+	}
+	if len(decisions) == 0 {
+		decisions = append(decisions, Decision{ForeignID: node.ForeignID, Target: "node", Decision: "no changes"})
+	}
+	return decisions
+}
+
+// Prepares a policy to be evaluated; returns the reason why it can't be simulated,
+// or an error when its parameters are invalid
+func compile(p model.Policy) (*rule, string, error) {
+	var attributes []string
+	var actions []string
+	switch p.Class {
+	case NodeCategorySettingPolicy:
+		attributes = nodeAttributes
+		if i := indexOf(p.Parameters, "category"); i < 0 || p.Parameters[i].Value == "" {
+			return nil, "", fmt.Errorf("Policy %s requires the category parameter", p.Name)
+		}
+	case MatchingIPInterfacePolicy:
+		attributes = ipInterfaceAttributes
+		actions = IPInterfaceActions
+	case MatchingSnmpInterfacePolicy:
+		attributes = snmpInterfaceAttributes
+		actions = SnmpInterfaceActions
+	default:
+		return nil, "unsupported class", nil
+	}
+	r := &rule{policy: p, behavior: MatchAll, criteria: make(map[string]*regexp.Regexp), values: make(map[string]string)}
+	if actions != nil {
+		r.action = DoNotPersist
+	}
+	for _, param := range p.Parameters {
+		switch {
+		case param.Key == "matchBehavior":
+			if !contains(MatchBehaviors, param.Value) {
+				return nil, "", fmt.Errorf("Invalid matchBehavior %s on policy %s, valid options: %s", param.Value, p.Name, strings.Join(MatchBehaviors, ", "))
+			}
+			r.behavior = param.Value
+		case param.Key == "category":
+			r.category = param.Value
+		case param.Key == "action" && actions != nil:
+			if !contains(actions, param.Value) {
+				return nil, "", fmt.Errorf("Invalid action %s on policy %s, valid options: %s", param.Value, p.Name, strings.Join(actions, ", "))
+			}
+			r.action = param.Value
+		case settings[param.Key]:
+		case !contains(attributes, param.Key):
+			return nil, fmt.Sprintf("parameter %s cannot be evaluated", param.Key), nil
+		default:
+			r.values[param.Key] = param.Value
+			if strings.HasPrefix(param.Value, "~") {
+				pattern, err := regexp.Compile("^(?:" + param.Value[1:] + ")$")
+				if err != nil {
+					return nil, "", fmt.Errorf("Invalid expression %s on parameter %s of policy %s: %w", param.Value[1:], param.Key, p.Name, err)
+				}
+				r.criteria[param.Key] = pattern
+			}
+		}
+	}
+	return r, "", nil
+}
+
+func indexOf(parameters []model.Parameter, key string) int {
+	for i, p := range parameters {
+		if p.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+func params(pairs ...string) []model.Parameter {
+	parameters := make([]model.Parameter, 0)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parameters = append(parameters, model.Parameter{Key: pairs[i], Value: pairs[i+1]})
+	}
+	return parameters
+}
+
+func simulate(t *testing.T, policies []model.Policy, node Node) []string {
+	simulation, err := Simulate(model.ForeignSourceDef{Name: "Servers", Policies: policies}, []Node{node})
+	assert.NilError(t, err)
+	decisions := make([]string, 0)
+	for _, d := range simulation.Decisions {
+		decisions = append(decisions, d.Target+": "+d.Decision)
+	}
+	return decisions
+}
+
+func TestNodeCategorySettingPolicy(t *testing.T) {
+	node := Node{ForeignID: "srv01", Label: "srv01.example.com", SysObjectID: ".1.3.6.1.4.1.8072.3.2.10", SysDescription: "Linux srv01 5.4.0"}
+	testCases := []struct {
+		name       string
+		parameters []model.Parameter
+		expected   []string
+	}{
+		{"exact value", params("category", "Linux", "sysObjectId", ".1.3.6.1.4.1.8072.3.2.10"), []string{"node: category Linux"}},
+		{"exact value mismatch", params("category", "Linux", "sysObjectId", ".1.3.6.1.4.1.8072"), []string{"node: no changes"}},
+		{"regular expression", params("category", "Linux", "sysObjectId", "~\\.1\\.3\\.6\\.1\\.4\\.1\\.8072\\..*"), []string{"node: category Linux"}},
+		{"regular expression matches the whole value", params("category", "Linux", "sysDescription", "~Linux"), []string{"node: no changes"}},
+		{"all parameters", params("category", "Linux", "sysDescription", "~Linux.*", "label", "~.*\\.example\\.org"), []string{"node: no changes"}},
+		{"any parameter", params("category", "Linux", "matchBehavior", "ANY_PARAMETER", "sysDescription", "~Linux.*", "label", "~.*\\.example\\.org"), []string{"node: category Linux"}},
+		{"no parameters", params("category", "Other", "matchBehavior", "NO_PARAMETERS", "sysDescription", "~Windows.*"), []string{"node: category Other"}},
+		{"missing attribute", params("category", "Linux", "sysContact", "~.*"), []string{"node: no changes"}},
+		{"foreign source", params("category", "Production", "foreignSource", "Servers"), []string{"node: category Production"}},
+		{"without criteria", params("category", "All"), []string{"node: category All"}},
+	}
+	for _, tc := range testCases {
+		policy := model.Policy{Name: "p1", Class: NodeCategorySettingPolicy, Parameters: tc.parameters}
+		assert.DeepEqual(t, tc.expected, simulate(t, []model.Policy{policy}, node))
+	}
+}
+
+func TestMatchingIPInterfacePolicy(t *testing.T) {
+	node := Node{ForeignID: "srv01", Interfaces: []IPInterface{
+		{IPAddress: "10.0.0.1", HostName: "srv01.example.com"},
+		{IPAddress: "192.168.1.1", HostName: "srv01-mgmt.example.com"},
+	}}
+	testCases := []struct {
+		name     string
+		policies []model.Policy
+		expected []string
+	}{
+		{"default action", []model.Policy{
+			{Name: "p1", Class: MatchingIPInterfacePolicy, Parameters: params("ipAddress", "~192\\.168\\..*")},
+		}, []string{"192.168.1.1: DO_NOT_PERSIST"}},
+		{"manage", []model.Policy{
+			{Name: "p1", Class: MatchingIPInterfacePolicy, Parameters: params("action", "MANAGE", "hostName", "~.*-mgmt\\..*")},
+		}, []string{"192.168.1.1: MANAGE"}},
+		{"unmanage the rest", []model.Policy{
+			{Name: "p1", Class: MatchingIPInterfacePolicy, Parameters: params("action", "UNMANAGE", "matchBehavior", "NO_PARAMETERS", "ipAddress", "10.0.0.1")},
+		}, []string{"192.168.1.1: UNMANAGE"}},
+		{"discarded interfaces are not evaluated by later policies", []model.Policy{
+			{Name: "p1", Class: MatchingIPInterfacePolicy, Parameters: params("action", "DO_NOT_PERSIST", "ipAddress", "~192\\..*")},
+			{Name: "p2", Class: MatchingIPInterfacePolicy, Parameters: params("action", "MANAGE")},
+		}, []string{"10.0.0.1: MANAGE", "192.168.1.1: DO_NOT_PERSIST"}},
+	}
+	for _, tc := range testCases {
+		assert.DeepEqual(t, tc.expected, simulate(t, tc.policies, node))
+	}
+}
+
+func TestMatchingSnmpInterfacePolicy(t *testing.T) {
+	node := Node{ForeignID: "sw01", SnmpInterfaces: []SnmpInterface{
+		{IfIndex: 1, IfName: "lo", IfType: 24},
+		{IfIndex: 2, IfName: "Gi0/1", IfAlias: "Uplink to core", IfType: 6, IfSpeed: 1000000000, IfOperStatus: 1},
+		{IfIndex: 3, IfName: "Gi0/2", IfType: 6, IfOperStatus: 2},
+	}}
+	testCases := []struct {
+		name       string
+		parameters []model.Parameter
+		expected   []string
+	}{
+		{"default action", params("ifType", "24"), []string{"ifIndex 1 (lo): DO_NOT_PERSIST"}},
+		{"enable polling", params("action", "ENABLE_POLLING", "ifAlias", "~.*[Uu]plink.*"), []string{"ifIndex 2 (Gi0/1): ENABLE_POLLING"}},
+		{"disable collection", params("action", "DISABLE_COLLECTION", "ifOperStatus", "2"), []string{"ifIndex 3 (Gi0/2): DISABLE_COLLECTION"}},
+		{"any parameter", params("action", "ENABLE_COLLECTION", "matchBehavior", "ANY_PARAMETER", "ifSpeed", "1000000000", "ifIndex", "3"), []string{"ifIndex 2 (Gi0/1): ENABLE_COLLECTION", "ifIndex 3 (Gi0/2): ENABLE_COLLECTION"}},
+	}
+	for _, tc := range testCases {
+		policy := model.Policy{Name: "p1", Class: MatchingSnmpInterfacePolicy, Parameters: tc.parameters}
+		assert.DeepEqual(t, tc.expected, simulate(t, []model.Policy{policy}, node))
+	}
+}
+
+func TestNotSimulated(t *testing.T) {
+	fsDef := model.ForeignSourceDef{Name: "Servers", Policies: []model.Policy{
+		{Name: "metadata", Class: "org.opennms.netmgt.provision.persist.policies.NodeMetadataSettingPolicy"},
+		{Name: "parent", Class: NodeCategorySettingPolicy, Parameters: params("category", "Child", "parentForeignId", "srv00")},
+		{Name: "linux", Class: NodeCategorySettingPolicy, Parameters: params("category", "Linux")},
+	}}
+	simulation, err := Simulate(fsDef, []Node{{ForeignID: "srv01"}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []Decision{{ForeignID: "srv01", Target: "node", Policy: "linux", Decision: "category Linux"}}, simulation.Decisions)
+	assert.DeepEqual(t, []Skipped{
+		{Policy: "metadata", Class: "org.opennms.netmgt.provision.persist.policies.NodeMetadataSettingPolicy", Reason: "unsupported class"},
+		{Policy: "parent", Class: NodeCategorySettingPolicy, Reason: "parameter parentForeignId cannot be evaluated"},
+	}, simulation.NotSimulated)
+}
+
+func TestInvalidPolicies(t *testing.T) {
+	testCases := []struct {
+		policy   model.Policy
+		expected string
+	}{
+		{model.Policy{Name: "p1", Class: NodeCategorySettingPolicy, Parameters: params("sysName", "srv01")}, "Policy p1 requires the category parameter"},
+		{model.Policy{Name: "p1", Class: NodeCategorySettingPolicy, Parameters: params("category", "Linux", "matchBehavior", "ALL")}, "Invalid matchBehavior ALL on policy p1, valid options: ALL_PARAMETERS, ANY_PARAMETER, NO_PARAMETERS"},
+		{model.Policy{Name: "p1", Class: MatchingIPInterfacePolicy, Parameters: params("action", "ENABLE_POLLING")}, "Invalid action ENABLE_POLLING on policy p1, valid options: DO_NOT_PERSIST, MANAGE, UNMANAGE"},
+		{model.Policy{Name: "p1", Class: MatchingSnmpInterfacePolicy, Parameters: params("ifName", "~Gi[0")}, "Invalid expression Gi[0 on parameter ifName of policy p1: error parsing regexp: missing closing ]: `[0)$`"},
+	}
+	for _, tc := range testCases {
+		_, err := Simulate(model.ForeignSourceDef{Name: "Test", Policies: []model.Policy{tc.policy}}, []Node{{ForeignID: "n1"}})
+		assert.Error(t, err, tc.expected)
+	}
+}
+
+func TestValidateNodes(t *testing.T) {
+	assert.Error(t, NodeList{}.Validate(), "There are no nodes to simulate")
+	assert.Error(t, NodeList{Nodes: []Node{{Label: "srv01"}}}.Validate(), "Foreign ID cannot be empty")
+	assert.Error(t, NodeList{Nodes: []Node{{ForeignID: "srv01", Interfaces: []IPInterface{{HostName: "srv01"}}}}}.Validate(), "IP Address cannot be empty on the interfaces of node srv01")
+	assert.NilError(t, NodeList{Nodes: []Node{{ForeignID: "srv01"}}}.Validate())
+}
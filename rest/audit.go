@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The fields of a request body that identify the resource being changed; only these are written to the audit log,
+// so the content (which might include credentials, e.x. on SNMP configuration) never is
+var auditedBodyFields = map[string]string{
+	"foreign-source": "foreignSource",
+	"foreign-id":     "foreignId",
+	"node-label":     "nodeLabel",
+	"ip-addr":        "ipAddress",
+	"service-name":   "service",
+	"name":           "name",
+	"uei":            "uei",
+	"location-name":  "location",
+	"user-id":        "user",
+}
+
+// AuditRecord a line of the audit log, describing a request that modified the server
+type AuditRecord struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Profile   string            `json:"profile,omitempty"`
+	User      string            `json:"user"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Resource  map[string]string `json:"resource,omitempty"`
+	Status    int               `json:"status,omitempty"` // The HTTP status of the response, or 0 when there was no response
+	Outcome   string            `json:"outcome"`          // success or failure
+	Error     string            `json:"error,omitempty"`
+}
+
+// The time of the audit records (replaced on tests)
+var auditNow = time.Now
+
+var auditLock sync.Mutex
+
+// Appends a record to the audit log, when enabled, for a request that modifies the server
+func (cli Client) audit(method string, path string, dataBytes []byte, status int, err error) {
+	if cli.AuditLog == "" {
+		return
+	}
+	record := AuditRecord{
+		Timestamp: auditNow().UTC(),
+		Profile:   cli.ProfileName,
+		User:      cli.Username,
+		Method:    method,
+		URL:       cli.auditURL(path),
+		Resource:  describeResource(path, dataBytes),
+		Status:    status,
+		Outcome:   "success",
+	}
+	if err != nil {
+		record.Outcome = "failure"
+		record.Error = RedactContent(err.Error())
+	}
+	line, e := json.Marshal(record)
+	if e != nil {
+		return
+	}
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	f, e := os.OpenFile(cli.AuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if e == nil {
+		_, e = f.Write(append(line, '\n'))
+		if closeErr := f.Close(); e == nil {
+			e = closeErr
+		}
+	}
+	if e != nil {
+		warnOnce(cli.auditWarning, func() {
+			cli.logger().Warnf("Cannot write the audit log %s: %s; the changes are not being recorded", cli.AuditLog, e)
+		})
+	}
+}
+
+// Returns the URL of the request without credentials
+func (cli Client) auditURL(path string) string {
+	u, err := url.Parse(cli.URL + path)
+	if err != nil {
+		return RedactContent(path)
+	}
+	u.User = nil
+	return redactURL(u)
+}
+
+// Summarizes the resource of a request from its path (e.x. requisitions=Test and nodes=n1 for /rest/requisitions/Test/nodes/n1),
+// and from the fields of its JSON body that identify it (e.x. the foreign ID of a node added to a requisition)
+func describeResource(path string, dataBytes []byte) map[string]string {
+	resource := make(map[string]string)
+	segments := strings.Split(strings.Trim(resourcePath(path), "/"), "/")
+	switch {
+	case len(segments) >= 2 && segments[0] == "api" && strings.HasPrefix(segments[1], "v"):
+		segments = segments[2:]
+	case len(segments) >= 1 && segments[0] == "rest":
+		segments = segments[1:]
+	}
+	for i := 0; i+1 < len(segments); i += 2 {
+		if value, err := url.PathUnescape(segments[i+1]); err == nil {
+			resource[segments[i]] = value
+		}
+	}
+	body := make(map[string]interface{})
+	if json.Unmarshal(dataBytes, &body) == nil {
+		for field, key := range auditedBodyFields {
+			if value, ok := body[field].(string); ok && value != "" {
+				resource[key] = value
+			}
+		}
+	}
+	if len(resource) == 0 {
+		return nil
+	}
+	return resource
+}
@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func readAuditLog(t *testing.T, path string) []string {
+	data, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestAuditLog(t *testing.T) {
+	auditNow = func() time.Time { return time.Date(2020, 3, 1, 10, 30, 0, 0, time.UTC) }
+	defer func() { auditNow = time.Now }()
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/rest/requisitions/Missing" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	dir, err := ioutil.TempDir("", "onmsctl-audit")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	auditLog := filepath.Join(dir, "audit.jsonl")
+
+	url := strings.Replace(testServer.URL, "http://", "http://admin:adm1nS3cret@", 1)
+	client := Client{URL: url, Username: "admin", Password: "adm1nS3cret", Timeout: 5, AuditLog: auditLog, ProfileName: "lab"}
+	_, err = client.Get("/rest/requisitions/Test")
+	assert.NilError(t, err)
+	_, err = os.Stat(auditLog)
+	assert.Assert(t, os.IsNotExist(err), "Reads are not audited")
+
+	node := `{"foreign-id":"n1","node-label":"srv01","asset":[{"name":"password","value":"s3cr3t"}],"community":"private"}`
+	assert.NilError(t, client.Post("/rest/requisitions/Test/nodes", []byte(node)))
+	assert.NilError(t, client.Put("/rest/snmpConfig/10.0.0.1", []byte(`{"version":"v2c","community":"private"}`), "application/json"))
+	assert.NilError(t, client.Delete("/rest/users/agalue?password=abc123"))
+	assert.ErrorContains(t, client.Delete("/rest/requisitions/Missing"), "404")
+	assert.NilError(t, client.Put("/api/v2/nodes/Test:n1/categories/Servers", nil, "application/x-www-form-urlencoded"))
+
+	lines := readAuditLog(t, auditLog)
+	assert.Equal(t, 5, len(lines))
+	assert.Equal(t, `{"timestamp":"2020-03-01T10:30:00Z","profile":"lab","user":"admin","method":"POST","url":"`+testServer.URL+`/rest/requisitions/Test/nodes","resource":{"foreignId":"n1","nodeLabel":"srv01","requisitions":"Test"},"status":200,"outcome":"success"}`, lines[0])
+	for _, line := range lines {
+		record := AuditRecord{}
+		assert.NilError(t, json.Unmarshal([]byte(line), &record), line)
+		for _, secret := range []string{"adm1nS3cret", "s3cr3t", "private", "public", "abc123"} {
+			assert.Assert(t, !strings.Contains(line, secret), line)
+		}
+	}
+
+	record := AuditRecord{}
+	assert.NilError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "PUT", record.Method)
+	assert.DeepEqual(t, map[string]string{"snmpConfig": "10.0.0.1"}, record.Resource)
+
+	record = AuditRecord{}
+	assert.NilError(t, json.Unmarshal([]byte(lines[2]), &record))
+	assert.Equal(t, testServer.URL+"/rest/users/agalue?password="+redacted, record.URL)
+	assert.DeepEqual(t, map[string]string{"users": "agalue"}, record.Resource)
+
+	record = AuditRecord{}
+	assert.NilError(t, json.Unmarshal([]byte(lines[3]), &record))
+	assert.Equal(t, "failure", record.Outcome)
+	assert.Equal(t, http.StatusNotFound, record.Status)
+	assert.Equal(t, "Invalid Response: 404 Not Found", record.Error)
+
+	record = AuditRecord{}
+	assert.NilError(t, json.Unmarshal([]byte(lines[4]), &record))
+	assert.DeepEqual(t, map[string]string{"nodes": "Test:n1", "categories": "Servers"}, record.Resource)
+}
+
+func TestAuditLogUnwritable(t *testing.T) {
+	log := &testLogger{}
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	client, err := NewClient(WithSettings(Client{URL: testServer.URL, Timeout: 5, AuditLog: "/nonexistent/onmsctl/audit.jsonl"}), WithLogger(log))
+	assert.NilError(t, err)
+	for i := 0; i < 3; i++ {
+		assert.NilError(t, client.Post("/rest/requisitions", []byte(`{"foreign-source":"Test"}`)))
+	}
+	assert.Equal(t, 1, len(log.warnings), strings.Join(log.warnings, "\n"))
+	assert.Assert(t, strings.HasPrefix(log.warnings[0], "Cannot write the audit log /nonexistent/onmsctl/audit.jsonl: "), log.warnings[0])
+}
+
+func TestAuditProfileName(t *testing.T) {
+	cfg := Config{Client: Client{URL: "http://localhost:8980/opennms"}, Profiles: map[string]Client{"lab": {URL: "http://lab:8980/opennms"}}}
+	client, err := cfg.GetProfile("")
+	assert.NilError(t, err)
+	assert.Equal(t, DefaultProfile, client.ProfileName)
+	client, err = cfg.GetProfile("lab")
+	assert.NilError(t, err)
+	assert.Equal(t, "lab", client.ProfileName)
+	cfg.Profile = "lab"
+	client, err = cfg.GetProfile("")
+	assert.NilError(t, err)
+	assert.Equal(t, "lab", client.ProfileName)
+}
@@ -0,0 +1,197 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL the default time in seconds the cacheable GET responses are kept
+const DefaultCacheTTL = 30
+
+var cacheLock sync.Mutex
+
+// cacheablePath a path prefix whose GET responses can be cached, and how long (0 means the TTL of the client)
+type cacheablePath struct {
+	prefix string
+	ttl    time.Duration
+}
+
+// The path prefixes whose GET responses can be cached, registered by the services
+var cacheablePaths = make([]cacheablePath, 0)
+
+// When each resource path was last modified by this process, to avoid storing responses obtained before the change
+var cacheWrites = make(map[string]time.Time)
+
+// DefaultCacheDir returns the default location of the cache (e.x. ~/.cache/onmsctl/http)
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "onmsctl", "http")
+}
+
+// RegisterCacheable allows caching the GET responses of the paths that start with any of the given prefixes
+func RegisterCacheable(prefixes ...string) {
+	RegisterCacheableFor(0, prefixes...)
+}
+
+// RegisterCacheableFor allows caching the GET responses of the paths that start with any of the given prefixes for a fixed time,
+// regardless of the TTL of the client (e.x. metadata that only changes when the server is upgraded)
+func RegisterCacheableFor(ttl time.Duration, prefixes ...string) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	for _, prefix := range prefixes {
+		cacheablePaths = append(cacheablePaths, cacheablePath{prefix, ttl})
+	}
+}
+
+func isCacheable(path string) bool {
+	_, ok := getCacheablePath(path)
+	return ok
+}
+
+// Returns the registered prefix that matches the path, the longest one when many of them do
+func getCacheablePath(path string) (cacheablePath, bool) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	found, ok := cacheablePath{}, false
+	for _, c := range cacheablePaths {
+		if isSameResource(c.prefix, path) && len(resourcePath(path)) >= len(c.prefix) && len(c.prefix) >= len(found.prefix) {
+			found, ok = c, true
+		}
+	}
+	return found, ok
+}
+
+// Returns the directory with the cached responses of the server and user of the client
+func (cli Client) cacheDir() string {
+	if cli.cacheRoot == "" || cli.CacheTTL < 0 {
+		return ""
+	}
+	hash := sha1.Sum([]byte(cli.URL + "|" + cli.Username))
+	return filepath.Join(cli.cacheRoot, hex.EncodeToString(hash[:8]))
+}
+
+func (cli Client) cacheTTL() time.Duration {
+	if cli.CacheTTL == 0 {
+		return DefaultCacheTTL * time.Second
+	}
+	return time.Duration(cli.CacheTTL) * time.Second
+}
+
+// Returns the cached response of a GET request, if it is cacheable and hasn't expired
+func (cli Client) getCached(path string) ([]byte, bool) {
+	dir := cli.cacheDir()
+	cacheable, ok := getCacheablePath(path)
+	if dir == "" || !ok {
+		return nil, false
+	}
+	ttl := cacheable.ttl
+	if ttl == 0 {
+		ttl = cli.cacheTTL()
+	}
+	file := cacheFile(dir, path)
+	info, err := os.Stat(file)
+	if err != nil || time.Since(info.ModTime()) > ttl {
+		return nil, false
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	// The first line is the path, used to find the entries to invalidate
+	parts := bytes.SplitN(data, []byte("\n"), 2)
+	if len(parts) != 2 || string(parts[0]) != path {
+		return nil, false
+	}
+	return parts[1], true
+}
+
+// Stores the response of a GET request sent at the given time, unless the resource was modified after that
+func (cli Client) storeCached(path string, sent time.Time, data []byte) {
+	dir := cli.cacheDir()
+	if dir == "" || !isCacheable(path) {
+		return
+	}
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	for written, when := range cacheWrites {
+		if isSameResource(written, path) && !when.Before(sent) {
+			return
+		}
+	}
+	if os.MkdirAll(dir, 0700) != nil {
+		return
+	}
+	content := append([]byte(path+"\n"), data...)
+	ioutil.WriteFile(cacheFile(dir, path), content, 0600)
+}
+
+// Removes the cached responses affected by a change on the given path (the path itself, its parents and its children)
+func (cli Client) invalidateCached(path string) {
+	if cli.cacheRoot == "" {
+		return
+	}
+	cacheLock.Lock()
+	cacheWrites[resourcePath(path)] = time.Now()
+	cacheLock.Unlock()
+	dir := cli.cacheDir()
+	if dir == "" {
+		return
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, info := range files {
+		file := filepath.Join(dir, info.Name())
+		if cached := readCachedPath(file); cached == "" || isSameResource(cached, path) {
+			os.Remove(file)
+		}
+	}
+}
+
+func cacheFile(dir string, path string) string {
+	hash := sha1.Sum([]byte(path))
+	return filepath.Join(dir, hex.EncodeToString(hash[:]))
+}
+
+func readCachedPath(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(line, "\n")
+}
+
+// Returns true when one of the paths contains the other (e.x. /rest/requisitions and /rest/requisitions/Test/nodes)
+func isSameResource(a string, b string) bool {
+	a = strings.TrimSuffix(resourcePath(a), "/")
+	b = strings.TrimSuffix(resourcePath(b), "/")
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a == b || strings.HasPrefix(b, a+"/")
+}
+
+// Returns the path without the query
+func resourcePath(path string) string {
+	if i := strings.Index(path, "?"); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+// countingTransport a transport that counts the requests per method and path, and responds with the path
+type countingTransport struct {
+	lock  sync.Mutex
+	calls map[string]int
+}
+
+func (t *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	t.lock.Lock()
+	t.calls[request.Method+" "+request.URL.RequestURI()]++
+	count := t.calls[request.Method+" "+request.URL.RequestURI()]
+	t.lock.Unlock()
+	body := fmt.Sprintf("%s %d", request.URL.RequestURI(), count)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    request,
+	}, nil
+}
+
+func (t *countingTransport) count(key string) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.calls[key]
+}
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl-cache")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	transport := &countingTransport{calls: make(map[string]int)}
+	defer func() {
+		cacheablePaths = make([]cacheablePath, 0)
+		cacheWrites = make(map[string]time.Time)
+	}()
+	RegisterCacheable("/rest/requisitions")
+	client := Client{URL: "http://onms.example.com/opennms", Username: "admin", transport: transport, cacheRoot: dir}
+
+	// Cache hits avoid network calls
+	data, err := client.Get("/rest/requisitions/Test")
+	assert.NilError(t, err)
+	assert.Equal(t, "/opennms/rest/requisitions/Test 1", string(data))
+	data, err = client.Get("/rest/requisitions/Test")
+	assert.NilError(t, err)
+	assert.Equal(t, "/opennms/rest/requisitions/Test 1", string(data))
+	assert.Equal(t, 1, transport.count("GET /opennms/rest/requisitions/Test"))
+
+	// Only the registered paths are cached
+	client.Get("/rest/requisitionNames")
+	client.Get("/rest/requisitionNames")
+	assert.Equal(t, 2, transport.count("GET /opennms/rest/requisitionNames"))
+
+	// Another server or user doesn't share the cache
+	other := Client{URL: "http://onms.example.com/opennms", Username: "operator", transport: transport, cacheRoot: dir}
+	other.Get("/rest/requisitions/Test")
+	assert.Equal(t, 2, transport.count("GET /opennms/rest/requisitions/Test"))
+
+	// Changes to the resource, or any of its children, invalidate it
+	client.Get("/rest/requisitions/Other")
+	assert.NilError(t, client.Post("/rest/requisitions/Test/nodes", []byte("{}")))
+	data, err = client.Get("/rest/requisitions/Test")
+	assert.NilError(t, err)
+	assert.Equal(t, "/opennms/rest/requisitions/Test 3", string(data))
+	client.Get("/rest/requisitions/Other")
+	assert.Equal(t, 1, transport.count("GET /opennms/rest/requisitions/Other"))
+
+	// A negative TTL or an empty directory disable the cache
+	client.CacheTTL = -1
+	client.Get("/rest/requisitions/Other")
+	assert.Equal(t, 2, transport.count("GET /opennms/rest/requisitions/Other"))
+	client.CacheTTL = 0
+	client.cacheRoot = ""
+	client.Get("/rest/requisitions/Test")
+	assert.Equal(t, 4, transport.count("GET /opennms/rest/requisitions/Test"))
+}
+
+func TestCacheWithFixedTTL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl-cache")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	transport := &countingTransport{calls: make(map[string]int)}
+	defer func() {
+		cacheablePaths = make([]cacheablePath, 0)
+		cacheWrites = make(map[string]time.Time)
+	}()
+	RegisterCacheable("/rest/foreignSources")
+	RegisterCacheableFor(time.Hour, "/rest/foreignSourcesConfig")
+	client := Client{URL: "http://onms.example.com/opennms", Username: "admin", CacheTTL: 60, transport: transport, cacheRoot: dir}
+
+	// Ages the cached responses by 10 minutes, beyond the TTL of the client
+	age := func() {
+		files, err := ioutil.ReadDir(client.cacheDir())
+		assert.NilError(t, err)
+		old := time.Now().Add(-10 * time.Minute)
+		for _, f := range files {
+			assert.NilError(t, os.Chtimes(filepath.Join(client.cacheDir(), f.Name()), old, old))
+		}
+	}
+	client.Get("/rest/foreignSourcesConfig/detectors")
+	client.Get("/rest/foreignSources/Test")
+	age()
+	client.Get("/rest/foreignSourcesConfig/detectors")
+	client.Get("/rest/foreignSources/Test")
+	assert.Equal(t, 1, transport.count("GET /opennms/rest/foreignSourcesConfig/detectors"))
+	assert.Equal(t, 2, transport.count("GET /opennms/rest/foreignSources/Test"))
+}
+
+func TestIsSameResource(t *testing.T) {
+	assert.Assert(t, isSameResource("/rest/requisitions", "/rest/requisitions/Test/nodes?limit=0"))
+	assert.Assert(t, isSameResource("/rest/requisitions/Test/nodes", "/rest/requisitions/"))
+	assert.Assert(t, !isSameResource("/rest/requisitions", "/rest/requisitionNames"))
+	assert.Assert(t, !isSameResource("/rest/requisitions/Test", "/rest/requisitions/Other"))
+}
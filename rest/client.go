@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Option a setting applied by NewClient
+type Option func(cli *Client) error
+
+// NewClient returns a client that starts from the built-in settings and applies the options in order;
+// it doesn't depend on the configuration file, so it can be used to embed the services in other applications.
+//
+// The client is safe for concurrent use by multiple goroutines, as long as its fields aren't modified after it is created;
+// clients with the same TLS and pool settings share the idle connections.
+func NewClient(opts ...Option) (*Client, error) {
+	cli := defaults
+	for _, opt := range opts {
+		if err := opt(&cli); err != nil {
+			return nil, err
+		}
+	}
+	if cli.setupError == nil {
+		if err := cli.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	cli.share()
+	return &cli, nil
+}
+
+// WithSettings replaces all the settings of the client (e.x. a profile obtained from the configuration file);
+// it must be the first option, as it also discards the ones applied before it
+func WithSettings(settings Client) Option {
+	return func(cli *Client) error {
+		*cli = settings
+		return nil
+	}
+}
+
+// WithURL sets the base URL of the server (e.x. https://onms.example.com/opennms)
+func WithURL(url string) Option {
+	return func(cli *Client) error {
+		cli.URL = url
+		return nil
+	}
+}
+
+// WithCredentials sets the user and password used to authenticate the requests
+func WithCredentials(username string, password string) Option {
+	return func(cli *Client) error {
+		cli.Username = username
+		cli.Password = password
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the server, instead of the one built from the certificate files
+func WithTLSConfig(config *tls.Config) Option {
+	return func(cli *Client) error {
+		if config == nil {
+			return fmt.Errorf("TLS configuration required")
+		}
+		cli.tlsConfig = config.Clone()
+		return nil
+	}
+}
+
+// WithTimeout sets the maximum time of each request, rounded up to seconds; 0 means no timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(cli *Client) error {
+		if timeout < 0 {
+			return fmt.Errorf("Timeout cannot be negative")
+		}
+		cli.Timeout = int(math.Ceil(timeout.Seconds()))
+		return nil
+	}
+}
+
+// WithRetries sets how many times the failed requests are retried, and the initial delay between attempts (doubled on each attempt)
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(cli *Client) error {
+		if retries < 0 {
+			return fmt.Errorf("Retries cannot be negative")
+		}
+		if backoff < 0 {
+			return fmt.Errorf("Retry backoff cannot be negative")
+		}
+		cli.Retries = retries
+		cli.RetryBackoff = int(backoff / time.Millisecond)
+		return nil
+	}
+}
+
+// WithStrictDecode sets whether the services built from the client reject responses with unknown fields or empty required fields
+func WithStrictDecode(strict bool) Option {
+	return func(cli *Client) error {
+		cli.StrictDecode = strict
+		return nil
+	}
+}
+
+// WithContext sets the context used by the requests that don't receive one explicitly (e.x. to cancel them on Ctrl-C)
+func WithContext(ctx context.Context) Option {
+	return func(cli *Client) error {
+		if ctx == nil {
+			return fmt.Errorf("Context required")
+		}
+		cli.ctx = ctx
+		return nil
+	}
+}
+
+// WithLogger sets where the client reports its warnings (e.x. a request that is going to be retried); they are discarded by default
+func WithLogger(log Logger) Option {
+	return func(cli *Client) error {
+		cli.log = log
+		return nil
+	}
+}
+
+// WithCacheDir sets the directory where the cacheable GET responses are stored; empty disables the cache, which is the default
+func WithCacheDir(dir string) Option {
+	return func(cli *Client) error {
+		cli.cacheRoot = dir
+		return nil
+	}
+}
+
+// WithTransport sets the transport used to send the requests, instead of the shared one built from the settings (e.x. for tests)
+func WithTransport(tr http.RoundTripper) Option {
+	return func(cli *Client) error {
+		cli.transport = tr
+		return nil
+	}
+}
+
+// WithSetupError defers the reason the client cannot be used (e.x. an invalid configuration file or an unknown profile)
+// until the first request is sent, so the commands that only work with local files don't depend on it;
+// the settings are not validated when it is set
+func WithSetupError(err error) Option {
+	return func(cli *Client) error {
+		cli.setupError = err
+		return nil
+	}
+}
@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestNewClientWithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"version":"26.1.0"}`))
+	}))
+	defer server.Close()
+
+	// The certificate of the test server is not trusted by default
+	client, err := NewClient(WithURL(server.URL), WithRetries(0, 0))
+	assert.NilError(t, err)
+	_, err = client.Get("/rest/info")
+	assert.ErrorContains(t, err, "certificate")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	client, err = NewClient(WithURL(server.URL), WithTLSConfig(&tls.Config{RootCAs: pool}))
+	assert.NilError(t, err)
+	assert.NilError(t, client.MinServerVersion("26.0.0", "Testing"))
+	assert.Equal(t, "26.1.0", client.ServerVersion.String())
+	assert.Equal(t, "http://localhost:8980/opennms", Defaults().URL)
+}
@@ -0,0 +1,308 @@
+package rest
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/imdario/mergo"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultProfile the name of the implicit profile built from the top level settings of the configuration file
+const DefaultProfile = "default"
+
+// Config the content of the configuration file;
+// the top level server settings are the implicit "default" profile, and named profiles inherit any setting they don't define from it
+type Config struct {
+	Client   `yaml:",inline"`
+	Profile  string            `yaml:"profile,omitempty"`
+	Profiles map[string]Client `yaml:"profiles,omitempty"`
+
+	// The boolean and numeric settings defined by each profile (the default one included), as false or 0 are also omitted
+	// when they are not defined; a defined value overrides the inherited one, even if it is false or 0
+	defined map[string]map[string]bool
+}
+
+// The YAML keys of the boolean and numeric settings of a client, with the index of their fields
+var scalarSettings = findScalarSettings()
+
+func findScalarSettings() map[string]int {
+	settings := make(map[string]int)
+	t := reflect.TypeOf(Client{})
+	for i := 0; i < t.NumField(); i++ {
+		switch t.Field(i).Type.Kind() {
+		case reflect.Bool, reflect.Int:
+			settings[strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]] = i
+		}
+	}
+	return settings
+}
+
+// UnmarshalYAML decodes the configuration file, recording the settings defined by each profile
+func (cfg *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Config
+	if err := unmarshal((*plain)(cfg)); err != nil {
+		return err
+	}
+	raw := make(map[string]interface{})
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	cfg.defined = nil
+	for key := range raw {
+		cfg.MarkDefined(DefaultProfile, key)
+	}
+	profiles, _ := raw["profiles"].(map[interface{}]interface{})
+	for name, settings := range profiles {
+		keys, _ := settings.(map[interface{}]interface{})
+		for key := range keys {
+			cfg.MarkDefined(fmt.Sprint(name), fmt.Sprint(key))
+		}
+	}
+	return nil
+}
+
+// MarshalYAML encodes the configuration file, keeping the settings defined as false or 0
+func (cfg Config) MarshalYAML() (interface{}, error) {
+	type plain Config
+	data, err := yaml.Marshal(plain(cfg))
+	if err != nil {
+		return nil, err
+	}
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	doc = cfg.withDefined(doc, DefaultProfile, cfg.Client)
+	for i, item := range doc {
+		if item.Key != "profiles" {
+			continue
+		}
+		profiles, _ := item.Value.(yaml.MapSlice)
+		for j, profile := range profiles {
+			name := fmt.Sprint(profile.Key)
+			settings, _ := profile.Value.(yaml.MapSlice)
+			profiles[j].Value = cfg.withDefined(settings, name, cfg.Profiles[name])
+		}
+		doc[i].Value = profiles
+	}
+	return doc, nil
+}
+
+// Adds the settings defined by a profile that were omitted for being false or 0
+func (cfg Config) withDefined(settings yaml.MapSlice, name string, client Client) yaml.MapSlice {
+	present := make(map[string]bool)
+	for _, item := range settings {
+		present[fmt.Sprint(item.Key)] = true
+	}
+	value := reflect.ValueOf(client)
+	for _, key := range cfg.definedSettings(name) {
+		if !present[key] {
+			settings = append(settings, yaml.MapItem{Key: key, Value: value.Field(scalarSettings[key]).Interface()})
+		}
+	}
+	return settings
+}
+
+// Returns the sorted boolean and numeric settings defined by a profile
+func (cfg Config) definedSettings(name string) []string {
+	keys := make([]string, 0)
+	for key := range cfg.defined[name] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MarkDefined records that a profile defines the given settings, so they override the inherited ones even if they are false or 0
+func (cfg *Config) MarkDefined(name string, keys ...string) {
+	for _, key := range keys {
+		if _, ok := scalarSettings[key]; !ok {
+			continue
+		}
+		if cfg.defined == nil {
+			cfg.defined = make(map[string]map[string]bool)
+		}
+		if cfg.defined[name] == nil {
+			cfg.defined[name] = make(map[string]bool)
+		}
+		cfg.defined[name][key] = true
+	}
+}
+
+// ParseConfig decodes the content of the configuration file; unknown keys (e.x. typos) are errors
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate returns the problems found on the effective settings of every profile
+func (cfg Config) Validate() []error {
+	problems := make([]error, 0)
+	if cfg.Profile != "" && cfg.Profile != DefaultProfile {
+		if _, ok := cfg.Profiles[cfg.Profile]; !ok {
+			problems = append(problems, fmt.Errorf("The active profile %s doesn't exist", cfg.Profile))
+		}
+	}
+	for _, name := range cfg.ProfileNames() {
+		client, err := cfg.GetProfile(name)
+		if err == nil {
+			err = client.Validate()
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("Profile %s: %w", name, err))
+		}
+	}
+	return problems
+}
+
+// Validate returns an error if the server settings are invalid
+func (cli Client) Validate() error {
+	u, err := url.Parse(cli.URL)
+	if err != nil {
+		return fmt.Errorf("Invalid URL %s: %w", cli.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("Invalid URL %s: the scheme must be http or https", cli.URL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("Invalid URL %s: the host is missing", cli.URL)
+	}
+	if cli.Timeout < 0 {
+		return fmt.Errorf("Timeout cannot be negative")
+	}
+	if cli.Retries < 0 {
+		return fmt.Errorf("Retries cannot be negative")
+	}
+	if cli.RetryBackoff < 0 {
+		return fmt.Errorf("Retry backoff cannot be negative")
+	}
+	if cli.MaxConnections < 0 {
+		return fmt.Errorf("Max connections cannot be negative")
+	}
+	if cli.IdleTimeout < 0 {
+		return fmt.Errorf("Idle timeout cannot be negative")
+	}
+	if err := cli.Proxy.Validate(); err != nil {
+		return err
+	}
+	if err := ValidatePasswordSource(cli.PasswordSource); err != nil {
+		return err
+	}
+	return cli.ValidateTLS()
+}
+
+// GetProfile returns the client settings for a given profile; when the name is empty, the active profile is returned
+func (cfg Config) GetProfile(name string) (*Client, error) {
+	if name == "" {
+		name = cfg.Profile
+	}
+	client := defaults
+	if err := cfg.inherit(&client, DefaultProfile, cfg.Client); err != nil {
+		return nil, err
+	}
+	if name != "" && name != DefaultProfile {
+		profile, ok := cfg.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("Profile %s doesn't exist", name)
+		}
+		if err := cfg.inherit(&client, name, profile); err != nil {
+			return nil, err
+		}
+	}
+	// The proxies are inherited as a whole, so a profile with its own proxy doesn't mix it with the ones of the default profile
+	if profile, ok := cfg.Profiles[name]; ok && !profile.Proxy.IsEmpty() {
+		client.Proxy = profile.Proxy
+		client.NoProxy = profile.NoProxy
+	}
+	// A profile with its own password doesn't use the password source of the default profile
+	if profile, ok := cfg.Profiles[name]; ok && profile.Password != "" && profile.PasswordSource == "" {
+		client.PasswordSource = ""
+	}
+	client.ProfileName = name
+	if name == "" {
+		client.ProfileName = DefaultProfile
+	}
+	client.share()
+	return &client, nil
+}
+
+// Applies the settings of a profile over the inherited ones; false and 0 only replace them when the profile defines them
+func (cfg Config) inherit(client *Client, name string, profile Client) error {
+	if err := mergo.Merge(client, profile, mergo.WithOverride); err != nil {
+		return err
+	}
+	target := reflect.ValueOf(client).Elem()
+	source := reflect.ValueOf(profile)
+	for key := range cfg.defined[name] {
+		i := scalarSettings[key]
+		target.Field(i).Set(source.Field(i))
+	}
+	return nil
+}
+
+// SetProfile adds or updates a named profile
+func (cfg *Config) SetProfile(name string, client Client) error {
+	if name == "" {
+		return fmt.Errorf("Profile name required")
+	}
+	if name == DefaultProfile {
+		cfg.Client = client
+		return nil
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]Client)
+	}
+	cfg.Profiles[name] = client
+	return nil
+}
+
+// DeleteProfile removes a named profile
+func (cfg *Config) DeleteProfile(name string) error {
+	if name == "" {
+		return fmt.Errorf("Profile name required")
+	}
+	if name == DefaultProfile {
+		return fmt.Errorf("The %s profile cannot be deleted", DefaultProfile)
+	}
+	if _, ok := cfg.Profiles[name]; !ok {
+		return fmt.Errorf("Profile %s doesn't exist", name)
+	}
+	delete(cfg.Profiles, name)
+	delete(cfg.defined, name)
+	if cfg.Profile == name {
+		cfg.Profile = ""
+	}
+	return nil
+}
+
+// UseProfile sets the active profile
+func (cfg *Config) UseProfile(name string) error {
+	if name == "" {
+		return fmt.Errorf("Profile name required")
+	}
+	if _, err := cfg.GetProfile(name); err != nil {
+		return err
+	}
+	if name == DefaultProfile {
+		name = ""
+	}
+	cfg.Profile = name
+	return nil
+}
+
+// ProfileNames returns the sorted list of profiles, including the default one
+func (cfg Config) ProfileNames() []string {
+	names := []string{DefaultProfile}
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
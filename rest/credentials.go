@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/google/shlex"
+	"github.com/zalando/go-keyring"
+)
+
+// PasswordSourceKeyring the password source that reads the password of the profile from the OS keychain
+const PasswordSourceKeyring = "keyring"
+
+// The prefixes of the password sources that take an argument
+const (
+	passwordSourceEnv = "env:" // The name of an environment variable
+	passwordSourceCmd = "cmd:" // A command and its arguments, split like a shell does (with quotes), whose output is the password
+)
+
+// KeyringService the service the passwords are stored under on the OS keychain, with the name of the profile as the account
+const KeyringService = "onmsctl"
+
+// Keyring a store for the passwords of the profiles
+type Keyring interface {
+	Get(service string, account string) (string, error)
+	Set(service string, account string, password string) error
+}
+
+// DefaultKeyring the keyring used by the keyring password source; the OS keychain by default
+// (the Keychain on macOS, the Secret Service on Linux, or the Credential Manager on Windows)
+var DefaultKeyring Keyring = systemKeyring{}
+
+// The passwords obtained from the sources, by profile and source, so each source is used once per execution
+var resolvedPasswords = make(map[string]string)
+var resolvedPasswordsLock sync.Mutex
+
+// ValidatePasswordSource returns an error if the password source is not keyring, env:VARNAME or cmd:command
+func ValidatePasswordSource(source string) error {
+	switch {
+	case source == "" || source == PasswordSourceKeyring:
+		return nil
+	case strings.HasPrefix(source, passwordSourceEnv):
+		if strings.TrimPrefix(source, passwordSourceEnv) == "" {
+			return fmt.Errorf("Invalid password source %s: the environment variable is missing", source)
+		}
+		return nil
+	case strings.HasPrefix(source, passwordSourceCmd):
+		_, err := commandArgs(source)
+		return err
+	}
+	return fmt.Errorf("Invalid password source %s: expected keyring, env:VARNAME or cmd:command", source)
+}
+
+// Returns the password of the requests, obtained from the password source of the profile when it has one
+func (cli Client) getPassword() (string, error) {
+	if cli.PasswordSource == "" {
+		return cli.Password, nil
+	}
+	profile := cli.ProfileName
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	key := profile + "\n" + cli.PasswordSource
+	resolvedPasswordsLock.Lock()
+	defer resolvedPasswordsLock.Unlock()
+	if password, ok := resolvedPasswords[key]; ok {
+		return password, nil
+	}
+	password, err := resolvePassword(cli.Context(), profile, cli.PasswordSource)
+	if err != nil {
+		return "", fmt.Errorf("Cannot obtain the password of profile %s from %s: %w", profile, cli.PasswordSource, err)
+	}
+	resolvedPasswords[key] = password
+	return password, nil
+}
+
+func resolvePassword(ctx context.Context, profile string, source string) (string, error) {
+	if err := ValidatePasswordSource(source); err != nil {
+		return "", err
+	}
+	switch {
+	case source == PasswordSourceKeyring:
+		return DefaultKeyring.Get(KeyringService, profile)
+	case strings.HasPrefix(source, passwordSourceEnv):
+		name := strings.TrimPrefix(source, passwordSourceEnv)
+		password, ok := os.LookupEnv(name)
+		if !ok || password == "" {
+			return "", fmt.Errorf("the environment variable %s is not set", name)
+		}
+		return password, nil
+	}
+	args, err := commandArgs(source)
+	if err != nil {
+		return "", err
+	}
+	password, err := runCommand(exec.CommandContext(ctx, args[0], args[1:]...))
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", fmt.Errorf("the command returned an empty password")
+	}
+	return password, nil
+}
+
+// Returns the command and arguments of a cmd: password source; like on a shell, quotes and backslashes
+// keep arguments with spaces together (e.x. cmd:get-secret "onms prod")
+func commandArgs(source string) ([]string, error) {
+	args, err := shlex.Split(strings.TrimPrefix(source, passwordSourceCmd))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid password source %s: %w", source, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Invalid password source %s: the command is missing", source)
+	}
+	return args, nil
+}
+
+// Runs a command, and returns its output without the trailing line break;
+// the errors include what the command wrote to stderr
+func runCommand(cmd *exec.Cmd) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%s: %s", err, message)
+		}
+		return "", err
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// systemKeyring the OS keychain, through go-keyring
+type systemKeyring struct{}
+
+func (k systemKeyring) Get(service string, account string) (string, error) {
+	password, err := keyring.Get(service, account)
+	if err == keyring.ErrNotFound || (err == nil && password == "") {
+		return "", fmt.Errorf("the keyring entry %s/%s doesn't exist", service, account)
+	}
+	if err != nil {
+		return "", fmt.Errorf("the keyring entry %s/%s cannot be read: %w", service, account, err)
+	}
+	return password, nil
+}
+
+func (k systemKeyring) Set(service string, account string, password string) error {
+	if err := keyring.Set(service, account, password); err != nil {
+		return fmt.Errorf("the keyring entry %s/%s cannot be written: %w", service, account, err)
+	}
+	return nil
+}
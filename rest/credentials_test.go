@@ -0,0 +1,156 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+	"gotest.tools/assert"
+)
+
+// mockKeyring a keyring in memory, counting the reads
+type mockKeyring struct {
+	entries map[string]string
+	reads   int
+}
+
+func (k *mockKeyring) Get(service string, account string) (string, error) {
+	k.reads++
+	if password, ok := k.entries[service+"/"+account]; ok {
+		return password, nil
+	}
+	return "", fmt.Errorf("the keyring entry %s/%s doesn't exist", service, account)
+}
+
+func (k *mockKeyring) Set(service string, account string, password string) error {
+	k.entries[service+"/"+account] = password
+	return nil
+}
+
+func resetPasswords() {
+	resolvedPasswords = make(map[string]string)
+	DefaultKeyring = systemKeyring{}
+}
+
+func TestValidatePasswordSource(t *testing.T) {
+	for _, source := range []string{"", "keyring", "env:ONMS_PASSWORD", "cmd:/usr/bin/get-secret onms-prod"} {
+		assert.NilError(t, ValidatePasswordSource(source))
+	}
+	assert.Error(t, ValidatePasswordSource("vault"), "Invalid password source vault: expected keyring, env:VARNAME or cmd:command")
+	assert.Error(t, ValidatePasswordSource("env:"), "Invalid password source env:: the environment variable is missing")
+	assert.Error(t, ValidatePasswordSource("cmd: "), "Invalid password source cmd: : the command is missing")
+	assert.Error(t, ValidatePasswordSource(`cmd:get-secret "onms prod`), `Invalid password source cmd:get-secret "onms prod: EOF found when expecting closing quote`)
+}
+
+func TestSystemKeyring(t *testing.T) {
+	keyring.MockInit()
+	_, err := systemKeyring{}.Get(KeyringService, "lab")
+	assert.Error(t, err, "the keyring entry onmsctl/lab doesn't exist")
+	assert.NilError(t, systemKeyring{}.Set(KeyringService, "lab", "s3cr3t"))
+	password, err := systemKeyring{}.Get(KeyringService, "lab")
+	assert.NilError(t, err)
+	assert.Equal(t, "s3cr3t", password)
+}
+
+func TestPasswordSources(t *testing.T) {
+	defer resetPasswords()
+	keyring := &mockKeyring{entries: map[string]string{"onmsctl/prod": "from-keyring"}}
+	DefaultKeyring = keyring
+	os.Setenv("ONMSCTL_TEST_PASSWORD", "from-env")
+	defer os.Unsetenv("ONMSCTL_TEST_PASSWORD")
+
+	password, err := Client{Password: "plain"}.getPassword()
+	assert.NilError(t, err)
+	assert.Equal(t, "plain", password)
+
+	client := Client{Password: "plain", PasswordSource: "keyring", ProfileName: "prod"}
+	password, err = client.getPassword()
+	assert.NilError(t, err)
+	assert.Equal(t, "from-keyring", password)
+	assert.Equal(t, "plain", client.Password) // Never replaced by the obtained password
+
+	// Obtained once per profile and source
+	_, err = client.getPassword()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, keyring.reads)
+
+	password, err = Client{PasswordSource: "env:ONMSCTL_TEST_PASSWORD"}.getPassword()
+	assert.NilError(t, err)
+	assert.Equal(t, "from-env", password)
+
+	password, err = Client{PasswordSource: "cmd:echo from-cmd"}.getPassword()
+	assert.NilError(t, err)
+	assert.Equal(t, "from-cmd", password)
+
+	// Quoted arguments are kept together, without using a shell
+	password, err = Client{PasswordSource: `cmd:printf "%s-%s" 'from cmd' quoted`}.getPassword()
+	assert.NilError(t, err)
+	assert.Equal(t, "from cmd-quoted", password)
+
+	// The errors identify the profile and the source
+	_, err = Client{PasswordSource: "keyring", ProfileName: "lab"}.getPassword()
+	assert.Error(t, err, "Cannot obtain the password of profile lab from keyring: the keyring entry onmsctl/lab doesn't exist")
+	_, err = Client{PasswordSource: "env:ONMSCTL_TEST_MISSING", ProfileName: "lab"}.getPassword()
+	assert.Error(t, err, "Cannot obtain the password of profile lab from env:ONMSCTL_TEST_MISSING: the environment variable ONMSCTL_TEST_MISSING is not set")
+	_, err = Client{PasswordSource: "cmd:false", ProfileName: "lab"}.getPassword()
+	assert.Error(t, err, "Cannot obtain the password of profile lab from cmd:false: exit status 1")
+	_, err = Client{PasswordSource: "cmd:sh -c exit", ProfileName: "lab"}.getPassword()
+	assert.Error(t, err, "Cannot obtain the password of profile lab from cmd:sh -c exit: the command returned an empty password")
+}
+
+func TestRequestWithPasswordSource(t *testing.T) {
+	defer resetPasswords()
+	DefaultKeyring = &mockKeyring{entries: map[string]string{"onmsctl/lab": "from-keyring"}}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests++
+		username, password, _ := req.BasicAuth()
+		assert.Equal(t, "admin", username)
+		assert.Equal(t, "from-keyring", password)
+	}))
+	defer server.Close()
+
+	client := Client{URL: server.URL, Username: "admin", PasswordSource: "keyring", ProfileName: "lab"}
+	_, err := client.Get("/rest/info")
+	assert.NilError(t, err)
+
+	// The source is not used until the first request
+	client.ProfileName = "stage"
+	_, err = client.Get("/rest/info")
+	assert.Error(t, err, "Cannot obtain the password of profile stage from keyring: the keyring entry onmsctl/stage doesn't exist")
+	assert.Equal(t, 1, requests)
+}
+
+func TestPasswordSourceProfiles(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+url: http://onms:8980/opennms
+passwordSource: keyring
+profiles:
+  lab:
+    url: http://lab:8980/opennms
+    password: lab
+  prod:
+    url: http://prod:8980/opennms
+    passwordSource: cmd:/usr/bin/get-secret onms-prod
+  stage:
+    url: http://stage:8980/opennms
+    passwordSource: vault
+`))
+	assert.NilError(t, err)
+	client, err := cfg.GetProfile("lab")
+	assert.NilError(t, err)
+	assert.Equal(t, "", client.PasswordSource) // Its own password wins over the inherited source
+	client, err = cfg.GetProfile("prod")
+	assert.NilError(t, err)
+	assert.Equal(t, "cmd:/usr/bin/get-secret onms-prod", client.PasswordSource)
+	client, err = cfg.GetProfile("")
+	assert.NilError(t, err)
+	assert.Equal(t, "keyring", client.PasswordSource)
+
+	problems := cfg.Validate()
+	assert.Equal(t, 1, len(problems))
+	assert.Error(t, problems[0], "Profile stage: Invalid password source vault: expected keyring, env:VARNAME or cmd:command")
+}
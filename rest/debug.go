@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugTrace the debug level that includes the headers and bodies of requests and responses
+const DebugTrace = "trace"
+
+// redacted the replacement for sensitive content on debug messages
+const redacted = "[REDACTED]"
+
+// Where the debug messages are written
+var debugOutput io.Writer = os.Stderr
+
+// The names of the fields that might contain credentials (e.x. SNMPv3 pass phrases or community strings)
+const sensitiveKey = `[\w.-]*(?:password|passwd|passphrase|secret|token|community)[\w.-]*`
+
+var sensitivePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)("` + sensitiveKey + `"\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\]\s]+)`), `${1}"` + redacted + `"`}, // JSON
+	{regexp.MustCompile(`(?i)(\s` + sensitiveKey + `\s*=\s*)("[^"]*"|'[^']*')`), `${1}"` + redacted + `"`},              // XML attributes
+	{regexp.MustCompile(`(?i)(<` + sensitiveKey + `(?:\s[^>]*)?>)[^<]*(<)`), `${1}` + redacted + `${2}`},                // XML elements
+	{regexp.MustCompile(`(?i)((?:^|[?&])` + sensitiveKey + `=)[^&\s]*`), `${1}` + redacted},                             // Query strings and forms
+	{regexp.MustCompile(`(?im)(^\s*-?\s*` + sensitiveKey + `\s*:[ \t]*)\S.*$`), `${1}` + redacted},                      // YAML
+}
+
+// DebugLevel a command line flag value to choose the debug level of the ReST client;
+// it can be used as a boolean flag, or with "trace" to include the content of requests and responses
+type DebugLevel struct {
+	Client *Client
+}
+
+// Set enables the debug level from a boolean or "trace"
+func (d DebugLevel) Set(value string) error {
+	if strings.EqualFold(value, DebugTrace) {
+		d.Client.Debug = true
+		d.Client.Trace = true
+		return nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("Invalid debug level %s, valid options: true, false, %s", value, DebugTrace)
+	}
+	d.Client.Debug = enabled
+	d.Client.Trace = false
+	return nil
+}
+
+// String returns the chosen debug level
+func (d DebugLevel) String() string {
+	if d.Client == nil || !d.Client.Debug {
+		return ""
+	}
+	if d.Client.Trace {
+		return DebugTrace
+	}
+	return "true"
+}
+
+// IsBoolFlag allows using the flag without a value
+func (d DebugLevel) IsBoolFlag() bool {
+	return true
+}
+
+// debugTransport an http.RoundTripper that logs requests and responses, redacting credentials
+type debugTransport struct {
+	next  http.RoundTripper
+	trace bool
+}
+
+func (t *debugTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	fmt.Fprintf(debugOutput, "DEBUG > %s %s\n", request.Method, redactURL(request.URL))
+	if tr, ok := t.next.(*http.Transport); ok && tr.Proxy != nil {
+		if proxy, err := tr.Proxy(request); err == nil && proxy != nil {
+			fmt.Fprintf(debugOutput, "DEBUG > via proxy %s\n", redactURL(proxy))
+		}
+	}
+	if t.trace {
+		writeHeaders(">", request.Header)
+		if request.Body != nil {
+			body, err := ioutil.ReadAll(request.Body)
+			request.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = ioutil.NopCloser(bytes.NewReader(body))
+			writeBody(">", body)
+		}
+	}
+	response, err := t.next.RoundTrip(request)
+	duration := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(debugOutput, "DEBUG < %s %s failed after %s: %s\n", request.Method, redactURL(request.URL), duration, RedactContent(err.Error()))
+		return nil, err
+	}
+	fmt.Fprintf(debugOutput, "DEBUG < %s %s %s\n", response.Status, redactURL(request.URL), duration)
+	if t.trace {
+		writeHeaders("<", response.Header)
+		body, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		response.Body = ioutil.NopCloser(bytes.NewReader(body))
+		writeBody("<", body)
+	}
+	return response, nil
+}
+
+// RedactContent replaces the values of the fields that might contain credentials
+func RedactContent(content string) string {
+	for _, p := range sensitivePatterns {
+		content = p.pattern.ReplaceAllString(content, p.replacement)
+	}
+	return content
+}
+
+func redactURL(u *url.URL) string {
+	copy := *u
+	if copy.User != nil {
+		if _, ok := copy.User.Password(); ok {
+			copy.User = url.UserPassword(copy.User.Username(), "xxxxx")
+		}
+	}
+	return RedactContent(copy.String())
+}
+
+func writeHeaders(direction string, headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(headers[name], ", ")
+		if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, "Cookie") || strings.EqualFold(name, "Set-Cookie") {
+			value = redacted
+		}
+		fmt.Fprintf(debugOutput, "DEBUG %s %s: %s\n", direction, name, value)
+	}
+}
+
+func writeBody(direction string, body []byte) {
+	if len(body) > 0 {
+		fmt.Fprintf(debugOutput, "DEBUG %s %s\n", direction, RedactContent(string(body)))
+	}
+}
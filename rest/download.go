@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// IsURL returns true when the location of a file is an HTTP(S) URL
+func IsURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// Download obtains the content of an external HTTP(S) URL (e.x. a requisition from a Git server);
+// the TLS settings and the timeout of the client are used, but the OpenNMS credentials are never sent
+func (cli Client) Download(url string) ([]byte, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client, err := cli.getHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Do(request.WithContext(cli.Context()))
+	if err != nil {
+		return nil, &ConnectionError{Method: request.Method, URL: redactURL(request.URL), Err: err}
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, newAPIError(request, response)
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, &ConnectionError{Method: request.Method, URL: redactURL(request.URL), Err: err}
+	}
+	return data, nil
+}
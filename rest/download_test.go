@@ -0,0 +1,37 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestDownload(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		_, _, ok := req.BasicAuth()
+		assert.Assert(t, !ok, "the credentials must not be sent")
+		if req.URL.Path != "/reqs/site-a.yaml" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		res.Write([]byte("name: SiteA\n"))
+	}))
+	defer server.Close()
+
+	assert.Assert(t, IsURL(server.URL))
+	assert.Assert(t, !IsURL("/tmp/site-a.yaml"))
+
+	client := Client{Username: "admin", Password: "admin", Timeout: 5}
+	_, err := client.Download(server.URL + "/reqs/site-a.yaml")
+	assert.ErrorContains(t, err, "certificate")
+
+	client.Insecure = true
+	data, err := client.Download(server.URL + "/reqs/site-a.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, "name: SiteA\n", string(data))
+
+	_, err = client.Download(server.URL + "/reqs/unknown.yaml")
+	assert.Error(t, err, "Invalid Response: 404 Not Found")
+}
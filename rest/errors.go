@@ -0,0 +1,168 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// The exit status of the CLI by type of failure, so scripts can branch on them
+const (
+	ExitConnectionError = 3 // The server is unreachable, or the request timed out
+	ExitClientError     = 4 // The server rejected the request with a 4xx status
+	ExitServerError     = 5 // The server failed with a 5xx status
+)
+
+// The maximum amount of characters of the response body kept on an APIError
+const maxErrorBody = 1000
+
+var (
+	htmlTagsPattern   = regexp.MustCompile(`(?s)<(script|style).*?</(script|style)>|<[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// APIError an unexpected HTTP status, with what the server said about it
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Message    string // The message field of a JSON response, usually the real reason
+	Body       string // The response body as text, truncated
+	Attempts   int
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("Invalid Response: %s", e.Status)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+	return msg
+}
+
+// Details returns a readable multi-line description, including the request and the response body
+func (e *APIError) Details() string {
+	lines := []string{fmt.Sprintf("%s %s failed with %s", e.Method, e.URL, e.Status)}
+	if e.Attempts > 1 {
+		lines[0] += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+	if e.Message != "" {
+		lines = append(lines, "  Message: "+e.Message)
+	}
+	if e.Body != "" && e.Body != e.Message {
+		lines = append(lines, "  Response: "+e.Body)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ExitStatus returns ExitClientError for 4xx responses, and ExitServerError otherwise
+func (e *APIError) ExitStatus() int {
+	if e.StatusCode >= 400 && e.StatusCode < 500 {
+		return ExitClientError
+	}
+	return ExitServerError
+}
+
+// ConnectionError a request that couldn't get a response (e.x. connection refused, DNS or TLS failures, timeouts)
+type ConnectionError struct {
+	Method   string
+	URL      string
+	Server   string // The base URL of the OpenNMS server; empty for external downloads
+	Err      error
+	Attempts int
+}
+
+func (e *ConnectionError) Error() string {
+	target := e.Server
+	if target == "" {
+		target = e.URL
+	}
+	msg := fmt.Sprintf("Cannot connect to %s: %s", target, describeNetError(e.Err))
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+	return msg
+}
+
+// Details returns the error with a hint about how to choose the server
+func (e *ConnectionError) Details() string {
+	if e.Server == "" {
+		return e.Error()
+	}
+	return e.Error() + "\n  Hint: verify that OpenNMS is running on that URL, or choose the server with --url or --profile (see 'onmsctl config profile list')"
+}
+
+// ExitStatus returns ExitConnectionError
+func (e *ConnectionError) ExitStatus() int {
+	return ExitConnectionError
+}
+
+// Returns the root cause of a network error, without the layers added by the HTTP client (e.x. "connection refused")
+func describeNetError(err error) string {
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return "the request timed out"
+	}
+	for {
+		switch e := err.(type) {
+		case *url.Error:
+			err = e.Err
+			continue
+		case *net.OpError:
+			err = e.Err
+			continue
+		case *os.SyscallError:
+			err = e.Err
+			continue
+		case *net.DNSError:
+			return "cannot resolve host " + e.Name
+		}
+		return err.Error()
+	}
+}
+
+// Builds the error for an unexpected response, reading its body
+func newAPIError(request *http.Request, response *http.Response) *APIError {
+	e := &APIError{
+		Method:     request.Method,
+		URL:        redactURL(request.URL),
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil || len(bytes.TrimSpace(data)) == 0 {
+		return e
+	}
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	switch {
+	case strings.HasSuffix(mediaType, "json"):
+		content := make(map[string]interface{})
+		if json.Unmarshal(data, &content) == nil {
+			if msg, ok := content["message"].(string); ok {
+				e.Message = RedactContent(strings.TrimSpace(msg))
+			}
+		}
+		compact := &bytes.Buffer{}
+		if json.Compact(compact, data) == nil {
+			data = compact.Bytes()
+		}
+	case mediaType == "text/html":
+		data = htmlTagsPattern.ReplaceAll(data, []byte(" "))
+	}
+	body := strings.TrimSpace(whitespacePattern.ReplaceAllString(string(data), " "))
+	if runes := []rune(body); len(runes) > maxErrorBody {
+		body = string(runes[:maxErrorBody]) + "..."
+	}
+	e.Body = RedactContent(body)
+	return e
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/json":
+			res.Header().Set("Content-Type", "application/json;charset=UTF-8")
+			res.WriteHeader(http.StatusBadRequest)
+			res.Write([]byte(`{ "message": "Invalid detector class", "password": "secret" }`))
+		case "/html":
+			res.Header().Set("Content-Type", "text/html")
+			res.WriteHeader(http.StatusInternalServerError)
+			res.Write([]byte("<html><head><title>Error 500</title><style>p {}</style></head><body><pre>constraint violation</pre></body></html>"))
+		case "/long":
+			res.Header().Set("Content-Type", "text/plain")
+			res.WriteHeader(http.StatusConflict)
+			res.Write([]byte(strings.Repeat("x", 2000)))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+	client := Client{URL: testServer.URL, Timeout: 5}
+
+	err := client.Put("/json", []byte("{}"), "application/json")
+	e, ok := err.(*APIError)
+	assert.Assert(t, ok)
+	assert.Error(t, err, "Invalid Response: 400 Bad Request: Invalid detector class")
+	assert.Equal(t, ExitClientError, e.ExitStatus())
+	assert.Equal(t, "PUT "+testServer.URL+"/json failed with 400 Bad Request\n  Message: Invalid detector class\n  Response: {\"message\":\"Invalid detector class\",\"password\":\"[REDACTED]\"}", e.Details())
+
+	_, err = client.Get("/html")
+	e = err.(*APIError)
+	assert.Error(t, err, "Invalid Response: 500 Internal Server Error")
+	assert.Equal(t, ExitServerError, e.ExitStatus())
+	assert.Equal(t, "Error 500 constraint violation", e.Body)
+
+	err = client.Delete("/long")
+	e = err.(*APIError)
+	assert.Equal(t, 1003, len(e.Body))
+	assert.Equal(t, ExitClientError, e.ExitStatus())
+
+	err = client.Post("/missing", []byte("{}"))
+	e = err.(*APIError)
+	assert.Equal(t, "", e.Body)
+	assert.Equal(t, "POST "+testServer.URL+"/missing failed with 404 Not Found", e.Details())
+}
+
+func TestConnectionError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+	testServer.Close()
+	client := Client{URL: testServer.URL, Timeout: 5, Retries: 1, RetryBackoff: 1}
+
+	_, err := client.Get("/user")
+	e, ok := err.(*ConnectionError)
+	assert.Assert(t, ok)
+	assert.Equal(t, ExitConnectionError, e.ExitStatus())
+	assert.Equal(t, 2, e.Attempts)
+	assert.Error(t, err, "Cannot connect to "+testServer.URL+": connection refused (after 2 attempts)")
+	assert.Assert(t, strings.Contains(e.Details(), "Hint: verify that OpenNMS is running on that URL, or choose the server with --url or --profile"))
+}
+
+func TestSetupError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		t.Fatalf("no request expected, got %s", req.URL.Path)
+	}))
+	defer testServer.Close()
+	client, err := NewClient(WithURL(testServer.URL), WithSetupError(fmt.Errorf("Profile prod doesn't exist")))
+	assert.NilError(t, err)
+	_, err = client.Get("/user")
+	assert.Error(t, err, "Profile prod doesn't exist")
+	err = client.GetStream("/user", func(body io.Reader) error { return nil })
+	assert.Error(t, err, "Profile prod doesn't exist")
+}
@@ -0,0 +1,21 @@
+package rest
+
+// Logger receives the messages of the ReST client that are not errors (e.x. a request that is going to be retried);
+// the CLI injects its leveled logger, so they can be silenced with --quiet
+type Logger interface {
+	Warnf(format string, args ...interface{})
+	Debugf(format string, args ...interface{})
+}
+
+// Returns where the client reports its messages; they are discarded when no logger was set through WithLogger
+func (cli Client) logger() Logger {
+	if cli.log == nil {
+		return nopLogger{}
+	}
+	return cli.log
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Warnf(format string, args ...interface{})  {}
+func (nopLogger) Debugf(format string, args ...interface{}) {}
@@ -2,109 +2,368 @@ package rest
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
+	"math/rand"
 	"net/http"
 	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Instance a global reference to the ReST Client instance
-var Instance = Client{
-	URL:      "http://localhost:8980/opennms",
-	Username: "admin",
-	Password: "admin",
-	Timeout:  5,
+// The built-in settings, used by NewClient and for anything not defined on the configuration file
+var defaults = Client{
+	URL:          "http://localhost:8980/opennms",
+	Username:     "admin",
+	Password:     "admin",
+	Timeout:      60,
+	RetryBackoff: 500,
+}
+
+// ErrCancelled the error returned when a request is interrupted through its context
+var ErrCancelled = errors.New("operation cancelled")
+
+// Defaults returns the built-in settings of the clients
+func Defaults() Client {
+	return defaults
+}
+
+// Context returns the context used by the requests that don't receive one (e.x. cancelled on Ctrl-C), set through WithContext;
+// it can be used by the operations related to the client that don't go through it (e.x. other transports)
+func (cli Client) Context() context.Context {
+	if cli.ctx == nil {
+		return context.Background()
+	}
+	return cli.ctx
+}
+
+// Sleep waits for the given duration, or until the context of the client is cancelled (e.x. on Ctrl-C)
+func (cli Client) Sleep(d time.Duration) error {
+	select {
+	case <-cli.Context().Done():
+		return ErrCancelled
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// IsStrictDecode returns true when the responses received by the client must be decoded strictly,
+// to detect ReST API changes after upgrades
+func (cli Client) IsStrictDecode() bool {
+	return cli.StrictDecode
 }
 
 // Client OpenNMS ReST API configuration
 type Client struct {
-	URL      string `yaml:"url"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
-	Insecure bool   `yaml:"insecure"`
-	Timeout  int    `yaml:"timeout"`
-	Debug    bool   `yaml:"debug"`
+	URL          string `yaml:"url,omitempty"`
+	Username     string `yaml:"username,omitempty"`
+	Password     string `yaml:"password,omitempty"`
+	Insecure     bool   `yaml:"insecure,omitempty"`
+	CACert       string `yaml:"caCert,omitempty"`     // PEM bundle with the CAs trusted to sign the server certificate
+	ClientCert   string `yaml:"clientCert,omitempty"` // PEM certificate presented to the server for mutual TLS
+	ClientKey    string `yaml:"clientKey,omitempty"`  // PEM private key of the client certificate
+	Timeout      int    `yaml:"timeout,omitempty"`    // Per request in seconds, 0 means no timeout
+	Debug        bool   `yaml:"debug,omitempty"`
+	Trace        bool   `yaml:"trace,omitempty"` // Include headers and bodies on the debug messages
+	Retries      int    `yaml:"retries,omitempty"`
+	RetryBackoff int    `yaml:"retryBackoff,omitempty"` // Initial delay between retries in milliseconds
+	CacheTTL     int    `yaml:"cacheTTL,omitempty"`     // Seconds the cacheable GET responses are kept, a negative value disables the cache
+	Production   bool   `yaml:"production,omitempty"`   // Disruptive operations (e.x. turning notifications off) ask for confirmation
+	StrictDecode bool   `yaml:"strictDecode,omitempty"` // Responses with unknown fields or empty required fields are rejected
+
+	MaxConnections int   `yaml:"maxConnections,omitempty"` // Idle connections kept open to the server for reuse, 0 means DefaultMaxConnections
+	IdleTimeout    int   `yaml:"idleTimeout,omitempty"`    // Seconds an idle connection is kept open, 0 means DefaultIdleTimeout
+	HTTP2          *bool `yaml:"http2,omitempty"`          // Negotiate HTTP/2 with HTTPS servers, enabled when not set
+
+	Proxy   ProxySettings `yaml:"proxy,omitempty"`
+	NoProxy bool          `yaml:"noProxy,omitempty"` // Connect directly, ignoring the proxies of the settings and the environment
+
+	// Where the password is obtained from when the first request is sent, instead of the password setting:
+	// keyring (the OS keychain), env:VARNAME or cmd:command (its output); the obtained password is never stored
+	PasswordSource string `yaml:"passwordSource,omitempty"`
+
+	EventSink EventSinkSettings `yaml:"eventSink,omitempty"`
+
+	AuditLog string `yaml:"auditLog,omitempty"` // File where a JSON line is appended for every request that modifies the server
+
+	ProfileName   string   `yaml:"-"` // The profile the settings were obtained from
+	ServerVersion *Version `yaml:"-"` // Obtained once through GetServerVersion
+
+	tlsConfig       *tls.Config       // Set through WithTLSConfig, it takes precedence over the certificate files
+	ctx             context.Context   // Used by the requests that don't receive one; set through WithContext
+	log             Logger            // Where the warnings are reported; set through WithLogger
+	cacheRoot       string            // Where the cacheable GET responses are stored, empty disables the cache; set through WithCacheDir
+	transport       http.RoundTripper // Used instead of the shared transport for the settings (e.x. by tests); set through WithTransport
+	setupError      error             // Returned by the first request; set through WithSetupError
+	versionLock     *sync.Mutex       // Serializes the lookups of ServerVersion; set by NewClient and GetProfile
+	insecureWarning *sync.Once        // Shows the warning about insecure connections once; set by NewClient and GetProfile
+	auditWarning    *sync.Once        // Reports once that the audit log cannot be written, instead of failing the operations
 }
 
-func (cli Client) getHTTPClient() *http.Client {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: cli.Insecure},
+// Creates the state shared by the copies of the client
+func (cli *Client) share() {
+	cli.versionLock = &sync.Mutex{}
+	cli.insecureWarning = &sync.Once{}
+	cli.auditWarning = &sync.Once{}
+}
+
+// Shows a warning once per client; clients not created through NewClient or GetProfile show it every time
+func warnOnce(once *sync.Once, warn func()) {
+	if once == nil {
+		warn()
+		return
+	}
+	once.Do(warn)
+}
+
+// EventSinkSettings where the events are sent; the ReST API is used when no sink is defined
+type EventSinkSettings struct {
+	Via     string   `yaml:"via,omitempty"`     // The name of the sink (e.x. rest or kafka)
+	Brokers []string `yaml:"brokers,omitempty"` // The Kafka bootstrap servers, as host:port
+	Topic   string   `yaml:"topic,omitempty"`
+
+	TLS      bool   `yaml:"tls,omitempty"`      // Connect to the Kafka brokers with TLS
+	CACert   string `yaml:"caCert,omitempty"`   // PEM bundle with the CAs trusted to sign the certificates of the brokers
+	SASL     string `yaml:"sasl,omitempty"`     // The SASL mechanism: plain, scram-sha-256 or scram-sha-512
+	Username string `yaml:"username,omitempty"` // The SASL credentials
+	Password string `yaml:"password,omitempty"`
+}
+
+func (cli Client) getHTTPClient() (*http.Client, error) {
+	tr := cli.transport
+	if tr == nil {
+		shared, err := cli.getTransport()
+		if err != nil {
+			return nil, err
+		}
+		tr = shared
 	}
+	warnOnce(cli.insecureWarning, cli.WarnIfInsecure)
 	timeout := time.Duration(cli.Timeout) * time.Second
-	return &http.Client{Transport: tr, Timeout: timeout}
+	if cli.Debug {
+		return &http.Client{Transport: &debugTransport{tr, cli.Trace}, Timeout: timeout}, nil
+	}
+	return &http.Client{Transport: tr, Timeout: timeout}, nil
 }
 
 // Get sends an HTTP GET request
 func (cli Client) Get(path string) ([]byte, error) {
-	request, err := cli.buildRequest(http.MethodGet, cli.URL+path, nil)
-	if err != nil {
-		return nil, err
+	return cli.GetWithContext(cli.Context(), path)
+}
+
+// GetWithContext sends an HTTP GET request that can be cancelled through the context
+func (cli Client) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	return cli.send(ctx, http.MethodGet, path, nil, "")
+}
+
+// Post sends an HTTP POST request
+func (cli Client) Post(path string, jsonBytes []byte) error {
+	return cli.PostWithContext(cli.Context(), path, jsonBytes)
+}
+
+// PostWithContext sends an HTTP POST request that can be cancelled through the context
+func (cli Client) PostWithContext(ctx context.Context, path string, jsonBytes []byte) error {
+	_, err := cli.send(ctx, http.MethodPost, path, jsonBytes, "application/json")
+	return err
+}
+
+// PostWithResponse sends an HTTP POST request and returns the content of the response (e.x. for queries)
+func (cli Client) PostWithResponse(path string, jsonBytes []byte) ([]byte, error) {
+	return cli.send(cli.Context(), http.MethodPost, path, jsonBytes, "application/json")
+}
+
+// Delete sends an HTTP DELETE request
+func (cli Client) Delete(path string) error {
+	return cli.DeleteWithContext(cli.Context(), path)
+}
+
+// DeleteWithContext sends an HTTP DELETE request that can be cancelled through the context
+func (cli Client) DeleteWithContext(ctx context.Context, path string) error {
+	_, err := cli.send(ctx, http.MethodDelete, path, nil, "")
+	return err
+}
+
+// Put sends an HTTP PUT request
+func (cli Client) Put(path string, dataBytes []byte, contentType string) error {
+	return cli.PutWithContext(cli.Context(), path, dataBytes, contentType)
+}
+
+// PutWithContext sends an HTTP PUT request that can be cancelled through the context
+func (cli Client) PutWithContext(ctx context.Context, path string, dataBytes []byte, contentType string) error {
+	_, err := cli.send(ctx, http.MethodPut, path, dataBytes, contentType)
+	return err
+}
+
+// Sends a request, retrying with exponential backoff when allowed;
+// POST requests are only retried when the connection failed before sending any data.
+func (cli Client) send(ctx context.Context, method string, path string, dataBytes []byte, contentType string) (result []byte, err error) {
+	if cli.setupError != nil {
+		return nil, cli.setupError
 	}
-	response, err := cli.getHTTPClient().Do(request)
-	if err != nil {
-		return nil, err
+	status := 0 // Of the last response received, for the audit log
+	if method != http.MethodGet {
+		defer cli.invalidateCached(path)
+		defer func() { cli.audit(method, path, dataBytes, status, err) }()
+	} else if data, ok := cli.getCached(path); ok {
+		if cli.Debug {
+			fmt.Fprintf(debugOutput, "DEBUG > %s %s (cached)\n", method, path)
+		}
+		return data, nil
 	}
-	err = httpIsValid(response)
+	sent := time.Now()
+	var data []byte
+	err = cli.withRetries(ctx, method, path, func(connected *bool) error {
+		response, err := cli.open(ctx, method, path, dataBytes, contentType, connected)
+		if e, ok := err.(*APIError); ok {
+			status = e.StatusCode
+		}
+		if err != nil {
+			return err
+		}
+		status = response.StatusCode
+		defer response.Body.Close()
+		data, err = ioutil.ReadAll(response.Body)
+		if err != nil {
+			return &ConnectionError{Method: method, URL: redactURL(response.Request.URL), Server: cli.serverURL(), Err: err}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	data, err := ioutil.ReadAll(response.Body)
-	if cli.Debug && err == nil {
-		log.Println("Data received", string(data))
+	if method == http.MethodGet {
+		cli.storeCached(path, sent, data)
 	}
-	response.Body.Close()
-	return data, err
+	return data, nil
 }
 
-// Post sends an HTTP POST request
-func (cli Client) Post(path string, jsonBytes []byte) error {
-	if cli.Debug {
-		log.Println("Data to be sent", string(jsonBytes))
+// GetStream sends an HTTP GET request and passes the body of the response to consume while it is received, instead of holding it in memory;
+// the response is not cached, and only the failures before receiving the response are retried
+func (cli Client) GetStream(path string, consume func(body io.Reader) error) error {
+	if cli.setupError != nil {
+		return cli.setupError
 	}
-	request, err := cli.buildRequest(http.MethodPost, cli.URL+path, bytes.NewBuffer(jsonBytes))
+	ctx := cli.Context()
+	var response *http.Response
+	err := cli.withRetries(ctx, http.MethodGet, path, func(connected *bool) error {
+		var err error
+		response, err = cli.open(ctx, http.MethodGet, path, nil, "", connected)
+		return err
+	})
 	if err != nil {
 		return err
 	}
-	request.Header.Set("Content-Type", "application/json")
-	response, err := cli.getHTTPClient().Do(request)
-	if err != nil {
+	defer response.Body.Close()
+	if err := consume(response.Body); err != nil {
+		if ctx.Err() != nil {
+			return ErrCancelled
+		}
 		return err
 	}
-	return httpIsValid(response)
+	return nil
 }
 
-// Delete sends an HTTP DELETE request
-func (cli Client) Delete(path string) error {
-	request, err := cli.buildRequest(http.MethodDelete, cli.URL+path, nil)
+// Runs an attempt until it succeeds, or until it fails with an error that cannot be retried
+func (cli Client) withRetries(ctx context.Context, method string, path string, attempt func(connected *bool) error) error {
+	attempts := 0
+	for {
+		attempts++
+		connected := false
+		err := attempt(&connected)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ErrCancelled
+		}
+		retryable := false
+		switch e := err.(type) {
+		case *APIError:
+			retryable = method != http.MethodPost && isRetryableStatus(e.StatusCode)
+			e.Attempts = attempts
+		case *ConnectionError:
+			retryable = method != http.MethodPost || !connected
+			e.Attempts = attempts
+		}
+		if !retryable || attempts > cli.Retries {
+			return err
+		}
+		delay := cli.getRetryDelay(attempts)
+		cli.logger().Debugf("%s %s failed on attempt %d: %s; retrying in %s", method, RedactContent(path), attempts, RedactContent(err.Error()), delay)
+		select {
+		case <-ctx.Done():
+			return ErrCancelled
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Sends the request, and returns the response when the status is valid; the caller must close its body
+func (cli Client) open(ctx context.Context, method string, path string, dataBytes []byte, contentType string, connected *bool) (*http.Response, error) {
+	var body io.Reader
+	if dataBytes != nil {
+		body = bytes.NewBuffer(dataBytes)
+	}
+	request, err := cli.buildRequest(method, cli.URL+path, body)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	response, err := cli.getHTTPClient().Do(request)
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	reused := false
+	trace := &httptrace.ClientTrace{
+		GotConn: func(connInfo httptrace.GotConnInfo) {
+			*connected = true
+			reused = connInfo.Reused
+			recordConnection(connInfo.Reused)
+		},
+	}
+	request = request.WithContext(httptrace.WithClientTrace(ctx, trace))
+	client, err := cli.getHTTPClient()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	atomic.AddInt64(&stats.requests, 1)
+	response, err := client.Do(request)
+	if err != nil && reused && isClosedByServer(err) {
+		*connected = false // The server closed the idle connection before reading the request, so it can be sent again
+	}
+	if err != nil {
+		return nil, &ConnectionError{Method: method, URL: redactURL(request.URL), Server: cli.serverURL(), Err: err}
 	}
-	return httpIsValid(response)
+	if !isValidStatus(response.StatusCode) {
+		defer response.Body.Close()
+		return nil, newAPIError(request, response)
+	}
+	return response, nil
 }
 
-// Put sends an HTTP PUT request
-func (cli Client) Put(path string, dataBytes []byte, contentType string) error {
-	if cli.Debug {
-		log.Println("Data to be sent", string(dataBytes))
+// Returns the delay before the next attempt, doubling on each attempt and adding up to 50% of jitter
+func (cli Client) getRetryDelay(attempt int) time.Duration {
+	delay := time.Duration(cli.RetryBackoff) * time.Millisecond
+	for i := 1; i < attempt; i++ {
+		delay *= 2
 	}
-	request, err := cli.buildRequest(http.MethodPut, cli.URL+path, bytes.NewBuffer(dataBytes))
-	if err != nil {
-		return err
+	if delay > 1 {
+		delay += time.Duration(rand.Int63n(int64(delay / 2)))
 	}
-	request.Header.Set("Content-Type", contentType)
-	response, err := cli.getHTTPClient().Do(request)
+	return delay
+}
+
+// Returns the base URL of the server without credentials
+func (cli Client) serverURL() string {
+	u, err := url.Parse(cli.URL)
 	if err != nil {
-		return err
+		return cli.URL
 	}
-	return httpIsValid(response)
+	return redactURL(u)
 }
 
 func (cli Client) buildRequest(method, url string, body io.Reader) (*http.Request, error) {
@@ -112,38 +371,27 @@ func (cli Client) buildRequest(method, url string, body io.Reader) (*http.Reques
 	if err != nil {
 		return nil, err
 	}
-	request.Header.Set("Accept", "application/json")
-	request.SetBasicAuth(cli.Username, cli.Password)
-	if cli.Debug {
-		trace := &httptrace.ClientTrace{
-			GotConn: func(connInfo httptrace.GotConnInfo) {
-				log.Println("Got Connection", connInfo)
-			},
-			ConnectStart: func(network, addr string) {
-				log.Println("Dial start", network, addr)
-			},
-			ConnectDone: func(network, addr string, err error) {
-				log.Println("Dial done", network, addr)
-			},
-			GotFirstResponseByte: func() {
-				log.Println("Got first response byte!")
-			},
-			WroteHeaderField: func(key string, value []string) {
-				log.Println("Wrote header", key, value)
-			},
-			WroteRequest: func(wr httptrace.WroteRequestInfo) {
-				log.Println("Wrote request error?", wr.Err)
-			},
-		}
-		request = request.WithContext(httptrace.WithClientTrace(request.Context(), trace))
+	password, err := cli.getPassword()
+	if err != nil {
+		return nil, err
 	}
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(cli.Username, password)
 	return request, nil
 }
 
-func httpIsValid(response *http.Response) error {
-	code := response.StatusCode
-	if code != http.StatusOK && code != http.StatusAccepted && code != http.StatusNoContent {
-		return fmt.Errorf("Invalid Response: %s", response.Status)
+func isValidStatus(code int) bool {
+	return code == http.StatusOK || code == http.StatusCreated || code == http.StatusAccepted || code == http.StatusNoContent
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// Verifies if a request failed because the server closed the connection without responding
+func isClosedByServer(err error) bool {
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
 	}
-	return nil
+	return err == io.EOF || err == io.ErrUnexpectedEOF
 }
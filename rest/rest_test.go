@@ -1,11 +1,15 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 )
@@ -26,8 +30,8 @@ func TestGet(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	Instance.URL = testServer.URL
-	bytes, err := Instance.Get("/user")
+	client := Client{URL: testServer.URL}
+	bytes, err := client.Get("/user")
 
 	assert.NilError(t, err)
 	var user User
@@ -53,11 +57,11 @@ func TestPost(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	Instance.URL = testServer.URL
+	client := Client{URL: testServer.URL}
 	user := User{"Alejandro", "Galue"}
 	bytes, _ := json.Marshal(user)
 
-	err := Instance.Post("/user", bytes)
+	err := client.Post("/user", bytes)
 	assert.NilError(t, err)
 }
 
@@ -69,8 +73,94 @@ func TestDelete(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	Instance.URL = testServer.URL
-	err := Instance.Delete("/user")
+	client := Client{URL: testServer.URL}
+	err := client.Delete("/user")
 
 	assert.NilError(t, err)
 }
+
+// testLogger keeps the warnings and debug messages reported by the client
+type testLogger struct {
+	warnings []string
+	debug    []string
+}
+
+func (l *testLogger) Warnf(format string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) {
+	l.debug = append(l.debug, fmt.Sprintf(format, args...))
+}
+
+func TestRetries(t *testing.T) {
+	log := &testLogger{}
+	calls := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls%3 != 0 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	client := Client{URL: testServer.URL, Timeout: 5, Retries: 2, RetryBackoff: 1, log: log}
+	_, err := client.Get("/user")
+	assert.NilError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 0, len(log.warnings))
+	assert.Equal(t, 2, len(log.debug))
+	assert.Assert(t, strings.HasPrefix(log.debug[0], "GET /user failed on attempt 1: Invalid Response: 503 Service Unavailable; retrying in "), log.debug[0])
+
+	calls = 0
+	err = client.Post("/user", []byte("{}"))
+	assert.Error(t, err, "Invalid Response: 503 Service Unavailable")
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	client.Retries = 1
+	err = client.Delete("/user")
+	assert.Error(t, err, "Invalid Response: 503 Service Unavailable (after 2 attempts)")
+	assert.Equal(t, 2, calls)
+
+	testServer.Close()
+	err = client.Post("/user", []byte("{}"))
+	assert.ErrorContains(t, err, "(after 2 attempts)")
+}
+
+func TestCancelRequest(t *testing.T) {
+	release := make(chan bool)
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			<-release
+		}
+		res.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer testServer.Close()
+	defer close(release)
+
+	client := Client{URL: testServer.URL, Timeout: 5, Retries: 5, RetryBackoff: 60000}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := client.GetWithContext(ctx, "/slow")
+	assert.Equal(t, ErrCancelled, err)
+
+	// Cancelled while waiting for the next attempt
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	err = client.DeleteWithContext(ctx, "/user")
+	assert.Equal(t, ErrCancelled, err)
+	assert.Assert(t, time.Since(start) < 10*time.Second)
+
+	// The context of the client is used by the methods that don't receive one
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	client.ctx = ctx
+	_, err = client.Get("/user")
+	assert.Equal(t, ErrCancelled, err)
+	assert.Equal(t, ErrCancelled, client.Sleep(time.Minute))
+	assert.Equal(t, ErrCancelled, err)
+}
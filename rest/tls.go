@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// InsecureWarning the message displayed every time the certificate validation is disabled
+const InsecureWarning = "HTTPS certificate validation is disabled (--insecure); the connection to the server can be intercepted"
+
+// ValidateTLS verifies that the certificate files can be loaded, returning an actionable error otherwise
+func (cli Client) ValidateTLS() error {
+	_, err := cli.getTLSConfig()
+	return err
+}
+
+// WarnIfInsecure reports a warning through the logger of the client when the certificate validation is disabled
+func (cli Client) WarnIfInsecure() {
+	if cli.Insecure || (cli.tlsConfig != nil && cli.tlsConfig.InsecureSkipVerify) {
+		cli.logger().Warnf("%s", InsecureWarning)
+	}
+}
+
+// Builds the TLS configuration from the custom CA bundle and the client certificate (for mutual TLS)
+func (cli Client) getTLSConfig() (*tls.Config, error) {
+	if cli.tlsConfig != nil {
+		return cli.tlsConfig.Clone(), nil
+	}
+	config := &tls.Config{InsecureSkipVerify: cli.Insecure}
+	if cli.CACert != "" {
+		data, err := ioutil.ReadFile(cli.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificate %s: %w", cli.CACert, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("could not parse PEM in %s: no valid certificates found", cli.CACert)
+		}
+		config.RootCAs = pool
+	}
+	if cli.ClientCert == "" && cli.ClientKey == "" {
+		return config, nil
+	}
+	if cli.ClientCert == "" || cli.ClientKey == "" {
+		return nil, fmt.Errorf("both the client certificate and its private key are required (--client-cert and --client-key)")
+	}
+	for _, file := range []string{cli.ClientCert, cli.ClientKey} {
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", file, err)
+		}
+	}
+	cert, err := tls.LoadX509KeyPair(cli.ClientCert, cli.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse PEM in %s or %s: %w", cli.ClientCert, cli.ClientKey, err)
+	}
+	config.Certificates = []tls.Certificate{cert}
+	return config, nil
+}
+
+// GetTLSConfig builds the TLS configuration of the connections to the brokers of the event sink, or returns nil without TLS
+func (s EventSinkSettings) GetTLSConfig() (*tls.Config, error) {
+	if !s.TLS {
+		return nil, nil
+	}
+	return Client{CACert: s.CACert}.getTLSConfig()
+}
@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+// Writes a self-signed client certificate and its private key as PEM files
+func createClientCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "onmsctl"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	assert.NilError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NilError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+// Writes the certificate of the test server as a PEM file, to be used as the CA bundle
+func writeServerCA(t *testing.T, server *httptest.Server, dir string) string {
+	caFile := filepath.Join(dir, "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NilError(t, ioutil.WriteFile(caFile, data, 0600))
+	return caFile
+}
+
+func TestCustomCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := Client{URL: server.URL, Timeout: 5}
+	_, err = client.Get("/")
+	assert.ErrorContains(t, err, "certificate")
+
+	client.CACert = writeServerCA(t, server, dir)
+	assert.NilError(t, client.ValidateTLS())
+	_, err = client.Get("/")
+	assert.NilError(t, err)
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	assert.NilError(t, ioutil.WriteFile(invalid, []byte("not a certificate"), 0600))
+	client.CACert = invalid
+	assert.Error(t, client.ValidateTLS(), "could not parse PEM in "+invalid+": no valid certificates found")
+
+	client.CACert = filepath.Join(dir, "missing.pem")
+	assert.ErrorContains(t, client.ValidateTLS(), "could not read CA certificate "+client.CACert)
+}
+
+func TestClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	var presented string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		presented = req.TLS.PeerCertificates[0].Subject.CommonName
+		res.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	client := Client{URL: server.URL, Timeout: 5, CACert: writeServerCA(t, server, dir)}
+	_, err = client.Get("/")
+	assert.Assert(t, err != nil)
+
+	client.ClientCert, client.ClientKey = createClientCertificate(t, dir)
+	assert.NilError(t, client.ValidateTLS())
+	_, err = client.Get("/")
+	assert.NilError(t, err)
+	assert.Equal(t, "onmsctl", presented)
+
+	client.ClientKey = ""
+	assert.ErrorContains(t, client.ValidateTLS(), "both the client certificate and its private key are required")
+
+	client.ClientKey = client.CACert
+	assert.ErrorContains(t, client.ValidateTLS(), "could not parse PEM in")
+}
+
+func TestInsecureWarning(t *testing.T) {
+	log := &testLogger{}
+
+	Client{log: log}.WarnIfInsecure()
+	assert.Equal(t, 0, len(log.warnings))
+
+	Client{Insecure: true, log: log}.WarnIfInsecure()
+	Client{Insecure: true, log: log}.WarnIfInsecure()
+	assert.DeepEqual(t, []string{InsecureWarning, InsecureWarning}, log.warnings)
+}
@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxConnections the default number of idle connections kept open per server, to reuse them on the next requests
+const DefaultMaxConnections = 10
+
+// DefaultIdleTimeout the default time in seconds an idle connection is kept open
+const DefaultIdleTimeout = 90
+
+// The transports shared by all the requests, one per combination of TLS, pool and proxy settings (e.x. per profile),
+// so the connections are reused instead of opening a new TCP and TLS session per request
+var transports = struct {
+	sync.Mutex
+	pool map[string]*http.Transport
+}{pool: make(map[string]*http.Transport)}
+
+// The counters of the requests sent by the process, reported under --debug
+var stats = struct {
+	requests    int64
+	connections int64
+	reused      int64
+	started     time.Time
+}{started: time.Now()}
+
+// RequestStats the summary of the requests sent by the process, to verify that connections are reused
+type RequestStats struct {
+	Requests    int64         // Every attempt, including retries
+	Connections int64         // The connections opened
+	Reused      int64         // The requests sent through an existing connection
+	Elapsed     time.Duration // Wall time since the process started
+}
+
+// ReuseRatio returns the fraction of requests that reused a connection
+func (s RequestStats) ReuseRatio() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Reused) / float64(s.Requests)
+}
+
+func (s RequestStats) String() string {
+	return fmt.Sprintf("%d requests, %d connections opened, %.1f%% reused, %s total", s.Requests, s.Connections, s.ReuseRatio()*100, s.Elapsed.Round(time.Millisecond))
+}
+
+// GetStats returns the summary of the requests sent so far
+func GetStats() RequestStats {
+	return RequestStats{
+		Requests:    atomic.LoadInt64(&stats.requests),
+		Connections: atomic.LoadInt64(&stats.connections),
+		Reused:      atomic.LoadInt64(&stats.reused),
+		Elapsed:     time.Since(stats.started),
+	}
+}
+
+func resetStats() {
+	atomic.StoreInt64(&stats.requests, 0)
+	atomic.StoreInt64(&stats.connections, 0)
+	atomic.StoreInt64(&stats.reused, 0)
+	stats.started = time.Now()
+}
+
+// Records a connection obtained for a request
+func recordConnection(reused bool) {
+	if reused {
+		atomic.AddInt64(&stats.reused, 1)
+	} else {
+		atomic.AddInt64(&stats.connections, 1)
+	}
+}
+
+// Returns the shared transport for the TLS, pool and proxy settings of the client, creating it on first use
+func (cli Client) getTransport() (*http.Transport, error) {
+	key := fmt.Sprintf("%t|%s|%s|%s|%d|%d|%t", cli.Insecure, cli.CACert, cli.ClientCert, cli.ClientKey, cli.maxConnections(), cli.idleTimeout(), cli.http2Enabled())
+	if cli.tlsConfig != nil {
+		key = fmt.Sprintf("%p|%d|%d|%t", cli.tlsConfig, cli.maxConnections(), cli.idleTimeout(), cli.http2Enabled())
+	}
+	key += fmt.Sprintf("|%t|%s|%s|%s", cli.NoProxy, cli.Proxy.HTTP, cli.Proxy.HTTPS, cli.Proxy.SOCKS5)
+	transports.Lock()
+	defer transports.Unlock()
+	if tr, ok := transports.pool[key]; ok {
+		return tr, nil
+	}
+	tlsConfig, err := cli.getTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	tr := &http.Transport{
+		Proxy:               func(request *http.Request) (*url.URL, error) { return cli.ProxyURL(request.URL) },
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        cli.maxConnections(),
+		MaxIdleConnsPerHost: cli.maxConnections(),
+		IdleConnTimeout:     cli.idleTimeout(),
+		ForceAttemptHTTP2:   cli.http2Enabled(),
+	}
+	if !cli.http2Enabled() {
+		// A non-nil empty map disables the HTTP/2 upgrade through ALPN
+		tr.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	transports.pool[key] = tr
+	return tr, nil
+}
+
+func (cli Client) maxConnections() int {
+	if cli.MaxConnections == 0 {
+		return DefaultMaxConnections
+	}
+	return cli.MaxConnections
+}
+
+func (cli Client) idleTimeout() time.Duration {
+	if cli.IdleTimeout == 0 {
+		return DefaultIdleTimeout * time.Second
+	}
+	return time.Duration(cli.IdleTimeout) * time.Second
+}
+
+func (cli Client) http2Enabled() bool {
+	return cli.HTTP2 == nil || *cli.HTTP2
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestConnectionReuse(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		res.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "onmsctl")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	client := Client{URL: server.URL, Timeout: 5, CACert: writeServerCA(t, server, dir)}
+	resetStats()
+	for i := 0; i < 20; i++ {
+		assert.NilError(t, client.Post("/rest/requisitions/Test/nodes", []byte(fmt.Sprintf(`{"foreign-id":"n%d"}`, i))))
+	}
+	stats := GetStats()
+	assert.Equal(t, int64(20), stats.Requests)
+	assert.Equal(t, int64(1), stats.Connections)
+	assert.Equal(t, int64(19), stats.Reused)
+	assert.Equal(t, 0.95, stats.ReuseRatio())
+}
+
+func TestSharedTransport(t *testing.T) {
+	disabled := false
+	client := Client{URL: "https://onms.example.com/opennms", MaxConnections: 4, HTTP2: &disabled}
+	tr, err := client.getTransport()
+	assert.NilError(t, err)
+	assert.Equal(t, 4, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, false, tr.ForceAttemptHTTP2)
+	assert.Assert(t, tr.TLSNextProto != nil)
+
+	same, err := Client{URL: "https://other.example.com/opennms", MaxConnections: 4, HTTP2: &disabled}.getTransport()
+	assert.NilError(t, err)
+	assert.Assert(t, tr == same)
+
+	other, err := Client{URL: "https://onms.example.com/opennms"}.getTransport()
+	assert.NilError(t, err)
+	assert.Assert(t, tr != other)
+	assert.Equal(t, DefaultMaxConnections, other.MaxIdleConnsPerHost)
+	assert.Equal(t, true, other.ForceAttemptHTTP2)
+
+	_, err = Client{CACert: "/unknown/ca.pem"}.getTransport()
+	assert.ErrorContains(t, err, "could not read CA certificate /unknown/ca.pem")
+}
+
+// Sends 1,000 small node POSTs per iteration through HTTPS, comparing the shared transport with a new connection per request;
+// run with: go test ./rest -run none -bench PostNodes
+func BenchmarkPostNodes(b *testing.B) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		res.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	node := []byte(`{"foreign-id":"n1","node-label":"n1","interface":[{"ip-addr":"10.0.0.1"}]}`)
+	client := Client{URL: server.URL, Timeout: 5, Insecure: true}
+
+	run := func(b *testing.B) {
+		resetStats()
+		for i := 0; i < b.N; i++ {
+			for n := 0; n < 1000; n++ {
+				if err := client.Post("/rest/requisitions/Test/nodes", node); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+		b.ReportMetric(GetStats().ReuseRatio()*100, "%reused")
+	}
+	b.Run("shared", run)
+	b.Run("new-connection", func(b *testing.B) {
+		saved := client
+		defer func() { client = saved }()
+		client.transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, DisableKeepAlives: true}
+		run(b)
+	})
+}
@@ -0,0 +1,169 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// The Horizon release each Meridian release is based on, by year; newer years are assumed to be based on a newer Horizon
+var meridianReleases = []struct {
+	Year    int
+	Horizon int
+}{
+	{2015, 16},
+	{2016, 17},
+	{2017, 20},
+	{2018, 21},
+	{2019, 24},
+	{2020, 26},
+	{2021, 27},
+	{2022, 29},
+	{2023, 31},
+}
+
+var versionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-(\S+))?$`)
+
+// Version the version of an OpenNMS server, either Horizon (e.x. 26.1.0) or Meridian (date-based, e.x. 2020.1.5)
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string // e.x. SNAPSHOT
+}
+
+// ParseVersion parses a version like 26.0.0, 27.0.0-SNAPSHOT, 2019.1.10 or 26 (missing numbers are zero)
+func ParseVersion(value string) (*Version, error) {
+	parts := versionPattern.FindStringSubmatch(value)
+	if parts == nil {
+		return nil, fmt.Errorf("Invalid version %s", value)
+	}
+	v := &Version{PreRelease: parts[4]}
+	v.Major, _ = strconv.Atoi(parts[1])
+	v.Minor, _ = strconv.Atoi(parts[2])
+	v.Patch, _ = strconv.Atoi(parts[3])
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	if v.IsMeridian() {
+		s += fmt.Sprintf(" (Horizon %d)", v.Horizon().Major)
+	}
+	return s
+}
+
+// IsMeridian returns true for date-based versions
+func (v Version) IsMeridian() bool {
+	return v.Major >= 2000
+}
+
+// Horizon returns the Horizon release a Meridian version is based on, or the version itself for Horizon;
+// as Meridian updates only contain fixes, they are considered equivalent to the first release of the Horizon major version
+func (v Version) Horizon() Version {
+	if !v.IsMeridian() {
+		return v
+	}
+	horizon := meridianReleases[0].Horizon
+	for _, r := range meridianReleases {
+		if v.Major >= r.Year {
+			horizon = r.Horizon
+		}
+	}
+	if last := meridianReleases[len(meridianReleases)-1]; v.Major > last.Year {
+		horizon = last.Horizon + v.Major - last.Year
+	}
+	return Version{Major: horizon}
+}
+
+// Compare returns -1, 0 or 1 when the version is older, equal or newer than the other one;
+// Meridian versions are compared through their Horizon equivalent, unless both are Meridian,
+// and a pre-release is older than the release
+func (v Version) Compare(other Version) int {
+	a, b := v, other
+	if a.IsMeridian() != b.IsMeridian() {
+		a, b = a.Horizon(), b.Horizon()
+	}
+	for _, d := range []int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case a.PreRelease == b.PreRelease:
+		return 0
+	case a.PreRelease == "":
+		return 1
+	case b.PreRelease == "":
+		return -1
+	case a.PreRelease < b.PreRelease:
+		return -1
+	}
+	return 1
+}
+
+// AtLeast returns true when the version is equal or newer than the required one;
+// the pre-release is ignored, as snapshots usually contain the features of the release they precede
+func (v Version) AtLeast(required Version) bool {
+	v.PreRelease = ""
+	required.PreRelease = ""
+	return v.Compare(required) >= 0
+}
+
+// GetServerVersion returns the version of the server, obtained from /rest/info only the first time;
+// concurrent lookups are serialized for the clients created through NewClient or GetProfile
+func (cli *Client) GetServerVersion() (*Version, error) {
+	if cli.versionLock != nil {
+		cli.versionLock.Lock()
+		defer cli.versionLock.Unlock()
+	}
+	if cli.ServerVersion != nil {
+		return cli.ServerVersion, nil
+	}
+	data, err := cli.Get("/rest/info")
+	if err != nil {
+		return nil, err
+	}
+	info := struct {
+		Version string `json:"version"`
+	}{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	version, err := ParseVersion(info.Version)
+	if err != nil {
+		return nil, err
+	}
+	cli.ServerVersion = version
+	return version, nil
+}
+
+// MinServerVersion returns an error when the server of the client is older than the required version,
+// so commands can fail fast instead of getting a 404 from an endpoint that doesn't exist yet
+func (cli *Client) MinServerVersion(required string, feature string) error {
+	min, err := ParseVersion(required)
+	if err != nil {
+		return err
+	}
+	version, err := cli.GetServerVersion()
+	var e *APIError
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s requires OpenNMS >= %s, and the server doesn't report its version", feature, min)
+	}
+	if err != nil {
+		return err
+	}
+	if !version.AtLeast(*min) {
+		return fmt.Errorf("%s requires OpenNMS >= %s, the server runs %s", feature, min, version)
+	}
+	return nil
+}
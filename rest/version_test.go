@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("27.0.0-SNAPSHOT")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, &Version{Major: 27, PreRelease: "SNAPSHOT"}, v)
+	assert.Equal(t, "27.0.0-SNAPSHOT", v.String())
+
+	v, err = ParseVersion("26")
+	assert.NilError(t, err)
+	assert.Equal(t, "26.0.0", v.String())
+
+	v, err = ParseVersion("2019.1.10")
+	assert.NilError(t, err)
+	assert.Assert(t, v.IsMeridian())
+	assert.Equal(t, "2019.1.10 (Horizon 24)", v.String())
+
+	_, err = ParseVersion("Horizon 26")
+	assert.Error(t, err, "Invalid version Horizon 26")
+	_, err = ParseVersion("")
+	assert.Error(t, err, "Invalid version ")
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b    string
+		compare int
+		atLeast bool
+	}{
+		{"26.0.0", "26.0.0", 0, true},
+		{"26.1.0", "26.0.5", 1, true},
+		{"24.1.2", "26.0.0", -1, false},
+		{"26.0.10", "26.0.9", 1, true},
+		{"27.0.0-SNAPSHOT", "27.0.0", -1, true},
+		{"27.0.0-RC1", "27.0.0-RC2", -1, true},
+		{"2019.1.10", "26.0.0", -1, false},
+		{"2020.1.0", "26.0.0", 0, true},
+		{"2020.1.5", "26.1.0", -1, false},
+		{"2023.1.0", "29.0.0", 1, true},
+		{"2030.1.0", "40.0.0", -1, false},
+		{"2021.1.2", "2021.1.10", -1, false},
+		{"26.0.0", "2019.1.0", 1, true},
+	}
+	for _, tc := range testCases {
+		a, err := ParseVersion(tc.a)
+		assert.NilError(t, err)
+		b, err := ParseVersion(tc.b)
+		assert.NilError(t, err)
+		assert.Equal(t, tc.compare, a.Compare(*b), "%s vs %s", tc.a, tc.b)
+		assert.Equal(t, tc.atLeast, a.AtLeast(*b), "%s at least %s", tc.a, tc.b)
+	}
+}
+
+func TestMinServerVersion(t *testing.T) {
+	requests := 0
+	version := "24.1.2"
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests++
+		if version == "" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		res.Write([]byte(`{"displayVersion":"` + version + `","version":"` + version + `","packageName":"opennms"}`))
+	}))
+	defer server.Close()
+	client := &Client{URL: server.URL, Timeout: 5}
+
+	assert.Error(t, client.MinServerVersion("26.0.0", "Checking the reload state"), "Checking the reload state requires OpenNMS >= 26.0.0, the server runs 24.1.2")
+	assert.NilError(t, client.MinServerVersion("22.0.0", "The v2 API"))
+	assert.Equal(t, 1, requests) // The version is obtained once
+	assert.Equal(t, "24.1.2", client.ServerVersion.String())
+	assert.ErrorContains(t, client.MinServerVersion("latest", "Anything"), "Invalid version latest")
+
+	version = ""
+	client.ServerVersion = nil
+	assert.Error(t, client.MinServerVersion("26.0.0", "Checking the reload state"), "Checking the reload state requires OpenNMS >= 26.0.0, and the server doesn't report its version")
+}
+
+// Run with -race to verify that the version of a client is looked up once, without a lock shared by all the clients
+func TestConcurrentServerVersion(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		res.Write([]byte(`{"version":"26.1.0"}`))
+	}))
+	defer server.Close()
+
+	clients := make([]*Client, 2)
+	for i := range clients {
+		client, err := NewClient(WithURL(server.URL))
+		assert.NilError(t, err)
+		clients[i] = client
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(client *Client) {
+			defer wg.Done()
+			version, err := client.GetServerVersion()
+			assert.Check(t, err)
+			assert.Check(t, version != nil && version.Major == 26)
+		}(clients[i%2])
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type alarmsAPI struct {
+	rest api.RestAPI
+}
+
+// GetAlarmsAPI Obtain an implementation of the Alarms API
+func GetAlarmsAPI(rest api.RestAPI) api.AlarmsAPI {
+	return &alarmsAPI{rest}
+}
+
+func (api alarmsAPI) GetAlarms(filter string, limit int, offset int) (*model.OnmsAlarmList, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("Limit cannot be negative")
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("Offset cannot be negative")
+	}
+	path := fmt.Sprintf("/api/v2/alarms?limit=%d&offset=%d", limit, offset)
+	if filter != "" {
+		path += "&_s=" + url.QueryEscape(filter)
+	}
+	jsonBytes, err := api.rest.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	list := &model.OnmsAlarmList{}
+	if len(jsonBytes) == 0 { // The v2 API returns no content when there are no matches
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetAllAlarms returns all the alarms matching the filter, requested in pages
+func (api alarmsAPI) GetAllAlarms(filter string) (*model.OnmsAlarmList, error) {
+	list := &model.OnmsAlarmList{Alarms: make([]model.OnmsAlarm, 0)}
+	err := GetPagerAPI(api.rest).ForEachPage("/api/v2/alarms", filterParams(filter), DefaultPageSize, func(body []byte) (int, error) {
+		page := &model.OnmsAlarmList{}
+		if err := getDecoder(api.rest).DecodeJSON(body, page); err != nil {
+			return 0, err
+		}
+		list.Alarms = append(list.Alarms, page.Alarms...)
+		return len(page.Alarms), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	list.Count = len(list.Alarms)
+	list.TotalCount = list.Count
+	return list, nil
+}
+
+func (api alarmsAPI) GetAlarm(id int) (*model.OnmsAlarm, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("Valid alarm ID required")
+	}
+	list, err := api.GetAlarms(fmt.Sprintf("alarm.id==%d", id), 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Alarms) == 0 {
+		return nil, fmt.Errorf("Alarm %d doesn't exist", id)
+	}
+	return &list.Alarms[0], nil
+}
+
+func (api alarmsAPI) AcknowledgeAlarm(id int, user string) error {
+	return api.updateAlarm(id, "ack", "true", user)
+}
+
+func (api alarmsAPI) UnacknowledgeAlarm(id int, user string) error {
+	return api.updateAlarm(id, "ack", "false", user)
+}
+
+func (api alarmsAPI) ClearAlarm(id int, user string) error {
+	return api.updateAlarm(id, "clear", "true", user)
+}
+
+func (api alarmsAPI) EscalateAlarm(id int, user string) error {
+	return api.updateAlarm(id, "escalate", "true", user)
+}
+
+func (api alarmsAPI) SetJournalMemo(id int, user string, body string) error {
+	return api.setMemo(id, "journal", user, body)
+}
+
+func (api alarmsAPI) DeleteJournalMemo(id int) error {
+	return api.deleteMemo(id, "journal")
+}
+
+func (api alarmsAPI) SetStickyMemo(id int, user string, body string) error {
+	return api.setMemo(id, "memo", user, body)
+}
+
+func (api alarmsAPI) DeleteStickyMemo(id int) error {
+	return api.deleteMemo(id, "memo")
+}
+
+func (api alarmsAPI) CreateTicket(id int) error {
+	return api.ticketAction(id, "create")
+}
+
+func (api alarmsAPI) UpdateTicket(id int) error {
+	return api.ticketAction(id, "update")
+}
+
+func (api alarmsAPI) CloseTicket(id int) error {
+	return api.ticketAction(id, "close")
+}
+
+// Requests an action to the trouble ticketer of the server, which is processed asynchronously
+func (api alarmsAPI) ticketAction(id int, action string) error {
+	if id <= 0 {
+		return fmt.Errorf("Valid alarm ID required")
+	}
+	return api.rest.Post(fmt.Sprintf("/api/v2/alarms/%d/ticket/%s", id, action), nil)
+}
+
+func (api alarmsAPI) setMemo(id int, kind string, user string, body string) error {
+	if id <= 0 {
+		return fmt.Errorf("Valid alarm ID required")
+	}
+	if body == "" {
+		return fmt.Errorf("Memo body required")
+	}
+	params := url.Values{}
+	params.Set("body", body)
+	if user != "" {
+		params.Set("user", user)
+	}
+	return api.rest.Put(fmt.Sprintf("/api/v2/alarms/%d/%s", id, kind), []byte(params.Encode()), "application/x-www-form-urlencoded")
+}
+
+func (api alarmsAPI) deleteMemo(id int, kind string) error {
+	if id <= 0 {
+		return fmt.Errorf("Valid alarm ID required")
+	}
+	return api.rest.Delete(fmt.Sprintf("/api/v2/alarms/%d/%s", id, kind))
+}
+
+func (api alarmsAPI) updateAlarm(id int, action string, value string, user string) error {
+	if id <= 0 {
+		return fmt.Errorf("Valid alarm ID required")
+	}
+	params := url.Values{}
+	params.Set(action, value)
+	if user != "" {
+		params.Set("ackUser", user)
+	}
+	return api.rest.Put(fmt.Sprintf("/api/v2/alarms/%d", id), []byte(params.Encode()), "application/x-www-form-urlencoded")
+}
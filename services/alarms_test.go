@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+var mockAlarms = &model.OnmsAlarmList{
+	Count:      1,
+	TotalCount: 1,
+	Alarms: []model.OnmsAlarm{
+		{ID: 10, UEI: "uei.opennms.org/nodes/nodeDown", Severity: "MAJOR", Count: 2, LogMessage: "Node is down"},
+	},
+}
+
+type mockAlarmsRest struct {
+	test        *testing.T
+	lastPath    string
+	lastData    string
+	lastDeleted string
+}
+
+func (api *mockAlarmsRest) Get(path string) ([]byte, error) {
+	api.lastPath = path
+	assert.Assert(api.test, strings.HasPrefix(path, "/api/v2/alarms"))
+	bytes, _ := json.Marshal(mockAlarms)
+	return bytes, nil
+}
+
+func (api *mockAlarmsRest) Post(path string, jsonBytes []byte) error {
+	api.lastPath = path
+	api.lastData = string(jsonBytes)
+	return nil
+}
+
+func (api *mockAlarmsRest) Delete(path string) error {
+	api.lastDeleted = path
+	return nil
+}
+
+func (api *mockAlarmsRest) Put(path string, dataBytes []byte, contentType string) error {
+	assert.Equal(api.test, "application/x-www-form-urlencoded", contentType)
+	api.lastPath = path
+	api.lastData = string(dataBytes)
+	return nil
+}
+
+func TestGetAlarms(t *testing.T) {
+	rest := &mockAlarmsRest{test: t}
+	api := GetAlarmsAPI(rest)
+
+	list, err := api.GetAlarms("alarm.severity==MAJOR", 10, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/alarms?limit=10&offset=0&_s=alarm.severity%3D%3DMAJOR", rest.lastPath)
+	assert.Equal(t, 1, len(list.Alarms))
+	assert.Equal(t, 10, list.Alarms[0].ID)
+
+	_, err = api.GetAlarms("", 10, -1)
+	assert.ErrorContains(t, err, "Offset")
+}
+
+func TestUpdateAlarms(t *testing.T) {
+	rest := &mockAlarmsRest{test: t}
+	api := GetAlarmsAPI(rest)
+
+	assert.NilError(t, api.AcknowledgeAlarm(10, "admin"))
+	assert.Equal(t, "/api/v2/alarms/10", rest.lastPath)
+	assert.Equal(t, "ack=true&ackUser=admin", rest.lastData)
+
+	assert.NilError(t, api.UnacknowledgeAlarm(10, "admin"))
+	assert.Equal(t, "ack=false&ackUser=admin", rest.lastData)
+
+	assert.NilError(t, api.ClearAlarm(10, "admin"))
+	assert.Equal(t, "ackUser=admin&clear=true", rest.lastData)
+
+	assert.NilError(t, api.EscalateAlarm(10, ""))
+	assert.Equal(t, "escalate=true", rest.lastData)
+
+	assert.Error(t, api.AcknowledgeAlarm(0, "admin"), "Valid alarm ID required")
+
+	assert.NilError(t, api.SetJournalMemo(10, "admin", "maintenance window"))
+	assert.Equal(t, "/api/v2/alarms/10/journal", rest.lastPath)
+	assert.Equal(t, "body=maintenance+window&user=admin", rest.lastData)
+
+	assert.Error(t, api.SetJournalMemo(10, "admin", ""), "Memo body required")
+}
+
+func TestAlarmMemos(t *testing.T) {
+	rest := &mockAlarmsRest{test: t}
+	api := GetAlarmsAPI(rest)
+
+	alarm, err := api.GetAlarm(10)
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/alarms?limit=1&offset=0&_s=alarm.id%3D%3D10", rest.lastPath)
+	assert.Equal(t, 10, alarm.ID)
+
+	assert.NilError(t, api.SetStickyMemo(10, "jdoe", "Waiting for the ISP"))
+	assert.Equal(t, "/api/v2/alarms/10/memo", rest.lastPath)
+	assert.Equal(t, "body=Waiting+for+the+ISP&user=jdoe", rest.lastData)
+	assert.Error(t, api.SetStickyMemo(10, "jdoe", ""), "Memo body required")
+
+	assert.NilError(t, api.DeleteStickyMemo(10))
+	assert.Equal(t, "/api/v2/alarms/10/memo", rest.lastDeleted)
+	assert.NilError(t, api.DeleteJournalMemo(10))
+	assert.Equal(t, "/api/v2/alarms/10/journal", rest.lastDeleted)
+	assert.Error(t, api.DeleteJournalMemo(0), "Valid alarm ID required")
+}
+
+func TestAlarmTickets(t *testing.T) {
+	rest := &mockAlarmsRest{test: t}
+	api := GetAlarmsAPI(rest)
+
+	assert.NilError(t, api.CreateTicket(10))
+	assert.Equal(t, "/api/v2/alarms/10/ticket/create", rest.lastPath)
+	assert.NilError(t, api.UpdateTicket(10))
+	assert.Equal(t, "/api/v2/alarms/10/ticket/update", rest.lastPath)
+	assert.NilError(t, api.CloseTicket(10))
+	assert.Equal(t, "/api/v2/alarms/10/ticket/close", rest.lastPath)
+	assert.Error(t, api.CreateTicket(-1), "Valid alarm ID required")
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type businessServicesAPI struct {
+	rest api.RestAPI
+}
+
+// GetBusinessServicesAPI Obtain an implementation of the Business Services API
+func GetBusinessServicesAPI(rest api.RestAPI) api.BusinessServicesAPI {
+	return &businessServicesAPI{rest}
+}
+
+func (api businessServicesAPI) GetBusinessServices() ([]model.BusinessService, error) {
+	jsonBytes, err := api.rest.Get("/api/v2/business-services")
+	if err != nil {
+		return nil, err
+	}
+	services := make([]model.BusinessService, 0)
+	if len(jsonBytes) == 0 { // The v2 API returns no content when there are no business services
+		return services, nil
+	}
+	list := &model.BusinessServiceList{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	for _, loc := range list.Services {
+		id, err := strconv.Atoi(path.Base(loc.Location))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid business service location %s", loc.Location)
+		}
+		bs, err := api.GetBusinessService(id)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, *bs)
+	}
+	return services, nil
+}
+
+func (api businessServicesAPI) GetBusinessService(id int) (*model.BusinessService, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("Valid business service ID required")
+	}
+	jsonBytes, err := api.rest.Get(fmt.Sprintf("/api/v2/business-services/%d", id))
+	if err != nil {
+		return nil, fmt.Errorf("Cannot retrieve business service %d: %w", id, err)
+	}
+	bs := &model.BusinessService{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, bs); err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+// FindBusinessService returns the business service with the given name, or nil if it doesn't exist
+func (api businessServicesAPI) FindBusinessService(name string) (*model.BusinessService, error) {
+	services, err := api.GetBusinessServices()
+	if err != nil {
+		return nil, err
+	}
+	for _, bs := range services {
+		if bs.Name == name {
+			return &bs, nil
+		}
+	}
+	return nil, nil
+}
+
+// SetBusinessService creates the business service, or updates it when the ID is provided
+// or when a business service with the same name already exists
+func (api businessServicesAPI) SetBusinessService(bs model.BusinessService) error {
+	if err := bs.Validate(); err != nil {
+		return err
+	}
+	if bs.ID == 0 {
+		current, err := api.FindBusinessService(bs.Name)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			bs.ID = current.ID
+		}
+	}
+	jsonBytes, err := json.Marshal(toBusinessServiceRequest(bs))
+	if err != nil {
+		return err
+	}
+	if bs.ID == 0 {
+		return api.rest.Post("/api/v2/business-services", jsonBytes)
+	}
+	return api.rest.Put(fmt.Sprintf("/api/v2/business-services/%d", bs.ID), jsonBytes, "application/json")
+}
+
+func (api businessServicesAPI) DeleteBusinessService(id int) error {
+	if id <= 0 {
+		return fmt.Errorf("Valid business service ID required")
+	}
+	return api.rest.Delete(fmt.Sprintf("/api/v2/business-services/%d", id))
+}
+
+func (api businessServicesAPI) AddIPServiceEdge(id int, edge model.IPServiceEdge) error {
+	if err := edge.Validate(); err != nil {
+		return err
+	}
+	edge.IPService = nil
+	return api.addEdge(id, "ip-service-edge", edge)
+}
+
+func (api businessServicesAPI) AddReductionKeyEdge(id int, edge model.ReductionKeyEdge) error {
+	if err := edge.Validate(); err != nil {
+		return err
+	}
+	return api.addEdge(id, "reduction-key-edge", edge)
+}
+
+func (api businessServicesAPI) AddChildEdge(id int, edge model.ChildEdge) error {
+	if err := edge.Validate(); err != nil {
+		return err
+	}
+	if edge.ChildID == id {
+		return fmt.Errorf("A business service cannot be a child of itself")
+	}
+	return api.addEdge(id, "child-edge", edge)
+}
+
+func (api businessServicesAPI) addEdge(id int, edgeType string, edge interface{}) error {
+	if id <= 0 {
+		return fmt.Errorf("Valid business service ID required")
+	}
+	jsonBytes, err := json.Marshal(edge)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post(fmt.Sprintf("/api/v2/business-services/%d/%s", id, edgeType), jsonBytes)
+}
+
+// Removes the content that is only available on responses, as the server rejects it on requests
+func toBusinessServiceRequest(bs model.BusinessService) model.BusinessService {
+	request := model.BusinessService{
+		Name:           bs.Name,
+		Attributes:     bs.Attributes,
+		ReduceFunction: bs.ReduceFunction,
+	}
+	for _, e := range bs.IPServiceEdges {
+		request.IPServiceEdges = append(request.IPServiceEdges, model.IPServiceEdge{
+			IPServiceID:  e.IPServiceID,
+			MapFunction:  e.MapFunction,
+			Weight:       e.Weight,
+			FriendlyName: e.FriendlyName,
+		})
+	}
+	for _, e := range bs.ReductionKeyEdges {
+		request.ReductionKeyEdges = append(request.ReductionKeyEdges, model.ReductionKeyEdge{
+			ReductionKey: e.ReductionKey,
+			MapFunction:  e.MapFunction,
+			Weight:       e.Weight,
+			FriendlyName: e.FriendlyName,
+		})
+	}
+	for _, e := range bs.ChildEdges {
+		request.ChildEdges = append(request.ChildEdges, model.ChildEdge{
+			ChildID:     e.ChildID,
+			MapFunction: e.MapFunction,
+			Weight:      e.Weight,
+		})
+	}
+	return request
+}
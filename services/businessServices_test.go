@@ -0,0 +1,125 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+var mockBusinessService = model.BusinessService{
+	ID:                1,
+	Name:              "Web Portal",
+	ReduceFunction:    model.BSMFunction{Type: "HighestSeverity"},
+	OperationalStatus: "Normal",
+	IPServiceEdges: []model.IPServiceEdge{
+		{
+			ID:                5,
+			IPService:         &model.BSMIPService{ID: 10, ServiceName: "HTTP", NodeLabel: "web01", IPAddress: "10.0.0.1"},
+			MapFunction:       model.BSMFunction{Type: "Identity"},
+			Weight:            1,
+			OperationalStatus: "Normal",
+		},
+	},
+}
+
+type mockBusinessServicesRest struct {
+	test     *testing.T
+	method   string
+	lastPath string
+	lastBody []byte
+}
+
+func (api *mockBusinessServicesRest) Get(path string) ([]byte, error) {
+	switch path {
+	case "/api/v2/business-services":
+		return json.Marshal(model.BusinessServiceList{Services: []model.ResourceLocation{{Location: "/api/v2/business-services/1"}}})
+	case "/api/v2/business-services/1":
+		return json.Marshal(mockBusinessService)
+	}
+	return nil, fmt.Errorf("Not Found")
+}
+
+func (api *mockBusinessServicesRest) Post(path string, jsonBytes []byte) error {
+	api.method = "POST"
+	api.lastPath = path
+	api.lastBody = jsonBytes
+	return nil
+}
+
+func (api *mockBusinessServicesRest) Delete(path string) error {
+	api.method = "DELETE"
+	api.lastPath = path
+	return nil
+}
+
+func (api *mockBusinessServicesRest) Put(path string, jsonBytes []byte, contentType string) error {
+	assert.Equal(api.test, "application/json", contentType)
+	api.method = "PUT"
+	api.lastPath = path
+	api.lastBody = jsonBytes
+	return nil
+}
+
+func TestGetBusinessServices(t *testing.T) {
+	api := GetBusinessServicesAPI(&mockBusinessServicesRest{test: t})
+
+	list, err := api.GetBusinessServices()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, "Web Portal", list[0].Name)
+	assert.Equal(t, "HTTP", list[0].IPServiceEdges[0].IPService.ServiceName)
+
+	_, err = api.GetBusinessService(0)
+	assert.Error(t, err, "Valid business service ID required")
+	_, err = api.GetBusinessService(2)
+	assert.Error(t, err, "Cannot retrieve business service 2: Not Found")
+}
+
+func TestSetBusinessService(t *testing.T) {
+	rest := &mockBusinessServicesRest{test: t}
+	api := GetBusinessServicesAPI(rest)
+
+	err := api.SetBusinessService(model.BusinessService{Name: "Database"})
+	assert.NilError(t, err)
+	assert.Equal(t, "POST", rest.method)
+	assert.Equal(t, "/api/v2/business-services", rest.lastPath)
+	assert.Equal(t, `{"name":"Database","reduce-function":{"type":"HighestSeverity"}}`, string(rest.lastBody))
+
+	// Updates the existing business service with the same name, without the content only available on responses
+	err = api.SetBusinessService(mockBusinessService)
+	assert.NilError(t, err)
+	assert.Equal(t, "PUT", rest.method)
+	assert.Equal(t, "/api/v2/business-services/1", rest.lastPath)
+	assert.Equal(t, `{"name":"Web Portal","reduce-function":{"type":"HighestSeverity"},"ip-service-edges":[{"ip-service-id":10,"map-function":{"type":"Identity"},"weight":1}]}`, string(rest.lastBody))
+}
+
+func TestBusinessServiceEdges(t *testing.T) {
+	rest := &mockBusinessServicesRest{test: t}
+	api := GetBusinessServicesAPI(rest)
+
+	err := api.AddReductionKeyEdge(1, model.ReductionKeyEdge{})
+	assert.Error(t, err, "Reduction key required")
+
+	err = api.AddReductionKeyEdge(1, model.ReductionKeyEdge{ReductionKey: "uei.opennms.org/nodes/nodeDown::1"})
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/business-services/1/reduction-key-edge", rest.lastPath)
+	assert.Equal(t, `{"reduction-key":"uei.opennms.org/nodes/nodeDown::1","map-function":{"type":"Identity"},"weight":1}`, string(rest.lastBody))
+
+	err = api.AddIPServiceEdge(1, model.IPServiceEdge{IPServiceID: 10, MapFunction: model.BSMFunction{Type: "Increase"}, Weight: 2})
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/business-services/1/ip-service-edge", rest.lastPath)
+
+	err = api.AddChildEdge(1, model.ChildEdge{ChildID: 1})
+	assert.Error(t, err, "A business service cannot be a child of itself")
+
+	err = api.AddChildEdge(1, model.ChildEdge{ChildID: 2})
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/business-services/1/child-edge", rest.lastPath)
+
+	assert.NilError(t, api.DeleteBusinessService(1))
+	assert.Equal(t, "DELETE", rest.method)
+	assert.Equal(t, "/api/v2/business-services/1", rest.lastPath)
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"time"
+
+	"github.com/OpenNMS/onmsctl/rest"
+)
+
+// The endpoints whose GET responses can be cached for a short time; only configuration that rarely changes
+// and is read repeatedly (e.x. by shell completion, find or stats), never state like alarms or events
+func init() {
+	rest.RegisterCacheable(
+		"/rest/requisitionNames",
+		"/rest/requisitions",
+		"/rest/foreignSources",
+		"/api/v2/monitoringLocations",
+	)
+	// The available detectors, policies, assets and services only change when the server is upgraded or restarted
+	rest.RegisterCacheableFor(time.Hour, "/rest/foreignSourcesConfig")
+}
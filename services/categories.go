@@ -0,0 +1,150 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type categoriesAPI struct {
+	rest api.RestAPI
+}
+
+// GetCategoriesAPI Obtain an implementation of the Categories API
+func GetCategoriesAPI(rest api.RestAPI) api.CategoriesAPI {
+	return &categoriesAPI{rest}
+}
+
+func (api categoriesAPI) GetCategories(filter string) (*model.OnmsCategoryList, error) {
+	path := "/api/v2/categories?limit=0"
+	if filter != "" {
+		path += "&_s=" + url.QueryEscape(filter)
+	}
+	jsonBytes, err := api.rest.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	list := &model.OnmsCategoryList{}
+	if len(jsonBytes) == 0 { // The v2 API returns no content when there are no matches
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api categoriesAPI) GetCategory(name string) (*model.OnmsCategory, error) {
+	if name == "" {
+		return nil, fmt.Errorf("Category name required")
+	}
+	list, err := api.GetCategories("name==" + name)
+	if err != nil {
+		return nil, err
+	}
+	category := list.GetCategory(name)
+	if category == nil {
+		return nil, fmt.Errorf("Category %s doesn't exist", name)
+	}
+	return category, nil
+}
+
+func (api categoriesAPI) AddCategory(category *model.OnmsCategory) error {
+	if category == nil {
+		return fmt.Errorf("Category cannot be null")
+	}
+	if err := category.Validate(); err != nil {
+		return err
+	}
+	list, err := api.GetCategories("name==" + category.Name)
+	if err != nil {
+		return err
+	}
+	if list.GetCategory(category.Name) != nil {
+		return fmt.Errorf("Category %s already exists", category.Name)
+	}
+	jsonBytes, err := json.Marshal(category)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post("/api/v2/categories", jsonBytes)
+}
+
+// DeleteCategory removes a category; when it is assigned to nodes, the associations are removed first only when forced.
+// Returns the number of associations removed.
+func (api categoriesAPI) DeleteCategory(name string, force bool) (int, error) {
+	category, err := api.GetCategory(name)
+	if err != nil {
+		return 0, err
+	}
+	nodes, err := api.GetNodes(name)
+	if err != nil {
+		return 0, err
+	}
+	if len(nodes.Nodes) > 0 && !force {
+		return 0, fmt.Errorf("Category %s is assigned to %d nodes; use --force to remove the associations and delete it", name, len(nodes.Nodes))
+	}
+	removed := 0
+	for _, node := range nodes.Nodes {
+		if err := api.unassign(node.ID, category); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, api.rest.Delete(fmt.Sprintf("/api/v2/categories/%d", category.ID))
+}
+
+// GetNodes returns the nodes that belong to a category
+func (api categoriesAPI) GetNodes(category string) (*model.OnmsNodeList, error) {
+	if category == "" {
+		return nil, fmt.Errorf("Category name required")
+	}
+	return GetNodesAPI(api.rest).GetNodes("category.name=="+category, 0, 0)
+}
+
+// AssignCategory adds a category to a node from the database; the change is reverted by the next import if the node belongs to a requisition without it
+func (api categoriesAPI) AssignCategory(category string, nodeCriteria string) error {
+	cat, node, err := api.getCategoryAndNode(category, nodeCriteria)
+	if err != nil {
+		return err
+	}
+	if node.HasCategory(cat.Name) {
+		return fmt.Errorf("Node %s (%s) already belongs to category %s", node.ID, node.Label, cat.Name)
+	}
+	jsonBytes, err := json.Marshal(cat)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post("/api/v2/nodes/"+node.ID+"/categories", jsonBytes)
+}
+
+// UnassignCategory removes a category from a node from the database; the change is reverted by the next import if the node belongs to a requisition with it
+func (api categoriesAPI) UnassignCategory(category string, nodeCriteria string) error {
+	cat, node, err := api.getCategoryAndNode(category, nodeCriteria)
+	if err != nil {
+		return err
+	}
+	if !node.HasCategory(cat.Name) {
+		return fmt.Errorf("Node %s (%s) doesn't belong to category %s", node.ID, node.Label, cat.Name)
+	}
+	return api.unassign(node.ID, cat)
+}
+
+func (api categoriesAPI) getCategoryAndNode(category string, nodeCriteria string) (*model.OnmsCategory, *model.OnmsNode, error) {
+	cat, err := api.GetCategory(category)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, err := GetNodesAPI(api.rest).GetNode(nodeCriteria)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cat, node, nil
+}
+
+func (api categoriesAPI) unassign(nodeID string, category *model.OnmsCategory) error {
+	return api.rest.Delete(fmt.Sprintf("/api/v2/nodes/%s/categories/%d", nodeID, category.ID))
+}
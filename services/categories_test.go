@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type mockCategoriesRest struct {
+	responses map[string]string
+	requests  []string
+}
+
+func (api *mockCategoriesRest) Get(path string) ([]byte, error) {
+	content, ok := api.responses[path]
+	if !ok {
+		return nil, fmt.Errorf("unexpected path %s", path)
+	}
+	return []byte(content), nil
+}
+
+func (api *mockCategoriesRest) Post(path string, jsonBytes []byte) error {
+	api.requests = append(api.requests, "POST "+path+" "+string(jsonBytes))
+	return nil
+}
+
+func (api *mockCategoriesRest) Delete(path string) error {
+	api.requests = append(api.requests, "DELETE "+path)
+	return nil
+}
+
+func (api *mockCategoriesRest) Put(path string, dataBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func createMockCategoriesRest() *mockCategoriesRest {
+	return &mockCategoriesRest{responses: map[string]string{
+		"/api/v2/categories?limit=0":                                   `{"count":2,"totalCount":2,"offset":0,"category":[{"id":1,"name":"Routers"},{"id":2,"name":"Servers"}]}`,
+		"/api/v2/categories?limit=0&_s=name%3D%3DRouters":              `{"count":1,"totalCount":1,"offset":0,"category":[{"id":1,"name":"Routers"}]}`,
+		"/api/v2/categories?limit=0&_s=name%3D%3DServers":              `{"count":1,"totalCount":1,"offset":0,"category":[{"id":2,"name":"Servers"}]}`,
+		"/api/v2/categories?limit=0&_s=name%3D%3DSwitches":             ``,
+		"/api/v2/nodes?limit=0&offset=0&_s=category.name%3D%3DRouters": `{"count":2,"totalCount":2,"offset":0,"node":[{"id":"1","label":"rtr01"},{"id":"4","label":"rtr02"}]}`,
+		"/api/v2/nodes?limit=0&offset=0&_s=category.name%3D%3DServers": ``,
+		"/api/v2/nodes?limit=1&offset=0&_s=id%3D%3D1":                  `{"count":1,"totalCount":1,"offset":0,"node":[{"id":"1","label":"rtr01","categories":[{"id":1,"name":"Routers"}]}]}`,
+	}}
+}
+
+func TestGetCategories(t *testing.T) {
+	api := GetCategoriesAPI(createMockCategoriesRest())
+
+	list, err := api.GetCategories("")
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(list.Categories))
+
+	category, err := api.GetCategory("Servers")
+	assert.NilError(t, err)
+	assert.Equal(t, 2, category.ID)
+
+	_, err = api.GetCategory("Switches")
+	assert.Error(t, err, "Category Switches doesn't exist")
+
+	nodes, err := api.GetNodes("Routers")
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(nodes.Nodes))
+}
+
+func TestAddCategory(t *testing.T) {
+	rest := createMockCategoriesRest()
+	api := GetCategoriesAPI(rest)
+
+	assert.NilError(t, api.AddCategory(&model.OnmsCategory{Name: "Switches"}))
+	assert.DeepEqual(t, []string{`POST /api/v2/categories {"name":"Switches"}`}, rest.requests)
+
+	assert.Error(t, api.AddCategory(&model.OnmsCategory{Name: "Routers"}), "Category Routers already exists")
+	assert.Error(t, api.AddCategory(&model.OnmsCategory{}), "Category name required")
+}
+
+func TestDeleteCategoryWithNodes(t *testing.T) {
+	rest := createMockCategoriesRest()
+	api := GetCategoriesAPI(rest)
+
+	_, err := api.DeleteCategory("Routers", false)
+	assert.Error(t, err, "Category Routers is assigned to 2 nodes; use --force to remove the associations and delete it")
+	assert.Equal(t, 0, len(rest.requests))
+
+	removed, err := api.DeleteCategory("Routers", true)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.DeepEqual(t, []string{
+		"DELETE /api/v2/nodes/1/categories/1",
+		"DELETE /api/v2/nodes/4/categories/1",
+		"DELETE /api/v2/categories/1",
+	}, rest.requests)
+
+	rest.requests = nil
+	removed, err = api.DeleteCategory("Servers", false)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, removed)
+	assert.DeepEqual(t, []string{"DELETE /api/v2/categories/2"}, rest.requests)
+}
+
+func TestAssignCategory(t *testing.T) {
+	rest := createMockCategoriesRest()
+	api := GetCategoriesAPI(rest)
+
+	assert.NilError(t, api.AssignCategory("Servers", "1"))
+	assert.Equal(t, 1, len(rest.requests))
+	assert.Assert(t, strings.HasPrefix(rest.requests[0], `POST /api/v2/nodes/1/categories {"id":2,"name":"Servers"`), rest.requests[0])
+
+	assert.Error(t, api.AssignCategory("Routers", "1"), "Node 1 (rtr01) already belongs to category Routers")
+	assert.Error(t, api.AssignCategory("Switches", "1"), "Category Switches doesn't exist")
+
+	rest.requests = nil
+	assert.NilError(t, api.UnassignCategory("Routers", "1"))
+	assert.DeepEqual(t, []string{"DELETE /api/v2/nodes/1/categories/1"}, rest.requests)
+
+	assert.Error(t, api.UnassignCategory("Servers", "1"), "Node 1 (rtr01) doesn't belong to category Servers")
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"gotest.tools/assert"
+)
+
+// Builds a server that only accepts the given credentials, and has a single requisition with the given name
+func startRequisitionServer(name string, username string, password string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if user, passwd, ok := req.BasicAuth(); !ok || user != username || passwd != password {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var data interface{}
+		switch req.URL.Path {
+		case "/rest/requisitionNames":
+			data = model.RequisitionsList{Count: 1, ForeignSources: []string{name}}
+		case "/rest/requisitions/" + name:
+			data = model.Requisition{Name: name, Nodes: []model.RequisitionNode{{ForeignID: name + "-1", NodeLabel: "n1"}}}
+		default:
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		bytes, _ := json.Marshal(data)
+		res.Write(bytes)
+	}))
+}
+
+// Run with -race to verify that independent clients can be used concurrently
+func TestIndependentClients(t *testing.T) {
+	serverA := startRequisitionServer("SiteA", "alice", "secretA")
+	defer serverA.Close()
+	serverB := startRequisitionServer("SiteB", "bob", "secretB")
+	defer serverB.Close()
+
+	clientA, err := rest.NewClient(rest.WithURL(serverA.URL), rest.WithCredentials("alice", "secretA"), rest.WithTimeout(5*time.Second))
+	assert.NilError(t, err)
+	clientB, err := rest.NewClient(rest.WithURL(serverB.URL), rest.WithCredentials("bob", "secretB"), rest.WithRetries(1, 10*time.Millisecond))
+	assert.NilError(t, err)
+	assert.Equal(t, 5, clientA.Timeout)
+	assert.Equal(t, 10, clientB.RetryBackoff)
+
+	apis := map[string]api.RequisitionsAPI{
+		"SiteA": GetRequisitionsAPI(clientA),
+		"SiteB": GetRequisitionsAPI(clientB),
+	}
+	var wg sync.WaitGroup
+	failures := make(chan error, 40)
+	for i := 0; i < 20; i++ {
+		for name, requisitionsAPI := range apis {
+			wg.Add(1)
+			go func(name string, requisitionsAPI api.RequisitionsAPI) {
+				defer wg.Done()
+				requisition, err := requisitionsAPI.GetRequisition(name)
+				if err == nil && requisition.Nodes[0].ForeignID != name+"-1" {
+					err = fmt.Errorf("Unexpected requisition %s from the server of %s", requisition.Name, name)
+				}
+				if err != nil {
+					failures <- err
+				}
+			}(name, requisitionsAPI)
+		}
+	}
+	wg.Wait()
+	close(failures)
+	for err := range failures {
+		assert.NilError(t, err)
+	}
+}
+
+func TestNewClientOptions(t *testing.T) {
+	_, err := rest.NewClient(rest.WithURL("ftp://onms"))
+	assert.Error(t, err, "Invalid URL ftp://onms: the scheme must be http or https")
+
+	_, err = rest.NewClient(rest.WithTimeout(-time.Second))
+	assert.Error(t, err, "Timeout cannot be negative")
+
+	_, err = rest.NewClient(rest.WithTLSConfig(nil))
+	assert.Error(t, err, "TLS configuration required")
+
+	client, err := rest.NewClient()
+	assert.NilError(t, err)
+	assert.Equal(t, "http://localhost:8980/opennms", client.URL)
+}
+
+func TestStrictDecodePerClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/rest/requisitionNames" {
+			res.Write([]byte(`{"count":1,"foreign-source":["SiteA"]}`))
+			return
+		}
+		res.Write([]byte(`{"foreign-source":"SiteA","date-stamp":1590000000000,"node":[{"foreign-id":"n1","node-label":"n1","sys-contact":"ops"}]}`))
+	}))
+	defer server.Close()
+
+	strict, err := rest.NewClient(rest.WithURL(server.URL), rest.WithStrictDecode(true))
+	assert.NilError(t, err)
+	lenient, err := rest.NewClient(rest.WithURL(server.URL))
+	assert.NilError(t, err)
+
+	_, err = GetRequisitionsAPI(strict).GetRequisition("SiteA")
+	assert.ErrorContains(t, err, `unknown field "sys-contact"`)
+	requisition, err := GetRequisitionsAPI(lenient).GetRequisition("SiteA")
+	assert.NilError(t, err)
+	assert.Equal(t, "n1", requisition.Nodes[0].ForeignID)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/rest"
+)
+
+// contextRest a ReST API bound to a context
+type contextRest struct {
+	rest api.RestAPI
+	ctx  context.Context
+}
+
+// WithContext Obtain a ReST API whose requests are cancelled with the given context;
+// any service created from it can be interrupted. When the ReST API doesn't support contexts,
+// the requests are rejected once the context is done, but in-flight requests are not interrupted.
+func WithContext(ctx context.Context, rest api.RestAPI) api.RestAPI {
+	return &contextRest{rest, ctx}
+}
+
+func (r contextRest) Get(path string) ([]byte, error) {
+	if rest, ok := r.rest.(api.RestContextAPI); ok {
+		return rest.GetWithContext(r.ctx, path)
+	}
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.rest.Get(path)
+}
+
+func (r contextRest) Post(path string, jsonBytes []byte) error {
+	if rest, ok := r.rest.(api.RestContextAPI); ok {
+		return rest.PostWithContext(r.ctx, path, jsonBytes)
+	}
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	return r.rest.Post(path, jsonBytes)
+}
+
+func (r contextRest) Delete(path string) error {
+	if rest, ok := r.rest.(api.RestContextAPI); ok {
+		return rest.DeleteWithContext(r.ctx, path)
+	}
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	return r.rest.Delete(path)
+}
+
+func (r contextRest) Put(path string, dataBytes []byte, contentType string) error {
+	if rest, ok := r.rest.(api.RestContextAPI); ok {
+		return rest.PutWithContext(r.ctx, path, dataBytes, contentType)
+	}
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	return r.rest.Put(path, dataBytes, contentType)
+}
+
+func (r contextRest) IsStrictDecode() bool {
+	return getDecoder(r.rest).Strict
+}
+
+func (r contextRest) Context() context.Context {
+	return r.ctx
+}
+
+// getContext returns the context that interrupts the operations of the ReST API, when it has one
+func getContext(client api.RestAPI) context.Context {
+	if r, ok := client.(api.RestCancelAPI); ok {
+		return r.Context()
+	}
+	return context.Background()
+}
+
+// sleep waits for the given duration, or until the context of the ReST API is done (e.x. between polls)
+func sleep(client api.RestAPI, d time.Duration) error {
+	select {
+	case <-getContext(client).Done():
+		return rest.ErrCancelled
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// getDecoder returns the decoder for the responses of the ReST API, strict when the ReST API requests it
+func getDecoder(rest api.RestAPI) common.Decoder {
+	if r, ok := rest.(api.RestDecodeAPI); ok {
+		return common.Decoder{Strict: r.IsStrictDecode()}
+	}
+	return common.Decoder{}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type mockContextRest struct {
+	mockMonitoringLocationRest
+	ctx context.Context
+}
+
+func (api *mockContextRest) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	api.ctx = ctx
+	return api.Get(path)
+}
+
+func (api *mockContextRest) PostWithContext(ctx context.Context, path string, jsonBytes []byte) error {
+	api.ctx = ctx
+	return api.Post(path, jsonBytes)
+}
+
+func (api *mockContextRest) DeleteWithContext(ctx context.Context, path string) error {
+	api.ctx = ctx
+	return api.Delete(path)
+}
+
+func (api *mockContextRest) PutWithContext(ctx context.Context, path string, dataBytes []byte, contentType string) error {
+	api.ctx = ctx
+	return api.Put(path, dataBytes, contentType)
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rest := &mockContextRest{mockMonitoringLocationRest: mockMonitoringLocationRest{test: t}}
+	api := GetMonitoringLocationsAPI(WithContext(ctx, rest))
+	exists, err := api.LocationExists("Apex")
+	assert.NilError(t, err)
+	assert.Assert(t, exists)
+	assert.Equal(t, ctx, rest.ctx)
+
+	// Without context support, requests are rejected once the context is done
+	simple := &mockMonitoringLocationRest{test: t}
+	api = GetMonitoringLocationsAPI(WithContext(ctx, simple))
+	assert.NilError(t, api.DeleteLocation("Apex"))
+	cancel()
+	simple.lastPath = ""
+	assert.Error(t, api.DeleteLocation("Apex"), "context canceled")
+	assert.Equal(t, "", simple.lastPath)
+}
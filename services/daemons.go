@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type daemonsAPI struct {
+	rest api.RestAPI
+}
+
+// GetDaemonsAPI Obtain an implementation of the Daemons API
+func GetDaemonsAPI(rest api.RestAPI) api.DaemonsAPI {
+	return &daemonsAPI{rest}
+}
+
+func (api daemonsAPI) GetDaemons() ([]model.Daemon, error) {
+	jsonBytes, err := api.rest.Get("/rest/daemons")
+	if err != nil {
+		return nil, err
+	}
+	daemons := make([]model.Daemon, 0)
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, &daemons); err != nil {
+		return nil, err
+	}
+	return daemons, nil
+}
+
+func (api daemonsAPI) GetReloadState(daemonName string) (*model.DaemonReloadState, error) {
+	if daemonName == "" {
+		return nil, fmt.Errorf("Daemon name required")
+	}
+	jsonBytes, err := api.rest.Get("/rest/daemons/reload/" + daemonName)
+	if err != nil {
+		return nil, err
+	}
+	state := &model.DaemonReloadState{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// WaitForReload polls the reload state until the daemon reports the result of a reload requested after the given time
+func (api daemonsAPI) WaitForReload(daemonName string, lastRequest *model.Time, timeout time.Duration, pollInterval time.Duration) (*model.DaemonReloadState, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		state, err := api.GetReloadState(daemonName)
+		if err != nil {
+			return nil, err
+		}
+		if state.IsNewerThan(lastRequest) && state.IsFinished() {
+			return state, nil
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return nil, fmt.Errorf("Timed out after %s waiting for daemon %s to reload", timeout, daemonName)
+		}
+		if err := sleep(api.rest, pollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type mockDaemonsRest struct {
+	test  *testing.T
+	polls int
+}
+
+func (api *mockDaemonsRest) Get(path string) ([]byte, error) {
+	switch path {
+	case "/rest/daemons":
+		return []byte(`[{"name":"Pollerd","internal":false,"enabled":true,"reloadable":true}]`), nil
+	case "/rest/daemons/reload/Pollerd":
+		api.polls++
+		if api.polls < 3 {
+			return []byte(`{"reloadRequestEventTime":1571000000000,"reloadResultEventTime":1571000001000,"reloadState":"Success"}`), nil
+		}
+		return []byte(`{"reloadRequestEventTime":1571000100000,"reloadResultEventTime":1571000101000,"reloadState":"Failed"}`), nil
+	}
+	return nil, fmt.Errorf("Invalid Response: 404 Not Found")
+}
+
+func (api mockDaemonsRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockDaemonsRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockDaemonsRest) Put(path string, dataBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetDaemons(t *testing.T) {
+	api := GetDaemonsAPI(&mockDaemonsRest{test: t})
+	daemons, err := api.GetDaemons()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(daemons))
+	assert.Equal(t, "Pollerd", model.FindDaemon(daemons, "pollerd").Name)
+	assert.Assert(t, model.FindDaemon(daemons, "collectd") == nil)
+}
+
+func TestWaitForReload(t *testing.T) {
+	api := GetDaemonsAPI(&mockDaemonsRest{test: t})
+
+	_, err := api.GetReloadState("")
+	assert.Error(t, err, "Daemon name required")
+
+	last, err := api.GetReloadState("Pollerd")
+	assert.NilError(t, err)
+	assert.Equal(t, model.DaemonReloadSuccess, last.State)
+	assert.Equal(t, int64(1571000000), last.RequestTime.Unix())
+
+	state, err := api.WaitForReload("Pollerd", last.RequestTime, time.Second, time.Millisecond)
+	assert.NilError(t, err)
+	assert.Equal(t, model.DaemonReloadFailed, state.State)
+
+	_, err = api.WaitForReload("Pollerd", state.RequestTime, 10*time.Millisecond, time.Millisecond)
+	assert.ErrorContains(t, err, "Timed out after 10ms waiting for daemon Pollerd to reload")
+
+	_, err = api.GetReloadState("Unknown")
+	assert.ErrorContains(t, err, "404")
+}
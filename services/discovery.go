@@ -0,0 +1,47 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type discoveryAPI struct {
+	rest api.RestAPI
+}
+
+// GetDiscoveryAPI Obtain an implementation of the Discovery API
+func GetDiscoveryAPI(rest api.RestAPI) api.DiscoveryAPI {
+	return &discoveryAPI{rest}
+}
+
+func (api discoveryAPI) GetConfig() (*model.DiscoveryConfiguration, error) {
+	data, err := api.rest.Get("/rest/config/discovery")
+	if err != nil {
+		return nil, err
+	}
+	config := &model.DiscoveryConfiguration{}
+	// The configuration endpoints might ignore the Accept header and send the XML content of the file
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '<' {
+		err = getDecoder(api.rest).DecodeXML(trimmed, config)
+	} else {
+		err = getDecoder(api.rest).DecodeJSON(data, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (api discoveryAPI) SetConfig(config model.DiscoveryConfiguration) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	xmlBytes, err := xml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return api.rest.Put("/rest/config/discovery", xmlBytes, "application/xml")
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type mockDiscoveryRest struct {
+	test    *testing.T
+	content string
+	config  *model.DiscoveryConfiguration
+}
+
+func (api *mockDiscoveryRest) Get(path string) ([]byte, error) {
+	assert.Equal(api.test, "/rest/config/discovery", path)
+	return []byte(api.content), nil
+}
+
+func (api mockDiscoveryRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockDiscoveryRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockDiscoveryRest) Put(path string, dataBytes []byte, contentType string) error {
+	assert.Equal(api.test, "/rest/config/discovery", path)
+	assert.Equal(api.test, "application/xml", contentType)
+	api.config = &model.DiscoveryConfiguration{}
+	return xml.Unmarshal(dataBytes, api.config)
+}
+
+func TestGetDiscoveryConfig(t *testing.T) {
+	rest := &mockDiscoveryRest{test: t}
+	api := GetDiscoveryAPI(rest)
+
+	rest.content = `{"retries":1,"timeout":2000,"specific":[{"content":"10.0.0.1"}],"include-range":[{"begin":"10.0.1.1","end":"10.0.1.254","location":"Durham"}]}`
+	config, err := api.GetConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, config.Retries)
+	assert.Equal(t, "10.0.0.1", config.Specifics[0].Address)
+	assert.Equal(t, "Durham", config.IncludeRanges[0].Location)
+
+	rest.content = `<?xml version="1.0"?>
+<discovery-configuration xmlns="http://xmlns.opennms.org/xsd/config/discovery" packets-per-second="1" retries="3" timeout="800">
+  <include-range><begin>192.168.0.1</begin><end>192.168.0.254</end></include-range>
+  <exclude-range><begin>192.168.0.100</begin><end>192.168.0.110</end></exclude-range>
+  <include-url location="Durham">file:/opt/opennms/etc/include.txt</include-url>
+</discovery-configuration>`
+	config, err = api.GetConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, 3, config.Retries)
+	assert.Equal(t, "192.168.0.254", config.IncludeRanges[0].End)
+	assert.Equal(t, "192.168.0.100", config.ExcludeRanges[0].Begin)
+	assert.Equal(t, "file:/opt/opennms/etc/include.txt", config.IncludeURLs[0].URL)
+}
+
+func TestSetDiscoveryConfig(t *testing.T) {
+	rest := &mockDiscoveryRest{test: t}
+	api := GetDiscoveryAPI(rest)
+
+	config := model.DiscoveryConfiguration{}
+	config.AddIncludeRange(model.IncludeRange{Begin: "10.0.0.254", End: "10.0.0.1"})
+	assert.Error(t, api.SetConfig(config), "Invalid include range: begin 10.0.0.254 must be lower or equal than end 10.0.0.1")
+	assert.Assert(t, rest.config == nil)
+
+	config.AddIncludeRange(model.IncludeRange{Begin: "10.0.0.1", End: "10.0.0.254", Retries: 2})
+	config.AddSpecific(model.Specific{Address: "10.0.1.1", Location: "Durham"})
+	assert.Assert(t, config.DeleteIncludeRange("10.0.0.254", "10.0.0.1"))
+	assert.NilError(t, api.SetConfig(config))
+	assert.Equal(t, 1, len(rest.config.IncludeRanges))
+	assert.Equal(t, 2, rest.config.IncludeRanges[0].Retries)
+	assert.Equal(t, "10.0.1.1", rest.config.Specifics[0].Address)
+	assert.Equal(t, "Durham", rest.config.Specifics[0].Location)
+}
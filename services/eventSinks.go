@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// DefaultEventSink the event sink used when the settings don't define one
+const DefaultEventSink = "rest"
+
+// EventSinkFactory creates an event sink from its settings; the ReST client is used by the sinks that need it
+type EventSinkFactory func(client api.RestAPI, settings rest.EventSinkSettings, timeout time.Duration) (api.EventSink, error)
+
+var eventSinks = map[string]EventSinkFactory{
+	DefaultEventSink: newRestEventSink,
+	"kafka":          newKafkaEventSink,
+}
+
+// RegisterEventSink adds a new kind of event sink, available through the settings or the --via flag
+func RegisterEventSink(name string, factory EventSinkFactory) {
+	eventSinks[name] = factory
+}
+
+// EventSinkNames returns the sorted names of the available event sinks
+func EventSinkNames() []string {
+	names := make([]string, 0, len(eventSinks))
+	for name := range eventSinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetEventSink Obtain the event sink described by the settings; it must be closed after sending the events
+func GetEventSink(client api.RestAPI, settings rest.EventSinkSettings, timeout time.Duration) (api.EventSink, error) {
+	via := settings.Via
+	if via == "" {
+		via = DefaultEventSink
+	}
+	if err := ValidateEventSink(via); err != nil {
+		return nil, err
+	}
+	return eventSinks[via](client, settings, timeout)
+}
+
+// ValidateEventSink returns an error if there is no event sink with the given name
+func ValidateEventSink(name string) error {
+	if _, ok := eventSinks[name]; !ok {
+		return fmt.Errorf("Invalid event sink %s, valid options: %s", name, strings.Join(EventSinkNames(), ", "))
+	}
+	return nil
+}
+
+// restEventSink sends events through the ReST API
+type restEventSink struct {
+	api.EventsAPI
+}
+
+func newRestEventSink(client api.RestAPI, settings rest.EventSinkSettings, timeout time.Duration) (api.EventSink, error) {
+	return restEventSink{GetEventsAPI(client)}, nil
+}
+
+func (sink restEventSink) Close() error {
+	return nil
+}
+
+// The SASL mechanisms supported by the Kafka event sink
+var saslMechanisms = map[string]func(username, password string) (sasl.Mechanism, error){
+	"plain": func(username, password string) (sasl.Mechanism, error) {
+		return plain.Mechanism{Username: username, Password: password}, nil
+	},
+	"scram-sha-256": func(username, password string) (sasl.Mechanism, error) {
+		return scram.Mechanism(scram.SHA256, username, password)
+	},
+	"scram-sha-512": func(username, password string) (sasl.Mechanism, error) {
+		return scram.Mechanism(scram.SHA512, username, password)
+	},
+}
+
+// kafkaEventSink sends events to a Kafka topic, each of them as an XML event log, waiting for the acknowledgment of the leader
+type kafkaEventSink struct {
+	writer *kafka.Writer
+	topic  string
+	ctx    context.Context
+}
+
+func newKafkaEventSink(client api.RestAPI, settings rest.EventSinkSettings, timeout time.Duration) (api.EventSink, error) {
+	config, err := kafkaWriterConfig(settings, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaEventSink{kafka.NewWriter(config), settings.Topic, getContext(client)}, nil
+}
+
+// Builds the configuration of the Kafka writer, verifying the settings
+func kafkaWriterConfig(settings rest.EventSinkSettings, timeout time.Duration) (kafka.WriterConfig, error) {
+	config := kafka.WriterConfig{
+		Brokers:      settings.Brokers,
+		Topic:        settings.Topic,
+		Balancer:     &kafka.RoundRobin{},
+		MaxAttempts:  3,
+		BatchTimeout: 10 * time.Millisecond,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+		RequiredAcks: 1,
+	}
+	if settings.Topic == "" {
+		return config, fmt.Errorf("Kafka topic required")
+	}
+	if len(settings.Brokers) == 0 {
+		return config, fmt.Errorf("At least one Kafka broker is required")
+	}
+	for _, b := range settings.Brokers {
+		if _, _, err := net.SplitHostPort(b); err != nil {
+			return config, fmt.Errorf("Invalid Kafka broker %s, expected host:port", b)
+		}
+	}
+	dialer := &kafka.Dialer{ClientID: "onmsctl", Timeout: timeout, DualStack: true}
+	tlsConfig, err := settings.GetTLSConfig()
+	if err != nil {
+		return config, fmt.Errorf("Invalid TLS settings for the Kafka brokers: %w", err)
+	}
+	dialer.TLS = tlsConfig
+	if settings.SASL != "" {
+		mechanism, ok := saslMechanisms[strings.ToLower(settings.SASL)]
+		if !ok {
+			return config, fmt.Errorf("Invalid SASL mechanism %s, valid options: plain, scram-sha-256, scram-sha-512", settings.SASL)
+		}
+		if settings.Username == "" {
+			return config, fmt.Errorf("The SASL mechanism %s requires a username", settings.SASL)
+		}
+		if dialer.SASLMechanism, err = mechanism(settings.Username, settings.Password); err != nil {
+			return config, err
+		}
+	}
+	config.Dialer = dialer
+	return config, nil
+}
+
+func (sink kafkaEventSink) SendEvent(event model.Event) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	data, err := xml.Marshal(model.EventLog{Events: []model.Event{event}})
+	if err != nil {
+		return err
+	}
+	if err := sink.writer.WriteMessages(sink.ctx, kafka.Message{Value: append([]byte(xml.Header), data...)}); err != nil {
+		return fmt.Errorf("Cannot produce the event to Kafka topic %s: %w", sink.topic, err)
+	}
+	return nil
+}
+
+func (sink kafkaEventSink) SendEvents(events []model.Event, concurrency int) []error {
+	return sendEvents(sink.SendEvent, events, concurrency)
+}
+
+func (sink kafkaEventSink) Close() error {
+	return sink.writer.Close()
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/rest"
+	"gotest.tools/assert"
+)
+
+func TestGetEventSink(t *testing.T) {
+	sink, err := GetEventSink(mockEventRest{t: t}, rest.EventSinkSettings{}, time.Second)
+	assert.NilError(t, err)
+	_, ok := sink.(restEventSink)
+	assert.Assert(t, ok)
+	assert.NilError(t, sink.Close())
+
+	_, err = GetEventSink(nil, rest.EventSinkSettings{Via: "kafka", Topic: "events"}, time.Second)
+	assert.Error(t, err, "At least one Kafka broker is required")
+
+	_, err = GetEventSink(nil, rest.EventSinkSettings{Via: "kafka", Brokers: []string{"kafka:9092"}}, time.Second)
+	assert.Error(t, err, "Kafka topic required")
+
+	sink, err = GetEventSink(nil, rest.EventSinkSettings{Via: "kafka", Brokers: []string{"kafka:9092"}, Topic: "events"}, time.Second)
+	assert.NilError(t, err)
+	assert.NilError(t, sink.Close())
+
+	_, err = GetEventSink(nil, rest.EventSinkSettings{Via: "kafka", Brokers: []string{"kafka"}, Topic: "events"}, time.Second)
+	assert.Error(t, err, "Invalid Kafka broker kafka, expected host:port")
+
+	_, err = GetEventSink(nil, rest.EventSinkSettings{Via: "amqp"}, time.Second)
+	assert.ErrorContains(t, err, "Invalid event sink amqp")
+}
+
+func TestKafkaWriterConfig(t *testing.T) {
+	settings := rest.EventSinkSettings{Via: "kafka", Brokers: []string{"kafka:9093"}, Topic: "events"}
+	config, err := kafkaWriterConfig(settings, 5*time.Second)
+	assert.NilError(t, err)
+	assert.Equal(t, "events", config.Topic)
+	assert.Equal(t, 5*time.Second, config.Dialer.Timeout)
+	assert.Assert(t, config.Dialer.TLS == nil)
+	assert.Assert(t, config.Dialer.SASLMechanism == nil)
+
+	settings.TLS = true
+	settings.SASL = "SCRAM-SHA-512"
+	settings.Username = "onms"
+	settings.Password = "secret"
+	config, err = kafkaWriterConfig(settings, 5*time.Second)
+	assert.NilError(t, err)
+	assert.Assert(t, config.Dialer.TLS != nil)
+	assert.Equal(t, "SCRAM-SHA-512", config.Dialer.SASLMechanism.Name())
+
+	settings.SASL = "plain"
+	config, err = kafkaWriterConfig(settings, 5*time.Second)
+	assert.NilError(t, err)
+	assert.Equal(t, "PLAIN", config.Dialer.SASLMechanism.Name())
+
+	settings.Username = ""
+	_, err = kafkaWriterConfig(settings, 5*time.Second)
+	assert.Error(t, err, "The SASL mechanism plain requires a username")
+
+	settings.SASL = "gssapi"
+	_, err = kafkaWriterConfig(settings, 5*time.Second)
+	assert.Error(t, err, "Invalid SASL mechanism gssapi, valid options: plain, scram-sha-256, scram-sha-512")
+
+	settings.SASL = ""
+	settings.CACert = "/missing/ca.pem"
+	_, err = kafkaWriterConfig(settings, 5*time.Second)
+	assert.ErrorContains(t, err, "Invalid TLS settings for the Kafka brokers: could not read CA certificate /missing/ca.pem")
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type eventConfAPI struct {
+	rest api.RestAPI
+}
+
+// GetEventConfAPI Obtain an implementation of the Event Configuration API
+func GetEventConfAPI(rest api.RestAPI) api.EventConfAPI {
+	return &eventConfAPI{rest}
+}
+
+// GetEventDefinitions returns the event definitions loaded by the server
+func (api eventConfAPI) GetEventDefinitions() (*model.EventDefinitionList, error) {
+	jsonBytes, err := api.rest.Get("/rest/eventconf")
+	if err != nil {
+		return nil, err
+	}
+	list := &model.EventDefinitionList{}
+	if len(jsonBytes) == 0 {
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// BundledEventDefinitions returns a snapshot of the event definitions shipped with OpenNMS,
+// for the servers that don't expose their event configuration
+func BundledEventDefinitions() *model.EventDefinitionList {
+	events := make([]model.EventDefinition, len(bundledEventDefinitions))
+	copy(events, bundledEventDefinitions)
+	return &model.EventDefinitionList{Count: len(events), Events: events}
+}
+
+var bundledEventDefinitions = []model.EventDefinition{
+	{UEI: "uei.opennms.org/alarms/trigger", Label: "Alarm: Generic Trigger", Severity: "Warning"},
+	{UEI: "uei.opennms.org/alarms/clear", Label: "Alarm: Generic Clear", Severity: "Normal"},
+	{UEI: "uei.opennms.org/default/event", Label: "OpenNMS-defined default event: event", Severity: "Indeterminate"},
+	{UEI: "uei.opennms.org/generic/traps/SNMP_Cold_Start", Label: "OpenNMS-defined trap event: SNMP_Cold_Start", Severity: "Normal"},
+	{UEI: "uei.opennms.org/generic/traps/SNMP_Warm_Start", Label: "OpenNMS-defined trap event: SNMP_Warm_Start", Severity: "Normal"},
+	{UEI: "uei.opennms.org/generic/traps/SNMP_Link_Down", Label: "OpenNMS-defined trap event: SNMP_Link_Down", Severity: "Minor"},
+	{UEI: "uei.opennms.org/generic/traps/SNMP_Link_Up", Label: "OpenNMS-defined trap event: SNMP_Link_Up", Severity: "Normal"},
+	{UEI: "uei.opennms.org/generic/traps/SNMP_Authen_Failure", Label: "OpenNMS-defined trap event: SNMP_Authen_Failure", Severity: "Warning"},
+	{UEI: "uei.opennms.org/internal/reloadDaemonConfig", Label: "OpenNMS-defined internal event: reload specified daemon configuration", Severity: "Normal"},
+	{UEI: "uei.opennms.org/internal/reloadDaemonConfigSuccessful", Label: "OpenNMS-defined internal event: daemon configuration reloaded", Severity: "Normal"},
+	{UEI: "uei.opennms.org/internal/reloadDaemonConfigFailed", Label: "OpenNMS-defined internal event: daemon configuration reload failed", Severity: "Minor"},
+	{UEI: "uei.opennms.org/internal/importer/reloadImport", Label: "OpenNMS-defined internal event: reload import", Severity: "Normal"},
+	{UEI: "uei.opennms.org/internal/discovery/newSuspect", Label: "OpenNMS-defined discovery event: newSuspect", Severity: "Warning"},
+	{UEI: "uei.opennms.org/internal/capsd/forceRescan", Label: "OpenNMS-defined internal event: forceRescan", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/nodeAdded", Label: "OpenNMS-defined node event: nodeAdded", Severity: "Warning"},
+	{UEI: "uei.opennms.org/nodes/nodeDeleted", Label: "OpenNMS-defined node event: nodeDeleted", Severity: "Minor"},
+	{UEI: "uei.opennms.org/nodes/nodeUpdated", Label: "OpenNMS-defined node event: nodeUpdated", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/nodeLabelChanged", Label: "OpenNMS-defined node event: nodeLabelChanged", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/nodeCategoryMembershipChanged", Label: "OpenNMS-defined node event: nodeCategoryMembershipChanged", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/nodeDown", Label: "OpenNMS-defined node event: nodeDown", Severity: "Major"},
+	{UEI: "uei.opennms.org/nodes/nodeUp", Label: "OpenNMS-defined node event: nodeUp", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/nodeGainedInterface", Label: "OpenNMS-defined node event: nodeGainedInterface", Severity: "Warning"},
+	{UEI: "uei.opennms.org/nodes/nodeGainedService", Label: "OpenNMS-defined node event: nodeGainedService", Severity: "Warning"},
+	{UEI: "uei.opennms.org/nodes/nodeLostService", Label: "OpenNMS-defined node event: nodeLostService", Severity: "Minor"},
+	{UEI: "uei.opennms.org/nodes/nodeRegainedService", Label: "OpenNMS-defined node event: nodeRegainedService", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/interfaceDown", Label: "OpenNMS-defined node event: interfaceDown", Severity: "Minor"},
+	{UEI: "uei.opennms.org/nodes/interfaceUp", Label: "OpenNMS-defined node event: interfaceUp", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/interfaceDeleted", Label: "OpenNMS-defined node event: interfaceDeleted", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/serviceDeleted", Label: "OpenNMS-defined node event: serviceDeleted", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/pathOutage", Label: "OpenNMS-defined node event: pathOutage", Severity: "Major"},
+	{UEI: "uei.opennms.org/nodes/snmp/interfaceOperDown", Label: "OpenNMS-defined node event: interfaceOperDown", Severity: "Minor"},
+	{UEI: "uei.opennms.org/nodes/snmp/interfaceOperUp", Label: "OpenNMS-defined node event: interfaceOperUp", Severity: "Normal"},
+	{UEI: "uei.opennms.org/nodes/dataCollectionFailed", Label: "OpenNMS-defined node event: dataCollectionFailed", Severity: "Minor"},
+	{UEI: "uei.opennms.org/nodes/dataCollectionSucceeded", Label: "OpenNMS-defined node event: dataCollectionSucceeded", Severity: "Normal"},
+	{UEI: "uei.opennms.org/threshold/highThresholdExceeded", Label: "OpenNMS-defined threshold event: highThresholdExceeded", Severity: "Warning"},
+	{UEI: "uei.opennms.org/threshold/highThresholdRearmed", Label: "OpenNMS-defined threshold event: highThresholdRearmed", Severity: "Normal"},
+	{UEI: "uei.opennms.org/threshold/lowThresholdExceeded", Label: "OpenNMS-defined threshold event: lowThresholdExceeded", Severity: "Warning"},
+	{UEI: "uei.opennms.org/threshold/lowThresholdRearmed", Label: "OpenNMS-defined threshold event: lowThresholdRearmed", Severity: "Normal"},
+	{UEI: "uei.opennms.org/threshold/relativeChangeExceeded", Label: "OpenNMS-defined threshold event: relativeChangeExceeded", Severity: "Warning"},
+	{UEI: "uei.opennms.org/threshold/absoluteChangeExceeded", Label: "OpenNMS-defined threshold event: absoluteChangeExceeded", Severity: "Warning"},
+	{UEI: "uei.opennms.org/syslogd/system/Error", Label: "OpenNMS-defined syslogd event: system/Error", Severity: "Minor"},
+	{UEI: "uei.opennms.org/vendor/opennms/syslog/unknown", Label: "Syslog message that doesn't match any rule", Severity: "Warning"},
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type mockEventConfRest struct {
+	data string
+}
+
+func (api mockEventConfRest) Get(path string) ([]byte, error) {
+	if path != "/rest/eventconf" {
+		return nil, fmt.Errorf("GET request for %s not implemented", path)
+	}
+	return []byte(api.data), nil
+}
+
+func (api mockEventConfRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockEventConfRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockEventConfRest) Put(path string, dataBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetEventDefinitions(t *testing.T) {
+	api := GetEventConfAPI(mockEventConfRest{`{"count":1,"event":[{"uei":"uei.opennms.org/nodes/nodeDown","event-label":"OpenNMS-defined node event: nodeDown","severity":"Major"}]}`})
+	list, err := api.GetEventDefinitions()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []model.EventDefinition{{UEI: "uei.opennms.org/nodes/nodeDown", Label: "OpenNMS-defined node event: nodeDown", Severity: "Major"}}, list.Events)
+
+	list, err = GetEventConfAPI(mockEventConfRest{}).GetEventDefinitions()
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(list.Events))
+}
+
+func TestBundledEventDefinitions(t *testing.T) {
+	list := BundledEventDefinitions()
+	assert.Equal(t, len(list.Events), list.Count)
+	assert.Assert(t, list.GetEvent("uei.opennms.org/nodes/nodeDown") != nil)
+	severities := &model.EnumValue{Enum: model.Severities.Enum}
+	seen := make(map[string]bool)
+	for _, e := range list.Events {
+		assert.Assert(t, !seen[e.UEI], e.UEI)
+		seen[e.UEI] = true
+		assert.NilError(t, severities.Set(e.Severity), e.UEI)
+	}
+}
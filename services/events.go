@@ -2,6 +2,7 @@ package services
 
 import (
 	"encoding/json"
+	"sync"
 
 	"github.com/OpenNMS/onmsctl/api"
 	"github.com/OpenNMS/onmsctl/model"
@@ -26,3 +27,37 @@ func (api eventsAPI) SendEvent(event model.Event) error {
 	}
 	return api.rest.Post("/rest/events", jsonBytes)
 }
+
+// SendEvents sends a list of events, in order when the concurrency is 1, or using a pool of workers otherwise;
+// the returned list contains the error for each event, or nil if the event was accepted
+func (api eventsAPI) SendEvents(events []model.Event, concurrency int) []error {
+	return sendEvents(api.SendEvent, events, concurrency)
+}
+
+// Sends the events with the given function, shared by all the event sinks
+func sendEvents(send func(event model.Event) error, events []model.Event, concurrency int) []error {
+	errors := make([]error, len(events))
+	if concurrency <= 1 {
+		for i, event := range events {
+			errors[i] = send(event)
+		}
+		return errors
+	}
+	jobs := make(chan int, len(events))
+	for i := range events {
+		jobs <- i
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errors[i] = send(events[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return errors
+}
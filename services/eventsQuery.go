@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type eventsQueryAPI struct {
+	rest api.RestAPI
+}
+
+// GetEventsQueryAPI Obtain an implementation of the Events Query API
+func GetEventsQueryAPI(rest api.RestAPI) api.EventsQueryAPI {
+	return &eventsQueryAPI{rest}
+}
+
+// GetEvents returns the events matching the FIQL filter, newest first
+func (api eventsQueryAPI) GetEvents(filter string, limit int, offset int) (*model.OnmsEventList, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("Limit cannot be negative")
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("Offset cannot be negative")
+	}
+	return api.query(filter, limit, offset, "desc")
+}
+
+// GetAllEvents returns all the events matching the filter, newest first, requested in pages
+func (api eventsQueryAPI) GetAllEvents(filter string) (*model.OnmsEventList, error) {
+	params := filterParams(filter)
+	params.Set("orderBy", "id")
+	params.Set("order", "desc")
+	list := &model.OnmsEventList{Events: make([]model.OnmsEvent, 0)}
+	err := GetPagerAPI(api.rest).ForEachPage("/api/v2/events", params, DefaultPageSize, func(body []byte) (int, error) {
+		page := &model.OnmsEventList{}
+		if err := getDecoder(api.rest).DecodeJSON(body, page); err != nil {
+			return 0, err
+		}
+		list.Events = append(list.Events, page.Events...)
+		return len(page.Events), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	list.Count = len(list.Events)
+	list.TotalCount = list.Count
+	return list, nil
+}
+
+// GetEventsAfter returns the events with an ID greater than the given one matching the FIQL filter, oldest first
+func (api eventsQueryAPI) GetEventsAfter(id int, filter string, limit int) (*model.OnmsEventList, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("Limit cannot be negative")
+	}
+	expression := "event.id=gt=" + strconv.Itoa(id)
+	if filter != "" {
+		expression += ";" + filter
+	}
+	return api.query(expression, limit, 0, "asc")
+}
+
+func (api eventsQueryAPI) query(filter string, limit int, offset int, order string) (*model.OnmsEventList, error) {
+	path := fmt.Sprintf("/api/v2/events?limit=%d&offset=%d&orderBy=id&order=%s", limit, offset, order)
+	if filter != "" {
+		path += "&_s=" + url.QueryEscape(filter)
+	}
+	jsonBytes, err := api.rest.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	list := &model.OnmsEventList{}
+	if len(jsonBytes) == 0 { // The v2 API returns no content when there are no matches
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
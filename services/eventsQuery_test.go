@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type mockEventsQueryRest struct {
+	t     *testing.T
+	paths []string
+}
+
+func (api *mockEventsQueryRest) Get(path string) ([]byte, error) {
+	api.paths = append(api.paths, path)
+	if u, _ := url.Parse(path); u.Query().Get("order") == "asc" {
+		return []byte{}, nil
+	}
+	return []byte(`{"event":[{"id":2,"uei":"uei.opennms.org/test","severity":"NORMAL"},{"id":1,"uei":"uei.opennms.org/test","severity":"NORMAL"}],"count":2,"totalCount":2,"offset":0}`), nil
+}
+
+func (api *mockEventsQueryRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockEventsQueryRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockEventsQueryRest) Put(path string, jsonBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetEvents(t *testing.T) {
+	rest := &mockEventsQueryRest{t: t}
+	api := GetEventsQueryAPI(rest)
+
+	list, err := api.GetEvents("event.uei==uei.opennms.org/test", 10, 5)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(list.Events))
+	assert.Equal(t, 2, list.Events[0].ID)
+	assert.Equal(t, "/api/v2/events?limit=10&offset=5&orderBy=id&order=desc&_s=event.uei%3D%3Duei.opennms.org%2Ftest", rest.paths[0])
+
+	list, err = api.GetEventsAfter(2, "event.uei==uei.opennms.org/test", 100)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(list.Events))
+	assert.Equal(t, "/api/v2/events?limit=100&offset=0&orderBy=id&order=asc&_s=event.id%3Dgt%3D2%3Bevent.uei%3D%3Duei.opennms.org%2Ftest", rest.paths[1])
+
+	_, err = api.GetEvents("", -1, 0)
+	assert.ErrorContains(t, err, "Limit cannot be negative")
+}
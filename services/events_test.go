@@ -56,3 +56,16 @@ func TestSendEvent(t *testing.T) {
 	err = api.SendEvent(model.Event{NodeID: 10})
 	assert.ErrorContains(t, err, "UEI")
 }
+
+func TestSendEvents(t *testing.T) {
+	api := GetEventsAPI(&mockEventRest{t})
+	events := []model.Event{*mockEvent, {NodeID: 10}, *mockEvent, *mockEvent}
+	for _, concurrency := range []int{1, 3} {
+		errors := api.SendEvents(events, concurrency)
+		assert.Equal(t, 4, len(errors))
+		assert.NilError(t, errors[0])
+		assert.ErrorContains(t, errors[1], "UEI")
+		assert.NilError(t, errors[2])
+		assert.NilError(t, errors[3])
+	}
+}
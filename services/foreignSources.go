@@ -9,13 +9,20 @@ import (
 )
 
 type foreignSourcesAPI struct {
-	rest  api.RestAPI
-	utils api.ProvisioningUtilsAPI
+	rest                 api.RestAPI
+	utils                api.ProvisioningUtilsAPI
+	skipPluginValidation bool
 }
 
 // GetForeignSourcesAPI Obtain an implementation of the Foreign Source Definitions API
 func GetForeignSourcesAPI(rest api.RestAPI) api.ForeignSourcesAPI {
-	return &foreignSourcesAPI{rest, GetProvisioningUtilsAPI(rest)}
+	return GetForeignSourcesAPIWithValidation(rest, false)
+}
+
+// GetForeignSourcesAPIWithValidation Obtain an implementation of the Foreign Source Definitions API;
+// when skipPluginValidation is true, detectors and policies are not verified against the classes exposed by the server
+func GetForeignSourcesAPIWithValidation(rest api.RestAPI, skipPluginValidation bool) api.ForeignSourcesAPI {
+	return &foreignSourcesAPI{rest, GetProvisioningUtilsAPI(rest), skipPluginValidation}
 }
 
 func (api foreignSourcesAPI) GetForeignSourceDef(foreignSource string) (*model.ForeignSourceDef, error) {
@@ -30,7 +37,7 @@ func (api foreignSourcesAPI) GetForeignSourceDef(foreignSource string) (*model.F
 	if err != nil {
 		return nil, fmt.Errorf("Cannot retrieve foreign source definition %s", foreignSource)
 	}
-	if err := json.Unmarshal(jsonBytes, fsDef); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, fsDef); err != nil {
 		return nil, err
 	}
 	return fsDef, nil
@@ -80,6 +87,9 @@ func (api foreignSourcesAPI) DeleteForeignSourceDef(foreignSource string) error
 }
 
 func (api foreignSourcesAPI) IsPolicyValid(policy model.Policy) error {
+	if api.skipPluginValidation {
+		return policy.Validate()
+	}
 	config, err := api.utils.GetAvailablePolicies()
 	if err != nil {
 		return nil
@@ -93,7 +103,7 @@ func (api foreignSourcesAPI) isPolicyValid(config model.PluginList, policy model
 	}
 	plugin := config.FindPlugin(policy.Class)
 	if plugin == nil {
-		return fmt.Errorf("Cannot find policy with class %s", policy.Class)
+		return fmt.Errorf("Cannot find policy with class %s%s", policy.Class, config.SuggestClass(policy.Class))
 	}
 	if err := plugin.VerifyParameters(policy.Parameters); err != nil {
 		return err
@@ -102,6 +112,9 @@ func (api foreignSourcesAPI) isPolicyValid(config model.PluginList, policy model
 }
 
 func (api foreignSourcesAPI) IsDetectorValid(detector model.Detector) error {
+	if api.skipPluginValidation {
+		return detector.Validate()
+	}
 	config, err := api.utils.GetAvailableDetectors()
 	if err != nil {
 		return nil
@@ -115,7 +128,7 @@ func (api foreignSourcesAPI) isDetectorValid(config model.PluginList, detector m
 	}
 	plugin := config.FindPlugin(detector.Class)
 	if plugin == nil {
-		return fmt.Errorf("Cannot find detector with class %s", detector.Class)
+		return fmt.Errorf("Cannot find detector with class %s%s", detector.Class, config.SuggestClass(detector.Class))
 	}
 	if err := plugin.VerifyParameters(detector.Parameters); err != nil {
 		return err
@@ -127,6 +140,9 @@ func (api foreignSourcesAPI) IsForeignSourceValid(fsDef model.ForeignSourceDef)
 	if err := fsDef.Validate(); err != nil {
 		return err
 	}
+	if api.skipPluginValidation {
+		return nil
+	}
 	if len(fsDef.Policies) > 0 {
 		policiesConfig, err := api.utils.GetAvailablePolicies()
 		if err != nil {
@@ -164,6 +164,16 @@ func TestIsForeignSourceValid(t *testing.T) {
 		},
 	})
 	assert.ErrorContains(t, err, "Cannot find detector with class")
+
+	// The class is not verified against the server, but the detector is still validated
+	api = GetForeignSourcesAPIWithValidation(&mockForeignSourcesRest{t}, true)
+	err = api.IsDetectorValid(model.Detector{
+		Name:  "Custom",
+		Class: "org.opennms.example.CustomDetector",
+	})
+	assert.NilError(t, err)
+	err = api.IsDetectorValid(model.Detector{Name: "Custom"})
+	assert.Assert(t, err != nil)
 }
 
 func TestIsPolicyValid(t *testing.T) {
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type graphsAPI struct {
+	rest api.RestAPI
+}
+
+// GetGraphsAPI Obtain an implementation of the Graphs API
+func GetGraphsAPI(rest api.RestAPI) api.GraphsAPI {
+	return &graphsAPI{rest}
+}
+
+func (api graphsAPI) GetGraphNames(resourceID string) (*model.GraphNameList, error) {
+	if resourceID == "" {
+		return nil, fmt.Errorf("Resource ID required")
+	}
+	jsonInfo, err := api.rest.Get("/rest/graphs/for/" + resourceID)
+	if err != nil {
+		return nil, err
+	}
+	list := &model.GraphNameList{}
+	if len(jsonInfo) == 0 { // No content when the resource has no graphs
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonInfo, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api graphsAPI) GetGraph(name string) (*model.PrefabGraph, error) {
+	if name == "" {
+		return nil, fmt.Errorf("Graph name required")
+	}
+	jsonInfo, err := api.rest.Get("/rest/graphs/" + name)
+	if err != nil {
+		return nil, err
+	}
+	graph := &model.PrefabGraph{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonInfo, graph); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type mockGraphsRest struct{}
+
+func (api mockGraphsRest) Get(path string) ([]byte, error) {
+	switch path {
+	case "/rest/graphs/for/node[1].nodeSnmp[]":
+		return []byte(`{"name":["mib2.tcpopen","netsnmp.cpuStats"]}`), nil
+	case "/rest/graphs/for/node[1].interfaceSnmp[eth0]":
+		return []byte(`{"name":"mib2.HCbits"}`), nil
+	case "/rest/graphs/for/node[2].nodeSnmp[]":
+		return []byte{}, nil
+	case "/rest/graphs/mib2.tcpopen":
+		return []byte(`{"name":"mib2.tcpopen","title":"TCP Open Connections","columns":["tcpActiveOpens"],"command":"DEF:actOpen={rrd1}:tcpActiveOpens:AVERAGE LINE2:actOpen#ff0000:\"Active\""}`), nil
+	default:
+		return nil, fmt.Errorf("should not be called")
+	}
+}
+
+func (api mockGraphsRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockGraphsRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockGraphsRest) Put(path string, jsonBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetGraphNames(t *testing.T) {
+	api := GetGraphsAPI(mockGraphsRest{})
+
+	_, err := api.GetGraphNames("")
+	assert.Error(t, err, "Resource ID required")
+
+	list, err := api.GetGraphNames("node[1].nodeSnmp[]")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"mib2.tcpopen", "netsnmp.cpuStats"}, list.Names)
+
+	list, err = api.GetGraphNames("node[1].interfaceSnmp[eth0]")
+	assert.NilError(t, err)
+	assert.Assert(t, list.Contains("mib2.HCbits"))
+
+	list, err = api.GetGraphNames("node[2].nodeSnmp[]")
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(list.Names))
+}
+
+func TestGetGraph(t *testing.T) {
+	api := GetGraphsAPI(mockGraphsRest{})
+
+	_, err := api.GetGraph("")
+	assert.Error(t, err, "Graph name required")
+
+	graph, err := api.GetGraph("mib2.tcpopen")
+	assert.NilError(t, err)
+	assert.Equal(t, "TCP Open Connections", graph.Title)
+	assert.DeepEqual(t, []string{"tcpActiveOpens"}, graph.Columns)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type groupsAPI struct {
+	rest api.RestAPI
+}
+
+// GetGroupsAPI Obtain an implementation of the Groups API
+func GetGroupsAPI(rest api.RestAPI) api.GroupsAPI {
+	return &groupsAPI{rest}
+}
+
+func (api groupsAPI) GetGroups() (*model.OnmsGroupList, error) {
+	jsonBytes, err := api.rest.Get("/rest/groups?limit=0")
+	if err != nil {
+		return nil, err
+	}
+	list := &model.OnmsGroupList{}
+	if len(jsonBytes) == 0 {
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api groupsAPI) GetGroup(name string) (*model.OnmsGroup, error) {
+	if name == "" {
+		return nil, fmt.Errorf("Group name required")
+	}
+	jsonBytes, err := api.rest.Get("/rest/groups/" + url.PathEscape(name))
+	if err != nil {
+		return nil, fmt.Errorf("Cannot retrieve group %s: %w", name, err)
+	}
+	group := &model.OnmsGroup{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (api groupsAPI) AddGroup(group model.OnmsGroup) error {
+	if err := group.Validate(); err != nil {
+		return err
+	}
+	jsonBytes, err := json.Marshal(group)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post("/rest/groups", jsonBytes)
+}
+
+func (api groupsAPI) DeleteGroup(name string) error {
+	if name == "" {
+		return fmt.Errorf("Group name required")
+	}
+	return api.rest.Delete("/rest/groups/" + url.PathEscape(name))
+}
+
+func (api groupsAPI) AddUser(group string, user string) error {
+	if err := api.checkMember(group, user); err != nil {
+		return err
+	}
+	return api.rest.Put("/rest/groups/"+url.PathEscape(group)+"/users/"+url.PathEscape(user), nil, "application/json")
+}
+
+func (api groupsAPI) RemoveUser(group string, user string) error {
+	if err := api.checkMember(group, user); err != nil {
+		return err
+	}
+	return api.rest.Delete("/rest/groups/" + url.PathEscape(group) + "/users/" + url.PathEscape(user))
+}
+
+func (api groupsAPI) checkMember(group string, user string) error {
+	if group == "" {
+		return fmt.Errorf("Group name required")
+	}
+	if user == "" {
+		return fmt.Errorf("User ID required")
+	}
+	return model.ValidateUserID(user)
+}
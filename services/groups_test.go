@@ -0,0 +1,64 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type mockGroupsRest struct {
+	test  *testing.T
+	calls *[]string
+}
+
+func (api mockGroupsRest) Get(path string) ([]byte, error) {
+	switch path {
+	case "/rest/groups?limit=0":
+		return []byte(`{"count":1,"totalCount":1,"offset":0,"group":[{"name":"Admin","comments":"The administrators","user":["admin"]}]}`), nil
+	}
+	return nil, fmt.Errorf("should not be called")
+}
+
+func (api mockGroupsRest) Post(path string, jsonBytes []byte) error {
+	assert.Equal(api.test, "/rest/groups", path)
+	group := model.OnmsGroup{}
+	assert.NilError(api.test, json.Unmarshal(jsonBytes, &group))
+	*api.calls = append(*api.calls, "POST "+group.Name)
+	return nil
+}
+
+func (api mockGroupsRest) Delete(path string) error {
+	*api.calls = append(*api.calls, "DELETE "+path)
+	return nil
+}
+
+func (api mockGroupsRest) Put(path string, jsonBytes []byte, contentType string) error {
+	*api.calls = append(*api.calls, "PUT "+path)
+	return nil
+}
+
+func TestGroupsAPI(t *testing.T) {
+	calls := []string{}
+	api := GetGroupsAPI(mockGroupsRest{t, &calls})
+
+	list, err := api.GetGroups()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(list.Groups))
+	assert.Assert(t, list.Groups[0].HasUser("admin"))
+
+	assert.ErrorContains(t, api.AddGroup(model.OnmsGroup{}), "Group name cannot be empty")
+	assert.NilError(t, api.AddGroup(model.OnmsGroup{Name: "NOC", Users: []string{"admin"}}))
+	assert.NilError(t, api.AddUser("NOC", "jdoe"))
+	assert.ErrorContains(t, api.AddUser("NOC", "j:doe"), "cannot contain spaces")
+	assert.NilError(t, api.RemoveUser("NOC", "admin"))
+	assert.NilError(t, api.DeleteGroup("NOC"))
+	assert.DeepEqual(t, []string{
+		"POST NOC",
+		"PUT /rest/groups/NOC/users/jdoe",
+		"DELETE /rest/groups/NOC/users/admin",
+		"DELETE /rest/groups/NOC",
+	}, calls)
+}
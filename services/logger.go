@@ -0,0 +1,7 @@
+package services
+
+import "github.com/OpenNMS/onmsctl/model"
+
+// Log where the services report the messages that are not part of their results (e.x. FQDN translations);
+// the CLI injects its leveled logger, so nothing is written to stdout
+var Log model.Logger = model.NopLogger{}
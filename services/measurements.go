@@ -0,0 +1,39 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type measurementsAPI struct {
+	rest api.RestQueryAPI
+}
+
+// GetMeasurementsAPI Obtain an implementation of the Measurements API
+func GetMeasurementsAPI(rest api.RestQueryAPI) api.MeasurementsAPI {
+	return &measurementsAPI{rest}
+}
+
+func (api measurementsAPI) Query(request model.QueryRequest) (*model.QueryResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	jsonBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	data, err := api.rest.PostWithResponse("/rest/measurements", jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+	response := &model.QueryResponse{}
+	if len(data) == 0 { // No content when the resources have no data for the requested period
+		return response, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
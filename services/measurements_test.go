@@ -0,0 +1,56 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type mockMeasurementsRest struct {
+	test *testing.T
+}
+
+func (api mockMeasurementsRest) Get(path string) ([]byte, error) {
+	return nil, fmt.Errorf("should not be called")
+}
+
+func (api mockMeasurementsRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockMeasurementsRest) PostWithResponse(path string, jsonBytes []byte) ([]byte, error) {
+	assert.Equal(api.test, "/rest/measurements", path)
+	request := model.QueryRequest{}
+	assert.NilError(api.test, json.Unmarshal(jsonBytes, &request))
+	assert.Equal(api.test, "AVERAGE", request.Sources[0].Aggregation)
+	return []byte(`{"step":300000,"start":0,"end":600000,"timestamps":[0,300000],"labels":["ifInOctets"],"columns":[{"values":[10,"NaN"]}]}`), nil
+}
+
+func (api mockMeasurementsRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockMeasurementsRest) Put(path string, jsonBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestQueryMeasurements(t *testing.T) {
+	api := GetMeasurementsAPI(mockMeasurementsRest{t})
+
+	_, err := api.Query(model.QueryRequest{Start: 0, End: 600000, Step: 300000})
+	assert.Error(t, err, "At least one source required")
+
+	response, err := api.Query(model.QueryRequest{
+		Start:   0,
+		End:     600000,
+		Step:    300000,
+		Sources: []model.QuerySource{{ResourceID: "node[Servers:web01].interfaceSnmp[eth0]", Attribute: "ifInOctets"}},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"ifInOctets"}, response.Labels)
+	assert.Equal(t, model.MetricValue(10), response.Columns[0].Values[0])
+	assert.Assert(t, response.Columns[0].Values[1].IsNaN())
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type minionsAPI struct {
+	rest api.RestAPI
+}
+
+// GetMinionsAPI Obtain an implementation of the Minions API
+func GetMinionsAPI(rest api.RestAPI) api.MinionsAPI {
+	return &minionsAPI{rest}
+}
+
+func (api minionsAPI) GetMinions(filter string, limit int, offset int) (*model.OnmsMinionList, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("Limit cannot be negative")
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("Offset cannot be negative")
+	}
+	path := fmt.Sprintf("/api/v2/minions?limit=%d&offset=%d", limit, offset)
+	if filter != "" {
+		path += "&_s=" + url.QueryEscape(filter)
+	}
+	jsonBytes, err := api.rest.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	list := &model.OnmsMinionList{}
+	if len(jsonBytes) == 0 { // The v2 API returns no content when there are no matches
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api minionsAPI) GetMinion(id string) (*model.OnmsMinion, error) {
+	if id == "" {
+		return nil, fmt.Errorf("Minion ID required")
+	}
+	jsonBytes, err := api.rest.Get("/api/v2/minions/" + url.PathEscape(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(jsonBytes) == 0 {
+		return nil, fmt.Errorf("Minion %s doesn't exist", id)
+	}
+	minion := &model.OnmsMinion{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, minion); err != nil {
+		return nil, err
+	}
+	return minion, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type mockMinionsRest struct {
+	lastPath string
+	content  string
+}
+
+func (api *mockMinionsRest) Get(path string) ([]byte, error) {
+	api.lastPath = path
+	return []byte(api.content), nil
+}
+
+func (api mockMinionsRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockMinionsRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockMinionsRest) Put(path string, dataBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetMinions(t *testing.T) {
+	rest := &mockMinionsRest{content: `{"count":1,"totalCount":1,"offset":0,"minion":[{"id":"minion-01","label":"minion-01","location":"Apex","status":"UP","type":"Minion","lastUpdated":1600000000000}]}`}
+	api := GetMinionsAPI(rest)
+
+	list, err := api.GetMinions("location==Apex", 0, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/minions?limit=0&offset=0&_s=location%3D%3DApex", rest.lastPath)
+	assert.Equal(t, 1, len(list.Minions))
+	assert.Equal(t, "Apex", list.Minions[0].Location)
+	assert.Equal(t, int64(1600000000000), list.Minions[0].LastUpdated.UnixNano()/1e6)
+
+	rest.content = ""
+	list, err = api.GetMinions("", 0, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(list.Minions))
+
+	_, err = api.GetMinions("", -1, 0)
+	assert.Error(t, err, "Limit cannot be negative")
+}
+
+func TestGetMinion(t *testing.T) {
+	rest := &mockMinionsRest{content: `{"id":"minion 02","location":"Durham","status":"DOWN","lastUpdated":1600000000000}`}
+	api := GetMinionsAPI(rest)
+
+	minion, err := api.GetMinion("minion 02")
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/minions/minion%2002", rest.lastPath)
+	assert.Equal(t, "DOWN", minion.Status)
+
+	rest.content = ""
+	_, err = api.GetMinion("minion-03")
+	assert.Error(t, err, "Minion minion-03 doesn't exist")
+
+	_, err = api.GetMinion("")
+	assert.Error(t, err, "Minion ID required")
+}
@@ -2,6 +2,9 @@ package services
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
 
 	"github.com/OpenNMS/onmsctl/api"
 	"github.com/OpenNMS/onmsctl/model"
@@ -17,12 +20,15 @@ func GetMonitoringLocationsAPI(rest api.RestAPI) api.MonitoringLocationsAPI {
 }
 
 func (api monitoringLocationsAPI) GetLocations() (*model.MonitoringLocationList, error) {
-	jsonString, err := api.rest.Get("/api/v2/monitoringLocations")
+	jsonString, err := api.rest.Get("/api/v2/monitoringLocations?limit=0")
 	if err != nil {
 		return nil, err
 	}
 	locations := &model.MonitoringLocationList{}
-	if err := json.Unmarshal(jsonString, locations); err != nil {
+	if len(jsonString) == 0 { // The v2 API returns no content when there are no locations
+		return locations, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonString, locations); err != nil {
 		return nil, err
 	}
 	return locations, nil
@@ -42,21 +48,49 @@ func (api monitoringLocationsAPI) LocationExists(location string) (bool, error)
 }
 
 func (api monitoringLocationsAPI) GetLocation(location string) (*model.MonitoringLocation, error) {
+	if location == "" {
+		return nil, fmt.Errorf("Location name required")
+	}
 	jsonString, err := api.rest.Get("/api/v2/monitoringLocations/" + location)
 	if err != nil {
 		return nil, err
 	}
 	loc := &model.MonitoringLocation{}
-	if err := json.Unmarshal(jsonString, loc); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonString, loc); err != nil {
 		return nil, err
 	}
 	return loc, nil
 }
 
 func (api monitoringLocationsAPI) SetLocation(location model.MonitoringLocation) error {
+	if err := location.Validate(); err != nil {
+		return err
+	}
 	jsonBytes, err := json.Marshal(location)
 	if err != nil {
 		return err
 	}
 	return api.rest.Post("/api/v2/monitoringLocations", jsonBytes)
 }
+
+// UpdateLocation updates the area, geolocation, coordinates and priority of an existing location;
+// the v2 API only accepts simple properties on updates, so tags and packages are not changed
+func (api monitoringLocationsAPI) UpdateLocation(location model.MonitoringLocation) error {
+	if err := location.Validate(); err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("monitoringArea", location.MonitoringArea)
+	params.Set("geolocation", location.GeoLocation)
+	params.Set("latitude", strconv.FormatFloat(location.Latitude, 'f', -1, 64))
+	params.Set("longitude", strconv.FormatFloat(location.Longitude, 'f', -1, 64))
+	params.Set("priority", strconv.Itoa(location.Priority))
+	return api.rest.Put("/api/v2/monitoringLocations/"+location.LocationName, []byte(params.Encode()), "application/x-www-form-urlencoded")
+}
+
+func (api monitoringLocationsAPI) DeleteLocation(location string) error {
+	if location == "" {
+		return fmt.Errorf("Location name required")
+	}
+	return api.rest.Delete("/api/v2/monitoringLocations/" + location)
+}
@@ -12,11 +12,12 @@ import (
 type mockMonitoringLocationRest struct {
 	test     *testing.T
 	lastPath string
+	lastData string
 }
 
 func (api *mockMonitoringLocationRest) Get(path string) ([]byte, error) {
 	api.lastPath = path
-	if path == "/api/v2/monitoringLocations" {
+	if path == "/api/v2/monitoringLocations?limit=0" {
 		bytes, _ := json.Marshal(&model.MonitoringLocationList{
 			Count: 1,
 			Locations: []model.MonitoringLocation{
@@ -44,12 +45,16 @@ func (api mockMonitoringLocationRest) Post(path string, jsonBytes []byte) error
 	return nil
 }
 
-func (api mockMonitoringLocationRest) Delete(path string) error {
-	return fmt.Errorf("should not be called")
+func (api *mockMonitoringLocationRest) Delete(path string) error {
+	api.lastPath = path
+	return nil
 }
 
-func (api mockMonitoringLocationRest) Put(path string, jsonBytes []byte, contentType string) error {
-	return fmt.Errorf("should not be called")
+func (api *mockMonitoringLocationRest) Put(path string, dataBytes []byte, contentType string) error {
+	assert.Equal(api.test, "application/x-www-form-urlencoded", contentType)
+	api.lastPath = path
+	api.lastData = string(dataBytes)
+	return nil
 }
 
 func TestLocationExists(t *testing.T) {
@@ -97,3 +102,25 @@ func TestSetLocation(t *testing.T) {
 	})
 	assert.NilError(t, err)
 }
+
+func TestUpdateLocation(t *testing.T) {
+	rest := &mockMonitoringLocationRest{test: t}
+	api := GetMonitoringLocationsAPI(rest)
+
+	err := api.UpdateLocation(model.MonitoringLocation{LocationName: "Apex", Latitude: 95})
+	assert.ErrorContains(t, err, "Invalid latitude")
+
+	err = api.UpdateLocation(model.MonitoringLocation{LocationName: "Apex", Latitude: 35.73, Longitude: -78.85, Priority: 10})
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/monitoringLocations/Apex", rest.lastPath)
+	assert.Equal(t, "geolocation=&latitude=35.73&longitude=-78.85&monitoringArea=Apex&priority=10", rest.lastData)
+}
+
+func TestDeleteLocation(t *testing.T) {
+	rest := &mockMonitoringLocationRest{test: t}
+	api := GetMonitoringLocationsAPI(rest)
+
+	assert.Error(t, api.DeleteLocation(""), "Location name required")
+	assert.NilError(t, api.DeleteLocation("Apex"))
+	assert.Equal(t, "/api/v2/monitoringLocations/Apex", rest.lastPath)
+}
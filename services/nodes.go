@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type nodesAPI struct {
+	rest api.RestAPI
+}
+
+// GetNodesAPI Obtain an implementation of the Nodes API
+func GetNodesAPI(rest api.RestAPI) api.NodesAPI {
+	return &nodesAPI{rest}
+}
+
+func (api nodesAPI) GetNodes(filter string, limit int, offset int) (*model.OnmsNodeList, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("Limit cannot be negative")
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("Offset cannot be negative")
+	}
+	path := fmt.Sprintf("/api/v2/nodes?limit=%d&offset=%d", limit, offset)
+	if filter != "" {
+		path += "&_s=" + url.QueryEscape(filter)
+	}
+	jsonBytes, err := api.rest.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	list := &model.OnmsNodeList{}
+	if len(jsonBytes) == 0 { // The v2 API returns no content when there are no matches
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetAllNodes returns all the nodes matching the filter, requested in pages
+func (api nodesAPI) GetAllNodes(filter string) (*model.OnmsNodeList, error) {
+	list := &model.OnmsNodeList{Nodes: make([]model.OnmsNode, 0)}
+	err := GetPagerAPI(api.rest).ForEachPage("/api/v2/nodes", filterParams(filter), DefaultPageSize, func(body []byte) (int, error) {
+		page := &model.OnmsNodeList{}
+		if err := getDecoder(api.rest).DecodeJSON(body, page); err != nil {
+			return 0, err
+		}
+		list.Nodes = append(list.Nodes, page.Nodes...)
+		return len(page.Nodes), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	list.Count = len(list.Nodes)
+	list.TotalCount = list.Count
+	return list, nil
+}
+
+// GetNode finds a node by its ID or by its foreign source and foreign ID (e.x. Servers:web01)
+func (api nodesAPI) GetNode(criteria string) (*model.OnmsNode, error) {
+	filter, err := nodeCriteriaFilter(criteria)
+	if err != nil {
+		return nil, err
+	}
+	list, err := api.GetNodes(filter, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Nodes) == 0 {
+		return nil, fmt.Errorf("Node %s doesn't exist", criteria)
+	}
+	return &list.Nodes[0], nil
+}
+
+// DeleteNode removes a node from the database; it is added again on the next import if it is still on its requisition
+func (api nodesAPI) DeleteNode(id string) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return fmt.Errorf("Invalid node ID %s", id)
+	}
+	return api.rest.Delete("/api/v2/nodes/" + id)
+}
+
+// Builds the FIQL expression to find a node by its ID or by foreignSource:foreignID
+func nodeCriteriaFilter(criteria string) (string, error) {
+	if criteria == "" {
+		return "", fmt.Errorf("Node ID or foreignSource:foreignID required")
+	}
+	if _, err := strconv.Atoi(criteria); err == nil {
+		return "id==" + criteria, nil
+	}
+	parts := strings.SplitN(criteria, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("Invalid node %s, expected a node ID or foreignSource:foreignID", criteria)
+	}
+	return "foreignSource==" + parts[0] + ";foreignId==" + parts[1], nil
+}
+
+// GetIPInterfaces returns all the IP interfaces of a node
+func (api nodesAPI) GetIPInterfaces(id string) (*model.OnmsIPInterfaceList, error) {
+	list := &model.OnmsIPInterfaceList{}
+	if err := api.getNodeResource(id, "ipinterfaces", list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetSnmpInterfaces returns all the SNMP interfaces of a node
+func (api nodesAPI) GetSnmpInterfaces(id string) (*model.OnmsSnmpInterfaceList, error) {
+	list := &model.OnmsSnmpInterfaceList{}
+	if err := api.getNodeResource(id, "snmpinterfaces", list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetHardwareInventory returns the root entity of the hardware inventory of a node, or nil when the server returns no content
+func (api nodesAPI) GetHardwareInventory(id string) (*model.HwEntity, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, fmt.Errorf("Invalid node ID %s", id)
+	}
+	jsonBytes, err := api.rest.Get("/rest/hardwareInventory/" + id)
+	if err != nil {
+		return nil, err
+	}
+	if len(jsonBytes) == 0 {
+		return nil, nil
+	}
+	entity := &model.HwEntity{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Reads a sub-resource of a node without pagination; the target is left empty when there is no content
+func (api nodesAPI) getNodeResource(id string, resource string, target interface{}) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return fmt.Errorf("Invalid node ID %s", id)
+	}
+	jsonBytes, err := api.rest.Get(fmt.Sprintf("/api/v2/nodes/%s/%s?limit=0", id, resource))
+	if err != nil {
+		return err
+	}
+	if len(jsonBytes) == 0 {
+		return nil
+	}
+	return getDecoder(api.rest).DecodeJSON(jsonBytes, target)
+}
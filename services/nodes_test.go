@@ -0,0 +1,130 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+var mockNodes = &model.OnmsNodeList{
+	Count:      1,
+	TotalCount: 2,
+	Nodes: []model.OnmsNode{
+		{ID: "1", Label: "web01", ForeignSource: "Servers", ForeignID: "web01", Location: "Default"},
+	},
+}
+
+type mockNodesRest struct {
+	test     *testing.T
+	lastPath string
+	deleted  string
+}
+
+func (api *mockNodesRest) Get(path string) ([]byte, error) {
+	api.lastPath = path
+	assert.Assert(api.test, strings.HasPrefix(path, "/api/v2/nodes"))
+	u, _ := url.Parse(path)
+	switch u.Path {
+	case "/api/v2/nodes/1/ipinterfaces":
+		return []byte(`{"count":1,"totalCount":1,"offset":0,"ipInterface":[{"id":10,"ipAddress":"10.0.0.1","isManaged":"M","snmpPrimary":"P","ifIndex":2}]}`), nil
+	case "/api/v2/nodes/1/snmpinterfaces":
+		return []byte(`{"count":1,"totalCount":1,"offset":0,"snmpInterface":[{"id":20,"ifIndex":2,"ifName":"eth0","ifOperStatus":2,"lastSnmpPoll":1571000000000}]}`), nil
+	case "/api/v2/nodes/2/ipinterfaces", "/api/v2/nodes/2/snmpinterfaces":
+		return []byte{}, nil
+	}
+	if filter := u.Query().Get("_s"); filter == "label==none" || filter == "id==2" {
+		return []byte{}, nil
+	}
+	bytes, _ := json.Marshal(mockNodes)
+	return bytes, nil
+}
+
+func (api *mockNodesRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockNodesRest) Delete(path string) error {
+	api.deleted = path
+	return nil
+}
+
+func (api *mockNodesRest) Put(path string, jsonBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetNodes(t *testing.T) {
+	rest := &mockNodesRest{test: t}
+	api := GetNodesAPI(rest)
+
+	list, err := api.GetNodes("", 10, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/nodes?limit=10&offset=0", rest.lastPath)
+	assert.Equal(t, 1, list.Count)
+	assert.Equal(t, 2, list.TotalCount)
+	assert.Equal(t, "web01", list.Nodes[0].Label)
+
+	_, err = api.GetNodes("label==web*;location==MINION", 5, 5)
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/nodes?limit=5&offset=5&_s=label%3D%3Dweb%2A%3Blocation%3D%3DMINION", rest.lastPath)
+
+	list, err = api.GetNodes("label==none", 10, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(list.Nodes))
+
+	_, err = api.GetNodes("", -1, 0)
+	assert.ErrorContains(t, err, "Limit")
+}
+
+func TestGetAndDeleteNode(t *testing.T) {
+	rest := &mockNodesRest{test: t}
+	api := GetNodesAPI(rest)
+
+	node, err := api.GetNode("1")
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/nodes?limit=1&offset=0&_s=id%3D%3D1", rest.lastPath)
+	assert.Equal(t, "web01", node.Label)
+
+	_, err = api.GetNode("Servers:web01")
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/nodes?limit=1&offset=0&_s=foreignSource%3D%3DServers%3BforeignId%3D%3Dweb01", rest.lastPath)
+
+	_, err = api.GetNode("2")
+	assert.Error(t, err, "Node 2 doesn't exist")
+
+	_, err = api.GetNode("Servers:")
+	assert.ErrorContains(t, err, "Invalid node Servers:")
+
+	assert.NilError(t, api.DeleteNode("1"))
+	assert.Equal(t, "/api/v2/nodes/1", rest.deleted)
+	assert.Error(t, api.DeleteNode("web01"), "Invalid node ID web01")
+}
+
+func TestGetNodeInterfaces(t *testing.T) {
+	rest := &mockNodesRest{test: t}
+	api := GetNodesAPI(rest)
+
+	ipList, err := api.GetIPInterfaces("1")
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/nodes/1/ipinterfaces?limit=0", rest.lastPath)
+	assert.Equal(t, 1, len(ipList.Interfaces))
+	assert.Equal(t, "10.0.0.1", ipList.Interfaces[0].IPAddress)
+	assert.Equal(t, "P", ipList.Interfaces[0].SnmpPrimary)
+
+	snmpList, err := api.GetSnmpInterfaces("1")
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/nodes/1/snmpinterfaces?limit=0", rest.lastPath)
+	assert.Equal(t, "eth0", snmpList.Interfaces[0].IfName)
+	assert.Equal(t, int64(1571000000), snmpList.Interfaces[0].LastSnmpPoll.Unix())
+
+	ipList, err = api.GetIPInterfaces("2")
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(ipList.Interfaces))
+
+	_, err = api.GetSnmpInterfaces("Servers:web01")
+	assert.Error(t, err, "Invalid node ID Servers:web01")
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type notificationsAPI struct {
+	rest api.RestAPI
+}
+
+// GetNotificationsAPI Obtain an implementation of the Notifications API
+func GetNotificationsAPI(rest api.RestAPI) api.NotificationsAPI {
+	return &notificationsAPI{rest}
+}
+
+// GetStatus returns whether Notifd sends notifications (on) or not (off)
+func (api notificationsAPI) GetStatus() (string, error) {
+	jsonBytes, err := api.rest.Get("/rest/notifd/status")
+	if err != nil {
+		return "", err
+	}
+	status := struct {
+		Status string `json:"status"`
+	}{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, &status); err != nil {
+		return "", err
+	}
+	return status.Status, nil
+}
+
+// SetStatus turns notifications on or off globally
+func (api notificationsAPI) SetStatus(status string) error {
+	if !model.NotificationStatus.Contains(status) {
+		return fmt.Errorf("Invalid notification status %s, valid options: %s", status, model.NotificationStatus.EnumAsString())
+	}
+	params := url.Values{}
+	params.Set("status", status)
+	return api.rest.Put("/rest/notifd/status", []byte(params.Encode()), "application/x-www-form-urlencoded")
+}
+
+func (api notificationsAPI) GetEventNotifications() (*model.EventNotificationList, error) {
+	jsonBytes, err := api.rest.Get("/rest/notifd/events")
+	if err != nil {
+		return nil, err
+	}
+	list := &model.EventNotificationList{}
+	if len(jsonBytes) == 0 {
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api notificationsAPI) AddEventNotification(notification model.EventNotification) error {
+	if err := notification.Validate(); err != nil {
+		return err
+	}
+	jsonBytes, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post("/rest/notifd/events", jsonBytes)
+}
+
+func (api notificationsAPI) DeleteEventNotification(name string) error {
+	if name == "" {
+		return fmt.Errorf("Notification name required")
+	}
+	return api.rest.Delete("/rest/notifd/events/" + url.PathEscape(name))
+}
+
+func (api notificationsAPI) GetDestinationPaths() (*model.DestinationPathList, error) {
+	jsonBytes, err := api.rest.Get("/rest/notifd/destinationPaths")
+	if err != nil {
+		return nil, err
+	}
+	list := &model.DestinationPathList{}
+	if len(jsonBytes) == 0 {
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api notificationsAPI) GetDestinationPath(name string) (*model.DestinationPath, error) {
+	if name == "" {
+		return nil, fmt.Errorf("Destination path name required")
+	}
+	jsonBytes, err := api.rest.Get("/rest/notifd/destinationPaths/" + url.PathEscape(name))
+	if err != nil {
+		return nil, err
+	}
+	path := &model.DestinationPath{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, path); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// SetDestinationPath adds or replaces a destination path
+func (api notificationsAPI) SetDestinationPath(path model.DestinationPath) error {
+	if err := path.Validate(); err != nil {
+		return err
+	}
+	jsonBytes, err := json.Marshal(path)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post("/rest/notifd/destinationPaths", jsonBytes)
+}
+
+func (api notificationsAPI) DeleteDestinationPath(name string) error {
+	if name == "" {
+		return fmt.Errorf("Destination path name required")
+	}
+	return api.rest.Delete("/rest/notifd/destinationPaths/" + url.PathEscape(name))
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type mockNotificationsRest struct {
+	test  *testing.T
+	calls *[]string
+}
+
+func (api mockNotificationsRest) Get(path string) ([]byte, error) {
+	switch path {
+	case "/rest/notifd/status":
+		return []byte(`{"status":"on"}`), nil
+	case "/rest/notifd/events":
+		return []byte(`{"count":1,"notification":[{"name":"Node Down","status":"on","uei":"uei.opennms.org/nodes/nodeDown","rule":"IPADDR != '0.0.0.0'","destinationPath":"Email-Admin","text-message":"%logmsg%"}]}`), nil
+	case "/rest/notifd/destinationPaths":
+		return []byte{}, nil
+	case "/rest/notifd/destinationPaths/Email-Admin":
+		return []byte(`{"name":"Email-Admin","initial-delay":"0s","target":[{"name":"Admin","command":["javaEmail"]}],"escalate":[{"delay":"15m","target":[{"name":"Ops","command":["javaEmail"]}]}]}`), nil
+	}
+	return nil, fmt.Errorf("should not be called")
+}
+
+func (api mockNotificationsRest) Post(path string, jsonBytes []byte) error {
+	*api.calls = append(*api.calls, "POST "+path+" "+string(jsonBytes))
+	return nil
+}
+
+func (api mockNotificationsRest) Delete(path string) error {
+	*api.calls = append(*api.calls, "DELETE "+path)
+	return nil
+}
+
+func (api mockNotificationsRest) Put(path string, jsonBytes []byte, contentType string) error {
+	*api.calls = append(*api.calls, "PUT "+path+" "+string(jsonBytes))
+	return nil
+}
+
+func TestNotificationsAPI(t *testing.T) {
+	calls := []string{}
+	api := GetNotificationsAPI(mockNotificationsRest{t, &calls})
+
+	status, err := api.GetStatus()
+	assert.NilError(t, err)
+	assert.Equal(t, "on", status)
+	assert.NilError(t, api.SetStatus("off"))
+	assert.Error(t, api.SetStatus("disabled"), "Invalid notification status disabled, valid options: on, off")
+
+	notifications, err := api.GetEventNotifications()
+	assert.NilError(t, err)
+	assert.Equal(t, "Email-Admin", notifications.Notifications[0].DestinationPath)
+	assert.ErrorContains(t, api.AddEventNotification(model.EventNotification{Name: "Test"}), "requires an UEI")
+	assert.NilError(t, api.AddEventNotification(model.EventNotification{Name: "Test", UEI: "uei.opennms.org/test", DestinationPath: "Email-Admin"}))
+	assert.NilError(t, api.DeleteEventNotification("Node Down"))
+
+	paths, err := api.GetDestinationPaths()
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(paths.Paths))
+	path, err := api.GetDestinationPath("Email-Admin")
+	assert.NilError(t, err)
+	assert.Equal(t, "Ops", path.Escalations[0].Targets[0].Name)
+	path.Targets[0].Commands = nil
+	assert.Error(t, api.SetDestinationPath(*path), "Target Admin requires at least one command")
+	assert.NilError(t, api.SetDestinationPath(model.DestinationPath{Name: "Page", Targets: []model.NotificationTarget{{Name: "Admin", Commands: []string{"javaPagerEmail"}}}}))
+	assert.NilError(t, api.DeleteDestinationPath("Email Admin"))
+	assert.Error(t, api.DeleteDestinationPath(""), "Destination path name required")
+
+	assert.DeepEqual(t, []string{
+		"PUT /rest/notifd/status status=off",
+		`POST /rest/notifd/events {"name":"Test","status":"on","uei":"uei.opennms.org/test","rule":"IPADDR != '0.0.0.0'","destinationPath":"Email-Admin","text-message":"%logmsg%"}`,
+		"DELETE /rest/notifd/events/Node%20Down",
+		`POST /rest/notifd/destinationPaths {"name":"Page","target":[{"name":"Admin","command":["javaPagerEmail"]}]}`,
+		"DELETE /rest/notifd/destinationPaths/Email%20Admin",
+	}, calls)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type outagesAPI struct {
+	rest api.RestAPI
+}
+
+// GetOutagesAPI Obtain an implementation of the Outages API
+func GetOutagesAPI(rest api.RestAPI) api.OutagesAPI {
+	return &outagesAPI{rest}
+}
+
+func (api outagesAPI) GetOutages(filter string, limit int, offset int) (*model.OnmsOutageList, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("Limit cannot be negative")
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("Offset cannot be negative")
+	}
+	path := fmt.Sprintf("/api/v2/outages?limit=%d&offset=%d", limit, offset)
+	if filter != "" {
+		path += "&_s=" + url.QueryEscape(filter)
+	}
+	jsonBytes, err := api.rest.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	list := &model.OnmsOutageList{}
+	if len(jsonBytes) == 0 { // The v2 API returns no content when there are no matches
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetAllOutages returns all the outages matching the filter, requested in pages
+func (api outagesAPI) GetAllOutages(filter string) (*model.OnmsOutageList, error) {
+	list := &model.OnmsOutageList{Outages: make([]model.OnmsOutage, 0)}
+	err := GetPagerAPI(api.rest).ForEachPage("/api/v2/outages", filterParams(filter), DefaultPageSize, func(body []byte) (int, error) {
+		page := &model.OnmsOutageList{}
+		if err := getDecoder(api.rest).DecodeJSON(body, page); err != nil {
+			return 0, err
+		}
+		list.Outages = append(list.Outages, page.Outages...)
+		return len(page.Outages), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	list.Count = len(list.Outages)
+	list.TotalCount = list.Count
+	return list, nil
+}
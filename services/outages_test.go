@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type mockOutagesRest struct {
+	lastPath string
+	content  string
+}
+
+func (api *mockOutagesRest) Get(path string) ([]byte, error) {
+	api.lastPath = path
+	return []byte(api.content), nil
+}
+
+func (api mockOutagesRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockOutagesRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api mockOutagesRest) Put(path string, dataBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetOutages(t *testing.T) {
+	rest := &mockOutagesRest{content: `{"count":1,"totalCount":1,"offset":0,"outage":[{"id":5,"nodeId":1,"nodeLabel":"srv01","ipAddress":"10.0.0.1","locationName":"Default","ifLostService":1600000000000,"monitoredService":{"serviceType":{"name":"ICMP"}}}]}`}
+	api := GetOutagesAPI(rest)
+
+	list, err := api.GetOutages(`node.id==1;ifRegainedService==\u0000`, 0, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, `/api/v2/outages?limit=0&offset=0&_s=node.id%3D%3D1%3BifRegainedService%3D%3D%5Cu0000`, rest.lastPath)
+	assert.Equal(t, 1, len(list.Outages))
+	assert.Equal(t, "ICMP", list.Outages[0].MonitoredService.ServiceType.Name)
+	assert.Assert(t, list.Outages[0].IsCurrent())
+
+	rest.content = ""
+	list, err = api.GetOutages("", 10, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, "/api/v2/outages?limit=10&offset=0", rest.lastPath)
+	assert.Equal(t, 0, len(list.Outages))
+
+	_, err = api.GetOutages("", -1, 0)
+	assert.Error(t, err, "Limit cannot be negative")
+}
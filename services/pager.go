@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/OpenNMS/onmsctl/api"
+)
+
+// DefaultPageSize the number of elements requested per page when fetching a whole list
+const DefaultPageSize = 100
+
+type pagerAPI struct {
+	rest api.RestAPI
+}
+
+// GetPagerAPI Obtain an implementation of the Pager API
+func GetPagerAPI(rest api.RestAPI) api.PagerAPI {
+	return &pagerAPI{rest}
+}
+
+// The pagination fields shared by all the lists of the ReST API v2
+type page struct {
+	Count      int `json:"count"`
+	TotalCount int `json:"totalCount"`
+	Offset     int `json:"offset"`
+}
+
+// ForEachPage requests the pages of a list, passing each one to handle, which returns the number of elements it contained;
+// it follows the offset until totalCount is reached, and stops on an empty page, in case the server reported an inconsistent totalCount
+func (api pagerAPI) ForEachPage(path string, params url.Values, pageSize int, handle func(body []byte) (count int, err error)) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("Page size must be greater than 0")
+	}
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	offset := 0
+	for {
+		query.Set("limit", strconv.Itoa(pageSize))
+		query.Set("offset", strconv.Itoa(offset))
+		jsonBytes, err := api.rest.Get(path + "?" + query.Encode())
+		if err != nil {
+			return err
+		}
+		if len(jsonBytes) == 0 { // The v2 API returns no content when there are no more elements
+			return nil
+		}
+		info := &page{}
+		if err := json.Unmarshal(jsonBytes, info); err != nil {
+			return err
+		}
+		count, err := handle(jsonBytes)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		offset += count
+		if offset >= info.TotalCount {
+			return nil
+		}
+	}
+}
+
+// Returns the query parameters for a FIQL filter
+func filterParams(filter string) url.Values {
+	params := url.Values{}
+	if filter != "" {
+		params.Set("_s", filter)
+	}
+	return params
+}
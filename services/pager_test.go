@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+// mockPagerRest serves a list of nodes in pages, reporting the given totalCount
+type mockPagerRest struct {
+	test       *testing.T
+	nodes      []model.OnmsNode
+	totalCount int
+	requests   []string
+}
+
+func (api *mockPagerRest) Get(path string) ([]byte, error) {
+	api.requests = append(api.requests, path)
+	u, err := url.Parse(path)
+	assert.NilError(api.test, err)
+	assert.Equal(api.test, "/api/v2/nodes", u.Path)
+	if u.Query().Get("_s") == "error" {
+		return nil, fmt.Errorf("Invalid Response: 500 Internal Server Error")
+	}
+	limit, _ := strconv.Atoi(u.Query().Get("limit"))
+	offset, _ := strconv.Atoi(u.Query().Get("offset"))
+	if offset >= len(api.nodes) && api.totalCount <= len(api.nodes) {
+		return []byte{}, nil
+	}
+	if offset > len(api.nodes) {
+		offset = len(api.nodes)
+	}
+	end := offset + limit
+	if end > len(api.nodes) {
+		end = len(api.nodes)
+	}
+	page := model.OnmsNodeList{Count: end - offset, TotalCount: api.totalCount, Offset: offset, Nodes: api.nodes[offset:end]}
+	return json.Marshal(page)
+}
+
+func (api *mockPagerRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockPagerRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockPagerRest) Put(path string, jsonBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func newMockPagerRest(t *testing.T, nodes int, totalCount int) *mockPagerRest {
+	rest := &mockPagerRest{test: t, totalCount: totalCount}
+	for i := 1; i <= nodes; i++ {
+		rest.nodes = append(rest.nodes, model.OnmsNode{ID: strconv.Itoa(i), Label: fmt.Sprintf("srv%02d", i)})
+	}
+	return rest
+}
+
+func TestForEachPage(t *testing.T) {
+	rest := newMockPagerRest(t, 25, 25)
+	params := url.Values{}
+	params.Set("_s", "label==srv*")
+	labels := make([]string, 0)
+	err := GetPagerAPI(rest).ForEachPage("/api/v2/nodes", params, 10, func(body []byte) (int, error) {
+		page := &model.OnmsNodeList{}
+		if err := json.Unmarshal(body, page); err != nil {
+			return 0, err
+		}
+		for _, n := range page.Nodes {
+			labels = append(labels, n.Label)
+		}
+		return len(page.Nodes), nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, 25, len(labels))
+	assert.Equal(t, "srv25", labels[24])
+	assert.DeepEqual(t, []string{
+		"/api/v2/nodes?_s=label%3D%3Dsrv%2A&limit=10&offset=0",
+		"/api/v2/nodes?_s=label%3D%3Dsrv%2A&limit=10&offset=10",
+		"/api/v2/nodes?_s=label%3D%3Dsrv%2A&limit=10&offset=20",
+	}, rest.requests)
+	assert.Equal(t, "", params.Get("limit"), "The parameters of the caller are not modified")
+}
+
+func TestForEachPageInconsistentTotal(t *testing.T) {
+	count := func(body []byte) (int, error) {
+		page := &model.OnmsNodeList{}
+		err := json.Unmarshal(body, page)
+		return len(page.Nodes), err
+	}
+
+	// The server reports more elements than it has, and returns an empty page instead of no content
+	rest := newMockPagerRest(t, 25, 100)
+	empty := 0
+	err := GetPagerAPI(rest).ForEachPage("/api/v2/nodes", nil, 10, func(body []byte) (int, error) {
+		n, err := count(body)
+		if n == 0 {
+			empty++
+		}
+		return n, err
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, 4, len(rest.requests))
+	assert.Equal(t, 1, empty)
+
+	// The server doesn't report the total
+	rest = newMockPagerRest(t, 25, 0)
+	assert.NilError(t, GetPagerAPI(rest).ForEachPage("/api/v2/nodes", nil, 10, count))
+	assert.Equal(t, 1, len(rest.requests))
+}
+
+func TestForEachPageErrors(t *testing.T) {
+	rest := newMockPagerRest(t, 25, 25)
+	pager := GetPagerAPI(rest)
+	assert.Error(t, pager.ForEachPage("/api/v2/nodes", nil, 0, nil), "Page size must be greater than 0")
+	assert.Error(t, pager.ForEachPage("/api/v2/nodes", url.Values{"_s": {"error"}}, 10, nil), "Invalid Response: 500 Internal Server Error")
+	rest.requests = nil
+	err := pager.ForEachPage("/api/v2/nodes", nil, 10, func(body []byte) (int, error) {
+		return 0, fmt.Errorf("Cannot process page")
+	})
+	assert.Error(t, err, "Cannot process page")
+	assert.Equal(t, 1, len(rest.requests))
+}
+
+func TestGetAllNodes(t *testing.T) {
+	rest := newMockPagerRest(t, 250, 250)
+	list, err := GetNodesAPI(rest).GetAllNodes("")
+	assert.NilError(t, err)
+	assert.Equal(t, 250, len(list.Nodes))
+	assert.Equal(t, 250, list.TotalCount)
+	assert.Equal(t, 3, len(rest.requests))
+
+	rest = newMockPagerRest(t, 0, 0)
+	list, err = GetNodesAPI(rest).GetAllNodes("label==none")
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(list.Nodes))
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+// The endpoint of the servers that evaluate the poller packages of a node
+const pollerEvaluationPath = "/rest/config/polling/evaluate"
+
+type pollersAPI struct {
+	rest api.RestAPI
+}
+
+// GetPollersAPI Obtain an implementation of the Pollers API
+func GetPollersAPI(rest api.RestAPI) api.PollersAPI {
+	return &pollersAPI{rest}
+}
+
+// GetConfiguration returns the poller configuration that applies to a monitoring location
+func (api pollersAPI) GetConfiguration(location string) (*model.PollerConfiguration, error) {
+	if location == "" {
+		location = "Default"
+	}
+	data, err := api.rest.Get("/rest/config/" + url.PathEscape(location) + "/polling")
+	if err != nil {
+		return nil, err
+	}
+	config := &model.PollerConfiguration{}
+	// The configuration endpoints might ignore the Accept header and send the XML content of the file
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '<' {
+		err = getDecoder(api.rest).DecodeXML(trimmed, config)
+	} else {
+		err = getDecoder(api.rest).DecodeJSON(data, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// EvaluateNode returns the poller packages and services that apply to the IP interfaces of a node, given by its ID
+// or foreignSource:foreignID. The server evaluates them when it provides the endpoint; otherwise, or when clientSide
+// is requested, the filters are evaluated locally against the categories and IP interfaces of the node
+func (api pollersAPI) EvaluateNode(criteria string, clientSide bool) (*model.PollerEvaluation, error) {
+	if _, err := nodeCriteriaFilter(criteria); err != nil {
+		return nil, err
+	}
+	if !clientSide {
+		data, err := api.rest.Get(pollerEvaluationPath + "?node=" + url.QueryEscape(criteria))
+		if err == nil {
+			evaluation := &model.PollerEvaluation{}
+			if err := getDecoder(api.rest).DecodeJSON(data, evaluation); err != nil {
+				return nil, err
+			}
+			evaluation.Source = model.PollerEvaluationServer
+			return evaluation, nil
+		}
+		if !isMissingEndpoint(err) {
+			return nil, err
+		}
+		Log.Debugf("The server doesn't evaluate the poller packages, evaluating the filters on the client side")
+	}
+	nodes := GetNodesAPI(api.rest)
+	node, err := nodes.GetNode(criteria)
+	if err != nil {
+		return nil, err
+	}
+	interfaces, err := nodes.GetIPInterfaces(node.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot obtain the IP interfaces of node %s: %w", criteria, err)
+	}
+	config, err := api.GetConfiguration(node.Location)
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]string, 0, len(node.Categories))
+	for _, c := range node.Categories {
+		categories = append(categories, c.Name)
+	}
+	addresses := make([]string, 0, len(interfaces.Interfaces))
+	for _, intf := range interfaces.Interfaces {
+		addresses = append(addresses, intf.IPAddress)
+	}
+	return config.EvaluateNode(criteria, categories, addresses), nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"gotest.tools/assert"
+)
+
+type mockPollersRest struct {
+	evaluation bool
+	requests   []string
+}
+
+func (api *mockPollersRest) Get(path string) ([]byte, error) {
+	api.requests = append(api.requests, path)
+	switch path {
+	case "/rest/config/Durham/polling":
+		return []byte(`<poller-configuration threads="30">
+  <package name="production">
+    <filter>catincProduction</filter>
+    <include-range begin="10.0.0.1" end="10.0.0.254"/>
+    <service name="ICMP" interval="60000" status="on"/>
+  </package>
+  <monitor service="ICMP" class-name="org.opennms.netmgt.poller.monitors.IcmpMonitor"/>
+</poller-configuration>`), nil
+	case "/rest/config/Default/polling":
+		return []byte(`{"threads": 30, "package": [{"name": "example1", "filter": "IPADDR != '0.0.0.0'", "service": [{"name": "ICMP", "interval": 300000}]}], "monitor": []}`), nil
+	case "/rest/config/polling/evaluate?node=Servers%3Aweb01":
+		if api.evaluation {
+			return []byte(`{"node": "Servers:web01", "services": [{"ipAddress": "10.0.0.1", "package": "production", "service": "ICMP", "interval": 60000, "effective": true}]}`), nil
+		}
+	case "/api/v2/nodes?limit=1&offset=0&_s=foreignSource%3D%3DServers%3BforeignId%3D%3Dweb01":
+		return []byte(`{"count": 1, "totalCount": 1, "node": [{"id": "10", "label": "web01", "location": "Durham", "categories": [{"id": 1, "name": "Production"}]}]}`), nil
+	case "/api/v2/nodes/10/ipinterfaces?limit=0":
+		return []byte(`{"count": 2, "ipInterface": [{"id": 1, "ipAddress": "10.0.0.1"}, {"id": 2, "ipAddress": "192.168.0.1"}]}`), nil
+	default:
+		return nil, fmt.Errorf("should not be called")
+	}
+	return nil, &rest.APIError{Method: http.MethodGet, URL: path, StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+}
+
+func (api *mockPollersRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockPollersRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockPollersRest) Put(path string, dataBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetPollerConfiguration(t *testing.T) {
+	api := GetPollersAPI(&mockPollersRest{})
+	config, err := api.GetConfiguration("Durham")
+	assert.NilError(t, err)
+	assert.Equal(t, "catincProduction", config.Packages[0].Filter)
+	assert.Equal(t, "org.opennms.netmgt.poller.monitors.IcmpMonitor", config.Monitors[0].ClassName)
+
+	config, err = api.GetConfiguration("")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(300000), config.Packages[0].Services[0].Interval)
+}
+
+func TestEvaluateNodePollers(t *testing.T) {
+	mock := &mockPollersRest{evaluation: true}
+	api := GetPollersAPI(mock)
+	evaluation, err := api.EvaluateNode("Servers:web01", false)
+	assert.NilError(t, err)
+	assert.Equal(t, model.PollerEvaluationServer, evaluation.Source)
+	assert.Equal(t, 1, len(mock.requests))
+
+	// Evaluated on the client side when requested, or when the server doesn't provide the endpoint
+	for _, m := range []*mockPollersRest{{evaluation: true}, {evaluation: false}} {
+		evaluation, err = GetPollersAPI(m).EvaluateNode("Servers:web01", m.evaluation)
+		assert.NilError(t, err)
+		assert.Equal(t, model.PollerEvaluationClient, evaluation.Source)
+		assert.DeepEqual(t, []model.PollerServiceMatch{
+			{IPAddress: "10.0.0.1", Package: "production", Service: "ICMP", Interval: 60000, Status: "on", Effective: true},
+		}, evaluation.Services)
+		assert.Equal(t, "/rest/config/Durham/polling", m.requests[len(m.requests)-1])
+	}
+
+	_, err = api.EvaluateNode("web01", false)
+	assert.Error(t, err, "Invalid node web01, expected a node ID or foreignSource:foreignID")
+}
@@ -1,8 +1,8 @@
 package services
 
 import (
-	"encoding/json"
 	"fmt"
+	"net/url"
 
 	"github.com/OpenNMS/onmsctl/api"
 	"github.com/OpenNMS/onmsctl/model"
@@ -20,10 +20,10 @@ func GetProvisioningUtilsAPI(rest api.RestAPI) api.ProvisioningUtilsAPI {
 func (api provisioningUtilsAPI) GetRequisitionNames() (*model.RequisitionsList, error) {
 	jsonRequisitions, err := api.rest.Get("/rest/requisitionNames")
 	if err != nil {
-		return nil, fmt.Errorf("Cannot retrieve requisition names: %s", err)
+		return nil, err
 	}
 	requisitions := &model.RequisitionsList{}
-	if err := json.Unmarshal(jsonRequisitions, requisitions); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonRequisitions, requisitions); err != nil {
 		return nil, err
 	}
 	return requisitions, nil
@@ -50,7 +50,7 @@ func (api provisioningUtilsAPI) GetAvailableAssets() (*model.ElementList, error)
 	if err != nil {
 		return nil, fmt.Errorf("Cannot retrieve asset names list")
 	}
-	if err := json.Unmarshal(jsonAssets, assets); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonAssets, assets); err != nil {
 		return nil, err
 	}
 	return assets, nil
@@ -62,7 +62,7 @@ func (api provisioningUtilsAPI) GetAvailableDetectors() (*model.PluginList, erro
 	if err != nil {
 		return nil, fmt.Errorf("Cannot retrieve detector list")
 	}
-	if err := json.Unmarshal(jsonData, detectors); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonData, detectors); err != nil {
 		return nil, err
 	}
 	return detectors, nil
@@ -74,8 +74,24 @@ func (api provisioningUtilsAPI) GetAvailablePolicies() (*model.PluginList, error
 	if err != nil {
 		return nil, fmt.Errorf("Cannot retrieve policy list")
 	}
-	if err := json.Unmarshal(jsonData, policies); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonData, policies); err != nil {
 		return nil, err
 	}
 	return policies, nil
 }
+
+// GetAvailableServices returns the services the pollers know about for a given foreign source
+func (api provisioningUtilsAPI) GetAvailableServices(foreignSource string) (*model.ElementList, error) {
+	if foreignSource == "" {
+		return nil, fmt.Errorf("Requisition name required")
+	}
+	services := &model.ElementList{}
+	jsonData, err := api.rest.Get("/rest/foreignSourcesConfig/services/" + url.PathEscape(foreignSource))
+	if err != nil {
+		return nil, fmt.Errorf("Cannot retrieve service list")
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonData, services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
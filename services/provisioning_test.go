@@ -22,6 +22,8 @@ func (api mockProvisioningRest) Get(path string) ([]byte, error) {
 		return []byte(test.PoliciesJSON), nil
 	case "/rest/foreignSourcesConfig/detectors":
 		return []byte(test.DetectorsJSON), nil
+	case "/rest/foreignSourcesConfig/services/Test1":
+		return []byte(`{"count":2,"element":["ICMP","SNMP"]}`), nil
 	}
 	return nil, fmt.Errorf("should not be called")
 }
@@ -75,3 +77,13 @@ func TestGetAvailablePolicies(t *testing.T) {
 	assert.Equal(t, 1, list.Count)
 	assert.Equal(t, "Set Node Category", list.Plugins[0].Name)
 }
+
+func TestGetAvailableServices(t *testing.T) {
+	api := GetProvisioningUtilsAPI(&mockProvisioningRest{t})
+	list, err := api.GetAvailableServices("Test1")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"ICMP", "SNMP"}, list.Element)
+
+	_, err = api.GetAvailableServices("")
+	assert.Error(t, err, "Requisition name required")
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type reportsAPI struct {
+	rest api.RestAPI
+}
+
+// GetReportsAPI Obtain an implementation of the Reports API
+func GetReportsAPI(rest api.RestAPI) api.ReportsAPI {
+	return &reportsAPI{rest}
+}
+
+func (api reportsAPI) GetReports() (*model.ReportList, error) {
+	jsonData, err := api.rest.Get("/rest/reports")
+	if err != nil {
+		return nil, err
+	}
+	list := &model.ReportList{}
+	if len(jsonData) == 0 {
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonData, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api reportsAPI) GetReport(reportID string) (*model.ReportDetails, error) {
+	if reportID == "" {
+		return nil, fmt.Errorf("Report ID required")
+	}
+	jsonData, err := api.rest.Get("/rest/reports/" + url.PathEscape(reportID))
+	if err != nil {
+		return nil, err
+	}
+	details := &model.ReportDetails{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonData, details); err != nil {
+		return nil, err
+	}
+	if details.ID == "" {
+		details.ID = reportID
+	}
+	return details, nil
+}
+
+func (api reportsAPI) RunReport(request model.ReportRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	jsonBytes, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post("/rest/reports/"+url.PathEscape(request.ID)+"/deliver", jsonBytes)
+}
+
+func (api reportsAPI) GetPersistedReports() (*model.PersistedReportList, error) {
+	jsonData, err := api.rest.Get("/rest/reports/persisted")
+	if err != nil {
+		return nil, err
+	}
+	list := &model.PersistedReportList{}
+	if len(jsonData) == 0 {
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonData, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// WaitForReport polls the persisted reports until the server stores a run of the report that is not part of the previous list;
+// it returns rest.ErrCancelled when the context of the ReST API is cancelled while waiting
+func (api reportsAPI) WaitForReport(reportID string, previous model.PersistedReportList, timeout time.Duration, pollInterval time.Duration) (*model.PersistedReport, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		list, err := api.GetPersistedReports()
+		if err != nil {
+			return nil, err
+		}
+		if report := list.FindNew(reportID, previous); report != nil {
+			return report, nil
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return nil, fmt.Errorf("Timed out after %s waiting for report %s", timeout, reportID)
+		}
+		if err := sleep(api.rest, pollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (api reportsAPI) DownloadReport(persistedID int, format string) ([]byte, error) {
+	return api.rest.Get(fmt.Sprintf("/rest/reports/persisted/%d?format=%s", persistedID, url.QueryEscape(format)))
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"gotest.tools/assert"
+)
+
+type mockReportsRest struct {
+	polls    int
+	received *model.ReportRequest
+}
+
+func (api *mockReportsRest) Get(path string) ([]byte, error) {
+	switch path {
+	case "/rest/reports":
+		return []byte(`{"count": 1, "report": [{"id": "local_Event-Analysis", "online": true}]}`), nil
+	case "/rest/reports/persisted":
+		api.polls++
+		if api.polls < 3 {
+			return []byte(`[{"id": 1, "reportId": "local_Event-Analysis"}]`), nil
+		}
+		return []byte(`[{"id": 1, "reportId": "local_Event-Analysis"}, {"id": 2, "reportId": "local_Event-Analysis"}]`), nil
+	}
+	return nil, fmt.Errorf("should not be called")
+}
+
+func (api *mockReportsRest) Post(path string, jsonBytes []byte) error {
+	if path != "/rest/reports/local_Event-Analysis/deliver" {
+		return fmt.Errorf("should not be called")
+	}
+	api.received = &model.ReportRequest{}
+	return json.Unmarshal(jsonBytes, api.received)
+}
+
+func (api *mockReportsRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockReportsRest) Put(path string, dataBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetReports(t *testing.T) {
+	api := GetReportsAPI(&mockReportsRest{})
+	list, err := api.GetReports()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, list.Count)
+	assert.Equal(t, "local_Event-Analysis", list.Reports[0].ID)
+}
+
+func TestRunReport(t *testing.T) {
+	rest := &mockReportsRest{}
+	api := GetReportsAPI(rest)
+	err := api.RunReport(model.ReportRequest{ID: "local_Event-Analysis", Format: "PDF", SendMail: true})
+	assert.Error(t, err, "E-mail address required to deliver report local_Event-Analysis")
+	assert.NilError(t, api.RunReport(model.ReportRequest{ID: "local_Event-Analysis", Format: "PDF", Persist: true}))
+	assert.Equal(t, "PDF", rest.received.Format)
+}
+
+func TestWaitForReport(t *testing.T) {
+	rest := &mockReportsRest{}
+	api := GetReportsAPI(rest)
+	previous, err := api.GetPersistedReports()
+	assert.NilError(t, err)
+	report, err := api.WaitForReport("local_Event-Analysis", *previous, time.Second, time.Millisecond)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, report.ID)
+	assert.Equal(t, 3, rest.polls)
+
+	rest.polls = 0
+	_, err = api.WaitForReport("local_Event-Analysis", *previous, 0, time.Millisecond)
+	assert.Error(t, err, "Timed out after 0s waiting for report local_Event-Analysis")
+}
+
+// cancelledRest a ReST API bound to a context, whose requests are not interrupted by it
+type cancelledRest struct {
+	*mockReportsRest
+	ctx context.Context
+}
+
+func (r cancelledRest) Context() context.Context {
+	return r.ctx
+}
+
+func TestWaitForReportCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	api := GetReportsAPI(cancelledRest{&mockReportsRest{}, ctx})
+	previous, err := api.GetPersistedReports()
+	assert.NilError(t, err)
+	_, err = api.WaitForReport("local_Event-Analysis", *previous, time.Hour, time.Minute)
+	assert.Equal(t, rest.ErrCancelled, err)
+}
@@ -3,8 +3,12 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/common"
 	"github.com/OpenNMS/onmsctl/model"
 )
 
@@ -24,7 +28,7 @@ func (api requisitionsAPI) GetRequisitionsStats() (*model.RequisitionsStats, err
 		return nil, fmt.Errorf("Cannot retrieve requisition statistics")
 	}
 	stats := &model.RequisitionsStats{}
-	if err = json.Unmarshal(jsonStats, stats); err != nil {
+	if err = getDecoder(api.rest).DecodeJSON(jsonStats, stats); err != nil {
 		return nil, err
 	}
 	return stats, nil
@@ -56,7 +60,166 @@ func (api requisitionsAPI) GetRequisition(foreignSource string) (*model.Requisit
 		return nil, err
 	}
 	requisition := &model.Requisition{}
-	if err = json.Unmarshal(jsonString, requisition); err != nil {
+	if err = getDecoder(api.rest).DecodeJSON(jsonString, requisition); err != nil {
+		return nil, err
+	}
+	return requisition, nil
+}
+
+// GetRequisitions fetches multiple requisitions using a pool of workers;
+// the requisitions are returned in the given order, and the errors are reported per requisition without aborting the rest
+func (api requisitionsAPI) GetRequisitions(foreignSources []string, concurrency int) ([]model.Requisition, map[string]error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	jobs := make(chan int, len(foreignSources))
+	for i := range foreignSources {
+		jobs <- i
+	}
+	close(jobs)
+	results := make([]*model.Requisition, len(foreignSources))
+	errors := make([]error, len(foreignSources))
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errors[i] = api.fetchRequisition(foreignSources[i])
+			}
+		}()
+	}
+	wg.Wait()
+	requisitions := make([]model.Requisition, 0, len(foreignSources))
+	failures := make(map[string]error)
+	for i, fs := range foreignSources {
+		if errors[i] != nil {
+			failures[fs] = errors[i]
+		} else {
+			requisitions = append(requisitions, *results[i])
+		}
+	}
+	return requisitions, failures
+}
+
+// GetDeployedRequisitions fetches all the requisitions that have been imported
+func (api requisitionsAPI) GetDeployedRequisitions() ([]model.Requisition, error) {
+	jsonBytes, err := api.rest.Get("/rest/requisitions/deployed")
+	if err != nil {
+		return nil, err
+	}
+	collection := &model.RequisitionCollection{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, collection); err != nil {
+		return nil, err
+	}
+	return collection.Requisitions, nil
+}
+
+// ForEachNode calls fn with each node of a requisition, decoding the nodes while they are received when the ReST API supports streaming,
+// so the whole requisition is never held in memory; it stops on the first error returned by fn, and returns the requisition without nodes
+func (api requisitionsAPI) ForEachNode(foreignSource string, fn func(node model.RequisitionNode) error) (*model.Requisition, error) {
+	if foreignSource == "" {
+		return nil, fmt.Errorf("Requisition name required")
+	}
+	if !api.utils.RequisitionExists(foreignSource) {
+		return nil, fmt.Errorf("Requisition %s doesn't exist", foreignSource)
+	}
+	path := "/rest/requisitions/" + foreignSource
+	stream, ok := asStreamAPI(api.rest)
+	if !ok {
+		requisition, err := api.fetchRequisition(foreignSource)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range requisition.Nodes {
+			if err := fn(node); err != nil {
+				return nil, err
+			}
+		}
+		requisition.Nodes = nil
+		return requisition, nil
+	}
+	requisition := &model.Requisition{}
+	err := stream.GetStream(path, func(body io.Reader) error {
+		decoder := getDecoder(api.rest)
+		return decodeRequisition(decoder, decoder.NewJSONDecoder(body), requisition, fn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return requisition, nil
+}
+
+// Decodes the attributes of a requisition, passing each node to fn as soon as it is decoded, instead of adding it to the requisition
+func decodeRequisition(d common.Decoder, decoder *json.Decoder, requisition *model.Requisition, fn func(node model.RequisitionNode) error) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	header := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+		if key != "node" {
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return err
+			}
+			header[key] = value
+			continue
+		}
+		if err := expectDelim(decoder, '['); err != nil {
+			return err
+		}
+		for decoder.More() {
+			node := model.RequisitionNode{}
+			if err := d.CheckDecodedJSON(&node, decoder.Decode(&node)); err != nil {
+				return err
+			}
+			if err := fn(node); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return err
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	return d.DecodeJSON(data, requisition)
+}
+
+func asStreamAPI(rest api.RestAPI) (api.RestStreamAPI, bool) {
+	stream, ok := rest.(api.RestStreamAPI)
+	return stream, ok
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("Invalid requisition content, expected %s but got %v", delim, token)
+	}
+	return nil
+}
+
+// Fetches a requisition without verifying if it exists, as the list of names was obtained from the server
+func (api requisitionsAPI) fetchRequisition(foreignSource string) (*model.Requisition, error) {
+	jsonBytes, err := api.rest.Get("/rest/requisitions/" + foreignSource)
+	if err != nil {
+		return nil, err
+	}
+	requisition := &model.Requisition{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, requisition); err != nil {
 		return nil, err
 	}
 	return requisition, nil
@@ -70,6 +233,73 @@ func (api requisitionsAPI) SetRequisition(req model.Requisition) error {
 	return api.rest.Post("/rest/requisitions", jsonBytes)
 }
 
+// SetRequisitionChunked creates or updates the requisition without nodes, and then adds the nodes individually using a pool of workers;
+// the progress function is called after sending each node, and the nodes that couldn't be sent are returned in the original order
+func (api requisitionsAPI) SetRequisitionChunked(req model.Requisition, concurrency int, progress func(done int, total int)) ([]model.NodeError, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("Requisition name required")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	parents := make(map[string]bool)
+	for _, node := range req.Nodes {
+		if fs := node.ParentForeignSource; fs != "" && fs != req.Name && !parents[fs] {
+			if !api.utils.RequisitionExists(fs) {
+				return nil, fmt.Errorf("Cannot set parent foreign source for node %s as requisition %s doesn't exist", node.ForeignID, fs)
+			}
+			parents[fs] = true
+		}
+	}
+	jsonBytes, err := json.Marshal(model.Requisition{Name: req.Name, DateStamp: req.DateStamp})
+	if err != nil {
+		return nil, err
+	}
+	if err := api.rest.Post("/rest/requisitions", jsonBytes); err != nil {
+		return nil, err
+	}
+	total := len(req.Nodes)
+	jobs := make(chan int, total)
+	for i := range req.Nodes {
+		jobs <- i
+	}
+	close(jobs)
+	results := make(chan int, total)
+	errors := make([]error, total)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			for i := range jobs {
+				errors[i] = api.postNode(req.Name, req.Nodes[i])
+				results <- i
+			}
+		}()
+	}
+	for done := 1; done <= total; done++ {
+		<-results
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+	failures := make([]model.NodeError, 0)
+	for i, err := range errors {
+		if err != nil {
+			failures = append(failures, model.NodeError{ForeignID: req.Nodes[i].ForeignID, Err: err})
+		}
+	}
+	return failures, nil
+}
+
+func (api requisitionsAPI) postNode(foreignSource string, node model.RequisitionNode) error {
+	if err := node.Validate(); err != nil {
+		return err
+	}
+	jsonBytes, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post("/rest/requisitions/"+foreignSource+"/nodes", jsonBytes)
+}
+
 func (api requisitionsAPI) DeleteRequisition(foreignSource string) error {
 	if foreignSource == "" {
 		return fmt.Errorf("Requisition name required")
@@ -105,14 +335,44 @@ func (api requisitionsAPI) DeleteRequisition(foreignSource string) error {
 	return nil
 }
 
-func (api requisitionsAPI) ImportRequisition(foreignSource string, rescanExisting string) error {
+func (api requisitionsAPI) ImportRequisition(foreignSource string, rescan model.RescanMode) error {
 	if foreignSource == "" {
 		return fmt.Errorf("Requisition name required")
 	}
+	if rescan == "" {
+		rescan = model.RescanAll
+	}
+	if err := rescan.Validate(); err != nil {
+		return err
+	}
 	if !api.utils.RequisitionExists(foreignSource) {
 		return fmt.Errorf("Requisition %s doesn't exist", foreignSource)
 	}
-	return api.rest.Put("/rest/requisitions/"+foreignSource+"/import?rescanExisting="+rescanExisting, nil, "application/json")
+	return api.rest.Put("/rest/requisitions/"+foreignSource+"/import?rescanExisting="+string(rescan), nil, "application/json")
+}
+
+// WaitForImport polls the deployed statistics until the last import time of the requisition is newer than the given one
+func (api requisitionsAPI) WaitForImport(foreignSource string, lastImport *model.Time, timeout time.Duration, pollInterval time.Duration) (*model.RequisitionStats, error) {
+	if foreignSource == "" {
+		return nil, fmt.Errorf("Requisition name required")
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		stats, err := api.GetRequisitionsStats()
+		if err != nil {
+			return nil, err
+		}
+		current := stats.GetRequisitionStats(foreignSource)
+		if current.LastImport != nil && (lastImport == nil || current.LastImport.After(lastImport.Time)) {
+			return &current, nil
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return nil, fmt.Errorf("Timed out after %s waiting for requisition %s to be imported", timeout, foreignSource)
+		}
+		if err := sleep(api.rest, pollInterval); err != nil {
+			return nil, err
+		}
+	}
 }
 
 func (api requisitionsAPI) GetNode(foreignSource string, foreignID string) (*model.RequisitionNode, error) {
@@ -130,7 +390,7 @@ func (api requisitionsAPI) GetNode(foreignSource string, foreignID string) (*mod
 		return nil, fmt.Errorf("Cannot retrieve node %s from requisition %s", foreignID, foreignSource)
 	}
 	node := &model.RequisitionNode{}
-	if err := json.Unmarshal(jsonBytes, node); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, node); err != nil {
 		return nil, err
 	}
 	return node, nil
@@ -179,6 +439,7 @@ func (api requisitionsAPI) GetInterface(foreignSource string, foreignID string,
 	if ipAddress == "" {
 		return nil, fmt.Errorf("IP Address required")
 	}
+	ipAddress, _ = model.SplitIPZone(ipAddress)
 	if !api.utils.RequisitionExists(foreignSource) {
 		return nil, fmt.Errorf("Requisition %s doesn't exist", foreignSource)
 	}
@@ -187,7 +448,7 @@ func (api requisitionsAPI) GetInterface(foreignSource string, foreignID string,
 		return nil, err
 	}
 	intf := &model.RequisitionInterface{}
-	if err := json.Unmarshal(jsonString, intf); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonString, intf); err != nil {
 		return nil, err
 	}
 	return intf, nil
@@ -223,6 +484,7 @@ func (api requisitionsAPI) DeleteInterface(foreignSource string, foreignID strin
 	if ipAddress == "" {
 		return fmt.Errorf("IP Address required")
 	}
+	ipAddress, _ = model.SplitIPZone(ipAddress)
 	if !api.utils.RequisitionExists(foreignSource) {
 		return fmt.Errorf("Requisition %s doesn't exist", foreignSource)
 	}
@@ -333,7 +595,7 @@ func (api requisitionsAPI) SetAsset(foreignSource string, foreignID string, asse
 		}
 	}
 	if !found {
-		return fmt.Errorf("Invalid Asset Field: %s", asset.Name)
+		return fmt.Errorf("Invalid Asset Field: %s%s", asset.Name, model.DidYouMean(asset.Name, assets.Element))
 	}
 	jsonBytes, err := json.Marshal(asset)
 	if err != nil {
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+// mockStreamRest serves the requisitions of mockRequisitionsRest, or a fixed requisition, through GetStream
+type mockStreamRest struct {
+	mockRequisitionsRest
+	data []byte
+}
+
+func (api mockStreamRest) Get(path string) ([]byte, error) {
+	if api.data != nil && path == "/rest/requisitions/Test1" {
+		return api.data, nil
+	}
+	return api.mockRequisitionsRest.Get(path)
+}
+
+func (api mockStreamRest) GetStream(path string, consume func(body io.Reader) error) error {
+	data, err := api.Get(path)
+	if err != nil {
+		return err
+	}
+	return consume(bytes.NewReader(data))
+}
+
+// strictStreamRest a mockStreamRest whose responses must be decoded strictly
+type strictStreamRest struct {
+	mockStreamRest
+}
+
+func (api strictStreamRest) IsStrictDecode() bool {
+	return true
+}
+
+// Builds a synthetic requisition with the given amount of nodes
+func buildLargeRequisition(nodes int) model.Requisition {
+	requisition := model.Requisition{Name: "Test1", DateStamp: &model.Time{}}
+	for i := 0; i < nodes; i++ {
+		requisition.Nodes = append(requisition.Nodes, model.RequisitionNode{
+			ForeignID: fmt.Sprintf("n%d", i),
+			NodeLabel: fmt.Sprintf("node%05d.example.com", i),
+			Interfaces: []model.RequisitionInterface{
+				{
+					IPAddress:   fmt.Sprintf("10.%d.%d.%d", i/65536, (i/256)%256, i%256),
+					SnmpPrimary: "P",
+					Services:    []model.RequisitionMonitoredService{{Name: "ICMP"}, {Name: "SNMP"}},
+				},
+			},
+			Categories: []model.RequisitionCategory{{Name: "Servers"}},
+			Assets:     []model.RequisitionAsset{{Name: "city", Value: "Durham"}},
+		})
+	}
+	return requisition
+}
+
+func TestForEachNode(t *testing.T) {
+	original := buildLargeRequisition(100)
+	data, err := json.Marshal(original)
+	assert.NilError(t, err)
+	// With and without streaming support
+	for _, rest := range []api.RestAPI{mockStreamRest{mockRequisitionsRest{t}, data}, mockRequisitionsRestWithData{mockRequisitionsRest{t}, data}} {
+		api := GetRequisitionsAPI(rest)
+		nodes := make([]model.RequisitionNode, 0)
+		header, err := api.ForEachNode("Test1", func(node model.RequisitionNode) error {
+			nodes = append(nodes, node)
+			return nil
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, "Test1", header.Name)
+		assert.Assert(t, header.DateStamp != nil)
+		assert.Equal(t, 0, len(header.Nodes))
+		// The output must be identical to the one from GetRequisition
+		full, err := api.GetRequisition("Test1")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, full.Nodes, nodes)
+		header.Nodes = nodes
+		assert.DeepEqual(t, full, header)
+	}
+}
+
+func TestForEachNodeStrictDecode(t *testing.T) {
+	ignore := func(node model.RequisitionNode) error { return nil }
+
+	data, err := json.Marshal(buildLargeRequisition(3))
+	assert.NilError(t, err)
+	valid := GetRequisitionsAPI(strictStreamRest{mockStreamRest{mockRequisitionsRest{t}, data}})
+	_, err = valid.ForEachNode("Test1", ignore)
+	assert.NilError(t, err)
+
+	// The mode belongs to each client, and it is kept when the client is bound to a context
+	unknownData := []byte(`{"foreign-source":"Test1","node":[{"foreign-id":"n1","node-label":"n1","sys-contact":"ops"}]}`)
+	lenient := GetRequisitionsAPI(mockStreamRest{mockRequisitionsRest{t}, unknownData})
+	_, err = lenient.ForEachNode("Test1", ignore)
+	assert.NilError(t, err)
+	unknown := GetRequisitionsAPI(strictStreamRest{mockStreamRest{mockRequisitionsRest{t}, unknownData}})
+	_, err = unknown.ForEachNode("Test1", ignore)
+	assert.Error(t, err, `The response doesn't match model.RequisitionNode (strict decode): json: unknown field "sys-contact"`)
+	bound := GetRequisitionsAPI(WithContext(context.Background(), strictStreamRest{mockStreamRest{mockRequisitionsRest{t}, unknownData}}))
+	_, err = bound.ForEachNode("Test1", ignore)
+	assert.ErrorContains(t, err, `json: unknown field "sys-contact"`)
+
+	missing := GetRequisitionsAPI(strictStreamRest{mockStreamRest{mockRequisitionsRest{t}, []byte(`{"foreign-source":"Test1","node":[{"foreign-id":"n1"}]}`)}})
+	_, err = missing.ForEachNode("Test1", ignore)
+	assert.Error(t, err, "The response doesn't match model.RequisitionNode (strict decode): required field node-label is empty")
+}
+
+func TestForEachNodeWithError(t *testing.T) {
+	data, err := json.Marshal(buildLargeRequisition(10))
+	assert.NilError(t, err)
+	api := GetRequisitionsAPI(mockStreamRest{mockRequisitionsRest{t}, data})
+	count := 0
+	_, err = api.ForEachNode("Test1", func(node model.RequisitionNode) error {
+		count++
+		if count == 3 {
+			return fmt.Errorf("Stop at %s", node.ForeignID)
+		}
+		return nil
+	})
+	assert.Error(t, err, "Stop at n2")
+	assert.Equal(t, 3, count)
+
+	_, err = api.ForEachNode("Test3", func(node model.RequisitionNode) error { return nil })
+	assert.Error(t, err, "Requisition Test3 doesn't exist")
+
+	api = GetRequisitionsAPI(mockStreamRest{mockRequisitionsRest{t}, []byte(`{"foreign-source":"Test1","node":[{"foreign-id":"n1"}`)})
+	_, err = api.ForEachNode("Test1", func(node model.RequisitionNode) error { return nil })
+	assert.ErrorContains(t, err, "unexpected end of JSON input")
+}
+
+// mockRequisitionsRestWithData serves a fixed requisition without streaming support
+type mockRequisitionsRestWithData struct {
+	mockRequisitionsRest
+	data []byte
+}
+
+func (api mockRequisitionsRestWithData) Get(path string) ([]byte, error) {
+	if path == "/rest/requisitions/Test1" {
+		return api.data, nil
+	}
+	return api.mockRequisitionsRest.Get(path)
+}
+
+// The benchmarks compare the memory used to process a requisition with 50000 nodes (run with -bench 50k -benchmem)
+func getLargeRequisitionData(b *testing.B) []byte {
+	data, err := json.Marshal(buildLargeRequisition(50000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func BenchmarkGetRequisition50k(b *testing.B) {
+	api := GetRequisitionsAPI(mockStreamRest{mockRequisitionsRest{}, getLargeRequisitionData(b)})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		requisition, err := api.GetRequisition("Test1")
+		if err != nil || len(requisition.Nodes) != 50000 {
+			b.Fatalf("unexpected result: %v", err)
+		}
+	}
+}
+
+func BenchmarkForEachNode50k(b *testing.B) {
+	api := GetRequisitionsAPI(mockStreamRest{mockRequisitionsRest{}, getLargeRequisitionData(b)})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		count := 0
+		_, err := api.ForEachNode("Test1", func(node model.RequisitionNode) error {
+			count++
+			return nil
+		})
+		if err != nil || count != 50000 {
+			b.Fatalf("unexpected result: %v", err)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/OpenNMS/onmsctl/model"
 	"github.com/OpenNMS/onmsctl/test"
@@ -56,6 +59,8 @@ func (api mockRequisitionsRest) Get(path string) ([]byte, error) {
 		return []byte(`{"count":1,"foreign-source":[{"count":1,"last-imported":1567526173532,"name":"Test"}]}`), nil
 	case "/rest/requisitions/Test1":
 		return json.Marshal(mockRequisition)
+	case "/rest/requisitions/deployed":
+		return json.Marshal(model.RequisitionCollection{Count: 1, Requisitions: []model.Requisition{mockRequisition}})
 	case "/rest/requisitions/Test1/nodes/n1":
 		return json.Marshal(mockRequisition.Nodes[0])
 	case "/rest/requisitions/Test1/nodes/n1/interfaces/10.0.0.1":
@@ -168,12 +173,91 @@ func TestGetRequisition(t *testing.T) {
 	assert.Equal(t, mockRequisition.Name, req.Name)
 }
 
+func TestGetRequisitions(t *testing.T) {
+	api := GetRequisitionsAPI(&mockRequisitionsRest{t})
+	requisitions, failures := api.GetRequisitions([]string{"Test1", "Test2", "Test1"}, 2)
+	assert.Equal(t, 2, len(requisitions))
+	assert.Equal(t, mockRequisition.Name, requisitions[1].Name)
+	assert.Equal(t, 1, len(failures))
+	assert.Error(t, failures["Test2"], "GET: should not be called with path /rest/requisitions/Test2")
+}
+
+func TestGetDeployedRequisitions(t *testing.T) {
+	api := GetRequisitionsAPI(&mockRequisitionsRest{t})
+	requisitions, err := api.GetDeployedRequisitions()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(requisitions))
+	assert.Equal(t, "n1", requisitions[0].Nodes[0].ForeignID)
+}
+
 func TestSetRequisition(t *testing.T) {
 	api := GetRequisitionsAPI(&mockRequisitionsRest{t})
 	err := api.SetRequisition(mockRequisition)
 	assert.NilError(t, err)
 }
 
+type mockChunkedRest struct {
+	mockRequisitionsRest
+	mutex    sync.Mutex
+	emptied  bool
+	received []string
+}
+
+func (api *mockChunkedRest) Post(path string, jsonBytes []byte) error {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+	switch path {
+	case "/rest/requisitions":
+		req := &model.Requisition{}
+		json.Unmarshal(jsonBytes, req)
+		assert.Equal(api.t, "Large", req.Name)
+		assert.Equal(api.t, 0, len(req.Nodes))
+		api.emptied = true
+		return nil
+	case "/rest/requisitions/Large/nodes":
+		assert.Assert(api.t, api.emptied, "the nodes were sent before the requisition")
+		node := &model.RequisitionNode{}
+		json.Unmarshal(jsonBytes, node)
+		if strings.HasSuffix(node.ForeignID, "7") {
+			return fmt.Errorf("Invalid Response: 500 Internal Server Error")
+		}
+		api.received = append(api.received, node.ForeignID)
+		return nil
+	}
+	return fmt.Errorf("POST: should not be called with path %s", path)
+}
+
+func TestSetRequisitionChunked(t *testing.T) {
+	rest := &mockChunkedRest{mockRequisitionsRest: mockRequisitionsRest{t}}
+	api := GetRequisitionsAPI(rest)
+	req := model.Requisition{Name: "Large"}
+	for i := 1; i <= 50; i++ {
+		id := fmt.Sprintf("n%d", i)
+		req.Nodes = append(req.Nodes, model.RequisitionNode{ForeignID: id, NodeLabel: id})
+	}
+	req.Nodes[3].Interfaces = []model.RequisitionInterface{{IPAddress: "10.0.0.500"}}
+
+	var calls []int
+	failures, err := api.SetRequisitionChunked(req, 8, func(done int, total int) {
+		assert.Equal(t, 50, total)
+		calls = append(calls, done)
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, 50, len(calls))
+	assert.Equal(t, 50, calls[49])
+	assert.Equal(t, 44, len(rest.received))
+	ids := make([]string, len(failures))
+	for i, f := range failures {
+		ids[i] = f.ForeignID
+	}
+	assert.DeepEqual(t, []string{"n4", "n7", "n17", "n27", "n37", "n47"}, ids)
+	assert.ErrorContains(t, failures[1], "n7: Invalid Response: 500")
+
+	req.Nodes[0].ParentForeignSource = "Unknown"
+	_, err = api.SetRequisitionChunked(req, 8, nil)
+	assert.Error(t, err, "Cannot set parent foreign source for node n1 as requisition Unknown doesn't exist")
+}
+
 func TestDeleteRequisition(t *testing.T) {
 	api := GetRequisitionsAPI(&mockRequisitionsRest{t})
 	err := api.DeleteRequisition(mockRequisition.Name)
@@ -182,8 +266,20 @@ func TestDeleteRequisition(t *testing.T) {
 
 func TestImportRequisition(t *testing.T) {
 	api := GetRequisitionsAPI(&mockRequisitionsRest{t})
-	err := api.ImportRequisition(mockRequisition.Name, "false")
+	err := api.ImportRequisition(mockRequisition.Name, model.RescanNone)
 	assert.NilError(t, err)
+	err = api.ImportRequisition(mockRequisition.Name, "always")
+	assert.Error(t, err, "Invalid rescan mode always; allowed values are true, false, dbonly")
+}
+
+func TestWaitForImport(t *testing.T) {
+	api := GetRequisitionsAPI(&mockRequisitionsRest{t})
+	stats, err := api.WaitForImport("Test", nil, time.Second, time.Millisecond)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, stats.Count)
+
+	_, err = api.WaitForImport("Test", stats.LastImport, 10*time.Millisecond, time.Millisecond)
+	assert.Error(t, err, "Timed out after 10ms waiting for requisition Test to be imported")
 }
 
 func TestGetNode(t *testing.T) {
@@ -1,7 +1,6 @@
 package services
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/OpenNMS/onmsctl/api"
@@ -17,28 +16,28 @@ func GetResourcesAPI(rest api.RestAPI) api.ResourcesAPI {
 	return &resourcesAPI{rest}
 }
 
-func (api resourcesAPI) GetResourceForNode(nodeCriteria string) (*model.Resource, error) {
+func (api resourcesAPI) GetResourceForNode(nodeCriteria string, depth int) (*model.Resource, error) {
 	if nodeCriteria == "" {
 		return nil, fmt.Errorf("Node ID or Foreign-Source:Foreign-ID combination required")
 	}
-	jsonInfo, err := api.rest.Get("/rest/resources/fornode/" + nodeCriteria)
+	jsonInfo, err := api.rest.Get("/rest/resources/fornode/" + nodeCriteria + depthParam(depth))
 	if err != nil {
 		return nil, err
 	}
 	resource := &model.Resource{}
-	if err := json.Unmarshal(jsonInfo, resource); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonInfo, resource); err != nil {
 		return nil, err
 	}
 	return resource, nil
 }
 
-func (api resourcesAPI) GetResources() (*model.ResourceList, error) {
-	jsonInfo, err := api.rest.Get("/rest/resources")
+func (api resourcesAPI) GetResources(depth int) (*model.ResourceList, error) {
+	jsonInfo, err := api.rest.Get("/rest/resources" + depthParam(depth))
 	if err != nil {
 		return nil, err
 	}
 	resourceList := &model.ResourceList{}
-	if err := json.Unmarshal(jsonInfo, resourceList); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonInfo, resourceList); err != nil {
 		return nil, err
 	}
 	return resourceList, nil
@@ -53,7 +52,7 @@ func (api resourcesAPI) GetResource(resourceID string) (*model.Resource, error)
 		return nil, err
 	}
 	resource := &model.Resource{}
-	if err := json.Unmarshal(jsonInfo, resource); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonInfo, resource); err != nil {
 		return nil, err
 	}
 	return resource, nil
@@ -69,3 +68,8 @@ func (api resourcesAPI) DeleteResource(resourceID string) error {
 	}
 	return nil
 }
+
+// The depth of the children to include; negative values mean all of them
+func depthParam(depth int) string {
+	return fmt.Sprintf("?depth=%d", depth)
+}
@@ -26,13 +26,13 @@ type mockResourceRest struct {
 
 func (api mockResourceRest) Get(path string) ([]byte, error) {
 	switch path {
-	case "/rest/resources":
+	case "/rest/resources?depth=1":
 		bytes, _ := json.Marshal(mockResources)
 		return bytes, nil
 	case "/rest/resources/node[1].nodeSnmp[]":
 		bytes, _ := json.Marshal(mockResources.Resources[0])
 		return bytes, nil
-	case "/rest/resources/fornode/1":
+	case "/rest/resources/fornode/1?depth=-1":
 		bytes, _ := json.Marshal(mockResources.Resources[0])
 		return bytes, nil
 	default:
@@ -58,7 +58,7 @@ func (api mockResourceRest) Put(path string, jsonBytes []byte, contentType strin
 func TestGetResources(t *testing.T) {
 	rest := &mockResourceRest{test: t}
 	api := GetResourcesAPI(rest)
-	list, err := api.GetResources()
+	list, err := api.GetResources(1)
 	assert.NilError(t, err)
 	assert.Equal(t, 1, list.Count)
 }
@@ -74,7 +74,7 @@ func TestGetResource(t *testing.T) {
 func TestGetResourceForNode(t *testing.T) {
 	rest := &mockResourceRest{test: t}
 	api := GetResourcesAPI(rest)
-	r, err := api.GetResourceForNode("1")
+	r, err := api.GetResourceForNode("1", -1)
 	assert.NilError(t, err)
 	assert.Equal(t, "Sample", r.Label)
 }
@@ -32,7 +32,7 @@ func (api snmpAPI) GetConfig(ipAddress string, location string) (*model.SnmpInfo
 		return nil, err
 	}
 	snmp := &model.SnmpInfo{}
-	if err := json.Unmarshal(jsonString, snmp); err != nil {
+	if err := getDecoder(api.rest).DecodeJSON(jsonString, snmp); err != nil {
 		return nil, err
 	}
 	return snmp, nil
@@ -54,6 +54,17 @@ func (api snmpAPI) SetConfig(ipAddress string, config model.SnmpInfo) error {
 	return api.rest.Put("/rest/snmpConfig/"+ipAddress, jsonBytes, "application/json")
 }
 
+func (api snmpAPI) SetRangeConfig(config model.SnmpRange) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	jsonBytes, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return api.rest.Put("/rest/snmpConfig/"+config.FirstIPAddress, jsonBytes, "application/json")
+}
+
 func (api snmpAPI) validateIPAddress(ipAddress string) (string, error) {
 	if ipAddress == "" {
 		return "", fmt.Errorf("IP Address or FQDN required")
@@ -62,9 +73,9 @@ func (api snmpAPI) validateIPAddress(ipAddress string) (string, error) {
 	if ip == nil {
 		addresses, err := net.LookupIP(ipAddress)
 		if err != nil || len(addresses) == 0 {
-			return "", fmt.Errorf("Cannot parse address from %s (invalid IP or FQDN); %s", ipAddress, err)
+			return "", fmt.Errorf("Cannot parse address from %s (invalid IP or FQDN); %w", ipAddress, err)
 		}
-		fmt.Printf("%s translates to %s\n", ipAddress, addresses[0].String())
+		Log.Infof("%s translates to %s", ipAddress, addresses[0].String())
 		ipAddress = addresses[0].String()
 	}
 	return ipAddress, nil
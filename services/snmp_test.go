@@ -46,9 +46,29 @@ func (api mockSnmpInfoRest) Put(path string, jsonBytes []byte, contentType strin
 	return nil
 }
 
+// recordingLogger keeps the messages reported by the services
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Infof(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
 func TestGetConfig(t *testing.T) {
 	rest := &mockSnmpInfoRest{test: t}
 	api := GetSnmpAPI(rest)
+	log := &recordingLogger{}
+	Log = log
+	defer func() { Log = model.NopLogger{} }()
 
 	snmp, err := api.GetConfig("", "")
 	assert.Assert(t, snmp == nil)
@@ -63,6 +83,8 @@ func TestGetConfig(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Assert(t, cmp.Equal(mockSnmpInfo, snmp))
 	assert.Assert(t, rest.lastPath == "/rest/snmpConfig/127.0.0.1" || rest.lastPath == "/rest/snmpConfig/::1")
+	assert.Equal(t, 1, len(log.messages))
+	assert.Assert(t, strings.HasPrefix(log.messages[0], "localhost translates to "), log.messages[0])
 
 	snmp, err = api.GetConfig("127.0.0.1", "Apex")
 	assert.NilError(t, err)
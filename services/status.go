@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+)
+
+// statusFetch obtains the data of a section of the status summary; the returned function stores it on the status,
+// and is called by GetStatus itself, so a response that arrives after the timeout cannot modify a returned status
+type statusFetch func(rest api.RestAPI, stale time.Duration) (func(*model.ServerStatus), error)
+
+// statusSection a section of the status summary, with the function to obtain its data
+type statusSection struct {
+	get   func(*model.ServerStatus) *model.StatusSection
+	fetch statusFetch
+}
+
+// The outcome of fetching a section
+type statusResult struct {
+	index int
+	store func(*model.ServerStatus)
+	err   error
+}
+
+type statusAPI struct {
+	rest     api.RestAPI
+	sections []statusSection
+}
+
+// GetStatusAPI Obtain an implementation of the Status API
+func GetStatusAPI(rest api.RestAPI) api.StatusAPI {
+	return &statusAPI{rest, []statusSection{
+		{func(s *model.ServerStatus) *model.StatusSection { return &s.Alarms.StatusSection }, fetchAlarmsStatus},
+		{func(s *model.ServerStatus) *model.StatusSection { return &s.Outages.StatusSection }, fetchOutagesStatus},
+		{func(s *model.ServerStatus) *model.StatusSection { return &s.Minions.StatusSection }, fetchMinionsStatus},
+		{func(s *model.ServerStatus) *model.StatusSection { return &s.Imports.StatusSection }, fetchImportsStatus},
+	}}
+}
+
+// GetStatus fetches all the sections concurrently; a section that fails, or is not ready when the timeout expires,
+// is reported as unavailable instead of failing the whole summary. It fails when the context of the ReST API is cancelled.
+func (api statusAPI) GetStatus(timeout time.Duration, stale time.Duration) (*model.ServerStatus, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("Timeout must be greater than zero")
+	}
+	status := &model.ServerStatus{Time: model.Time{Time: time.Now()}}
+	parent := getContext(api.rest)
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	client := WithContext(ctx, api.rest)
+	results := make(chan statusResult, len(api.sections)) // Buffered, so late sections never block
+	for i, s := range api.sections {
+		go func(index int, fetch statusFetch) {
+			store, err := fetch(client, stale)
+			results <- statusResult{index, store, err}
+		}(i, s.fetch)
+	}
+	pending := make(map[int]bool)
+	for i := range api.sections {
+		pending[i] = true
+	}
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.index)
+			section := api.sections[r.index].get(status)
+			switch {
+			case r.err == nil:
+				r.store(status)
+				section.State = model.StatusSectionOK
+			case isMissingEndpoint(r.err):
+				section.State = model.StatusSectionMissing
+			case ctx.Err() != nil:
+				setStatusTimeout(section, timeout)
+			default:
+				section.State = model.StatusSectionFailed
+				section.Error = r.err.Error()
+			}
+		case <-ctx.Done():
+			if parent.Err() != nil {
+				return nil, rest.ErrCancelled
+			}
+			for index := range pending {
+				setStatusTimeout(api.sections[index].get(status), timeout)
+			}
+			return status, nil
+		}
+	}
+	return status, nil
+}
+
+func setStatusTimeout(section *model.StatusSection, timeout time.Duration) {
+	section.State = model.StatusSectionFailed
+	section.Error = fmt.Sprintf("timed out after %s", timeout)
+}
+
+// Returns true when the server doesn't provide the requested endpoint
+func isMissingEndpoint(err error) bool {
+	var e *rest.APIError
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
+// Counts the alarms of each severity, requesting a single alarm per severity to obtain the total count
+func fetchAlarmsStatus(client api.RestAPI, stale time.Duration) (func(*model.ServerStatus), error) {
+	alarms := GetAlarmsAPI(client)
+	counts := make([]model.SeverityCount, len(model.StatusSeverities))
+	errs := make([]error, len(model.StatusSeverities))
+	var wg sync.WaitGroup
+	for i, severity := range model.StatusSeverities {
+		wg.Add(1)
+		go func(i int, severity string) {
+			defer wg.Done()
+			list, err := alarms.GetAlarms("alarm.severity=="+strings.ToUpper(severity), 1, 0)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			counts[i] = model.SeverityCount{Severity: severity, Count: list.TotalCount}
+		}(i, severity)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return func(s *model.ServerStatus) {
+		s.Alarms.Severities = counts
+	}, nil
+}
+
+// Obtains the current outages, and the nodes with at least one of them
+func fetchOutagesStatus(client api.RestAPI, stale time.Duration) (func(*model.ServerStatus), error) {
+	list, err := GetOutagesAPI(client).GetOutages(model.CurrentOutagesFilter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool)
+	nodes := []string{}
+	for _, o := range list.Outages {
+		if !o.IsCurrent() || seen[o.NodeID] {
+			continue
+		}
+		seen[o.NodeID] = true
+		label := o.NodeLabel
+		if label == "" {
+			label = strconv.Itoa(o.NodeID)
+		}
+		nodes = append(nodes, label)
+	}
+	sort.Strings(nodes)
+	return func(s *model.ServerStatus) {
+		s.Outages.Outages = len(list.Outages)
+		s.Outages.NodesDown = nodes
+	}, nil
+}
+
+// Obtains the minions whose last heartbeat is older than the stale threshold
+func fetchMinionsStatus(client api.RestAPI, stale time.Duration) (func(*model.ServerStatus), error) {
+	list, err := GetMinionsAPI(client).GetMinions("", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	down := []string{}
+	for _, m := range list.Minions {
+		if m.IsStale(now, stale) {
+			down = append(down, m.ID)
+		}
+	}
+	sort.Strings(down)
+	return func(s *model.ServerStatus) {
+		s.Minions.Total = len(list.Minions)
+		s.Minions.Down = down
+	}, nil
+}
+
+// Obtains the last import of each requisition; the statistics are requested directly, as the Requisitions API
+// doesn't expose the status code required to tell a missing endpoint apart
+func fetchImportsStatus(client api.RestAPI, stale time.Duration) (func(*model.ServerStatus), error) {
+	jsonBytes, err := client.Get("/rest/requisitions/deployed/stats")
+	if err != nil {
+		return nil, err
+	}
+	stats := &model.RequisitionsStats{}
+	if err := getDecoder(client).DecodeJSON(jsonBytes, stats); err != nil {
+		return nil, err
+	}
+	imports := make([]model.RequisitionImport, len(stats.ForeignSources))
+	for i, fs := range stats.ForeignSources {
+		imports[i] = model.RequisitionImport{Name: fs.Name, Nodes: fs.Count, LastImport: fs.LastImport}
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Name < imports[j].Name })
+	return func(s *model.ServerStatus) {
+		s.Imports.Requisitions = imports
+	}, nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"gotest.tools/assert"
+)
+
+// The amount of requests made to build the status: one per severity, plus outages, minions and imports
+var statusRequests = len(model.StatusSeverities) + 3
+
+type mockStatusRest struct {
+	missing map[string]bool // Endpoints that return 404
+	blocked map[string]bool // Endpoints that don't answer until release is closed
+	release chan struct{}
+	barrier int // Requests don't answer until this amount of them is in-flight at the same time
+	mutex   sync.Mutex
+	arrived int
+	all     chan struct{}
+}
+
+func (api *mockStatusRest) Get(path string) ([]byte, error) {
+	endpoint := strings.Split(path, "?")[0]
+	if api.barrier > 0 {
+		api.mutex.Lock()
+		api.arrived++
+		if api.arrived == api.barrier {
+			close(api.all)
+		}
+		api.mutex.Unlock()
+		select {
+		case <-api.all:
+		case <-time.After(5 * time.Second):
+			return nil, fmt.Errorf("the requests were not concurrent")
+		}
+	}
+	if api.blocked[endpoint] {
+		<-api.release
+	}
+	if api.missing[endpoint] {
+		return nil, &rest.APIError{Method: http.MethodGet, URL: path, StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+	}
+	switch endpoint {
+	case "/api/v2/alarms":
+		counts := map[string]int{"CRITICAL": 2, "MAJOR": 5, "WARNING": 1}
+		for severity, count := range counts {
+			if strings.HasSuffix(path, "alarm.severity%3D%3D"+severity) {
+				return []byte(fmt.Sprintf(`{"count":1,"totalCount":%d,"offset":0,"alarm":[{"id":1}]}`, count)), nil
+			}
+		}
+		return []byte{}, nil
+	case "/api/v2/outages":
+		return []byte(`{"count":3,"totalCount":3,"offset":0,"outage":[
+			{"id":1,"nodeId":2,"nodeLabel":"srv01"},
+			{"id":2,"nodeId":1,"nodeLabel":"rtr01"},
+			{"id":3,"nodeId":2,"nodeLabel":"srv01"}]}`), nil
+	case "/api/v2/minions":
+		return []byte(fmt.Sprintf(`{"count":2,"totalCount":2,"offset":0,"minion":[
+			{"id":"minion-02","lastUpdated":1600000000000},
+			{"id":"minion-01","lastUpdated":%d}]}`, time.Now().UnixNano()/int64(time.Millisecond))), nil
+	case "/rest/requisitions/deployed/stats":
+		return []byte(`{"count":2,"foreign-source":[
+			{"name":"Servers","count":10,"foreign-id":[],"last-imported":1600000000000},
+			{"name":"Routers","count":3,"foreign-id":[]}]}`), nil
+	}
+	return nil, fmt.Errorf("should not be called: %s", path)
+}
+
+func (api *mockStatusRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockStatusRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockStatusRest) Put(path string, dataBytes []byte, contentType string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func TestGetStatus(t *testing.T) {
+	// Every request waits for all the others, so the status can only be built when they are concurrent
+	rest := &mockStatusRest{barrier: statusRequests, all: make(chan struct{})}
+	status, err := GetStatusAPI(rest).GetStatus(10*time.Second, 5*time.Minute)
+	assert.NilError(t, err)
+
+	assert.Equal(t, model.StatusSectionOK, status.Alarms.State)
+	assert.DeepEqual(t, []model.SeverityCount{
+		{Severity: "Critical", Count: 2},
+		{Severity: "Major", Count: 5},
+		{Severity: "Minor", Count: 0},
+		{Severity: "Warning", Count: 1},
+		{Severity: "Indeterminate", Count: 0},
+	}, status.Alarms.Severities)
+
+	assert.Equal(t, model.StatusSectionOK, status.Outages.State)
+	assert.Equal(t, 3, status.Outages.Outages)
+	assert.DeepEqual(t, []string{"rtr01", "srv01"}, status.Outages.NodesDown)
+
+	assert.Equal(t, model.StatusSectionOK, status.Minions.State)
+	assert.Equal(t, 2, status.Minions.Total)
+	assert.DeepEqual(t, []string{"minion-02"}, status.Minions.Down)
+
+	assert.Equal(t, model.StatusSectionOK, status.Imports.State)
+	assert.Equal(t, 2, len(status.Imports.Requisitions))
+	assert.Equal(t, "Routers", status.Imports.Requisitions[0].Name)
+	assert.Assert(t, status.Imports.Requisitions[0].LastImport == nil)
+	assert.Equal(t, 10, status.Imports.Requisitions[1].Nodes)
+	assert.Equal(t, int64(1600000000), status.Imports.Requisitions[1].LastImport.Unix())
+
+	_, err = GetStatusAPI(rest).GetStatus(0, time.Minute)
+	assert.Error(t, err, "Timeout must be greater than zero")
+}
+
+func TestGetStatusWithMissingEndpoints(t *testing.T) {
+	rest := &mockStatusRest{missing: map[string]bool{"/api/v2/minions": true, "/api/v2/alarms": true}}
+	status, err := GetStatusAPI(rest).GetStatus(10*time.Second, 5*time.Minute)
+	assert.NilError(t, err)
+	assert.Equal(t, model.StatusSectionMissing, status.Alarms.State)
+	assert.Equal(t, 0, len(status.Alarms.Severities))
+	assert.Equal(t, model.StatusSectionMissing, status.Minions.State)
+	assert.Equal(t, "n/a (endpoint missing)", status.Minions.Unavailable())
+	assert.Equal(t, model.StatusSectionOK, status.Outages.State)
+	assert.Equal(t, model.StatusSectionOK, status.Imports.State)
+}
+
+func TestGetStatusWithTimeout(t *testing.T) {
+	rest := &mockStatusRest{blocked: map[string]bool{"/rest/requisitions/deployed/stats": true}, release: make(chan struct{})}
+	defer close(rest.release)
+	start := time.Now()
+	status, err := GetStatusAPI(rest).GetStatus(100*time.Millisecond, 5*time.Minute)
+	assert.NilError(t, err)
+	assert.Assert(t, time.Since(start) < 5*time.Second)
+
+	// The sections that answered on time are kept
+	assert.Equal(t, model.StatusSectionOK, status.Alarms.State)
+	assert.Equal(t, model.StatusSectionOK, status.Outages.State)
+	assert.Equal(t, model.StatusSectionOK, status.Minions.State)
+	assert.Equal(t, model.StatusSectionFailed, status.Imports.State)
+	assert.Equal(t, "n/a (timed out after 100ms)", status.Imports.Unavailable())
+	assert.Equal(t, 0, len(status.Imports.Requisitions))
+}
+
+// cancelledStatusRest a ReST API whose context is done
+type cancelledStatusRest struct {
+	*mockStatusRest
+	ctx context.Context
+}
+
+func (r cancelledStatusRest) Context() context.Context {
+	return r.ctx
+}
+
+func TestGetStatusCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mock := &mockStatusRest{blocked: map[string]bool{"/rest/requisitions/deployed/stats": true}, release: make(chan struct{})}
+	defer close(mock.release)
+	_, err := GetStatusAPI(cancelledStatusRest{mock, ctx}).GetStatus(time.Hour, 5*time.Minute)
+	assert.Equal(t, rest.ErrCancelled, err)
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type thresholdsAPI struct {
+	rest api.RestAPI
+}
+
+// GetThresholdsAPI Obtain an implementation of the Thresholds API
+func GetThresholdsAPI(rest api.RestAPI) api.ThresholdsAPI {
+	return &thresholdsAPI{rest}
+}
+
+func (api thresholdsAPI) GetGroups() (*model.ThresholdGroupList, error) {
+	jsonData, err := api.rest.Get("/rest/thresholds")
+	if err != nil {
+		return nil, err
+	}
+	list := &model.ThresholdGroupList{}
+	if len(jsonData) == 0 {
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonData, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api thresholdsAPI) GetGroup(name string) (*model.ThresholdGroup, error) {
+	if name == "" {
+		return nil, fmt.Errorf("Threshold group name required")
+	}
+	jsonData, err := api.rest.Get("/rest/thresholds/" + url.PathEscape(name))
+	if err != nil {
+		return nil, err
+	}
+	group := &model.ThresholdGroup{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonData, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (api thresholdsAPI) SetGroup(group model.ThresholdGroup) error {
+	if err := group.Validate(); err != nil {
+		return err
+	}
+	jsonBytes, err := json.Marshal(group)
+	if err != nil {
+		return err
+	}
+	return api.rest.Put("/rest/thresholds/"+url.PathEscape(group.Name), jsonBytes, "application/json")
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"gotest.tools/assert"
+)
+
+type mockThresholdsRest struct {
+	lastPath string
+	received *model.ThresholdGroup
+}
+
+func (api *mockThresholdsRest) Get(path string) ([]byte, error) {
+	switch path {
+	case "/rest/thresholds":
+		return []byte(`{"count": 1, "group": [{"name": "mib2", "threshold": [{"ds-name": "ifHCInOctets", "type": "high", "ds-type": "if", "value": 1e9, "rearm": 8e8, "trigger": 3}]}]}`), nil
+	case "/rest/thresholds/mib2":
+		return []byte(`{"name": "mib2", "threshold": [{"ds-name": "ifHCInOctets", "type": "high", "ds-type": "if", "value": 1e9, "rearm": 8e8, "trigger": 3}]}`), nil
+	}
+	return nil, fmt.Errorf("should not be called")
+}
+
+func (api *mockThresholdsRest) Post(path string, jsonBytes []byte) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockThresholdsRest) Delete(path string) error {
+	return fmt.Errorf("should not be called")
+}
+
+func (api *mockThresholdsRest) Put(path string, dataBytes []byte, contentType string) error {
+	api.lastPath = path
+	api.received = &model.ThresholdGroup{}
+	return json.Unmarshal(dataBytes, api.received)
+}
+
+func TestGetThresholdGroups(t *testing.T) {
+	api := GetThresholdsAPI(&mockThresholdsRest{})
+	list, err := api.GetGroups()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, list.Count)
+	assert.Equal(t, "mib2", list.Groups[0].Name)
+
+	group, err := api.GetGroup("mib2")
+	assert.NilError(t, err)
+	assert.Equal(t, float64(8e8), group.Thresholds[0].Rearm)
+	_, err = api.GetGroup("")
+	assert.Error(t, err, "Threshold group name required")
+}
+
+func TestSetThresholdGroup(t *testing.T) {
+	rest := &mockThresholdsRest{}
+	api := GetThresholdsAPI(rest)
+	group := model.ThresholdGroup{Name: "netsnmp memory", Thresholds: []model.Threshold{
+		{DsName: "memAvailReal", ThresholdSettings: model.ThresholdSettings{Type: "low", DsType: "node", Value: 1000, Rearm: 2000, Trigger: 0}},
+	}}
+	err := api.SetGroup(group)
+	assert.Error(t, err, "Invalid threshold for memAvailReal: Trigger must be at least 1, got 0")
+	assert.Assert(t, rest.received == nil)
+
+	group.Thresholds[0].Trigger = 2
+	assert.NilError(t, api.SetGroup(group))
+	assert.Equal(t, "/rest/thresholds/netsnmp%20memory", rest.lastPath)
+	assert.Equal(t, 2, rest.received.Thresholds[0].Trigger)
+}
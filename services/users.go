@@ -0,0 +1,109 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/OpenNMS/onmsctl/api"
+	"github.com/OpenNMS/onmsctl/model"
+)
+
+type usersAPI struct {
+	rest api.RestAPI
+}
+
+// GetUsersAPI Obtain an implementation of the Users API
+func GetUsersAPI(rest api.RestAPI) api.UsersAPI {
+	return &usersAPI{rest}
+}
+
+func (api usersAPI) GetUsers() (*model.OnmsUserList, error) {
+	jsonBytes, err := api.rest.Get("/rest/users?limit=0")
+	if err != nil {
+		return nil, err
+	}
+	list := &model.OnmsUserList{}
+	if len(jsonBytes) == 0 {
+		return list, nil
+	}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (api usersAPI) GetUser(id string) (*model.OnmsUser, error) {
+	if id == "" {
+		return nil, fmt.Errorf("User ID required")
+	}
+	jsonBytes, err := api.rest.Get("/rest/users/" + url.PathEscape(id))
+	if err != nil {
+		return nil, fmt.Errorf("Cannot retrieve user %s: %w", id, err)
+	}
+	user := &model.OnmsUser{}
+	if err := getDecoder(api.rest).DecodeJSON(jsonBytes, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// AddUser creates or updates a user; the password is sent in clear text, and the server stores its hash
+func (api usersAPI) AddUser(user model.OnmsUser) error {
+	if err := user.Validate(); err != nil {
+		return err
+	}
+	if user.Password == "" {
+		return fmt.Errorf("Password required for user %s", user.ID)
+	}
+	user.PasswordSalt = false
+	jsonBytes, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	return api.rest.Post("/rest/users?hashPassword=true", jsonBytes)
+}
+
+func (api usersAPI) DeleteUser(id string) error {
+	if id == "" {
+		return fmt.Errorf("User ID required")
+	}
+	return api.rest.Delete("/rest/users/" + url.PathEscape(id))
+}
+
+// SetPassword changes the password of a user; the password is sent in clear text, and the server stores its hash
+func (api usersAPI) SetPassword(id string, password string) error {
+	if id == "" {
+		return fmt.Errorf("User ID required")
+	}
+	if password == "" {
+		return fmt.Errorf("Password cannot be empty")
+	}
+	params := url.Values{}
+	params.Set("password", password)
+	return api.rest.Put("/rest/users/"+url.PathEscape(id)+"?hashPassword=true", []byte(params.Encode()), "application/x-www-form-urlencoded")
+}
+
+func (api usersAPI) AddRole(id string, role string) error {
+	if err := api.checkRole(id, role); err != nil {
+		return err
+	}
+	return api.rest.Put("/rest/users/"+url.PathEscape(id)+"/roles/"+role, nil, "application/json")
+}
+
+func (api usersAPI) RemoveRole(id string, role string) error {
+	if err := api.checkRole(id, role); err != nil {
+		return err
+	}
+	return api.rest.Delete("/rest/users/" + url.PathEscape(id) + "/roles/" + role)
+}
+
+func (api usersAPI) checkRole(id string, role string) error {
+	if id == "" {
+		return fmt.Errorf("User ID required")
+	}
+	if role == "" {
+		return fmt.Errorf("Role required")
+	}
+	return model.ValidateRole(role)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/OpenNMS/onmsctl/model"
+	"github.com/OpenNMS/onmsctl/rest"
+	"gotest.tools/assert"
+)
+
+type mockUsersRest struct {
+	test  *testing.T
+	calls *[]string
+}
+
+func (api mockUsersRest) Get(path string) ([]byte, error) {
+	switch path {
+	case "/rest/users?limit=0":
+		return []byte{}, nil
+	case "/rest/users/admin":
+		return []byte(`{"user-id":"admin","full-name":"Administrator","password":"21232F297A57A5A743894A0E4A801FC3","passwordSalt":true,"role":["ROLE_ADMIN"]}`), nil
+	}
+	return nil, fmt.Errorf("should not be called")
+}
+
+func (api mockUsersRest) Post(path string, jsonBytes []byte) error {
+	assert.Equal(api.test, "/rest/users?hashPassword=true", path)
+	user := model.OnmsUser{}
+	assert.NilError(api.test, json.Unmarshal(jsonBytes, &user))
+	assert.Equal(api.test, "s3cr3t", user.Password)
+	assert.Assert(api.test, !strings.Contains(rest.RedactContent(string(jsonBytes)), "s3cr3t"))
+	*api.calls = append(*api.calls, "POST "+user.ID)
+	return nil
+}
+
+func (api mockUsersRest) Delete(path string) error {
+	*api.calls = append(*api.calls, "DELETE "+path)
+	return nil
+}
+
+func (api mockUsersRest) Put(path string, jsonBytes []byte, contentType string) error {
+	if strings.Contains(path, "hashPassword") {
+		assert.Equal(api.test, "application/x-www-form-urlencoded", contentType)
+		assert.Equal(api.test, "password=n3w%26p4ss", string(jsonBytes))
+		assert.Assert(api.test, !strings.Contains(rest.RedactContent(string(jsonBytes)), "n3w"))
+	}
+	*api.calls = append(*api.calls, "PUT "+path)
+	return nil
+}
+
+func TestUsersAPI(t *testing.T) {
+	calls := []string{}
+	api := GetUsersAPI(mockUsersRest{t, &calls})
+
+	list, err := api.GetUsers()
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(list.Users))
+
+	user, err := api.GetUser("admin")
+	assert.NilError(t, err)
+	assert.Equal(t, "Administrator", user.FullName)
+	assert.Assert(t, user.HasRole("ROLE_ADMIN"))
+
+	assert.Error(t, api.AddUser(model.OnmsUser{ID: "jdoe"}), "Password required for user jdoe")
+	assert.NilError(t, api.AddUser(model.OnmsUser{ID: "jdoe", Password: "s3cr3t", Roles: []string{"ROLE_USER"}}))
+	assert.NilError(t, api.SetPassword("jdoe", "n3w&p4ss"))
+	assert.Error(t, api.SetPassword("jdoe", ""), "Password cannot be empty")
+	assert.NilError(t, api.AddRole("jdoe", "ROLE_REST"))
+	assert.NilError(t, api.RemoveRole("jdoe", "ROLE_REST"))
+	assert.NilError(t, api.DeleteUser("jdoe"))
+	assert.DeepEqual(t, []string{
+		"POST jdoe",
+		"PUT /rest/users/jdoe?hashPassword=true",
+		"PUT /rest/users/jdoe/roles/ROLE_REST",
+		"DELETE /rest/users/jdoe/roles/ROLE_REST",
+		"DELETE /rest/users/jdoe",
+	}, calls)
+}
@@ -0,0 +1,110 @@
+package snmpclient
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// The OIDs of the system group (RFC 1213)
+const (
+	SysDescr    = "1.3.6.1.2.1.1.1.0"
+	SysObjectID = "1.3.6.1.2.1.1.2.0"
+	SysContact  = "1.3.6.1.2.1.1.4.0"
+	SysName     = "1.3.6.1.2.1.1.5.0"
+	SysLocation = "1.3.6.1.2.1.1.6.0"
+)
+
+// Versions the SNMP versions supported by the client
+var Versions = []string{"v1", "v2c"}
+
+// Client an SNMPv1/v2c client, built on gosnmp, that only sends GET requests
+type Client struct {
+	Address   string // The IP address or FQDN of the agent
+	Port      int
+	Community string
+	Version   string // v1 or v2c
+	Timeout   time.Duration
+	Retries   int
+}
+
+// Get obtains the values of the given OIDs as strings; the OIDs the agent doesn't provide are not included in the result
+func (cli Client) Get(oids ...string) (map[string]string, error) {
+	version, err := cli.getVersion()
+	if err != nil {
+		return nil, err
+	}
+	target := net.JoinHostPort(cli.Address, strconv.Itoa(cli.Port))
+	snmp := &gosnmp.GoSNMP{
+		Target:    cli.Address,
+		Port:      uint16(cli.Port),
+		Community: cli.Community,
+		Version:   version,
+		Timeout:   cli.Timeout,
+		Retries:   cli.Retries,
+	}
+	if err := snmp.Connect(); err != nil {
+		return nil, fmt.Errorf("Cannot reach the SNMP agent at %s: %w", target, err)
+	}
+	defer snmp.Conn.Close()
+	packet, err := snmp.Get(oids)
+	if err != nil && strings.Contains(err.Error(), "timeout") {
+		return nil, fmt.Errorf("No response from the SNMP agent at %s after %d attempts with a timeout of %s; verify the address, that UDP port %d is reachable, and that the agent accepts SNMP %s with the given community", target, cli.Retries+1, cli.Timeout, cli.Port, cli.Version)
+	}
+	if err != nil {
+		// e.x. ICMP port unreachable, reported on the next read of a connected UDP socket
+		return nil, fmt.Errorf("Cannot reach the SNMP agent at %s: %s; verify that the agent is running and listening on UDP port %d", target, err, cli.Port)
+	}
+	if packet.Error != gosnmp.NoError {
+		return cli.handleError(packet, oids, target)
+	}
+	values := make(map[string]string)
+	for _, pdu := range packet.Variables {
+		if value, ok := formatValue(pdu); ok {
+			values[strings.TrimPrefix(pdu.Name, ".")] = value
+		}
+	}
+	return values, nil
+}
+
+// SNMPv1 agents reject the whole request when one of the OIDs doesn't exist, so it is sent again without it
+func (cli Client) handleError(packet *gosnmp.SnmpPacket, oids []string, target string) (map[string]string, error) {
+	index := int(packet.ErrorIndex)
+	if packet.Error == gosnmp.NoSuchName && index > 0 && index <= len(oids) && len(oids) > 1 {
+		remaining := append(append([]string{}, oids[:index-1]...), oids[index:]...)
+		return cli.Get(remaining...)
+	}
+	if packet.Error == gosnmp.NoSuchName {
+		return map[string]string{}, nil
+	}
+	return nil, fmt.Errorf("The SNMP agent at %s rejected the request: %s", target, packet.Error)
+}
+
+func (cli Client) getVersion() (gosnmp.SnmpVersion, error) {
+	switch cli.Version {
+	case "v1":
+		return gosnmp.Version1, nil
+	case "v2c", "":
+		return gosnmp.Version2c, nil
+	}
+	return 0, fmt.Errorf("Unsupported SNMP version %s, valid options: v1, v2c", cli.Version)
+}
+
+// Returns the string representation of a value, or false when the agent doesn't provide it
+func formatValue(pdu gosnmp.SnmpPDU) (string, bool) {
+	switch pdu.Type {
+	case gosnmp.OctetString:
+		return strings.TrimRight(string(pdu.Value.([]byte)), "\x00"), true
+	case gosnmp.ObjectIdentifier:
+		return strings.TrimPrefix(pdu.Value.(string), "."), true
+	case gosnmp.IPAddress:
+		return pdu.Value.(string), true
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32:
+		return gosnmp.ToBigInt(pdu.Value).String(), true
+	}
+	return "", false // NULL, noSuchObject, noSuchInstance, endOfMibView
+}
@@ -0,0 +1,147 @@
+package snmpclient
+
+import (
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"gotest.tools/assert"
+)
+
+// fakeAgent an SNMP agent that answers GET requests from a static table, behaving like SNMPv1 or SNMPv2c agents for missing OIDs
+type fakeAgent struct {
+	conn      *net.UDPConn
+	community string
+	values    map[string]string
+	requests  int32
+}
+
+func startFakeAgent(t *testing.T, community string, values map[string]string) *fakeAgent {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NilError(t, err)
+	agent := &fakeAgent{conn: conn, community: community, values: values}
+	go agent.serve()
+	return agent
+}
+
+func (a *fakeAgent) port() int {
+	return a.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func (a *fakeAgent) serve() {
+	buffer := make([]byte, 65535)
+	for {
+		n, addr, err := a.conn.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		atomic.AddInt32(&a.requests, 1)
+		if response := a.respond(buffer[:n]); response != nil {
+			a.conn.WriteToUDP(response, addr)
+		}
+	}
+}
+
+func (a *fakeAgent) respond(data []byte) []byte {
+	request, err := (&gosnmp.GoSNMP{}).SnmpDecodePacket(data)
+	if err != nil || request.Community != a.community {
+		return nil // Agents silently drop requests with a wrong community
+	}
+	response := &gosnmp.SnmpPacket{
+		Version:   request.Version,
+		Community: request.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: request.RequestID,
+		Variables: make([]gosnmp.SnmpPDU, 0, len(request.Variables)),
+	}
+	for i, v := range request.Variables {
+		value, ok := a.values[strings.TrimPrefix(v.Name, ".")]
+		switch {
+		case ok:
+			response.Variables = append(response.Variables, gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.OctetString, Value: []byte(value)})
+		case request.Version == gosnmp.Version1:
+			if response.Error == gosnmp.NoError {
+				response.Error, response.ErrorIndex = gosnmp.NoSuchName, uint8(i+1)
+			}
+		default:
+			response.Variables = append(response.Variables, gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.NoSuchObject})
+		}
+	}
+	if response.Error != gosnmp.NoError {
+		// The bindings of the request are sent back unchanged
+		response.Variables = make([]gosnmp.SnmpPDU, len(request.Variables))
+		for i, v := range request.Variables {
+			response.Variables[i] = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.Null}
+		}
+	}
+	encoded, err := response.MarshalMsg()
+	if err != nil {
+		return nil
+	}
+	return encoded
+}
+
+func TestGet(t *testing.T) {
+	agent := startFakeAgent(t, "s3cr3t", map[string]string{
+		SysName:  "rtr01.example.com",
+		SysDescr: "Cisco IOS Software",
+	})
+	defer agent.conn.Close()
+
+	for _, version := range Versions {
+		client := Client{Address: "127.0.0.1", Port: agent.port(), Community: "s3cr3t", Version: version, Timeout: time.Second}
+		values, err := client.Get(SysName, SysDescr, SysLocation)
+		assert.NilError(t, err, version)
+		assert.DeepEqual(t, map[string]string{SysName: "rtr01.example.com", SysDescr: "Cisco IOS Software"}, values)
+	}
+
+	client := Client{Address: "127.0.0.1", Port: agent.port(), Community: "public", Version: "v2c", Timeout: 50 * time.Millisecond, Retries: 1}
+	atomic.StoreInt32(&agent.requests, 0)
+	_, err := client.Get(SysName)
+	assert.ErrorContains(t, err, "No response from the SNMP agent at 127.0.0.1:")
+	assert.ErrorContains(t, err, "after 2 attempts with a timeout of 50ms")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&agent.requests))
+
+	client.Version = "v3"
+	_, err = client.Get(SysName)
+	assert.Error(t, err, "Unsupported SNMP version v3, valid options: v1, v2c")
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		pdu      gosnmp.SnmpPDU
+		expected string
+	}{
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("rtr01\x00")}, "rtr01"},
+		{gosnmp.SnmpPDU{Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1"}, "1.3.6.1.4.1.9.1.1"},
+		{gosnmp.SnmpPDU{Type: gosnmp.IPAddress, Value: "10.0.0.1"}, "10.0.0.1"},
+		{gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: -1}, "-1"},
+		{gosnmp.SnmpPDU{Type: gosnmp.TimeTicks, Value: uint32(4294967295)}, "4294967295"},
+		{gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(18446744073709551615)}, "18446744073709551615"},
+	}
+	for _, test := range tests {
+		value, ok := formatValue(test.pdu)
+		assert.Assert(t, ok, test.pdu.Type)
+		assert.Equal(t, test.expected, value)
+	}
+	for _, missing := range []gosnmp.Asn1BER{gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView} {
+		_, ok := formatValue(gosnmp.SnmpPDU{Type: missing})
+		assert.Assert(t, !ok, missing)
+	}
+}
+
+func TestGetUnreachable(t *testing.T) {
+	// A port without listener, so the ICMP port unreachable is reported (or the request times out)
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NilError(t, err)
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+
+	client := Client{Address: "127.0.0.1", Port: port, Community: "public", Version: "v2c", Timeout: 50 * time.Millisecond}
+	_, err = client.Get(SysName)
+	assert.Assert(t, err != nil)
+	assert.Assert(t, strings.Contains(err.Error(), "Cannot reach the SNMP agent") || strings.Contains(err.Error(), "No response from the SNMP agent"), err.Error())
+}
@@ -1,6 +1,13 @@
 package test
 
 import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/OpenNMS/onmsctl/common"
+	"github.com/OpenNMS/onmsctl/rest"
 	"github.com/urfave/cli"
 )
 
@@ -213,10 +220,59 @@ var DetectorsJSON = `
 }
 `
 
-// CreateCli Creates a CLI Application object
-func CreateCli(cmd cli.Command) *cli.App {
+// CreateCli Creates a CLI Application object, whose ReST client is built from the given options (e.x. the URL of a test server)
+func CreateCli(cmd cli.Command, opts ...rest.Option) *cli.App {
 	var app = cli.NewApp()
 	app.Name = "onmsctl"
 	app.Commands = []cli.Command{cmd}
+	client, err := rest.NewClient(append([]rest.Option{rest.WithLogger(common.Log)}, opts...)...)
+	if err != nil {
+		panic(err)
+	}
+	common.SetClient(app, client)
 	return app
 }
+
+// Client returns the ReST client of a CLI Application object created through CreateCli, to adjust its settings
+func Client(app *cli.App) *rest.Client {
+	return common.GetClient(cli.NewContext(app, nil, nil))
+}
+
+// RunWithOutput runs a CLI Application with a given output format, and returns the displayed content
+func RunWithOutput(app *cli.App, format string, args ...string) (string, error) {
+	var output bytes.Buffer
+	common.Output = &output
+	common.OutputFormat = format
+	defer func() {
+		common.Output = os.Stdout
+		common.OutputFormat = common.OutputTable
+	}()
+	err := app.Run(append([]string{app.Name}, args...))
+	return output.String(), err
+}
+
+// CaptureLogs redirects the messages of the leveled logger to a buffer, until the returned function is called
+func CaptureLogs() (*bytes.Buffer, func()) {
+	var logs bytes.Buffer
+	common.Log.Output = &logs
+	return &logs, func() { common.Log.Output = os.Stderr }
+}
+
+// Hosts the static table used by LookupIP
+var Hosts = map[string][]string{
+	"www.opennms.com": {"34.194.50.139"},
+	"www.opennms.org": {"34.194.50.139"},
+}
+
+// LookupIP resolves the names from Hosts, to avoid live DNS queries on tests (replacement for net.LookupIP)
+func LookupIP(host string) ([]net.IP, error) {
+	addresses, ok := Hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("lookup %s: no such host", host)
+	}
+	ips := make([]net.IP, len(addresses))
+	for i, a := range addresses {
+		ips[i] = net.ParseIP(a)
+	}
+	return ips, nil
+}